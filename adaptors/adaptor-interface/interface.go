@@ -12,10 +12,12 @@ import (
 	"log/slog"
 	"os"
 
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 )
@@ -26,6 +28,30 @@ type HwMgrAdaptorIntf interface {
 	HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (bool, error)
 	GetResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourcePoolInfo, int, error)
 	GetResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, int, error)
+	StreamResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, emit func(invserver.ResourceInfo) error) (int, error)
+	RotateBMCCredentials(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node, credentials *corev1.Secret) (bool, error)
+	HandleNodePowerAction(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node, action string) (bool, error)
+	HandleNodeTags(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node, add, remove []string) (bool, error)
+}
+
+// InventoryAdaptorIntf is implemented by adaptors that run background tasks to serve the inventory API, such as
+// refreshing an inventory cache. In a process serving only the inventory API, SetupInventory is called in place of
+// SetupAdaptor, which also sets up the adaptor controllers.
+type InventoryAdaptorIntf interface {
+	SetupInventory(mgr ctrl.Manager) error
+}
+
+// SiteResourcePoolsAdaptorIntf is implemented by adaptors that can look up the resource pools of a single site without
+// listing those of every site. The resource pools of other adaptors are filtered by site after they are listed.
+type SiteResourcePoolsAdaptorIntf interface {
+	GetSiteResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, site string) ([]invserver.ResourcePoolInfo, int, error)
+}
+
+// MetadataMigrationAdaptorIntf is implemented by adaptors that set labels and annotations on objects of their own,
+// such as BMHs, so that these are migrated to the current metadata schema when the plugin starts. The client reads
+// from the API server, as the migration runs before the manager cache is started.
+type MetadataMigrationAdaptorIntf interface {
+	MigrateMetadata(ctx context.Context, c client.Client) (utils.MetadataMigrationResult, error)
 }
 
 // Define the HwMgrAdaptor structures
@@ -0,0 +1,63 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptorinterface
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdaptorFactory creates an instance of an adaptor
+type AdaptorFactory func(client client.Client, noncachedClient client.Reader, scheme *runtime.Scheme,
+	logger *slog.Logger, namespace string) HwMgrAdaptorIntf
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]AdaptorFactory)
+)
+
+// RegisterAdaptor registers the factory of an adaptor under its adaptor ID. Adaptor packages call this from an init
+// function, so an adaptor is made available by importing its package. Registering the same ID twice panics.
+func RegisterAdaptor(adaptorID string, factory AdaptorFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if adaptorID == "" || factory == nil {
+		panic("adaptor registration requires an adaptor ID and factory")
+	}
+	if _, exists := registry[adaptorID]; exists {
+		panic(fmt.Sprintf("adaptor %s is already registered", adaptorID))
+	}
+	registry[adaptorID] = factory
+}
+
+// GetAdaptorFactory returns the factory registered for an adaptor ID
+func GetAdaptorFactory(adaptorID string) (AdaptorFactory, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	factory, exists := registry[adaptorID]
+	return factory, exists
+}
+
+// RegisteredAdaptors returns the sorted IDs of the registered adaptors
+func RegisteredAdaptors() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	ids := make([]string, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
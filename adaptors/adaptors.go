@@ -8,15 +8,20 @@ package adaptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -25,18 +30,12 @@ import (
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 
-	// Import the adaptors
-	dellhwmgr "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr"
-	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback"
-	metal3 "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/metal3"
-)
-
-// Supported adaptor IDs
-const (
-	LoopbackAdaptorID  = "loopback"
-	DellHwMgrAdaptorID = "dell-hwmgr"
-	Metal3AdaptorID    = "metal3"
+	// Import the adaptors, which register themselves with the adaptor registry
+	_ "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr"
+	_ "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback"
+	_ "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/metal3"
 )
 
 // HwMgrAdaptorController
@@ -46,25 +45,97 @@ type HwMgrAdaptorController struct {
 	Scheme          *runtime.Scheme
 	Logger          *slog.Logger
 	Namespace       string
-	adaptors        map[string]adaptorinterface.HwMgrAdaptorIntf
+	// EnabledAdaptors lists the IDs of the adaptors to set up. All registered adaptors are set up if empty.
+	EnabledAdaptors []string
+	// DisabledAdaptors lists the IDs of adaptors that are not set up, even if enabled
+	DisabledAdaptors []string
+	// InventoryOnly sets up the adaptors to serve the inventory API alone, without their controllers, for a process
+	// that leaves NodePool handling to another
+	InventoryOnly    bool
+	adaptors         map[string]adaptorinterface.HwMgrAdaptorIntf
+	nodePoolLocks    *nodePoolLocks
+	siteAdmissions   *siteAdmissions
+	backendHealth    *backendHealthMonitor
+	waitForCacheSync func(ctx context.Context) bool
+	recorder         record.EventRecorder
 }
 
 func (c *HwMgrAdaptorController) SetupWithManager(mgr ctrl.Manager) error {
-	// Setup the supported adaptors
+	c.nodePoolLocks = newNodePoolLocks()
+	c.siteAdmissions = newSiteAdmissions()
+	c.backendHealth = newBackendHealthMonitor(c)
+	c.waitForCacheSync = mgr.GetCache().WaitForCacheSync
+	c.recorder = mgr.GetEventRecorderFor("hwmgr-adaptors")
+
+	enabled, err := c.selectAdaptors()
+	if err != nil {
+		return err
+	}
+
+	// Setup the enabled adaptors
 	c.adaptors = make(map[string]adaptorinterface.HwMgrAdaptorIntf)
-	c.adaptors[LoopbackAdaptorID] = loopback.NewAdaptor(c.Client, c.NoncachedClient, c.Scheme, c.Logger, c.Namespace)
-	c.adaptors[DellHwMgrAdaptorID] = dellhwmgr.NewAdaptor(c.Client, c.NoncachedClient, c.Scheme, c.Logger, c.Namespace)
-	c.adaptors[Metal3AdaptorID] = metal3.NewAdaptor(c.Client, c.NoncachedClient, c.Scheme, c.Logger, c.Namespace)
+	for _, id := range enabled {
+		factory, _ := adaptorinterface.GetAdaptorFactory(id)
+		c.adaptors[id] = factory(c.Client, c.NoncachedClient, c.Scheme, c.Logger, c.Namespace)
+	}
+	c.Logger.Info("Adaptors enabled", slog.Any("adaptors", enabled), slog.Any("disabled", c.DisabledAdaptors))
 
 	for id, adaptor := range c.adaptors {
+		if c.InventoryOnly {
+			if inventoryAdaptor, ok := adaptor.(adaptorinterface.InventoryAdaptorIntf); ok {
+				if err := inventoryAdaptor.SetupInventory(mgr); err != nil {
+					c.Logger.Error("failed to setup adaptor inventory", slog.String("id", id), slog.String("error", err.Error()))
+				}
+			}
+			continue
+		}
 		if err := adaptor.SetupAdaptor(mgr); err != nil {
 			c.Logger.Error("failed to setup adaptor", slog.String("id", id), slog.String("error", err.Error()))
 		}
 	}
 
+	if !c.InventoryOnly {
+		if err := c.setupNodeTimingMetrics(mgr); err != nil {
+			c.Logger.Error("failed to setup node timing metrics", slog.String("error", err.Error()))
+		}
+	}
+
+	if err := mgr.Add(c.backendHealth); err != nil {
+		return fmt.Errorf("failed to add inventory backend health monitor: %w", err)
+	}
+
 	return nil
 }
 
+// selectAdaptors returns the IDs of the adaptors to set up, from the enabled and disabled lists
+func (c *HwMgrAdaptorController) selectAdaptors() ([]string, error) {
+	registered := adaptorinterface.RegisteredAdaptors()
+	for _, id := range append(slices.Clone(c.EnabledAdaptors), c.DisabledAdaptors...) {
+		if !slices.Contains(registered, id) {
+			return nil, fmt.Errorf("unknown adaptor %s, registered adaptors are: %s", id, strings.Join(registered, ", "))
+		}
+	}
+
+	candidates := c.EnabledAdaptors
+	if len(candidates) == 0 {
+		candidates = registered
+	}
+
+	var enabled []string
+	for _, id := range candidates {
+		if !slices.Contains(c.DisabledAdaptors, id) && !slices.Contains(enabled, id) {
+			enabled = append(enabled, id)
+		}
+	}
+	return enabled, nil
+}
+
+// IsAdaptorEnabled checks whether an adaptor is set up in this deployment
+func (c *HwMgrAdaptorController) IsAdaptorEnabled(adaptorID string) bool {
+	_, exists := c.adaptors[adaptorID]
+	return exists
+}
+
 func (c *HwMgrAdaptorController) getHwMgr(ctx context.Context, hwMgrId string) (*pluginv1alpha1.HardwareManager, int, error) {
 	name := types.NamespacedName{
 		Name:      hwMgrId,
@@ -89,22 +160,56 @@ func (c *HwMgrAdaptorController) getHwMgr(ctx context.Context, hwMgrId string) (
 		}
 	case pluginv1alpha1.SupportedAdaptors.Metal3:
 		c.Logger.InfoContext(ctx, "HardwareManager", slog.String("name", hwmgr.Name))
-	default:
-		return nil, http.StatusServiceUnavailable, fmt.Errorf("unsupported adaptorId (%s) HardwareManager: name=%s", hwmgr.Spec.AdaptorID, hwmgr.Name)
 	}
 
 	return hwmgr, http.StatusOK, nil
 }
 
-// HandleNodePool calls the applicable adaptor handler to process the NodePool CR
+// HandleNodePool calls the applicable adaptor handler to process the NodePool CR. The status updates made while
+// handling it are collected and written in a single patch, before the NodePool is unlocked.
 func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
-	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", nodepool.Spec.HwMgrId))
+	ctx = logging.WithHwMgr(ctx, nodepool.Spec.HwMgrId)
+
+	if !c.nodePoolLocks.tryLock(nodepool.Name) {
+		c.Logger.InfoContext(ctx, "NodePool is being handled by another request, requeueing")
+		return utils.RequeueWithShortInterval(), nil
+	}
+	defer c.nodePoolLocks.unlock(nodepool.Name)
+
+	batchCtx := utils.WithNodePoolStatusBatch(ctx, nodepool)
+	result, err := c.handleNodePool(batchCtx, nodepool)
+
+	statusCtx, statusSpan := tracing.StartSpan(ctx, "Update NodePool status")
+	flushErr := utils.FlushNodePoolStatus(batchCtx, c.Client)
+	tracing.EndSpan(statusSpan, flushErr)
+	if flushErr != nil {
+		c.Logger.InfoContext(statusCtx, "Failed to update NodePool status, requeueing", slog.String("error", flushErr.Error()))
+		if err == nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, flushErr)
+		}
+	}
+
+	return result, err
+}
+
+// handleNodePool runs the checks made before handing off a NodePool to its adaptor, then the adaptor handler
+func (c *HwMgrAdaptorController) handleNodePool(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	// A paused NodePool is not handed to the adaptor at all, so that no adaptor makes changes for it. Removing the
+	// annotation retriggers the reconcile.
+	paused := utils.IsNodePoolPaused(nodepool)
+	if err := utils.UpdateNodePoolPausedCondition(ctx, c.Client, nodepool, paused); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	if paused {
+		c.Logger.InfoContext(ctx, "NodePool is paused, skipping")
+		return utils.DoNotRequeue(), nil
+	}
+
 	hwmgr, _, err := c.getHwMgr(ctx, nodepool.Spec.HwMgrId)
 	if err != nil {
 		c.Logger.ErrorContext(ctx, "failed to get adaptor instance", slog.String("error", err.Error()))
 
-		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
+		if err := c.updateNodePoolFailure(ctx, nodepool,
 			"Unable to find HardwareManager instance: "+nodepool.Spec.HwMgrId); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
@@ -120,8 +225,7 @@ func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *h
 	if !exists {
 		c.Logger.ErrorContext(ctx, "unsupported adaptor ID", slog.String("adaptorID", adaptorID))
 
-		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
-			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
+		if err := c.updateNodePoolFailure(ctx, nodepool,
 			"Unsupported adaptor ID specified: "+adaptorID); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
@@ -130,12 +234,67 @@ func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *h
 		return utils.DoNotRequeue(), nil
 	}
 
-	result, err := adaptor.HandleNodePool(ctx, hwmgr, nodepool)
+	// A dry run does not allocate anything, so there is nothing to release on deletion
+	dryRun := utils.IsNodePoolDryRun(nodepool)
+
+	if valid, err := c.checkNodePoolResourceSelectors(ctx, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	} else if !valid {
+		return utils.DoNotRequeue(), nil
+	}
+
+	if valid, err := c.checkNodePoolResourcePools(ctx, hwmgr, adaptor, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	} else if !valid {
+		return utils.DoNotRequeue(), nil
+	}
+
+	if proceed, err := c.checkNodePoolPriority(ctx, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	} else if !proceed {
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	if proceed, err := c.checkSiteProvisioning(ctx, hwmgr, nodepool); err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	} else if !proceed {
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	// The pre-allocation hook gates the hand-off of a new NodePool to the adaptor. A reservation only holds the
+	// hardware, so the hook runs once the NodePool is activated.
+	if !dryRun && !utils.IsNodePoolReservation(nodepool) && utils.GetNodePoolProvisionedCondition(nodepool) == nil {
+		state, err := utils.RunAllocationHook(ctx, c.Client, c.NoncachedClient, c.Namespace, nodepool, utils.PreAllocationHook)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		switch state {
+		case utils.AllocationHookRunning:
+			c.Logger.InfoContext(ctx, "Waiting for pre-allocation hook")
+			return utils.RequeueWithShortInterval(), nil
+		case utils.AllocationHookFailed:
+			return utils.DoNotRequeue(), nil
+		}
+	}
+
+	timedOut, result, remaining, err := c.checkProvisioningTimeout(ctx, hwmgr, adaptor, nodepool)
+	if timedOut || err != nil {
+		return result, err
+	}
+
+	if err := c.checkNodeHwProfileOverrides(ctx, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	adaptorCtx, span := tracing.StartSpan(ctx, "Adaptor HandleNodePool", tracing.AttrAdaptor.String(adaptorID))
+	result, err = adaptor.HandleNodePool(adaptorCtx, hwmgr, nodepool)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return result, fmt.Errorf("failed HandleNodePool for adaptorID %s: %w", adaptorID, err)
 	}
+	result = requeueByDeadline(result, remaining)
 
-	if !controllerutil.ContainsFinalizer(nodepool, utils.NodepoolFinalizer) {
+	if !dryRun && !controllerutil.ContainsFinalizer(nodepool, utils.NodepoolFinalizer) {
 		c.Logger.InfoContext(ctx, "Adding finalizer to NodePool")
 		if err := utils.NodepoolAddFinalizer(ctx, c.Client, nodepool); err != nil {
 			return utils.RequeueImmediately(), fmt.Errorf("failed to add finalizer to nodepool: %w", err)
@@ -145,8 +304,27 @@ func (c *HwMgrAdaptorController) HandleNodePool(ctx context.Context, nodepool *h
 	return result, nil
 }
 
+// updateNodePoolFailure reports a NodePool that cannot be handed off to an adaptor, in the DryRun condition if only
+// a dry run was requested
+func (c *HwMgrAdaptorController) updateNodePoolFailure(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, message string) error {
+	if utils.IsNodePoolDryRun(nodepool) {
+		return utils.UpdateNodePoolDryRunCondition(ctx, c.Client, nodepool, errors.New(message), "")
+	}
+
+	return utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+		hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse, message)
+}
+
 // HandleNodePool calls the applicable adaptor handler to process the NodePool CR deletion
 func (c *HwMgrAdaptorController) HandleNodePoolDeletion(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+	ctx = logging.WithHwMgr(ctx, nodepool.Spec.HwMgrId)
+
+	if !c.nodePoolLocks.tryLock(nodepool.Name) {
+		c.Logger.InfoContext(ctx, "NodePool is being handled by another request, deferring deletion")
+		return false, nil
+	}
+	defer c.nodePoolLocks.unlock(nodepool.Name)
+
 	hwmgr, _, err := c.getHwMgr(ctx, nodepool.Spec.HwMgrId)
 	if err != nil {
 		return false, fmt.Errorf("failed to get HardwareManager CR (%s): %w", nodepool.Spec.HwMgrId, err)
@@ -161,96 +339,206 @@ func (c *HwMgrAdaptorController) HandleNodePoolDeletion(ctx context.Context, nod
 		return true, nil
 	}
 
-	completed, err := adaptor.HandleNodePoolDeletion(ctx, hwmgr, nodepool)
+	adaptorCtx, span := tracing.StartSpan(ctx, "Adaptor HandleNodePoolDeletion", tracing.AttrAdaptor.String(adaptorID))
+	completed, err := adaptor.HandleNodePoolDeletion(adaptorCtx, hwmgr, nodepool)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return false, fmt.Errorf("failed HandleNodePoolDeletion for adaptorID %s: %w", adaptorID, err)
 	}
 
+	if completed {
+		if err := utils.DeleteNodePoolBmcSecrets(ctx, c.Client, nodepool); err != nil {
+			return false, err
+		}
+		if err := utils.DeleteNodePoolHookJobs(ctx, c.Client, c.NoncachedClient, c.Namespace, nodepool); err != nil {
+			return false, err
+		}
+		c.siteAdmissions.release(nodepool)
+	}
+
 	return completed, nil
 }
 
-// HandleNodePool calls the applicable adaptor handler to process the NodePool CR deletion
-func (c *HwMgrAdaptorController) GetResourcePools(ctx context.Context, request invserver.GetResourcePoolsRequestObject) (invserver.GetResourcePoolsResponseObject, error) {
-
-	hwmgr, statusCode, err := c.getHwMgr(ctx, request.HwMgrId)
-	if err != nil {
-		if statusCode == http.StatusNotFound {
-			return invserver.GetResourcePools404ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-				Status: statusCode,
-				Detail: fmt.Sprintf("Hardware Manager %s not found", request.HwMgrId),
-			}), fmt.Errorf("hardware manager %s not found: %w", request.HwMgrId, err)
-		} else {
-			return invserver.GetResourcePools503ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-				Status: statusCode,
-				Detail: fmt.Sprintf("Hardware Manager %s unavailable: %s", request.HwMgrId, err.Error()),
-			}), fmt.Errorf("unable to get hardware manager %s: %w", request.HwMgrId, err)
-		}
+// RotateBMCCredentials calls the applicable adaptor handler to set new BMC credentials on a node, returning true
+// once the rotation is complete
+func (c *HwMgrAdaptorController) RotateBMCCredentials(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	credentials *corev1.Secret) (bool, error) {
+
+	ctx = logging.WithHwMgr(ctx, hwmgr.Name)
+
+	// The rotation updates the node, so it must not run while the NodePool handler may be updating it too
+	if !c.nodePoolLocks.tryLock(node.Spec.NodePool) {
+		c.Logger.InfoContext(ctx, "NodePool is being handled by another request, deferring BMC credentials rotation",
+			slog.String("nodepool", node.Spec.NodePool))
+		return false, nil
 	}
+	defer c.nodePoolLocks.unlock(node.Spec.NodePool)
 
 	adaptorID := string(hwmgr.Spec.AdaptorID)
 
 	// Validate the specified adaptor ID
 	adaptor, exists := c.adaptors[adaptorID]
 	if !exists {
-		// We should never get here, as the adaptor ID is validated in getHwMgr
-		c.Logger.ErrorContext(ctx, "unsupported adaptor ID", slog.String("adaptorID", adaptorID))
-		return invserver.GetResourcePools500ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-			Status: statusCode,
-			Detail: fmt.Sprintf("Hardware Manager %s specifies invalid adaptorId: %s", request.HwMgrId, adaptorID),
-		}), fmt.Errorf("hardware manager %s species invalid adaptorId: %s", request.HwMgrId, adaptorID)
+		return false, fmt.Errorf("unsupported adaptorId (%s) HardwareManager: name=%s", adaptorID, hwmgr.Name)
 	}
 
-	resp, statusCode, err := adaptor.GetResourcePools(ctx, hwmgr)
+	completed, err := adaptor.RotateBMCCredentials(ctx, hwmgr, node, credentials)
 	if err != nil {
-		c.Logger.ErrorContext(ctx, "unable to get resource pools from hardware manager", slog.String("hwMgrId", request.HwMgrId), slog.String("error", err.Error()))
-		return invserver.GetResourcePools500ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-			Status: statusCode,
-			Detail: fmt.Sprintf("Resource Pool query failed for %s: %s", request.HwMgrId, err.Error()),
-		}), fmt.Errorf("unable to query pools from hardware manager %s: %w", request.HwMgrId, err)
+		return false, fmt.Errorf("failed RotateBMCCredentials for adaptorID %s: %w", adaptorID, err)
 	}
 
-	return invserver.GetResourcePools200JSONResponse(resp), nil
+	return completed, nil
 }
 
-// HandleNodePool calls the applicable adaptor handler to process the NodePool CR deletion
-func (c *HwMgrAdaptorController) GetResources(ctx context.Context, request invserver.GetResourcesRequestObject) (invserver.GetResourcesResponseObject, error) {
+// HandleNodePowerAction calls the applicable adaptor handler to apply a power action to a node, returning true once
+// the action is complete
+func (c *HwMgrAdaptorController) HandleNodePowerAction(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	action string) (bool, error) {
+
+	ctx = logging.WithHwMgr(ctx, hwmgr.Name)
+
+	// A power action must not interrupt a hardware configuration update driven by the NodePool handler
+	if !c.nodePoolLocks.tryLock(node.Spec.NodePool) {
+		c.Logger.InfoContext(ctx, "NodePool is being handled by another request, deferring power action",
+			slog.String("nodepool", node.Spec.NodePool))
+		return false, nil
+	}
+	defer c.nodePoolLocks.unlock(node.Spec.NodePool)
+
+	adaptorID := string(hwmgr.Spec.AdaptorID)
 
-	hwmgr, statusCode, err := c.getHwMgr(ctx, request.HwMgrId)
+	// Validate the specified adaptor ID
+	adaptor, exists := c.adaptors[adaptorID]
+	if !exists {
+		return false, fmt.Errorf("unsupported adaptorId (%s) HardwareManager: name=%s", adaptorID, hwmgr.Name)
+	}
+
+	completed, err := adaptor.HandleNodePowerAction(ctx, hwmgr, node, action)
 	if err != nil {
-		if statusCode == http.StatusNotFound {
-			return invserver.GetResources404ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-				Status: statusCode,
-				Detail: fmt.Sprintf("Hardware Manager %s not found", request.HwMgrId),
-			}), fmt.Errorf("hardware manager %s not found: %w", request.HwMgrId, err)
-		} else {
-			return invserver.GetResources503ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-				Status: statusCode,
-				Detail: fmt.Sprintf("Hardware Manager %s unavailable: %s", request.HwMgrId, err.Error()),
-			}), fmt.Errorf("unable to get hardware manager %s: %w", request.HwMgrId, err)
-		}
+		return false, fmt.Errorf("failed HandleNodePowerAction for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return completed, nil
+}
+
+// HandleNodeTags calls the applicable adaptor handler to add and remove tags on the hardware backing a node, returning
+// true once the tags are applied
+func (c *HwMgrAdaptorController) HandleNodeTags(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	add, remove []string) (bool, error) {
+
+	ctx = logging.WithHwMgr(ctx, hwmgr.Name)
+
+	// Tags are applied between, rather than alongside, the hardware updates driven by the NodePool handler
+	if !c.nodePoolLocks.tryLock(node.Spec.NodePool) {
+		c.Logger.InfoContext(ctx, "NodePool is being handled by another request, deferring tag update",
+			slog.String("nodepool", node.Spec.NodePool))
+		return false, nil
 	}
+	defer c.nodePoolLocks.unlock(node.Spec.NodePool)
 
 	adaptorID := string(hwmgr.Spec.AdaptorID)
 
 	// Validate the specified adaptor ID
 	adaptor, exists := c.adaptors[adaptorID]
 	if !exists {
-		// We should never get here, as the adaptor ID is validated in getHwMgr
-		c.Logger.ErrorContext(ctx, "unsupported adaptor ID", slog.String("adaptorID", adaptorID))
-		return invserver.GetResources500ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-			Status: statusCode,
-			Detail: fmt.Sprintf("Hardware Manager %s specifies invalid adaptorId: %s", request.HwMgrId, adaptorID),
-		}), fmt.Errorf("hardware manager %s species invalid adaptorId: %s", request.HwMgrId, adaptorID)
+		return false, fmt.Errorf("unsupported adaptorId (%s) HardwareManager: name=%s", adaptorID, hwmgr.Name)
 	}
 
-	resp, statusCode, err := adaptor.GetResources(ctx, hwmgr)
+	completed, err := adaptor.HandleNodeTags(ctx, hwmgr, node, add, remove)
 	if err != nil {
-		c.Logger.ErrorContext(ctx, "unable to get resources from hardware manager", slog.String("hwMgrId", request.HwMgrId), slog.String("error", err.Error()))
-		return invserver.GetResources500ApplicationProblemPlusJSONResponse(invserver.ProblemDetails{
-			Status: statusCode,
-			Detail: fmt.Sprintf("Resource query failed for %s: %s", request.HwMgrId, err.Error()),
-		}), fmt.Errorf("unable to query resources from hardware manager %s: %w", request.HwMgrId, err)
+		return false, fmt.Errorf("failed HandleNodeTags for adaptorID %s: %w", adaptorID, err)
+	}
+
+	return completed, nil
+}
+
+// GetResourcePools handles an inventory API request to list the resource pools of a hardware manager
+func (c *HwMgrAdaptorController) GetResourcePools(ctx context.Context, request invserver.GetResourcePoolsRequestObject) (invserver.GetResourcePoolsResponseObject, error) {
+	site := ""
+	if request.Params.SiteId != nil {
+		site = *request.Params.SiteId
+	}
+
+	resp, err := c.ListResourcePools(ctx, request.HwMgrId, site)
+	if err != nil {
+		problem, status := getProblemDetails(err)
+		switch status {
+		case http.StatusNotFound:
+			return invserver.GetResourcePools404ApplicationProblemPlusJSONResponse(problem), err
+		case http.StatusServiceUnavailable:
+			return invserver.GetResourcePools503ApplicationProblemPlusJSONResponse(problem), err
+		default:
+			return invserver.GetResourcePools500ApplicationProblemPlusJSONResponse(problem), err
+		}
+	}
+
+	return invserver.GetResourcePools200JSONResponse(resp), nil
+}
+
+// GetResourcePoolCapacity handles an inventory API request for the capacity of the resource pools of a hardware manager
+func (c *HwMgrAdaptorController) GetResourcePoolCapacity(ctx context.Context, request invserver.GetResourcePoolCapacityRequestObject) (invserver.GetResourcePoolCapacityResponseObject, error) {
+	resp, err := c.ListResourcePoolCapacity(ctx, request.HwMgrId)
+	if err != nil {
+		problem, status := getProblemDetails(err)
+		switch status {
+		case http.StatusNotFound:
+			return invserver.GetResourcePoolCapacity404ApplicationProblemPlusJSONResponse(problem), err
+		case http.StatusServiceUnavailable:
+			return invserver.GetResourcePoolCapacity503ApplicationProblemPlusJSONResponse(problem), err
+		default:
+			return invserver.GetResourcePoolCapacity500ApplicationProblemPlusJSONResponse(problem), err
+		}
+	}
+
+	return invserver.GetResourcePoolCapacity200JSONResponse(resp), nil
+}
+
+// GetNodePoolJobs handles an inventory API request for the hardware manager jobs run for a NodePool
+func (c *HwMgrAdaptorController) GetNodePoolJobs(ctx context.Context, request invserver.GetNodePoolJobsRequestObject) (invserver.GetNodePoolJobsResponseObject, error) {
+	resp, err := c.ListNodePoolJobs(ctx, request.HwMgrId, request.NodePoolName)
+	if err != nil {
+		problem, status := getProblemDetails(err)
+		if status == http.StatusNotFound {
+			return invserver.GetNodePoolJobs404ApplicationProblemPlusJSONResponse(problem), err
+		}
+		return invserver.GetNodePoolJobs500ApplicationProblemPlusJSONResponse(problem), err
+	}
+
+	return invserver.GetNodePoolJobs200JSONResponse(resp), nil
+}
+
+// GetResources handles an inventory API request to list the resources of a hardware manager
+func (c *HwMgrAdaptorController) GetResources(ctx context.Context, request invserver.GetResourcesRequestObject) (invserver.GetResourcesResponseObject, error) {
+	if request.Params.Format != nil && *request.Params.Format == invserver.Ndjson {
+		return c.streamResourcesResponse(ctx, request.HwMgrId)
+	}
+
+	resp, err := c.ListResources(ctx, request.HwMgrId)
+	if err != nil {
+		return getResourcesProblemResponse(err)
 	}
 
 	return invserver.GetResources200JSONResponse(resp), nil
 }
+
+// getResourcesProblemResponse returns the response to a failed inventory API request to list resources
+func getResourcesProblemResponse(err error) (invserver.GetResourcesResponseObject, error) {
+	problem, status := getProblemDetails(err)
+	switch status {
+	case http.StatusNotFound:
+		return invserver.GetResources404ApplicationProblemPlusJSONResponse(problem), err
+	case http.StatusServiceUnavailable:
+		return invserver.GetResources503ApplicationProblemPlusJSONResponse(problem), err
+	default:
+		return invserver.GetResources500ApplicationProblemPlusJSONResponse(problem), err
+	}
+}
@@ -11,18 +11,23 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/controller"
 	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 )
@@ -34,6 +39,21 @@ type Adaptor struct {
 	Logger          *slog.Logger
 	Namespace       string
 	AdaptorID       pluginv1alpha1.HardwareManagerAdaptorID
+	inventoryCache  *InventoryCache
+	credentialCache *CredentialCache
+
+	// resourceGroupChecks records the time and result of the last resource group validation of each NodePool, and
+	// healthChecks those of the last hardware health check
+	resourceGroupChecks sync.Map
+	healthChecks        sync.Map
+
+	// resourceGroupGCChecks records the time of the last orphaned resource group check of each HardwareManager, and
+	// orphanedResourceGroups the orphaned resource groups that it found
+	resourceGroupGCChecks  sync.Map
+	orphanedResourceGroups sync.Map
+
+	// jobRecoveryDone is closed once the jobs in flight when the plugin started have been revalidated
+	jobRecoveryDone chan struct{}
 }
 
 func NewAdaptor(client client.Client, noncachedClient client.Reader, scheme *runtime.Scheme, logger *slog.Logger, namespace string) *Adaptor {
@@ -43,9 +63,19 @@ func NewAdaptor(client client.Client, noncachedClient client.Reader, scheme *run
 		Scheme:          scheme,
 		Logger:          logger.With(slog.String("adaptor", "dell-hwmgr")),
 		Namespace:       namespace,
+		inventoryCache:  NewInventoryCache(),
+		credentialCache: NewCredentialCache(),
+		jobRecoveryDone: make(chan struct{}),
 	}
 }
 
+func init() {
+	adaptorinterface.RegisterAdaptor(string(pluginv1alpha1.SupportedAdaptors.Dell),
+		func(client client.Client, noncachedClient client.Reader, scheme *runtime.Scheme, logger *slog.Logger, namespace string) adaptorinterface.HwMgrAdaptorIntf {
+			return NewAdaptor(client, noncachedClient, scheme, logger, namespace)
+		})
+}
+
 // SetupAdaptor sets up the Dell Hardware Manager Adaptor
 func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
 	a.Logger.Info("SetupAdaptor called for DellHwMgr")
@@ -55,10 +85,33 @@ func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
 		Scheme:    a.Scheme,
 		Logger:    a.Logger,
 		Namespace: a.Namespace,
+		// Drop the cached inventory, so it is next fetched with the rotated bundle rather than served until it expires
+		OnCaBundleChanged: a.inventoryCache.remove,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to setup dell-hwmgr adaptor: %w", err)
 	}
 
+	if err := mgr.Add(manager.RunnableFunc(a.recoverInFlightJobs)); err != nil {
+		return fmt.Errorf("unable to setup dell-hwmgr job recovery: %w", err)
+	}
+
+	if err := a.SetupInventory(mgr); err != nil {
+		return err
+	}
+
+	if err := mgr.Add(manager.RunnableFunc(a.runResourceGroupGC)); err != nil {
+		return fmt.Errorf("unable to setup dell-hwmgr resource group GC: %w", err)
+	}
+
+	return nil
+}
+
+// SetupInventory sets up the background refresh of the inventory cache
+func (a *Adaptor) SetupInventory(mgr ctrl.Manager) error {
+	if err := mgr.Add(manager.RunnableFunc(a.runInventoryCacheRefresh)); err != nil {
+		return fmt.Errorf("unable to setup dell-hwmgr inventory cache refresh: %w", err)
+	}
+
 	return nil
 }
 
@@ -71,49 +124,96 @@ const (
 	NodePoolFSMNoop
 )
 
-func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
-	if len(nodepool.Status.Conditions) == 0 {
-		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
-		return NodePoolFSMCreate
+func (action fsmAction) String() string {
+	switch action {
+	case NodePoolFSMCreate:
+		return "create"
+	case NodePoolFSMProcessing:
+		return "processing"
+	case NodePoolFSMSpecChanged:
+		return "specChanged"
+	case NodePoolFSMNoop:
+		return "noop"
 	}
+	return "unknown"
+}
 
+func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
 	provisionedCondition := meta.FindStatusCondition(
 		nodepool.Status.Conditions,
 		string(hwmgmtv1alpha1.Provisioned))
+	if provisionedCondition == nil {
+		// Nothing has been provisioned yet, which includes NodePools that have only been dry run
+		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
+		return NodePoolFSMCreate
+	}
 
-	if provisionedCondition != nil {
-		if provisionedCondition.Status == metav1.ConditionTrue {
-			// Check if the generation has changed
-			if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
-				a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
-				return NodePoolFSMSpecChanged
-			}
-			a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
-			return NodePoolFSMNoop
+	if provisionedCondition.Status == metav1.ConditionTrue {
+		// Check if the generation has changed
+		if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
+			a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
+			return NodePoolFSMSpecChanged
 		}
-
-		if provisionedCondition.Reason == string(hwmgmtv1alpha1.Failed) {
-			a.Logger.InfoContext(ctx, "NodePool request in Failed state")
-			return NodePoolFSMNoop
+		// A configuration update can also be started without a spec change, to apply a node hardware profile override
+		if utils.IsNodePoolConfigUpdateRequested(nodepool) {
+			a.Logger.InfoContext(ctx, "Handling NodePool configuration update")
+			return NodePoolFSMSpecChanged
 		}
+		a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
+		return NodePoolFSMNoop
+	}
 
-		return NodePoolFSMProcessing
+	if utils.IsConditionFailed(provisionedCondition) {
+		a.Logger.InfoContext(ctx, "NodePool request in Failed state")
+		return NodePoolFSMNoop
 	}
 
-	return NodePoolFSMNoop
+	if provisionedCondition.Reason == string(hwmgmtv1alpha1.InvalidInput) {
+		a.Logger.InfoContext(ctx, "NodePool request has invalid input")
+		return NodePoolFSMNoop
+	}
+
+	return NodePoolFSMProcessing
 }
 
 func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	if !a.jobsRecovered() {
+		a.Logger.InfoContext(ctx, "Waiting for in-flight job recovery")
+		return utils.RequeueWithShortInterval(), nil
+	}
+
 	result := utils.DoNotRequeue()
 
 	hwmgrClient, clientErr := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
+	if retryAfter, isOpen := typederrors.GetCircuitOpenRetryAfter(clientErr); isOpen {
+		// Wait out the cooldown rather than backing off on errors, which would delay the NodePool beyond it
+		a.Logger.InfoContext(ctx, "Hardware manager circuit breaker open, deferring NodePool",
+			slog.String("retryAfter", retryAfter.String()))
+		return utils.RequeueWithCustomInterval(retryAfter), nil
+	}
 	if clientErr != nil {
 		// TODO: Improve client error handling to distinguish between connectivity errors, auth, etc
 		a.Logger.InfoContext(ctx, "NewClientWithResponses error", slog.String("error", clientErr.Error()))
 		return result, fmt.Errorf("failed to setup hwmgr client: %w", clientErr)
 	}
 
-	switch a.determineAction(ctx, nodepool) {
+	hwmgrClient, err := a.getNodePoolClient(ctx, hwmgrClient, hwmgr, nodepool)
+	if err != nil {
+		if typederrors.IsInputError(err) {
+			if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool, hwmgmtv1alpha1.Provisioned,
+				hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse, err.Error()); err != nil {
+				return utils.RequeueWithMediumInterval(),
+					fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	action := a.determineAction(ctx, nodepool)
+	tracing.SetAttributes(ctx, tracing.AttrNodePoolPhase.String(action.String()))
+
+	switch action {
 	case NodePoolFSMCreate:
 		return a.HandleNodePoolCreate(ctx, hwmgrClient, hwmgr, nodepool)
 	case NodePoolFSMProcessing:
@@ -121,8 +221,9 @@ func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.Hard
 	case NodePoolFSMSpecChanged:
 		return a.HandleNodePoolSpecChanged(ctx, hwmgrClient, hwmgr, nodepool)
 	case NodePoolFSMNoop:
-		// Nothing to do
-		return result, nil
+		// Nothing to do, other than removing nodes and periodically re-validating the resource group of a provisioned
+		// NodePool and polling the health of its servers
+		return a.checkProvisionedNodePool(ctx, hwmgrClient, hwmgr, nodepool)
 	}
 
 	return result, nil
@@ -130,6 +231,13 @@ func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.Hard
 
 func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
 	a.Logger.InfoContext(ctx, "Finalizing nodepool")
+	a.resourceGroupChecks.Delete(client.ObjectKeyFromObject(nodepool).String())
+	a.healthChecks.Delete(client.ObjectKeyFromObject(nodepool).String())
+
+	if !a.jobsRecovered() {
+		a.Logger.InfoContext(ctx, "Waiting for in-flight job recovery")
+		return false, nil
+	}
 
 	hwmgrClient, clientErr := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
 	if clientErr != nil {
@@ -137,6 +245,7 @@ func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alp
 		a.Logger.InfoContext(ctx, "NewClientWithResponses error", slog.String("error", clientErr.Error()))
 		return false, fmt.Errorf("failed to setup hwmgr client: %w", clientErr)
 	}
+	hwmgrClient = hwmgrClient.WithTenant(nodepool.GetAnnotations()[NodePoolTenantAnnotation])
 
 	if exists, err := hwmgrClient.ResourceGroupExists(ctx, nodepool); err != nil {
 		return false, fmt.Errorf("resource group existence check failed for cloudID=%s: err: %w", nodepool.Spec.CloudID, err)
@@ -154,7 +263,40 @@ func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alp
 	return completed, nil
 }
 
+// GetResourcePools returns the resource pools of the HardwareManager, from the inventory cache where possible
 func (a *Adaptor) GetResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourcePoolInfo, int, error) {
+	inventory, status, err := a.getInventory(ctx, hwmgr)
+	if err != nil {
+		return nil, status, err
+	}
+	return inventory.pools, http.StatusOK, nil
+}
+
+// GetResources returns the resources of the HardwareManager, from the inventory cache where possible
+func (a *Adaptor) GetResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, int, error) {
+	inventory, status, err := a.getInventory(ctx, hwmgr)
+	if err != nil {
+		return nil, status, err
+	}
+	return inventory.resources, http.StatusOK, nil
+}
+
+// StreamResources passes each resource of the HardwareManager to emit, from the inventory cache where possible
+func (a *Adaptor) StreamResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, emit func(invserver.ResourceInfo) error) (int, error) {
+	inventory, status, err := a.getInventory(ctx, hwmgr)
+	if err != nil {
+		return status, err
+	}
+	for _, resource := range inventory.resources {
+		if err := emit(resource); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// fetchResourcePools queries the hardware manager for its resource pools
+func (a *Adaptor) fetchResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourcePoolInfo, int, error) {
 	var resp []invserver.ResourcePoolInfo
 
 	client, err := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
@@ -181,45 +323,58 @@ func (a *Adaptor) GetResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.Ha
 	return resp, http.StatusOK, nil
 }
 
-func (a *Adaptor) GetResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, int, error) {
+// fetchResources queries the hardware manager for its resources and the inventory of the corresponding servers,
+// returning the inventory data of each resource along with its server, by resource ID
+func (a *Adaptor) fetchResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, map[string]*hwmgrapi.ApiprotoServer, int, error) {
 	var resp []invserver.ResourceInfo
 
 	client, err := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
 	if err != nil {
 		// TODO: Expose status errors from client
 		a.Logger.InfoContext(ctx, "NewClientWithResponses error", slog.String("error", err.Error()))
-		return resp, http.StatusInternalServerError, fmt.Errorf("unable to create hwmgr client: %w", err)
+		return resp, nil, http.StatusInternalServerError, fmt.Errorf("unable to create hwmgr client: %w", err)
 	}
 
 	resources, err := client.GetResources(ctx)
 	if err != nil {
 		a.Logger.InfoContext(ctx, "GetResources error", slog.String("error", err.Error()))
-		return resp, http.StatusInternalServerError, fmt.Errorf("unable to query resources: %w", err)
+		return resp, nil, http.StatusInternalServerError, fmt.Errorf("unable to query resources: %w", err)
 	}
 
 	servers, err := client.GetServersInventory(ctx)
 	if err != nil {
 		a.Logger.InfoContext(ctx, "GetServersInventory error", slog.String("error", err.Error()))
-		return resp, http.StatusInternalServerError, fmt.Errorf("unable to query server inventory: %w", err)
+		return resp, nil, http.StatusInternalServerError, fmt.Errorf("unable to query server inventory: %w", err)
+	}
+
+	allocations, err := a.getResourceAllocations(ctx, client, hwmgr)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "getResourceAllocations error", slog.String("error", err.Error()))
+		return resp, nil, http.StatusInternalServerError, fmt.Errorf("unable to query resource allocations: %w", err)
+	}
+
+	resourceServers, unmatched := indexServersByResource(resources, servers)
+	inventoryUnmatchedResources.WithLabelValues(hwmgr.Name).Set(float64(len(unmatched)))
+	if len(unmatched) > 0 {
+		a.Logger.WarnContext(ctx, "Unable to find server info for resources, omitting them from the inventory",
+			slog.Any("resources", unmatched))
 	}
 
 	for _, resource := range *resources.Resources {
-		var server *hwmgrapi.ApiprotoServer
-		for _, iter := range *servers.Servers {
-			if resource.Name == nil || iter.Metadata.Name == nil || *resource.Name != *iter.Metadata.Name {
-				continue
-			}
-			server = &iter
+		if resource.Id == nil {
+			continue
 		}
-
-		if server == nil {
-			a.Logger.InfoContext(ctx, "Unable to find server info for resource. Skipping",
-				slog.String("resource-name", *resource.Name))
+		server, exists := resourceServers[*resource.Id]
+		if !exists {
 			continue
 		}
 
-		resp = append(resp, getResourceInfo(resource, server))
+		var allocation *utils.ResourceAllocation
+		if entry, exists := allocations[*resource.Id]; exists {
+			allocation = &entry
+		}
+		resp = append(resp, getResourceInfo(resource, server, allocation))
 	}
 
-	return resp, http.StatusOK, nil
+	return resp, resourceServers, http.StatusOK, nil
 }
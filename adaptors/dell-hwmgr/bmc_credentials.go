@@ -0,0 +1,99 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RotateBMCCredentials requests the hardware manager to set new BMC credentials on the resource backing a node. The
+// job ID is tracked in an annotation on the node, tagged with the credentials revision, and the bmc-secret is updated
+// once the job completes.
+func (a *Adaptor) RotateBMCCredentials(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	credentials *corev1.Secret) (bool, error) {
+
+	username, password, err := utils.GetBmcCredentials(credentials)
+	if err != nil {
+		return false, fmt.Errorf("invalid BMC credentials secret %s: %w", credentials.Name, err)
+	}
+
+	hwmgrClient, err := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
+	if err != nil {
+		return false, fmt.Errorf("failed to create hwmgr client: %w", err)
+	}
+	if hwmgrClient, err = a.getNodeClient(ctx, hwmgrClient, node); err != nil {
+		return false, err
+	}
+
+	revision := utils.GetBmcCredentialsRevision(credentials)
+	jobRevision, jobId, _ := strings.Cut(node.Annotations[utils.BmcCredentialsJobAnnotation], ";")
+	if jobId == "" || jobRevision != revision {
+		a.Logger.InfoContext(ctx, "Requesting BMC credentials update", slog.String("revision", revision))
+		jobId, err = hwmgrClient.UpdateResourceBMCCredentials(ctx, node, username, password)
+		if err != nil {
+			return false, fmt.Errorf("failed to update BMC credentials for node %s: %w", node.Name, err)
+		}
+
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.BmcCredentialsJobAnnotation, revision+";"+jobId); err != nil {
+			return false, fmt.Errorf("failed to record BMC credentials job for node %s: %w", node.Name, err)
+		}
+		return false, nil
+	}
+
+	status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	if err != nil {
+		return false, fmt.Errorf("BMC credentials job progress check failed: %w", err)
+	}
+
+	switch status {
+	case hwmgrclient.JobStatusInProgress:
+		a.Logger.InfoContext(ctx, "BMC credentials job is in progress", slog.String("jobId", jobId))
+		return false, nil
+	case hwmgrclient.JobStatusCompleted:
+		a.Logger.InfoContext(ctx, "BMC credentials job has completed", slog.String("jobId", jobId))
+	case hwmgrclient.JobStatusFailed:
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.BmcCredentialsJobAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear BMC credentials job for node %s: %w", node.Name, err)
+		}
+		return false, fmt.Errorf("BMC credentials job %s failed: %s", jobId, a.classifyFailReason(ctx, hwmgr, failReason))
+	case hwmgrclient.JobStatusNotExist:
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.BmcCredentialsJobAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear BMC credentials job for node %s: %w", node.Name, err)
+		}
+		return false, fmt.Errorf("BMC credentials job %s no longer exists on hardware manager", jobId)
+	default:
+		a.Logger.InfoContext(ctx, "BMC credentials job check returned unknown status",
+			slog.Any("status", status), slog.String("failReason", failReason))
+		return false, nil
+	}
+
+	backend, err := utils.NewBmcSecretBackend(a.Client, hwmgr)
+	if err != nil {
+		return false, fmt.Errorf("invalid BMC secret backend: %w", err)
+	}
+	if err := backend.UpdateBmcSecretCredentials(ctx, utils.GetNodeBmcSecret(node), username, password); err != nil {
+		return false, fmt.Errorf("failed to update bmc-secret for node %s: %w", node.Name, err)
+	}
+
+	if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.BmcCredentialsJobAnnotation, ""); err != nil {
+		return false, fmt.Errorf("failed to clear BMC credentials job for node %s: %w", node.Name, err)
+	}
+
+	return true, nil
+}
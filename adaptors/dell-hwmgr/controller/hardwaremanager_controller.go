@@ -11,17 +11,27 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"time"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 )
@@ -33,10 +43,18 @@ type HardwareManagerReconciler struct {
 	Logger    *slog.Logger
 	Namespace string
 	AdaptorID pluginv1alpha1.HardwareManagerAdaptorID
+
+	// OnCaBundleChanged, if set, is called with the name of each HardwareManager whose CA bundle configmap has
+	// changed, so that any state derived from connections using the previous bundle can be dropped
+	OnCaBundleChanged func(name string)
+
+	// circuitEvents triggers a reconcile of a HardwareManager when the circuit breaker on its API calls changes state
+	circuitEvents chan event.GenericEvent
 }
 
 //+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get
 //+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/finalizers,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -46,13 +64,17 @@ type HardwareManagerReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
 	result = utils.DoNotRequeue()
 
 	// Fetch the CR:
 	hwmgr := &pluginv1alpha1.HardwareManager{}
 	if err = r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
 		if errors.IsNotFound(err) {
-			// The NodePool has likely been deleted
+			// The HardwareManager has likely been deleted, so its circuit breaker and client certificate are no longer
+			// needed
+			hwmgrclient.RemoveCircuitBreaker(req.NamespacedName)
+			hwmgrclient.RemoveClientCert(req.NamespacedName)
 			err = nil
 			return
 		}
@@ -70,7 +92,7 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return
 	}
 
-	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+	ctx = logging.WithHwMgr(ctx, hwmgr.Name)
 
 	hwmgr.Status.ObservedGeneration = hwmgr.Generation
 
@@ -88,11 +110,69 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return
 	}
 
+	if _, _, windowErr := utils.GetMaintenanceWindowStatus(hwmgr, time.Now()); windowErr != nil {
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			windowErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "HardwareManager CR has invalid maintenance window", slog.String("name", hwmgr.Name),
+			slog.String("error", windowErr.Error()))
+		return
+	}
+
+	if _, proxyErr := utils.GetProxyFunc(hwmgr.Spec.Proxy); proxyErr != nil {
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			proxyErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "HardwareManager CR has invalid proxy configuration", slog.String("name", hwmgr.Name),
+			slog.String("error", proxyErr.Error()))
+		return
+	}
+
+	if _, backendErr := utils.NewBmcSecretBackend(r.Client, hwmgr); backendErr != nil {
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			backendErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "HardwareManager CR has invalid BMC secret backend", slog.String("name", hwmgr.Name),
+			slog.String("error", backendErr.Error()))
+		return
+	}
+
+	circuitState, retryAfter := hwmgrclient.GetCircuitState(hwmgr)
+	if retryAfter > 0 {
+		// Leave the Validation condition as is, as the hardware manager is not being called while the circuit is open
+		result, err = r.setCircuitOpenCondition(ctx, hwmgr, retryAfter)
+		return
+	}
+	if circuitState == hwmgrclient.CircuitClosed {
+		if err = r.clearCircuitOpenCondition(ctx, hwmgr); err != nil {
+			return
+		}
+	}
+
 	result = utils.RequeueWithLongInterval()
 
 	r.Logger.InfoContext(ctx, "Validating client connection", slog.String("apiUrl", hwmgr.Spec.DellData.ApiUrl))
 
 	client, clientErr := hwmgrclient.NewClientWithResponses(ctx, r.Logger, r.Client, hwmgr)
+	if retryAfter, isOpen := typederrors.GetCircuitOpenRetryAfter(clientErr); isOpen {
+		result, err = r.setCircuitOpenCondition(ctx, hwmgr, retryAfter)
+		return
+	}
 	if clientErr != nil {
 		r.Logger.InfoContext(ctx, "NewClientWithResponses error", slog.String("error", clientErr.Error()))
 		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
@@ -108,6 +188,10 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	pools, clientErr := client.GetResourcePools(ctx)
+	if retryAfter, isOpen := typederrors.GetCircuitOpenRetryAfter(clientErr); isOpen {
+		result, err = r.setCircuitOpenCondition(ctx, hwmgr, retryAfter)
+		return
+	}
 	if clientErr != nil {
 		r.Logger.InfoContext(ctx, "GetResourcePools error", slog.String("error", clientErr.Error()))
 		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
@@ -156,6 +240,59 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	return
 }
 
+// setCircuitOpenCondition marks the HardwareManager as degraded while the circuit breaker on its API calls is open,
+// requeuing for when a trial call is allowed
+func (r *HardwareManagerReconciler) setCircuitOpenCondition(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	retryAfter time.Duration) (ctrl.Result, error) {
+
+	message := fmt.Sprintf("Circuit breaker open after consecutive failed calls to the hardware manager, retrying in %s",
+		retryAfter.Round(time.Second))
+	r.Logger.InfoContext(ctx, "Hardware manager circuit breaker open", slog.String("retryAfter", retryAfter.String()))
+
+	if err := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+		pluginv1alpha1.ConditionTypes.Degraded,
+		pluginv1alpha1.ConditionReasons.CircuitOpen,
+		metav1.ConditionTrue,
+		message); err != nil {
+		return utils.RequeueWithShortInterval(),
+			fmt.Errorf("failed to update status for hardware manager (%s) with open circuit: %w", hwmgr.Name, err)
+	}
+
+	return utils.RequeueWithCustomInterval(retryAfter), nil
+}
+
+// clearCircuitOpenCondition clears the Degraded condition set by setCircuitOpenCondition once the circuit has closed
+func (r *HardwareManagerReconciler) clearCircuitOpenCondition(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) error {
+	condition := meta.FindStatusCondition(hwmgr.Status.Conditions, string(pluginv1alpha1.ConditionTypes.Degraded))
+	if condition == nil || condition.Status != metav1.ConditionTrue ||
+		condition.Reason != string(pluginv1alpha1.ConditionReasons.CircuitOpen) {
+		return nil
+	}
+
+	if err := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+		pluginv1alpha1.ConditionTypes.Degraded,
+		pluginv1alpha1.ConditionReasons.Completed,
+		metav1.ConditionFalse,
+		"Circuit breaker closed"); err != nil {
+		return fmt.Errorf("failed to update status for hardware manager (%s) with closed circuit: %w", hwmgr.Name, err)
+	}
+	return nil
+}
+
+// onCircuitStateChange queues a reconcile of a HardwareManager whose circuit breaker has changed state, to update its
+// Degraded condition. It does not block the hardware manager call that changed the state, dropping the event if the
+// queue is full, as the periodic reconcile will catch up.
+func (r *HardwareManagerReconciler) onCircuitStateChange(key types.NamespacedName, state hwmgrclient.CircuitState) {
+	r.Logger.Info("Hardware manager circuit breaker state changed", slog.String("hwmgr", key.Name),
+		slog.String("state", state.String()))
+
+	hwmgr := &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	select {
+	case r.circuitEvents <- event.GenericEvent{Object: hwmgr}:
+	default:
+	}
+}
+
 func filterEvents(adaptorID pluginv1alpha1.HardwareManagerAdaptorID) predicate.Predicate {
 	return predicate.NewPredicateFuncs(func(object client.Object) bool {
 		hwmgr := object.(*pluginv1alpha1.HardwareManager)
@@ -163,15 +300,53 @@ func filterEvents(adaptorID pluginv1alpha1.HardwareManagerAdaptorID) predicate.P
 	})
 }
 
+// caBundleToHardwareManagers maps a configmap to the HardwareManagers that reference it as their CA bundle, so that
+// they are revalidated with the rotated bundle rather than at the next periodic reconcile
+func (r *HardwareManagerReconciler) caBundleToHardwareManagers(ctx context.Context, object client.Object) []reconcile.Request {
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := r.Client.List(ctx, hwmgrs, client.InNamespace(object.GetNamespace())); err != nil {
+		r.Logger.ErrorContext(ctx, "Failed to list HardwareManagers", slog.String("error", err.Error()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, hwmgr := range hwmgrs.Items {
+		if hwmgr.Spec.AdaptorID != r.AdaptorID || hwmgr.Spec.DellData == nil ||
+			hwmgr.Spec.DellData.CaBundleName == nil || *hwmgr.Spec.DellData.CaBundleName != object.GetName() {
+			continue
+		}
+
+		r.Logger.InfoContext(ctx, "CA bundle changed, revalidating HardwareManager",
+			slog.String("hwmgr", hwmgr.Name), slog.String("configmap", object.GetName()))
+		if r.OnCaBundleChanged != nil {
+			r.OnCaBundleChanged(hwmgr.Name)
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&hwmgr)})
+	}
+
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *HardwareManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.AdaptorID = pluginv1alpha1.SupportedAdaptors.Dell
 	r.Logger.Info("Setting up Dell controller", slog.String("adaptorId", string(r.AdaptorID)))
+
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	r.circuitEvents = make(chan event.GenericEvent, 100)
+	hwmgrclient.SetCircuitStateChangeHandler(r.onCircuitStateChange)
+
 	if err := ctrl.NewControllerManagedBy(mgr).
 		Named(string(r.AdaptorID)).
-		For(&pluginv1alpha1.HardwareManager{}).
-		WithEventFilter(filterEvents(r.AdaptorID)).
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{})).
+		For(&pluginv1alpha1.HardwareManager{}, builder.WithPredicates(
+			filterEvents(r.AdaptorID),
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.caBundleToHardwareManagers),
+			builder.WithPredicates(inNamespace, predicate.ResourceVersionChangedPredicate{})).
+		WatchesRawSource(source.Channel(r.circuitEvents, &handler.EnqueueRequestForObject{})).
 		Complete(r); err != nil {
 		return fmt.Errorf("failed to setup controller for %s: %w", r.AdaptorID, err)
 	}
@@ -0,0 +1,138 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// DefaultCredentialVaultCacheTTL is how long credentials fetched from the credential vault are cached by default
+const DefaultCredentialVaultCacheTTL = 5 * time.Minute
+
+// credentialCacheEntry holds the BMC credentials fetched from a secret of the hardware manager
+type credentialCacheEntry struct {
+	username  []byte
+	password  []byte
+	expiresAt time.Time
+}
+
+// CredentialCache holds the BMC credentials fetched from the credential vault of each HardwareManager, so that the
+// secret of a resource is fetched once while its credentials are needed
+type CredentialCache struct {
+	mu      sync.Mutex
+	entries map[string]credentialCacheEntry
+}
+
+func NewCredentialCache() *CredentialCache {
+	return &CredentialCache{
+		entries: make(map[string]credentialCacheEntry),
+	}
+}
+
+// get returns the cached credentials for the key, if they have not expired
+func (c *CredentialCache) get(key string) ([]byte, []byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return nil, nil, false
+	}
+	return entry.username, entry.password, true
+}
+
+// set caches the credentials for the key, dropping any expired entries so that the credentials of released resources
+// are not kept
+func (c *CredentialCache) set(key string, username, password []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = credentialCacheEntry{username: username, password: password, expiresAt: now.Add(ttl)}
+}
+
+// isCredentialVaultEnabled checks whether the BMC credentials of resources are resolved from the credential vault
+func isCredentialVaultEnabled(hwmgr *pluginv1alpha1.HardwareManager) bool {
+	return hwmgr.Spec.DellData != nil && hwmgr.Spec.DellData.CredentialVault != nil
+}
+
+// getCredentialVaultCacheTTL returns how long credentials fetched from the credential vault are cached
+func getCredentialVaultCacheTTL(hwmgr *pluginv1alpha1.HardwareManager) time.Duration {
+	vault := hwmgr.Spec.DellData.CredentialVault
+	if vault.CacheTTL == nil || vault.CacheTTL.Duration <= 0 {
+		return DefaultCredentialVaultCacheTTL
+	}
+	return vault.CacheTTL.Duration
+}
+
+// getBMCSecretKey returns the key of the secret on the hardware manager that holds the BMC credentials of a resource,
+// which is named by the credential vault prefix and the resource ID when a credential vault is configured, and
+// otherwise referenced by the password field of the resource
+func getBMCSecretKey(hwmgr *pluginv1alpha1.HardwareManager, resource hwmgrapi.RhprotoResource) (string, error) {
+	if isCredentialVaultEnabled(hwmgr) {
+		if resource.Id == nil || *resource.Id == "" {
+			return "", fmt.Errorf("resource has no ID to resolve its BMC credentials from the credential vault")
+		}
+		return hwmgr.Spec.DellData.CredentialVault.Prefix + *resource.Id, nil
+	}
+
+	if resource.ResourceAttribute == nil || resource.ResourceAttribute.Compute == nil ||
+		resource.ResourceAttribute.Compute.Lom == nil || resource.ResourceAttribute.Compute.Lom.Password == nil {
+		return "", fmt.Errorf("resource has no BMC credentials reference")
+	}
+	return *resource.ResourceAttribute.Compute.Lom.Password, nil
+}
+
+// fetchBMCCredentials fetches the BMC credentials held by a secret on the hardware manager. Credentials resolved from
+// the credential vault are cached, per HardwareManager and tenant, for the cache TTL of the vault.
+func (a *Adaptor) fetchBMCCredentials(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	secretKey string) ([]byte, []byte, error) {
+
+	cacheKey := ""
+	if isCredentialVaultEnabled(hwmgr) {
+		cacheKey = fmt.Sprintf("%s/%s/%s", hwmgr.Name, hwmgrClient.GetTenant(), secretKey)
+		if username, password, cached := a.credentialCache.get(cacheKey); cached {
+			return username, password, nil
+		}
+	}
+
+	remoteSecret, err := hwmgrClient.GetSecret(ctx, secretKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve BMC credentials (%s): %w", secretKey, err)
+	}
+
+	if remoteSecret == nil || remoteSecret.Secret == nil || remoteSecret.Secret.Value == nil {
+		return nil, nil, fmt.Errorf("no BMC credentials in secret (%s)", secretKey)
+	}
+
+	creds := BMCCredentials{}
+	if err := json.Unmarshal([]byte(*remoteSecret.Secret.Value), &creds); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse BMC credentials (%s)", secretKey)
+	}
+
+	if cacheKey != "" {
+		a.credentialCache.set(cacheKey, []byte(creds.Username), []byte(creds.Password), getCredentialVaultCacheTTL(hwmgr))
+	}
+
+	return []byte(creds.Username), []byte(creds.Password), nil
+}
@@ -0,0 +1,145 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+const (
+	// failReasonRulesKey is the field of the fail reason rules config map that holds the rules, as a YAML list
+	failReasonRulesKey = "rules"
+
+	// FailCodeUnknown is the code of a fail reason that matches no rule
+	FailCodeUnknown = "Unknown"
+)
+
+// failReasonRule classifies the fail reasons that match its pattern under a code, with a message telling the user how
+// to address the failure
+type failReasonRule struct {
+	Code    string `json:"code"`
+	Pattern string `json:"pattern"`
+	Message string `json:"message"`
+
+	re *regexp.Regexp
+}
+
+// defaultFailReasonRules cover the common failures reported by the hardware manager, and are matched in order
+var defaultFailReasonRules = []failReasonRule{
+	{
+		Code:    "InsufficientResources",
+		Pattern: `(?i)(insufficient|not enough|no)( (available|free|matching))? (resources|servers|nodes|capacity)`,
+		Message: "The resource pool does not have enough free servers matching the node groups; free up servers in the resource pool or reduce the size of the node groups",
+	},
+	{
+		Code:    "ResourcePoolNotFound",
+		Pattern: `(?i)resource ?pool.*(not found|does not exist|unknown)`,
+		Message: "A resource pool of the node groups does not exist on the hardware manager; check the resourcePoolId of each node group",
+	},
+	{
+		Code:    "ProfileNotFound",
+		Pattern: `(?i)(profile|template).*(not found|does not exist|unknown)`,
+		Message: "A hardware profile of the node groups does not exist on the hardware manager; check the hwProfile of each node group",
+	},
+	{
+		Code:    "Unauthorized",
+		Pattern: `(?i)(unauthori[sz]ed|forbidden|permission denied|access denied)`,
+		Message: "The hardware manager rejected the request as unauthorized; check the credentials in the authSecret and the tenant of the HardwareManager",
+	},
+	{
+		Code:    "BMCUnreachable",
+		Pattern: `(?i)(bmc|idrac|redfish).*(unreachable|unavailable|connection refused|no route|not responding)`,
+		Message: "The hardware manager could not reach the BMC of a server; check the network connectivity and power of the server",
+	},
+	{
+		Code:    "FirmwareUpdateFailed",
+		Pattern: `(?i)(firmware|bios).*(fail|error)`,
+		Message: "A firmware or BIOS update failed on a server; check that the firmware images of the hardware profile are valid for the server model",
+	},
+	{
+		Code:    "OperationTimedOut",
+		Pattern: `(?i)(timed? ?out|deadline exceeded)`,
+		Message: "The hardware manager timed out operating on a server; check the state of the server on the hardware manager before retrying",
+	},
+}
+
+func init() {
+	for i := range defaultFailReasonRules {
+		defaultFailReasonRules[i].re = regexp.MustCompile(defaultFailReasonRules[i].Pattern)
+	}
+}
+
+// jobFailure is the classification of the reason given by the hardware manager for a failed job
+type jobFailure struct {
+	Code    string
+	Message string
+	Reason  string
+}
+
+// String describes the failure for conditions and events, leading with its code and actionable message, and keeping
+// the fail reason as reported by the hardware manager
+func (f jobFailure) String() string {
+	if f.Message == "" {
+		return f.Reason
+	}
+	return fmt.Sprintf("%s: %s (hardware manager reported: %s)", f.Code, f.Message, f.Reason)
+}
+
+// getFailReasonRules gets the rules of the config map referenced by the HardwareManager, if any
+func (a *Adaptor) getFailReasonRules(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]failReasonRule, error) {
+	if hwmgr.Spec.DellData == nil || hwmgr.Spec.DellData.FailReasonRulesName == nil {
+		return nil, nil
+	}
+
+	cm, err := utils.GetConfigmap(ctx, a.Client, *hwmgr.Spec.DellData.FailReasonRulesName, hwmgr.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configmap: %w", err)
+	}
+
+	rules, err := utils.ExtractDataFromConfigMap[[]failReasonRule](cm, failReasonRulesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fail reason rules from configmap: %w", err)
+	}
+
+	for i := range rules {
+		if rules[i].Code == "" || rules[i].Pattern == "" || rules[i].Message == "" {
+			return nil, fmt.Errorf("fail reason rule %d of configmap %s must have a code, pattern, and message", i, cm.Name)
+		}
+		if rules[i].re, err = regexp.Compile(rules[i].Pattern); err != nil {
+			return nil, fmt.Errorf("invalid pattern of fail reason rule %s in configmap %s: %w", rules[i].Code, cm.Name, err)
+		}
+	}
+
+	return rules, nil
+}
+
+// classifyFailReason matches the fail reason of a job against the rules of the HardwareManager, then the built-in
+// rules. The rules only improve the reporting of a failure, so rules that cannot be read are logged and skipped.
+func (a *Adaptor) classifyFailReason(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, failReason string) jobFailure {
+	if failReason == "" {
+		return jobFailure{}
+	}
+
+	rules, err := a.getFailReasonRules(ctx, hwmgr)
+	if err != nil {
+		a.Logger.WarnContext(ctx, "Ignoring fail reason rules of HardwareManager", slog.String("error", err.Error()))
+	}
+
+	for _, rule := range append(rules, defaultFailReasonRules...) {
+		if rule.re.MatchString(failReason) {
+			return jobFailure{Code: rule.Code, Message: rule.Message, Reason: failReason}
+		}
+	}
+
+	return jobFailure{Code: FailCodeUnknown, Reason: failReason}
+}
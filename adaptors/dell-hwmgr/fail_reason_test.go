@@ -0,0 +1,210 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+const testNamespace = "oran-hwmgr-plugin"
+
+func newTestAdaptor(t *testing.T, objects ...client.Object) *Adaptor {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := pluginv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	return &Adaptor{Client: c, NoncachedClient: c, Scheme: scheme, Logger: slog.Default(), Namespace: testNamespace}
+}
+
+// newFailReasonTestHwMgr returns a HardwareManager referencing the named fail reason rules config map, if any
+func newFailReasonTestHwMgr(rulesName string) *pluginv1alpha1.HardwareManager {
+	hwmgr := &pluginv1alpha1.HardwareManager{
+		ObjectMeta: metav1.ObjectMeta{Name: "dell-1", Namespace: testNamespace},
+		Spec: pluginv1alpha1.HardwareManagerSpec{
+			DellData: &pluginv1alpha1.DellData{},
+		},
+	}
+	if rulesName != "" {
+		hwmgr.Spec.DellData.FailReasonRulesName = &rulesName
+	}
+	return hwmgr
+}
+
+func newFailReasonRulesConfigMap(name, rules string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Data:       map[string]string{failReasonRulesKey: rules},
+	}
+}
+
+func TestClassifyFailReason(t *testing.T) {
+	customRules := newFailReasonRulesConfigMap("custom-rules", `
+- code: LicenseExpired
+  pattern: (?i)license.*expired
+  message: Renew the license of the hardware manager
+- code: SiteMaintenance
+  pattern: (?i)insufficient free resources.*site-b
+  message: Site B is under maintenance; allocate from another site
+`)
+	invalidRules := newFailReasonRulesConfigMap("invalid-rules", `
+- code: Broken
+  pattern: (unclosed
+  message: Never matches
+`)
+	incompleteRules := newFailReasonRulesConfigMap("incomplete-rules", `
+- code: NoMessage
+  pattern: .*
+`)
+
+	tests := []struct {
+		description string
+		rulesName   string
+		failReason  string
+		expected    string
+	}{
+		{description: "no fail reason", failReason: "", expected: ""},
+
+		// Reasons reported by the hardware manager, as returned by the fake server and shown in the README
+		{
+			description: "insufficient free resources for a resource selector",
+			failReason:  "insufficient free resources for resource selector worker: requested 3, found 1",
+			expected:    "InsufficientResources",
+		},
+		{
+			description: "no servers available in the pool",
+			failReason:  "no servers available in pool",
+			expected:    "InsufficientResources",
+		},
+		{
+			description: "resource group already exists",
+			failReason:  "resource group np1-8f21d4c7 already exists",
+			expected:    FailCodeUnknown,
+		},
+		{
+			description: "resource pool not found",
+			failReason:  "resource pool pool-7 not found",
+			expected:    "ResourcePoolNotFound",
+		},
+
+		{description: "not enough nodes", failReason: "Not enough nodes in resource pool pool-7", expected: "InsufficientResources"},
+		{description: "no matching servers", failReason: "no matching servers for profile", expected: "InsufficientResources"},
+		{description: "resource pool does not exist", failReason: "ResourcePool pool-7 does not exist", expected: "ResourcePoolNotFound"},
+		{description: "profile not found", failReason: "hardware profile profile-spr-v2 not found", expected: "ProfileNotFound"},
+		{description: "template unknown", failReason: "template bios-settings-v3 unknown", expected: "ProfileNotFound"},
+		{description: "unauthorized", failReason: "401 Unauthorized: token expired", expected: "Unauthorized"},
+		{description: "access denied", failReason: "Access denied for tenant default", expected: "Unauthorized"},
+		{
+			description: "BMC unreachable",
+			failReason:  "iDRAC 192.0.2.10 is unreachable: connection refused",
+			expected:    "BMCUnreachable",
+		},
+		{
+			description: "firmware update failed",
+			failReason:  "BIOS update job JID_123 failed with error SUP029",
+			expected:    "FirmwareUpdateFailed",
+		},
+		{description: "timed out", failReason: "operation timed out waiting for server power on", expected: "OperationTimedOut"},
+		{description: "deadline exceeded", failReason: "context deadline exceeded", expected: "OperationTimedOut"},
+		{description: "unknown reason", failReason: "unexpected internal error 0x2f", expected: FailCodeUnknown},
+
+		{
+			description: "custom rule",
+			rulesName:   customRules.Name,
+			failReason:  "license for OpenManage Enterprise expired",
+			expected:    "LicenseExpired",
+		},
+		{
+			description: "custom rule takes precedence over built-in rules",
+			rulesName:   customRules.Name,
+			failReason:  "insufficient free resources for resource selector site-b-worker: requested 3, found 1",
+			expected:    "SiteMaintenance",
+		},
+		{
+			description: "built-in rules apply with custom rules",
+			rulesName:   customRules.Name,
+			failReason:  "insufficient free resources for resource selector worker: requested 3, found 1",
+			expected:    "InsufficientResources",
+		},
+		{
+			description: "invalid custom pattern is skipped",
+			rulesName:   invalidRules.Name,
+			failReason:  "no servers available in pool",
+			expected:    "InsufficientResources",
+		},
+		{
+			description: "incomplete custom rule is skipped",
+			rulesName:   incompleteRules.Name,
+			failReason:  "unexpected internal error 0x2f",
+			expected:    FailCodeUnknown,
+		},
+		{
+			description: "missing config map is skipped",
+			rulesName:   "missing-rules",
+			failReason:  "context deadline exceeded",
+			expected:    "OperationTimedOut",
+		},
+	}
+
+	a := newTestAdaptor(t, customRules, invalidRules, incompleteRules)
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			failure := a.classifyFailReason(context.Background(), newFailReasonTestHwMgr(test.rulesName), test.failReason)
+			if failure.Code != test.expected {
+				t.Errorf("expected code %q, got %q", test.expected, failure.Code)
+			}
+			if failure.Reason != test.failReason {
+				t.Errorf("expected the fail reason to be kept, got %q", failure.Reason)
+			}
+			if (failure.Message == "") != (test.expected == "" || test.expected == FailCodeUnknown) {
+				t.Errorf("unexpected message %q for code %q", failure.Message, failure.Code)
+			}
+		})
+	}
+}
+
+func TestJobFailureString(t *testing.T) {
+	tests := []struct {
+		description string
+		failure     jobFailure
+		expected    string
+	}{
+		{
+			description: "classified",
+			failure:     jobFailure{Code: "InsufficientResources", Message: "Free up servers", Reason: "no servers available in pool"},
+			expected:    "InsufficientResources: Free up servers (hardware manager reported: no servers available in pool)",
+		},
+		{
+			description: "unknown",
+			failure:     jobFailure{Code: FailCodeUnknown, Reason: "unexpected internal error 0x2f"},
+			expected:    "unexpected internal error 0x2f",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if actual := test.failure.String(); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
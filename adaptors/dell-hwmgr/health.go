@@ -0,0 +1,150 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultHealthCheckInterval is used when the HardwareManager does not configure the interval
+const defaultHealthCheckInterval = 5 * time.Minute
+
+// serverHealthOK is the health reported by the hardware manager for a server without faults. Servers with faults are
+// reported as Warning or Critical, following the Redfish health states.
+const serverHealthOK = "OK"
+
+// getHealthCheckInterval returns the hardware health check interval configured for the HardwareManager
+func getHealthCheckInterval(hwmgr *pluginv1alpha1.HardwareManager) time.Duration {
+	if hwmgr.Spec.DellData == nil || hwmgr.Spec.DellData.HealthCheckInterval == nil ||
+		hwmgr.Spec.DellData.HealthCheckInterval.Duration <= 0 {
+		return defaultHealthCheckInterval
+	}
+	return hwmgr.Spec.DellData.HealthCheckInterval.Duration
+}
+
+// healthCheck holds the result of the last hardware health check of a NodePool
+type healthCheck struct {
+	checkedAt time.Time
+	faults    []string
+}
+
+// nodeHealth is the Healthy condition of a Node, derived from the health of its server
+type nodeHealth struct {
+	status  metav1.ConditionStatus
+	reason  string
+	message string
+}
+
+// getServerHealth derives the Healthy condition of a Node from the health reported for its server, including any errors
+// reported for the server in the message
+func getServerHealth(server *hwmgrapi.ApiprotoServer) nodeHealth {
+	if server == nil || server.Status == nil || server.Status.Status == nil ||
+		server.Status.Status.Health == nil || *server.Status.Status.Health == "" {
+		return nodeHealth{
+			status:  metav1.ConditionUnknown,
+			reason:  string(pluginv1alpha1.ConditionReasons.HealthUnknown),
+			message: "Server health is not reported by the hardware manager",
+		}
+	}
+
+	health := *server.Status.Status.Health
+	if strings.EqualFold(health, serverHealthOK) {
+		return nodeHealth{
+			status:  metav1.ConditionTrue,
+			reason:  string(pluginv1alpha1.ConditionReasons.HardwareHealthy),
+			message: "Server health is " + health,
+		}
+	}
+
+	message := "Server health is " + health
+	if server.Status.Errors != nil && len(*server.Status.Errors) > 0 {
+		message += ": " + strings.Join(*server.Status.Errors, ", ")
+	}
+	return nodeHealth{
+		status:  metav1.ConditionFalse,
+		reason:  string(pluginv1alpha1.ConditionReasons.HardwareFault),
+		message: message,
+	}
+}
+
+// checkHardwareHealth periodically polls the health of the servers backing the nodes of a provisioned NodePool,
+// setting the Healthy condition of each Node. It returns a description of each fault found, and the time until the
+// next check. Between checks, the faults found by the last check are returned.
+func (a *Adaptor) checkHardwareHealth(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) ([]string, time.Duration, error) {
+
+	interval := getHealthCheckInterval(hwmgr)
+	key := client.ObjectKeyFromObject(nodepool).String()
+	if last, ok := a.healthChecks.Load(key); ok {
+		check := last.(healthCheck)
+		if elapsed := time.Since(check.checkedAt); elapsed < interval {
+			return check.faults, interval - elapsed, nil
+		}
+	}
+
+	resourceServers, cached := a.inventoryCache.getServers(hwmgr)
+	if !cached {
+		resources, err := hwmgrClient.GetResources(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get resources for health check of NodePool %s: %w", nodepool.Name, err)
+		}
+		servers, err := hwmgrClient.GetServersInventory(ctx)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get server inventory for health check of NodePool %s: %w", nodepool.Name, err)
+		}
+		resourceServers, _ = indexServersByResource(resources, servers)
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	var faults []string
+	conditionType := string(pluginv1alpha1.ConditionTypes.Healthy)
+	for _, node := range nodelist.Items {
+		health := getServerHealth(resourceServers[node.Spec.HwMgrNodeId])
+		if health.status == metav1.ConditionFalse {
+			faults = append(faults, fmt.Sprintf("node %s (%s): %s", node.Name, node.Spec.HwMgrNodeId, health.message))
+		}
+
+		condition := meta.FindStatusCondition(node.Status.Conditions, conditionType)
+		if condition != nil && condition.Status == health.status && condition.Reason == health.reason &&
+			utils.ConditionMessage(condition) == health.message {
+			continue
+		}
+		if health.status == metav1.ConditionFalse {
+			a.Logger.WarnContext(ctx, "Hardware fault reported for node",
+				slog.String("node", node.Name),
+				slog.String("resourceId", node.Spec.HwMgrNodeId),
+				slog.String("health", health.message))
+		}
+		if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+			conditionType, health.status, health.reason, health.message); err != nil {
+			return nil, 0, fmt.Errorf("failed to set health condition on node %s: %w", node.Name, err)
+		}
+	}
+	a.healthChecks.Store(key, healthCheck{checkedAt: time.Now(), faults: faults})
+
+	return faults, interval, nil
+}
@@ -0,0 +1,323 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hwmgrclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitCooldown         = 2 * time.Minute
+
+	// circuitProbeRetryAfter is returned to calls rejected while the trial call of a half-open circuit is in flight
+	circuitProbeRetryAfter = 5 * time.Second
+)
+
+// CircuitState is the state of the circuit breaker on calls to a hardware manager
+type CircuitState int
+
+const (
+	// CircuitClosed allows all calls
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen allows a single trial call after the cooldown, which closes the circuit if it succeeds
+	CircuitHalfOpen
+	// CircuitOpen fails calls fast until the cooldown has passed
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "Closed"
+	case CircuitHalfOpen:
+		return "HalfOpen"
+	case CircuitOpen:
+		return "Open"
+	default:
+		return "Unknown"
+	}
+}
+
+var (
+	circuitStateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hwmgr_plugin_dell_circuit_breaker_state",
+		Help: "State of the circuit breaker on calls to a hardware manager: 0 closed, 1 half-open, 2 open",
+	}, []string{"namespace", "hwmgr"})
+
+	circuitFailuresGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hwmgr_plugin_dell_circuit_breaker_consecutive_failures",
+		Help: "Number of consecutive failed calls to a hardware manager",
+	}, []string{"namespace", "hwmgr"})
+
+	circuitOpenedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hwmgr_plugin_dell_circuit_breaker_opened_total",
+		Help: "Number of times the circuit breaker on calls to a hardware manager has opened",
+	}, []string{"namespace", "hwmgr"})
+
+	circuitRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hwmgr_plugin_dell_circuit_breaker_rejected_total",
+		Help: "Number of calls to a hardware manager failed fast by an open circuit breaker",
+	}, []string{"namespace", "hwmgr"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(circuitStateGauge, circuitFailuresGauge, circuitOpenedTotal, circuitRejectedTotal)
+}
+
+// circuitBreakers holds the circuit breaker of each HardwareManager, by namespaced name. Clients are created for each
+// reconcile, so the breakers are kept here to track failures across them.
+var circuitBreakers sync.Map
+
+var (
+	circuitStateChangeMu      sync.RWMutex
+	circuitStateChangeHandler func(hwmgr types.NamespacedName, state CircuitState)
+)
+
+// SetCircuitStateChangeHandler registers a function called whenever the circuit breaker of a HardwareManager changes
+// state. The handler is called from the goroutine making the hardware manager call, so it must not block.
+func SetCircuitStateChangeHandler(handler func(hwmgr types.NamespacedName, state CircuitState)) {
+	circuitStateChangeMu.Lock()
+	defer circuitStateChangeMu.Unlock()
+	circuitStateChangeHandler = handler
+}
+
+func notifyCircuitStateChange(hwmgr types.NamespacedName, state CircuitState) {
+	circuitStateChangeMu.RLock()
+	handler := circuitStateChangeHandler
+	circuitStateChangeMu.RUnlock()
+
+	if handler != nil {
+		handler(hwmgr, state)
+	}
+}
+
+// circuitBreaker tracks the consecutive failed calls to a hardware manager, failing calls fast for a cooldown period
+// once the failure threshold is reached, rather than waiting for each to time out
+type circuitBreaker struct {
+	mu        sync.Mutex
+	key       types.NamespacedName
+	threshold int
+	cooldown  time.Duration
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// getCircuitBreaker returns the circuit breaker of a HardwareManager, configured from its current spec
+func getCircuitBreaker(hwmgr *pluginv1alpha1.HardwareManager) *circuitBreaker {
+	key := client.ObjectKeyFromObject(hwmgr)
+	value, _ := circuitBreakers.LoadOrStore(key, &circuitBreaker{key: key, state: CircuitClosed})
+	breaker := value.(*circuitBreaker)
+	breaker.configure(hwmgr)
+	return breaker
+}
+
+// RemoveCircuitBreaker discards the circuit breaker of a deleted HardwareManager, along with its metrics
+func RemoveCircuitBreaker(key types.NamespacedName) {
+	circuitBreakers.Delete(key)
+	for _, metric := range []*prometheus.MetricVec{
+		circuitStateGauge.MetricVec, circuitFailuresGauge.MetricVec, circuitOpenedTotal.MetricVec, circuitRejectedTotal.MetricVec,
+	} {
+		metric.DeleteLabelValues(key.Namespace, key.Name)
+	}
+}
+
+// GetCircuitState returns the state of the circuit breaker of a HardwareManager and, if it is open, the time remaining
+// until a trial call is allowed
+func GetCircuitState(hwmgr *pluginv1alpha1.HardwareManager) (CircuitState, time.Duration) {
+	value, exists := circuitBreakers.Load(client.ObjectKeyFromObject(hwmgr))
+	if !exists {
+		return CircuitClosed, 0
+	}
+
+	breaker := value.(*circuitBreaker)
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	return breaker.state, breaker.remainingCooldown()
+}
+
+func (b *circuitBreaker) configure(hwmgr *pluginv1alpha1.HardwareManager) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.threshold = defaultCircuitFailureThreshold
+	b.cooldown = defaultCircuitCooldown
+	if hwmgr.Spec.DellData == nil || hwmgr.Spec.DellData.CircuitBreaker == nil {
+		return
+	}
+
+	config := hwmgr.Spec.DellData.CircuitBreaker
+	if config.FailureThreshold != nil && *config.FailureThreshold > 0 {
+		b.threshold = *config.FailureThreshold
+	}
+	if config.Cooldown != nil && config.Cooldown.Duration > 0 {
+		b.cooldown = config.Cooldown.Duration
+	}
+}
+
+// remainingCooldown returns the time until an open circuit allows a trial call. The caller must hold the lock.
+func (b *circuitBreaker) remainingCooldown() time.Duration {
+	if b.state != CircuitOpen {
+		return 0
+	}
+	return max(b.cooldown-time.Since(b.openedAt), 0)
+}
+
+// setState changes the state of the circuit, returning true if it has changed. The caller must hold the lock.
+func (b *circuitBreaker) setState(state CircuitState) bool {
+	circuitStateGauge.WithLabelValues(b.key.Namespace, b.key.Name).Set(float64(state))
+	if b.state == state {
+		return false
+	}
+	if state == CircuitOpen {
+		circuitOpenedTotal.WithLabelValues(b.key.Namespace, b.key.Name).Inc()
+	}
+	b.state = state
+	return true
+}
+
+// check returns a CircuitOpenError if the circuit is open and still within its cooldown
+func (b *circuitBreaker) check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if remaining := b.remainingCooldown(); remaining > 0 {
+		circuitRejectedTotal.WithLabelValues(b.key.Namespace, b.key.Name).Inc()
+		return typederrors.NewCircuitOpenError(remaining,
+			"circuit breaker open for hardware manager %s after %d consecutive failures, retry in %s",
+			b.key.Name, b.failures, remaining.Round(time.Second))
+	}
+	return nil
+}
+
+// allow reports whether a call may proceed, moving an open circuit to half-open for a trial call once its cooldown
+// has passed. It returns true if the call is the trial call of a half-open circuit, which must be passed back to
+// record or release.
+func (b *circuitBreaker) allow() (bool, error) {
+	b.mu.Lock()
+	changed := false
+	defer func() {
+		b.mu.Unlock()
+		if changed {
+			notifyCircuitStateChange(b.key, CircuitHalfOpen)
+		}
+	}()
+
+	switch b.state {
+	case CircuitOpen:
+		if remaining := b.remainingCooldown(); remaining > 0 {
+			circuitRejectedTotal.WithLabelValues(b.key.Namespace, b.key.Name).Inc()
+			return false, typederrors.NewCircuitOpenError(remaining,
+				"circuit breaker open for hardware manager %s, retry in %s", b.key.Name, remaining.Round(time.Second))
+		}
+		changed = b.setState(CircuitHalfOpen)
+		b.probing = true
+		return true, nil
+	case CircuitHalfOpen:
+		if b.probing {
+			circuitRejectedTotal.WithLabelValues(b.key.Namespace, b.key.Name).Inc()
+			return false, typederrors.NewCircuitOpenError(circuitProbeRetryAfter,
+				"circuit breaker half-open for hardware manager %s, trial call in progress", b.key.Name)
+		}
+		b.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// record updates the circuit with the outcome of a call. Consecutive failures open the circuit once they reach the
+// threshold, and a successful call resets them. Once the circuit has opened, only its trial call decides whether it
+// closes again or reopens, as calls allowed before it opened may still complete with an outcome that is out of date.
+func (b *circuitBreaker) record(probe, success bool) {
+	b.mu.Lock()
+	changed := false
+	defer func() {
+		state := b.state
+		b.mu.Unlock()
+		if changed {
+			notifyCircuitStateChange(b.key, state)
+		}
+	}()
+
+	if probe {
+		b.probing = false
+	} else if b.state != CircuitClosed {
+		return
+	}
+
+	if success {
+		b.failures = 0
+		circuitFailuresGauge.WithLabelValues(b.key.Namespace, b.key.Name).Set(0)
+		changed = b.setState(CircuitClosed)
+		return
+	}
+
+	b.failures++
+	circuitFailuresGauge.WithLabelValues(b.key.Namespace, b.key.Name).Set(float64(b.failures))
+	if probe || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		changed = b.setState(CircuitOpen)
+	}
+}
+
+// release ends a call that was abandoned by the caller, without counting it as a success or failure. An abandoned trial
+// call leaves the circuit half-open for another.
+func (b *circuitBreaker) release(probe bool) {
+	if !probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// circuitBreakerTransport passes calls to the hardware manager through its circuit breaker. Transport errors and
+// server errors count as failures.
+type circuitBreakerTransport struct {
+	next    http.RoundTripper
+	breaker *circuitBreaker
+}
+
+func newCircuitBreakerTransport(next http.RoundTripper, breaker *circuitBreaker) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &circuitBreakerTransport{next: next, breaker: breaker}
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	probe, err := t.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// The caller gave up on the call, which says nothing of the hardware manager
+		t.breaker.release(probe)
+	case err != nil:
+		t.breaker.record(probe, false)
+	default:
+		t.breaker.record(probe, resp.StatusCode < http.StatusInternalServerError)
+	}
+
+	// nolint: wrapcheck
+	return resp, err
+}
@@ -0,0 +1,269 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hwmgrclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+// circuitTestServer is a hardware manager that responds with its current status, holding requests to heldPath until
+// released or cancelled
+type circuitTestServer struct {
+	*httptest.Server
+	status   atomic.Int32
+	requests atomic.Int32
+	// received is signalled when a held request arrives
+	received chan struct{}
+	// release releases a held request when sent to, or all of them when closed
+	release chan struct{}
+}
+
+const heldPath = "/held"
+
+func newCircuitTestServer(t *testing.T) *circuitTestServer {
+	server := &circuitTestServer{received: make(chan struct{}, 10), release: make(chan struct{})}
+	server.status.Store(http.StatusOK)
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+		// The status is read on arrival, as the outcome of a slow call reflects the state of the hardware manager when
+		// it was made
+		status := int(server.status.Load())
+		if r.URL.Path == heldPath {
+			server.received <- struct{}{}
+			select {
+			case <-server.release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestCircuitBreaker returns a closed circuit breaker and an HTTP client passing calls through it
+func newTestCircuitBreaker(t *testing.T, threshold int) (*circuitBreaker, *http.Client) {
+	breaker := &circuitBreaker{
+		key:       types.NamespacedName{Namespace: "oran-hwmgr-plugin", Name: t.Name()},
+		threshold: threshold,
+		cooldown:  time.Hour,
+		state:     CircuitClosed,
+	}
+	t.Cleanup(func() { RemoveCircuitBreaker(breaker.key) })
+	return breaker, &http.Client{Transport: newCircuitBreakerTransport(nil, breaker)}
+}
+
+func doCall(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (b *circuitBreaker) getState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *circuitBreaker) getRemainingCooldown() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remainingCooldown()
+}
+
+// expireCooldown moves the opening of the circuit back past its cooldown
+func (b *circuitBreaker) expireCooldown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = time.Now().Add(-b.cooldown)
+}
+
+func TestCircuitBreakerOpensAtThreshold(t *testing.T) {
+	server := newCircuitTestServer(t)
+	server.status.Store(http.StatusServiceUnavailable)
+	breaker, client := newTestCircuitBreaker(t, 3)
+
+	for i := 0; i < 3; i++ {
+		if breaker.getState() != CircuitClosed {
+			t.Fatalf("expected the circuit to be closed after %d failures", i)
+		}
+		if err := doCall(context.Background(), client, server.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if breaker.getState() != CircuitOpen {
+		t.Fatalf("expected the circuit to open at the threshold, got %s", breaker.getState())
+	}
+
+	err := doCall(context.Background(), client, server.URL)
+	if !typederrors.IsCircuitOpenError(err) {
+		t.Errorf("expected a circuit open error, got %v", err)
+	}
+	if server.requests.Load() != 3 {
+		t.Errorf("expected the call to fail fast without reaching the hardware manager")
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	server := newCircuitTestServer(t)
+	breaker, client := newTestCircuitBreaker(t, 2)
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusInternalServerError} {
+		server.status.Store(int32(status))
+		if err := doCall(context.Background(), client, server.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if breaker.getState() != CircuitClosed {
+		t.Errorf("expected the failures not to be consecutive, got %s", breaker.getState())
+	}
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	tests := []struct {
+		description string
+		status      int
+		expected    CircuitState
+	}{
+		{description: "successful probe closes the circuit", status: http.StatusOK, expected: CircuitClosed},
+		{description: "failed probe reopens the circuit", status: http.StatusBadGateway, expected: CircuitOpen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			server := newCircuitTestServer(t)
+			server.status.Store(http.StatusInternalServerError)
+			breaker, client := newTestCircuitBreaker(t, 1)
+
+			if err := doCall(context.Background(), client, server.URL); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if breaker.getState() != CircuitOpen {
+				t.Fatalf("expected the circuit to open, got %s", breaker.getState())
+			}
+
+			// The trial call is allowed once the cooldown has passed, and others are rejected while it is in flight
+			breaker.expireCooldown()
+			server.status.Store(int32(test.status))
+			probe := make(chan error)
+			go func() { probe <- doCall(context.Background(), client, server.URL+heldPath) }()
+			<-server.received
+
+			if breaker.getState() != CircuitHalfOpen {
+				t.Errorf("expected the circuit to be half-open during the trial call, got %s", breaker.getState())
+			}
+			err := doCall(context.Background(), client, server.URL)
+			if retryAfter, ok := typederrors.GetCircuitOpenRetryAfter(err); !ok || retryAfter != circuitProbeRetryAfter {
+				t.Errorf("expected the call to be rejected during the trial call, got %v", err)
+			}
+
+			close(server.release)
+			if err := <-probe; err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if breaker.getState() != test.expected {
+				t.Errorf("expected the circuit to be %s after the trial call, got %s", test.expected, breaker.getState())
+			}
+			if test.expected == CircuitOpen && breaker.getRemainingCooldown() <= 0 {
+				t.Errorf("expected a new cooldown after the failed trial call")
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCancelledCall(t *testing.T) {
+	server := newCircuitTestServer(t)
+	server.status.Store(http.StatusInternalServerError)
+	breaker, client := newTestCircuitBreaker(t, 1)
+
+	cancelledCall := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() { result <- doCall(ctx, client, server.URL+heldPath) }()
+		<-server.received
+		cancel()
+		if err := <-result; err == nil {
+			t.Fatalf("expected the cancelled call to fail")
+		}
+	}
+
+	// A call abandoned by the caller is not a failure of the hardware manager
+	cancelledCall()
+	if breaker.getState() != CircuitClosed {
+		t.Fatalf("expected the cancelled call not to open the circuit, got %s", breaker.getState())
+	}
+
+	// An abandoned trial call leaves the circuit half-open for another
+	if err := doCall(context.Background(), client, server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	breaker.expireCooldown()
+	cancelledCall()
+	if breaker.getState() != CircuitHalfOpen {
+		t.Fatalf("expected the circuit to remain half-open, got %s", breaker.getState())
+	}
+
+	server.status.Store(http.StatusOK)
+	if err := doCall(context.Background(), client, server.URL); err != nil {
+		t.Fatalf("expected another trial call to be allowed, got %v", err)
+	}
+	if breaker.getState() != CircuitClosed {
+		t.Errorf("expected the second trial call to close the circuit, got %s", breaker.getState())
+	}
+}
+
+func TestCircuitBreakerStaleSuccess(t *testing.T) {
+	server := newCircuitTestServer(t)
+	breaker, client := newTestCircuitBreaker(t, 1)
+
+	// A slow call is allowed while the circuit is closed, and succeeds only after the circuit has opened
+	stale := make(chan error)
+	go func() { stale <- doCall(context.Background(), client, server.URL+heldPath) }()
+	<-server.received
+
+	server.status.Store(http.StatusInternalServerError)
+	if err := doCall(context.Background(), client, server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if breaker.getState() != CircuitOpen {
+		t.Fatalf("expected the circuit to open, got %s", breaker.getState())
+	}
+
+	close(server.release)
+	if err := <-stale; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if breaker.getState() != CircuitOpen {
+		t.Errorf("expected a call allowed before the circuit opened not to close it, got %s", breaker.getState())
+	}
+
+	// Nor does it take the place of the trial call once the cooldown has passed
+	breaker.expireCooldown()
+	server.status.Store(http.StatusOK)
+	if err := doCall(context.Background(), client, server.URL); err != nil {
+		t.Fatalf("expected the trial call to be allowed, got %v", err)
+	}
+	if breaker.getState() != CircuitClosed {
+		t.Errorf("expected the trial call to close the circuit, got %s", breaker.getState())
+	}
+}
@@ -19,6 +19,7 @@ import (
 	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,6 +28,13 @@ import (
 const (
 	RoleKey       = "role"
 	DefaultTenant = "default_tenant"
+
+	// Fields of the authSecret
+	ClientIdKey     = "client-id"
+	ClientSecretKey = "client-secret"
+
+	// tokenPath is the hardware manager token endpoint, relative to the apiUrl
+	tokenPath = "./identity/v1/tenant/Fulcrum/token/create"
 )
 
 type JobStatus int
@@ -81,10 +89,26 @@ type HardwareManagerClient struct {
 	Logger      *slog.Logger
 	Namespace   string
 	hwmgr       *pluginv1alpha1.HardwareManager
+	tenant      string
+}
+
+// WithTenant returns a copy of the client that makes its calls as the given tenant, rather than the tenant of the
+// hwmgr configuration. An empty tenant returns the client unchanged.
+func (c *HardwareManagerClient) WithTenant(tenant string) *HardwareManagerClient {
+	if tenant == "" {
+		return c
+	}
+	tenantClient := *c
+	tenantClient.tenant = tenant
+	return &tenantClient
 }
 
-// GetTenant gets the tenant parameter from the hwmgr configuration
+// GetTenant gets the tenant parameter from the hwmgr configuration, unless overridden for the client
 func (c *HardwareManagerClient) GetTenant() string {
+	if c.tenant != "" {
+		return c.tenant
+	}
+
 	if c.hwmgr.Spec.DellData.Tenant != nil && *c.hwmgr.Spec.DellData.Tenant != "" {
 		return *c.hwmgr.Spec.DellData.Tenant
 	}
@@ -92,6 +116,68 @@ func (c *HardwareManagerClient) GetTenant() string {
 	return DefaultTenant
 }
 
+// GetGrantType gets the OAuth grant type from the hwmgr configuration
+func (c *HardwareManagerClient) GetGrantType() pluginv1alpha1.OAuthGrantType {
+	if c.hwmgr.Spec.DellData.GrantType != "" {
+		return c.hwmgr.Spec.DellData.GrantType
+	}
+
+	return pluginv1alpha1.OAuthGrantTypes.Password
+}
+
+// GetTokenUrl gets the URL used to acquire a token with the client_credentials grant
+func (c *HardwareManagerClient) GetTokenUrl() (string, error) {
+	if c.hwmgr.Spec.DellData.TokenUrl != nil && *c.hwmgr.Spec.DellData.TokenUrl != "" {
+		tokenURL, err := utils.ParseEndpointURL(*c.hwmgr.Spec.DellData.TokenUrl)
+		if err != nil {
+			return "", typederrors.NewInputError("invalid tokenUrl: %s", err.Error())
+		}
+		return tokenURL.String(), nil
+	}
+
+	serverURL, err := utils.ParseEndpointURL(c.hwmgr.Spec.DellData.ApiUrl)
+	if err != nil {
+		return "", typederrors.NewInputError("invalid apiUrl: %s", err.Error())
+	}
+
+	tokenURL, err := serverURL.Parse(tokenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to build token url from apiUrl %s: %w", c.hwmgr.Spec.DellData.ApiUrl, err)
+	}
+
+	return tokenURL.String(), nil
+}
+
+// GetClientCredentialsConfig populates the OAuth client config for the client_credentials grant from the authSecret
+func (c *HardwareManagerClient) GetClientCredentialsConfig(ctx context.Context, config *utils.OAuthClientConfig) error {
+	clientSecrets, err := utils.GetSecret(ctx, c.rtclient, c.hwmgr.Spec.DellData.AuthSecret, c.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get client secret: %w", err)
+	}
+
+	clientId, err := utils.GetSecretField(clientSecrets, ClientIdKey)
+	if err != nil {
+		return fmt.Errorf("failed to get %s from secret: %s, %w", ClientIdKey, c.hwmgr.Spec.DellData.AuthSecret, err)
+	}
+
+	clientSecret, err := utils.GetSecretField(clientSecrets, ClientSecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to get %s from secret: %s, %w", ClientSecretKey, c.hwmgr.Spec.DellData.AuthSecret, err)
+	}
+
+	tokenUrl, err := c.GetTokenUrl()
+	if err != nil {
+		return err
+	}
+
+	config.ClientId = clientId
+	config.ClientSecret = clientSecret
+	config.TokenUrl = tokenUrl
+	config.GrantType = pluginv1alpha1.OAuthGrantTypes.ClientCredentials
+
+	return nil
+}
+
 // GetToken sends a request to the hardware manager to request an authentication token
 func (c *HardwareManagerClient) GetToken(ctx context.Context) (string, error) {
 	clientSecrets, err := utils.GetSecret(ctx, c.rtclient, c.hwmgr.Spec.DellData.AuthSecret, c.Namespace)
@@ -99,9 +185,9 @@ func (c *HardwareManagerClient) GetToken(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("failed to get client secret: %w", err)
 	}
 
-	clientId, err := utils.GetSecretField(clientSecrets, "client-id")
+	clientId, err := utils.GetSecretField(clientSecrets, ClientIdKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to get client-id from secret: %s, %w", c.hwmgr.Spec.DellData.AuthSecret, err)
+		return "", fmt.Errorf("failed to get %s from secret: %s, %w", ClientIdKey, c.hwmgr.Spec.DellData.AuthSecret, err)
 	}
 
 	username, err := utils.GetSecretField(clientSecrets, corev1.BasicAuthUsernameKey)
@@ -151,13 +237,26 @@ func NewClientWithResponses(
 	rtclient client.Client,
 	hwmgr *pluginv1alpha1.HardwareManager) (*HardwareManagerClient, error) {
 
+	// An IPv6 literal host in the apiUrl is accepted without brackets, so the URL is normalized before use
+	apiURL, err := utils.ParseEndpointURL(hwmgr.Spec.DellData.ApiUrl)
+	if err != nil {
+		return nil, typederrors.NewInputError("invalid apiUrl: %s", err.Error())
+	}
+	serverURL := apiURL.String()
+
 	hwmgrClient := HardwareManagerClient{
 		rtclient:  rtclient,
-		Logger:    logger,
+		Logger:    logging.ComponentLogger(logger, logging.Components.HwMgrClient),
 		Namespace: hwmgr.Namespace,
 		hwmgr:     hwmgr,
 	}
 
+	// Fail fast while the circuit breaker is open, rather than waiting for calls to a failing hardware manager to time out
+	breaker := getCircuitBreaker(hwmgr)
+	if err := breaker.check(); err != nil {
+		return nil, err
+	}
+
 	// If the HardwareManager CR includes certificates, get the bundle to add to the client
 	var caBundle string
 	if hwmgr.Spec.DellData.CaBundleName != nil {
@@ -173,7 +272,42 @@ func NewClientWithResponses(
 	}
 
 	config := utils.OAuthClientConfig{
-		CaBundle: []byte(caBundle),
+		CaBundle:  []byte(caBundle),
+		Proxy:     hwmgr.Spec.Proxy,
+		HwMgrName: hwmgr.Name,
+	}
+
+	// If the HardwareManager CR references a client certificate, present it for mutual TLS authentication. The secret
+	// is read each time a client is created, so a rotated certificate is used on the next connection.
+	if hwmgr.Spec.DellData.ClientCertSecret != nil {
+		clientCert, err := getClientCertContent(ctx, hwmgrClient.Logger, rtclient, hwmgr, *hwmgr.Spec.DellData.ClientCertSecret)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCert = clientCert
+	}
+
+	if hwmgrClient.GetGrantType() == pluginv1alpha1.OAuthGrantTypes.ClientCredentials {
+		// The OAuth client acquires and refreshes the token itself, so no bearer token intercept is needed
+		if err := hwmgrClient.GetClientCredentialsConfig(ctx, &config); err != nil {
+			return nil, typederrors.NewTokenError(err, "failed to get client credentials for %s: %s", hwmgr.Name, err.Error())
+		}
+
+		httpClient, err := utils.SetupOAuthClient(ctx, config, hwmgr.Spec.DellData.InsecureSkipTLSVerify, utils.IsHardwareManagerLogMessagesEnabled(hwmgr))
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup oauth client for %s: %w", hwmgr.Name, err)
+		}
+		httpClient.Transport = newCircuitBreakerTransport(httpClient.Transport, breaker)
+
+		hwmgrClient.HwmgrClient, err = hwmgrapi.NewClientWithResponses(
+			serverURL,
+			hwmgrapi.WithHTTPClient(httpClient),
+			hwmgrapi.WithRequestEditorFn(logging.AddCorrelationIDHeader))
+		if err != nil {
+			return nil, fmt.Errorf("failed to setup auth client for %s: %w", hwmgr.Name, err)
+		}
+
+		return &hwmgrClient, nil
 	}
 
 	tr, err := utils.GetTransportWithCaBundle(config, hwmgr.Spec.DellData.InsecureSkipTLSVerify, utils.IsHardwareManagerLogMessagesEnabled(hwmgr))
@@ -181,14 +315,15 @@ func NewClientWithResponses(
 		return nil, fmt.Errorf("failed to get http transport: %w", err)
 	}
 
-	httpClient := &http.Client{Transport: tr}
+	httpClient := &http.Client{Transport: newCircuitBreakerTransport(tr, breaker)}
 
 	// Create the hwmgrapi client, along with a bearer token
 	hwmgrClient.HwmgrClient, err = hwmgrapi.NewClientWithResponses(
-		hwmgr.Spec.DellData.ApiUrl,
-		hwmgrapi.WithHTTPClient(httpClient))
+		serverURL,
+		hwmgrapi.WithHTTPClient(httpClient),
+		hwmgrapi.WithRequestEditorFn(logging.AddCorrelationIDHeader))
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup client to %s: %w", hwmgr.Spec.DellData.ApiUrl, err)
+		return nil, fmt.Errorf("failed to setup client to %s: %w", serverURL, err)
 	}
 
 	token, err := hwmgrClient.GetToken(ctx)
@@ -201,11 +336,12 @@ func NewClientWithResponses(
 		return nil, fmt.Errorf("failed to create security provider for %s: %w", hwmgr.Name, err)
 	}
 
-	// Create a new client with an intercept to add the bearer token
+	// Create a new client with intercepts to add the bearer token and correlation ID
 	hwmgrClient.HwmgrClient, err = hwmgrapi.NewClientWithResponses(
-		hwmgr.Spec.DellData.ApiUrl,
+		serverURL,
 		hwmgrapi.WithHTTPClient(httpClient),
-		hwmgrapi.WithRequestEditorFn(bearerAuth.Intercept))
+		hwmgrapi.WithRequestEditorFn(bearerAuth.Intercept),
+		hwmgrapi.WithRequestEditorFn(logging.AddCorrelationIDHeader))
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup auth client for %s: %w", hwmgr.Name, err)
 	}
@@ -267,9 +403,12 @@ func (c *HardwareManagerClient) GetResourceGroups(ctx context.Context) (*hwmgrap
 	return response.JSON200, nil
 }
 
+// ResourceGroupIdPrefix is the prefix of the identifiers of the resource groups created by the plugin
+const ResourceGroupIdPrefix = "rhplugin-rg-"
+
 // ResourceGroupIdFromNodePool returns the resource group identifier corresponding to the specified nodepool
 func ResourceGroupIdFromNodePool(nodepool *hwmgmtv1alpha1.NodePool) string {
-	return fmt.Sprintf("rhplugin-rg-%s", nodepool.Spec.CloudID)
+	return ResourceGroupIdPrefix + nodepool.Spec.CloudID
 }
 
 // ResourceGroupFromNodePool transforms data from a nodepool object to a CreateResourceGroupJSONRequestBody instance
@@ -278,9 +417,13 @@ func (c *HardwareManagerClient) ResourceGroupFromNodePool(ctx context.Context, n
 	tenant := c.GetTenant()
 	resourceTypeId := utils.GetResourceTypeId(nodepool)
 	description := "Resource Group managed by O-Cloud Hardware Manager Plugin"
-	excludes := make(map[string]interface{})
 	roleKey := RoleKey
 
+	pins, err := GetPinnedResources(nodepool)
+	if err != nil {
+		c.Logger.InfoContext(ctx, "Unable to parse pinned resources", slog.String("error", err.Error()))
+	}
+
 	resourceSelectors := make(map[string]hwmgrapi.RhprotoResourceSelectorRequest)
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
 		inclusions := []hwmgrapi.RhprotoResourceSelectorFilterIncludeLabel{
@@ -289,16 +432,23 @@ func (c *HardwareManagerClient) ResourceGroupFromNodePool(ctx context.Context, n
 				Value: &nodegroup.NodePoolData.Name, // TODO: This should be nodegroup.NodePoolData.Role, but has to be nodegroup.NodePoolData.Name for now
 			},
 		}
+		excludes := make(map[string]interface{})
 		if nodegroup.NodePoolData.ResourceSelector != "" {
-			selectors := make(map[string]string)
-			if err := json.Unmarshal([]byte(nodegroup.NodePoolData.ResourceSelector), &selectors); err != nil {
+			selectors, exclusions, err := ParseResourceSelector(nodegroup.NodePoolData.ResourceSelector)
+			if err != nil {
 				c.Logger.InfoContext(ctx, "Unable to parse resourceSelector", slog.String("resourceSelector", nodegroup.NodePoolData.ResourceSelector))
 			} else {
 				for key, value := range selectors {
 					inclusions = append(inclusions, hwmgrapi.RhprotoResourceSelectorFilterIncludeLabel{Key: &key, Value: &value})
 				}
+				excludes = excludeFilter(exclusions)
 			}
 		}
+		// Pinned resources are included by ID, so that the hardware manager selects exactly those resources
+		for _, resourceId := range pins[nodegroup.NodePoolData.Name] {
+			key, value := ResourceIdKey, resourceId
+			inclusions = append(inclusions, hwmgrapi.RhprotoResourceSelectorFilterIncludeLabel{Key: &key, Value: &value})
+		}
 
 		rpId := nodepool.Status.SelectedPools[nodegroup.NodePoolData.Name]
 		resourceSelectors[nodegroup.NodePoolData.Name] = hwmgrapi.RhprotoResourceSelectorRequest{
@@ -329,6 +479,7 @@ func (c *HardwareManagerClient) ResourceGroupFromNodePool(ctx context.Context, n
 			}
 
 			numResources := 0
+			excludes := make(map[string]interface{})
 			resourceSelectors[worker] = hwmgrapi.RhprotoResourceSelectorRequest{
 				RpId:              controllerSelector.RpId,
 				ResourceProfileId: controllerSelector.ResourceProfileId,
@@ -469,7 +620,11 @@ func (c *HardwareManagerClient) CheckJobStatus(ctx context.Context, jobId string
 
 // DeleteResourceGroup asks the hardware manager to delete the resource group associated with the specified nodepool
 func (c *HardwareManagerClient) DeleteResourceGroup(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
-	rgId := ResourceGroupIdFromNodePool(nodepool)
+	return c.DeleteResourceGroupFromId(ctx, ResourceGroupIdFromNodePool(nodepool))
+}
+
+// DeleteResourceGroupFromId asks the hardware manager to delete the specified resource group
+func (c *HardwareManagerClient) DeleteResourceGroupFromId(ctx context.Context, rgId string) (string, error) {
 	tenant := c.GetTenant()
 
 	response, err := c.HwmgrClient.DeleteResourceGroupWithResponse(ctx, tenant, rgId)
@@ -634,3 +789,112 @@ func (c *HardwareManagerClient) UpdateResourceProfile(ctx context.Context, node
 
 	return *response.JSON200.Response.Jobid, nil
 }
+
+// UpdateResourceBMCCredentials sends a request to set new BMC credentials for a node
+func (c *HardwareManagerClient) UpdateResourceBMCCredentials(ctx context.Context, node *hwmgmtv1alpha1.Node, username, password string) (string, error) {
+	tenant := c.GetTenant()
+
+	op := "replace"
+	path := "/Resource/ResourceAttribute/Compute/Lom"
+	value := []map[string]interface{}{{"user": username, "password": password}}
+	body := hwmgrapi.UpdateResourceJSONRequestBody{
+		ResourceName: &node.Spec.HwMgrNodeId,
+		Resource: &[]hwmgrapi.ApiprotoUpdateResource{
+			{
+				Op:    &op,
+				Path:  &path,
+				Value: &value,
+			},
+		},
+	}
+	response, err := c.HwmgrClient.UpdateResourceWithResponse(ctx, tenant, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to update BMC credentials: err: %w", err)
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("BMC credentials update failed with status %s (%d), message=%s",
+			response.Status(), response.StatusCode(), string(response.Body))
+	}
+
+	if response.JSON200 == nil || response.JSON200.Response == nil || response.JSON200.Response.Jobid == nil {
+		return "", fmt.Errorf("BMC credentials update response is missing the job ID")
+	}
+
+	return *response.JSON200.Response.Jobid, nil
+}
+
+// UpdateResourceTags sends a request to replace the tags of the resource backing a node
+func (c *HardwareManagerClient) UpdateResourceTags(ctx context.Context, node *hwmgmtv1alpha1.Node, tags []string) (string, error) {
+	tenant := c.GetTenant()
+
+	op := "replace"
+	path := "/Resource/Tags"
+	value := []map[string]interface{}{{"tags": tags}}
+	body := hwmgrapi.UpdateResourceJSONRequestBody{
+		ResourceName: &node.Spec.HwMgrNodeId,
+		Resource: &[]hwmgrapi.ApiprotoUpdateResource{
+			{
+				Op:    &op,
+				Path:  &path,
+				Value: &value,
+			},
+		},
+	}
+	response, err := c.HwmgrClient.UpdateResourceWithResponse(ctx, tenant, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to update tags: err: %w", err)
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("tags update failed with status %s (%d), message=%s",
+			response.Status(), response.StatusCode(), string(response.Body))
+	}
+
+	if response.JSON200 == nil || response.JSON200.Response == nil || response.JSON200.Response.Jobid == nil {
+		return "", fmt.Errorf("tags update response is missing the job ID")
+	}
+
+	return *response.JSON200.Response.Jobid, nil
+}
+
+// Power states accepted by UpdateResourcePowerState
+const (
+	PowerStateOn              = "On"
+	PowerStateOff             = "Off"
+	PowerStateGracefulRestart = "GracefulRestart"
+)
+
+// UpdateResourcePowerState sends a request to change the power state of a node
+func (c *HardwareManagerClient) UpdateResourcePowerState(ctx context.Context, node *hwmgmtv1alpha1.Node, powerState string) (string, error) {
+	tenant := c.GetTenant()
+
+	op := "replace"
+	path := "/Resource/PowerState"
+	value := []map[string]interface{}{{"powerState": powerState}}
+	body := hwmgrapi.UpdateResourceJSONRequestBody{
+		ResourceName: &node.Spec.HwMgrNodeId,
+		Resource: &[]hwmgrapi.ApiprotoUpdateResource{
+			{
+				Op:    &op,
+				Path:  &path,
+				Value: &value,
+			},
+		},
+	}
+	response, err := c.HwmgrClient.UpdateResourceWithResponse(ctx, tenant, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to update power state: err: %w", err)
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("power state update failed with status %s (%d), message=%s",
+			response.Status(), response.StatusCode(), string(response.Body))
+	}
+
+	if response.JSON200 == nil || response.JSON200.Response == nil || response.JSON200.Response.Jobid == nil {
+		return "", fmt.Errorf("power state update response is missing the job ID")
+	}
+
+	return *response.JSON200.Response.Jobid, nil
+}
@@ -0,0 +1,135 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hwmgrclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+// clientCertContent provides the client certificate and key of a HardwareManager, updated from its secret each time a
+// client is created. Rotated content that is not a valid certificate and key pair is rejected in favour of the content
+// already provided.
+type clientCertContent struct {
+	name string
+
+	mu        sync.RWMutex
+	cert      []byte
+	key       []byte
+	listeners []dynamiccertificates.Listener
+}
+
+var _ dynamiccertificates.CertKeyContentProvider = &clientCertContent{}
+
+// clientCerts holds the client certificate content of each HardwareManager, keyed by its namespaced name
+var clientCerts sync.Map
+
+// Name is the name of the content provider
+func (c *clientCertContent) Name() string {
+	return c.name
+}
+
+// CurrentCertKeyContent returns the current client certificate and key
+func (c *clientCertContent) CurrentCertKeyContent() ([]byte, []byte) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, c.key
+}
+
+// AddListener adds a listener notified when the content changes
+func (c *clientCertContent) AddListener(listener dynamiccertificates.Listener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// hasContent returns whether a valid certificate and key pair has been provided
+func (c *clientCertContent) hasContent() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.cert) != 0
+}
+
+// update replaces the content if it has changed, notifying the listeners. An invalid certificate and key pair is
+// returned as an error and leaves the content unchanged.
+func (c *clientCertContent) update(cert, key []byte) error {
+	c.mu.Lock()
+	if bytes.Equal(c.cert, cert) && bytes.Equal(c.key, key) {
+		c.mu.Unlock()
+		return nil
+	}
+
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("invalid certificate and key pair: %w", err)
+	}
+
+	c.cert, c.key = cert, key
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.Enqueue()
+	}
+	return nil
+}
+
+// getClientCertContent returns the certificate content provider of a HardwareManager, updated from the client
+// certificate and key in its TLS secret. If the secret has been rotated to an invalid pair, the previous content is
+// kept.
+func getClientCertContent(
+	ctx context.Context,
+	logger *slog.Logger,
+	rtclient client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	name string) (dynamiccertificates.CertKeyContentProvider, error) {
+
+	secret, err := utils.GetSecret(ctx, rtclient, name, hwmgr.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client certificate secret: %w", err)
+	}
+
+	cert, err := utils.GetSecretField(secret, corev1.TLSCertKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from secret: %s, %w", corev1.TLSCertKey, name, err)
+	}
+
+	key, err := utils.GetSecretField(secret, corev1.TLSPrivateKeyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from secret: %s, %w", corev1.TLSPrivateKeyKey, name, err)
+	}
+
+	value, _ := clientCerts.LoadOrStore(client.ObjectKeyFromObject(hwmgr), &clientCertContent{name: "dell-hwmgr-client"})
+	content := value.(*clientCertContent)
+	if err := content.update([]byte(cert), []byte(key)); err != nil {
+		if !content.hasContent() {
+			return nil, typederrors.NewSecretError(err, "invalid client certificate in secret '%s': %s", name, err.Error())
+		}
+		logger.WarnContext(ctx, "Rejected rotated client certificate, presenting the previous certificate",
+			slog.String("secret", name), slog.String("error", err.Error()))
+	}
+
+	return content, nil
+}
+
+// RemoveClientCert discards the client certificate content of a HardwareManager, once it has been deleted
+func RemoveClientCert(key types.NamespacedName) {
+	clientCerts.Delete(key)
+}
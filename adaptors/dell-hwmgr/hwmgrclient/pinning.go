@@ -0,0 +1,66 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hwmgrclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// PinnedResourcesExtension is the NodePool extension pinning the nodes of node groups to specific hardware manager
+	// resources, as a JSON map of node group name to resource IDs, such as {"controller": ["res-1", "res-2", "res-3"]}
+	PinnedResourcesExtension = "pinnedResources"
+
+	// ResourceIdKey is the include filter key selecting a resource by its ID
+	ResourceIdKey = "Id"
+)
+
+// GetPinnedResources returns the resource IDs pinned for each node group of the NodePool. As the resources of a
+// resource selector are chosen only through its filters, a pinned node group pins a resource for each of its nodes, and
+// a resource can be pinned only once.
+func GetPinnedResources(nodepool *hwmgmtv1alpha1.NodePool) (map[string][]string, error) {
+	value, exists := nodepool.Spec.Extensions[PinnedResourcesExtension]
+	if !exists {
+		return nil, nil
+	}
+
+	pins := make(map[string][]string)
+	if err := json.Unmarshal([]byte(value), &pins); err != nil {
+		return nil, fmt.Errorf("unable to parse %s extension: %w", PinnedResourcesExtension, err)
+	}
+
+	sizes := make(map[string]int, len(nodepool.Spec.NodeGroup))
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		sizes[nodegroup.NodePoolData.Name] = nodegroup.Size
+	}
+
+	pinned := make(map[string]bool)
+	for name, resourceIds := range pins {
+		size, exists := sizes[name]
+		if !exists {
+			return nil, fmt.Errorf("%s extension pins resources for unknown nodegroup %s", PinnedResourcesExtension, name)
+		}
+		if len(resourceIds) != size {
+			return nil, fmt.Errorf("%s extension pins %d resources for nodegroup %s, which has %d nodes",
+				PinnedResourcesExtension, len(resourceIds), name, size)
+		}
+		for _, resourceId := range resourceIds {
+			if resourceId == "" {
+				return nil, fmt.Errorf("%s extension pins an empty resource ID for nodegroup %s", PinnedResourcesExtension, name)
+			}
+			if pinned[resourceId] {
+				return nil, fmt.Errorf("%s extension pins resource %s more than once", PinnedResourcesExtension, resourceId)
+			}
+			pinned[resourceId] = true
+		}
+	}
+
+	return pins, nil
+}
@@ -0,0 +1,199 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hwmgrclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// ResourceGroupUpdateBody is the request body for updating an existing resource group
+type ResourceGroupUpdateBody struct {
+	Tenant        *string                            `json:"tenant,omitempty"`
+	ResourceGroup *[]hwmgrapi.ApiprotoUpdateResource `json:"ResourceGroup,omitempty"`
+}
+
+// ResourceGroupUpdateFromNodePool builds the operations updating the number of resources requested by each resource
+// selector of the resource group to the sizes of the corresponding node groups
+func (c *HardwareManagerClient) ResourceGroupUpdateFromNodePool(nodepool *hwmgmtv1alpha1.NodePool) *ResourceGroupUpdateBody {
+	tenant := c.GetTenant()
+
+	nodegroups := make([]hwmgmtv1alpha1.NodeGroup, len(nodepool.Spec.NodeGroup))
+	copy(nodegroups, nodepool.Spec.NodeGroup)
+	sort.Slice(nodegroups, func(i, j int) bool { return nodegroups[i].NodePoolData.Name < nodegroups[j].NodePoolData.Name })
+
+	ops := make([]hwmgrapi.ApiprotoUpdateResource, 0, len(nodegroups))
+	for _, nodegroup := range nodegroups {
+		op := "replace"
+		path := fmt.Sprintf("/ResourceGroup/ResourceSelectors/%s/NumResources", nodegroup.NodePoolData.Name)
+		value := []map[string]interface{}{{"numResources": nodegroup.Size}}
+		ops = append(ops, hwmgrapi.ApiprotoUpdateResource{
+			Op:    &op,
+			Path:  &path,
+			Value: &value,
+		})
+	}
+
+	return &ResourceGroupUpdateBody{
+		Tenant:        &tenant,
+		ResourceGroup: &ops,
+	}
+}
+
+// ResourceGroupRemovalFromResources builds the operations removing the given resources from a resource group, and
+// reducing the number of resources requested by each affected resource selector by the number removed from it, so
+// that the hardware manager does not allocate other resources in their place
+func (c *HardwareManagerClient) ResourceGroupRemovalFromResources(
+	rg hwmgrapi.RhprotoResourceGroupObjectGetResponseBody,
+	resourceIds []string) *ResourceGroupUpdateBody {
+
+	tenant := c.GetTenant()
+
+	var ops []hwmgrapi.ApiprotoUpdateResource
+	if rg.ResourceSelectors != nil {
+		selectorNames := make([]string, 0, len(*rg.ResourceSelectors))
+		for name := range *rg.ResourceSelectors {
+			selectorNames = append(selectorNames, name)
+		}
+		sort.Strings(selectorNames)
+
+		for _, name := range selectorNames {
+			selector := (*rg.ResourceSelectors)[name]
+			if selector.Resources == nil {
+				continue
+			}
+
+			remaining := 0
+			for _, resource := range *selector.Resources {
+				if resource.Id == nil || !slices.Contains(resourceIds, *resource.Id) {
+					remaining++
+					continue
+				}
+				op := "remove"
+				path := fmt.Sprintf("/ResourceGroup/ResourceSelectors/%s/Resources/%s", name, *resource.Id)
+				ops = append(ops, hwmgrapi.ApiprotoUpdateResource{
+					Op:   &op,
+					Path: &path,
+				})
+			}
+			if remaining == len(*selector.Resources) {
+				continue
+			}
+
+			op := "replace"
+			path := fmt.Sprintf("/ResourceGroup/ResourceSelectors/%s/NumResources", name)
+			value := []map[string]interface{}{{"numResources": remaining}}
+			ops = append(ops, hwmgrapi.ApiprotoUpdateResource{
+				Op:    &op,
+				Path:  &path,
+				Value: &value,
+			})
+		}
+	}
+
+	return &ResourceGroupUpdateBody{
+		Tenant:        &tenant,
+		ResourceGroup: &ops,
+	}
+}
+
+// UpdateResourceGroup sends a request to the hardware manager to update the number of resources in the existing
+// resource group of a nodepool, returning a jobId.
+func (c *HardwareManagerClient) UpdateResourceGroup(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
+	return c.patchResourceGroup(ctx, ResourceGroupIdFromNodePool(nodepool), c.ResourceGroupUpdateFromNodePool(nodepool))
+}
+
+// RemoveResourcesFromResourceGroup sends a request to the hardware manager to release the given resources from the
+// resource group of a nodepool, returning a jobId
+func (c *HardwareManagerClient) RemoveResourcesFromResourceGroup(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	rg hwmgrapi.RhprotoResourceGroupObjectGetResponseBody,
+	resourceIds []string) (string, error) {
+
+	return c.patchResourceGroup(ctx, ResourceGroupIdFromNodePool(nodepool), c.ResourceGroupRemovalFromResources(rg, resourceIds))
+}
+
+// patchResourceGroup sends an update of an existing resource group to the hardware manager, returning a jobId.
+//
+// The resource group update API is not included in the generated client, so the request is built here, using the
+// server, HTTP client and request editors (authentication and correlation ID) of the generated client.
+func (c *HardwareManagerClient) patchResourceGroup(ctx context.Context, rgId string, update *ResourceGroupUpdateBody) (string, error) {
+	tenant := c.GetTenant()
+
+	apiClient, ok := c.HwmgrClient.ClientInterface.(*hwmgrapi.Client)
+	if !ok {
+		return "", fmt.Errorf("failed to update resource group %s: unexpected client type %T", rgId, c.HwmgrClient.ClientInterface)
+	}
+
+	buf, err := json.Marshal(update)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode update for resource group %s: %w", rgId, err)
+	}
+
+	serverURL, err := url.Parse(apiClient.Server)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse server url %s: %w", apiClient.Server, err)
+	}
+
+	queryURL, err := serverURL.Parse(fmt.Sprintf("./v1/tenants/%s/resourcegroups/%s",
+		url.PathEscape(tenant), url.PathEscape(rgId)))
+	if err != nil {
+		return "", fmt.Errorf("failed to build url for resource group %s: %w", rgId, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, queryURL.String(), bytes.NewReader(buf))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request for resource group %s: %w", rgId, err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	for _, editor := range apiClient.RequestEditors {
+		if err := editor(ctx, req); err != nil {
+			return "", fmt.Errorf("failed to prepare request for resource group %s: %w", rgId, err)
+		}
+	}
+
+	response, err := apiClient.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to update resource group %s, api failure: err: %w", rgId, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response for resource group %s: %w", rgId, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		c.Logger.InfoContext(ctx, "Failure from UpdateResourceGroup", slog.String("response", string(body)))
+		return "", fmt.Errorf("failed to update resource group %s, bad status: %s, code: %d, message=%s",
+			rgId, response.Status, response.StatusCode, string(body))
+	}
+
+	var rgResponse hwmgrapi.ApiprotoResponse
+	if err := json.Unmarshal(body, &rgResponse); err != nil {
+		return "", fmt.Errorf("failed to decode response for resource group %s: %w", rgId, err)
+	}
+
+	if rgResponse.Jobid == nil {
+		return "", fmt.Errorf("resource group %s update response is missing the job ID", rgId)
+	}
+
+	return *rgResponse.Jobid, nil
+}
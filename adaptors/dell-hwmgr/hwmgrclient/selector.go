@@ -0,0 +1,82 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hwmgrclient
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// ExcludeSelectorPrefix marks a resourceSelector key as an exclusion, such as {"!rack": "r12"}, keeping the resources
+// with the label value out of the node group. An exclusion on the ResourceIdKey keeps a specific resource out.
+const ExcludeSelectorPrefix = "!"
+
+// ParseResourceSelector parses the resourceSelector of a node group, returning the labels its resources must have and
+// the labels they must not have. The hardware manager filters resources on exact label values, so quantity
+// comparisons are not supported.
+func ParseResourceSelector(selector string) (includes, excludes map[string]string, err error) {
+	includes = make(map[string]string)
+	excludes = make(map[string]string)
+
+	requirements, err := utils.ParseResourceSelector(selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse resourceSelector %s: %w", selector, err)
+	}
+
+	for _, requirement := range requirements {
+		if !requirement.IsExactMatch() {
+			return nil, nil, fmt.Errorf("resourceSelector %s compares %s by quantity, which is not supported by the hardware manager",
+				selector, requirement.Key)
+		}
+
+		key, value := requirement.Key, requirement.Value
+		if excluded, found := strings.CutPrefix(key, ExcludeSelectorPrefix); found {
+			if excluded == "" {
+				return nil, nil, fmt.Errorf("resourceSelector %s has an exclusion with no label key", selector)
+			}
+			excludes[excluded] = value
+		} else {
+			includes[key] = value
+		}
+	}
+
+	for key, value := range excludes {
+		if includes[key] == value {
+			return nil, nil, fmt.Errorf("resourceSelector %s both includes and excludes %s=%s", selector, key, value)
+		}
+	}
+
+	return includes, excludes, nil
+}
+
+// excludeFilter builds the exclude filter of a resource selector, which lists its labels in the same form as the
+// include filter
+func excludeFilter(excludes map[string]string) map[string]interface{} {
+	filter := make(map[string]interface{})
+	if len(excludes) == 0 {
+		return filter
+	}
+
+	keys := make([]string, 0, len(excludes))
+	for key := range excludes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labels := make([]hwmgrapi.RhprotoResourceSelectorFilterIncludeLabel, 0, len(keys))
+	for _, key := range keys {
+		key, value := key, excludes[key]
+		labels = append(labels, hwmgrapi.RhprotoResourceSelectorFilterIncludeLabel{Key: &key, Value: &value})
+	}
+	filter["labels"] = labels
+
+	return filter
+}
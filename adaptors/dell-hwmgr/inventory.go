@@ -11,14 +11,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 
 	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
 	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"github.com/samber/lo"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func getResourceInfoAdminState(resource hwmgrapi.ApiprotoResource) invserver.ResourceInfoAdminState {
@@ -40,6 +45,18 @@ func getResourceInfoAdminState(resource hwmgrapi.ApiprotoResource) invserver.Res
 	}
 }
 
+func getResourceInfoAllocated(allocation *utils.ResourceAllocation) *bool {
+	allocated := allocation != nil
+	return &allocated
+}
+
+func getResourceInfoCloudId(allocation *utils.ResourceAllocation) *string {
+	if allocation == nil || allocation.CloudID == "" {
+		return nil
+	}
+	return &allocation.CloudID
+}
+
 func getResourceInfoDescription(resource hwmgrapi.ApiprotoResource) string {
 	if resource.Description == nil {
 		return ""
@@ -98,6 +115,20 @@ func getResourceInfoName(resource hwmgrapi.ApiprotoResource) string {
 	return *resource.Name
 }
 
+func getResourceInfoNodeName(allocation *utils.ResourceAllocation) *string {
+	if allocation == nil || allocation.NodeName == "" {
+		return nil
+	}
+	return &allocation.NodeName
+}
+
+func getResourceInfoNodePoolName(allocation *utils.ResourceAllocation) *string {
+	if allocation == nil || allocation.NodePoolName == "" {
+		return nil
+	}
+	return &allocation.NodePoolName
+}
+
 func getResourceInfoOperationalState(resource hwmgrapi.ApiprotoResource) invserver.ResourceInfoOperationalState {
 	if resource.OpState == nil {
 		return invserver.ResourceInfoOperationalStateUNKNOWN
@@ -168,6 +199,121 @@ func getResourceInfoProcessors(server *hwmgrapi.ApiprotoServer) []invserver.Proc
 	return processors
 }
 
+func getNetworkInterfaceInfoLabels(port ExtensionPort) *[]string {
+	labels := []string{}
+	for _, label := range port.Labels {
+		if label.Key == LabelLabelKey && label.Value != "" {
+			labels = append(labels, label.Value)
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+	return &labels
+}
+
+func getNetworkInterfaceInfoName(port ExtensionPort) *string {
+	for _, label := range port.Labels {
+		if label.Key == LabelNameKey {
+			return &label.Value
+		}
+	}
+	return nil
+}
+
+func getNetworkInterfaceInfoSpeed(port ExtensionPort) *int {
+	if port.MBPS == 0 {
+		return nil
+	}
+	return &port.MBPS
+}
+
+// getNetworkAdapterModel returns the model of the network adapter with a port using the given MAC address
+func getNetworkAdapterModel(server *hwmgrapi.ApiprotoServer, macAddress string) *string {
+	if server.Status.NIC == nil {
+		return nil
+	}
+
+	for _, adapter := range *server.Status.NIC {
+		if adapter.NetworkPorts == nil {
+			continue
+		}
+		for _, port := range *adapter.NetworkPorts {
+			if port.AssociatedNetworkAddresses != nil &&
+				slices.ContainsFunc(*port.AssociatedNetworkAddresses, func(address string) bool {
+					return strings.EqualFold(address, macAddress)
+				}) {
+				return adapter.Model
+			}
+		}
+	}
+	return nil
+}
+
+// getServerInterfaces builds the network interfaces from the ethernet interfaces reported in the server inventory,
+// for resources whose extensions carry no interface data
+func getServerInterfaces(server *hwmgrapi.ApiprotoServer) *[]invserver.NetworkInterfaceInfo {
+	if server == nil || server.Status == nil || server.Status.EthernetInterface == nil {
+		return nil
+	}
+
+	interfaces := []invserver.NetworkInterfaceInfo{}
+	for _, eth := range *server.Status.EthernetInterface {
+		macAddress := ""
+		switch {
+		case eth.MACAddress != nil && *eth.MACAddress != "":
+			macAddress = *eth.MACAddress
+		case eth.PermanentMACAddress != nil:
+			macAddress = *eth.PermanentMACAddress
+		}
+		if macAddress == "" {
+			continue
+		}
+
+		var speed *int
+		if eth.SpeedMbps != nil && *eth.SpeedMbps > 0 {
+			mbps := int(*eth.SpeedMbps)
+			speed = &mbps
+		}
+
+		name := eth.Name
+		if name == nil {
+			name = eth.ID
+		}
+
+		interfaces = append(interfaces, invserver.NetworkInterfaceInfo{
+			MacAddress: macAddress,
+			Model:      getNetworkAdapterModel(server, macAddress),
+			Name:       name,
+			Speed:      speed,
+		})
+	}
+	return &interfaces
+}
+
+func getResourceInfoInterfaces(resource hwmgrapi.ApiprotoResource, server *hwmgrapi.ApiprotoServer) *[]invserver.NetworkInterfaceInfo {
+	extensionInterfaces, err := parseExtensionsNics(resource.Extensions)
+	if err != nil {
+		// Interface data is optional in the resource extensions, so fall back to the server inventory
+		return getServerInterfaces(server)
+	}
+
+	interfaces := []invserver.NetworkInterfaceInfo{}
+	for _, extIntf := range extensionInterfaces {
+		for _, port := range extIntf.Ports {
+			interfaces = append(interfaces, invserver.NetworkInterfaceInfo{
+				Labels:     getNetworkInterfaceInfoLabels(port),
+				MacAddress: port.MACAddress,
+				Model:      &extIntf.Model,
+				Name:       getNetworkInterfaceInfoName(port),
+				Speed:      getNetworkInterfaceInfoSpeed(port),
+			})
+		}
+	}
+	return &interfaces
+}
+
 func getResourceInfoResourceId(resource hwmgrapi.ApiprotoResource) string {
 	if resource.Res == nil || resource.Res.Id == nil {
 		return ""
@@ -196,6 +342,50 @@ func getResourceInfoSerialNumber(server *hwmgrapi.ApiprotoServer) string {
 	return *server.Status.SerialNumber
 }
 
+func getStorageInfoCapacity(drive hwmgrapi.ApiprotoDrive) *int64 {
+	if drive.CapacityBytes == nil {
+		return nil
+	}
+
+	capacity, err := strconv.ParseInt(*drive.CapacityBytes, 10, 64)
+	if err != nil || capacity <= 0 {
+		return nil
+	}
+	return &capacity
+}
+
+func getStorageInfoProtocol(drive hwmgrapi.ApiprotoDrive) *string {
+	if drive.Protocol != nil && *drive.Protocol != "" {
+		return drive.Protocol
+	}
+	return drive.DeviceProtocol
+}
+
+func getResourceInfoStorage(server *hwmgrapi.ApiprotoServer) *[]invserver.StorageInfo {
+	if server == nil || server.Status == nil || server.Status.Storage == nil {
+		return nil
+	}
+
+	storage := []invserver.StorageInfo{}
+	for _, controller := range *server.Status.Storage {
+		if controller.Drives == nil {
+			continue
+		}
+		for _, drive := range *controller.Drives {
+			storage = append(storage, invserver.StorageInfo{
+				Capacity:     getStorageInfoCapacity(drive),
+				Model:        drive.Model,
+				Name:         drive.Name,
+				Protocol:     getStorageInfoProtocol(drive),
+				SerialNumber: drive.SerialNumber,
+				Type:         drive.MediaType,
+				Vendor:       drive.Manufacturer,
+			})
+		}
+	}
+	return &storage
+}
+
 func getResourceInfoTags(resource hwmgrapi.ApiprotoResource) *[]string {
 	return resource.Tags
 }
@@ -224,17 +414,27 @@ func getResourceInfoVendor(server *hwmgrapi.ApiprotoServer) string {
 	return *server.Status.Manufacturer
 }
 
-func getResourceInfo(resource hwmgrapi.ApiprotoResource, server *hwmgrapi.ApiprotoServer) invserver.ResourceInfo {
+// getResourceInfo returns the inventory data of a resource and its server, along with its allocation, if it is in a
+// resource group
+func getResourceInfo(
+	resource hwmgrapi.ApiprotoResource,
+	server *hwmgrapi.ApiprotoServer,
+	allocation *utils.ResourceAllocation) invserver.ResourceInfo {
 	return invserver.ResourceInfo{
 		AdminState:       getResourceInfoAdminState(resource),
+		Allocated:        getResourceInfoAllocated(allocation),
+		CloudId:          getResourceInfoCloudId(allocation),
 		Description:      getResourceInfoDescription(resource),
 		GlobalAssetId:    getResourceInfoGlobalAssetId(resource),
 		Groups:           getResourceInfoGroups(resource),
 		HwProfile:        getResourceInfoResourceProfileId(resource),
+		Interfaces:       getResourceInfoInterfaces(resource, server),
 		Labels:           getResourceInfoLabels(resource),
 		Memory:           getResourceInfoMemory(server),
 		Model:            getResourceInfoModel(server),
 		Name:             getResourceInfoName(resource),
+		NodeName:         getResourceInfoNodeName(allocation),
+		NodePoolName:     getResourceInfoNodePoolName(allocation),
 		OperationalState: getResourceInfoOperationalState(resource),
 		PartNumber:       getResourceInfoPartNumber(server),
 		PowerState:       getResourceInfoPowerState(server),
@@ -242,24 +442,75 @@ func getResourceInfo(resource hwmgrapi.ApiprotoResource, server *hwmgrapi.Apipro
 		ResourceId:       getResourceInfoResourceId(resource),
 		ResourcePoolId:   getResourceInfoResourcePoolId(resource),
 		SerialNumber:     getResourceInfoSerialNumber(server),
+		Storage:          getResourceInfoStorage(server),
 		Tags:             getResourceInfoTags(resource),
 		UsageState:       getResourceInfoUsageState(resource),
 		Vendor:           getResourceInfoVendor(server),
 	}
 }
 
+// indexServersByResource returns the server backing each resource of the hardware manager, by resource ID, along with
+// the IDs of the resources for which no server was found. Resources are matched to servers by name.
+func indexServersByResource(resources *hwmgrapi.ApiprotoGetResourcesResp,
+	servers *hwmgrapi.ApiprotoGetServersInventoryResp) (map[string]*hwmgrapi.ApiprotoServer, []string) {
+
+	serversByName := make(map[string]*hwmgrapi.ApiprotoServer)
+	if servers != nil && servers.Servers != nil {
+		for i := range *servers.Servers {
+			server := &(*servers.Servers)[i]
+			if server.Metadata != nil && server.Metadata.Name != nil {
+				serversByName[*server.Metadata.Name] = server
+			}
+		}
+	}
+
+	resourceServers := make(map[string]*hwmgrapi.ApiprotoServer)
+	var unmatched []string
+	if resources != nil && resources.Resources != nil {
+		for _, resource := range *resources.Resources {
+			if resource.Id == nil {
+				continue
+			}
+			if resource.Name != nil {
+				if server, exists := serversByName[*resource.Name]; exists {
+					resourceServers[*resource.Id] = server
+					continue
+				}
+			}
+			unmatched = append(unmatched, *resource.Id)
+		}
+	}
+	return resourceServers, unmatched
+}
+
 func (a *Adaptor) FindAllocatedServers(ctx context.Context, hwmgrClient *hwmgrclient.HardwareManagerClient) ([]string, error) {
 	allocatedServers := []string{}
 
+	members, err := a.findResourceGroupMembers(ctx, hwmgrClient)
+	if err != nil {
+		return allocatedServers, err
+	}
+
+	for resourceId := range members {
+		allocatedServers = append(allocatedServers, resourceId)
+	}
+
+	return allocatedServers, nil
+}
+
+// findResourceGroupMembers returns the ID of the resource group of each resource in a resource group, by resource ID
+func (a *Adaptor) findResourceGroupMembers(ctx context.Context, hwmgrClient *hwmgrclient.HardwareManagerClient) (map[string]string, error) {
+	members := make(map[string]string)
+
 	resourceGroups, err := hwmgrClient.GetResourceGroups(ctx)
 	if err != nil {
 		a.Logger.InfoContext(ctx, "GetResourceGroups error", slog.String("error", err.Error()))
-		return allocatedServers, fmt.Errorf("unable to query resource groups: %w", err)
+		return members, fmt.Errorf("unable to query resource groups: %w", err)
 	}
 
 	if resourceGroups.ResourceGroups == nil {
 		a.Logger.InfoContext(ctx, "ResourceGroups returned from query is nil")
-		return allocatedServers, nil
+		return members, nil
 	}
 
 	for _, iter := range *resourceGroups.ResourceGroups {
@@ -270,7 +521,7 @@ func (a *Adaptor) FindAllocatedServers(ctx context.Context, hwmgrClient *hwmgrcl
 		rg, err := hwmgrClient.GetResourceGroupFromId(ctx, *iter.Id)
 		if err != nil {
 			a.Logger.InfoContext(ctx, "Failed GetResourceGroup", slog.String("error", err.Error()))
-			return allocatedServers, fmt.Errorf("unable to query resource group %s: %w", *iter.Id, err)
+			return members, fmt.Errorf("unable to query resource group %s: %w", *iter.Id, err)
 		}
 
 		if rg.ResourceSelectors == nil {
@@ -280,13 +531,53 @@ func (a *Adaptor) FindAllocatedServers(ctx context.Context, hwmgrClient *hwmgrcl
 		for _, resourceSelector := range *rg.ResourceSelectors {
 			for _, node := range *resourceSelector.Resources {
 				if node.Id != nil {
-					allocatedServers = append(allocatedServers, *node.Id)
+					members[*node.Id] = *iter.Id
 				}
 			}
 		}
 	}
 
-	return allocatedServers, nil
+	return members, nil
+}
+
+// getResourceAllocations returns the allocation of each resource in a resource group, by resource ID. A resource in a
+// resource group not created for a NodePool of the HardwareManager is allocated, with no NodePool.
+func (a *Adaptor) getResourceAllocations(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager) (map[string]utils.ResourceAllocation, error) {
+
+	members, err := a.findResourceGroupMembers(ctx, hwmgrClient)
+	if err != nil {
+		return nil, err
+	}
+
+	nodepools, err := utils.GetNodePoolAllocations(ctx, a.Client, a.Namespace, hwmgr.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NodePool allocations: %w", err)
+	}
+
+	resourceGroups := make(map[string]utils.ResourceAllocation)
+	for _, allocation := range nodepools {
+		nodepool := &hwmgmtv1alpha1.NodePool{Spec: hwmgmtv1alpha1.NodePoolSpec{CloudID: allocation.CloudID}}
+		resourceGroups[hwmgrclient.ResourceGroupIdFromNodePool(nodepool)] = allocation
+	}
+
+	nodes, err := utils.GetNodeAllocations(ctx, a.Client, a.Namespace, hwmgr.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Node allocations: %w", err)
+	}
+
+	allocations := make(map[string]utils.ResourceAllocation)
+	for resourceId, rgId := range members {
+		if allocation, exists := nodes[types.NamespacedName{Name: resourceId}]; exists {
+			allocations[resourceId] = allocation
+			continue
+		}
+		allocations[resourceId] = resourceGroups[rgId]
+	}
+
+	return allocations, nil
 }
 
 // labelsMatch checks the set of labels for a given match
@@ -304,9 +595,16 @@ func labelsMatch(labels *[]hwmgrapi.ApiprotoLabel, key, value string) bool {
 	return false
 }
 
-func checkResourceSelectors(labels *[]hwmgrapi.ApiprotoLabel, resourceSelectors map[string]string) bool {
+// checkResourceSelectors checks a resource against the resourceSelector of a nodegroup, which may exclude label values,
+// or specific resources by ID, with keys prefixed with hwmgrclient.ExcludeSelectorPrefix
+func checkResourceSelectors(resource hwmgrapi.ApiprotoResource, resourceSelectors map[string]string) bool {
 	for key, value := range resourceSelectors {
-		if !labelsMatch(labels, key, value) {
+		if excluded, found := strings.CutPrefix(key, hwmgrclient.ExcludeSelectorPrefix); found {
+			if labelsMatch(resource.Labels, excluded, value) ||
+				(excluded == hwmgrclient.ResourceIdKey && lo.FromPtr(resource.Id) == value) {
+				return false
+			}
+		} else if !labelsMatch(resource.Labels, key, value) {
 			return false
 		}
 	}
@@ -331,7 +629,7 @@ func findFreeServersInPool(
 			continue
 		}
 
-		if !checkResourceSelectors(resource.Labels, resourceSelectors) {
+		if !checkResourceSelectors(resource, resourceSelectors) {
 			// Server doesn't match criteria
 			continue
 		}
@@ -341,14 +639,34 @@ func findFreeServersInPool(
 	return freeServers
 }
 
+// poolInSite checks whether a pool belongs to the given site. If no site is specified, any pool is accepted.
+func poolInSite(pool hwmgrapi.ApiprotoResourcePool, site string) bool {
+	return site == "" || (pool.SiteId != nil && *pool.SiteId == site)
+}
+
+// sitePoolCount returns the number of pools that belong to the given site
+func sitePoolCount(pools *hwmgrapi.ApiprotoResourcePoolsResp, site string) int {
+	count := 0
+	for _, pool := range *pools.ResourcePools {
+		if pool.Id != nil && poolInSite(pool, site) {
+			count++
+		}
+	}
+	return count
+}
+
 func findMatchingPool(
 	pools *hwmgrapi.ApiprotoResourcePoolsResp,
 	allocatedServers []string,
 	resources *hwmgrapi.ApiprotoGetResourcesResp,
 	resourceSelectors map[string]string,
-	numServers int) string {
+	numServers int,
+	site string) string {
 
 	for _, pool := range *pools.ResourcePools {
+		if pool.Id == nil || !poolInSite(pool, site) {
+			continue
+		}
 		freeServers := findFreeServersInPool(allocatedServers, resources, resourceSelectors, *pool.Id)
 		if len(freeServers) >= numServers {
 			return *pool.Id
@@ -358,31 +676,96 @@ func findMatchingPool(
 	return ""
 }
 
-func poolExists(
+func findPool(
 	pools *hwmgrapi.ApiprotoResourcePoolsResp,
-	pool string) bool {
+	pool string) *hwmgrapi.ApiprotoResourcePool {
 
 	for _, iter := range *pools.ResourcePools {
 		if iter.Id != nil && *iter.Id == pool {
-			return true
+			return &iter
 		}
 	}
 
-	return false
+	return nil
+}
+
+// findPinnedResourcesPool checks that the resources pinned for a nodegroup exist, match its criteria, and are not
+// allocated to another resource group, and returns the pool they belong to. All of the pinned resources of a nodegroup
+// must be in the same pool, in the site of the NodePool.
+func findPinnedResourcesPool(
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodegroup hwmgmtv1alpha1.NodeGroup,
+	resourceIds []string,
+	members map[string]string,
+	pools *hwmgrapi.ApiprotoResourcePoolsResp,
+	resources *hwmgrapi.ApiprotoGetResourcesResp,
+	resourceSelectors map[string]string) (string, error) {
+
+	name := nodegroup.NodePoolData.Name
+	rgId := hwmgrclient.ResourceGroupIdFromNodePool(nodepool)
+
+	selectedPool := nodegroup.NodePoolData.ResourcePoolId
+	for _, resourceId := range resourceIds {
+		resource, found := lo.Find(*resources.Resources, func(resource hwmgrapi.ApiprotoResource) bool {
+			return resource.Id != nil && *resource.Id == resourceId
+		})
+		if !found {
+			return "", typederrors.NewNonRetriableError(nil,
+				"pinned resource %s does not exist on hardware manager, nodegroup: %s", resourceId, name)
+		}
+
+		if owner := members[resourceId]; owner != "" && owner != rgId {
+			return "", typederrors.NewNonRetriableError(nil,
+				"pinned resource %s is allocated to resource group %s, nodegroup: %s", resourceId, owner, name)
+		}
+
+		if !checkResourceSelectors(resource, resourceSelectors) {
+			return "", typederrors.NewNonRetriableError(nil,
+				"pinned resource %s does not match resourceSelector %s, nodegroup: %s",
+				resourceId, nodegroup.NodePoolData.ResourceSelector, name)
+		}
+
+		pool := lo.FromPtr(resource.ResourcePoolId)
+		if selectedPool == "" {
+			selectedPool = pool
+		} else if pool != selectedPool {
+			return "", typederrors.NewNonRetriableError(nil,
+				"pinned resource %s is in pool %s rather than %s, nodegroup: %s", resourceId, pool, selectedPool, name)
+		}
+	}
+
+	found := findPool(pools, selectedPool)
+	if found == nil {
+		return "", typederrors.NewNonRetriableError(nil, "pool %s of pinned resources does not exist on hardware manager, nodegroup: %s",
+			selectedPool, name)
+	}
+	if !poolInSite(*found, nodepool.Spec.Site) {
+		return "", typederrors.NewNonRetriableError(nil, "pool %s of pinned resources is not in site %s, nodegroup: %s",
+			selectedPool, nodepool.Spec.Site, name)
+	}
+
+	return selectedPool, nil
 }
 
-// FindResourcePoolId checks the hardware manager inventory to find a pool with free resources that match the criteria
+// FindResourcePoolIds checks the hardware manager inventory to find a pool with free resources that match the criteria
+// for each nodegroup, restricted to the pools in the site requested by the NodePool
 func (a *Adaptor) FindResourcePoolIds(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
 
-	allocatedServers, err := a.FindAllocatedServers(ctx, hwmgrClient)
+	members, err := a.findResourceGroupMembers(ctx, hwmgrClient)
 	if err != nil {
-		a.Logger.InfoContext(ctx, "FindAllocatedServers error", slog.String("error", err.Error()))
+		a.Logger.InfoContext(ctx, "findResourceGroupMembers error", slog.String("error", err.Error()))
 		return typederrors.NewRetriableError(err, "unable to determine list of allocated servers")
 
 	}
+	allocatedServers := lo.Keys(members)
+
+	pins, err := hwmgrclient.GetPinnedResources(nodepool)
+	if err != nil {
+		return typederrors.NewNonRetriableError(err, "invalid pinned resources")
+	}
 
 	pools, err := hwmgrClient.GetResourcePools(ctx)
 	if err != nil {
@@ -396,6 +779,11 @@ func (a *Adaptor) FindResourcePoolIds(
 		return typederrors.NewRetriableError(err, "unable to query resources")
 	}
 
+	site := nodepool.Spec.Site
+	if sitePoolCount(pools, site) == 0 {
+		return typederrors.NewNonRetriableError(nil, "no resource pools found on hardware manager for site: %s", site)
+	}
+
 	if nodepool.Status.SelectedPools == nil {
 		nodepool.Status.SelectedPools = make(map[string]string)
 	}
@@ -417,13 +805,26 @@ func (a *Adaptor) FindResourcePoolIds(
 			}
 		}
 
-		if nodegroup.NodePoolData.ResourcePoolId != "" {
+		if resourceIds := pins[nodegroup.NodePoolData.Name]; len(resourceIds) > 0 {
+			// The nodegroup is pinned to specific resources, so use their pool
+			pool, err := findPinnedResourcesPool(nodepool, nodegroup, resourceIds, members, pools, resources, resourceSelectors)
+			if err != nil {
+				return err
+			}
+
+			nodepool.Status.SelectedPools[nodegroup.NodePoolData.Name] = pool
+			a.Logger.InfoContext(ctx, "Setting pool from pinned resources", slog.String("pool", pool))
+		} else if nodegroup.NodePoolData.ResourcePoolId != "" {
 			// There's a pool specified in the nodegroup, so use it
 
-			// Check whether the pool exists on hardware manager
-			if !poolExists(pools, nodegroup.NodePoolData.ResourcePoolId) {
+			// Check whether the pool exists on hardware manager, in the requested site
+			pool := findPool(pools, nodegroup.NodePoolData.ResourcePoolId)
+			if pool == nil {
 				return typederrors.NewNonRetriableError(nil, "pool specified in nodegroup does not exist on hardware manager, nodegroup: %s", nodegroup.NodePoolData.Name)
 			}
+			if !poolInSite(*pool, site) {
+				return typederrors.NewNonRetriableError(nil, "pool specified in nodegroup is not in site %s, nodegroup: %s", site, nodegroup.NodePoolData.Name)
+			}
 
 			if nodegroup.Size > 0 {
 				// Check whether there are free servers that match the specified criteria
@@ -436,9 +837,10 @@ func (a *Adaptor) FindResourcePoolIds(
 			nodepool.Status.SelectedPools[nodegroup.NodePoolData.Name] = nodegroup.NodePoolData.ResourcePoolId
 			a.Logger.InfoContext(ctx, "Setting pool from nodegroup", slog.String("pool", nodepool.Status.SelectedPools[nodegroup.NodePoolData.Name]))
 		} else {
-			matchingPool := findMatchingPool(pools, allocatedServers, resources, resourceSelectors, nodegroup.Size)
+			matchingPool := findMatchingPool(pools, allocatedServers, resources, resourceSelectors, nodegroup.Size, site)
 			if matchingPool == "" {
-				return typederrors.NewNonRetriableError(nil, "unable to find pool matching criteria: resourceSelector: %s", nodegroup.NodePoolData.ResourceSelector)
+				return typederrors.NewNonRetriableError(nil, "unable to find pool in site %s with capacity matching criteria: nodegroup: %s, resourceSelector: %s",
+					site, nodegroup.NodePoolData.Name, nodegroup.NodePoolData.ResourceSelector)
 			}
 
 			nodepool.Status.SelectedPools[nodegroup.NodePoolData.Name] = matchingPool
@@ -447,7 +849,7 @@ func (a *Adaptor) FindResourcePoolIds(
 		statusUpdated = true
 	}
 
-	if statusUpdated {
+	if statusUpdated && !utils.IsNodePoolDryRun(nodepool) {
 		if err := utils.UpdateNodePoolSelectedPools(ctx, a.Client, nodepool); err != nil {
 			return typederrors.NewNonRetriableError(err, "failed to update status for NodePool %s", nodepool.Name)
 		}
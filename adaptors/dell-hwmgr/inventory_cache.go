@@ -0,0 +1,207 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// InventoryCacheTTL is the age at which cached inventory is no longer served
+	InventoryCacheTTL = 5 * time.Minute
+
+	// InventoryCacheRefreshInterval is how often cached inventory is refreshed in the background
+	InventoryCacheRefreshInterval = 2 * time.Minute
+
+	// InventoryCacheIdleTimeout is how long inventory is kept, and refreshed, after it was last requested
+	InventoryCacheIdleTimeout = 30 * time.Minute
+)
+
+var inventoryUnmatchedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hwmgr_plugin_dell_inventory_unmatched_resources",
+	Help: "Number of resources of a hardware manager omitted from the inventory as no server was found for them",
+}, []string{"hwmgr"})
+
+func init() {
+	metrics.Registry.MustRegister(inventoryUnmatchedResources)
+}
+
+// inventoryCacheEntry holds the inventory of one HardwareManager
+type inventoryCacheEntry struct {
+	generation   int64
+	pools        []invserver.ResourcePoolInfo
+	resources    []invserver.ResourceInfo
+	servers      map[string]*hwmgrapi.ApiprotoServer
+	refreshedAt  time.Time
+	lastAccessed time.Time
+}
+
+// InventoryCache holds the inventory of each HardwareManager, so that the inventory server is not bound by the
+// response time and rate limits of the hardware manager API
+type InventoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*inventoryCacheEntry
+}
+
+func NewInventoryCache() *InventoryCache {
+	return &InventoryCache{
+		entries: make(map[string]*inventoryCacheEntry),
+	}
+}
+
+// get returns the cached inventory for the HardwareManager, if it is current, and records the access
+func (c *InventoryCache) get(hwmgr *pluginv1alpha1.HardwareManager) (*inventoryCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[hwmgr.Name]
+	if !exists {
+		return nil, false
+	}
+
+	entry.lastAccessed = time.Now()
+	if entry.generation != hwmgr.Generation || time.Since(entry.refreshedAt) > InventoryCacheTTL {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// getServers returns the cached server of each resource of the HardwareManager, by resource ID, if it is current. As the
+// lookup is not made for the inventory API, it does not record an access that would keep the cache refreshed.
+func (c *InventoryCache) getServers(hwmgr *pluginv1alpha1.HardwareManager) (map[string]*hwmgrapi.ApiprotoServer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[hwmgr.Name]
+	if !exists || entry.generation != hwmgr.Generation || time.Since(entry.refreshedAt) > InventoryCacheTTL {
+		return nil, false
+	}
+
+	return entry.servers, true
+}
+
+// set stores the inventory for the HardwareManager
+func (c *InventoryCache) set(
+	hwmgr *pluginv1alpha1.HardwareManager,
+	pools []invserver.ResourcePoolInfo,
+	resources []invserver.ResourceInfo,
+	servers map[string]*hwmgrapi.ApiprotoServer) *inventoryCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &inventoryCacheEntry{
+		generation:   hwmgr.Generation,
+		pools:        pools,
+		resources:    resources,
+		servers:      servers,
+		refreshedAt:  time.Now(),
+		lastAccessed: time.Now(),
+	}
+	if existing, exists := c.entries[hwmgr.Name]; exists {
+		entry.lastAccessed = existing.lastAccessed
+	}
+	c.entries[hwmgr.Name] = entry
+
+	return entry
+}
+
+// active returns the names of the HardwareManagers whose inventory has been requested recently, dropping the rest
+func (c *InventoryCache) active() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var names []string
+	for name, entry := range c.entries {
+		if time.Since(entry.lastAccessed) > InventoryCacheIdleTimeout {
+			delete(c.entries, name)
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// remove drops the cached inventory for a HardwareManager
+func (c *InventoryCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, name)
+}
+
+// getInventory returns the inventory of the HardwareManager, querying the hardware manager if the cached copy is not
+// current
+func (a *Adaptor) getInventory(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*inventoryCacheEntry, int, error) {
+	if entry, ok := a.inventoryCache.get(hwmgr); ok {
+		return entry, http.StatusOK, nil
+	}
+
+	return a.refreshInventory(ctx, hwmgr)
+}
+
+// refreshInventory queries the hardware manager for the pools and resources of the HardwareManager and caches them
+func (a *Adaptor) refreshInventory(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*inventoryCacheEntry, int, error) {
+	pools, status, err := a.fetchResourcePools(ctx, hwmgr)
+	if err != nil {
+		return nil, status, err
+	}
+
+	resources, servers, status, err := a.fetchResources(ctx, hwmgr)
+	if err != nil {
+		return nil, status, err
+	}
+
+	return a.inventoryCache.set(hwmgr, pools, resources, servers), http.StatusOK, nil
+}
+
+// refreshInventoryCache refreshes the cached inventory of each HardwareManager that is in use
+func (a *Adaptor) refreshInventoryCache(ctx context.Context) {
+	for _, name := range a.inventoryCache.active() {
+		hwmgr := &pluginv1alpha1.HardwareManager{}
+		if err := a.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: a.Namespace}, hwmgr); err != nil {
+			a.Logger.InfoContext(ctx, "Unable to get HardwareManager, dropping cached inventory",
+				slog.String("hwmgr", name), slog.String("error", err.Error()))
+			a.inventoryCache.remove(name)
+			continue
+		}
+
+		if _, _, err := a.refreshInventory(ctx, hwmgr); err != nil {
+			// Keep serving the cached inventory until it expires
+			a.Logger.InfoContext(ctx, "Failed to refresh cached inventory",
+				slog.String("hwmgr", name), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// runInventoryCacheRefresh refreshes the cached inventory periodically, until the context is cancelled
+func (a *Adaptor) runInventoryCacheRefresh(ctx context.Context) error {
+	ticker := time.NewTicker(InventoryCacheRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.refreshInventoryCache(logging.WithCorrelationID(ctx, logging.NewCorrelationID()))
+		}
+	}
+}
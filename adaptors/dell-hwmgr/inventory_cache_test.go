@@ -0,0 +1,177 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+)
+
+func newInventoryCacheTestHwMgr(name string, generation int64) *pluginv1alpha1.HardwareManager {
+	return &pluginv1alpha1.HardwareManager{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Generation: generation},
+	}
+}
+
+// setInventoryCacheTestEntry caches an inventory for the HardwareManager, refreshed and last accessed the given time ago
+func setInventoryCacheTestEntry(cache *InventoryCache, hwmgr *pluginv1alpha1.HardwareManager, refreshedAgo, accessedAgo time.Duration) {
+	cache.set(hwmgr,
+		[]invserver.ResourcePoolInfo{{ResourcePoolId: "pool-1"}},
+		[]invserver.ResourceInfo{{ResourceId: "node-1"}},
+		map[string]*hwmgrapi.ApiprotoServer{"node-1": {}})
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry := cache.entries[hwmgr.Name]
+	entry.refreshedAt = time.Now().Add(-refreshedAgo)
+	entry.lastAccessed = time.Now().Add(-accessedAgo)
+}
+
+func TestInventoryCache(t *testing.T) {
+	tests := []struct {
+		description  string
+		refreshedAgo time.Duration
+		accessedAgo  time.Duration
+		// generation is the generation of the HardwareManager when the cache is read, which was 1 when it was filled
+		generation int64
+		// invalidate is applied to the cache after it is filled
+		invalidate func(cache *InventoryCache)
+		current    bool
+		active     bool
+	}{
+		{
+			description: "fresh inventory",
+			generation:  1,
+			current:     true,
+			active:      true,
+		},
+		{
+			description:  "inventory within the TTL",
+			refreshedAgo: InventoryCacheTTL - time.Minute,
+			accessedAgo:  InventoryCacheTTL - time.Minute,
+			generation:   1,
+			current:      true,
+			active:       true,
+		},
+		{
+			description:  "inventory past the TTL",
+			refreshedAgo: InventoryCacheTTL + time.Second,
+			accessedAgo:  InventoryCacheTTL + time.Second,
+			generation:   1,
+			active:       true,
+		},
+		{
+			description: "HardwareManager spec changed",
+			generation:  2,
+			active:      true,
+		},
+		{
+			description:  "inventory idle past the idle timeout",
+			refreshedAgo: InventoryCacheIdleTimeout + time.Second,
+			accessedAgo:  InventoryCacheIdleTimeout + time.Second,
+			generation:   1,
+		},
+		{
+			description: "inventory removed",
+			generation:  1,
+			invalidate:  func(cache *InventoryCache) { cache.remove("dell-1") },
+		},
+		{
+			description: "other inventory removed",
+			generation:  1,
+			invalidate:  func(cache *InventoryCache) { cache.remove("dell-2") },
+			current:     true,
+			active:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cache := NewInventoryCache()
+			setInventoryCacheTestEntry(cache, newInventoryCacheTestHwMgr("dell-1", 1), test.refreshedAgo, test.accessedAgo)
+			setInventoryCacheTestEntry(cache, newInventoryCacheTestHwMgr("dell-2", 1), 0, 0)
+			if test.invalidate != nil {
+				test.invalidate(cache)
+			}
+
+			// Checked before get, which records an access
+			if active := slices.Contains(cache.active(), "dell-1"); active != test.active {
+				t.Errorf("expected the inventory to be active: %t", test.active)
+			}
+
+			hwmgr := newInventoryCacheTestHwMgr("dell-1", test.generation)
+			if _, current := cache.getServers(hwmgr); current != test.current {
+				t.Errorf("expected the servers to be current: %t", test.current)
+			}
+			entry, current := cache.get(hwmgr)
+			if current != test.current {
+				t.Fatalf("expected the inventory to be current: %t", test.current)
+			}
+			if current && (len(entry.pools) != 1 || len(entry.resources) != 1) {
+				t.Errorf("expected the cached inventory, got %d pools and %d resources", len(entry.pools), len(entry.resources))
+			}
+		})
+	}
+}
+
+func TestInventoryCacheAccess(t *testing.T) {
+	hwmgr := newInventoryCacheTestHwMgr("dell-1", 1)
+	idle := InventoryCacheIdleTimeout + time.Second
+
+	t.Run("servers lookup does not keep the inventory active", func(t *testing.T) {
+		cache := NewInventoryCache()
+		setInventoryCacheTestEntry(cache, hwmgr, 0, idle)
+		cache.getServers(hwmgr)
+		if slices.Contains(cache.active(), hwmgr.Name) {
+			t.Errorf("expected the inventory to be dropped")
+		}
+	})
+
+	t.Run("stale inventory request keeps the inventory active", func(t *testing.T) {
+		cache := NewInventoryCache()
+		setInventoryCacheTestEntry(cache, hwmgr, idle, idle)
+		if _, current := cache.get(hwmgr); current {
+			t.Errorf("expected the inventory not to be current")
+		}
+		if !slices.Contains(cache.active(), hwmgr.Name) {
+			t.Errorf("expected the request to keep the inventory active")
+		}
+	})
+
+	t.Run("refresh keeps the last access", func(t *testing.T) {
+		cache := NewInventoryCache()
+		setInventoryCacheTestEntry(cache, hwmgr, 0, idle)
+		cache.set(hwmgr, nil, nil, nil)
+		if slices.Contains(cache.active(), hwmgr.Name) {
+			t.Errorf("expected a background refresh not to keep the inventory active")
+		}
+	})
+}
+
+func TestRefreshInventoryCacheDropsDeletedHardwareManager(t *testing.T) {
+	a := newTestAdaptor(t)
+	a.inventoryCache = NewInventoryCache()
+	hwmgr := newInventoryCacheTestHwMgr("dell-1", 1)
+	setInventoryCacheTestEntry(a.inventoryCache, hwmgr, 0, 0)
+
+	a.refreshInventoryCache(context.Background())
+
+	if _, current := a.inventoryCache.get(hwmgr); current {
+		t.Errorf("expected the inventory of the deleted HardwareManager to be dropped")
+	}
+	if len(a.inventoryCache.active()) != 0 {
+		t.Errorf("expected no active inventory")
+	}
+}
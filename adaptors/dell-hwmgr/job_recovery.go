@@ -0,0 +1,173 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jobsRecovered reports whether the startup job recovery pass has completed
+func (a *Adaptor) jobsRecovered() bool {
+	select {
+	case <-a.jobRecoveryDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// recoverInFlightJobs runs once when the plugin starts, revalidating the jobs recorded on the NodePools and Nodes of
+// each Dell HardwareManager with the hardware manager, and repairing status left inconsistent by a restart while a job
+// was in flight. NodePools are not handled until it completes. Failures are logged rather than returned, as the normal
+// reconcile of each NodePool still checks its jobs.
+func (a *Adaptor) recoverInFlightJobs(ctx context.Context) error {
+	defer close(a.jobRecoveryDone)
+
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	a.Logger.InfoContext(ctx, "Recovering in-flight jobs")
+
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := a.Client.List(ctx, hwmgrs, client.InNamespace(a.Namespace)); err != nil {
+		a.Logger.ErrorContext(ctx, "Unable to list HardwareManagers for job recovery", slog.String("error", err.Error()))
+		return nil
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := a.Client.List(ctx, nodepools); err != nil {
+		a.Logger.ErrorContext(ctx, "Unable to list NodePools for job recovery", slog.String("error", err.Error()))
+		return nil
+	}
+
+	for i := range hwmgrs.Items {
+		hwmgr := &hwmgrs.Items[i]
+		if hwmgr.Spec.AdaptorID != pluginv1alpha1.SupportedAdaptors.Dell {
+			continue
+		}
+
+		hwmgrCtx := logging.WithHwMgr(ctx, hwmgr.Name)
+		hwmgrClient, err := hwmgrclient.NewClientWithResponses(hwmgrCtx, a.Logger, a.Client, hwmgr)
+		if err != nil {
+			a.Logger.WarnContext(hwmgrCtx, "Unable to setup hwmgr client for job recovery", slog.String("error", err.Error()))
+			continue
+		}
+
+		for j := range nodepools.Items {
+			nodepool := &nodepools.Items[j]
+			if nodepool.Spec.HwMgrId != hwmgr.Name || nodepool.GetDeletionTimestamp() != nil {
+				continue
+			}
+
+			nodepoolCtx := logging.AppendCtx(hwmgrCtx, slog.String("nodepool", nodepool.Name))
+			tenantClient := hwmgrClient.WithTenant(nodepool.GetAnnotations()[NodePoolTenantAnnotation])
+			if err := a.recoverNodePoolJobs(nodepoolCtx, tenantClient, nodepool); err != nil {
+				a.Logger.WarnContext(nodepoolCtx, "Unable to recover NodePool jobs", slog.String("error", err.Error()))
+			}
+		}
+	}
+
+	a.Logger.InfoContext(ctx, "In-flight job recovery complete")
+	return nil
+}
+
+// recoverNodePoolJobs revalidates the jobs of a NodePool and its nodes
+func (a *Adaptor) recoverNodePoolJobs(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	if jobId := utils.GetJobId(nodepool); jobId != "" {
+		if err := a.recoverNodePoolJob(logging.AppendCtx(ctx, slog.String("jobId", jobId)), hwmgrClient, nodepool, jobId); err != nil {
+			return err
+		}
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	// Node profile update jobs are resolved by the configuring state of the NodePool, so they are only revalidated
+	for _, node := range nodelist.Items {
+		jobId := utils.GetJobId(&node)
+		if jobId == "" {
+			continue
+		}
+		status, _, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+		if err != nil {
+			return fmt.Errorf("failed to check job %s of node %s: %w", jobId, node.Name, err)
+		}
+		a.Logger.InfoContext(ctx, "Recovered node job",
+			slog.String("node", node.Name), slog.String("jobId", jobId), slog.Any("status", status))
+	}
+
+	return nil
+}
+
+// recoverNodePoolJob repairs the status of a NodePool with a recorded job, where the plugin stopped between recording
+// the job and updating the Provisioned condition
+func (a *Adaptor) recoverNodePoolJob(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	jobId string) error {
+
+	status, _, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	if err != nil {
+		return fmt.Errorf("failed to check job %s: %w", jobId, err)
+	}
+	a.Logger.InfoContext(ctx, "Recovered NodePool job", slog.Any("status", status))
+
+	provisioned := utils.GetNodePoolProvisionedCondition(nodepool)
+	switch {
+	case provisioned == nil:
+		// The resource group creation was requested, but the NodePool was not moved to processing, so it would
+		// otherwise be handled as a new NodePool and fail as the resource group already exists
+		a.Logger.InfoContext(ctx, "Resuming NodePool creation job")
+		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.InProgress, metav1.ConditionFalse, "Handling creation"); err != nil {
+			return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		if err := utils.UpdateNodePoolPluginStatus(ctx, a.Client, nodepool); err != nil {
+			return fmt.Errorf("failed to update hwMgrPlugin observedGeneration for NodePool %s: %w", nodepool.Name, err)
+		}
+	case provisioned.Status == metav1.ConditionTrue:
+		// Either the job completed but was not cleared, or a scale-out was requested but the NodePool was not moved
+		// to processing. A scale-out is requested again as the spec change is still pending, and updating the
+		// resource group size is idempotent.
+		a.Logger.InfoContext(ctx, "Clearing job from provisioned NodePool")
+		utils.ClearJobId(nodepool)
+		if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, nodepool, nil, utils.PATCH); err != nil {
+			return fmt.Errorf("failed to clear annotation from nodepool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// isNodeProfileApplied checks whether the hardware manager reports the profile of a node as applied to its resource
+func (a *Adaptor) isNodeProfileApplied(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	node *hwmgmtv1alpha1.Node) (bool, error) {
+
+	resource, err := hwmgrClient.GetResource(ctx, node)
+	if err != nil {
+		return false, fmt.Errorf("failed to get resource for node %s: %w", node.Name, err)
+	}
+	return resource.Resource != nil && resource.Resource.ResourceProfileID != nil &&
+		*resource.Resource.ResourceProfileID == node.Spec.HwProfile, nil
+}
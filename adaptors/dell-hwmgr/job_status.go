@@ -0,0 +1,56 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// jobPhase maps the status of a job on the hardware manager to the phase published in the AllocationRecord. A job
+// purged by the hardware manager is Missing, even where the adaptor carries on as if it had completed.
+func jobPhase(status hwmgrclient.JobStatus) (pluginv1alpha1.JobPhase, bool) {
+	switch status {
+	case hwmgrclient.JobStatusInProgress:
+		return pluginv1alpha1.JobPhases.InProgress, true
+	case hwmgrclient.JobStatusCompleted:
+		return pluginv1alpha1.JobPhases.Completed, true
+	case hwmgrclient.JobStatusFailed:
+		return pluginv1alpha1.JobPhases.Failed, true
+	case hwmgrclient.JobStatusNotExist:
+		return pluginv1alpha1.JobPhases.Missing, true
+	}
+	return "", false
+}
+
+// recordJobStatus publishes the status of a job of the NodePool in its AllocationRecord. The record is informational,
+// so a failure to update it is logged rather than holding up the NodePool.
+func (a *Adaptor) recordJobStatus(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	jobId string, operation pluginv1alpha1.AllocationOperation, nodename string,
+	status hwmgrclient.JobStatus, failure jobFailure) {
+
+	phase, known := jobPhase(status)
+	if !known {
+		return
+	}
+
+	if err := utils.UpdateAllocationRecordJob(ctx, a.Client, nodepool, utils.JobUpdate{
+		JobId:      jobId,
+		Operation:  operation,
+		NodeName:   nodename,
+		Phase:      phase,
+		FailReason: failure.Reason,
+		FailCode:   failure.Code,
+	}); err != nil {
+		a.Logger.WarnContext(ctx, "Failed to record job status", slog.String("jobId", jobId), slog.String("error", err.Error()))
+	}
+}
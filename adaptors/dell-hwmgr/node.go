@@ -14,10 +14,10 @@ import (
 
 	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -56,33 +56,35 @@ type BMCCredentials struct {
 	Password string `json:"bmc_password"`
 }
 
-func bmcSecretName(nodename string) string {
-	return fmt.Sprintf("%s-bmc-secret", nodename)
-}
-
 // AllocateNode processes a NodePool CR, allocating a free node for each specified nodegroup as needed
 func (a *Adaptor) AllocateNode(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool,
 	resource hwmgrapi.RhprotoResource,
 	nodegroupName string) (string, error) {
 	nodename := utils.GenerateNodeName()
 	ctx = logging.AppendCtx(ctx, slog.String("nodename", nodename))
 
-	if err := a.ValidateNodeConfig(ctx, resource); err != nil {
+	if err := a.ValidateNodeConfig(ctx, hwmgr, resource); err != nil {
 		return "", fmt.Errorf("failed to validate resource configuration: %w", err)
 	}
 
-	if err := a.CreateBMCSecret(ctx, hwmgrClient, nodepool, nodename, resource); err != nil {
+	bmcSecret, err := utils.GetBmcSecretLocation(nodepool, nodename)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bmc-secret name for node %s: %w", nodename, err)
+	}
+
+	if err := a.CreateBMCSecret(ctx, hwmgrClient, hwmgr, nodepool, nodename, bmcSecret, resource); err != nil {
 		return "", fmt.Errorf("failed to create bmc-secret when allocating node %s: %w", nodename, err)
 	}
 
-	if err := a.CreateNode(ctx, nodepool, nodename, resource, nodegroupName); err != nil {
+	if err := a.CreateNode(ctx, nodepool, nodename, bmcSecret, resource, nodegroupName); err != nil {
 		return "", fmt.Errorf("failed to create allocated node (%s): %w", *resource.Id, err)
 	}
 
-	if err := a.SetInitialNodeStatus(ctx, nodename, resource); err != nil {
+	if err := a.SetInitialNodeStatus(ctx, nodename, bmcSecret.Name, resource); err != nil {
 		return nodename, fmt.Errorf("failed to update node status (%s): %w", *resource.Id, err)
 	}
 
@@ -91,11 +93,16 @@ func (a *Adaptor) AllocateNode(
 
 // parseExtensionInterfaces parses interface data from the Extensions object in the resource
 func (a *Adaptor) parseExtensionInterfaces(resource hwmgrapi.RhprotoResource) ([]ExtensionInterface, error) {
-	if resource.Extensions == nil {
+	return parseExtensionsNics(resource.Extensions)
+}
+
+// parseExtensionsNics parses interface data from the O2-nics field of a resource Extensions object
+func parseExtensionsNics(extensions *map[string]map[string]interface{}) ([]ExtensionInterface, error) {
+	if extensions == nil {
 		return nil, fmt.Errorf("resource structure missing required extensions field")
 	}
 
-	nics, exists := (*resource.Extensions)[ExtensionsNics]
+	nics, exists := (*extensions)[ExtensionsNics]
 	if !exists {
 		return nil, fmt.Errorf("resource structure missing required extensions field: %s", ExtensionsNics)
 	}
@@ -118,7 +125,8 @@ func (a *Adaptor) parseExtensionInterfaces(resource hwmgrapi.RhprotoResource) ([
 	return interfaces, nil
 }
 
-// parseExtensionVirtualMediaUrl parses the Extensions object in the resource to get the virtualMediaUrl
+// parseExtensionVirtualMediaUrl parses the Extensions object in the resource to get the virtualMediaUrl, with an IPv6
+// literal host enclosed in brackets
 func (a *Adaptor) parseExtensionVirtualMediaUrl(resource hwmgrapi.RhprotoResource) (string, error) {
 	if resource.Extensions == nil {
 		return "", fmt.Errorf("resource structure missing required extensions field")
@@ -139,7 +147,12 @@ func (a *Adaptor) parseExtensionVirtualMediaUrl(resource hwmgrapi.RhprotoResourc
 		return "", fmt.Errorf("resource structure has invalid field, expected string: %s.%s", ExtensionsRemoteManagement, ExtensionsVirtualMediaUrl)
 	}
 
-	return virtualMediaUrl, nil
+	parsed, err := utils.ParseEndpointURL(virtualMediaUrl)
+	if err != nil {
+		return "", fmt.Errorf("resource structure has invalid field: %s.%s: %w", ExtensionsRemoteManagement, ExtensionsVirtualMediaUrl, err)
+	}
+
+	return parsed.String(), nil
 }
 
 // getNodeInterfaces translates the interface data from the resource object into the o2ims-defined data structure for the Node CR
@@ -175,16 +188,19 @@ func (a *Adaptor) getNodeInterfaces(resource hwmgrapi.RhprotoResource) ([]*hwmgm
 }
 
 // ValidateNodeConfig performs basic data structure validation on the resource
-func (a *Adaptor) ValidateNodeConfig(ctx context.Context, resource hwmgrapi.RhprotoResource) error {
+func (a *Adaptor) ValidateNodeConfig(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, resource hwmgrapi.RhprotoResource) error {
 	// Check required fields
 	if resource.ResourceAttribute == nil ||
 		resource.ResourceAttribute.Compute == nil ||
 		resource.ResourceAttribute.Compute.Lom == nil ||
-		resource.ResourceAttribute.Compute.Lom.IpAddress == nil ||
-		resource.ResourceAttribute.Compute.Lom.Password == nil {
+		resource.ResourceAttribute.Compute.Lom.IpAddress == nil {
 		return fmt.Errorf("resource structure missing required resource attribute field")
 	}
 
+	if _, err := getBMCSecretKey(hwmgr, resource); err != nil {
+		return fmt.Errorf("invalid BMC credentials: %w", err)
+	}
+
 	if _, err := a.parseExtensionInterfaces(resource); err != nil {
 		return fmt.Errorf("invalid interface list: %w", err)
 	}
@@ -196,48 +212,36 @@ func (a *Adaptor) ValidateNodeConfig(ctx context.Context, resource hwmgrapi.Rhpr
 	return nil
 }
 
-// CreateBMCSecret creates the bmc-secret for a node
+// CreateBMCSecret creates the bmc-secret for a node through the BMC secret backend of the HardwareManager. The
+// credentials are held by the hardware manager, as a secret referenced by the resource or named by the credential
+// vault of the HardwareManager. They are only fetched if the backend needs them to create the bmc-secret.
 func (a *Adaptor) CreateBMCSecret(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool,
 	nodename string,
+	location types.NamespacedName,
 	resource hwmgrapi.RhprotoResource) error {
-	a.Logger.InfoContext(ctx, "Creating bmc-secret")
+	a.Logger.InfoContext(ctx, "Creating bmc-secret", slog.String("secret", location.String()))
 
-	remoteSecretKey := *resource.ResourceAttribute.Compute.Lom.Password
-	remoteSecret, err := hwmgrClient.GetSecret(ctx, remoteSecretKey)
+	backend, err := utils.NewBmcSecretBackend(a.Client, hwmgr)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve BMC credentials (%s): %w", remoteSecretKey, err)
+		return fmt.Errorf("invalid BMC secret backend: %w", err)
 	}
 
-	creds := BMCCredentials{}
-	if err := json.Unmarshal([]byte(*remoteSecret.Secret.Value), &creds); err != nil {
-		return fmt.Errorf("unable to parse BMC credentials (%s)", remoteSecretKey)
+	remoteSecretKey, err := getBMCSecretKey(hwmgr, resource)
+	if err != nil {
+		return err
 	}
-
-	secretName := bmcSecretName(nodename)
-
-	blockDeletion := true
-	bmcSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: a.Namespace,
-			OwnerReferences: []metav1.OwnerReference{{
-				APIVersion:         nodepool.APIVersion,
-				Kind:               nodepool.Kind,
-				Name:               nodepool.Name,
-				UID:                nodepool.UID,
-				BlockOwnerDeletion: &blockDeletion,
-			}},
-		},
-		Data: map[string][]byte{
-			"username": []byte(creds.Username),
-			"password": []byte(creds.Password),
+	source := utils.BmcCredentialsSource{
+		Reference: remoteSecretKey,
+		Fetch: func(ctx context.Context) ([]byte, []byte, error) {
+			return a.fetchBMCCredentials(ctx, hwmgrClient, hwmgr, remoteSecretKey)
 		},
 	}
 
-	if err = utils.CreateOrUpdateK8sCR(ctx, a.Client, bmcSecret, nil, utils.UPDATE); err != nil {
+	if err := backend.CreateBmcSecret(ctx, nodepool, nodename, location, source); err != nil {
 		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
 	}
 
@@ -245,7 +249,13 @@ func (a *Adaptor) CreateBMCSecret(
 }
 
 // CreateNode creates a Node CR with specified attributes
-func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, nodename string, resource hwmgrapi.RhprotoResource, nodegroupName string) error {
+func (a *Adaptor) CreateNode(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodename string,
+	bmcSecret types.NamespacedName,
+	resource hwmgrapi.RhprotoResource,
+	nodegroupName string) error {
 	// TODO: remove this casuistic when the hwprofile returned by the Dell hwmgr is not empty (not supported yet)
 	//
 	var hwprofile string
@@ -272,8 +282,9 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 	blockDeletion := true
 	node := &hwmgmtv1alpha1.Node{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      nodename,
-			Namespace: a.Namespace,
+			Name:        nodename,
+			Namespace:   a.Namespace,
+			Annotations: utils.GetBmcSecretNodeAnnotations(bmcSecret, a.Namespace),
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         nodepool.APIVersion,
 				Kind:               nodepool.Kind,
@@ -290,6 +301,12 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 			HwMgrNodeId: *resource.Id,
 		},
 	}
+	utils.AddNodeEvent(node, utils.NodeEventAllocated,
+		fmt.Sprintf("Resource %s allocated to NodePool %s", *resource.Id, nodepool.Name))
+
+	if err := utils.ApplyHardwareManagerNodeMetadata(ctx, a.Client, a.Namespace, node); err != nil {
+		return err // nolint: wrapcheck
+	}
 
 	if err := a.Client.Create(ctx, node); err != nil {
 		return fmt.Errorf("failed to create Node: %w", err)
@@ -299,7 +316,7 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 }
 
 // SetInitialNodeStatus updates a Node CR status field with additional node information from the RhprotoResource
-func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename string, resource hwmgrapi.RhprotoResource) error {
+func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename, bmcSecretName string, resource hwmgrapi.RhprotoResource) error {
 	a.Logger.InfoContext(ctx, "Updating node")
 
 	node := &hwmgmtv1alpha1.Node{}
@@ -317,7 +334,7 @@ func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename string, res
 
 	node.Status.BMC = &hwmgmtv1alpha1.BMC{
 		Address:         virtualMediaUrl,
-		CredentialsName: bmcSecretName(nodename),
+		CredentialsName: bmcSecretName,
 	}
 
 	var parseErr error
@@ -325,7 +342,7 @@ func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename string, res
 		return fmt.Errorf("invalid interface list: %w", parseErr)
 	}
 
-	utils.SetStatusCondition(&node.Status.Conditions,
+	utils.SetStatusCondition(ctx, &node.Status.Conditions,
 		string(hwmgmtv1alpha1.Provisioned),
 		string(hwmgmtv1alpha1.Completed),
 		metav1.ConditionTrue,
@@ -337,5 +354,10 @@ func (a *Adaptor) SetInitialNodeStatus(ctx context.Context, nodename string, res
 		return fmt.Errorf("failed to update status for node %s: %w", nodename, err)
 	}
 
+	if err := utils.RecordNodeEvent(ctx, a.Client, nodename, a.Namespace, utils.NodeEventProvisioned,
+		"Hardware profile "+node.Spec.HwProfile); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", nodename), slog.String("error", err.Error()))
+	}
+
 	return nil
 }
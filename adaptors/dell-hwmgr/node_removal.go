@@ -0,0 +1,208 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	hwmgrapi "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkNodeRemoval removes the nodes of a provisioned NodePool named in its remove-nodes annotation or whose Node CR
+// has been deleted, releasing their resources from the resource group. The nodes are only dropped from the NodePool
+// once the job releasing their resources has completed. It returns whether any removal was handled, in which case the
+// result and error are returned to the reconciler.
+func (a *Adaptor) checkNodeRemoval(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, bool, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), true, fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	removals, err := utils.GetNodesToRemove(ctx, a.NoncachedClient, nodepool, nodelist)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), true, err
+	}
+	jobId := nodepool.GetAnnotations()[utils.NodeRemovalJobIdAnnotation]
+	if len(removals) == 0 {
+		if jobId != "" {
+			if err := a.clearNodeRemovalJob(ctx, nodepool); err != nil {
+				return utils.RequeueWithShortInterval(), true, err
+			}
+		}
+		if err := utils.ClearNodeRemovalRequest(ctx, a.Client, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), true, err
+		}
+		return ctrl.Result{}, false, nil
+	}
+
+	if jobId != "" {
+		ctx = logging.AppendCtx(ctx, slog.String("jobId", jobId))
+		if result, done, err := a.checkNodeRemovalJob(ctx, hwmgrClient, hwmgr, nodepool, jobId); !done || err != nil {
+			return result, true, err
+		}
+	}
+
+	rg, err := hwmgrClient.GetResourceGroupFromNodePool(ctx, nodepool)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), true, fmt.Errorf("failed to get resource group for NodePool %s: %w", nodepool.Name, err)
+	}
+	inResourceGroup := resourceGroupResourceIds(rg)
+
+	// A node whose resource is no longer in the resource group, or cannot be identified, has nothing left to release
+	var resourceIds []string
+	for _, removal := range removals {
+		resourceId, err := a.getRemovedNodeResourceId(ctx, nodepool, removal)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), true, err
+		}
+		if resourceId != "" && inResourceGroup[resourceId] {
+			resourceIds = append(resourceIds, resourceId)
+			continue
+		}
+
+		if err := utils.CompleteNodeRemoval(ctx, a.Client, nodepool, removal); err != nil {
+			return utils.RequeueWithShortInterval(), true, err
+		}
+		a.Logger.InfoContext(ctx, "Node removed from NodePool", slog.String("node", removal.NodeName))
+	}
+
+	if len(resourceIds) == 0 {
+		if err := utils.ClearNodeRemovalRequest(ctx, a.Client, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), true, err
+		}
+
+		// The resource group no longer matches the last validation, so it is validated again on the next check
+		a.resourceGroupChecks.Delete(client.ObjectKeyFromObject(nodepool).String())
+		return utils.RequeueWithShortInterval(), true, nil
+	}
+
+	a.Logger.InfoContext(ctx, "Releasing resources of removed nodes", slog.Any("resourceIds", resourceIds))
+	jobId, err = hwmgrClient.RemoveResourcesFromResourceGroup(ctx, nodepool, *rg, resourceIds)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), true,
+			fmt.Errorf("failed to remove resources from resource group for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, nodepool.DeepCopy(),
+		[]utils.MetaMutation{utils.AddAnnotation(utils.NodeRemovalJobIdAnnotation, jobId)}); err != nil {
+		return utils.RequeueWithShortInterval(), true, fmt.Errorf("failed to annotate nodepool %s: %w", nodepool.Name, err)
+	}
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Release, "", hwmgrclient.JobStatusInProgress, jobFailure{})
+
+	return utils.RequeueWithShortInterval(), true, nil
+}
+
+// checkNodeRemovalJob checks the job releasing the resources of removed nodes, returning whether it has finished such
+// that the nodes whose resources were released can be dropped from the NodePool. A failed job is cleared, so that the
+// release of the resources still in the resource group is requested again.
+func (a *Adaptor) checkNodeRemovalJob(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	jobId string) (ctrl.Result, bool, error) {
+
+	status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), false, fmt.Errorf("failed to check node removal job progress, jobId=%s: %w", jobId, err)
+	}
+	failure := a.classifyFailReason(ctx, hwmgr, failReason)
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Release, "", status, failure)
+
+	switch status {
+	case hwmgrclient.JobStatusInProgress:
+		a.Logger.InfoContext(ctx, "Node removal job is in progress")
+		return utils.RequeueWithShortInterval(), false, nil
+	case hwmgrclient.JobStatusCompleted:
+		a.Logger.InfoContext(ctx, "Node removal job has completed")
+	case hwmgrclient.JobStatusNotExist:
+		// The resource group is checked for the resources that were not released
+		a.Logger.InfoContext(ctx, "Node removal job no longer exists on hardware manager")
+	case hwmgrclient.JobStatusFailed:
+		a.Logger.ErrorContext(ctx, "Node removal job failed",
+			slog.String("failReason", failReason), slog.String("failCode", failure.Code))
+		if err := a.clearNodeRemovalJob(ctx, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), false, err
+		}
+		return utils.RequeueWithMediumInterval(), false, fmt.Errorf("node removal job %s failed: %s", jobId, failure)
+	default:
+		a.Logger.InfoContext(ctx, "Node removal job check returned unknown status", slog.Any("status", status),
+			slog.String("failReason", failReason))
+		return utils.RequeueWithShortInterval(), false, nil
+	}
+
+	if err := a.clearNodeRemovalJob(ctx, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), false, err
+	}
+	return ctrl.Result{}, true, nil
+}
+
+// clearNodeRemovalJob removes the node removal job annotation from a NodePool
+func (a *Adaptor) clearNodeRemovalJob(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, nodepool.DeepCopy(),
+		[]utils.MetaMutation{utils.RemoveAnnotation(utils.NodeRemovalJobIdAnnotation)}); err != nil {
+		return fmt.Errorf("failed to remove %s annotation from NodePool %s: %w",
+			utils.NodeRemovalJobIdAnnotation, nodepool.Name, err)
+	}
+	return nil
+}
+
+// getRemovedNodeResourceId returns the resource backing a node being removed. The resource of a Node CR that has been
+// deleted is found in the AllocationRecord of the NodePool, and is empty if it is not recorded there.
+func (a *Adaptor) getRemovedNodeResourceId(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	removal utils.NodeRemoval) (string, error) {
+
+	if removal.Node != nil {
+		return removal.Node.Spec.HwMgrNodeId, nil
+	}
+
+	record, err := utils.GetAllocatedNodeRecord(ctx, a.Client, nodepool, removal.NodeName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get resource of removed node %s: %w", removal.NodeName, err)
+	}
+	if record == nil || record.HwMgrNodeId == "" {
+		a.Logger.WarnContext(ctx, "Resource of deleted node is not recorded, leaving it in the resource group",
+			slog.String("node", removal.NodeName))
+		return "", nil
+	}
+	return record.HwMgrNodeId, nil
+}
+
+// resourceGroupResourceIds returns the IDs of the resources in a resource group
+func resourceGroupResourceIds(rg *hwmgrapi.RhprotoResourceGroupObjectGetResponseBody) map[string]bool {
+	ids := make(map[string]bool)
+	if rg.ResourceSelectors == nil {
+		return ids
+	}
+	for _, resourceSelector := range *rg.ResourceSelectors {
+		if resourceSelector.Resources == nil {
+			continue
+		}
+		for _, resource := range *resourceSelector.Resources {
+			if resource.Id != nil {
+				ids[*resource.Id] = true
+			}
+		}
+	}
+	return ids
+}
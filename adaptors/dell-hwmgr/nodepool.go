@@ -8,10 +8,10 @@ package dellhwmgr
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"slices"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -28,15 +28,16 @@ import (
 // ValidateNodePool performs basic validation of the nodepool data
 func (a *Adaptor) ValidateNodePool(nodepool *hwmgmtv1alpha1.NodePool) error {
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
-		if nodegroup.NodePoolData.ResourceSelector != "" {
-			// Validate that the resourceSelector is parsable
-			selectors := make(map[string]string)
-			if err := json.Unmarshal([]byte(nodegroup.NodePoolData.ResourceSelector), &selectors); err != nil {
-				return fmt.Errorf("unable to parse resourceSelector: %s", nodegroup.NodePoolData.ResourceSelector)
-			}
+		// Validate that the resourceSelector is parsable, with well-formed exclusions
+		if _, _, err := hwmgrclient.ParseResourceSelector(nodegroup.NodePoolData.ResourceSelector); err != nil {
+			return err // nolint: wrapcheck
 		}
 	}
 
+	if _, err := hwmgrclient.GetPinnedResources(nodepool); err != nil {
+		return err // nolint: wrapcheck
+	}
+
 	return nil
 }
 
@@ -47,6 +48,13 @@ func (a *Adaptor) HandleNodePoolCreate(
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
+	if utils.IsNodePoolDryRun(nodepool) {
+		return a.HandleNodePoolDryRun(ctx, hwmgrClient, nodepool)
+	}
+	if utils.IsNodePoolReservation(nodepool) {
+		return a.HandleNodePoolReservation(ctx, hwmgrClient, hwmgr, nodepool)
+	}
+
 	conditionType := hwmgmtv1alpha1.Provisioned
 	var conditionReason hwmgmtv1alpha1.ConditionReason
 	var conditionStatus metav1.ConditionStatus
@@ -78,7 +86,13 @@ func (a *Adaptor) HandleNodePoolCreate(
 		return utils.DoNotRequeue(), nil
 	}
 
-	if err := a.ProcessNewNodePool(ctx, hwmgrClient, hwmgr, nodepool); err != nil {
+	if utils.GetJobId(nodepool) != "" {
+		// The resource group was requested while the NodePool was reserved, so its Nodes are created from it
+		a.Logger.InfoContext(ctx, "Activating reserved NodePool")
+		conditionReason = hwmgmtv1alpha1.InProgress
+		conditionStatus = metav1.ConditionFalse
+		message = "Handling creation"
+	} else if err := a.ProcessNewNodePool(ctx, hwmgrClient, hwmgr, nodepool); err != nil {
 		a.Logger.InfoContext(ctx, "failed ProcessNewNodePool", slog.String("err", err.Error()))
 		conditionReason = hwmgmtv1alpha1.Failed
 		conditionStatus = metav1.ConditionFalse
@@ -104,6 +118,49 @@ func (a *Adaptor) HandleNodePoolCreate(
 	return utils.DoNotRequeue(), nil
 }
 
+// HandleNodePoolDryRun runs the validation and pool selection done for a new NodePool and reports the outcome,
+// without creating a resource group on the hardware manager
+func (a *Adaptor) HandleNodePoolDryRun(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Handling dry run of NodePool request")
+
+	// Select pools on a copy, so the selection is not recorded in the NodePool status
+	candidate := nodepool.DeepCopy()
+
+	checkErr := a.ValidateNodePool(candidate)
+	if checkErr == nil {
+		checkErr = utils.ValidateNodePoolHwProfiles(ctx, a.Client, a.Namespace, candidate)
+	}
+	if checkErr == nil {
+		checkErr = a.FindResourcePoolIds(ctx, hwmgrClient, candidate)
+		if typederrors.IsRetriableError(checkErr) {
+			return utils.RequeueWithMediumInterval(), fmt.Errorf("failed FindResourcePoolIds with retriable error: %w", checkErr)
+		}
+	}
+
+	selected := []string{}
+	for _, nodegroup := range candidate.Spec.NodeGroup {
+		if pool := candidate.Status.SelectedPools[nodegroup.NodePoolData.Name]; pool != "" {
+			selected = append(selected, fmt.Sprintf("%s=%s", nodegroup.NodePoolData.Name, pool))
+		}
+	}
+
+	detail := ""
+	if len(selected) > 0 {
+		detail = "selected pools " + strings.Join(selected, ", ")
+	}
+
+	if err := utils.UpdateNodePoolDryRunCondition(ctx, a.Client, nodepool, checkErr, detail); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
 // ProcessNewNodePool sends a request to the hardware manager to create a resource group
 func (a *Adaptor) ProcessNewNodePool(ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
@@ -123,6 +180,7 @@ func (a *Adaptor) ProcessNewNodePool(ctx context.Context,
 	if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, nodepool, nil, utils.PATCH); err != nil {
 		return fmt.Errorf("failed to annotate nodepool %s: %w", nodepool.Name, err)
 	}
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Allocation, "", hwmgrclient.JobStatusInProgress, jobFailure{})
 
 	return nil
 }
@@ -151,16 +209,19 @@ func (a *Adaptor) HandleNodePoolProcessing(
 		a.Logger.InfoContext(ctx, "Resource group check failed", slog.String("error", err.Error()))
 		return result, fmt.Errorf("failed to check job progress, jobId=%s: %w", jobId, err)
 	}
+	failure := a.classifyFailReason(ctx, hwmgr, failReason)
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Allocation, "", status, failure)
 
 	// Process the status response
 	switch status {
 	case hwmgrclient.JobStatusInProgress:
 		return utils.RequeueWithShortInterval(), nil
 	case hwmgrclient.JobStatusFailed:
-		a.Logger.InfoContext(ctx, "Resource group creation failed", slog.String("failReason", failReason))
+		a.Logger.InfoContext(ctx, "Resource group creation failed",
+			slog.String("failReason", failReason), slog.String("failCode", failure.Code))
 		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
-			fmt.Sprintf("Resource group creation failed: %s", failReason)); err != nil {
+			fmt.Sprintf("Resource group creation failed: %s", failure)); err != nil {
 			return utils.RequeueWithMediumInterval(),
 				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 		}
@@ -168,8 +229,21 @@ func (a *Adaptor) HandleNodePoolProcessing(
 	case hwmgrclient.JobStatusCompleted:
 		a.Logger.InfoContext(ctx, "Job has completed")
 	case hwmgrclient.JobStatusNotExist:
+		// The hardware manager may have purged the job, such as when it finished while the plugin was down, so
+		// carry on from the resource group if it exists
 		a.Logger.InfoContext(ctx, "Job check returned Not Exist")
-		return result, fmt.Errorf("job does not exist on hardware manager, jobId=%s", jobId)
+		if exists, err := hwmgrClient.ResourceGroupExists(ctx, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("resource group existence check failed for NodePool %s: %w", nodepool.Name, err)
+		} else if !exists {
+			if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+				hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
+				fmt.Sprintf("Job %s no longer exists on hardware manager, and no resource group was created", jobId)); err != nil {
+				return utils.RequeueWithMediumInterval(),
+					fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+			return result, fmt.Errorf("job does not exist on hardware manager, jobId=%s", jobId)
+		}
+		a.Logger.InfoContext(ctx, "Resource group exists, treating job as completed")
 	default:
 		a.Logger.InfoContext(ctx, "Resource group check returned unknown status", slog.String("failReason", failReason))
 		return result, fmt.Errorf("failed to check job progress, jobId=%s: %s", jobId, failReason)
@@ -235,7 +309,7 @@ func (a *Adaptor) HandleNodePoolProcessing(
 					return utils.DoNotRequeue(), nil
 				}
 			}
-			if nodename, err := a.AllocateNode(ctx, hwmgrClient, nodepool, node, nodegroupName); err != nil {
+			if nodename, err := a.AllocateNode(ctx, hwmgrClient, hwmgr, nodepool, node, nodegroupName); err != nil {
 				a.Logger.InfoContext(ctx, "Failed allocating node", slog.String("err", err.Error()))
 				if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 					hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Failed, metav1.ConditionFalse,
@@ -259,6 +333,10 @@ func (a *Adaptor) HandleNodePoolProcessing(
 
 	a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
 
+	if provisioned, result, err := utils.CheckPostAllocationHook(ctx, a.Client, a.NoncachedClient, a.Namespace, nodepool); !provisioned {
+		return result, err
+	}
+
 	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 		hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Completed, metav1.ConditionTrue, "Created"); err != nil {
 		return utils.RequeueWithMediumInterval(),
@@ -291,6 +369,8 @@ func (a *Adaptor) CheckDeletionJobStatus(ctx context.Context,
 		// return false, fmt.Errorf("deletion job progress check failed: %w", err)
 		return true, nil
 	}
+	failure := a.classifyFailReason(ctx, hwmgr, failReason)
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Release, "", status, failure)
 
 	// Process the status response
 	switch status {
@@ -298,8 +378,9 @@ func (a *Adaptor) CheckDeletionJobStatus(ctx context.Context,
 		a.Logger.InfoContext(ctx, "Deletion job is in progress")
 		return false, nil
 	case hwmgrclient.JobStatusFailed:
-		a.Logger.ErrorContext(ctx, "Deletion job failed", slog.String("failReason", failReason))
-		return false, fmt.Errorf("deletion job failed: failReason=%s", failReason)
+		a.Logger.ErrorContext(ctx, "Deletion job failed",
+			slog.String("failReason", failReason), slog.String("failCode", failure.Code))
+		return false, fmt.Errorf("deletion job failed: %s", failure)
 	case hwmgrclient.JobStatusCompleted:
 		a.Logger.InfoContext(ctx, "Deletion job has completed")
 		return true, nil
@@ -351,6 +432,7 @@ func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 	if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, refreshedNodepool, nil, utils.PATCH); err != nil {
 		return false, fmt.Errorf("failed to annotate nodepool %s: %w", refreshedNodepool.Name, err)
 	}
+	a.recordJobStatus(ctx, refreshedNodepool, jobId, pluginv1alpha1.AllocationOperations.Release, "", hwmgrclient.JobStatusInProgress, jobFailure{})
 
 	// Return completed=false so the reconciler requeues to check the job status
 	return false, nil
@@ -359,6 +441,7 @@ func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 func (a *Adaptor) handleNodePoolConfiguring(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
 	var result ctrl.Result
@@ -370,15 +453,25 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		return ctrl.Result{}, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
 	}
 
-	a.Logger.InfoContext(ctx, "Checking for node with profile update in-progress")
+	strategy, err := utils.GetUpdateStrategy(nodepool)
+	if err != nil {
+		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool, hwmgmtv1alpha1.Configured,
+			hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse, err.Error()); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return utils.DoNotRequeue(), fmt.Errorf("invalid update strategy for NodePool %s: %w", nodepool.Name, err)
+	}
 
-	// Search for a node that is currently being updated
-	if node := utils.FindNodeUpdateInProgress(nodelist); node != nil {
-		// A node has an update already in progress
+	a.Logger.InfoContext(ctx, "Checking for nodes with profile update in-progress")
 
+	// Check the nodes that are currently being updated
+	updating := 0
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
 		jobId := utils.GetJobId(node)
 		if jobId == "" {
-			return result, fmt.Errorf("jobId annotation is missing or empty from node %s", node.Name)
+			continue
 		}
 
 		// Query the hardware manager for the job status
@@ -387,18 +480,26 @@ func (a *Adaptor) handleNodePoolConfiguring(
 			a.Logger.InfoContext(ctx, "Profile update job progress check failed", slog.String("error", err.Error()))
 			return result, fmt.Errorf("failed to check profile update job progress, jobId=%s: %w", jobId, err)
 		}
+		failure := a.classifyFailReason(ctx, hwmgr, failReason)
+		a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.ProfileUpdate, node.Name, status, failure)
 
 		// Process the status response
 		switch status {
 		case hwmgrclient.JobStatusInProgress:
-			return utils.RequeueWithShortInterval(), nil
+			updating++
+			continue
 		case hwmgrclient.JobStatusFailed:
-			a.Logger.InfoContext(ctx, "Profile update creation failed", slog.String("failReason", failReason))
+			a.Logger.InfoContext(ctx, "Profile update creation failed",
+				slog.String("failReason", failReason), slog.String("failCode", failure.Code))
+			if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError,
+				fmt.Sprintf("Profile update job %s failed: %s", jobId, failure)); err != nil {
+				a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+			}
 			if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 				hwmgmtv1alpha1.Configured,
 				hwmgmtv1alpha1.Failed,
 				metav1.ConditionFalse,
-				fmt.Sprintf("Profile update creation failed: %s", failReason)); err != nil {
+				fmt.Sprintf("Profile update creation failed: %s", failure)); err != nil {
 				return utils.RequeueWithMediumInterval(),
 					fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 			}
@@ -407,8 +508,29 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		case hwmgrclient.JobStatusCompleted:
 			a.Logger.InfoContext(ctx, "Profile update job has completed")
 		case hwmgrclient.JobStatusNotExist:
+			// The hardware manager may have purged the job, such as when it finished while the plugin was down, so
+			// check whether the profile was applied
 			a.Logger.InfoContext(ctx, "Job check returned Not Exist")
-			return result, fmt.Errorf("job does not exist on hardware manager, jobId=%s", jobId)
+			applied, err := a.isNodeProfileApplied(ctx, hwmgrClient, node)
+			if err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+			if !applied {
+				if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError,
+					fmt.Sprintf("Profile update job %s no longer exists on hardware manager", jobId)); err != nil {
+					a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+				}
+				if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+					hwmgmtv1alpha1.Configured,
+					hwmgmtv1alpha1.Failed,
+					metav1.ConditionFalse,
+					fmt.Sprintf("Profile update job %s no longer exists on hardware manager", jobId)); err != nil {
+					return utils.RequeueWithMediumInterval(),
+						fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+				}
+				return result, fmt.Errorf("job does not exist on hardware manager, jobId=%s", jobId)
+			}
+			a.Logger.InfoContext(ctx, "Profile is applied to resource, treating job as completed")
 		default:
 			a.Logger.InfoContext(ctx, "Profile update check returned unknown status", slog.String("failReason", failReason))
 			return result, fmt.Errorf("failed to check profile update job progress, jobId=%s: %s", jobId, failReason)
@@ -417,11 +539,17 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		// Node update is complete
 		a.Logger.InfoContext(ctx, "Node update complete", slog.String("nodename", node.Name))
 		node.Status.HwProfile = node.Spec.HwProfile
+		utils.SetStatusCondition(ctx, &node.Status.Conditions,
+			string(hwmgmtv1alpha1.Configured),
+			string(hwmgmtv1alpha1.ConfigApplied),
+			metav1.ConditionTrue,
+			string(hwmgmtv1alpha1.ConfigSuccess))
 		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
 		}
 
 		utils.ClearJobId(node)
+		utils.AddNodeEvent(node, utils.NodeEventProfileUpdateCompleted, "Hardware profile "+node.Spec.HwProfile)
 		if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, node, nil, utils.PATCH); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to clear annotation from node %s: %w", node.Name, err)
 		}
@@ -431,13 +559,36 @@ func (a *Adaptor) handleNodePoolConfiguring(
 
 	a.Logger.InfoContext(ctx, "Checking for nodes to update")
 
-	// There are no nodes currently in-progress, so we can look for the next one to start updating
+	var pending []*hwmgmtv1alpha1.Node
+	newHwProfiles := make(map[string]string)
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
-		newHwProfile := nodegroup.NodePoolData.HwProfile
-		node := utils.FindNextNodeToUpdate(nodelist, nodegroup.NodePoolData.Name, newHwProfile)
-		if node == nil {
-			// No more nodes to update in this nodegroup
-			continue
+		for _, node := range utils.FindNodesToUpdate(nodelist, nodegroup.NodePoolData.Name, nodegroup.NodePoolData.HwProfile) {
+			if utils.GetJobId(node) != "" {
+				// Already updating
+				continue
+			}
+			pending = append(pending, node)
+			newHwProfiles[node.Name] = utils.GetNodeTargetHwProfile(node, nodegroup.NodePoolData.HwProfile)
+		}
+	}
+
+	// Start updating the next nodes, as allowed by the maintenance window and update strategy
+	var start []*hwmgmtv1alpha1.Node
+	held, wait, err := utils.HoldNodesForMaintenanceWindow(ctx, a.Client, hwmgr, pending)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+	if !held {
+		start, wait, err = utils.PlanNodeUpdates(ctx, a.Client, nodepool, strategy, pending, updating)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+	for _, node := range start {
+		newHwProfile := newHwProfiles[node.Name]
+
+		if err := utils.ClearNodeAwaitingWindow(ctx, a.Client, node); err != nil {
+			return utils.RequeueWithShortInterval(), err
 		}
 
 		a.Logger.InfoContext(ctx, "Issuing profile update to node",
@@ -464,17 +615,32 @@ func (a *Adaptor) handleNodePoolConfiguring(
 
 		// Record the jobId in an annotation
 		utils.SetJobId(node, jobId)
+		utils.AddNodeEvent(node, utils.NodeEventProfileUpdateStarted,
+			fmt.Sprintf("Hardware profile %s, job %s", newHwProfile, jobId))
 
 		if err = a.Client.Patch(ctx, node, patch); err != nil {
 			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
 		}
+		a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.ProfileUpdate, node.Name, hwmgrclient.JobStatusInProgress, jobFailure{})
+	}
 
+	switch {
+	case len(start) > 0 || updating > 0:
 		// Requeue to check update progress
 		return utils.RequeueWithMediumInterval(), nil
+	case wait > 0:
+		a.Logger.InfoContext(ctx, "Pausing before the next node updates", slog.Duration("wait", wait),
+			slog.Bool("awaitingWindow", held))
+		return utils.RequeueWithCustomInterval(wait), nil
+	case len(pending) > 0:
+		return utils.RequeueWithShortInterval(), nil
 	}
 
 	// All nodes have been updated
 	a.Logger.InfoContext(ctx, "All nodes have been updated to new profile")
+	if err := utils.ClearUpdateBatch(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
 	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 		hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue, string(hwmgmtv1alpha1.ConfigSuccess)); err != nil {
 		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
@@ -487,12 +653,78 @@ func (a *Adaptor) handleNodePoolConfiguring(
 	return result, nil
 }
 
+// nodeGroupsToScaleOut returns the names of the node groups with a size greater than the number of nodes allocated
+func (a *Adaptor) nodeGroupsToScaleOut(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) ([]string, error) {
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
+	}
+
+	allocated := make(map[string]int)
+	for _, node := range nodelist.Items {
+		allocated[node.Spec.GroupName]++
+	}
+
+	var groups []string
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if nodegroup.Size > allocated[nodegroup.NodePoolData.Name] {
+			groups = append(groups, nodegroup.NodePoolData.Name)
+		}
+	}
+	return groups, nil
+}
+
+// handleNodePoolScaleOut requests the hardware manager to grow the existing resource group to the new node group
+// sizes. The resulting job is tracked by HandleNodePoolProcessing, which allocates the newly added resources.
+func (a *Adaptor) handleNodePoolScaleOut(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	groups []string) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Scaling out resource group", slog.Any("nodegroups", groups))
+
+	jobId, err := hwmgrClient.UpdateResourceGroup(ctx, nodepool)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "Failed to update resource group", slog.String("error", err.Error()))
+		return utils.RequeueWithMediumInterval(), fmt.Errorf("failed to update resource group for nodepool %s: %w", nodepool.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Resource group update requested", slog.String("jobId", jobId))
+
+	utils.SetJobId(nodepool, jobId)
+	if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, nodepool, nil, utils.PATCH); err != nil {
+		return utils.RequeueWithMediumInterval(), fmt.Errorf("failed to annotate nodepool %s: %w", nodepool.Name, err)
+	}
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Allocation, "", hwmgrclient.JobStatusInProgress, jobFailure{})
+
+	// Moving the Provisioned condition back to InProgress hands the NodePool over to HandleNodePoolProcessing
+	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+		hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.InProgress, metav1.ConditionFalse,
+		fmt.Sprintf("Scaling out node groups: %s", strings.Join(groups, ", "))); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.RequeueWithShortInterval(), nil
+}
+
 func (a *Adaptor) HandleNodePoolSpecChanged(
 	ctx context.Context,
 	hwmgrClient *hwmgrclient.HardwareManagerClient,
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
+	// A node group size increase is handled by growing the existing resource group. Any hardware profile change is
+	// handled once the new nodes are allocated, as the generation is only observed after configuration completes.
+	groups, err := a.nodeGroupsToScaleOut(ctx, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	if len(groups) > 0 {
+		return a.handleNodePoolScaleOut(ctx, hwmgrClient, nodepool, groups)
+	}
+
 	if err := utils.UpdateNodePoolStatusCondition(
 		ctx,
 		a.Client,
@@ -505,5 +737,5 @@ func (a *Adaptor) HandleNodePoolSpecChanged(
 			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
 
-	return a.handleNodePoolConfiguring(ctx, hwmgrClient, nodepool)
+	return a.handleNodePoolConfiguring(ctx, hwmgrClient, hwmgr, nodepool)
 }
@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// powerActionStates maps the Node power actions to the hardware manager resource power states
+var powerActionStates = map[string]string{
+	utils.PowerActionOn:     hwmgrclient.PowerStateOn,
+	utils.PowerActionOff:    hwmgrclient.PowerStateOff,
+	utils.PowerActionReboot: hwmgrclient.PowerStateGracefulRestart,
+}
+
+// HandleNodePowerAction requests the hardware manager to change the power state of the resource backing a node. The
+// job ID is tracked in an annotation on the node, tagged with the action, and the action is complete once the job
+// completes.
+func (a *Adaptor) HandleNodePowerAction(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	action string) (bool, error) {
+
+	powerState, supported := powerActionStates[action]
+	if !supported {
+		return false, typederrors.NewInputError("unsupported power action %s", action)
+	}
+
+	hwmgrClient, err := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
+	if err != nil {
+		return false, fmt.Errorf("failed to create hwmgr client: %w", err)
+	}
+	if hwmgrClient, err = a.getNodeClient(ctx, hwmgrClient, node); err != nil {
+		return false, err
+	}
+
+	jobAction, jobId, _ := strings.Cut(node.Annotations[utils.PowerActionJobAnnotation], ";")
+	if jobId == "" || jobAction != action {
+		a.Logger.InfoContext(ctx, "Requesting power state update", slog.String("powerState", powerState))
+		jobId, err = hwmgrClient.UpdateResourcePowerState(ctx, node, powerState)
+		if err != nil {
+			return false, fmt.Errorf("failed to update power state for node %s: %w", node.Name, err)
+		}
+
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.PowerActionJobAnnotation, action+";"+jobId); err != nil {
+			return false, fmt.Errorf("failed to record power action job for node %s: %w", node.Name, err)
+		}
+		return false, nil
+	}
+
+	status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	if err != nil {
+		return false, fmt.Errorf("power action job progress check failed: %w", err)
+	}
+
+	switch status {
+	case hwmgrclient.JobStatusInProgress:
+		a.Logger.InfoContext(ctx, "Power action job is in progress", slog.String("jobId", jobId))
+		return false, nil
+	case hwmgrclient.JobStatusCompleted:
+		a.Logger.InfoContext(ctx, "Power action job has completed", slog.String("jobId", jobId))
+	case hwmgrclient.JobStatusFailed:
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.PowerActionJobAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear power action job for node %s: %w", node.Name, err)
+		}
+		return false, fmt.Errorf("power action job %s failed: %s", jobId, a.classifyFailReason(ctx, hwmgr, failReason))
+	case hwmgrclient.JobStatusNotExist:
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.PowerActionJobAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear power action job for node %s: %w", node.Name, err)
+		}
+		return false, fmt.Errorf("power action job %s no longer exists on hardware manager", jobId)
+	default:
+		a.Logger.InfoContext(ctx, "Power action job check returned unknown status",
+			slog.Any("status", status), slog.String("failReason", failReason))
+		return false, nil
+	}
+
+	if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.PowerActionJobAnnotation, ""); err != nil {
+		return false, fmt.Errorf("failed to clear power action job for node %s: %w", node.Name, err)
+	}
+
+	return true, nil
+}
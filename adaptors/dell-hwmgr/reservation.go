@@ -0,0 +1,100 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// HandleNodePoolReservation creates the resource group for a NodePool annotated for reservation, so the hardware
+// manager allocates its servers, without creating the Node CRs. The job ID annotation is kept once the resource group
+// is created, so that the NodePool carries on from it when activated, rather than requesting another one.
+func (a *Adaptor) HandleNodePoolReservation(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	if utils.IsNodePoolReserved(nodepool) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	a.Logger.InfoContext(ctx, "Handling reservation of NodePool hardware")
+
+	jobId := utils.GetJobId(nodepool)
+	if jobId == "" {
+		reserveErr := a.ValidateNodePool(nodepool)
+		if reserveErr == nil {
+			reserveErr = utils.ValidateNodePoolHwProfiles(ctx, a.Client, a.Namespace, nodepool)
+		}
+		if reserveErr == nil {
+			reserveErr = a.FindResourcePoolIds(ctx, hwmgrClient, nodepool)
+			if typederrors.IsRetriableError(reserveErr) {
+				return utils.RequeueWithMediumInterval(), fmt.Errorf("failed FindResourcePoolIds with retriable error: %w", reserveErr)
+			}
+		}
+		if reserveErr == nil {
+			reserveErr = a.ProcessNewNodePool(ctx, hwmgrClient, hwmgr, nodepool)
+		}
+
+		if err := utils.UpdateNodePoolReservedCondition(ctx, a.Client, nodepool, reserveErr, false, ""); err != nil {
+			return utils.RequeueWithMediumInterval(),
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		if reserveErr != nil {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("jobId", jobId))
+
+	status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to check job progress, jobId=%s: %w", jobId, err)
+	}
+	failure := a.classifyFailReason(ctx, hwmgr, failReason)
+	a.recordJobStatus(ctx, nodepool, jobId, pluginv1alpha1.AllocationOperations.Allocation, "", status, failure)
+
+	var reserveErr error
+	switch status {
+	case hwmgrclient.JobStatusInProgress:
+		return utils.RequeueWithShortInterval(), nil
+	case hwmgrclient.JobStatusCompleted:
+	case hwmgrclient.JobStatusFailed:
+		reserveErr = fmt.Errorf("resource group creation failed: %s", failure)
+	case hwmgrclient.JobStatusNotExist:
+		// As with an allocation, carry on from the resource group if the job was purged
+		exists, err := hwmgrClient.ResourceGroupExists(ctx, nodepool)
+		if err != nil {
+			return utils.RequeueWithShortInterval(),
+				fmt.Errorf("resource group existence check failed for NodePool %s: %w", nodepool.Name, err)
+		}
+		if !exists {
+			reserveErr = fmt.Errorf("job %s no longer exists on hardware manager, and no resource group was created", jobId)
+		}
+	default:
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to check job progress, jobId=%s: %s", jobId, failReason)
+	}
+
+	if err := utils.UpdateNodePoolReservedCondition(ctx, a.Client, nodepool, reserveErr, reserveErr == nil, ""); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
@@ -0,0 +1,238 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ResourceGroupGCCheckInterval is how often the HardwareManagers are checked for a due resource group GC
+	ResourceGroupGCCheckInterval = 5 * time.Minute
+
+	defaultResourceGroupGCInterval = time.Hour
+)
+
+// orphanedResourceGroup identifies a resource group created by the Plugin for a NodePool that no longer exists
+type orphanedResourceGroup struct {
+	tenant string
+	rgId   string
+}
+
+func (o orphanedResourceGroup) String() string {
+	return o.tenant + "/" + o.rgId
+}
+
+func getResourceGroupGCInterval(hwmgr *pluginv1alpha1.HardwareManager) time.Duration {
+	gc := hwmgr.Spec.DellData.ResourceGroupGC
+	if gc.Interval != nil && gc.Interval.Duration > 0 {
+		return gc.Interval.Duration
+	}
+	return defaultResourceGroupGCInterval
+}
+
+// runResourceGroupGC periodically checks the HardwareManagers with resource group GC enabled for orphaned resource
+// groups, until the manager stops
+func (a *Adaptor) runResourceGroupGC(ctx context.Context) error {
+	ticker := time.NewTicker(ResourceGroupGCCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.collectResourceGroups(logging.WithCorrelationID(ctx, logging.NewCorrelationID()))
+		}
+	}
+}
+
+// collectResourceGroups checks each Dell HardwareManager whose resource group GC is due
+func (a *Adaptor) collectResourceGroups(ctx context.Context) {
+	if !a.jobsRecovered() {
+		return
+	}
+
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := a.Client.List(ctx, hwmgrs, client.InNamespace(a.Namespace)); err != nil {
+		a.Logger.ErrorContext(ctx, "Unable to list HardwareManagers for resource group GC", slog.String("error", err.Error()))
+		return
+	}
+
+	for i := range hwmgrs.Items {
+		hwmgr := &hwmgrs.Items[i]
+		key := client.ObjectKeyFromObject(hwmgr).String()
+		if hwmgr.Spec.AdaptorID != pluginv1alpha1.SupportedAdaptors.Dell || hwmgr.Spec.DellData == nil ||
+			hwmgr.Spec.DellData.ResourceGroupGC == nil {
+			a.orphanedResourceGroups.Delete(key)
+			continue
+		}
+
+		if last, ok := a.resourceGroupGCChecks.Load(key); ok && time.Since(last.(time.Time)) < getResourceGroupGCInterval(hwmgr) {
+			continue
+		}
+		a.resourceGroupGCChecks.Store(key, time.Now())
+
+		hwmgrCtx := logging.WithHwMgr(ctx, hwmgr.Name)
+		if err := a.collectHwMgrResourceGroups(hwmgrCtx, hwmgr); err != nil {
+			a.Logger.WarnContext(hwmgrCtx, "Resource group GC failed", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// findOrphanedResourceGroups lists the resource groups created by the Plugin that do not belong to an existing NodePool.
+// The resource groups of each tenant that a NodePool of the HardwareManager may be mapped to are checked, while the
+// NodePools of all HardwareManagers are cross-referenced, in case more than one is configured for the same hardware
+// manager.
+func (a *Adaptor) findOrphanedResourceGroups(ctx context.Context, hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager) ([]orphanedResourceGroup, error) {
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := a.Client.List(ctx, nodepools, client.InNamespace(a.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	tenants := []string{hwmgrClient.GetTenant()}
+	if mapping := hwmgr.Spec.DellData.TenantMapping; mapping != nil {
+		tenants = append(tenants, mapping.AllowedTenants...)
+	}
+
+	// NodePools being deleted still hold their resource group, which is deleted as they are finalized
+	expected := make(map[string]bool)
+	for i := range nodepools.Items {
+		nodepool := &nodepools.Items[i]
+		expected[hwmgrclient.ResourceGroupIdFromNodePool(nodepool)] = true
+		if nodepool.Spec.HwMgrId == hwmgr.Name {
+			tenants = append(tenants, hwmgrClient.WithTenant(nodepool.GetAnnotations()[NodePoolTenantAnnotation]).GetTenant())
+		}
+	}
+	slices.Sort(tenants)
+	tenants = slices.Compact(tenants)
+
+	var orphans []orphanedResourceGroup
+	for _, tenant := range tenants {
+		resourceGroups, err := hwmgrClient.WithTenant(tenant).GetResourceGroups(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to query resource groups of tenant %s: %w", tenant, err)
+		}
+		if resourceGroups.ResourceGroups == nil {
+			continue
+		}
+
+		for _, rg := range *resourceGroups.ResourceGroups {
+			if rg.Id == nil || !strings.HasPrefix(*rg.Id, hwmgrclient.ResourceGroupIdPrefix) || expected[*rg.Id] {
+				continue
+			}
+			orphans = append(orphans, orphanedResourceGroup{tenant: tenant, rgId: *rg.Id})
+		}
+	}
+
+	return orphans, nil
+}
+
+// collectHwMgrResourceGroups reports the orphaned resource groups of a HardwareManager, deleting them if enabled. A
+// resource group is only deleted once it has been found orphaned by two consecutive checks, so that a stale view of
+// the NodePools cannot cause the resource group of a NodePool to be deleted.
+func (a *Adaptor) collectHwMgrResourceGroups(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) error {
+	hwmgrClient, err := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
+	if err != nil {
+		return fmt.Errorf("failed to create hwmgr client: %w", err)
+	}
+
+	orphans, err := a.findOrphanedResourceGroups(ctx, hwmgrClient, hwmgr)
+	if err != nil {
+		return err
+	}
+
+	key := client.ObjectKeyFromObject(hwmgr).String()
+	previous := make(map[orphanedResourceGroup]bool)
+	if value, ok := a.orphanedResourceGroups.Load(key); ok {
+		previous = value.(map[orphanedResourceGroup]bool)
+	}
+
+	found := make(map[orphanedResourceGroup]bool)
+	var remaining []string
+	for _, orphan := range orphans {
+		found[orphan] = true
+		if !hwmgr.Spec.DellData.ResourceGroupGC.Delete || !previous[orphan] {
+			a.Logger.InfoContext(ctx, "Found orphaned resource group", slog.String("tenant", orphan.tenant),
+				slog.String("resourceGroup", orphan.rgId))
+			remaining = append(remaining, orphan.String())
+			continue
+		}
+
+		jobId, err := hwmgrClient.WithTenant(orphan.tenant).DeleteResourceGroupFromId(ctx, orphan.rgId)
+		if err != nil {
+			a.Logger.WarnContext(ctx, "Unable to delete orphaned resource group", slog.String("tenant", orphan.tenant),
+				slog.String("resourceGroup", orphan.rgId), slog.String("error", err.Error()))
+			remaining = append(remaining, orphan.String())
+			continue
+		}
+		a.Logger.InfoContext(ctx, "Deleting orphaned resource group", slog.String("tenant", orphan.tenant),
+			slog.String("resourceGroup", orphan.rgId), slog.String("jobId", jobId))
+		delete(found, orphan)
+	}
+	a.orphanedResourceGroups.Store(key, found)
+
+	return a.setOrphanedResourceGroupsCondition(ctx, hwmgr, remaining)
+}
+
+// setOrphanedResourceGroupsCondition reports the orphaned resource groups in the OrphanedResourceGroups condition of
+// the HardwareManager. The condition is only cleared if it was previously set.
+func (a *Adaptor) setOrphanedResourceGroupsCondition(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	orphans []string) error {
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.OrphanedResourceGroups)
+	status := metav1.ConditionTrue
+	reason := pluginv1alpha1.ConditionReasons.Diverged
+	message := "Orphaned resource groups: " + strings.Join(orphans, ", ")
+	if len(orphans) == 0 {
+		status = metav1.ConditionFalse
+		reason = pluginv1alpha1.ConditionReasons.Completed
+		message = "No orphaned resource groups"
+	}
+
+	// The HardwareManager status is also updated by its controller, so the latest version is updated
+	// nolint: wrapcheck
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &pluginv1alpha1.HardwareManager{}
+		if err := a.Client.Get(ctx, client.ObjectKeyFromObject(hwmgr), latest); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get HardwareManager %s: %w", hwmgr.Name, err)
+		}
+
+		condition := meta.FindStatusCondition(latest.Status.Conditions, conditionType)
+		if condition == nil && len(orphans) == 0 {
+			return nil
+		}
+		if condition != nil && condition.Status == status && condition.Reason == string(reason) &&
+			utils.ConditionMessage(condition) == message {
+			return nil
+		}
+
+		return utils.UpdateHardwareManagerStatusCondition(ctx, a.Client, latest,
+			pluginv1alpha1.ConditionTypes.OrphanedResourceGroups, reason, status, message)
+	})
+}
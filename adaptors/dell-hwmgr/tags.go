@@ -0,0 +1,120 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// HandleNodeTags requests the hardware manager to update the tags of the resource backing a node, keeping the tags of
+// the resource that were not set through the node. The job ID is tracked in an annotation on the node, tagged with
+// the requested tags, and the tags are applied once the job completes.
+func (a *Adaptor) HandleNodeTags(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	add, remove []string) (bool, error) {
+
+	hwmgrClient, err := hwmgrclient.NewClientWithResponses(ctx, a.Logger, a.Client, hwmgr)
+	if err != nil {
+		return false, fmt.Errorf("failed to create hwmgr client: %w", err)
+	}
+	if hwmgrClient, err = a.getNodeClient(ctx, hwmgrClient, node); err != nil {
+		return false, err
+	}
+
+	tags, err := utils.ParseNodeTags(node.Annotations[utils.NodeTagsAnnotation])
+	if err != nil {
+		return false, err
+	}
+	requested := utils.JoinNodeTags(tags)
+
+	jobTags, jobId, _ := strings.Cut(node.Annotations[utils.NodeTagsJobAnnotation], ";")
+	if jobId == "" || jobTags != requested {
+		resource, err := hwmgrClient.GetResource(ctx, node)
+		if err != nil {
+			return false, fmt.Errorf("failed to get resource for node %s: %w", node.Name, err)
+		}
+
+		var current []string
+		if resource != nil && resource.Resource != nil && resource.Resource.Tags != nil {
+			current = *resource.Resource.Tags
+		}
+		updated := slices.DeleteFunc(slices.Clone(current), func(tag string) bool {
+			return slices.Contains(remove, tag)
+		})
+		for _, tag := range add {
+			if !slices.Contains(updated, tag) {
+				updated = append(updated, tag)
+			}
+		}
+		if slices.Equal(updated, current) {
+			// The resource already has the requested tags
+			if jobId != "" {
+				if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.NodeTagsJobAnnotation, ""); err != nil {
+					return false, fmt.Errorf("failed to clear tags job for node %s: %w", node.Name, err)
+				}
+			}
+			return true, nil
+		}
+
+		a.Logger.InfoContext(ctx, "Requesting tags update", slog.Any("tags", updated))
+		jobId, err = hwmgrClient.UpdateResourceTags(ctx, node, updated)
+		if err != nil {
+			return false, fmt.Errorf("failed to update tags for node %s: %w", node.Name, err)
+		}
+
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.NodeTagsJobAnnotation, requested+";"+jobId); err != nil {
+			return false, fmt.Errorf("failed to record tags job for node %s: %w", node.Name, err)
+		}
+		return false, nil
+	}
+
+	status, failReason, err := hwmgrClient.CheckJobStatus(ctx, jobId)
+	if err != nil {
+		return false, fmt.Errorf("tags job progress check failed: %w", err)
+	}
+
+	switch status {
+	case hwmgrclient.JobStatusInProgress:
+		a.Logger.InfoContext(ctx, "Tags job is in progress", slog.String("jobId", jobId))
+		return false, nil
+	case hwmgrclient.JobStatusCompleted:
+		a.Logger.InfoContext(ctx, "Tags job has completed", slog.String("jobId", jobId))
+	case hwmgrclient.JobStatusFailed:
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.NodeTagsJobAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear tags job for node %s: %w", node.Name, err)
+		}
+		return false, fmt.Errorf("tags job %s failed: %s", jobId, a.classifyFailReason(ctx, hwmgr, failReason))
+	case hwmgrclient.JobStatusNotExist:
+		// Check the tags of the resource again, requesting the update again if they are not applied
+		a.Logger.InfoContext(ctx, "Tags job no longer exists on hardware manager", slog.String("jobId", jobId))
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.NodeTagsJobAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear tags job for node %s: %w", node.Name, err)
+		}
+		return false, nil
+	default:
+		a.Logger.InfoContext(ctx, "Tags job check returned unknown status",
+			slog.Any("status", status), slog.String("failReason", failReason))
+		return false, nil
+	}
+
+	if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.NodeTagsJobAnnotation, ""); err != nil {
+		return false, fmt.Errorf("failed to clear tags job for node %s: %w", node.Name, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,122 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NodePoolTenantAnnotation records the hardware manager tenant resolved for a NodePool by the tenant mapping of its
+// HardwareManager, so that the resource group of the NodePool is always accessed as the same tenant
+const NodePoolTenantAnnotation = "hwmgr-plugin.oran.openshift.io/tenant"
+
+// mapNodePoolTenant derives the tenant of a NodePool from its extensions, or the labels of its cluster namespace,
+// returning an empty string if the NodePool is not mapped to a tenant
+func (a *Adaptor) mapNodePoolTenant(ctx context.Context, mapping *pluginv1alpha1.TenantMapping,
+	nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
+
+	if mapping.ExtensionKey != "" {
+		if tenant := nodepool.Spec.Extensions[mapping.ExtensionKey]; tenant != "" {
+			return tenant, nil
+		}
+	}
+
+	if mapping.NamespaceLabel != "" && nodepool.Spec.CloudID != "" {
+		ns := &corev1.Namespace{}
+		if err := a.NoncachedClient.Get(ctx, types.NamespacedName{Name: nodepool.Spec.CloudID}, ns); err != nil {
+			if !errors.IsNotFound(err) {
+				return "", fmt.Errorf("failed to get namespace %s: %w", nodepool.Spec.CloudID, err)
+			}
+		} else if tenant := ns.Labels[mapping.NamespaceLabel]; tenant != "" {
+			return tenant, nil
+		}
+	}
+
+	return "", nil
+}
+
+// getNodePoolTenant returns the tenant recorded for a NodePool, resolving and recording it on first use. An empty
+// string is returned for NodePools that use the tenant of the HardwareManager.
+func (a *Adaptor) getNodePoolTenant(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
+
+	if tenant, exists := nodepool.GetAnnotations()[NodePoolTenantAnnotation]; exists {
+		return tenant, nil
+	}
+
+	mapping := hwmgr.Spec.DellData.TenantMapping
+	if mapping == nil {
+		return "", nil
+	}
+
+	// A NodePool handled before the mapping was configured keeps the tenant of the HardwareManager, which holds its
+	// resource group
+	var tenant string
+	if meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) == nil {
+		var err error
+		if tenant, err = a.mapNodePoolTenant(ctx, mapping, nodepool); err != nil {
+			return "", err
+		}
+	}
+	if tenant != "" && len(mapping.AllowedTenants) > 0 && !slices.Contains(mapping.AllowedTenants, tenant) {
+		return "", typederrors.NewInputError("tenant %s of NodePool %s is not allowed by HardwareManager %s",
+			tenant, nodepool.Name, hwmgr.Name)
+	}
+
+	// The annotation is recorded even for an unmapped NodePool, so that a mapping added later does not move the
+	// NodePool to a tenant that does not hold its resource group
+	if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, nodepool,
+		[]utils.MetaMutation{utils.AddAnnotation(NodePoolTenantAnnotation, tenant)}); err != nil {
+		return "", fmt.Errorf("failed to record tenant of NodePool %s: %w", nodepool.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Resolved NodePool tenant", slog.String("nodepool", nodepool.Name),
+		slog.String("tenant", tenant))
+	return tenant, nil
+}
+
+// getNodePoolClient returns the hwmgr client for the API calls of a NodePool, as its tenant
+func (a *Adaptor) getNodePoolClient(ctx context.Context, hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (*hwmgrclient.HardwareManagerClient, error) {
+
+	tenant, err := a.getNodePoolTenant(ctx, hwmgr, nodepool)
+	if err != nil {
+		return nil, err
+	}
+	return hwmgrClient.WithTenant(tenant), nil
+}
+
+// getNodeClient returns the hwmgr client for the API calls of a Node, as the tenant of its NodePool
+func (a *Adaptor) getNodeClient(ctx context.Context, hwmgrClient *hwmgrclient.HardwareManagerClient,
+	node *hwmgmtv1alpha1.Node) (*hwmgrclient.HardwareManagerClient, error) {
+
+	if node.Spec.NodePool == "" {
+		return hwmgrClient, nil
+	}
+
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}, nodepool); err != nil {
+		if errors.IsNotFound(err) {
+			return hwmgrClient, nil
+		}
+		return nil, fmt.Errorf("failed to get NodePool %s of node %s: %w", node.Spec.NodePool, node.Name, err)
+	}
+	return hwmgrClient.WithTenant(nodepool.GetAnnotations()[NodePoolTenantAnnotation]), nil
+}
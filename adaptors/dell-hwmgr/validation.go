@@ -0,0 +1,191 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellhwmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultResourceGroupValidationInterval is used when the HardwareManager does not configure the interval
+const defaultResourceGroupValidationInterval = 15 * time.Minute
+
+// getResourceGroupValidationInterval returns the resource group validation interval configured for the HardwareManager
+func getResourceGroupValidationInterval(hwmgr *pluginv1alpha1.HardwareManager) time.Duration {
+	if hwmgr.Spec.DellData == nil || hwmgr.Spec.DellData.ResourceGroupValidationInterval == nil ||
+		hwmgr.Spec.DellData.ResourceGroupValidationInterval.Duration <= 0 {
+		return defaultResourceGroupValidationInterval
+	}
+	return hwmgr.Spec.DellData.ResourceGroupValidationInterval.Duration
+}
+
+// findResourceGroupDivergence compares the resource group on the hardware manager with the NodePool and its nodes,
+// returning a description of each difference found
+func (a *Adaptor) findResourceGroupDivergence(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	nodepool *hwmgmtv1alpha1.NodePool) ([]string, error) {
+
+	if exists, err := hwmgrClient.ResourceGroupExists(ctx, nodepool); err != nil {
+		return nil, fmt.Errorf("resource group existence check failed for NodePool %s: %w", nodepool.Name, err)
+	} else if !exists {
+		return []string{"resource group no longer exists on hardware manager"}, nil
+	}
+
+	rg, err := hwmgrClient.GetResourceGroupFromNodePool(ctx, nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource group for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	var divergence []string
+	if err := hwmgrClient.ValidateResourceGroup(ctx, nodepool, *rg); err != nil {
+		divergence = append(divergence, strings.ReplaceAll(err.Error(), "\n", ","))
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	// Check that each node is still backed by a server in the resource group, and no servers have been added
+	var resourceIds []string
+	if rg.ResourceSelectors != nil {
+		for _, resourceSelector := range *rg.ResourceSelectors {
+			if resourceSelector.Resources == nil {
+				continue
+			}
+			for _, resource := range *resourceSelector.Resources {
+				if resource.Id != nil {
+					resourceIds = append(resourceIds, *resource.Id)
+				}
+			}
+		}
+	}
+
+	for _, node := range nodelist.Items {
+		if !slices.Contains(resourceIds, node.Spec.HwMgrNodeId) {
+			divergence = append(divergence, fmt.Sprintf("node %s (%s) is no longer in resource group", node.Name, node.Spec.HwMgrNodeId))
+		}
+	}
+	for _, resourceId := range resourceIds {
+		if utils.FindNodeInList(*nodelist, nodepool.Spec.HwMgrId, resourceId) == "" {
+			divergence = append(divergence, fmt.Sprintf("resource %s in resource group has no node", resourceId))
+		}
+	}
+
+	return divergence, nil
+}
+
+// resourceGroupCheck holds the result of the last resource group validation of a NodePool
+type resourceGroupCheck struct {
+	checkedAt  time.Time
+	divergence []string
+}
+
+// checkResourceGroup periodically re-validates the resource group of a provisioned NodePool, returning any divergence
+// from the NodePool, such as servers removed on the hardware manager side, and the time until the next check. Between
+// checks, the divergence found by the last check is returned.
+func (a *Adaptor) checkResourceGroup(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) ([]string, time.Duration, error) {
+
+	// Other NodePool events retrigger this between checks, so track when the last check was done
+	interval := getResourceGroupValidationInterval(hwmgr)
+	key := client.ObjectKeyFromObject(nodepool).String()
+	if last, ok := a.resourceGroupChecks.Load(key); ok {
+		check := last.(resourceGroupCheck)
+		if elapsed := time.Since(check.checkedAt); elapsed < interval {
+			return check.divergence, interval - elapsed, nil
+		}
+	}
+
+	divergence, err := a.findResourceGroupDivergence(ctx, hwmgrClient, nodepool)
+	if err != nil {
+		return nil, 0, err
+	}
+	a.resourceGroupChecks.Store(key, resourceGroupCheck{checkedAt: time.Now(), divergence: divergence})
+
+	if len(divergence) > 0 {
+		a.Logger.WarnContext(ctx, "Resource group has diverged from NodePool", slog.Any("divergence", divergence))
+	}
+	return divergence, interval, nil
+}
+
+// checkProvisionedNodePool periodically re-validates the resource group of a provisioned NodePool and polls the health
+// of its servers, reporting any divergence or hardware fault in the Degraded condition of the NodePool. Divergence is
+// reported ahead of hardware faults, as it means the nodes of the NodePool may no longer be the servers being polled.
+func (a *Adaptor) checkProvisionedNodePool(
+	ctx context.Context,
+	hwmgrClient *hwmgrclient.HardwareManagerClient,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	if !meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	if result, handled, err := a.checkNodeRemoval(ctx, hwmgrClient, hwmgr, nodepool); handled || err != nil {
+		return result, err
+	}
+
+	divergence, nextValidation, err := a.checkResourceGroup(ctx, hwmgrClient, hwmgr, nodepool)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+
+	faults, nextHealthCheck, err := a.checkHardwareHealth(ctx, hwmgrClient, hwmgr, nodepool)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.Degraded)
+	status := metav1.ConditionFalse
+	reason := string(pluginv1alpha1.ConditionReasons.Completed)
+	message := "Resource group matches NodePool"
+	var problems []string
+	if len(divergence) > 0 {
+		problems = append(problems, "Resource group has diverged from NodePool: "+strings.Join(divergence, "; "))
+	}
+	if len(faults) > 0 {
+		problems = append(problems, "Hardware faults reported: "+strings.Join(faults, "; "))
+	}
+	switch {
+	case len(divergence) > 0:
+		status = metav1.ConditionTrue
+		reason = string(pluginv1alpha1.ConditionReasons.Diverged)
+		message = strings.Join(problems, ". ")
+	case len(faults) > 0:
+		status = metav1.ConditionTrue
+		reason = string(pluginv1alpha1.ConditionReasons.HardwareFault)
+		message = strings.Join(problems, ". ")
+	}
+
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, conditionType)
+	if condition == nil || condition.Status != status || condition.Reason != reason || utils.ConditionMessage(condition) != message {
+		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+			hwmgmtv1alpha1.ConditionType(conditionType), hwmgmtv1alpha1.ConditionReason(reason), status, message); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	return utils.RequeueWithCustomInterval(min(nextValidation, nextHealthCheck)), nil
+}
@@ -0,0 +1,304 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+const (
+	// backendProbeInterval is the interval at which the inventory backend of each HardwareManager is probed
+	backendProbeInterval = 30 * time.Second
+
+	// backendProbeTimeout bounds a single inventory backend probe
+	backendProbeTimeout = 10 * time.Second
+
+	// cacheSyncCheckTimeout bounds the wait for the manager cache in a health check, which must answer within the
+	// timeout of the kubelet probe
+	cacheSyncCheckTimeout = 500 * time.Millisecond
+)
+
+// Readiness check names, which can be passed to the exclude parameter of the /readyz endpoint
+const (
+	CacheSyncCheck         = "cache-sync"
+	HardwareManagersCheck  = "hardware-managers"
+	InventoryBackendsCheck = "inventory-backends"
+)
+
+// HealthCheckStatus is the outcome of a health check
+type HealthCheckStatus string
+
+const (
+	HealthCheckPassed  HealthCheckStatus = "Passed"
+	HealthCheckFailed  HealthCheckStatus = "Failed"
+	HealthCheckUnknown HealthCheckStatus = "Unknown"
+)
+
+// HealthCheckResult reports the outcome of a single health check
+type HealthCheckResult struct {
+	Status      HealthCheckStatus `json:"status"`
+	Message     string            `json:"message,omitempty"`
+	LastChecked *metav1.Time      `json:"lastChecked,omitempty"`
+}
+
+// HardwareManagerHealth reports the health of a HardwareManager served by this deployment
+type HardwareManagerHealth struct {
+	Name       string            `json:"name"`
+	AdaptorId  string            `json:"adaptorId"`
+	Validation HealthCheckResult `json:"validation"`
+	Inventory  HealthCheckResult `json:"inventory"`
+}
+
+// HealthStatus is the aggregated health of the plugin and its hardware manager backends
+type HealthStatus struct {
+	Ready            bool                    `json:"ready"`
+	CacheSync        HealthCheckResult       `json:"cacheSync"`
+	HardwareManagers []HardwareManagerHealth `json:"hardwareManagers"`
+}
+
+// backendProbe records the outcome of the last inventory probe of a HardwareManager
+type backendProbe struct {
+	err       error
+	checkedAt time.Time
+}
+
+// backendHealthMonitor periodically probes the inventory backend of each HardwareManager, so that health checks
+// report the last known reachability rather than querying the backends within the timeout of a kubelet probe
+type backendHealthMonitor struct {
+	controller *HwMgrAdaptorController
+	mu         sync.RWMutex
+	probes     map[string]backendProbe
+}
+
+func newBackendHealthMonitor(controller *HwMgrAdaptorController) *backendHealthMonitor {
+	return &backendHealthMonitor{
+		controller: controller,
+		probes:     make(map[string]backendProbe),
+	}
+}
+
+// NeedLeaderElection runs the monitor on every replica, as each replica serves its own health endpoints
+func (m *backendHealthMonitor) NeedLeaderElection() bool {
+	return false
+}
+
+// Start probes the inventory backends until the context is cancelled
+func (m *backendHealthMonitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(backendProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		m.probeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeAll queries the resource pools of each HardwareManager served by this deployment
+func (m *backendHealthMonitor) probeAll(ctx context.Context) {
+	c := m.controller
+
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := c.Client.List(ctx, hwmgrs, client.InNamespace(c.Namespace)); err != nil {
+		c.Logger.ErrorContext(ctx, "Failed to list HardwareManagers for backend probe", slog.String("error", err.Error()))
+		return
+	}
+
+	probes := make(map[string]backendProbe)
+	for i := range hwmgrs.Items {
+		hwmgr := &hwmgrs.Items[i]
+		adaptor, exists := c.adaptors[string(hwmgr.Spec.AdaptorID)]
+		if !exists || !hwmgr.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, backendProbeTimeout)
+		_, _, err := adaptor.GetResourcePools(probeCtx, hwmgr)
+		cancel()
+		if err != nil {
+			c.Logger.InfoContext(ctx, "Inventory backend probe failed",
+				slog.String("hwmgr", hwmgr.Name), slog.String("error", err.Error()))
+		}
+		probes[hwmgr.Name] = backendProbe{err: err, checkedAt: time.Now()}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.probes = probes
+}
+
+// getProbe returns the last inventory probe of a HardwareManager, if it has been probed
+func (m *backendHealthMonitor) getProbe(name string) (backendProbe, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	probe, exists := m.probes[name]
+	return probe, exists
+}
+
+// checkCacheSync reports whether the manager cache has synced
+func (c *HwMgrAdaptorController) checkCacheSync(ctx context.Context) HealthCheckResult {
+	if c.waitForCacheSync == nil {
+		return HealthCheckResult{Status: HealthCheckUnknown, Message: "Adaptors not set up"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cacheSyncCheckTimeout)
+	defer cancel()
+	if !c.waitForCacheSync(ctx) {
+		return HealthCheckResult{Status: HealthCheckFailed, Message: "Cache not synced"}
+	}
+	return HealthCheckResult{Status: HealthCheckPassed}
+}
+
+// getHardwareManagerHealth reports the validation and inventory reachability of a HardwareManager
+func (c *HwMgrAdaptorController) getHardwareManagerHealth(hwmgr *pluginv1alpha1.HardwareManager) HardwareManagerHealth {
+	health := HardwareManagerHealth{
+		Name:      hwmgr.Name,
+		AdaptorId: string(hwmgr.Spec.AdaptorID),
+	}
+
+	condition := utils.GetHardwareManagerValidationCondition(hwmgr)
+	switch {
+	case condition == nil:
+		health.Validation = HealthCheckResult{Status: HealthCheckUnknown, Message: "Validation pending"}
+	case condition.Status == metav1.ConditionTrue:
+		health.Validation = HealthCheckResult{Status: HealthCheckPassed, Message: condition.Message}
+	default:
+		health.Validation = HealthCheckResult{Status: HealthCheckFailed, Message: condition.Message}
+	}
+	if condition != nil {
+		health.Validation.LastChecked = condition.LastTransitionTime.DeepCopy()
+	}
+
+	probe, exists := c.backendHealth.getProbe(hwmgr.Name)
+	switch {
+	case !exists:
+		health.Inventory = HealthCheckResult{Status: HealthCheckUnknown, Message: "Inventory backend not yet probed"}
+	case probe.err != nil:
+		health.Inventory = HealthCheckResult{Status: HealthCheckFailed, Message: probe.err.Error()}
+	default:
+		health.Inventory = HealthCheckResult{Status: HealthCheckPassed}
+	}
+	if exists {
+		health.Inventory.LastChecked = &metav1.Time{Time: probe.checkedAt}
+	}
+
+	return health
+}
+
+// listHardwareManagerHealth reports the health of each HardwareManager served by the adaptors of this deployment
+func (c *HwMgrAdaptorController) listHardwareManagerHealth(ctx context.Context) ([]HardwareManagerHealth, error) {
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := c.Client.List(ctx, hwmgrs, client.InNamespace(c.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list HardwareManagers: %w", err)
+	}
+
+	health := []HardwareManagerHealth{}
+	for i := range hwmgrs.Items {
+		hwmgr := &hwmgrs.Items[i]
+		if !c.IsAdaptorEnabled(string(hwmgr.Spec.AdaptorID)) || !hwmgr.DeletionTimestamp.IsZero() {
+			continue
+		}
+		health = append(health, c.getHardwareManagerHealth(hwmgr))
+	}
+
+	slices.SortFunc(health, func(a, b HardwareManagerHealth) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return health, nil
+}
+
+// GetHealthStatus aggregates the readiness of the manager cache and of the HardwareManagers served by this deployment.
+// The plugin is ready when the cache has synced and no HardwareManager has failed validation or an inventory probe.
+func (c *HwMgrAdaptorController) GetHealthStatus(ctx context.Context) *HealthStatus {
+	status := &HealthStatus{
+		CacheSync:        c.checkCacheSync(ctx),
+		HardwareManagers: []HardwareManagerHealth{},
+	}
+
+	if status.CacheSync.Status != HealthCheckPassed {
+		return status
+	}
+
+	health, err := c.listHardwareManagerHealth(ctx)
+	if err != nil {
+		status.CacheSync = HealthCheckResult{Status: HealthCheckFailed, Message: err.Error()}
+		return status
+	}
+	status.HardwareManagers = health
+
+	status.Ready = true
+	for _, hwmgr := range health {
+		if hwmgr.Validation.Status == HealthCheckFailed || hwmgr.Inventory.Status == HealthCheckFailed {
+			status.Ready = false
+		}
+	}
+
+	return status
+}
+
+// hardwareManagerCheck returns a health checker that fails if the given check has failed for any HardwareManager
+func (c *HwMgrAdaptorController) hardwareManagerCheck(
+	description string, result func(HardwareManagerHealth) HealthCheckResult) healthz.Checker {
+
+	return func(req *http.Request) error {
+		if c.checkCacheSync(req.Context()).Status != HealthCheckPassed {
+			// Reported by the cache sync check
+			return nil
+		}
+
+		health, err := c.listHardwareManagerHealth(req.Context())
+		if err != nil {
+			return err
+		}
+
+		var failed []string
+		for _, hwmgr := range health {
+			if check := result(hwmgr); check.Status == HealthCheckFailed {
+				failed = append(failed, fmt.Sprintf("%s: %s", hwmgr.Name, check.Message))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%s failed for %s", description, strings.Join(failed, "; "))
+		}
+		return nil
+	}
+}
+
+// ReadyzChecks returns the readiness checks of the plugin, by name, for registration with the manager
+func (c *HwMgrAdaptorController) ReadyzChecks() map[string]healthz.Checker {
+	return map[string]healthz.Checker{
+		CacheSyncCheck: func(req *http.Request) error {
+			if result := c.checkCacheSync(req.Context()); result.Status != HealthCheckPassed {
+				return fmt.Errorf("%s", result.Message)
+			}
+			return nil
+		},
+		HardwareManagersCheck: c.hardwareManagerCheck("validation",
+			func(hwmgr HardwareManagerHealth) HealthCheckResult { return hwmgr.Validation }),
+		InventoryBackendsCheck: c.hardwareManagerCheck("inventory backend probe",
+			func(hwmgr HardwareManagerHealth) HealthCheckResult { return hwmgr.Inventory }),
+	}
+}
@@ -0,0 +1,240 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+)
+
+// InventoryError describes a failed inventory query, independent of the API it was made through
+type InventoryError struct {
+	// Status is the HTTP status code for the failure
+	Status int
+	// Detail is a description of the failure that can be returned to the client
+	Detail string
+	Err    error
+}
+
+func (e *InventoryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InventoryError) Unwrap() error {
+	return e.Err
+}
+
+// getProblemDetails returns the problem details and HTTP status code to report for an inventory query failure
+func getProblemDetails(err error) (invserver.ProblemDetails, int) {
+	var invErr *InventoryError
+	if !errors.As(err, &invErr) {
+		return invserver.ProblemDetails{Status: http.StatusInternalServerError, Detail: err.Error()}, http.StatusInternalServerError
+	}
+	return invserver.ProblemDetails{Status: invErr.Status, Detail: invErr.Detail}, invErr.Status
+}
+
+// getInventoryAdaptor looks up the HardwareManager and the adaptor that serves it for an inventory query
+func (c *HwMgrAdaptorController) getInventoryAdaptor(ctx context.Context, hwMgrId string) (
+	*pluginv1alpha1.HardwareManager, adaptorinterface.HwMgrAdaptorIntf, error) {
+
+	hwmgr, statusCode, err := c.getHwMgr(ctx, hwMgrId)
+	if err != nil {
+		if statusCode == http.StatusNotFound {
+			return nil, nil, &InventoryError{
+				Status: statusCode,
+				Detail: fmt.Sprintf("Hardware Manager %s not found", hwMgrId),
+				Err:    fmt.Errorf("hardware manager %s not found: %w", hwMgrId, err),
+			}
+		}
+		return nil, nil, &InventoryError{
+			Status: statusCode,
+			Detail: fmt.Sprintf("Hardware Manager %s unavailable: %s", hwMgrId, err.Error()),
+			Err:    fmt.Errorf("unable to get hardware manager %s: %w", hwMgrId, err),
+		}
+	}
+
+	adaptorID := string(hwmgr.Spec.AdaptorID)
+
+	// Validate the specified adaptor ID
+	adaptor, exists := c.adaptors[adaptorID]
+	if !exists {
+		// The adaptor is either unknown or not enabled in this deployment
+		c.Logger.ErrorContext(ctx, "unsupported adaptor ID", slog.String("adaptorID", adaptorID))
+		return nil, nil, &InventoryError{
+			Status: http.StatusServiceUnavailable,
+			Detail: fmt.Sprintf("Hardware Manager %s specifies unsupported or disabled adaptorId: %s", hwMgrId, adaptorID),
+			Err:    fmt.Errorf("hardware manager %s specifies unsupported or disabled adaptorId: %s", hwMgrId, adaptorID),
+		}
+	}
+
+	return hwmgr, adaptor, nil
+}
+
+// ListResourcePools calls the applicable adaptor handler to query the resource pools of a hardware manager. If a site is
+// given, only the resource pools of that site are returned.
+func (c *HwMgrAdaptorController) ListResourcePools(ctx context.Context, hwMgrId, site string) ([]invserver.ResourcePoolInfo, error) {
+	ctx = logging.WithHwMgr(ctx, hwMgrId)
+
+	hwmgr, adaptor, err := c.getInventoryAdaptor(ctx, hwMgrId)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []invserver.ResourcePoolInfo
+	var statusCode int
+	siteAdaptor, isSiteAdaptor := adaptor.(adaptorinterface.SiteResourcePoolsAdaptorIntf)
+	switch {
+	case site != "" && isSiteAdaptor:
+		resp, statusCode, err = siteAdaptor.GetSiteResourcePools(ctx, hwmgr, site)
+	case site != "":
+		resp, statusCode, err = adaptor.GetResourcePools(ctx, hwmgr)
+		resp = slices.DeleteFunc(resp, func(pool invserver.ResourcePoolInfo) bool {
+			return pool.SiteId == nil || *pool.SiteId != site
+		})
+	default:
+		resp, statusCode, err = adaptor.GetResourcePools(ctx, hwmgr)
+	}
+	if err != nil {
+		c.Logger.ErrorContext(ctx, "unable to get resource pools from hardware manager", slog.String("hwMgrId", hwMgrId), slog.String("error", err.Error()))
+		return nil, &InventoryError{
+			Status: statusCode,
+			Detail: fmt.Sprintf("Resource Pool query failed for %s: %s", hwMgrId, err.Error()),
+			Err:    fmt.Errorf("unable to query pools from hardware manager %s: %w", hwMgrId, err),
+		}
+	}
+
+	return resp, nil
+}
+
+// ListResourcePoolCapacity calls the applicable adaptor handlers to count the resources of each resource pool of a
+// hardware manager, and how many of them are allocated. The resources are counted as they are streamed from the
+// adaptor, so the full list is never held.
+func (c *HwMgrAdaptorController) ListResourcePoolCapacity(ctx context.Context, hwMgrId string) ([]invserver.ResourcePoolCapacity, error) {
+	pools, err := c.ListResourcePools(ctx, hwMgrId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := make([]invserver.ResourcePoolCapacity, 0, len(pools))
+	index := make(map[string]int, len(pools))
+	for _, pool := range pools {
+		if _, exists := index[pool.ResourcePoolId]; exists {
+			continue
+		}
+		index[pool.ResourcePoolId] = len(capacity)
+		capacity = append(capacity, invserver.ResourcePoolCapacity{
+			ResourcePoolId: pool.ResourcePoolId,
+			SiteId:         pool.SiteId,
+		})
+	}
+
+	if err := c.StreamResources(ctx, hwMgrId, func(resource invserver.ResourceInfo) error {
+		i, exists := index[resource.ResourcePoolId]
+		if !exists {
+			// Count resources of pools the adaptor does not report, rather than dropping them from the totals
+			i = len(capacity)
+			index[resource.ResourcePoolId] = i
+			capacity = append(capacity, invserver.ResourcePoolCapacity{ResourcePoolId: resource.ResourcePoolId})
+		}
+
+		capacity[i].Total++
+		if resource.Allocated != nil && *resource.Allocated {
+			capacity[i].Allocated++
+		} else {
+			capacity[i].Free++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return capacity, nil
+}
+
+// ListResources calls the applicable adaptor handler to query the resources of a hardware manager
+func (c *HwMgrAdaptorController) ListResources(ctx context.Context, hwMgrId string) ([]invserver.ResourceInfo, error) {
+	ctx = logging.WithHwMgr(ctx, hwMgrId)
+
+	hwmgr, adaptor, err := c.getInventoryAdaptor(ctx, hwMgrId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, statusCode, err := adaptor.GetResources(ctx, hwmgr)
+	if err != nil {
+		c.Logger.ErrorContext(ctx, "unable to get resources from hardware manager", slog.String("hwMgrId", hwMgrId), slog.String("error", err.Error()))
+		return nil, &InventoryError{
+			Status: statusCode,
+			Detail: fmt.Sprintf("Resource query failed for %s: %s", hwMgrId, err.Error()),
+			Err:    fmt.Errorf("unable to query resources from hardware manager %s: %w", hwMgrId, err),
+		}
+	}
+
+	return resp, nil
+}
+
+// StreamResources calls the applicable adaptor handler to query the resources of a hardware manager, passing each
+// resource to emit as it is produced. A failure of emit ends the query, and is returned as is.
+func (c *HwMgrAdaptorController) StreamResources(ctx context.Context, hwMgrId string, emit func(invserver.ResourceInfo) error) error {
+	ctx = logging.WithHwMgr(ctx, hwMgrId)
+
+	hwmgr, adaptor, err := c.getInventoryAdaptor(ctx, hwMgrId)
+	if err != nil {
+		return err
+	}
+
+	var emitErr error
+	statusCode, err := adaptor.StreamResources(ctx, hwmgr, func(resource invserver.ResourceInfo) error {
+		if emitErr = emit(resource); emitErr != nil {
+			return emitErr
+		}
+		return nil
+	})
+	if emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		c.Logger.ErrorContext(ctx, "unable to stream resources from hardware manager", slog.String("hwMgrId", hwMgrId), slog.String("error", err.Error()))
+		return &InventoryError{
+			Status: statusCode,
+			Detail: fmt.Sprintf("Resource query failed for %s: %s", hwMgrId, err.Error()),
+			Err:    fmt.Errorf("unable to query resources from hardware manager %s: %w", hwMgrId, err),
+		}
+	}
+
+	return nil
+}
+
+// GetResourceInfo calls the applicable adaptor handler to query a single resource of a hardware manager
+func (c *HwMgrAdaptorController) GetResourceInfo(ctx context.Context, hwMgrId, resourceId string) (*invserver.ResourceInfo, error) {
+	resources, err := c.ListResources(ctx, hwMgrId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range resources {
+		if resources[i].ResourceId == resourceId {
+			return &resources[i], nil
+		}
+	}
+
+	return nil, &InventoryError{
+		Status: http.StatusNotFound,
+		Detail: fmt.Sprintf("Resource %s not found in Hardware Manager %s", resourceId, hwMgrId),
+		Err:    fmt.Errorf("resource %s not found in hardware manager %s", resourceId, hwMgrId),
+	}
+}
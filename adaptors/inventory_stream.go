@@ -0,0 +1,72 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+)
+
+//
+// For very large fleets, the resources of a hardware manager can be streamed as newline delimited JSON, with each
+// resource written to the response as the adaptor produces it rather than collected into a single array. The query
+// runs in a goroutine writing to a pipe that the response body is copied from, so at most one resource is buffered
+// between the adaptor and the client. The status code of the response is held back until the first resource is
+// produced, so that a query failing up front is reported with the same problem details as a JSON request. A query
+// failing once the stream has started cannot change the status code, and instead ends the stream with a final line
+// holding the problem details.
+//
+
+// streamResourcesResponse handles an inventory API request to stream the resources of a hardware manager
+func (c *HwMgrAdaptorController) streamResourcesResponse(ctx context.Context, hwMgrId string) (invserver.GetResourcesResponseObject, error) {
+	reader, writer := io.Pipe()
+
+	started := make(chan struct{})
+	var startOnce sync.Once
+	done := make(chan error, 1)
+
+	go func() {
+		encoder := json.NewEncoder(writer)
+		err := c.StreamResources(ctx, hwMgrId, func(resource invserver.ResourceInfo) error {
+			startOnce.Do(func() { close(started) })
+			return encoder.Encode(resource) // nolint: wrapcheck
+		})
+
+		select {
+		case <-started:
+			if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+				// The client is still reading, so report the failure at the end of the stream
+				problem, _ := getProblemDetails(err)
+				_ = encoder.Encode(problem)
+			}
+			_ = writer.Close()
+		default:
+			// Nothing has been written, so the failure can still be reported in the status of the response
+			_ = writer.CloseWithError(err)
+			done <- err
+		}
+	}()
+
+	select {
+	case <-started:
+	case err := <-done:
+		if err != nil {
+			_ = reader.Close()
+			return getResourcesProblemResponse(err)
+		}
+	case <-ctx.Done():
+		_ = reader.Close()
+		return nil, ctx.Err() // nolint: wrapcheck
+	}
+
+	return invserver.GetResources200ApplicationXNdjsonResponse{Body: reader}, nil
+}
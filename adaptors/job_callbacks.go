@@ -0,0 +1,130 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// JobCallbackTokenKey is the field of the HardwareManager callback secret holding the expected bearer token
+const JobCallbackTokenKey = "token"
+
+// JobCallback is the job status update posted by a hardware manager
+type JobCallback struct {
+	JobId   string `json:"jobId"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// jobCallbackStatuses are the job states reported by the hardware manager
+var jobCallbackStatuses = []string{"started", "pending", "completed", "failed"}
+
+// Validate checks that the callback identifies a job and carries a known job status
+func (j *JobCallback) Validate() error {
+	if j.JobId == "" {
+		return fmt.Errorf("jobId is required")
+	}
+
+	if !slices.Contains(jobCallbackStatuses, j.Status) {
+		return fmt.Errorf("unsupported job status %q, expected one of %v", j.Status, jobCallbackStatuses)
+	}
+
+	return nil
+}
+
+// AuthenticateJobCallback checks the bearer token presented on a job status callback against the callback secret of
+// the HardwareManager, returning the HTTP status to respond with on failure
+func (c *HwMgrAdaptorController) AuthenticateJobCallback(ctx context.Context, hwMgrId, token string) (int, error) {
+	hwmgr, status, err := c.getHwMgr(ctx, hwMgrId)
+	if err != nil {
+		return status, err
+	}
+
+	if hwmgr.Spec.AdaptorID != pluginv1alpha1.SupportedAdaptors.Dell {
+		return http.StatusNotFound, fmt.Errorf("job callbacks are not supported by adaptorId (%s) HardwareManager: name=%s",
+			hwmgr.Spec.AdaptorID, hwmgr.Name)
+	}
+
+	if hwmgr.Spec.DellData.CallbackSecret == nil {
+		return http.StatusForbidden, fmt.Errorf("job callbacks are not enabled for HardwareManager: name=%s", hwmgr.Name)
+	}
+
+	secret, err := utils.GetSecret(ctx, c.Client, *hwmgr.Spec.DellData.CallbackSecret, c.Namespace)
+	if err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("failed to get callback secret for HardwareManager %s: %w", hwmgr.Name, err)
+	}
+
+	expected, err := utils.GetSecretField(secret, JobCallbackTokenKey)
+	if err != nil || expected == "" {
+		return http.StatusServiceUnavailable, fmt.Errorf("callback secret for HardwareManager %s has no %s", hwmgr.Name, JobCallbackTokenKey)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return http.StatusUnauthorized, fmt.Errorf("invalid callback token for HardwareManager %s", hwmgr.Name)
+	}
+
+	return http.StatusOK, nil
+}
+
+// FindJobNodePools returns the NodePools of the HardwareManager waiting on the specified job, either directly or
+// through one of their Nodes
+func (c *HwMgrAdaptorController) FindJobNodePools(ctx context.Context, hwMgrId, jobId string) ([]*hwmgmtv1alpha1.NodePool, error) {
+	var nodepools []*hwmgmtv1alpha1.NodePool
+	seen := make(map[types.NamespacedName]bool)
+
+	addNodePool := func(nodepool *hwmgmtv1alpha1.NodePool) {
+		name := client.ObjectKeyFromObject(nodepool)
+		if nodepool.Spec.HwMgrId != hwMgrId || seen[name] {
+			return
+		}
+		seen[name] = true
+		nodepools = append(nodepools, nodepool)
+	}
+
+	nodepoolList := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.Client.List(ctx, nodepoolList, client.MatchingFields{utils.JobIdIndexKey: jobId}); err != nil {
+		return nil, fmt.Errorf("failed to query nodepools by job: %w", err)
+	}
+
+	for i := range nodepoolList.Items {
+		addNodePool(&nodepoolList.Items[i])
+	}
+
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	if err := c.Client.List(ctx, nodelist, client.MatchingFields{utils.JobIdIndexKey: jobId}); err != nil {
+		return nil, fmt.Errorf("failed to query nodes by job: %w", err)
+	}
+
+	for _, node := range nodelist.Items {
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		name := types.NamespacedName{Name: node.Spec.NodePool, Namespace: node.Namespace}
+		if err := c.Client.Get(ctx, name, nodepool); err != nil {
+			if errors.IsNotFound(err) {
+				c.Logger.InfoContext(ctx, "NodePool for job callback no longer exists",
+					slog.String("node", node.Name), slog.String("nodepool", node.Spec.NodePool))
+				continue
+			}
+			return nil, fmt.Errorf("failed to get nodepool %s: %w", name, err)
+		}
+		addNodePool(nodepool)
+	}
+
+	return nodepools, nil
+}
@@ -20,9 +20,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback/controller"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 )
 
 type Adaptor struct {
@@ -44,6 +46,13 @@ func NewAdaptor(client client.Client, noncachedClient client.Reader, scheme *run
 	}
 }
 
+func init() {
+	adaptorinterface.RegisterAdaptor(string(pluginv1alpha1.SupportedAdaptors.Loopback),
+		func(client client.Client, noncachedClient client.Reader, scheme *runtime.Scheme, logger *slog.Logger, namespace string) adaptorinterface.HwMgrAdaptorIntf {
+			return NewAdaptor(client, noncachedClient, scheme, logger, namespace)
+		})
+}
+
 // SetupAdaptor sets up the Loopback adaptor
 func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
 	a.Logger.Info("SetupAdaptor called for Loopback")
@@ -70,36 +79,65 @@ const (
 	NodePoolFSMNoop
 )
 
-func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
-	if len(nodepool.Status.Conditions) == 0 {
-		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
-		return NodePoolFSMCreate
+func (action fsmAction) String() string {
+	switch action {
+	case NodePoolFSMCreate:
+		return "create"
+	case NodePoolFSMProcessing:
+		return "processing"
+	case NodePoolFSMSpecChanged:
+		return "specChanged"
+	case NodePoolFSMNoop:
+		return "noop"
 	}
+	return "unknown"
+}
 
+func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
 	provisionedCondition := meta.FindStatusCondition(
 		nodepool.Status.Conditions,
 		string(hwmgmtv1alpha1.Provisioned))
-	if provisionedCondition != nil {
-		if provisionedCondition.Status == metav1.ConditionTrue {
-			// Check if the generation has changed
-			if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
-				a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
-				return NodePoolFSMSpecChanged
-			}
-			a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
-			return NodePoolFSMNoop
+	if provisionedCondition == nil {
+		// Nothing has been provisioned yet, which includes NodePools that have only been dry run
+		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
+		return NodePoolFSMCreate
+	}
+
+	if provisionedCondition.Status == metav1.ConditionTrue {
+		// Check if the generation has changed
+		if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
+			a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
+			return NodePoolFSMSpecChanged
 		}
+		// A configuration update can also be started without a spec change, to apply a node hardware profile override
+		if utils.IsNodePoolConfigUpdateRequested(nodepool) {
+			a.Logger.InfoContext(ctx, "Handling NodePool configuration update")
+			return NodePoolFSMSpecChanged
+		}
+		a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
+		return NodePoolFSMNoop
+	}
+
+	if provisionedCondition.Reason == string(hwmgmtv1alpha1.InvalidInput) {
+		a.Logger.InfoContext(ctx, "NodePool request has invalid input")
+		return NodePoolFSMNoop
+	}
 
-		return NodePoolFSMProcessing
+	if provisionedCondition.Reason == string(hwmgmtv1alpha1.TimedOut) {
+		a.Logger.InfoContext(ctx, "NodePool request timed out")
+		return NodePoolFSMNoop
 	}
 
-	return NodePoolFSMNoop
+	return NodePoolFSMProcessing
 }
 
 func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 	result := utils.DoNotRequeue()
 
-	switch a.determineAction(ctx, nodepool) {
+	action := a.determineAction(ctx, nodepool)
+	tracing.SetAttributes(ctx, tracing.AttrNodePoolPhase.String(action.String()))
+
+	switch action {
 	case NodePoolFSMCreate:
 		return a.HandleNodePoolCreate(ctx, hwmgr, nodepool)
 	case NodePoolFSMProcessing:
@@ -107,8 +145,8 @@ func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.Hard
 	case NodePoolFSMSpecChanged:
 		return a.HandleNodePoolSpecChanged(ctx, hwmgr, nodepool)
 	case NodePoolFSMNoop:
-		// Nothing to do
-		return result, nil
+		// Nothing to do, other than removing nodes
+		return a.checkNodeRemoval(ctx, hwmgr, nodepool)
 	}
 
 	return result, nil
@@ -126,7 +164,7 @@ func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alp
 
 func (a *Adaptor) GetResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourcePoolInfo, int, error) {
 	var resp []invserver.ResourcePoolInfo
-	_, resources, _, err := a.GetCurrentResources(ctx)
+	_, resources, _, err := a.GetCurrentResources(ctx, hwmgr)
 	if err != nil {
 		return resp, http.StatusServiceUnavailable, fmt.Errorf("unable to get current resources: %w", err)
 	}
@@ -160,14 +198,27 @@ func convertProcessorInfo(infos []processorInfo) []invserver.ProcessorInfo {
 func (a *Adaptor) GetResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, int, error) {
 	var resp []invserver.ResourceInfo
 
-	_, resources, _, err := a.GetCurrentResources(ctx)
+	status, err := a.StreamResources(ctx, hwmgr, func(resource invserver.ResourceInfo) error {
+		resp = append(resp, resource)
+		return nil
+	})
 	if err != nil {
-		return resp, http.StatusServiceUnavailable, fmt.Errorf("unable to get current resources: %w", err)
+		return nil, status, err
+	}
+
+	return resp, http.StatusOK, nil
+}
+
+// StreamResources passes each resource of the HardwareManager to emit, stopping at the first error returned by emit
+func (a *Adaptor) StreamResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, emit func(invserver.ResourceInfo) error) (int, error) {
+	_, resources, _, err := a.GetCurrentResources(ctx, hwmgr)
+	if err != nil {
+		return http.StatusServiceUnavailable, fmt.Errorf("unable to get current resources: %w", err)
 	}
 
 	for name, server := range resources.Nodes {
 		powerState := invserver.ResourceInfoPowerState("ON")
-		resp = append(resp, invserver.ResourceInfo{
+		if err := emit(invserver.ResourceInfo{
 			AdminState:       invserver.ResourceInfoAdminState(server.AdminState),
 			Description:      server.Description,
 			GlobalAssetId:    &server.GlobalAssetID,
@@ -187,7 +238,9 @@ func (a *Adaptor) GetResources(ctx context.Context, hwmgr *pluginv1alpha1.Hardwa
 			Tags:             nil,
 			UsageState:       invserver.ResourceInfoUsageState(server.UsageState),
 			Vendor:           server.Vendor,
-		})
+		}); err != nil {
+			return http.StatusInternalServerError, err
+		}
 	}
-	return resp, http.StatusOK, nil
+	return http.StatusOK, nil
 }
@@ -13,7 +13,9 @@ import (
 
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 )
 
@@ -63,6 +65,8 @@ type cmAllocatedNode struct {
 type cmAllocatedCloud struct {
 	CloudID    string                       `json:"cloudID" yaml:"cloudID"`
 	Nodegroups map[string][]cmAllocatedNode `json:"nodegroups" yaml:"nodegroups"`
+	// Reserved holds the IDs of the nodes reserved for each nodegroup, which are allocated ahead of any free node
+	Reserved map[string][]string `json:"reserved,omitempty" yaml:"reserved,omitempty"`
 }
 
 type cmAllocations struct {
@@ -84,6 +88,11 @@ func getFreeNodesInPool(resources cmResources, allocations cmAllocations, poolID
 				inuse[node.NodeId] = true
 			}
 		}
+		for groupname := range cloud.Reserved {
+			for _, nodeId := range cloud.Reserved[groupname] {
+				inuse[nodeId] = true
+			}
+		}
 	}
 
 	for nodeId, node := range resources.Nodes {
@@ -99,19 +108,44 @@ func getFreeNodesInPool(resources cmResources, allocations cmAllocations, poolID
 	return
 }
 
-// GetCurrentResources parses the nodelist configmap to get the current available and allocated resource lists
-func (a *Adaptor) GetCurrentResources(ctx context.Context) (
+// getAllocatedCloud returns the allocations for the given cloud, or nil if nothing has been allocated for it
+func getAllocatedCloud(allocations *cmAllocations, cloudID string) *cmAllocatedCloud {
+	for i := range allocations.Clouds {
+		if allocations.Clouds[i].CloudID == cloudID {
+			return &allocations.Clouds[i]
+		}
+	}
+	return nil
+}
+
+// GetCurrentResources parses the nodelist configmap to get the current available and allocated resource lists,
+// including the synthetic inventory, if configured for the HardwareManager
+func (a *Adaptor) GetCurrentResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (
 	cm *corev1.ConfigMap, resources cmResources, allocations cmAllocations, err error) {
-	cm, err = utils.GetConfigmap(ctx, a.Client, cmName, a.Namespace)
-	if err != nil {
-		err = fmt.Errorf("unable to get configmap: %w", err)
-		return
+	scale := getScale(hwmgr)
+	if scale != nil {
+		// The configmap is still used to track allocations, but need not be created by the user
+		if cm, err = a.ensureConfigmap(ctx); err != nil {
+			return
+		}
+	} else {
+		cm, err = utils.GetConfigmap(ctx, a.Client, cmName, a.Namespace)
+		if err != nil {
+			err = fmt.Errorf("unable to get configmap: %w", err)
+			return
+		}
 	}
 
-	resources, err = utils.ExtractDataFromConfigMap[cmResources](cm, resourcesKey)
-	if err != nil {
-		err = fmt.Errorf("unable to parse resources from configmap: %w", err)
-		return
+	if _, exists := cm.Data[resourcesKey]; exists || scale == nil {
+		resources, err = utils.ExtractDataFromConfigMap[cmResources](cm, resourcesKey)
+		if err != nil {
+			err = fmt.Errorf("unable to parse resources from configmap: %w", err)
+			return
+		}
+	}
+
+	if scale != nil {
+		generateScaleResources(scale, &resources)
 	}
 
 	allocations, err = utils.ExtractDataFromConfigMap[cmAllocations](cm, allocationsKey)
@@ -121,14 +155,44 @@ func (a *Adaptor) GetCurrentResources(ctx context.Context) (
 		err = nil
 	}
 
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+
 	return
 }
 
+// ensureConfigmap gets the nodelist configmap, creating an empty one if it does not exist
+func (a *Adaptor) ensureConfigmap(ctx context.Context) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	exists, err := utils.DoesK8SResourceExist(ctx, a.Client, cmName, a.Namespace, cm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get configmap: %w", err)
+	}
+	if exists {
+		return cm, nil
+	}
+
+	cm = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: a.Namespace,
+		},
+		Data: make(map[string]string),
+	}
+	if err := a.Client.Create(ctx, cm); err != nil {
+		return nil, fmt.Errorf("failed to create configmap: %w", err)
+	}
+	return cm, nil
+}
+
 // GetAllocatedNodes gets a list of nodes allocated for the specified NodePool CR
-func (a *Adaptor) GetAllocatedNodes(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (allocatedNodes []string, err error) {
+func (a *Adaptor) GetAllocatedNodes(ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (allocatedNodes []string, err error) {
 	cloudID := nodepool.Spec.CloudID
 
-	_, _, allocations, err := a.GetCurrentResources(ctx)
+	_, _, allocations, err := a.GetCurrentResources(ctx, hwmgr)
 	if err != nil {
 		err = fmt.Errorf("unable to get current resources: %w", err)
 		return
@@ -44,6 +44,7 @@ type HardwareManagerReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
 	result = utils.DoNotRequeue()
 
 	// Fetch the CR:
@@ -69,10 +70,21 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return
 	}
 
-	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+	ctx = logging.WithHwMgr(ctx, hwmgr.Name)
 
 	hwmgr.Status.ObservedGeneration = hwmgr.Generation
 
+	if _, backendErr := utils.NewBmcSecretBackend(r.Client, hwmgr); backendErr != nil {
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			backendErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
+		}
+		return
+	}
+
 	// Configuration data is not currently mandatory for the loopback adaptor
 	if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
 		pluginv1alpha1.ConditionTypes.Validation,
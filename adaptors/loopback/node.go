@@ -13,6 +13,7 @@ import (
 	"log/slog"
 	"time"
 
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -23,13 +24,13 @@ import (
 )
 
 // AllocateNode processes a NodePool CR, allocating a free node for each specified nodegroup as needed
-func (a *Adaptor) AllocateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+func (a *Adaptor) AllocateNode(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
 	cloudID := nodepool.Spec.CloudID
 
 	// Inject a delay before allocating node
 	time.Sleep(10 * time.Second)
 
-	cm, resources, allocations, err := a.GetCurrentResources(ctx)
+	cm, resources, allocations, err := a.GetCurrentResources(ctx, hwmgr)
 	if err != nil {
 		return fmt.Errorf("unable to get current resources: %w", err)
 	}
@@ -57,22 +58,34 @@ func (a *Adaptor) AllocateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.Nod
 			continue
 		}
 
+		reserved := cloud.Reserved[nodegroup.NodePoolData.Name]
 		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
-		if remaining > len(freenodes) {
+		if remaining > len(freenodes)+len(reserved) {
 			return fmt.Errorf("not enough free resources remaining in resource pool %s", nodegroup.NodePoolData.ResourcePoolId)
 		}
 
 		nodename := utils.GenerateNodeName()
 
-		// Grab the first node
-		nodeId := freenodes[0]
+		// Grab the first reserved node, or else the first free node
+		var nodeId string
+		if len(reserved) > 0 {
+			nodeId = reserved[0]
+			cloud.Reserved[nodegroup.NodePoolData.Name] = reserved[1:]
+		} else {
+			nodeId = freenodes[0]
+		}
 
 		nodeinfo, exists := resources.Nodes[nodeId]
 		if !exists {
 			return fmt.Errorf("unable to find nodeinfo for %s", nodeId)
 		}
 
-		if err := a.CreateBMCSecret(ctx, nodepool, nodename, nodeinfo.BMC.UsernameBase64, nodeinfo.BMC.PasswordBase64); err != nil {
+		bmcSecret, err := utils.GetBmcSecretLocation(nodepool, nodename)
+		if err != nil {
+			return fmt.Errorf("failed to get bmc-secret name for node %s: %w", nodename, err)
+		}
+
+		if err := a.CreateBMCSecret(ctx, hwmgr, nodepool, nodename, nodeId, bmcSecret, nodeinfo.BMC.UsernameBase64, nodeinfo.BMC.PasswordBase64); err != nil {
 			return fmt.Errorf("failed to create bmc-secret when allocating node %s, nodeId %s: %w", nodename, nodeId, err)
 		}
 
@@ -88,11 +101,12 @@ func (a *Adaptor) AllocateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.Nod
 			return fmt.Errorf("failed to update configmap: %w", err)
 		}
 
-		if err := a.CreateNode(ctx, nodepool, cloudID, nodename, nodeId, nodegroup.NodePoolData.Name, nodegroup.NodePoolData.HwProfile); err != nil {
+		if err := a.CreateNode(ctx, nodepool, cloudID, nodename, nodeId, nodegroup.NodePoolData.Name, nodegroup.NodePoolData.HwProfile,
+			nodeinfo.Vendor, bmcSecret); err != nil {
 			return fmt.Errorf("failed to create allocated node (%s): %w", nodename, err)
 		}
 
-		if err := a.UpdateNodeStatus(ctx, nodename, nodeinfo, nodegroup.NodePoolData.HwProfile); err != nil {
+		if err := a.UpdateNodeStatus(ctx, nodename, bmcSecret.Name, nodeinfo, nodegroup.NodePoolData.HwProfile); err != nil {
 			return fmt.Errorf("failed to update node status (%s): %w", nodename, err)
 		}
 	}
@@ -100,46 +114,34 @@ func (a *Adaptor) AllocateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.Nod
 	return nil
 }
 
-func bmcSecretName(nodename string) string {
-	return fmt.Sprintf("%s-bmc-secret", nodename)
-}
-
-// CreateBMCSecret creates the bmc-secret for a node
-func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, nodename, usernameBase64, passwordBase64 string) error {
-	a.Logger.InfoContext(ctx, "Creating bmc-secret:", slog.String("nodename", nodename))
-
-	secretName := bmcSecretName(nodename)
-
-	username, err := base64.StdEncoding.DecodeString(usernameBase64)
-	if err != nil {
-		return fmt.Errorf("failed to decode usernameBase64 string (%s) for node %s: %w", usernameBase64, nodename, err)
-	}
+// CreateBMCSecret creates the bmc-secret for a node through the BMC secret backend of the HardwareManager. The
+// credentials are held in the loopback resources, referenced by node ID.
+func (a *Adaptor) CreateBMCSecret(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool,
+	nodename, nodeId string, location types.NamespacedName, usernameBase64, passwordBase64 string) error {
+	a.Logger.InfoContext(ctx, "Creating bmc-secret:", slog.String("nodename", nodename), slog.String("secret", location.String()))
 
-	password, err := base64.StdEncoding.DecodeString(passwordBase64)
+	backend, err := utils.NewBmcSecretBackend(a.Client, hwmgr)
 	if err != nil {
-		return fmt.Errorf("failed to decode usernameBase64 string (%s) for node %s: %w", passwordBase64, nodename, err)
+		return fmt.Errorf("invalid BMC secret backend: %w", err)
 	}
 
-	blockDeletion := true
-	bmcSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: a.Namespace,
-			OwnerReferences: []metav1.OwnerReference{{
-				APIVersion:         nodepool.APIVersion,
-				Kind:               nodepool.Kind,
-				Name:               nodepool.Name,
-				UID:                nodepool.UID,
-				BlockOwnerDeletion: &blockDeletion,
-			}},
-		},
-		Data: map[string][]byte{
-			"username": username,
-			"password": password,
+	source := utils.BmcCredentialsSource{
+		Reference: nodeId,
+		Fetch: func(context.Context) ([]byte, []byte, error) {
+			username, err := base64.StdEncoding.DecodeString(usernameBase64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode usernameBase64 string (%s) for node %s: %w", usernameBase64, nodename, err)
+			}
+
+			password, err := base64.StdEncoding.DecodeString(passwordBase64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode usernameBase64 string (%s) for node %s: %w", passwordBase64, nodename, err)
+			}
+			return username, password, nil
 		},
 	}
 
-	if err = utils.CreateOrUpdateK8sCR(ctx, a.Client, bmcSecret, nil, utils.UPDATE); err != nil {
+	if err := backend.CreateBmcSecret(ctx, nodepool, nodename, location, source); err != nil {
 		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
 	}
 
@@ -147,7 +149,8 @@ func (a *Adaptor) CreateBMCSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.
 }
 
 // CreateNode creates a Node CR with specified attributes
-func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, cloudID, nodename, nodeId, groupname, hwprofile string) error {
+func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, cloudID, nodename, nodeId, groupname, hwprofile,
+	vendor string, bmcSecret types.NamespacedName) error {
 	a.Logger.InfoContext(ctx, "Creating node",
 		slog.String("nodegroup name", groupname),
 		slog.String("nodename", nodename),
@@ -156,8 +159,9 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 	blockDeletion := true
 	node := &hwmgmtv1alpha1.Node{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      nodename,
-			Namespace: a.Namespace,
+			Name:        nodename,
+			Namespace:   a.Namespace,
+			Annotations: utils.GetBmcSecretNodeAnnotations(bmcSecret, a.Namespace),
 			OwnerReferences: []metav1.OwnerReference{{
 				APIVersion:         nodepool.APIVersion,
 				Kind:               nodepool.Kind,
@@ -174,6 +178,13 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 			HwMgrNodeId: nodeId,
 		},
 	}
+	utils.SetNodeVendor(node, vendor)
+	utils.AddNodeEvent(node, utils.NodeEventAllocated,
+		fmt.Sprintf("Node %s allocated to NodePool %s", nodeId, nodepool.Name))
+
+	if err := utils.ApplyHardwareManagerNodeMetadata(ctx, a.Client, a.Namespace, node); err != nil {
+		return err // nolint: wrapcheck
+	}
 
 	if err := a.Client.Create(ctx, node); err != nil {
 		return fmt.Errorf("failed to create Node: %w", err)
@@ -183,7 +194,7 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 }
 
 // UpdateNodeStatus updates a Node CR status field with additional node information from the nodelist configmap
-func (a *Adaptor) UpdateNodeStatus(ctx context.Context, nodename string, info cmNodeInfo, hwprofile string) error {
+func (a *Adaptor) UpdateNodeStatus(ctx context.Context, nodename, bmcSecretName string, info cmNodeInfo, hwprofile string) error {
 	a.Logger.InfoContext(ctx, "Updating node", slog.String("nodename", nodename))
 
 	node := &hwmgmtv1alpha1.Node{}
@@ -198,12 +209,12 @@ func (a *Adaptor) UpdateNodeStatus(ctx context.Context, nodename string, info cm
 		slog.String("nodename", nodename),
 		slog.Any("info", info))
 	node.Status.BMC = &hwmgmtv1alpha1.BMC{
-		Address:         info.BMC.Address,
-		CredentialsName: bmcSecretName(nodename),
+		Address:         utils.NormalizeBMCAddress(info.BMC.Address),
+		CredentialsName: bmcSecretName,
 	}
 	node.Status.Interfaces = info.Interfaces
 
-	utils.SetStatusCondition(&node.Status.Conditions,
+	utils.SetStatusCondition(ctx, &node.Status.Conditions,
 		string(hwmgmtv1alpha1.Provisioned),
 		string(hwmgmtv1alpha1.Completed),
 		metav1.ConditionTrue,
@@ -213,5 +224,61 @@ func (a *Adaptor) UpdateNodeStatus(ctx context.Context, nodename string, info cm
 		return fmt.Errorf("failed to update status for node %s: %w", nodename, err)
 	}
 
+	if err := utils.RecordNodeEvent(ctx, a.Client, nodename, a.Namespace, utils.NodeEventProvisioned,
+		"Hardware profile "+hwprofile); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", nodename), slog.String("error", err.Error()))
+	}
+
 	return nil
 }
+
+// RotateBMCCredentials sets new credentials in the bmc-secret for a node. The loopback adaptor has no BMC to update,
+// so the rotation completes immediately.
+func (a *Adaptor) RotateBMCCredentials(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	credentials *corev1.Secret) (bool, error) {
+
+	username, password, err := utils.GetBmcCredentials(credentials)
+	if err != nil {
+		return false, fmt.Errorf("invalid BMC credentials secret %s: %w", credentials.Name, err)
+	}
+
+	backend, err := utils.NewBmcSecretBackend(a.Client, hwmgr)
+	if err != nil {
+		return false, fmt.Errorf("invalid BMC secret backend: %w", err)
+	}
+
+	a.Logger.InfoContext(ctx, "Rotating BMC credentials", slog.String("nodename", node.Name))
+	if err := backend.UpdateBmcSecretCredentials(ctx, utils.GetNodeBmcSecret(node), username, password); err != nil {
+		return false, fmt.Errorf("failed to update bmc-secret for node %s: %w", node.Name, err)
+	}
+
+	return true, nil
+}
+
+// HandleNodePowerAction applies a power action to a node. The loopback adaptor has no hardware to act on, so the
+// action completes immediately.
+func (a *Adaptor) HandleNodePowerAction(
+	ctx context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	action string) (bool, error) {
+
+	a.Logger.InfoContext(ctx, "Applying power action", slog.String("nodename", node.Name), slog.String("action", action))
+	return true, nil
+}
+
+// HandleNodeTags applies tags to the hardware backing a node. The loopback adaptor has no hardware inventory to tag,
+// so the tags are applied immediately.
+func (a *Adaptor) HandleNodeTags(
+	ctx context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	add, remove []string) (bool, error) {
+
+	a.Logger.InfoContext(ctx, "Applying tags", slog.String("nodename", node.Name),
+		slog.Any("add", add), slog.Any("remove", remove))
+	return true, nil
+}
@@ -0,0 +1,106 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// checkNodeRemoval removes the nodes of a provisioned NodePool named in its remove-nodes annotation or whose Node CR
+// has been deleted, freeing them in the allocations configmap
+func (a *Adaptor) checkNodeRemoval(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	removals, err := utils.GetNodesToRemove(ctx, a.NoncachedClient, nodepool, nodelist)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	if len(removals) > 0 {
+		nodenames := make([]string, 0, len(removals))
+		for _, removal := range removals {
+			nodenames = append(nodenames, removal.NodeName)
+		}
+		if err := a.ReleaseNodes(ctx, hwmgr, nodepool, nodenames); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+
+		for _, removal := range removals {
+			if err := utils.CompleteNodeRemoval(ctx, a.Client, nodepool, removal); err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+			a.Logger.InfoContext(ctx, "Node removed from NodePool", slog.String("node", removal.NodeName))
+		}
+	}
+
+	if err := utils.ClearNodeRemovalRequest(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	return utils.DoNotRequeue(), nil
+}
+
+// ReleaseNodes frees the named nodes of a NodePool in the allocations configmap
+func (a *Adaptor) ReleaseNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool,
+	nodenames []string) error {
+
+	cm, _, allocations, err := a.GetCurrentResources(ctx, hwmgr)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	cloud := getAllocatedCloud(&allocations, nodepool.Spec.CloudID)
+	if cloud == nil {
+		return nil
+	}
+
+	updated := false
+	for groupname, nodes := range cloud.Nodegroups {
+		remaining := slices.DeleteFunc(slices.Clone(nodes), func(node cmAllocatedNode) bool {
+			return slices.Contains(nodenames, node.NodeName)
+		})
+		if len(remaining) != len(nodes) {
+			a.Logger.InfoContext(ctx, "Releasing nodes",
+				slog.String("nodegroup", groupname),
+				slog.Int("count", len(nodes)-len(remaining)))
+			cloud.Nodegroups[groupname] = remaining
+			updated = true
+		}
+	}
+
+	if !updated {
+		return nil
+	}
+
+	// Update the configmap
+	yamlString, err := yaml.Marshal(&allocations)
+	if err != nil {
+		return fmt.Errorf("unable to marshal allocated data: %w", err)
+	}
+	cm.Data[allocationsKey] = string(yamlString)
+	if err := a.Client.Update(ctx, cm); err != nil {
+		return fmt.Errorf("failed to update configmap: %w", err)
+	}
+
+	return nil
+}
@@ -44,7 +44,7 @@ func (a *Adaptor) CheckNodePoolProgress(
 			slog.String("nodegroup name", nodegroup.NodePoolData.Name),
 		)
 
-		if err = a.AllocateNode(ctx, nodepool); err != nil {
+		if err = a.AllocateNode(ctx, hwmgr, nodepool); err != nil {
 			err = fmt.Errorf("failed to allocate node: %w", err)
 			return
 		}
@@ -58,6 +58,13 @@ func (a *Adaptor) HandleNodePoolCreate(
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
+	if utils.IsNodePoolDryRun(nodepool) {
+		return a.HandleNodePoolDryRun(ctx, hwmgr, nodepool)
+	}
+	if utils.IsNodePoolReservation(nodepool) {
+		return a.HandleNodePoolReservation(ctx, hwmgr, nodepool)
+	}
+
 	conditionType := hwmgmtv1alpha1.Provisioned
 	var conditionReason hwmgmtv1alpha1.ConditionReason
 	var conditionStatus metav1.ConditionStatus
@@ -87,6 +94,28 @@ func (a *Adaptor) HandleNodePoolCreate(
 	return utils.DoNotRequeue(), nil
 }
 
+// HandleNodePoolDryRun checks whether there are enough free nodes for a new NodePool and reports the outcome,
+// without allocating any nodes
+func (a *Adaptor) HandleNodePoolDryRun(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Handling dry run of NodePool request")
+
+	checkErr := utils.ValidateNodePoolHwProfiles(ctx, a.Client, a.Namespace, nodepool)
+	if checkErr == nil {
+		checkErr = a.ProcessNewNodePool(ctx, hwmgr, nodepool)
+	}
+
+	if err := utils.UpdateNodePoolDryRunCondition(ctx, a.Client, nodepool, checkErr, ""); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
 func (a *Adaptor) HandleNodePoolProcessing(
 	ctx context.Context,
 	hwmgr *pluginv1alpha1.HardwareManager,
@@ -97,7 +126,7 @@ func (a *Adaptor) HandleNodePoolProcessing(
 		return ctrl.Result{}, fmt.Errorf("failed CheckNodePoolProgress: %w", err)
 	}
 
-	allocatedNodes, err := a.GetAllocatedNodes(ctx, nodepool)
+	allocatedNodes, err := a.GetAllocatedNodes(ctx, hwmgr, nodepool)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to get allocated nodes for %s: %w", nodepool.Name, err)
 	}
@@ -113,6 +142,10 @@ func (a *Adaptor) HandleNodePoolProcessing(
 	if full {
 		a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
 
+		if provisioned, result, err := utils.CheckPostAllocationHook(ctx, a.Client, a.NoncachedClient, a.Namespace, nodepool); !provisioned {
+			return result, err
+		}
+
 		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Completed, metav1.ConditionTrue, "Created"); err != nil {
 			return utils.RequeueWithMediumInterval(),
@@ -159,6 +192,7 @@ func (a *Adaptor) checkNodeUpgradeProcess(
 
 func (a *Adaptor) handleNodePoolConfiguring(
 	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
 	var nodesToCheck []*hwmgmtv1alpha1.Node // To track nodes that we actually attempted to upgrade
@@ -166,7 +200,7 @@ func (a *Adaptor) handleNodePoolConfiguring(
 
 	a.Logger.InfoContext(ctx, "Handling Node Pool Configuring")
 
-	allocatedNodes, err := a.GetAllocatedNodes(ctx, nodepool)
+	allocatedNodes, err := a.GetAllocatedNodes(ctx, hwmgr, nodepool)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to get allocated nodes for %s: %w", nodepool.Name, err)
 	}
@@ -179,12 +213,13 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		}
 		// Check each node against each nodegroup in the node pool spec
 		for _, nodegroup := range nodepool.Spec.NodeGroup {
-			if node.Spec.GroupName != nodegroup.NodePoolData.Name || node.Spec.HwProfile == nodegroup.NodePoolData.HwProfile {
+			hwProfile := utils.GetNodeTargetHwProfile(node, nodegroup.NodePoolData.HwProfile)
+			if node.Spec.GroupName != nodegroup.NodePoolData.Name || node.Spec.HwProfile == hwProfile {
 				continue
 			}
 			// Node needs an upgrade, so update Spec.HwProfile
 			patch := client.MergeFrom(node.DeepCopy())
-			node.Spec.HwProfile = nodegroup.NodePoolData.HwProfile
+			node.Spec.HwProfile = hwProfile
 			if err = a.Client.Patch(ctx, node, patch); err != nil {
 				return utils.RequeueWithShortInterval(), fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
 			}
@@ -239,7 +274,7 @@ func (a *Adaptor) HandleNodePoolSpecChanged(
 			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
 
-	return a.handleNodePoolConfiguring(ctx, nodepool)
+	return a.handleNodePoolConfiguring(ctx, hwmgr, nodepool)
 }
 
 // ProcessNewNodePool processes a new NodePool CR, verifying that there are enough free resources to satisfy the request
@@ -253,13 +288,20 @@ func (a *Adaptor) ProcessNewNodePool(ctx context.Context,
 		slog.String("cloudID", cloudID),
 	)
 
-	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	_, resources, allocations, err := a.GetCurrentResources(ctx, hwmgr)
 	if err != nil {
 		return fmt.Errorf("unable to get current resources: %w", err)
 	}
 
+	// Nodes reserved for the NodePool are held for it
+	var reserved map[string][]string
+	if cloud := getAllocatedCloud(&allocations, cloudID); cloud != nil {
+		reserved = cloud.Reserved
+	}
+
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
 		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
+		freenodes = append(freenodes, reserved[nodegroup.NodePoolData.Name]...)
 		if nodegroup.Size > len(freenodes) {
 			return fmt.Errorf("not enough free resources in resource pool %s: freenodes=%d", nodegroup.NodePoolData.ResourcePoolId, len(freenodes))
 		}
@@ -275,7 +317,7 @@ func (a *Adaptor) IsNodePoolFullyAllocated(ctx context.Context,
 
 	cloudID := nodepool.Spec.CloudID
 
-	_, resources, allocations, err := a.GetCurrentResources(ctx)
+	_, resources, allocations, err := a.GetCurrentResources(ctx, hwmgr)
 	if err != nil {
 		return false, fmt.Errorf("unable to get current resources: %w", err)
 	}
@@ -303,7 +345,7 @@ func (a *Adaptor) IsNodePoolFullyAllocated(ctx context.Context,
 		}
 
 		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
-		if remaining > len(freenodes) {
+		if remaining > len(freenodes)+len(cloud.Reserved[nodegroup.NodePoolData.Name]) {
 			return false, fmt.Errorf("not enough free resources remaining in resource pool %s", nodegroup.NodePoolData.ResourcePoolId)
 		}
 
@@ -325,7 +367,7 @@ func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 		slog.String("cloudID", cloudID),
 	)
 
-	cm, _, allocations, err := a.GetCurrentResources(ctx)
+	cm, _, allocations, err := a.GetCurrentResources(ctx, hwmgr)
 	if err != nil {
 		return fmt.Errorf("unable to get current resources: %w", err)
 	}
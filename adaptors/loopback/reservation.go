@@ -0,0 +1,94 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loopback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// HandleNodePoolReservation reserves the nodes for a NodePool annotated for reservation, without creating its Node
+// CRs. The reserved nodes are allocated to the NodePool once it is activated.
+func (a *Adaptor) HandleNodePoolReservation(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Handling reservation of NodePool hardware")
+
+	reserveErr := a.ReserveNodes(ctx, hwmgr, nodepool)
+	if err := utils.UpdateNodePoolReservedCondition(ctx, a.Client, nodepool, reserveErr, reserveErr == nil, ""); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// ReserveNodes records free nodes in the allocations configmap as reserved for each nodegroup of the NodePool, up to
+// the size of the nodegroup
+func (a *Adaptor) ReserveNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
+	cloudID := nodepool.Spec.CloudID
+
+	cm, resources, allocations, err := a.GetCurrentResources(ctx, hwmgr)
+	if err != nil {
+		return fmt.Errorf("unable to get current resources: %w", err)
+	}
+
+	cloud := getAllocatedCloud(&allocations, cloudID)
+	if cloud == nil {
+		allocations.Clouds = append(allocations.Clouds, cmAllocatedCloud{CloudID: cloudID, Nodegroups: make(map[string][]cmAllocatedNode)})
+		cloud = &allocations.Clouds[len(allocations.Clouds)-1]
+	}
+	if cloud.Reserved == nil {
+		cloud.Reserved = make(map[string][]string)
+	}
+
+	updated := false
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupname := nodegroup.NodePoolData.Name
+		remaining := nodegroup.Size - len(cloud.Nodegroups[groupname]) - len(cloud.Reserved[groupname])
+		if remaining <= 0 {
+			continue
+		}
+
+		freenodes := getFreeNodesInPool(resources, allocations, nodegroup.NodePoolData.ResourcePoolId)
+		if remaining > len(freenodes) {
+			return fmt.Errorf("not enough free resources in resource pool %s: freenodes=%d", nodegroup.NodePoolData.ResourcePoolId, len(freenodes))
+		}
+
+		a.Logger.InfoContext(ctx, "Reserving nodes",
+			slog.String("nodegroup", groupname),
+			slog.Any("nodeIds", freenodes[:remaining]))
+		cloud.Reserved[groupname] = append(cloud.Reserved[groupname], freenodes[:remaining]...)
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+
+	// Update the configmap
+	yamlString, err := yaml.Marshal(&allocations)
+	if err != nil {
+		return fmt.Errorf("unable to marshal allocated data: %w", err)
+	}
+	cm.Data[allocationsKey] = string(yamlString)
+	if err := a.Client.Update(ctx, cm); err != nil {
+		return fmt.Errorf("failed to update configmap: %w", err)
+	}
+
+	return nil
+}
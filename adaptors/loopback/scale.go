@@ -0,0 +1,122 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package loopback
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Defaults for generated nodes, matching the nodes created by examples/nodelist-generator.sh
+const (
+	defaultScalePoolPrefix = "scale-pool"
+	defaultScaleVendor     = "Red Hat"
+	defaultScaleModel      = "Loopback"
+	defaultScaleMemory     = 32768
+)
+
+var defaultScaleProcessors = []processorInfo{
+	{
+		Architecture: "x86-64",
+		Cores:        32,
+		Manufacturer: "Intel",
+		Model:        "Intel(R) Xeon(R) Gold 6230R CPU @ 2.10GHz",
+	},
+}
+
+// getScale returns the synthetic inventory configured for the HardwareManager, if any
+func getScale(hwmgr *pluginv1alpha1.HardwareManager) *pluginv1alpha1.LoopbackScale {
+	if hwmgr == nil || hwmgr.Spec.LoopbackData == nil {
+		return nil
+	}
+	return hwmgr.Spec.LoopbackData.Scale
+}
+
+// generateScaleResources adds the resource pools and nodes of a synthetic inventory to the resources parsed from the
+// configmap. The inventory is generated deterministically, so a generated node keeps its ID and properties across
+// calls and its allocations can be tracked in the configmap like any other node.
+func generateScaleResources(scale *pluginv1alpha1.LoopbackScale, resources *cmResources) {
+	prefix := scale.PoolPrefix
+	if prefix == "" {
+		prefix = defaultScalePoolPrefix
+	}
+
+	tmpl := scale.Template
+	vendor, model, memory := tmpl.Vendor, tmpl.Model, tmpl.Memory
+	if vendor == "" {
+		vendor = defaultScaleVendor
+	}
+	if model == "" {
+		model = defaultScaleModel
+	}
+	if memory == 0 {
+		memory = defaultScaleMemory
+	}
+	processors := defaultScaleProcessors
+	if len(tmpl.Processors) > 0 {
+		processors = make([]processorInfo, len(tmpl.Processors))
+		for i, p := range tmpl.Processors {
+			processors[i] = processorInfo{
+				Architecture: p.Architecture,
+				Cores:        p.Cores,
+				Manufacturer: p.Manufacturer,
+				Model:        p.Model,
+			}
+		}
+	}
+
+	username := base64.StdEncoding.EncodeToString([]byte("admin"))
+	password := base64.StdEncoding.EncodeToString([]byte("mypass"))
+
+	if resources.Nodes == nil {
+		resources.Nodes = make(map[string]cmNodeInfo, scale.NumPools*scale.NodesPerPool)
+	}
+
+	for p := 0; p < scale.NumPools; p++ {
+		poolID := fmt.Sprintf("%s-%d", prefix, p)
+		resources.ResourcePools = append(resources.ResourcePools, poolID)
+
+		for n := 0; n < scale.NodesPerPool; n++ {
+			// The global index of the node provides a unique BMC address and MAC address for each node
+			index := p*scale.NodesPerPool + n
+			octets := fmt.Sprintf("%d.%d.%d", (index>>16)&0xff, (index>>8)&0xff, index&0xff)
+
+			resources.Nodes[fmt.Sprintf("%s-node-%d", poolID, n)] = cmNodeInfo{
+				ResourcePoolID: poolID,
+				BMC: &cmBmcInfo{
+					Address:        fmt.Sprintf("idrac-virtualmedia+https://10.%s/redfish/v1/Systems/System.Embedded.1", octets),
+					UsernameBase64: username,
+					PasswordBase64: password,
+				},
+				Interfaces: []*hwmgmtv1alpha1.Interface{
+					{
+						Name:       "eth0",
+						Label:      "bootable-interface",
+						MACAddress: fmt.Sprintf("c6:b6:14:%02x:%02x:%02x", (index>>16)&0xff, (index>>8)&0xff, index&0xff),
+					},
+				},
+				Description:      "Red Hat Loopback Scale Node",
+				GlobalAssetID:    fmt.Sprintf("GASC%08d", index),
+				Vendor:           vendor,
+				Model:            model,
+				Memory:           memory,
+				AdminState:       "UNLOCKED",
+				OperationalState: "ENABLED",
+				UsageState:       "IDLE",
+				PowerState:       "ON",
+				SerialNumber:     fmt.Sprintf("SNSC%08d", index),
+				PartNumber:       fmt.Sprintf("PNSC%08d", index),
+				Labels:           maps.Clone(tmpl.Labels),
+				Processors:       processors,
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// nodePoolMetadataMigrations and nodeMetadataMigrations convert the NodePool and Node labels and annotations set by
+// earlier plugin versions, by schema version
+var (
+	nodePoolMetadataMigrations []utils.MetadataMigration
+	nodeMetadataMigrations     []utils.MetadataMigration
+)
+
+// MigrateMetadata brings the labels and annotations of the NodePools and Nodes served by the plugin, and those of the
+// objects managed by each adaptor, to the current metadata schema, recording the outcome in the metadata migration
+// config map. It is run when the plugin starts, before the controllers, so that they only see the current schema.
+// Objects that fail to migrate are logged and migrated on the next start.
+func (c *HwMgrAdaptorController) MigrateMetadata(ctx context.Context, cl client.Client) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	c.Logger.InfoContext(ctx, "Migrating metadata", slog.Int("schemaVersion", utils.MetadataSchemaVersion))
+
+	status := utils.MetadataMigrationStatus{SchemaVersion: utils.MetadataSchemaVersion}
+	record := func(result utils.MetadataMigrationResult, err error) {
+		status.Results = append(status.Results, result)
+		if err != nil {
+			c.Logger.WarnContext(ctx, "Metadata migration failed", slog.String("kind", result.Kind), slog.String("error", err.Error()))
+			status.Errors = append(status.Errors, err.Error())
+		}
+	}
+
+	// NodePools are only migrated if served by a HardwareManager of the plugin
+	hwmgrs := &pluginv1alpha1.HardwareManagerList{}
+	if err := cl.List(ctx, hwmgrs, client.InNamespace(c.Namespace)); err != nil {
+		record(utils.MetadataMigrationResult{Kind: "NodePool"}, err)
+	} else {
+		served := make(map[string]bool)
+		for _, hwmgr := range hwmgrs.Items {
+			served[hwmgr.Name] = true
+		}
+		record(utils.MigrateObjectsMetadata(ctx, cl, c.Logger, "NodePool", &hwmgmtv1alpha1.NodePoolList{},
+			func(object client.Object) bool {
+				return served[object.(*hwmgmtv1alpha1.NodePool).Spec.HwMgrId]
+			}, nodePoolMetadataMigrations))
+	}
+
+	record(utils.MigrateObjectsMetadata(ctx, cl, c.Logger, "Node", &hwmgmtv1alpha1.NodeList{}, nil,
+		nodeMetadataMigrations, client.InNamespace(c.Namespace)))
+
+	ids := make([]string, 0, len(c.adaptors))
+	for id := range c.adaptors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if migrator, ok := c.adaptors[id].(adaptorinterface.MetadataMigrationAdaptorIntf); ok {
+			record(migrator.MigrateMetadata(ctx, cl))
+		}
+	}
+
+	status.CompletedAt = metav1.Now()
+	if err := utils.RecordMetadataMigrationStatus(ctx, cl, c.Namespace, status); err != nil {
+		c.Logger.WarnContext(ctx, "Failed to record metadata migration status", slog.String("error", err.Error()))
+	}
+
+	c.Logger.InfoContext(ctx, "Metadata migration complete", slog.Any("results", status.Results))
+}
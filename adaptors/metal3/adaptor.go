@@ -11,16 +11,20 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync"
 
-	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/metal3/controller"
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -32,22 +36,60 @@ type Adaptor struct {
 	Logger          *slog.Logger
 	Namespace       string
 	AdaptorID       pluginv1alpha1.HardwareManagerAdaptorID
+
+	// driftChecks records the time of the last drift check of each NodePool
+	driftChecks sync.Map
+	// firmwareChecks records the time of the last successful preflight check of each firmware image
+	firmwareChecks sync.Map
+	// recorder emits events for conditions that need operator attention, once the adaptor is set up
+	recorder record.EventRecorder
+	// bmhReader reads BMHs from the manager cache through its BMH field indexes, once they are set up
+	bmhReader client.Reader
+
+	// allocator and adopter allocate BMHs to NodePools that provision them and that adopt them, updater applies
+	// hardware profiles to BMHs, and finalizer releases them. See bmh_lifecycle.go.
+	allocator bmhAllocator
+	adopter   bmhAllocator
+	updater   bmhUpdater
+	finalizer bmhFinalizer
 }
 
 func NewAdaptor(client client.Client, noncachedClient client.Reader, scheme *runtime.Scheme, logger *slog.Logger, namespace string) *Adaptor {
-	return &Adaptor{
+	a := &Adaptor{
 		Client:          client,
 		NoncachedClient: noncachedClient,
 		Scheme:          scheme,
 		Logger:          logger.With(slog.String("adaptor", "metal3")),
 		Namespace:       namespace,
 	}
+	a.allocator = provisioningAllocator{a}
+	a.adopter = adoptionAllocator{a}
+	a.updater = profileUpdater{a}
+	a.finalizer = bmhReleaser{a}
+	return a
+}
+
+func init() {
+	adaptorinterface.RegisterAdaptor(string(pluginv1alpha1.SupportedAdaptors.Metal3),
+		func(client client.Client, noncachedClient client.Reader, scheme *runtime.Scheme, logger *slog.Logger, namespace string) adaptorinterface.HwMgrAdaptorIntf {
+			return NewAdaptor(client, noncachedClient, scheme, logger, namespace)
+		})
 }
 
 // SetupAdaptor sets up the metal3 adaptor
 func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
 	a.Logger.Info("SetupAdaptor called for metal3")
 
+	a.recorder = mgr.GetEventRecorderFor("metal3-adaptor")
+
+	if err := a.SetupInventory(mgr); err != nil {
+		return err
+	}
+
+	if err := a.setupBMHMetrics(mgr); err != nil {
+		a.Logger.Warn("BMH state transitions are not counted", slog.String("error", err.Error()))
+	}
+
 	if err := (&controller.HardwareManagerReconciler{
 		Client:    a.Client,
 		Scheme:    a.Scheme,
@@ -60,6 +102,15 @@ func (a *Adaptor) SetupAdaptor(mgr ctrl.Manager) error {
 	return nil
 }
 
+// SetupInventory sets up the BMH indexes the inventory is served from. Without them, the inventory is built by listing
+// every BMH.
+func (a *Adaptor) SetupInventory(mgr ctrl.Manager) error {
+	if err := a.setupBMHIndexes(mgr); err != nil {
+		a.Logger.Warn("BMHs are read without cache indexes", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
 // Metal3 Adaptor FSM
 type fsmAction int
 
@@ -70,41 +121,71 @@ const (
 	NodePoolFSMNoop
 )
 
-func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
-	if len(nodepool.Status.Conditions) == 0 {
-		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
-		return NodePoolFSMCreate
+func (action fsmAction) String() string {
+	switch action {
+	case NodePoolFSMCreate:
+		return "create"
+	case NodePoolFSMProcessing:
+		return "processing"
+	case NodePoolFSMSpecChanged:
+		return "specChanged"
+	case NodePoolFSMNoop:
+		return "noop"
 	}
+	return "unknown"
+}
 
+func (a *Adaptor) determineAction(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) fsmAction {
 	provisionedCondition := meta.FindStatusCondition(
 		nodepool.Status.Conditions,
 		string(hwmgmtv1alpha1.Provisioned))
-	if provisionedCondition != nil {
-		if provisionedCondition.Status == metav1.ConditionTrue {
-			// Check if the generation has changed
-			if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
-				a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
-				return NodePoolFSMSpecChanged
-			}
-			a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
-			return NodePoolFSMNoop
-		}
+	if provisionedCondition == nil {
+		// Nothing has been provisioned yet, which includes NodePools that have only been dry run
+		a.Logger.InfoContext(ctx, "Handling Create NodePool request")
+		return NodePoolFSMCreate
+	}
 
-		if provisionedCondition.Reason == string(hwmgmtv1alpha1.Failed) {
-			a.Logger.InfoContext(ctx, "NodePool request in Failed state")
-			return NodePoolFSMNoop
+	if provisionedCondition.Status == metav1.ConditionTrue {
+		// Check if the generation has changed
+		if nodepool.ObjectMeta.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration {
+			a.Logger.InfoContext(ctx, "Handling NodePool Spec change")
+			return NodePoolFSMSpecChanged
+		}
+		// A configuration update can also be started without a spec change, to remediate drift or apply a node
+		// hardware profile override
+		if utils.IsNodePoolConfigUpdateRequested(nodepool) {
+			a.Logger.InfoContext(ctx, "Handling NodePool configuration update")
+			return NodePoolFSMSpecChanged
 		}
+		a.Logger.InfoContext(ctx, "NodePool request in Provisioned state")
+		return NodePoolFSMNoop
+	}
+
+	if utils.IsConditionFailed(provisionedCondition) {
+		a.Logger.InfoContext(ctx, "NodePool request in Failed state")
+		return NodePoolFSMNoop
+	}
 
-		return NodePoolFSMProcessing
+	if provisionedCondition.Reason == string(hwmgmtv1alpha1.InvalidInput) {
+		a.Logger.InfoContext(ctx, "NodePool request has invalid input")
+		return NodePoolFSMNoop
 	}
 
-	return NodePoolFSMNoop
+	return NodePoolFSMProcessing
 }
 
 func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 	result := utils.DoNotRequeue()
 
-	switch a.determineAction(ctx, nodepool) {
+	action := a.determineAction(ctx, nodepool)
+	tracing.SetAttributes(ctx, tracing.AttrNodePoolPhase.String(action.String()))
+	if action != NodePoolFSMCreate {
+		if err := a.syncNodeCordons(ctx, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	switch action {
 	case NodePoolFSMCreate:
 		return a.HandleNodePoolCreate(ctx, hwmgr, nodepool)
 	case NodePoolFSMProcessing:
@@ -112,8 +193,24 @@ func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.Hard
 	case NodePoolFSMSpecChanged:
 		return a.HandleNodePoolSpecChanged(ctx, hwmgr, nodepool)
 	case NodePoolFSMNoop:
-		// Nothing to do
-		return result, nil
+		// Nothing to do, other than removing, reinspecting and replacing nodes and checking provisioned nodes for drift
+		// when enabled
+		if result, handled, err := a.checkNodeRemoval(ctx, hwmgr, nodepool); handled || err != nil {
+			return result, err
+		}
+		inspectionResult, err := a.checkNodeReinspection(ctx, nodepool)
+		if err != nil {
+			return inspectionResult, err
+		}
+		if result, handled, err := a.checkNodeReplacement(ctx, hwmgr, nodepool); handled || err != nil {
+			return result, err
+		}
+		result, err := a.checkNodePoolDrift(ctx, hwmgr, nodepool)
+		if err == nil && result.IsZero() && (utils.IsAutoReplaceFailedNodesEnabled(nodepool) || !inspectionResult.IsZero()) {
+			// BMH status changes do not trigger a reconcile, so poll for failed nodes and inspections
+			result = utils.RequeueWithMediumInterval()
+		}
+		return result, err
 	}
 
 	return result, nil
@@ -121,6 +218,8 @@ func (a *Adaptor) HandleNodePool(ctx context.Context, hwmgr *pluginv1alpha1.Hard
 
 func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
 	a.Logger.InfoContext(ctx, "Finalizing nodepool")
+	a.driftChecks.Delete(client.ObjectKeyFromObject(nodepool).String())
+	clearNodePoolResourceMetrics(nodepool)
 
 	if err := a.ReleaseNodePool(ctx, hwmgr, nodepool); err != nil {
 		return false, fmt.Errorf("failed to release nodepool %s: %w", nodepool.Name, err)
@@ -129,51 +228,44 @@ func (a *Adaptor) HandleNodePoolDeletion(ctx context.Context, hwmgr *pluginv1alp
 	return true, nil
 }
 
-func (a *Adaptor) GetResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourcePoolInfo, int, error) {
-	var resp []invserver.ResourcePoolInfo
-
-	var bmhList metal3v1alpha1.BareMetalHostList
-	var opts []client.ListOption
-
-	if err := a.Client.List(ctx, &bmhList, opts...); err != nil {
-		return resp, http.StatusInternalServerError, fmt.Errorf("failed to get bmh list: %w", err)
-	}
-
-	pools := make(map[string]string)
-
-	for _, bmh := range bmhList.Items {
-		if includeInInventory(bmh) {
-			pools[bmh.Labels[LabelSiteID]] = bmh.Labels[LabelResourcePoolID]
-		}
-	}
+func (a *Adaptor) GetResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, int, error) {
+	var resp []invserver.ResourceInfo
 
-	for siteId, poolID := range pools {
-		resp = append(resp, invserver.ResourcePoolInfo{
-			ResourcePoolId: poolID,
-			Description:    poolID,
-			Name:           poolID,
-			SiteId:         &siteId,
-		})
+	status, err := a.StreamResources(ctx, hwmgr, func(resource invserver.ResourceInfo) error {
+		resp = append(resp, resource)
+		return nil
+	})
+	if err != nil {
+		return nil, status, err
 	}
 
 	return resp, http.StatusOK, nil
 }
 
-func (a *Adaptor) GetResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourceInfo, int, error) {
-	var resp []invserver.ResourceInfo
-
-	var bmhList metal3v1alpha1.BareMetalHostList
-	var opts []client.ListOption
+// StreamResources passes each resource of the HardwareManager to emit as it is built from its BMH, stopping at the
+// first error returned by emit
+func (a *Adaptor) StreamResources(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, emit func(invserver.ResourceInfo) error) (int, error) {
+	bmhList, err := a.listInventoryBMHs(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
 
-	if err := a.Client.List(ctx, &bmhList, opts...); err != nil {
-		return resp, http.StatusInternalServerError, fmt.Errorf("failed to get bmh list: %w", err)
+	allocations, err := utils.GetNodeAllocations(ctx, a.Client, a.Namespace, hwmgr.Name)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to get resource allocations: %w", err)
 	}
 
 	for _, bmh := range bmhList.Items {
-		if includeInInventory(bmh) {
-			resp = append(resp, getResourceInfo(bmh))
+		if isBMHNamespaceAllowed(hwmgr, bmh.Namespace) {
+			var allocation *utils.ResourceAllocation
+			if entry, exists := allocations[types.NamespacedName{Namespace: bmh.Namespace, Name: bmh.Name}]; exists {
+				allocation = &entry
+			}
+			if err := emit(getResourceInfo(bmh, allocation)); err != nil {
+				return http.StatusInternalServerError, err
+			}
 		}
 	}
 
-	return resp, http.StatusOK, nil
+	return http.StatusOK, nil
 }
@@ -0,0 +1,135 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// AdoptNodesAnnotation requests that a NodePool is satisfied by adopting BMHs that are already provisioned and in
+	// use, rather than by provisioning available BMHs
+	AdoptNodesAnnotation = "hwmgr-plugin.oran.openshift.io/adopt-nodes"
+
+	// BmhAdoptedAnnotation marks a BMH that was adopted by a NodePool
+	BmhAdoptedAnnotation = "hwmgr-plugin.oran.openshift.io/adopted"
+)
+
+// isNodePoolAdoption checks whether the NodePool requests adoption of already provisioned BMHs
+func isNodePoolAdoption(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.Annotations[AdoptNodesAnnotation] == ValueTrue
+}
+
+// isBMHAdopted checks whether a BMH was adopted, rather than provisioned, by the plugin
+func isBMHAdopted(bmh *metal3v1alpha1.BareMetalHost) bool {
+	_, exists := bmh.Annotations[BmhAdoptedAnnotation]
+	return exists
+}
+
+// filterProvisionedBMHs filters out BareMetalHosts that are not provisioned, by metal3 or externally.
+func filterProvisionedBMHs(bmhList metal3v1alpha1.BareMetalHostList) metal3v1alpha1.BareMetalHostList {
+	var filteredBMHs metal3v1alpha1.BareMetalHostList
+	for _, bmh := range bmhList.Items {
+		switch bmh.Status.Provisioning.State {
+		case metal3v1alpha1.StateProvisioned, metal3v1alpha1.StateExternallyProvisioned:
+			filteredBMHs.Items = append(filteredBMHs.Items, bmh)
+		}
+	}
+	return filteredBMHs
+}
+
+// fetchAdoptableBMHs retrieves the unallocated, provisioned BareMetalHosts matching a node group
+func (a *Adaptor) fetchAdoptableBMHs(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	site string,
+	nodePoolData hwmgmtv1alpha1.NodePoolData,
+	namespace string) (metal3v1alpha1.BareMetalHostList, error) {
+
+	bmhList, err := a.listBMHs(ctx, hwmgr, site, nodePoolData, UnallocatedBMHs, namespace)
+	if err != nil {
+		return bmhList, err
+	}
+	return filterProvisionedBMHs(bmhList), nil
+}
+
+// fetchCandidateBMHs retrieves the BareMetalHosts that can be allocated to a node group: BMHs that are already
+// provisioned when the NodePool requests adoption, otherwise available BMHs.
+func (a *Adaptor) fetchCandidateBMHs(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodePoolData hwmgmtv1alpha1.NodePoolData,
+	namespace string) (metal3v1alpha1.BareMetalHostList, error) {
+
+	if isNodePoolAdoption(nodepool) {
+		return a.fetchAdoptableBMHs(ctx, hwmgr, nodepool.Spec.Site, nodePoolData, namespace)
+	}
+	return a.FetchBMHList(ctx, hwmgr, nodepool.Spec.Site, nodePoolData, UnallocatedBMHs, namespace)
+}
+
+// adoptBMHToNodePool assigns an already provisioned BareMetalHost to a NodePool. Unlike allocateBMHToNodePool, the
+// BMH is left as it is: the hardware profile and network data are not applied, as that would disrupt the host.
+func (a *Adaptor) adoptBMHToNodePool(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) error {
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	claim, err := a.claimBMH(ctx, bmh, nodepool, group.NodePoolData.Name)
+	if err != nil {
+		return fmt.Errorf("failed to claim BMH (%s): %w", bmh.Name, err)
+	}
+	nodeName := claim.NodeName
+
+	if err := a.CreateNode(ctx, nodepool, nodepool.Spec.CloudID, nodeName, bmh.Name, bmh.Namespace,
+		group.NodePoolData.Name, group.NodePoolData.HwProfile, getResourceInfoVendor(*bmh)); err != nil {
+		return fmt.Errorf("failed to create adopted node (%s): %w", nodeName, err)
+	}
+
+	if err := a.applyBMHMetadata(ctx, nodepool, bmh, nodeName, group.NodePoolData.Name); err != nil {
+		return fmt.Errorf("failed to add metadata to adopted BMH (%s): %w", bmh.Name, err)
+	}
+
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(BmhAdoptedAnnotation, nodepool.Name)); err != nil {
+		return fmt.Errorf("failed to add adopted annotation to BMH (%s): %w", bmh.Name, err)
+	}
+
+	if err := a.markBMHAllocated(ctx, bmh); err != nil {
+		return fmt.Errorf("failed to add allocated label to BMH (%s): %w", bmh.Name, err)
+	}
+
+	nodeInfo := bmhNodeInfo{
+		ResourcePoolID: group.NodePoolData.ResourcePoolId,
+		BMC: &bmhBmcInfo{
+			Address:         bmh.Spec.BMC.Address,
+			CredentialsName: bmh.Spec.BMC.CredentialsName,
+		},
+		Interfaces: a.buildInterfacesFromBMH(nodepool, *bmh),
+	}
+	if err := a.UpdateNodeStatus(ctx, nodeInfo, nodeName, group.NodePoolData.HwProfile, false); err != nil {
+		return fmt.Errorf("failed to update node status (%s): %w", nodeName, err)
+	}
+
+	if !contains(nodepool.Status.Properties.NodeNames, nodeName) {
+		nodepool.Status.Properties.NodeNames = append(nodepool.Status.Properties.NodeNames, nodeName)
+	}
+
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(NodeNameAnnotation)); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to clear node name annotation from BMH", slog.Any("bmh", bmhName), slog.String("error", err.Error()))
+	}
+
+	a.Logger.InfoContext(ctx, "Adopted provisioned BMH",
+		slog.Any("bmh", bmhName),
+		slog.String("nodename", nodeName),
+		slog.String("state", string(bmh.Status.Provisioning.State)))
+	return nil
+}
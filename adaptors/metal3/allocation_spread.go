@@ -0,0 +1,172 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+const (
+	// AllocationSpreadExtension is the NodePool extension setting the spread policy of all node groups. The policy of a
+	// single node group is set with the extension suffixed with "." and the group name, which takes precedence.
+	AllocationSpreadExtension = "allocationSpread"
+
+	// BareMetalHost labels identifying the failure domains that allocations are spread across
+	LabelRack = LabelPrefixResources + "rack"
+	LabelZone = "topology.kubernetes.io/zone"
+)
+
+// SpreadPolicy selects the failure domain that the nodes of a group are spread across
+type SpreadPolicy string
+
+const (
+	SpreadNone SpreadPolicy = "none"
+	SpreadRack SpreadPolicy = "rack"
+	SpreadZone SpreadPolicy = "zone"
+)
+
+// labelKey returns the BareMetalHost label identifying the failure domain of the policy
+func (p SpreadPolicy) labelKey() string {
+	switch p {
+	case SpreadRack:
+		return LabelRack
+	case SpreadZone:
+		return LabelZone
+	default:
+		return ""
+	}
+}
+
+// getSpreadPolicy returns the spread policy of a node group from the NodePool extensions
+func getSpreadPolicy(nodepool *hwmgmtv1alpha1.NodePool, groupName string) (SpreadPolicy, error) {
+	key := AllocationSpreadExtension + "." + groupName
+	value, exists := nodepool.Spec.Extensions[key]
+	if !exists {
+		key = AllocationSpreadExtension
+		value, exists = nodepool.Spec.Extensions[key]
+	}
+	if !exists || value == "" {
+		return SpreadNone, nil
+	}
+
+	switch policy := SpreadPolicy(value); policy {
+	case SpreadNone, SpreadRack, SpreadZone:
+		return policy, nil
+	default:
+		return SpreadNone, typederrors.NewInputError("invalid %s extension %q: must be one of %s, %s, %s",
+			key, value, SpreadNone, SpreadRack, SpreadZone)
+	}
+}
+
+// countGroupDomains counts the nodes already allocated to a node group in each failure domain
+func (a *Adaptor) countGroupDomains(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, groupName, labelKey string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, nodeName := range nodepool.Status.Properties.NodeNames {
+		node, err := utils.GetNode(ctx, a.Logger, a.NoncachedClient, a.Namespace, nodeName)
+		if err != nil || node == nil {
+			// A node that can't be read doesn't change the selection much, so don't block allocation on it
+			continue
+		}
+		if node.Spec.GroupName != groupName || node.Spec.HwMgrNodeId == "" {
+			continue
+		}
+
+		bmh := &metal3v1alpha1.BareMetalHost{}
+		bmhName := types.NamespacedName{Name: node.Spec.HwMgrNodeId, Namespace: node.Spec.HwMgrNodeNs}
+		if err := a.Client.Get(ctx, bmhName, bmh); err != nil {
+			return nil, fmt.Errorf("failed to get BMH %s for node %s: %w", bmhName, nodeName, err)
+		}
+		if domain := bmh.Labels[labelKey]; domain != "" {
+			counts[domain]++
+		}
+	}
+	return counts, nil
+}
+
+// spreadBMHs orders candidate BareMetalHosts so that taking them in order spreads the nodes of a group across failure
+// domains. Each host is taken from the domain with the fewest nodes of the group so far, counting those already
+// allocated. Hosts without the domain label are placed last, as their domain is unknown.
+func spreadBMHs(bmhs []metal3v1alpha1.BareMetalHost, labelKey string, allocated map[string]int) []metal3v1alpha1.BareMetalHost {
+	byDomain := make(map[string][]metal3v1alpha1.BareMetalHost)
+	var unlabeled []metal3v1alpha1.BareMetalHost
+	for _, bmh := range bmhs {
+		if domain := bmh.Labels[labelKey]; domain != "" {
+			byDomain[domain] = append(byDomain[domain], bmh)
+		} else {
+			unlabeled = append(unlabeled, bmh)
+		}
+	}
+
+	domains := make([]string, 0, len(byDomain))
+	for domain := range byDomain {
+		domains = append(domains, domain)
+		sort.Slice(byDomain[domain], func(i, j int) bool { return byDomain[domain][i].Name < byDomain[domain][j].Name })
+	}
+
+	counts := make(map[string]int, len(domains))
+	for _, domain := range domains {
+		counts[domain] = allocated[domain]
+	}
+
+	ordered := make([]metal3v1alpha1.BareMetalHost, 0, len(bmhs))
+	for len(domains) > 0 {
+		// Take the next host from the least used domain, breaking ties by name for a stable order
+		sort.Slice(domains, func(i, j int) bool {
+			if counts[domains[i]] != counts[domains[j]] {
+				return counts[domains[i]] < counts[domains[j]]
+			}
+			return domains[i] < domains[j]
+		})
+
+		domain := domains[0]
+		ordered = append(ordered, byDomain[domain][0])
+		byDomain[domain] = byDomain[domain][1:]
+		counts[domain]++
+		if len(byDomain[domain]) == 0 {
+			domains = domains[1:]
+		}
+	}
+
+	return append(ordered, unlabeled...)
+}
+
+// orderBMHsForGroup orders the candidate BareMetalHosts of a node group according to its spread policy
+func (a *Adaptor) orderBMHsForGroup(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup,
+	bmhs []metal3v1alpha1.BareMetalHost) ([]metal3v1alpha1.BareMetalHost, error) {
+
+	policy, err := getSpreadPolicy(nodepool, group.NodePoolData.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	labelKey := policy.labelKey()
+	if labelKey == "" {
+		return bmhs, nil
+	}
+
+	allocated, err := a.countGroupDomains(ctx, nodepool, group.NodePoolData.Name, labelKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := spreadBMHs(bmhs, labelKey, allocated)
+	a.Logger.InfoContext(ctx, "Spreading node group allocation",
+		slog.String("nodegroup", group.NodePoolData.Name),
+		slog.String("policy", string(policy)),
+		slog.Any("allocated", allocated))
+	return ordered, nil
+}
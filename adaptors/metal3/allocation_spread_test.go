@@ -0,0 +1,192 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+// newSpreadTestBMH returns a BMH in the given rack and zone, if any
+func newSpreadTestBMH(name, rack, zone string) *metal3v1alpha1.BareMetalHost {
+	labels := map[string]string{LabelSiteID: "site1"}
+	if rack != "" {
+		labels[LabelRack] = rack
+	}
+	if zone != "" {
+		labels[LabelZone] = zone
+	}
+	return &metal3v1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testBMHNamespace, Labels: labels},
+	}
+}
+
+// newSpreadTestNode returns a node of the group, allocated from the given BMH
+func newSpreadTestNode(name, groupName, bmhName string) *hwmgmtv1alpha1.Node {
+	return &hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "oran-hwmgr-plugin"},
+		Spec: hwmgmtv1alpha1.NodeSpec{
+			NodePool:    "np1",
+			GroupName:   groupName,
+			HwMgrId:     "hwmgr1",
+			HwMgrNodeId: bmhName,
+			HwMgrNodeNs: testBMHNamespace,
+		},
+	}
+}
+
+func TestOrderBMHsForGroup(t *testing.T) {
+	// The candidates of the worker group, in the order they were listed
+	candidates := []*metal3v1alpha1.BareMetalHost{
+		newSpreadTestBMH("bmh-e", "", ""),
+		newSpreadTestBMH("bmh-d", "r3", "z1"),
+		newSpreadTestBMH("bmh-c", "r2", "z1"),
+		newSpreadTestBMH("bmh-b", "r1", "z2"),
+		newSpreadTestBMH("bmh-a", "r1", "z1"),
+	}
+
+	// BMHs already allocated to nodes of the NodePool
+	allocated := []client.Object{
+		newSpreadTestBMH("bmh-x", "r1", "z1"),
+		newSpreadTestBMH("bmh-y", "r2", "z1"),
+		newSpreadTestBMH("bmh-z", "r1", "z1"),
+	}
+	workerInR1 := newSpreadTestNode("node-x", "worker", "bmh-x")
+	masterInR2 := newSpreadTestNode("node-y", "master", "bmh-y")
+	workerInR1Again := newSpreadTestNode("node-z", "worker", "bmh-z")
+	workerUnallocated := newSpreadTestNode("node-w", "worker", "")
+	workerMissingBMH := newSpreadTestNode("node-v", "worker", "bmh-missing")
+
+	tests := []struct {
+		description string
+		extensions  map[string]string
+		nodes       []*hwmgmtv1alpha1.Node
+		// nodeNames are the nodes listed in the NodePool status, defaulting to the names of the nodes
+		nodeNames  []string
+		expected   []string
+		inputError bool
+		failed     bool
+	}{
+		{
+			description: "no policy keeps the listed order",
+			expected:    []string{"bmh-e", "bmh-d", "bmh-c", "bmh-b", "bmh-a"},
+		},
+		{
+			description: "none policy keeps the listed order",
+			extensions:  map[string]string{AllocationSpreadExtension: "none"},
+			expected:    []string{"bmh-e", "bmh-d", "bmh-c", "bmh-b", "bmh-a"},
+		},
+		{
+			description: "rack spread takes one host from each rack in turn, unlabeled last",
+			extensions:  map[string]string{AllocationSpreadExtension: "rack"},
+			expected:    []string{"bmh-a", "bmh-c", "bmh-d", "bmh-b", "bmh-e"},
+		},
+		{
+			description: "zone spread takes one host from each zone in turn, unlabeled last",
+			extensions:  map[string]string{AllocationSpreadExtension: "zone"},
+			expected:    []string{"bmh-a", "bmh-b", "bmh-c", "bmh-d", "bmh-e"},
+		},
+		{
+			description: "rack spread counts the nodes already allocated to the group",
+			extensions:  map[string]string{AllocationSpreadExtension: "rack"},
+			nodes:       []*hwmgmtv1alpha1.Node{workerInR1, masterInR2, workerUnallocated},
+			expected:    []string{"bmh-c", "bmh-d", "bmh-a", "bmh-b", "bmh-e"},
+		},
+		{
+			description: "zone spread counts the nodes already allocated to the group",
+			extensions:  map[string]string{AllocationSpreadExtension: "zone"},
+			nodes:       []*hwmgmtv1alpha1.Node{workerInR1, workerInR1Again},
+			expected:    []string{"bmh-b", "bmh-a", "bmh-c", "bmh-d", "bmh-e"},
+		},
+		{
+			description: "node that cannot be read is not counted",
+			extensions:  map[string]string{AllocationSpreadExtension: "rack"},
+			nodeNames:   []string{"node-missing"},
+			expected:    []string{"bmh-a", "bmh-c", "bmh-d", "bmh-b", "bmh-e"},
+		},
+		{
+			description: "group policy takes precedence over the NodePool policy",
+			extensions:  map[string]string{AllocationSpreadExtension: "rack", AllocationSpreadExtension + ".worker": "zone"},
+			expected:    []string{"bmh-a", "bmh-b", "bmh-c", "bmh-d", "bmh-e"},
+		},
+		{
+			description: "group policy can disable the NodePool policy",
+			extensions:  map[string]string{AllocationSpreadExtension: "rack", AllocationSpreadExtension + ".worker": "none"},
+			expected:    []string{"bmh-e", "bmh-d", "bmh-c", "bmh-b", "bmh-a"},
+		},
+		{
+			description: "policy of another group does not apply",
+			extensions:  map[string]string{AllocationSpreadExtension + ".master": "rack"},
+			expected:    []string{"bmh-e", "bmh-d", "bmh-c", "bmh-b", "bmh-a"},
+		},
+		{
+			description: "invalid policy",
+			extensions:  map[string]string{AllocationSpreadExtension: "row"},
+			inputError:  true,
+		},
+		{
+			description: "missing BMH of an allocated node",
+			extensions:  map[string]string{AllocationSpreadExtension: "rack"},
+			nodes:       []*hwmgmtv1alpha1.Node{workerMissingBMH},
+			failed:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			nodepool := newLifecycleTestNodePool()
+			nodepool.Spec.Extensions = test.extensions
+
+			objects := slices.Clone(allocated)
+			for _, node := range test.nodes {
+				objects = append(objects, node.DeepCopy())
+				nodepool.Status.Properties.NodeNames = append(nodepool.Status.Properties.NodeNames, node.Name)
+			}
+			if test.nodeNames != nil {
+				nodepool.Status.Properties.NodeNames = test.nodeNames
+			}
+			a := newTestAdaptor(t, objects...)
+
+			bmhs := make([]metal3v1alpha1.BareMetalHost, 0, len(candidates))
+			for _, bmh := range candidates {
+				bmhs = append(bmhs, *bmh.DeepCopy())
+			}
+
+			ordered, err := a.orderBMHsForGroup(context.Background(), nodepool, nodepool.Spec.NodeGroup[0], bmhs)
+			switch {
+			case test.inputError:
+				if !typederrors.IsInputError(err) {
+					t.Fatalf("expected an input error, got %v", err)
+				}
+				return
+			case test.failed:
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, bmh := range ordered {
+				names = append(names, bmh.Name)
+			}
+			if !slices.Equal(names, test.expected) {
+				t.Errorf("expected order %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func getArtifactCache(hwmgr *pluginv1alpha1.HardwareManager) *pluginv1alpha1.ArtifactCache {
+	if hwmgr == nil || hwmgr.Spec.Metal3Data == nil {
+		return nil
+	}
+	return hwmgr.Spec.Metal3Data.ArtifactCache
+}
+
+// getArtifactMirrors returns the URLs of the files cached for the hardware profiles on the artifact cache, by their
+// original URL
+func (a *Adaptor) getArtifactMirrors(ctx context.Context, cache *pluginv1alpha1.ArtifactCache) (map[string]string, error) {
+	profiles := &pluginv1alpha1.HardwareProfileList{}
+	if err := a.Client.List(ctx, profiles, client.InNamespace(a.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list HardwareProfiles: %w", err)
+	}
+
+	mirrors := make(map[string]string)
+	for _, profile := range profiles.Items {
+		for _, cached := range profile.Status.CachedArtifacts {
+			mirrors[cached.URL] = utils.GetArtifactCacheURL(cache.URL, cached.Path)
+		}
+	}
+	return mirrors, nil
+}
+
+// mirrorFirmwareSpec replaces the URLs of the firmware images and signatures of a resolved hardware profile with those
+// of their copies on the artifact cache, when enabled for the hardware manager. A retriable error is returned while
+// any of the files is not yet cached, so that the update waits for them.
+func (a *Adaptor) mirrorFirmwareSpec(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	spec pluginv1alpha1.HardwareProfileSpec) (pluginv1alpha1.HardwareProfileSpec, error) {
+
+	cache := getArtifactCache(hwmgr)
+	if cache == nil {
+		return spec, nil
+	}
+
+	mirrors, err := a.getArtifactMirrors(ctx, cache)
+	if err != nil {
+		return spec, err
+	}
+
+	mirror := func(rawURL string) (string, error) {
+		if !utils.IsCacheableArtifactURL(rawURL) {
+			return rawURL, nil
+		}
+		mirrored, exists := mirrors[rawURL]
+		if !exists {
+			return "", typederrors.NewRetriableError(nil, "waiting for %s to be cached", rawURL)
+		}
+		return mirrored, nil
+	}
+
+	for _, fw := range []*pluginv1alpha1.Firmware{&spec.BiosFirmware, &spec.BmcFirmware} {
+		if fw.URL == "" {
+			continue
+		}
+		if fw.URL, err = mirror(fw.URL); err != nil {
+			return spec, err
+		}
+		if fw.Signature != nil {
+			signature := *fw.Signature
+			if signature.URL, err = mirror(signature.URL); err != nil {
+				return spec, err
+			}
+			fw.Signature = &signature
+		}
+	}
+
+	return spec, nil
+}
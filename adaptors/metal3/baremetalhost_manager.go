@@ -8,9 +8,10 @@ package metal3
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
@@ -18,10 +19,8 @@ import (
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -48,10 +47,6 @@ const (
 	UpdateReasonBIOSSettings       = "bios-settings-update"
 	UpdateReasonFirmware           = "firmware-update"
 	ValueTrue                      = "true"
-	MetaTypeLabel                  = "label"
-	MetaTypeAnnotation             = "annotation"
-	OpAdd                          = "add"
-	OpRemove                       = "remove"
 	BmhServicingErr                = "BMH Servicing Error"
 )
 
@@ -67,84 +62,46 @@ type bmhNodeInfo struct {
 	Interfaces     []*hwmgmtv1alpha1.Interface `json:"interfaces,omitempty"`
 }
 
-func (a *Adaptor) updateBMHMetaWithRetry(
-	ctx context.Context,
-	name types.NamespacedName,
-	metaType string, // "label" or "annotation"
-	key, value, operation string,
-) error {
-	// nolint: wrapcheck
-	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
-		// Fetch the latest version of the BMH
-		var latestBMH metal3v1alpha1.BareMetalHost
-		if err := a.Client.Get(ctx, name, &latestBMH); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to fetch BMH for "+metaType+" update",
-				slog.Any("bmh", name),
-				slog.String("error", err.Error()))
-			return err
-		}
-
-		var targetMap map[string]string
-		switch metaType {
-		case MetaTypeLabel:
-			if latestBMH.Labels == nil && operation == OpAdd {
-				latestBMH.Labels = make(map[string]string)
-			}
-			targetMap = latestBMH.Labels
-		case MetaTypeAnnotation:
-			if latestBMH.Annotations == nil && operation == OpAdd {
-				latestBMH.Annotations = make(map[string]string)
-			}
-			targetMap = latestBMH.Annotations
-		default:
-			return fmt.Errorf("unsupported meta type: %s", metaType)
-		}
-
-		if operation == OpRemove {
-			if targetMap == nil {
-				a.Logger.InfoContext(ctx, fmt.Sprintf("BMH has no %ss, skipping remove operation", metaType),
-					slog.Any("bmh", name))
-				return nil
-			}
-			if _, exists := targetMap[key]; !exists {
-				a.Logger.InfoContext(ctx, fmt.Sprintf("%s not present, skipping remove operation", metaType),
-					slog.Any("bmh", name),
-					slog.String(metaType, key))
-				return nil
-			}
-		}
+// updateBMHMetaWithRetry changes the labels and annotations of the latest version of a BMH, retrying on conflict
+func (a *Adaptor) updateBMHMetaWithRetry(ctx context.Context, name types.NamespacedName, mutations ...utils.MetaMutation) error {
+	bmh := &metal3v1alpha1.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace}}
+	if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, bmh, mutations); err != nil {
+		a.Logger.ErrorContext(ctx, "Failed to update BMH metadata",
+			slog.String("bmh", name.Name),
+			slog.String("changes", fmt.Sprint(mutations)),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to update metadata on BMH %s: %w", name, err)
+	}
 
-		// Create a patch base
-		patch := client.MergeFrom(latestBMH.DeepCopy())
+	a.Logger.InfoContext(ctx, "Successfully updated BMH metadata",
+		slog.String("bmh", name.Name),
+		slog.String("changes", fmt.Sprint(mutations)))
+	return nil
+}
 
-		switch operation {
-		case OpAdd:
-			targetMap[key] = value
-		case OpRemove:
-			delete(targetMap, key)
-		default:
-			return fmt.Errorf("unsupported operation: %s", operation)
-		}
+// FetchBMHList retrieves BareMetalHosts filtered by site ID, allocation status, and optional namespace, limited to the
+// namespaces the HardwareManager is allowed to allocate from. BMHs excluded from allocation are filtered out.
+func (a *Adaptor) FetchBMHList(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	site string,
+	nodePoolData hwmgmtv1alpha1.NodePoolData,
+	allocationStatus BMHAllocationStatus,
+	namespace string) (metal3v1alpha1.BareMetalHostList, error) {
 
-		// Apply the patch
-		if err := a.Client.Patch(ctx, &latestBMH, patch); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to update BMH "+metaType,
-				slog.String("bmh", name.Name),
-				slog.String("operation", operation),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("failed to %s %s on BMH %s: %w", operation, metaType, name.Name, err)
-		}
+	bmhList, err := a.listBMHs(ctx, hwmgr, site, nodePoolData, allocationStatus, namespace)
+	if err != nil || len(bmhList.Items) == 0 {
+		return bmhList, err
+	}
 
-		a.Logger.InfoContext(ctx, "Successfully updated BMH "+metaType,
-			slog.String("bmh", name.Name),
-			slog.String("operation", operation))
-		return nil
-	})
+	// we only care about the ones in "available" state
+	return filterAvailableBMHs(bmhList), nil
 }
 
-// FetchBMHList retrieves BareMetalHosts filtered by site ID, allocation status, and optional namespace.
-func (a *Adaptor) FetchBMHList(
+// listBMHs retrieves the BareMetalHosts matching the filters of FetchBMHList, regardless of their provisioning state
+func (a *Adaptor) listBMHs(
 	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	site string,
 	nodePoolData hwmgmtv1alpha1.NodePoolData,
 	allocationStatus BMHAllocationStatus,
@@ -153,46 +110,73 @@ func (a *Adaptor) FetchBMHList(
 	var bmhList metal3v1alpha1.BareMetalHostList
 	opts := []client.ListOption{}
 	matchingLabels := make(client.MatchingLabels)
+	// The site, pool and allocation filters are looked up through the cache indexes, if set up
+	matchingFields := make(client.MatchingFields)
 
 	// Add site ID filter if provided
 	if site != "" {
-		matchingLabels[LabelSiteID] = site
+		if a.bmhReader != nil {
+			matchingFields[bmhSiteIDIndexKey] = site
+		} else {
+			matchingLabels[LabelSiteID] = site
+		}
 	}
 
 	// Add pool ID filter if provided
 	if nodePoolData.ResourcePoolId != "" {
-		matchingLabels[LabelResourcePoolID] = nodePoolData.ResourcePoolId
+		if a.bmhReader != nil {
+			matchingFields[bmhResourcePoolIDIndexKey] = nodePoolData.ResourcePoolId
+		} else {
+			matchingLabels[LabelResourcePoolID] = nodePoolData.ResourcePoolId
+		}
 	}
 
-	if nodePoolData.ResourceSelector != "" {
-		resourceSelectors := make(map[string]string)
-
-		if err := json.Unmarshal([]byte(nodePoolData.ResourceSelector), &resourceSelectors); err != nil {
-			return bmhList, fmt.Errorf("unable to parse resourceSelector: %s: %w", nodePoolData.ResourceSelector, err)
+	// Requirements on an exact label value are matched by the label selector, while quantity comparisons are checked
+	// against the labels of the listed BMHs
+	requirements, err := utils.ParseResourceSelector(nodePoolData.ResourceSelector)
+	if err != nil {
+		return bmhList, typederrors.NewInputError("unable to parse resourceSelector: %s: %w", nodePoolData.ResourceSelector, err)
+	}
+	var quantityRequirements []utils.ResourceSelectorRequirement
+	for _, requirement := range requirements {
+		fullLabelName := requirement.Key
+		if !REPatternResourceSelectorLabel.MatchString(fullLabelName) {
+			fullLabelName = LabelPrefixResourceSelector + requirement.Key
+		}
+		if _, exists := matchingLabels[fullLabelName]; exists || slices.ContainsFunc(quantityRequirements,
+			func(r utils.ResourceSelectorRequirement) bool { return r.Key == fullLabelName }) {
+			return bmhList, typederrors.NewInputError("resourceSelector %s selects label %s more than once",
+				nodePoolData.ResourceSelector, fullLabelName)
 		}
 
-		for key, value := range resourceSelectors {
-			fullLabelName := key
-			if !REPatternResourceSelectorLabel.MatchString(fullLabelName) {
-				fullLabelName = LabelPrefixResourceSelector + key
-			}
-
-			matchingLabels[fullLabelName] = value
+		if requirement.IsExactMatch() {
+			matchingLabels[fullLabelName] = requirement.Value
+		} else {
+			requirement.Key = fullLabelName
+			quantityRequirements = append(quantityRequirements, requirement)
 		}
 	}
 
 	// Add namespace filter if provided
 	if namespace != "" {
+		if !isBMHNamespaceAllowed(hwmgr, namespace) {
+			a.Logger.WarnContext(ctx, "BareMetalHost namespace is not allowed for HardwareManager",
+				slog.String("namespace", namespace))
+			return bmhList, nil
+		}
 		opts = append(opts, client.InNamespace(namespace))
 	}
 
 	// Apply allocation filtering based on enum value
-	switch allocationStatus {
-	case AllocatedBMHs:
+	switch {
+	case a.bmhReader != nil && allocationStatus != AllBMHs:
+		matchingFields[bmhAllocatedIndexKey] = strconv.FormatBool(allocationStatus == AllocatedBMHs)
+
+	case allocationStatus == AllocatedBMHs:
 		// Fetch only allocated BMHs
 		matchingLabels[BmhAllocatedLabel] = ValueTrue
 
-	case UnallocatedBMHs:
+	case allocationStatus == UnallocatedBMHs:
 		// Fetch only unallocated BMHs
 		selector := metav1.LabelSelector{
 			MatchExpressions: []metav1.LabelSelectorRequirement{
@@ -209,26 +193,68 @@ func (a *Adaptor) FetchBMHList(
 		}
 		opts = append(opts, client.MatchingLabelsSelector{Selector: labelSelector})
 
-	case AllBMHs:
+	default:
 		// fetch all BMHs
 	}
 
 	opts = append(opts, matchingLabels)
 
 	// Fetch BMHs based on filters
-	if err := a.Client.List(ctx, &bmhList, opts...); err != nil {
+	if len(matchingFields) > 0 {
+		var err error
+		if bmhList, err = a.listIndexedBMHs(ctx, matchingFields, opts...); err != nil {
+			return bmhList, err
+		}
+	} else if err := a.Client.List(ctx, &bmhList, opts...); err != nil {
 		return bmhList, fmt.Errorf("failed to get BMH list: %w", err)
 	}
 
-	if len(bmhList.Items) == 0 {
-		a.Logger.WarnContext(ctx, "No BareMetalHosts found",
-			slog.String(LabelSiteID, site),
-			slog.String("Allocation Status", string(allocationStatus)))
-		return bmhList, nil
+	bmhList = filterBMHsByNamespace(hwmgr, bmhList)
+	bmhList = filterBMHsByRequirements(bmhList, quantityRequirements)
+	if allocationStatus != AllocatedBMHs {
+		// Excluded BMHs are never allocation candidates. The allocated BMHs of a NodePool are still listed, as they
+		// remain part of it while excluded.
+		bmhList = filterExcludedBMHs(bmhList)
 	}
+	return bmhList, nil
+}
 
-	// we only care about the ones in "available" state
-	return filterAvailableBMHs(bmhList), nil
+// isBMHNamespaceAllowed checks whether the HardwareManager is allowed to allocate BareMetalHosts from the namespace
+func isBMHNamespaceAllowed(hwmgr *pluginv1alpha1.HardwareManager, namespace string) bool {
+	if hwmgr == nil || hwmgr.Spec.Metal3Data == nil || len(hwmgr.Spec.Metal3Data.AllowedNamespaces) == 0 {
+		return true
+	}
+	return contains(hwmgr.Spec.Metal3Data.AllowedNamespaces, namespace)
+}
+
+// filterBMHsByRequirements filters out BareMetalHosts whose labels do not meet the resourceSelector requirements that
+// cannot be expressed as a label selector
+func filterBMHsByRequirements(bmhList metal3v1alpha1.BareMetalHostList, requirements []utils.ResourceSelectorRequirement) metal3v1alpha1.BareMetalHostList {
+	if len(requirements) == 0 {
+		return bmhList
+	}
+
+	var filteredBMHs metal3v1alpha1.BareMetalHostList
+	for _, bmh := range bmhList.Items {
+		if !slices.ContainsFunc(requirements, func(r utils.ResourceSelectorRequirement) bool {
+			value, exists := bmh.Labels[r.Key]
+			return !exists || !r.Matches(value)
+		}) {
+			filteredBMHs.Items = append(filteredBMHs.Items, bmh)
+		}
+	}
+	return filteredBMHs
+}
+
+// filterBMHsByNamespace filters out BareMetalHosts in namespaces the HardwareManager is not allowed to allocate from.
+func filterBMHsByNamespace(hwmgr *pluginv1alpha1.HardwareManager, bmhList metal3v1alpha1.BareMetalHostList) metal3v1alpha1.BareMetalHostList {
+	var filteredBMHs metal3v1alpha1.BareMetalHostList
+	for _, bmh := range bmhList.Items {
+		if isBMHNamespaceAllowed(hwmgr, bmh.Namespace) {
+			filteredBMHs.Items = append(filteredBMHs.Items, bmh)
+		}
+	}
+	return filteredBMHs
 }
 
 // filterAvailableBMHs filters out BareMetalHosts that are not in the "Available" provisioning state.
@@ -311,108 +337,31 @@ func (a *Adaptor) isBMHAllocated(bmh *metal3v1alpha1.BareMetalHost) bool {
 	return false
 }
 
-func (a *Adaptor) clearBMHNetworkData(ctx context.Context, name types.NamespacedName) error {
-	// nolint:wrapcheck
-	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
-		updatedBmh := &metal3v1alpha1.BareMetalHost{}
-
-		if err := a.Get(ctx, name, updatedBmh); err != nil {
-			return fmt.Errorf("failed to fetch BMH %s/%s: %w", name.Namespace, name.Name, err)
-		}
-		if updatedBmh.Spec.PreprovisioningNetworkDataName != "" {
-			updatedBmh.Spec.PreprovisioningNetworkDataName = ""
-			return a.Client.Update(ctx, updatedBmh)
-		}
-		return nil
-	})
-}
-
 func (a *Adaptor) applyPreChangeAnnotation(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	// nolint: wrapcheck
-	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
-		var latestBMH metal3v1alpha1.BareMetalHost
-		if err := a.Client.Get(ctx, bmhName, &latestBMH); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to fetch BMH for pre-change annotation update",
-				slog.Any("BMH", bmhName),
-				slog.String("error", err.Error()))
-			return err
-		}
-
-		patchBase := latestBMH.DeepCopy()
-
-		if latestBMH.Annotations == nil {
-			latestBMH.Annotations = make(map[string]string)
-		}
-
-		// Remove the paused annotation
-		delete(latestBMH.Annotations, BmhPausedAnnotation)
-		// Set the Day2 config annotation to "in-progress".
-		latestBMH.Annotations[BmhDay2ConfigAnnotation] = "in-progress"
-
-		patch := client.MergeFrom(patchBase)
-
-		if err := a.Client.Patch(ctx, &latestBMH, patch); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to update BMH annotations in pre-change update",
-				slog.String("BMH", bmhName.Name),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("failed to patch annotations on BMH %+v: %w", bmhName, err)
-		}
-
-		a.Logger.InfoContext(ctx, "Successfully applied pre-change annotations to BMH",
-			slog.Any("BMH", bmhName))
-		return nil
-	})
+	// Remove the paused annotation, and set the Day2 config annotation to "in-progress"
+	return a.updateBMHMetaWithRetry(ctx, bmhName,
+		utils.RemoveAnnotation(BmhPausedAnnotation),
+		utils.AddAnnotation(BmhDay2ConfigAnnotation, "in-progress"))
 }
 
 func (a *Adaptor) removeDetachedAnnotation(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	// nolint: wrapcheck
-	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
-		var latestBMH metal3v1alpha1.BareMetalHost
-		if err := a.Client.Get(ctx, bmhName, &latestBMH); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to fetch BMH for deetached annotation removal",
-				slog.Any("BMH", bmhName),
-				slog.String("error", err.Error()))
-			return err
-		}
-
-		patchBase := latestBMH.DeepCopy()
-
-		if latestBMH.Annotations == nil {
-			latestBMH.Annotations = make(map[string]string)
-		}
-
-		// Remove the detached annotation.
-		delete(latestBMH.Annotations, BmhDetachedAnnotation)
-
-		patch := client.MergeFrom(patchBase)
-
-		if err := a.Client.Patch(ctx, &latestBMH, patch); err != nil {
-			a.Logger.ErrorContext(ctx, "Failed to remove BMH detached annotation",
-				slog.String("BMH", bmhName.Name),
-				slog.String("error", err.Error()))
-			return fmt.Errorf("failed to remove detached annotation on BMH %+v: %w", bmhName, err)
-		}
-
-		a.Logger.InfoContext(ctx, "Successfully applied pre-change annotations to BMH",
-			slog.Any("BMH", bmhName))
-		return nil
-	})
+	return a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(BmhDetachedAnnotation))
 }
 
 func (a *Adaptor) removePreChangeAnnotation(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	if err := a.updateBMHMetaWithRetry(ctx, bmhName, "annotation", BmhDay2ConfigAnnotation, "", OpRemove); err != nil {
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(BmhDay2ConfigAnnotation)); err != nil {
 		return fmt.Errorf("failed to remove %s BMH %+v: %w", BmhDetachedAnnotation, bmhName, err)
 	}
 	return nil
 }
 
-func (a *Adaptor) processHwProfileWithHandledError(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost,
-	nodeName, nodeNamepace, profileName string, postInstall bool) (bool, error) {
+func (a *Adaptor) processHwProfileWithHandledError(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	bmh *metal3v1alpha1.BareMetalHost, nodeName, nodeNamepace, profileName string, postInstall bool) (bool, error) {
 
-	updateRequired, err := a.processHwProfile(ctx, bmh, profileName, postInstall)
+	updateRequired, err := a.processHwProfile(ctx, hwmgr, bmh, profileName, postInstall)
 	contType := string(hwmgmtv1alpha1.Provisioned)
 	if postInstall {
 		contType = string(hwmgmtv1alpha1.Configured)
@@ -421,6 +370,9 @@ func (a *Adaptor) processHwProfileWithHandledError(ctx context.Context, bmh *met
 		reason := hwmgmtv1alpha1.Failed
 		if typederrors.IsInputError(err) {
 			reason = hwmgmtv1alpha1.InvalidInput
+		} else if typederrors.IsRetriableError(err) {
+			// Such as while the firmware images are being cached
+			reason = hwmgmtv1alpha1.InProgress
 		}
 		if err := utils.SetNodeConditionStatus(ctx, a.Client, nodeName, nodeNamepace,
 			contType, metav1.ConditionFalse, string(reason), err.Error()); err != nil {
@@ -438,30 +390,36 @@ func (a *Adaptor) processHwProfileWithHandledError(ctx context.Context, bmh *met
 	return updateRequired, nil
 }
 
-func (a *Adaptor) processHwProfile(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, profileName string, postInstall bool) (bool, error) {
+func (a *Adaptor) processHwProfile(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, bmh *metal3v1alpha1.BareMetalHost,
+	profileName string, postInstall bool) (bool, error) {
 
-	var err error
-	name := types.NamespacedName{
-		Name:      profileName,
-		Namespace: a.Namespace,
+	// Resolve the profile with its base profiles, so that the merged attributes and firmware are checked
+	hwProfile, err := utils.GetHardwareProfile(ctx, a.Client, a.Namespace, profileName)
+	if err != nil {
+		return false, err
 	}
 
-	hwProfile := &pluginv1alpha1.HardwareProfile{}
-	if err := a.Client.Get(ctx, name, hwProfile); err != nil {
-		return false, fmt.Errorf("unable to find HardwareProfile CR (%s): %w", profileName, err)
-	}
+	return a.applyHwProfileSpec(ctx, hwmgr, bmh, hwProfile.Spec, postInstall)
+}
+
+// applyHwProfileSpec sets the BIOS settings and firmware of a resolved hardware profile on the BMH, returning true if
+// the BMH has been annotated for an update. The firmware images are checked as configured by the hardware manager,
+// if provided.
+func (a *Adaptor) applyHwProfileSpec(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, bmh *metal3v1alpha1.BareMetalHost,
+	spec pluginv1alpha1.HardwareProfileSpec, postInstall bool) (bool, error) {
 
 	// Check if BIOS update is required
+	var err error
 	biosUpdateRequired := false
-	if hwProfile.Spec.Bios.Attributes != nil {
-		biosUpdateRequired, err = a.IsBiosUpdateRequired(ctx, bmh, hwProfile.Spec.Bios)
+	if spec.Bios.Attributes != nil {
+		biosUpdateRequired, err = a.IsBiosUpdateRequired(ctx, bmh, spec.Bios)
 		if err != nil {
 			return false, err
 		}
 	}
 
 	// Check if firmware update is required
-	firmwareUpdateRequired, err := a.IsFirmwareUpdateRequired(ctx, bmh, hwProfile.Spec)
+	firmwareUpdateRequired, err := a.IsFirmwareUpdateRequired(ctx, hwmgr, bmh, spec)
 	if err != nil {
 		return false, err
 	}
@@ -480,14 +438,14 @@ func (a *Adaptor) processHwProfile(ctx context.Context, bmh *metal3v1alpha1.Bare
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
 	// If bios update is required, annotate BMH
 	if biosUpdateRequired {
-		if err := a.updateBMHMetaWithRetry(ctx, bmhName, MetaTypeAnnotation, BiosUpdateNeededAnnotation, ValueTrue, OpAdd); err != nil {
+		if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(BiosUpdateNeededAnnotation, ValueTrue)); err != nil {
 			return true, fmt.Errorf("failed to annotate BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
 		}
 	}
 
 	// if firmware update is required, annotate BMH
 	if firmwareUpdateRequired {
-		if err := a.updateBMHMetaWithRetry(ctx, bmhName, MetaTypeAnnotation, FirmwareUpdateNeededAnnotation, ValueTrue, OpAdd); err != nil {
+		if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(FirmwareUpdateNeededAnnotation, ValueTrue)); err != nil {
 			return true, fmt.Errorf("failed to annotate BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
 		}
 	}
@@ -518,6 +476,8 @@ func (a *Adaptor) annotateNodeConfigInProgress(ctx context.Context, nodeName, re
 	}
 
 	utils.SetConfigAnnotation(node, reason)
+	started, _ := nodeUpdateEvents(reason)
+	utils.AddNodeEvent(node, started, "Hardware profile "+node.Spec.HwProfile)
 
 	// Update the Node object
 	if err := a.Client.Update(ctx, node); err != nil {
@@ -528,7 +488,8 @@ func (a *Adaptor) annotateNodeConfigInProgress(ctx context.Context, nodeName, re
 	return nil
 }
 
-func (a *Adaptor) handleTransitionNodes(ctx context.Context, nodelist *hwmgmtv1alpha1.NodeList, postInstall bool) (bool, error) {
+func (a *Adaptor) handleTransitionNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodelist *hwmgmtv1alpha1.NodeList,
+	postInstall bool) (bool, error) {
 
 	for _, node := range nodelist.Items {
 		bmh, err := a.getBMHForNode(ctx, &node)
@@ -541,9 +502,16 @@ func (a *Adaptor) handleTransitionNodes(ctx context.Context, nodelist *hwmgmtv1a
 		}
 
 		if postInstall {
-			if err := a.evaluateCRForReboot(ctx, bmh); err != nil {
+			deferred, err := a.evaluateCRForReboot(ctx, &node, bmh)
+			if err != nil {
 				return true, err
 			}
+			if deferred {
+				if err := a.completeDeferredBiosUpdate(ctx, &node, bmh); err != nil {
+					return true, err
+				}
+				return true, nil
+			}
 		}
 		updateCases := []struct {
 			AnnotationKey string
@@ -560,7 +528,7 @@ func (a *Adaptor) handleTransitionNodes(ctx context.Context, nodelist *hwmgmtv1a
 				continue
 			}
 
-			if err := a.processBMHUpdateCase(ctx, &node, bmh, uc, postInstall); err != nil {
+			if err := a.processBMHUpdateCase(ctx, hwmgr, &node, bmh, uc, postInstall); err != nil {
 				return true, err
 			}
 			return true, nil
@@ -572,13 +540,15 @@ func (a *Adaptor) handleTransitionNodes(ctx context.Context, nodelist *hwmgmtv1a
 
 func (a *Adaptor) addRebootAnnotation(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	if err := a.updateBMHMetaWithRetry(ctx, bmhName, "annotation", BmhRebootAnnotation, "", OpAdd); err != nil {
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(BmhRebootAnnotation, "")); err != nil {
 		return fmt.Errorf("failed to add %s to BMH %+v: %w", BmhRebootAnnotation, bmhName, err)
 	}
 	return nil
 }
 
-func (a *Adaptor) evaluateCRForReboot(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
+// evaluateCRForReboot reboots the BMH into servicing once metal3 has validated the requested changes. It returns true,
+// without rebooting, if only BIOS settings flagged as not requiring a reboot have changed.
+func (a *Adaptor) evaluateCRForReboot(ctx context.Context, node *hwmgmtv1alpha1.Node, bmh *metal3v1alpha1.BareMetalHost) (bool, error) {
 	// Check if both annotations are present
 	hasBiosAnnotation := bmh.Annotations[BiosUpdateNeededAnnotation] != ""
 	hasFirmwareAnnotation := bmh.Annotations[FirmwareUpdateNeededAnnotation] != ""
@@ -590,28 +560,36 @@ func (a *Adaptor) evaluateCRForReboot(ctx context.Context, bmh *metal3v1alpha1.B
 	if hasBiosAnnotation && hasFirmwareAnnotation {
 		biosChange, err = a.isFirmwareSettingsChangeDetectedAndValid(ctx, bmh)
 		if err != nil {
-			return fmt.Errorf("failed to evaluate FirmwareSettings status: %w", err)
+			return false, fmt.Errorf("failed to evaluate FirmwareSettings status: %w", err)
 		}
 
 		firmwareChange, err = a.isHostFirmwareComponentsChangeDetectedAndValid(ctx, bmh)
 		if err != nil {
-			return fmt.Errorf("failed to evaluate HostFirmwareComponents status: %w", err)
+			return false, fmt.Errorf("failed to evaluate HostFirmwareComponents status: %w", err)
 		}
 
 		if biosChange && firmwareChange {
-			return a.addRebootAnnotation(ctx, bmh)
+			return false, a.addRebootAnnotation(ctx, bmh)
 		}
-		return nil
+		return false, nil
 	}
 
 	// If only BIOS annotation is present
 	if hasBiosAnnotation {
 		biosChange, err = a.isFirmwareSettingsChangeDetectedAndValid(ctx, bmh)
 		if err != nil {
-			return fmt.Errorf("failed to evaluate FirmwareSettings status: %w", err)
+			return false, fmt.Errorf("failed to evaluate FirmwareSettings status: %w", err)
 		}
 		if biosChange {
-			return a.addRebootAnnotation(ctx, bmh)
+			// A firmware update always needs a reboot, so the BIOS settings alone decide whether one is needed here
+			deferrable, err := a.isBiosRebootDeferrable(ctx, node, bmh)
+			if err != nil {
+				return false, fmt.Errorf("failed to evaluate BIOS settings for reboot: %w", err)
+			}
+			if deferrable {
+				return true, nil
+			}
+			return false, a.addRebootAnnotation(ctx, bmh)
 		}
 	}
 
@@ -619,25 +597,32 @@ func (a *Adaptor) evaluateCRForReboot(ctx context.Context, bmh *metal3v1alpha1.B
 	if hasFirmwareAnnotation {
 		firmwareChange, err = a.isHostFirmwareComponentsChangeDetectedAndValid(ctx, bmh)
 		if err != nil {
-			return fmt.Errorf("failed to evaluate HostFirmwareComponents status: %w", err)
+			return false, fmt.Errorf("failed to evaluate HostFirmwareComponents status: %w", err)
 		}
 		if firmwareChange {
-			return a.addRebootAnnotation(ctx, bmh)
+			return false, a.addRebootAnnotation(ctx, bmh)
 		}
 	}
 
-	return nil
+	return false, nil
 }
 
-// processBMHUpdateCase handles the update for a given BMH and update case.
-func (a *Adaptor) processBMHUpdateCase(ctx context.Context, node *hwmgmtv1alpha1.Node, bmh *metal3v1alpha1.BareMetalHost,
+// processBMHUpdateCase handles the update for a given BMH and update case. A BMH in an error state fails the node
+// during provisioning, while a day-2 update remediates the error according to the policy of the HardwareManager.
+func (a *Adaptor) processBMHUpdateCase(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost,
 	uc struct {
 		AnnotationKey string
 		Reason        string
 		LogLabel      string
 	}, postInstall bool) error {
 
-	if bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError && bmh.Status.ErrorType != metal3v1alpha1.PowerManagementError {
+	// A rollback is started from the error left by the failed update, which servicing the rollback clears
+	if bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError && bmh.Status.ErrorType != metal3v1alpha1.PowerManagementError &&
+		!isHwProfileRollbackInProgress(node) {
+		if postInstall {
+			return a.remediateBMHUpdateError(ctx, hwmgr, node, bmh)
+		}
 		message := "BMH in error state"
 		a.Logger.WarnContext(ctx, message, slog.String("BMH", bmh.Name))
 		condType := hwmgmtv1alpha1.Provisioned
@@ -679,7 +664,7 @@ func (a *Adaptor) processBMHUpdateCase(ctx context.Context, node *hwmgmtv1alpha1
 
 	// Remove the update-needed annotation from the BMH.
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	if err := a.updateBMHMetaWithRetry(ctx, bmhName, MetaTypeAnnotation, uc.AnnotationKey, "", OpRemove); err != nil {
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(uc.AnnotationKey)); err != nil {
 		return fmt.Errorf("failed to remove annotation %s from BMH %s: %w", uc.AnnotationKey, bmh.Name, err)
 	}
 
@@ -704,7 +689,8 @@ func (a *Adaptor) processBMHUpdateCase(ctx context.Context, node *hwmgmtv1alpha1
 	return nil
 }
 
-func (a *Adaptor) handleBMHCompletion(ctx context.Context, nodelist *hwmgmtv1alpha1.NodeList) (bool, error) {
+func (a *Adaptor) handleBMHCompletion(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodelist *hwmgmtv1alpha1.NodeList) (bool, error) {
 
 	a.Logger.InfoContext(ctx, "Checking for node with config in progress")
 	node := utils.FindNodeInProgress(nodelist)
@@ -734,6 +720,13 @@ func (a *Adaptor) handleBMHCompletion(ctx context.Context, nodelist *hwmgmtv1alp
 			}
 			return false, errMessage
 		}
+		timedOut, err := a.updateNodeProgress(ctx, hwmgr, node, bmh, string(hwmgmtv1alpha1.Provisioned))
+		if err != nil {
+			a.Logger.ErrorContext(ctx, "failed to update node progress", slog.String("node", node.Name), slog.String("error", err.Error()))
+		}
+		if timedOut {
+			return false, fmt.Errorf("configuration of node %s timed out", node.Name)
+		}
 		return true, nil
 	}
 
@@ -745,7 +738,8 @@ func (a *Adaptor) handleBMHCompletion(ctx context.Context, nodelist *hwmgmtv1alp
 	return false, nil // update is now complete
 }
 
-func (a *Adaptor) checkForPendingUpdate(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+func (a *Adaptor) checkForPendingUpdate(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
 	// check if there are any pending work
 	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
 	if err != nil {
@@ -753,7 +747,7 @@ func (a *Adaptor) checkForPendingUpdate(ctx context.Context, nodepool *hwmgmtv1a
 	}
 
 	// Process BMHs transitioning to "Preparing"
-	updating, err := a.handleTransitionNodes(ctx, nodelist, false)
+	updating, err := a.handleTransitionNodes(ctx, hwmgr, nodelist, false)
 	if err != nil {
 		return updating, err
 	}
@@ -764,7 +758,7 @@ func (a *Adaptor) checkForPendingUpdate(ctx context.Context, nodepool *hwmgmtv1a
 	}
 
 	// Check if configuration is completed
-	updating, err = a.handleBMHCompletion(ctx, nodelist)
+	updating, err = a.handleBMHCompletion(ctx, hwmgr, nodelist)
 	if err != nil {
 		return updating, err
 	}
@@ -793,13 +787,16 @@ func (a *Adaptor) markBMHAllocated(ctx context.Context, bmh *metal3v1alpha1.Bare
 		return nil // No change needed
 	}
 	name := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	return a.updateBMHMetaWithRetry(ctx, name, MetaTypeLabel, BmhAllocatedLabel, ValueTrue, OpAdd)
+	return a.updateBMHMetaWithRetry(ctx, name, utils.AddLabel(BmhAllocatedLabel, ValueTrue))
 }
 
-// unmarkBMHAllocated removes the "allocated" label from a BareMetalHost if it exists.
+// unmarkBMHAllocated removes the "allocated" label and the claim from a BareMetalHost if they exist.
 func (a *Adaptor) unmarkBMHAllocated(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
 	name := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	return a.updateBMHMetaWithRetry(ctx, name, MetaTypeLabel, BmhAllocatedLabel, "", OpRemove)
+	return a.updateBMHMetaWithRetry(ctx, name,
+		utils.RemoveLabel(BmhAllocatedLabel),
+		utils.RemoveAnnotation(BmhClaimAnnotation),
+		utils.RemoveAnnotation(NodeNameAnnotation))
 }
 
 // removeMetal3Finalizer removes the Metal3 finalizer from the corresponding PreprovisioningImage resource.
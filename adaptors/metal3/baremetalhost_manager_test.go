@@ -0,0 +1,177 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const testBMHNamespace = "bmh-ns"
+
+func newTestAdaptor(t *testing.T, objects ...client.Object) *Adaptor {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := metal3v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := hwmgmtv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := pluginv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	// Nodes are listed by the NodePool field index set up by the NodePool controller
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(&hwmgmtv1alpha1.Node{}, utils.NodeSpecNodePoolKey, func(obj client.Object) []string {
+			return []string{obj.(*hwmgmtv1alpha1.Node).Spec.NodePool}
+		}).
+		WithStatusSubresource(&hwmgmtv1alpha1.Node{}, &hwmgmtv1alpha1.NodePool{}).
+		Build()
+	return NewAdaptor(c, c, scheme, slog.Default(), "oran-hwmgr-plugin")
+}
+
+// newAllocatedBMH returns a BMH allocated to a node, with network data and template metadata applied
+func newAllocatedBMH(annotations map[string]string) *metal3v1alpha1.BareMetalHost {
+	bmh := &metal3v1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bmh1",
+			Namespace: testBMHNamespace,
+			Labels: map[string]string{
+				BmhAllocatedLabel:  "true",
+				"example.com/rack": "r1",
+			},
+			Annotations: map[string]string{
+				BmhClaimAnnotation:           "np1",
+				NodeNameAnnotation:           "node1",
+				NetworkDataAnnotation:        "bmh1-network-data",
+				BmhAppliedMetadataAnnotation: `{"labels":["example.com/rack"]}`,
+			},
+		},
+		Spec: metal3v1alpha1.BareMetalHostSpec{PreprovisioningNetworkDataName: "bmh1-network-data"},
+	}
+	for key, value := range annotations {
+		bmh.Annotations[key] = value
+	}
+	return bmh
+}
+
+func newPreprovisioningImage() *metal3v1alpha1.PreprovisioningImage {
+	return &metal3v1alpha1.PreprovisioningImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "bmh1",
+			Namespace:  testBMHNamespace,
+			Finalizers: []string{Metal3Finalizer},
+		},
+	}
+}
+
+func newNetworkDataSecret() *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bmh1-network-data", Namespace: testBMHNamespace}}
+}
+
+func TestReleaseBMH(t *testing.T) {
+	tests := []struct {
+		description string
+		annotations map[string]string
+		// withImage creates the PreprovisioningImage of the BMH
+		withImage bool
+		// expectFinalizer is whether the PreprovisioningImage keeps the Metal3 finalizer
+		expectFinalizer bool
+	}{
+		{
+			description: "allocated BMH",
+			withImage:   true,
+		},
+		{
+			description:     "adopted BMH keeps its PreprovisioningImage finalizer",
+			annotations:     map[string]string{BmhAdoptedAnnotation: "true"},
+			withImage:       true,
+			expectFinalizer: true,
+		},
+		{
+			description: "BMH without a PreprovisioningImage",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			bmh := newAllocatedBMH(test.annotations)
+			objects := []client.Object{bmh, newNetworkDataSecret()}
+			if test.withImage {
+				objects = append(objects, newPreprovisioningImage())
+			}
+			a := newTestAdaptor(t, objects...)
+
+			if err := a.finalizer.releaseBMH(ctx, bmh); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			released := &metal3v1alpha1.BareMetalHost{}
+			if err := a.Client.Get(ctx, client.ObjectKeyFromObject(bmh), released); err != nil {
+				t.Fatalf("failed to get BMH: %v", err)
+			}
+			for _, label := range []string{BmhAllocatedLabel, "example.com/rack"} {
+				if _, exists := released.Labels[label]; exists {
+					t.Errorf("expected label %s to be removed", label)
+				}
+			}
+			for _, annotation := range []string{BmhClaimAnnotation, NodeNameAnnotation, NetworkDataAnnotation,
+				BmhAppliedMetadataAnnotation, BmhAdoptedAnnotation} {
+				if _, exists := released.Annotations[annotation]; exists {
+					t.Errorf("expected annotation %s to be removed", annotation)
+				}
+			}
+			if released.Spec.PreprovisioningNetworkDataName != "" {
+				t.Errorf("expected the network data to be cleared, got %s", released.Spec.PreprovisioningNetworkDataName)
+			}
+
+			secret := &corev1.Secret{}
+			if err := a.Client.Get(ctx, client.ObjectKeyFromObject(newNetworkDataSecret()), secret); !errors.IsNotFound(err) {
+				t.Errorf("expected the network data secret to be deleted, got %v", err)
+			}
+
+			if !test.withImage {
+				return
+			}
+			image := &metal3v1alpha1.PreprovisioningImage{}
+			if err := a.Client.Get(ctx, client.ObjectKeyFromObject(newPreprovisioningImage()), image); err != nil {
+				t.Fatalf("failed to get PreprovisioningImage: %v", err)
+			}
+			if controllerutil.ContainsFinalizer(image, Metal3Finalizer) != test.expectFinalizer {
+				t.Errorf("expected the Metal3 finalizer to be present: %t", test.expectFinalizer)
+			}
+		})
+	}
+}
+
+func TestReleaseBMHNotFound(t *testing.T) {
+	a := newTestAdaptor(t)
+	if err := a.finalizer.releaseBMH(context.Background(), newAllocatedBMH(nil)); err == nil {
+		t.Errorf("expected an error releasing a BMH that does not exist")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// changedBiosSettings returns the names of the settings of the HostFirmwareSettings whose requested value differs from
+// the value reported by the host
+func changedBiosSettings(hfs *metal3v1alpha1.HostFirmwareSettings) []string {
+	var changed []string
+	for name, value := range hfs.Spec.Settings {
+		if current, exists := hfs.Status.Settings[name]; !exists || current != value.String() {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// isBiosRebootDeferrable checks whether every BIOS setting changed by the hardware profile of the node is flagged as not
+// requiring a reboot, in which case the settings are left to be applied at the next reboot of the host
+func (a *Adaptor) isBiosRebootDeferrable(ctx context.Context, node *hwmgmtv1alpha1.Node, bmh *metal3v1alpha1.BareMetalHost) (bool, error) {
+	hfs, err := a.getHostFirmwareSettings(ctx, bmh.Name, bmh.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	changed := changedBiosSettings(hfs)
+	if len(changed) == 0 {
+		return false, nil
+	}
+
+	hwProfile, err := utils.GetHardwareProfile(ctx, a.Client, a.Namespace, node.Spec.HwProfile)
+	if err != nil {
+		return false, err // nolint: wrapcheck
+	}
+
+	for _, name := range changed {
+		if hwProfile.Spec.Bios.IsRebootRequired(name) {
+			return false, nil
+		}
+	}
+
+	a.Logger.InfoContext(ctx, "Changed BIOS settings do not require a reboot",
+		slog.String("BMH", bmh.Name), slog.Any("settings", changed))
+	return true, nil
+}
+
+// completeDeferredBiosUpdate completes the configuration of a node whose BIOS settings do not require a reboot. The
+// settings remain requested in the HostFirmwareSettings, and are applied by the servicing of the next reboot of the
+// host.
+func (a *Adaptor) completeDeferredBiosUpdate(ctx context.Context, node *hwmgmtv1alpha1.Node, bmh *metal3v1alpha1.BareMetalHost) error {
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(BiosUpdateNeededAnnotation)); err != nil {
+		return fmt.Errorf("failed to remove annotation %s from BMH %s: %w", BiosUpdateNeededAnnotation, bmh.Name, err)
+	}
+
+	node.Status.HwProfile = node.Spec.HwProfile
+	utils.SetStatusCondition(ctx, &node.Status.Conditions,
+		string(hwmgmtv1alpha1.Configured),
+		string(hwmgmtv1alpha1.ConfigApplied),
+		metav1.ConditionTrue,
+		string(hwmgmtv1alpha1.ConfigSuccess))
+	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
+		return fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+
+	utils.AddNodeEvent(node, utils.NodeEventBiosUpdateDeferred,
+		fmt.Sprintf("Hardware profile %s, BIOS settings applied at the next reboot", node.Spec.HwProfile))
+	removeUpdateProgressAnnotation(node)
+	if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, node, nil, utils.PATCH); err != nil {
+		return fmt.Errorf("failed to update node %s: %w", node.Name, err)
+	}
+	if err := a.recordAppliedHwProfile(ctx, node.Name, node.Namespace, node.Spec.HwProfile); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record applied hardware profile", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+
+	if err := a.removePreChangeAnnotation(ctx, bmh); err != nil {
+		return fmt.Errorf("failed to apply post-change annotation for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "BIOS update completed without reboot", slog.String("node", node.Name), slog.String("BMH", bmh.Name))
+	return nil
+}
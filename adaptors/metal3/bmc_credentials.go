@@ -0,0 +1,63 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RotateBMCCredentials sets new credentials in the secret referenced by the BareMetalHost backing a node. The
+// baremetal-operator re-registers the host when the secret changes, and the rotation is complete once the BMH reports
+// the new version of the secret as good credentials.
+func (a *Adaptor) RotateBMCCredentials(
+	ctx context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	credentials *corev1.Secret) (bool, error) {
+
+	username, password, err := utils.GetBmcCredentials(credentials)
+	if err != nil {
+		return false, fmt.Errorf("invalid BMC credentials secret %s: %w", credentials.Name, err)
+	}
+
+	bmh, err := a.getBMHForNode(ctx, node)
+	if err != nil {
+		return false, fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+	}
+
+	if bmh.Spec.BMC.CredentialsName == "" {
+		return false, fmt.Errorf("BMH %s/%s has no BMC credentials secret", bmh.Namespace, bmh.Name)
+	}
+
+	secret, err := utils.UpdateBmcSecretCredentials(ctx, a.Client, bmh.Spec.BMC.CredentialsName, bmh.Namespace, username, password)
+	if err != nil {
+		return false, fmt.Errorf("failed to update BMC credentials for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	good := bmh.Status.GoodCredentials
+	if good.Reference != nil && good.Reference.Name == secret.Name && good.Version == secret.ResourceVersion {
+		a.Logger.InfoContext(ctx, "BMH has accepted the new BMC credentials", slog.String("bmh", bmh.Name))
+		return true, nil
+	}
+
+	tried := bmh.Status.TriedCredentials
+	if tried.Version == secret.ResourceVersion && bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError {
+		return false, fmt.Errorf("BMH %s/%s failed to register with the new BMC credentials: %s",
+			bmh.Namespace, bmh.Name, bmh.Status.ErrorMessage)
+	}
+
+	a.Logger.InfoContext(ctx, "Waiting for BMH to register with the new BMC credentials", slog.String("bmh", bmh.Name))
+	return false, nil
+}
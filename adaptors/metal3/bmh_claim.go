@@ -0,0 +1,295 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BmhClaimAnnotation holds the claim of a BMH, naming the Node and NodePool it is allocated to. The claim is written
+// before any other allocation step and kept for as long as the BMH is allocated, so that an allocation interrupted part
+// way through can be resumed by the next reconcile instead of being repeated.
+const BmhClaimAnnotation = "hwmgr-plugin.oran.openshift.io/claim"
+
+// errBMHClaimed is returned when a BMH is claimed by another NodePool before this one could claim it
+var errBMHClaimed = errors.New("BMH is claimed by another NodePool")
+
+// bmhClaim is the content of the BmhClaimAnnotation
+type bmhClaim struct {
+	NodeName          string    `json:"nodeName"`
+	NodePool          string    `json:"nodePool"`
+	NodePoolNamespace string    `json:"nodePoolNamespace"`
+	NodePoolUID       types.UID `json:"nodePoolUID"`
+	NodeGroup         string    `json:"nodeGroup"`
+}
+
+// getBMHClaim returns the claim of a BMH, or nil if it has none. A claim that cannot be parsed
+// is treated as absent, so the BMH can be claimed again.
+func getBMHClaim(bmh *metal3v1alpha1.BareMetalHost) *bmhClaim {
+	value, exists := bmh.Annotations[BmhClaimAnnotation]
+	if !exists {
+		return nil
+	}
+
+	claim := &bmhClaim{}
+	if err := json.Unmarshal([]byte(value), claim); err != nil || claim.NodeName == "" || claim.NodePoolUID == "" {
+		return nil
+	}
+	return claim
+}
+
+// isOwnedBy checks whether the claim belongs to the given NodePool
+func (r *bmhClaim) isOwnedBy(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return r != nil && r.NodePoolUID == nodepool.UID
+}
+
+// isBMHClaimLive checks whether the NodePool of a claim still exists. A claim left behind by a
+// deleted NodePool no longer holds the BMH.
+func (a *Adaptor) isBMHClaimLive(ctx context.Context, claim *bmhClaim) (bool, error) {
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	err := a.NoncachedClient.Get(ctx, types.NamespacedName{Name: claim.NodePool, Namespace: claim.NodePoolNamespace}, nodepool)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get NodePool %s/%s: %w", claim.NodePoolNamespace, claim.NodePool, err)
+	}
+	return nodepool.UID == claim.NodePoolUID, nil
+}
+
+// claimBMH writes the claim of a BMH for a node of the given NodePool, returning the claim. If the BMH
+// already has a claim for the NodePool, that claim is returned unchanged so the allocation resumes with the same
+// Node. A BMH claimed by another live NodePool is not touched, and errBMHClaimed is returned.
+//
+// The claim is written with an optimistic lock on the latest version of the BMH, so two NodePools allocating at the
+// same time cannot both claim it.
+func (a *Adaptor) claimBMH(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, nodepool *hwmgmtv1alpha1.NodePool,
+	groupName string) (*bmhClaim, error) {
+
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	var claimed *bmhClaim
+
+	err := retry.OnError(retry.DefaultRetry, k8serrors.IsConflict, func() error {
+		latest := &metal3v1alpha1.BareMetalHost{}
+		if err := a.NoncachedClient.Get(ctx, bmhName, latest); err != nil {
+			return fmt.Errorf("failed to get BMH %s: %w", bmhName, err)
+		}
+
+		existing := getBMHClaim(latest)
+		if existing.isOwnedBy(nodepool) {
+			claimed = existing
+			return nil
+		}
+		if existing != nil {
+			live, err := a.isBMHClaimLive(ctx, existing)
+			if err != nil {
+				return err
+			}
+			if live {
+				return errBMHClaimed
+			}
+			a.Logger.InfoContext(ctx, "Reclaiming BMH with a stale claim",
+				slog.Any("bmh", bmhName),
+				slog.String("nodePool", existing.NodePoolNamespace+"/"+existing.NodePool),
+				slog.String("nodename", existing.NodeName))
+		}
+
+		// A BMH whose allocation was started before BMH claims were introduced keeps its node name
+		nodeName := latest.Annotations[NodeNameAnnotation]
+		if nodeName == "" {
+			nodeName = utils.GenerateNodeName()
+		}
+		claim := &bmhClaim{
+			NodeName:          nodeName,
+			NodePool:          nodepool.Name,
+			NodePoolNamespace: nodepool.Namespace,
+			NodePoolUID:       nodepool.UID,
+			NodeGroup:         groupName,
+		}
+		value, err := json.Marshal(claim)
+		if err != nil {
+			return fmt.Errorf("failed to encode BMH claim: %w", err)
+		}
+
+		base := latest.DeepCopy()
+		if latest.Annotations == nil {
+			latest.Annotations = make(map[string]string)
+		}
+		latest.Annotations[BmhClaimAnnotation] = string(value)
+		if err := a.Client.Patch(ctx, latest, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return fmt.Errorf("failed to patch BMH %s: %w", bmhName, err)
+		}
+		claimed = claim
+		return nil
+	})
+	if err != nil {
+		return nil, err // nolint: wrapcheck
+	}
+
+	if bmh.Annotations == nil {
+		bmh.Annotations = make(map[string]string)
+	}
+	if value, err := json.Marshal(claimed); err == nil {
+		bmh.Annotations[BmhClaimAnnotation] = string(value)
+	}
+	return claimed, nil
+}
+
+// filterClaimedBMHs removes the BMHs that are claimed by a live NodePool from a list of allocation candidates. The
+// BMHs claimed by the given NodePool are removed as well, as they are resumed by resumeInterruptedAllocations.
+func (a *Adaptor) filterClaimedBMHs(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	bmhList metal3v1alpha1.BareMetalHostList) (metal3v1alpha1.BareMetalHostList, error) {
+	return a.excludeClaimedBMHs(ctx, nodepool, bmhList, false)
+}
+
+// filterBMHsClaimedByOthers removes the BMHs that are claimed by another live NodePool from a list of BMHs, keeping
+// those claimed by the given NodePool, such as the BMHs it has reserved
+func (a *Adaptor) filterBMHsClaimedByOthers(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	bmhList metal3v1alpha1.BareMetalHostList) (metal3v1alpha1.BareMetalHostList, error) {
+	return a.excludeClaimedBMHs(ctx, nodepool, bmhList, true)
+}
+
+func (a *Adaptor) excludeClaimedBMHs(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	bmhList metal3v1alpha1.BareMetalHostList, keepOwn bool) (metal3v1alpha1.BareMetalHostList, error) {
+
+	var filtered metal3v1alpha1.BareMetalHostList
+	for _, bmh := range bmhList.Items {
+		claim := getBMHClaim(&bmh)
+		if claim != nil {
+			if claim.isOwnedBy(nodepool) {
+				if keepOwn {
+					filtered.Items = append(filtered.Items, bmh)
+				}
+				continue
+			}
+			live, err := a.isBMHClaimLive(ctx, claim)
+			if err != nil {
+				return filtered, err
+			}
+			if live {
+				continue
+			}
+		}
+		filtered.Items = append(filtered.Items, bmh)
+	}
+	return filtered, nil
+}
+
+// listClaimedBMHs lists the BMHs with a claim for the given NodePool
+func (a *Adaptor) listClaimedBMHs(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) ([]metal3v1alpha1.BareMetalHost, error) {
+
+	bmhList, err := a.listBMHs(ctx, hwmgr, nodepool.Spec.Site, hwmgmtv1alpha1.NodePoolData{}, AllBMHs, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list BMHs for site=%s: %w", nodepool.Spec.Site, err)
+	}
+
+	var claimed []metal3v1alpha1.BareMetalHost
+	for _, bmh := range bmhList.Items {
+		if getBMHClaim(&bmh).isOwnedBy(nodepool) {
+			claimed = append(claimed, bmh)
+		}
+	}
+	return claimed, nil
+}
+
+// resumeInterruptedAllocations completes the allocation of the BMHs claimed by the NodePool whose Node is not yet
+// recorded in its status. This happens when a reconcile fails after claiming a BMH, for example when the Node was
+// created but marking the BMH allocated or updating the NodePool status failed. Resuming the allocation, rather than
+// picking a new BMH, avoids allocating more BMHs than requested and leaking the ones already claimed.
+func (a *Adaptor) resumeInterruptedAllocations(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool, allocator bmhAllocator) error {
+
+	claimed, err := a.listClaimedBMHs(ctx, hwmgr, nodepool)
+	if err != nil {
+		return err
+	}
+
+	for i := range claimed {
+		bmh := &claimed[i]
+		claim := getBMHClaim(bmh)
+		if contains(nodepool.Status.Properties.NodeNames, claim.NodeName) {
+			continue
+		}
+
+		group, found := findNodeGroup(nodepool, claim.NodeGroup)
+		if !found {
+			// The group was removed from the NodePool, so any Node created for the BMH is dropped and the BMH is
+			// returned to the free pool, undoing whichever allocation steps were completed
+			a.Logger.WarnContext(ctx, "Releasing BMH claimed for a node group that no longer exists",
+				slog.String("bmh", bmh.Namespace+"/"+bmh.Name),
+				slog.String("nodegroup", claim.NodeGroup))
+			node := &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: claim.NodeName, Namespace: a.Namespace}}
+			if err := a.Client.Delete(ctx, node); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete node %s: %w", claim.NodeName, err)
+			}
+			if err := a.finalizer.releaseBMH(ctx, bmh); err != nil {
+				return err
+			}
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Resuming interrupted allocation",
+			slog.String("bmh", bmh.Namespace+"/"+bmh.Name),
+			slog.String("nodename", claim.NodeName),
+			slog.String("nodegroup", claim.NodeGroup))
+		if err := allocator.allocateBMH(ctx, hwmgr, bmh, nodepool, group); err != nil {
+			return fmt.Errorf("failed to resume allocation of BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+		}
+	}
+	return nil
+}
+
+// releaseClaimedBMHs returns to the free pool the BMHs claimed by a NodePool without a Node recorded for them, which
+// ReleaseNodePool would otherwise miss as it only follows the child Nodes of the NodePool.
+func (a *Adaptor) releaseClaimedBMHs(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool, released map[types.NamespacedName]bool) error {
+
+	claimed, err := a.listClaimedBMHs(ctx, hwmgr, nodepool)
+	if err != nil {
+		return err
+	}
+
+	for i := range claimed {
+		bmh := &claimed[i]
+		if released[types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}] {
+			continue
+		}
+		a.Logger.InfoContext(ctx, "Releasing BMH of an interrupted allocation",
+			slog.String("bmh", bmh.Namespace+"/"+bmh.Name))
+		if err := a.finalizer.releaseBMH(ctx, bmh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findNodeGroup returns the node group of a NodePool with the given name
+func findNodeGroup(nodepool *hwmgmtv1alpha1.NodePool, name string) (hwmgmtv1alpha1.NodeGroup, bool) {
+	index := slices.IndexFunc(nodepool.Spec.NodeGroup, func(group hwmgmtv1alpha1.NodeGroup) bool {
+		return group.NodePoolData.Name == name
+	})
+	if index < 0 {
+		return hwmgmtv1alpha1.NodeGroup{}, false
+	}
+	return nodepool.Spec.NodeGroup[index], true
+}
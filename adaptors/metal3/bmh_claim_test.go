@@ -0,0 +1,110 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestResumeInterruptedAllocationOfRemovedGroup(t *testing.T) {
+	ctx := context.Background()
+
+	bmh := &metal3v1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bmh1",
+			Namespace: testBMHNamespace,
+			Labels:    map[string]string{LabelSiteID: "site1"},
+		},
+		Status: metal3v1alpha1.BareMetalHostStatus{HardwareDetails: &metal3v1alpha1.HardwareDetails{}},
+	}
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "oran-hwmgr-plugin", UID: "np1-uid"},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID:      "cloud1",
+			LocationSpec: hwmgmtv1alpha1.LocationSpec{Site: "site1"},
+			Extensions: map[string]string{
+				BMHMetadataTemplateExtension: "labels:\n  example.com/cluster: \"{{ .CloudID }}\"\n",
+				NetworkDataTemplateExtension: "network-data",
+			},
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "worker"}, Size: 1},
+			},
+		},
+	}
+	template := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "network-data", Namespace: "oran-hwmgr-plugin"},
+		Data:       map[string]string{NetworkDataTemplateKey: "interfaces: []\n"},
+	}
+	a := newTestAdaptor(t, bmh, nodepool, template, newPreprovisioningImage())
+
+	// The allocation is interrupted once the metadata and network data have been applied
+	claim, err := a.claimBMH(ctx, bmh, nodepool, "worker")
+	if err != nil {
+		t.Fatalf("failed to claim BMH: %v", err)
+	}
+	if err := a.applyBMHMetadata(ctx, nodepool, bmh, claim.NodeName, "worker"); err != nil {
+		t.Fatalf("failed to apply BMH metadata: %v", err)
+	}
+	if err := a.generateNetworkData(ctx, nodepool, bmh, claim.NodeName, "worker"); err != nil {
+		t.Fatalf("failed to generate network data: %v", err)
+	}
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := a.applyBMHNetworkData(ctx, bmhName); err != nil {
+		t.Fatalf("failed to apply network data: %v", err)
+	}
+
+	// The group is then removed from the NodePool
+	nodepool.Spec.NodeGroup = nil
+	allocator := &fakeAllocator{}
+	if err := a.resumeInterruptedAllocations(ctx, &pluginv1alpha1.HardwareManager{}, nodepool, allocator); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(allocator.allocated) != 0 {
+		t.Errorf("expected the allocation of a removed group not to be resumed")
+	}
+
+	released := &metal3v1alpha1.BareMetalHost{}
+	if err := a.Client.Get(ctx, bmhName, released); err != nil {
+		t.Fatalf("failed to get BMH: %v", err)
+	}
+	if _, exists := released.Labels["example.com/cluster"]; exists {
+		t.Errorf("expected the templated label to be removed")
+	}
+	for _, annotation := range []string{BmhClaimAnnotation, NetworkDataAnnotation, BmhAppliedMetadataAnnotation} {
+		if _, exists := released.Annotations[annotation]; exists {
+			t.Errorf("expected annotation %s to be removed", annotation)
+		}
+	}
+	if released.Spec.PreprovisioningNetworkDataName != "" {
+		t.Errorf("expected the network data to be cleared, got %s", released.Spec.PreprovisioningNetworkDataName)
+	}
+
+	secret := &corev1.Secret{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Name: networkDataSecretName(bmh), Namespace: bmh.Namespace}, secret); !errors.IsNotFound(err) {
+		t.Errorf("expected the network data secret to be deleted, got %v", err)
+	}
+
+	image := &metal3v1alpha1.PreprovisioningImage{}
+	if err := a.Client.Get(ctx, client.ObjectKeyFromObject(newPreprovisioningImage()), image); err != nil {
+		t.Fatalf("failed to get PreprovisioningImage: %v", err)
+	}
+	if controllerutil.ContainsFinalizer(image, Metal3Finalizer) {
+		t.Errorf("expected the Metal3 finalizer to be removed")
+	}
+}
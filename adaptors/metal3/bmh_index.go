@@ -0,0 +1,97 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Field indexes on the BMHs in the manager cache, so that BMHs are looked up by their labels and allocation without
+// scanning every cached BMH
+const (
+	bmhSiteIDIndexKey         = "bmh.siteId"
+	bmhResourcePoolIDIndexKey = "bmh.resourcePoolId"
+	bmhAllocatedIndexKey      = "bmh.allocated"
+
+	// bmhInventoryIndexKey indexes the BMHs reported in the inventory
+	bmhInventoryIndexKey = "bmh.inventory"
+)
+
+// bmhLabelIndexFunc returns an index function for the value of a BMH label, leaving out BMHs without it
+func bmhLabelIndexFunc(label string) client.IndexerFunc {
+	return func(obj client.Object) []string {
+		if value := obj.GetLabels()[label]; value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+}
+
+// setupBMHIndexes adds the BMH field indexes to the manager cache, and reads BMHs from the cache through them. If the
+// indexes cannot be added, such as when the BareMetalHost CRD is not installed, BMHs are read with label selectors.
+func (a *Adaptor) setupBMHIndexes(mgr ctrl.Manager) error {
+	ctx := context.Background()
+	indexer := mgr.GetFieldIndexer()
+
+	indexes := map[string]client.IndexerFunc{
+		bmhSiteIDIndexKey:         bmhLabelIndexFunc(LabelSiteID),
+		bmhResourcePoolIDIndexKey: bmhLabelIndexFunc(LabelResourcePoolID),
+		bmhAllocatedIndexKey: func(obj client.Object) []string {
+			return []string{strconv.FormatBool(obj.GetLabels()[BmhAllocatedLabel] == ValueTrue)}
+		},
+		bmhInventoryIndexKey: func(obj client.Object) []string {
+			return []string{strconv.FormatBool(includeInInventory(*obj.(*metal3v1alpha1.BareMetalHost)))}
+		},
+	}
+	for key, indexFunc := range indexes {
+		if err := indexer.IndexField(ctx, &metal3v1alpha1.BareMetalHost{}, key, indexFunc); err != nil {
+			return fmt.Errorf("failed to setup BMH %s indexer: %w", key, err)
+		}
+	}
+
+	a.bmhReader = mgr.GetCache()
+	return nil
+}
+
+// listIndexedBMHs lists the BMHs from the cache with the given field index values
+func (a *Adaptor) listIndexedBMHs(ctx context.Context, fields client.MatchingFields,
+	opts ...client.ListOption) (metal3v1alpha1.BareMetalHostList, error) {
+
+	var bmhList metal3v1alpha1.BareMetalHostList
+	if err := a.bmhReader.List(ctx, &bmhList, append(opts, fields)...); err != nil {
+		return bmhList, fmt.Errorf("failed to get BMH list: %w", err)
+	}
+	return bmhList, nil
+}
+
+// listInventoryBMHs lists the BMHs reported in the inventory. The BMHs are not copied from the cache, as the inventory
+// is built from every BMH on each request, so they must not be modified.
+func (a *Adaptor) listInventoryBMHs(ctx context.Context) (metal3v1alpha1.BareMetalHostList, error) {
+	if a.bmhReader != nil {
+		return a.listIndexedBMHs(ctx, client.MatchingFields{bmhInventoryIndexKey: strconv.FormatBool(true)},
+			client.UnsafeDisableDeepCopy)
+	}
+
+	var bmhList metal3v1alpha1.BareMetalHostList
+	if err := a.Client.List(ctx, &bmhList); err != nil {
+		return bmhList, fmt.Errorf("failed to get bmh list: %w", err)
+	}
+
+	inventory := metal3v1alpha1.BareMetalHostList{}
+	for _, bmh := range bmhList.Items {
+		if includeInInventory(bmh) {
+			inventory.Items = append(inventory.Items, bmh)
+		}
+	}
+	return inventory, nil
+}
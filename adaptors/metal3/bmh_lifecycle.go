@@ -0,0 +1,112 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The lifecycle of a BMH in a NodePool is split into three stages, each behind an interface so that the flows that use
+// one stage can be tested with the others replaced:
+//   - a bmhAllocator assigns a BMH to a node group of a NodePool and creates its Node
+//   - a bmhUpdater applies a hardware profile to a BMH, when it is allocated and as a day-2 update of its Node
+//   - a bmhFinalizer returns a BMH to the free pool once its Node is released, replaced or removed
+
+// bmhAllocator assigns a BareMetalHost to a node group of a NodePool
+type bmhAllocator interface {
+	allocateBMH(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, bmh *metal3v1alpha1.BareMetalHost,
+		nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) error
+}
+
+// bmhUpdater applies a hardware profile to a BareMetalHost, returning whether an update of the host was started
+type bmhUpdater interface {
+	updateBMHProfile(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, bmh *metal3v1alpha1.BareMetalHost,
+		nodeName, nodeNamespace, profileName string, postInstall bool) (bool, error)
+}
+
+// bmhFinalizer returns a BareMetalHost allocated to a NodePool to the free pool
+type bmhFinalizer interface {
+	releaseBMH(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error
+}
+
+// provisioningAllocator allocates available BMHs, applying the hardware profile and network data of the NodePool
+type provisioningAllocator struct {
+	*Adaptor
+}
+
+func (p provisioningAllocator) allocateBMH(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	bmh *metal3v1alpha1.BareMetalHost, nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) error {
+	return p.allocateBMHToNodePool(ctx, hwmgr, bmh, nodepool, group)
+}
+
+// adoptionAllocator allocates BMHs that are already provisioned, leaving the hosts as they are
+type adoptionAllocator struct {
+	*Adaptor
+}
+
+func (p adoptionAllocator) allocateBMH(ctx context.Context, _ *pluginv1alpha1.HardwareManager,
+	bmh *metal3v1alpha1.BareMetalHost, nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) error {
+	return p.adoptBMHToNodePool(ctx, bmh, nodepool, group)
+}
+
+// profileUpdater applies hardware profiles through the firmware settings and components of the BMH, reporting the
+// outcome in the conditions of the Node
+type profileUpdater struct {
+	*Adaptor
+}
+
+func (p profileUpdater) updateBMHProfile(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	bmh *metal3v1alpha1.BareMetalHost, nodeName, nodeNamespace, profileName string, postInstall bool) (bool, error) {
+	return p.processHwProfileWithHandledError(ctx, hwmgr, bmh, nodeName, nodeNamespace, profileName, postInstall)
+}
+
+// bmhReleaser undoes the allocation steps completed on a BMH
+type bmhReleaser struct {
+	*Adaptor
+}
+
+// releaseBMH returns a BMH allocated or claimed by a NodePool to the free pool. It is the single release path for
+// BMHs, whether the whole NodePool is released, or a node is replaced, removed, left over from an interrupted
+// allocation, or no longer reserved. A failed BMH is not in the available state, so it is only allocated again once it
+// has been repaired.
+func (r bmhReleaser) releaseBMH(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
+	if err := r.unmarkBMHAllocated(ctx, bmh); err != nil {
+		return fmt.Errorf("failed to unmark BMH %s/%s allocated: %w", bmh.Namespace, bmh.Name, err)
+	}
+	if err := r.releaseBMHNetworkData(ctx, bmh); err != nil {
+		return fmt.Errorf("failed to release network data of BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+	if err := r.removeBMHMetadata(ctx, bmh); err != nil {
+		return err
+	}
+	if isBMHAdopted(bmh) {
+		// An adopted BMH was provisioned outside the plugin, so leave its PreprovisioningImage alone
+		bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+		if err := r.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(BmhAdoptedAnnotation)); err != nil {
+			return fmt.Errorf("failed to remove adopted annotation from BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+		}
+		return nil
+	}
+	if err := r.removeMetal3Finalizer(ctx, bmh.Name, bmh.Namespace); k8serrors.IsNotFound(err) {
+		// The PreprovisioningImage of a failed BMH, or of one whose allocation was interrupted, may never have been
+		// created
+		r.Logger.InfoContext(ctx, "No PreprovisioningImage to remove the finalizer from",
+			slog.String("bmh", bmh.Namespace+"/"+bmh.Name))
+	} else if err != nil {
+		return fmt.Errorf("failed to remove finalizer for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeAllocator records the BMHs it is asked to allocate
+type fakeAllocator struct {
+	allocated []string
+}
+
+func (f *fakeAllocator) allocateBMH(_ context.Context, _ *pluginv1alpha1.HardwareManager, bmh *metal3v1alpha1.BareMetalHost,
+	_ *hwmgmtv1alpha1.NodePool, _ hwmgmtv1alpha1.NodeGroup) error {
+	f.allocated = append(f.allocated, bmh.Name)
+	return nil
+}
+
+// fakeUpdater records the hardware profiles it is asked to apply, reporting whether an update was started
+type fakeUpdater struct {
+	updating bool
+	profiles []string
+}
+
+func (f *fakeUpdater) updateBMHProfile(_ context.Context, _ *pluginv1alpha1.HardwareManager, _ *metal3v1alpha1.BareMetalHost,
+	_, _, profileName string, _ bool) (bool, error) {
+	f.profiles = append(f.profiles, profileName)
+	return f.updating, nil
+}
+
+// fakeFinalizer records the BMHs it is asked to release
+type fakeFinalizer struct {
+	released []string
+}
+
+func (f *fakeFinalizer) releaseBMH(_ context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
+	f.released = append(f.released, bmh.Name)
+	return nil
+}
+
+func newLifecycleTestBMH() *metal3v1alpha1.BareMetalHost {
+	return &metal3v1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bmh1",
+			Namespace: testBMHNamespace,
+			Labels:    map[string]string{LabelSiteID: "site1"},
+		},
+		Status: metal3v1alpha1.BareMetalHostStatus{HardwareDetails: &metal3v1alpha1.HardwareDetails{}},
+	}
+}
+
+func newLifecycleTestNodePool() *hwmgmtv1alpha1.NodePool {
+	return &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "oran-hwmgr-plugin", UID: "np1-uid"},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID:      "cloud1",
+			HwMgrId:      "hwmgr1",
+			LocationSpec: hwmgmtv1alpha1.LocationSpec{Site: "site1"},
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "worker", HwProfile: "profile-v1"}, Size: 1},
+			},
+		},
+	}
+}
+
+func TestAllocateBMHWithUpdater(t *testing.T) {
+	ctx := context.Background()
+	bmh := newLifecycleTestBMH()
+	nodepool := newLifecycleTestNodePool()
+	hwmgr := &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: "hwmgr1", Namespace: "oran-hwmgr-plugin"}}
+	a := newTestAdaptor(t, bmh, nodepool, hwmgr)
+	updater := &fakeUpdater{}
+	a.updater = updater
+
+	if err := a.allocator.allocateBMH(ctx, hwmgr, bmh, nodepool, nodepool.Spec.NodeGroup[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(updater.profiles, []string{"profile-v1"}) {
+		t.Errorf("expected the hardware profile of the group to be applied, got %v", updater.profiles)
+	}
+	if len(nodepool.Status.Properties.NodeNames) != 1 {
+		t.Fatalf("expected one node recorded in the NodePool, got %v", nodepool.Status.Properties.NodeNames)
+	}
+
+	node := &hwmgmtv1alpha1.Node{}
+	nodeName := types.NamespacedName{Name: nodepool.Status.Properties.NodeNames[0], Namespace: a.Namespace}
+	if err := a.Client.Get(ctx, nodeName, node); err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+	if node.Spec.HwMgrNodeId != bmh.Name || node.Spec.HwProfile != "profile-v1" {
+		t.Errorf("unexpected node spec: %+v", node.Spec)
+	}
+	if cond := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)); cond == nil ||
+		cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected the node to be provisioned, got %+v", cond)
+	}
+
+	allocated := &metal3v1alpha1.BareMetalHost{}
+	if err := a.Client.Get(ctx, client.ObjectKeyFromObject(bmh), allocated); err != nil {
+		t.Fatalf("failed to get BMH: %v", err)
+	}
+	if allocated.Labels[BmhAllocatedLabel] != ValueTrue {
+		t.Errorf("expected the BMH to be marked allocated")
+	}
+	if getBMHClaim(allocated).NodeName != nodeName.Name {
+		t.Errorf("expected the BMH to be claimed for node %s", nodeName.Name)
+	}
+}
+
+func TestInitiateNodeUpdateWithUpdater(t *testing.T) {
+	ctx := context.Background()
+	bmh := newLifecycleTestBMH()
+	node := &hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: "oran-hwmgr-plugin"},
+		Spec: hwmgmtv1alpha1.NodeSpec{
+			NodePool:    "np1",
+			HwProfile:   "profile-v1",
+			HwMgrNodeId: bmh.Name,
+			HwMgrNodeNs: bmh.Namespace,
+		},
+	}
+	a := newTestAdaptor(t, bmh, node)
+	updater := &fakeUpdater{updating: true}
+	a.updater = updater
+
+	if _, err := a.initiateNodeUpdate(ctx, &pluginv1alpha1.HardwareManager{}, node, "profile-v2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(updater.profiles, []string{"profile-v2"}) {
+		t.Errorf("expected the new hardware profile to be applied, got %v", updater.profiles)
+	}
+
+	updated := &hwmgmtv1alpha1.Node{}
+	if err := a.Client.Get(ctx, client.ObjectKeyFromObject(node), updated); err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+	if updated.Spec.HwProfile != "profile-v2" {
+		t.Errorf("expected the node to have the new profile, got %s", updated.Spec.HwProfile)
+	}
+	cond := meta.FindStatusCondition(updated.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+	if cond == nil || cond.Reason != string(hwmgmtv1alpha1.ConfigUpdate) {
+		t.Errorf("expected the node to be updating, got %+v", cond)
+	}
+
+	configuring := &metal3v1alpha1.BareMetalHost{}
+	if err := a.Client.Get(ctx, client.ObjectKeyFromObject(bmh), configuring); err != nil {
+		t.Fatalf("failed to get BMH: %v", err)
+	}
+	if _, exists := configuring.Annotations[BmhDay2ConfigAnnotation]; !exists {
+		t.Errorf("expected the BMH to be annotated for the day-2 update")
+	}
+}
+
+func TestReserveBMHsReleasesUnneededClaims(t *testing.T) {
+	ctx := context.Background()
+	bmh := newLifecycleTestBMH()
+	nodepool := newLifecycleTestNodePool()
+	a := newTestAdaptor(t, bmh, nodepool)
+
+	if _, err := a.claimBMH(ctx, bmh, nodepool, "removed"); err != nil {
+		t.Fatalf("failed to claim BMH: %v", err)
+	}
+	finalizer := &fakeFinalizer{}
+	a.finalizer = finalizer
+
+	if _, _, err := a.reserveBMHs(ctx, &pluginv1alpha1.HardwareManager{}, nodepool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(finalizer.released, []string{bmh.Name}) {
+		t.Errorf("expected the BMH reserved for a removed group to be released, got %v", finalizer.released)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/template"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BMHMetadataTemplateExtension is the NodePool extension holding a template of the labels and annotations added
+	// to each BMH allocated to the NodePool, rendered as YAML with the labels and annotations fields
+	BMHMetadataTemplateExtension = "bmhMetadataTemplate"
+
+	// BmhAppliedMetadataAnnotation records the keys of the labels and annotations added to a BMH from the NodePool
+	// template, so that they can be removed when the BMH is released
+	BmhAppliedMetadataAnnotation = "hwmgr-plugin.oran.openshift.io/applied-metadata"
+
+	// bmhReservedDomain is the domain of the labels and annotations used by the plugin to track and select BMHs
+	bmhReservedDomain = "oran.openshift.io"
+)
+
+// bmhMetadataTemplateInput is the data available to the BMH metadata template when rendering it for a node
+type bmhMetadataTemplateInput struct {
+	NodeName  string
+	GroupName string
+	NodePool  string
+	CloudID   string
+	Site      string
+}
+
+// bmhAppliedMetadata is the content of the BmhAppliedMetadataAnnotation
+type bmhAppliedMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// isBMHMetadataKeyReserved checks whether a label or annotation key is in a domain used by the plugin, which the
+// template may not set, as it could change how the BMH is selected or handled
+func isBMHMetadataKeyReserved(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	return found && (prefix == bmhReservedDomain || strings.HasSuffix(prefix, "."+bmhReservedDomain))
+}
+
+// renderBMHMetadata renders the BMH metadata template of the NodePool for a node, returning nil if there is none
+func renderBMHMetadata(nodepool *hwmgmtv1alpha1.NodePool, input bmhMetadataTemplateInput) (*pluginv1alpha1.NodeMetadata, error) {
+	text := nodepool.Spec.Extensions[BMHMetadataTemplateExtension]
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(BMHMetadataTemplateExtension).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, typederrors.NewInputError("invalid %s extension: %s", BMHMetadataTemplateExtension, err.Error())
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, input); err != nil {
+		return nil, typederrors.NewInputError("invalid %s extension: %s", BMHMetadataTemplateExtension, err.Error())
+	}
+
+	metadata := &pluginv1alpha1.NodeMetadata{}
+	if err := yaml.UnmarshalStrict(rendered.Bytes(), metadata); err != nil {
+		return nil, typederrors.NewInputError("invalid %s extension: %s", BMHMetadataTemplateExtension, err.Error())
+	}
+
+	var errs []string
+	for key, value := range metadata.Labels {
+		errs = append(errs, validation.IsQualifiedName(key)...)
+		errs = append(errs, validation.IsValidLabelValue(value)...)
+		if isBMHMetadataKeyReserved(key) {
+			errs = append(errs, fmt.Sprintf("label %s is reserved for the plugin", key))
+		}
+	}
+	for key := range metadata.Annotations {
+		errs = append(errs, validation.IsQualifiedName(strings.ToLower(key))...)
+		if isBMHMetadataKeyReserved(key) {
+			errs = append(errs, fmt.Sprintf("annotation %s is reserved for the plugin", key))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, typederrors.NewInputError("invalid %s extension: %s", BMHMetadataTemplateExtension, strings.Join(errs, ", "))
+	}
+
+	return metadata, nil
+}
+
+// ValidateBMHMetadataTemplate checks that the BMH metadata template of the NodePool, if any, renders valid labels and
+// annotations
+func (a *Adaptor) ValidateBMHMetadataTemplate(nodepool *hwmgmtv1alpha1.NodePool) error {
+	var groupName string
+	if len(nodepool.Spec.NodeGroup) > 0 {
+		groupName = nodepool.Spec.NodeGroup[0].NodePoolData.Name
+	}
+	_, err := renderBMHMetadata(nodepool, bmhMetadataTemplateInput{
+		NodeName:  utils.GenerateNodeName(),
+		GroupName: groupName,
+		NodePool:  nodepool.Name,
+		CloudID:   nodepool.Spec.CloudID,
+		Site:      nodepool.Spec.Site,
+	})
+	return err
+}
+
+// applyBMHMetadata adds the labels and annotations rendered from the NodePool template to an allocated BMH, recording
+// their keys on it. Keys already set on the BMH are left as they are, and are not removed on release. The metadata is
+// applied once, so it is not changed by a later update of the template.
+func (a *Adaptor) applyBMHMetadata(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	bmh *metal3v1alpha1.BareMetalHost,
+	nodeName, groupName string) error {
+
+	if _, exists := bmh.Annotations[BmhAppliedMetadataAnnotation]; exists {
+		return nil
+	}
+
+	metadata, err := renderBMHMetadata(nodepool, bmhMetadataTemplateInput{
+		NodeName:  nodeName,
+		GroupName: groupName,
+		NodePool:  nodepool.Name,
+		CloudID:   nodepool.Spec.CloudID,
+		Site:      nodepool.Spec.Site,
+	})
+	if err != nil || metadata == nil {
+		return err
+	}
+
+	applied := bmhAppliedMetadata{}
+	var mutations []utils.MetaMutation
+	for key, value := range metadata.Labels {
+		if _, exists := bmh.Labels[key]; exists {
+			continue
+		}
+		applied.Labels = append(applied.Labels, key)
+		mutations = append(mutations, utils.AddLabel(key, value))
+	}
+	for key, value := range metadata.Annotations {
+		if _, exists := bmh.Annotations[key]; exists {
+			continue
+		}
+		applied.Annotations = append(applied.Annotations, key)
+		mutations = append(mutations, utils.AddAnnotation(key, value))
+	}
+	sort.Strings(applied.Labels)
+	sort.Strings(applied.Annotations)
+
+	record, err := json.Marshal(applied)
+	if err != nil {
+		return fmt.Errorf("failed to encode applied metadata for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+	mutations = append(mutations, utils.AddAnnotation(BmhAppliedMetadataAnnotation, string(record)))
+
+	// The metadata and its record are set in a single patch, so that an interrupted allocation does not leave keys
+	// behind that would not be removed on release
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, mutations...); err != nil {
+		return fmt.Errorf("failed to add metadata to BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Added NodePool metadata to BMH",
+		slog.String("bmh", bmh.Namespace+"/"+bmh.Name),
+		slog.Any("labels", applied.Labels),
+		slog.Any("annotations", applied.Annotations))
+	return nil
+}
+
+// removeBMHMetadata removes the labels and annotations added to a released BMH from the NodePool template
+func (a *Adaptor) removeBMHMetadata(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
+	record, exists := bmh.Annotations[BmhAppliedMetadataAnnotation]
+	if !exists {
+		return nil
+	}
+
+	// A record that cannot be parsed is dropped, leaving the keys it named on the BMH
+	applied := bmhAppliedMetadata{}
+	if err := json.Unmarshal([]byte(record), &applied); err != nil {
+		a.Logger.WarnContext(ctx, "Unable to parse the metadata applied to BMH",
+			slog.String("bmh", bmh.Namespace+"/"+bmh.Name), slog.String("error", err.Error()))
+	}
+
+	mutations := []utils.MetaMutation{utils.RemoveAnnotation(BmhAppliedMetadataAnnotation)}
+	for _, key := range applied.Labels {
+		mutations = append(mutations, utils.RemoveLabel(key))
+	}
+	for _, key := range applied.Annotations {
+		mutations = append(mutations, utils.RemoveAnnotation(key))
+	}
+
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, mutations...); err != nil {
+		return fmt.Errorf("failed to remove metadata from BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// BMH lifecycle states counted in the transition metrics, other than the provisioning states reported by metal3.
+// Servicing and errors are reported by metal3 in the operational status, which takes precedence over the
+// provisioning state.
+const (
+	bmhLifecycleServicing = "servicing"
+	bmhLifecycleError     = "error"
+
+	// bmhLifecycleNone is reported for a BMH that has no provisioning state yet
+	bmhLifecycleNone = "none"
+)
+
+var bmhStateTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "hwmgr_plugin_metal3_bmh_state_transitions_total",
+	Help: "Number of transitions of the BareMetalHosts of a resource pool into a provisioning, preparing, servicing or error state",
+}, []string{"resource_pool", "site", "from", "to"})
+
+func init() {
+	metrics.Registry.MustRegister(bmhStateTransitions)
+}
+
+// countedBMHLifecycleStates are the states whose transitions are counted, as they track the progress and health of
+// provisioning
+var countedBMHLifecycleStates = map[string]bool{
+	string(metal3v1alpha1.StateProvisioning): true,
+	string(metal3v1alpha1.StatePreparing):    true,
+	bmhLifecycleServicing:                    true,
+	bmhLifecycleError:                        true,
+}
+
+// getBMHLifecycleState returns the lifecycle state of a BMH, as counted in the transition metrics
+func getBMHLifecycleState(bmh *metal3v1alpha1.BareMetalHost) string {
+	switch bmh.Status.OperationalStatus {
+	case metal3v1alpha1.OperationalStatusServicing:
+		return bmhLifecycleServicing
+	case metal3v1alpha1.OperationalStatusError:
+		return bmhLifecycleError
+	}
+	return string(bmh.Status.Provisioning.State)
+}
+
+// recordBMHStateTransition counts the transition of a BMH into a counted state. Only the BMHs of a resource pool are
+// counted, as these are the BMHs managed by the plugin.
+func recordBMHStateTransition(oldBMH, newBMH *metal3v1alpha1.BareMetalHost) {
+	pool, site := newBMH.Labels[LabelResourcePoolID], newBMH.Labels[LabelSiteID]
+	if pool == "" || site == "" {
+		return
+	}
+
+	from, to := getBMHLifecycleState(oldBMH), getBMHLifecycleState(newBMH)
+	if from == to || !countedBMHLifecycleStates[to] {
+		return
+	}
+
+	if from == "" {
+		from = bmhLifecycleNone
+	}
+	bmhStateTransitions.WithLabelValues(pool, site, from, to).Inc()
+}
+
+// setupBMHMetrics counts the state transitions of the BMHs from the BMH updates seen by the manager cache, so that
+// transitions are counted as they happen rather than sampled when the plugin handles a BMH
+func (a *Adaptor) setupBMHMetrics(mgr ctrl.Manager) error {
+	informer, err := mgr.GetCache().GetInformer(context.Background(), &metal3v1alpha1.BareMetalHost{})
+	if err != nil {
+		return fmt.Errorf("failed to get BMH informer: %w", err)
+	}
+
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldBMH, oldOk := oldObj.(*metal3v1alpha1.BareMetalHost)
+			newBMH, newOk := newObj.(*metal3v1alpha1.BareMetalHost)
+			if oldOk && newOk {
+				recordBMHStateTransition(oldBMH, newBMH)
+			}
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to add BMH metrics event handler: %w", err)
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
@@ -52,6 +53,7 @@ type HardwareManagerReconciler struct {
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
 func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
 	result = utils.DoNotRequeue()
 
 	// Fetch the CR:
@@ -77,10 +79,24 @@ func (r *HardwareManagerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return
 	}
 
-	ctx = logging.AppendCtx(ctx, slog.String("hwmgr", hwmgr.Name))
+	ctx = logging.WithHwMgr(ctx, hwmgr.Name)
 
 	hwmgr.Status.ObservedGeneration = hwmgr.Generation
 
+	if _, _, windowErr := utils.GetMaintenanceWindowStatus(hwmgr, time.Now()); windowErr != nil {
+		if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+			pluginv1alpha1.ConditionTypes.Validation,
+			pluginv1alpha1.ConditionReasons.Failed,
+			metav1.ConditionFalse,
+			windowErr.Error()); updateErr != nil {
+			err = fmt.Errorf("failed to update status for hardware manager (%s) with validation failure: %w", hwmgr.Name, updateErr)
+			return
+		}
+		r.Logger.ErrorContext(ctx, "HardwareManager CR has invalid maintenance window", slog.String("name", hwmgr.Name),
+			slog.String("error", windowErr.Error()))
+		return
+	}
+
 	// Configuration data is not currently needed for the metal3 adaptor
 	if updateErr := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
 		pluginv1alpha1.ConditionTypes.Validation,
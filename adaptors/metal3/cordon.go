@@ -0,0 +1,90 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// BmhExcludeLabel excludes a BMH from allocation, such as while it is in maintenance. It may be set directly by
+	// an administrator, or through the cordon annotation of the Node allocated to the BMH.
+	BmhExcludeLabel = "hwmgr-plugin.oran.openshift.io/exclude"
+
+	// BmhCordonedByAnnotation records the Node whose cordon annotation set the exclude label of a BMH, so that the
+	// label is only removed on uncordon when it was set by the plugin
+	BmhCordonedByAnnotation = "hwmgr-plugin.oran.openshift.io/cordoned-by"
+)
+
+// isBMHExcluded checks whether a BMH is excluded from allocation
+func isBMHExcluded(bmh *metal3v1alpha1.BareMetalHost) bool {
+	return bmh.Labels[BmhExcludeLabel] == ValueTrue
+}
+
+// filterExcludedBMHs filters out BareMetalHosts that are excluded from allocation
+func filterExcludedBMHs(bmhList metal3v1alpha1.BareMetalHostList) metal3v1alpha1.BareMetalHostList {
+	var filteredBMHs metal3v1alpha1.BareMetalHostList
+	for _, bmh := range bmhList.Items {
+		if !isBMHExcluded(&bmh) {
+			filteredBMHs.Items = append(filteredBMHs.Items, bmh)
+		}
+	}
+	return filteredBMHs
+}
+
+// syncNodeCordons excludes the BMH of each cordoned node of the NodePool from allocation, and lifts the exclusion set
+// by a node that is no longer cordoned
+func (a *Adaptor) syncNodeCordons(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if node.Spec.HwMgrNodeId == "" {
+			continue
+		}
+
+		bmh, err := a.getBMHForNode(ctx, node)
+		if err != nil {
+			return fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+		}
+		bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+		cordonedBy := bmh.GetAnnotations()[BmhCordonedByAnnotation]
+
+		switch {
+		case utils.IsNodeCordonRequested(node) && !isBMHExcluded(bmh):
+			a.Logger.InfoContext(ctx, "Cordoning node, excluding its BMH from allocation",
+				slog.String("node", node.Name), slog.String("bmh", bmhName.String()))
+			if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(BmhCordonedByAnnotation, node.Name)); err != nil {
+				return fmt.Errorf("failed to annotate BMH %s for cordon of node %s: %w", bmhName, node.Name, err)
+			}
+			if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddLabel(BmhExcludeLabel, ValueTrue)); err != nil {
+				return fmt.Errorf("failed to exclude BMH %s for cordon of node %s: %w", bmhName, node.Name, err)
+			}
+		case !utils.IsNodeCordonRequested(node) && cordonedBy == node.Name:
+			a.Logger.InfoContext(ctx, "Uncordoning node, lifting the exclusion of its BMH",
+				slog.String("node", node.Name), slog.String("bmh", bmhName.String()))
+			if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveLabel(BmhExcludeLabel)); err != nil {
+				return fmt.Errorf("failed to lift exclusion of BMH %s for node %s: %w", bmhName, node.Name, err)
+			}
+			if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(BmhCordonedByAnnotation)); err != nil {
+				return fmt.Errorf("failed to remove cordon annotation of BMH %s for node %s: %w", bmhName, node.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getDriftDetectionInterval returns the drift detection interval configured for the HardwareManager, or zero if
+// drift detection is disabled
+func getDriftDetectionInterval(hwmgr *pluginv1alpha1.HardwareManager) time.Duration {
+	if hwmgr.Spec.Metal3Data == nil || hwmgr.Spec.Metal3Data.DriftDetectionInterval == nil {
+		return 0
+	}
+	return hwmgr.Spec.Metal3Data.DriftDetectionInterval.Duration
+}
+
+// detectHwProfileDrift compares the BIOS settings and firmware versions reported for the BMH against the hardware
+// profile, returning the parts of the hardware that differ from the profile. Unlike IsBiosUpdateRequired and
+// IsFirmwareUpdateRequired, this leaves the HostFirmwareSettings and HostFirmwareComponents untouched.
+func (a *Adaptor) detectHwProfileDrift(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost,
+	hwProfile *pluginv1alpha1.HardwareProfile) ([]string, error) {
+
+	var drifted []string
+
+	if len(hwProfile.Spec.Bios.Attributes) > 0 {
+		hfs, err := a.getHostFirmwareSettings(ctx, bmh.Name, bmh.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if isChangeDetected(ctx, a.Logger, hwProfile.Spec.Bios.Attributes, hfs.Status.Settings) {
+			drifted = append(drifted, "BIOS settings")
+		}
+	}
+
+	if !hwProfile.Spec.BiosFirmware.IsEmpty() || !hwProfile.Spec.BmcFirmware.IsEmpty() {
+		hfc, err := a.getHostFirmwareComponents(ctx, bmh.Name, bmh.Namespace)
+		switch {
+		case errors.IsNotFound(err):
+			// The firmware in the profile has never been applied to this BMH
+			drifted = append(drifted, "firmware versions")
+		case err != nil:
+			return nil, err
+		default:
+			if _, changed := isVersionChangeDetected(ctx, a.Logger, &hfc.Status, hwProfile.Spec); changed {
+				drifted = append(drifted, "firmware versions")
+			}
+		}
+	}
+
+	return drifted, nil
+}
+
+// setNodeDriftCondition sets the Drifted condition on a node, if it has changed
+func (a *Adaptor) setNodeDriftCondition(ctx context.Context, node *hwmgmtv1alpha1.Node,
+	status metav1.ConditionStatus, reason pluginv1alpha1.ConditionReason, message string) error {
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.Drifted)
+	condition := meta.FindStatusCondition(node.Status.Conditions, conditionType)
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) && utils.ConditionMessage(condition) == message {
+		return nil
+	}
+
+	if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+		conditionType, status, string(reason), message); err != nil {
+		return fmt.Errorf("failed to set drift condition on node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// checkNodePoolDrift periodically checks the allocated nodes of a provisioned NodePool for drift from their hardware
+// profiles, when enabled on the HardwareManager. Drift is reported in the Drifted condition of each node. If the
+// profile of a drifted node allows auto-remediation, the update workflow is started to re-apply the profile, one node
+// at a time and only within the maintenance window and concurrent update limit of the HardwareManager, and the
+// NodePool is moved back to configuring so the workflow is driven to completion.
+func (a *Adaptor) checkNodePoolDrift(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	interval := getDriftDetectionInterval(hwmgr)
+	if interval <= 0 || !meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	// Other NodePool events retrigger this between checks, so track when the last check was done
+	key := client.ObjectKeyFromObject(nodepool).String()
+	if last, ok := a.driftChecks.Load(key); ok {
+		if elapsed := time.Since(last.(time.Time)); elapsed < interval {
+			return utils.RequeueWithCustomInterval(interval - elapsed), nil
+		}
+	}
+	a.driftChecks.Store(key, time.Now())
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	// Remediation is an update, so it is only started within the maintenance window
+	windowOpen, _, err := utils.GetMaintenanceWindowStatus(hwmgr, time.Now())
+	if err != nil {
+		a.Logger.WarnContext(ctx, "Unable to check maintenance window", slog.String("error", err.Error()))
+	}
+
+	// Nor is it started while the hardware manager is at its limit of concurrent updates
+	atUpdateLimit := false
+	if windowOpen {
+		slots, limited, err := a.managerUpdateSlots(ctx, hwmgr)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		atUpdateLimit = limited && slots == 0
+	}
+
+	hwProfiles := make(map[string]*pluginv1alpha1.HardwareProfile)
+	var remediate *hwmgmtv1alpha1.Node
+
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if utils.GetConfigAnnotation(node) != "" ||
+			!meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+			// Skip nodes with a configuration change in progress
+			continue
+		}
+
+		hwProfile, exists := hwProfiles[node.Spec.HwProfile]
+		if !exists {
+			var err error
+			hwProfile, err = utils.GetHardwareProfile(ctx, a.Client, a.Namespace, node.Spec.HwProfile)
+			if err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+			hwProfiles[node.Spec.HwProfile] = hwProfile
+		}
+
+		bmh, err := a.getBMHForNode(ctx, node)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+		}
+
+		drifted, err := a.detectHwProfileDrift(ctx, bmh, hwProfile)
+		if err != nil {
+			a.Logger.WarnContext(ctx, "Unable to check node for drift", slog.String("node", node.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		if len(drifted) == 0 {
+			if err := a.setNodeDriftCondition(ctx, node, metav1.ConditionFalse, pluginv1alpha1.ConditionReasons.Completed,
+				fmt.Sprintf("Hardware matches profile %s", hwProfile.Name)); err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+			continue
+		}
+
+		message := fmt.Sprintf("%s differ from profile %s", strings.Join(drifted, " and "), hwProfile.Name)
+		a.Logger.InfoContext(ctx, "Node has drifted from its hardware profile", slog.String("node", node.Name),
+			slog.String("drift", message))
+
+		reason := pluginv1alpha1.ConditionReasons.DriftDetected
+		switch {
+		case hwProfile.Spec.AutoRemediate && !windowOpen:
+			message += ", remediation awaiting maintenance window"
+		case hwProfile.Spec.AutoRemediate && atUpdateLimit:
+			message += ", remediation awaiting a free update slot"
+		case hwProfile.Spec.AutoRemediate && remediate == nil:
+			remediate = node
+			reason = pluginv1alpha1.ConditionReasons.InProgress
+			message = "Remediating: " + message
+		}
+		if err := a.setNodeDriftCondition(ctx, node, metav1.ConditionTrue, reason, message); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	if remediate != nil {
+		return a.remediateNodeDrift(ctx, hwmgr, nodepool, remediate)
+	}
+
+	return utils.RequeueWithCustomInterval(interval), nil
+}
+
+// remediateNodeDrift starts the update workflow to re-apply the current hardware profile of a drifted node
+func (a *Adaptor) remediateNodeDrift(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Remediating hardware profile drift", slog.String("node", node.Name),
+		slog.String("hwProfile", node.Spec.HwProfile))
+
+	result, err := a.initiateNodeUpdate(ctx, hwmgr, node, node.Spec.HwProfile)
+	if err != nil {
+		return result, fmt.Errorf("failed to remediate drift on node %s: %w", node.Name, err)
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+		hwmgmtv1alpha1.Configured, hwmgmtv1alpha1.ConfigUpdate, metav1.ConditionFalse,
+		fmt.Sprintf("Remediating hardware profile drift on node %s", node.Name)); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	// Check again as soon as the remediation completes, to clear the Drifted condition
+	a.driftChecks.Delete(client.ObjectKeyFromObject(nodepool).String())
+
+	return result, nil
+}
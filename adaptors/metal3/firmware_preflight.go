@@ -0,0 +1,212 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+const (
+	defaultFirmwarePreflightTimeout = 5 * time.Minute
+
+	// A successful check of a firmware image is reused for this long, so that updating the nodes of a NodePool does
+	// not download the same image for each node
+	firmwarePreflightCacheTTL = 10 * time.Minute
+)
+
+func getFirmwarePreflight(hwmgr *pluginv1alpha1.HardwareManager) *pluginv1alpha1.FirmwarePreflight {
+	if hwmgr == nil || hwmgr.Spec.Metal3Data == nil {
+		return nil
+	}
+	return hwmgr.Spec.Metal3Data.FirmwarePreflight
+}
+
+// getFirmwarePreflightClient builds the HTTP client used to check firmware images, with the CA bundle and proxy
+// configuration of the hardware manager
+func (a *Adaptor) getFirmwarePreflightClient(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (*http.Client, error) {
+	preflight := getFirmwarePreflight(hwmgr)
+	if preflight == nil {
+		preflight = &pluginv1alpha1.FirmwarePreflight{}
+	}
+
+	config := utils.OAuthClientConfig{Proxy: hwmgr.Spec.Proxy}
+	if preflight.CaBundleName != nil {
+		cm, err := utils.GetConfigmap(ctx, a.Client, *preflight.CaBundleName, hwmgr.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get firmware preflight CA bundle configmap: %w", err)
+		}
+		caBundle, err := utils.GetConfigMapField(cm, "ca-bundle.pem")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get firmware preflight CA bundle: %w", err)
+		}
+		config.CaBundle = []byte(caBundle)
+	}
+
+	transport, err := utils.GetTransportWithCaBundle(config, preflight.InsecureSkipTLSVerify, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firmware preflight transport: %w", err)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// firmwareCheckKey identifies a check of a firmware image, along with the checksum and signature it was verified
+// against
+func firmwareCheckKey(url string, fw pluginv1alpha1.Firmware) string {
+	key := url + "#" + strings.ToLower(fw.Checksum)
+	if fw.Signature != nil {
+		key += "#" + fw.Signature.URL + "#" + fw.Signature.PublicKeyName
+	}
+	return key
+}
+
+// checkFirmwareImages checks the firmware images of the updates before they are set on the HostFirmwareComponents,
+// as Metal3 does not verify them. Images with a checksum or signature in the hardware profile are always downloaded
+// and verified, while the other images are only checked to be reachable when enabled for the hardware manager. A
+// failed check is reported as invalid input.
+func (a *Adaptor) checkFirmwareImages(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	spec pluginv1alpha1.HardwareProfileSpec, updates []metal3v1alpha1.FirmwareUpdate) error {
+
+	if hwmgr == nil {
+		return nil
+	}
+
+	preflight := getFirmwarePreflight(hwmgr)
+	timeout := defaultFirmwarePreflightTimeout
+	if preflight != nil && preflight.Timeout != nil && preflight.Timeout.Duration > 0 {
+		timeout = preflight.Timeout.Duration
+	}
+
+	firmware := firmwareByComponent(spec)
+	var client *http.Client
+	for _, update := range updates {
+		fw := firmware[update.Component]
+		verify := fw.Checksum != "" || fw.Signature != nil
+		if preflight == nil && !verify {
+			continue
+		}
+
+		key := firmwareCheckKey(update.URL, fw)
+		if checked, ok := a.firmwareChecks.Load(key); ok && time.Since(checked.(time.Time)) < firmwarePreflightCacheTTL {
+			continue
+		}
+
+		if client == nil {
+			var err error
+			if client, err = a.getFirmwarePreflightClient(ctx, hwmgr); err != nil {
+				return err
+			}
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := a.checkFirmwareImage(checkCtx, client, hwmgr.Namespace, update.URL, fw, verify)
+		cancel()
+		if err != nil {
+			if typederrors.IsInputError(err) {
+				return err
+			}
+			return typederrors.NewInputError("failed to check %s firmware image %s: %v", update.Component, update.URL, err)
+		}
+
+		a.firmwareChecks.Store(key, time.Now())
+		a.Logger.InfoContext(ctx, "Checked firmware image",
+			slog.String("component", update.Component),
+			slog.String("url", update.URL),
+			slog.Bool("verified", verify))
+	}
+
+	return nil
+}
+
+// checkFirmwareImage requests a firmware image, downloading it to verify its checksum and signature if requested.
+// Otherwise, only the headers of the image are requested, falling back to a GET request for servers that do not
+// support HEAD requests. Images with a scheme other than http or https are skipped, unless they are to be verified.
+func (a *Adaptor) checkFirmwareImage(ctx context.Context, client *http.Client, namespace, imageURL string,
+	fw pluginv1alpha1.Firmware, verify bool) error {
+
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		if verify {
+			return fmt.Errorf("unable to download image with scheme %s for verification", parsed.Scheme)
+		}
+		return nil
+	}
+
+	method := http.MethodGet
+	if !verify {
+		method = http.MethodHead
+	}
+
+	resp, err := requestFirmwareImage(ctx, client, method, imageURL)
+	if err != nil {
+		return err
+	}
+	if method == http.MethodHead &&
+		(resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		if resp, err = requestFirmwareImage(ctx, client, http.MethodGet, imageURL); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	if !verify {
+		return nil
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	digest := hash.Sum(nil)
+
+	if fw.Checksum != "" {
+		if actual := hex.EncodeToString(digest); actual != strings.ToLower(fw.Checksum) {
+			return fmt.Errorf("checksum mismatch: expected %s, got %s", strings.ToLower(fw.Checksum), actual)
+		}
+	}
+
+	if fw.Signature != nil {
+		if err := a.verifyFirmwareSignature(ctx, client, namespace, fw.Signature, digest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func requestFirmwareImage(ctx context.Context, client *http.Client, method, imageURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,103 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+const (
+	firmwarePublicKeyField = "public-key.pem"
+
+	// Signatures are small, so anything larger than this is not a signature file
+	maxFirmwareSignatureSize = 64 * 1024
+)
+
+// getFirmwarePublicKey returns the public key provided by the configmap of a firmware signature
+func (a *Adaptor) getFirmwarePublicKey(ctx context.Context, namespace, name string) (crypto.PublicKey, error) {
+	cm, err := utils.GetConfigmap(ctx, a.Client, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key configmap: %w", err)
+	}
+	data, err := utils.GetConfigMapField(cm, firmwarePublicKeyField)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s field of configmap %s", firmwarePublicKeyField, name)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key in configmap %s: %w", name, err)
+	}
+	return key, nil
+}
+
+// downloadFirmwareSignature fetches a detached signature file
+func downloadFirmwareSignature(ctx context.Context, client *http.Client, signatureURL string) ([]byte, error) {
+	resp, err := requestFirmwareImage(ctx, client, http.MethodGet, signatureURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to get signature: unexpected response: %s", resp.Status)
+	}
+
+	signature, err := io.ReadAll(io.LimitReader(resp.Body, maxFirmwareSignatureSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signature: %w", err)
+	}
+	if len(signature) > maxFirmwareSignatureSize {
+		return nil, fmt.Errorf("signature exceeds %d bytes", maxFirmwareSignatureSize)
+	}
+	return signature, nil
+}
+
+// verifyFirmwareSignature verifies the detached signature of a firmware image against the SHA-256 digest of the image
+func (a *Adaptor) verifyFirmwareSignature(ctx context.Context, client *http.Client, namespace string,
+	sig *pluginv1alpha1.FirmwareSignature, digest []byte) error {
+
+	key, err := a.getFirmwarePublicKey(ctx, namespace, sig.PublicKeyName)
+	if err != nil {
+		return err
+	}
+
+	signature, err := downloadFirmwareSignature(ctx, client, sig.URL)
+	if err != nil {
+		return err
+	}
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return fmt.Errorf("signature verification failed")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T in configmap %s", key, sig.PublicKeyName)
+	}
+
+	return nil
+}
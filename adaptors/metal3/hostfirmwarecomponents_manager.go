@@ -22,7 +22,7 @@ import (
 	"k8s.io/client-go/util/retry"
 )
 
-// validateFirmwareUpdateSpec checks that the BIOS and firmware URLs are valid
+// validateFirmwareUpdateSpec checks that the BIOS and firmware URLs, and those of their signatures, are valid
 func validateFirmwareUpdateSpec(spec pluginv1alpha1.HardwareProfileSpec) error {
 
 	if spec.BiosFirmware.Version != "" {
@@ -41,10 +41,24 @@ func validateFirmwareUpdateSpec(spec pluginv1alpha1.HardwareProfileSpec) error {
 			return typederrors.NewInputError("invalid BMC firmware URL: %v", spec.BmcFirmware.URL)
 		}
 	}
+	if spec.BiosFirmware.Signature != nil && !utils.IsValidURL(spec.BiosFirmware.Signature.URL) {
+		return typederrors.NewInputError("invalid BIOS firmware signature URL: %v", spec.BiosFirmware.Signature.URL)
+	}
+	if spec.BmcFirmware.Signature != nil && !utils.IsValidURL(spec.BmcFirmware.Signature.URL) {
+		return typederrors.NewInputError("invalid BMC firmware signature URL: %v", spec.BmcFirmware.Signature.URL)
+	}
 
 	return nil
 }
 
+// firmwareByComponent returns the firmware of a hardware profile by HostFirmwareComponents component name
+func firmwareByComponent(spec pluginv1alpha1.HardwareProfileSpec) map[string]pluginv1alpha1.Firmware {
+	return map[string]pluginv1alpha1.Firmware{
+		"bios": spec.BiosFirmware,
+		"bmc":  spec.BmcFirmware,
+	}
+}
+
 func convertToFirmwareUpdates(spec pluginv1alpha1.HardwareProfileSpec) []metal3v1alpha1.FirmwareUpdate {
 	var updates []metal3v1alpha1.FirmwareUpdate
 
@@ -88,10 +102,7 @@ func (a *Adaptor) isHostFirmwareComponentsChangeDetectedAndValid(ctx context.Con
 func isVersionChangeDetected(ctx context.Context, logger *slog.Logger, status *metal3v1alpha1.HostFirmwareComponentsStatus,
 	spec pluginv1alpha1.HardwareProfileSpec) ([]metal3v1alpha1.FirmwareUpdate, bool) {
 
-	firmwareMap := map[string]pluginv1alpha1.Firmware{
-		"bios": spec.BiosFirmware,
-		"bmc":  spec.BmcFirmware,
-	}
+	firmwareMap := firmwareByComponent(spec)
 
 	var updates []metal3v1alpha1.FirmwareUpdate
 	updateRequired := false
@@ -163,12 +174,19 @@ func (a *Adaptor) updateHostFirmwareComponents(ctx context.Context, name types.N
 	})
 }
 
-func (a *Adaptor) IsFirmwareUpdateRequired(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, spec pluginv1alpha1.HardwareProfileSpec) (bool, error) {
+func (a *Adaptor) IsFirmwareUpdateRequired(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	bmh *metal3v1alpha1.BareMetalHost, spec pluginv1alpha1.HardwareProfileSpec) (bool, error) {
 	if err := validateFirmwareUpdateSpec(spec); err != nil {
 		return false, err
 	}
 
-	existingHFC, created, err := a.getOrCreateHostFirmwareComponents(ctx, bmh, spec)
+	// The updates and the checks of their images use the copies on the artifact cache, if enabled
+	spec, err := a.mirrorFirmwareSpec(ctx, hwmgr, spec)
+	if err != nil {
+		return false, err
+	}
+
+	existingHFC, created, err := a.getOrCreateHostFirmwareComponents(ctx, hwmgr, bmh, spec)
 	if err != nil {
 		return false, err
 	}
@@ -184,6 +202,10 @@ func (a *Adaptor) IsFirmwareUpdateRequired(ctx context.Context, bmh *metal3v1alp
 		return false, nil
 	}
 
+	if err := a.checkFirmwareImages(ctx, hwmgr, spec, updates); err != nil {
+		return false, err
+	}
+
 	if err := a.updateHostFirmwareComponents(ctx, types.NamespacedName{
 		Name:      existingHFC.Name,
 		Namespace: existingHFC.Namespace,
@@ -195,12 +217,15 @@ func (a *Adaptor) IsFirmwareUpdateRequired(ctx context.Context, bmh *metal3v1alp
 }
 
 // Retrieves existing HostFirmwareComponents or creates a new one if not found.
-func (a *Adaptor) getOrCreateHostFirmwareComponents(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost,
-	spec pluginv1alpha1.HardwareProfileSpec) (*metal3v1alpha1.HostFirmwareComponents, bool, error) {
+func (a *Adaptor) getOrCreateHostFirmwareComponents(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	bmh *metal3v1alpha1.BareMetalHost, spec pluginv1alpha1.HardwareProfileSpec) (*metal3v1alpha1.HostFirmwareComponents, bool, error) {
 
 	hfc, err := a.getHostFirmwareComponents(ctx, bmh.Name, bmh.Namespace)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			if err := a.checkFirmwareImages(ctx, hwmgr, spec, convertToFirmwareUpdates(spec)); err != nil {
+				return nil, false, err
+			}
 			newHFC, err := a.createHostFirmwareComponents(ctx, bmh, spec)
 			if err != nil {
 				return nil, false, fmt.Errorf("failed to create HostFirmwareComponents: %w", err)
@@ -0,0 +1,76 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isBMHInspected checks whether the hardware details of the BMH have been reported by inspection. The node interfaces
+// are built from these details, so a BMH is not allocated until they are available.
+func isBMHInspected(bmh metal3v1alpha1.BareMetalHost) bool {
+	return bmh.Status.HardwareDetails != nil
+}
+
+// filterInspectedBMHs splits the BareMetalHosts into those that have completed inspection and the names of those still
+// awaiting it
+func filterInspectedBMHs(bmhList metal3v1alpha1.BareMetalHostList) (metal3v1alpha1.BareMetalHostList, []string) {
+	var inspected metal3v1alpha1.BareMetalHostList
+	var awaiting []string
+	for _, bmh := range bmhList.Items {
+		if isBMHInspected(bmh) {
+			inspected.Items = append(inspected.Items, bmh)
+		} else {
+			awaiting = append(awaiting, bmh.Namespace+"/"+bmh.Name)
+		}
+	}
+	return inspected, awaiting
+}
+
+// updateNodePoolInspectionCondition reports the BMHs that allocation of the NodePool is waiting on to complete
+// inspection in the AwaitingInspection condition. The condition is only cleared if it was previously set.
+func (a *Adaptor) updateNodePoolInspectionCondition(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, awaiting []string) error {
+	conditionType := string(pluginv1alpha1.ConditionTypes.AwaitingInspection)
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, conditionType)
+
+	status := metav1.ConditionTrue
+	reason := pluginv1alpha1.ConditionReasons.InProgress
+	message := "Waiting for inspection of BareMetalHosts: " + strings.Join(awaiting, ", ")
+	if len(awaiting) == 0 {
+		if condition == nil || condition.Status == metav1.ConditionFalse {
+			return nil
+		}
+		status = metav1.ConditionFalse
+		reason = pluginv1alpha1.ConditionReasons.Completed
+		message = "Inspection completed"
+	} else {
+		a.Logger.InfoContext(ctx, "NodePool allocation is waiting for BMH inspection",
+			slog.String("nodepool", nodepool.Name),
+			slog.Any("bmhs", awaiting))
+	}
+
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) &&
+		utils.ConditionMessage(condition) == message {
+		return nil
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+		hwmgmtv1alpha1.ConditionType(conditionType), hwmgmtv1alpha1.ConditionReason(reason), status, message); err != nil {
+		return fmt.Errorf("failed to set inspection condition on NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
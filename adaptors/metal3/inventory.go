@@ -8,8 +8,11 @@ package metal3
 
 import (
 	"regexp"
+	"sort"
+	"strings"
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
 )
 
@@ -32,9 +35,24 @@ var REPatternResourceSelectorLabel = regexp.MustCompile(`^` + LabelPrefixResourc
 var emptyString = ""
 
 func getResourceInfoAdminState(bmh metal3v1alpha1.BareMetalHost) invserver.ResourceInfoAdminState {
+	if isBMHExcluded(&bmh) {
+		return invserver.ResourceInfoAdminStateLOCKED
+	}
 	return invserver.ResourceInfoAdminStateUNKNOWN
 }
 
+func getResourceInfoAllocated(bmh metal3v1alpha1.BareMetalHost) *bool {
+	allocated := bmh.Labels[BmhAllocatedLabel] == ValueTrue
+	return &allocated
+}
+
+func getResourceInfoCloudId(allocation *utils.ResourceAllocation) *string {
+	if allocation == nil || allocation.CloudID == "" {
+		return nil
+	}
+	return &allocation.CloudID
+}
+
 func getResourceInfoDescription(bmh metal3v1alpha1.BareMetalHost) string {
 	return emptyString
 }
@@ -77,6 +95,27 @@ func getResourceInfoName(bmh metal3v1alpha1.BareMetalHost) string {
 	return bmh.Name
 }
 
+func getResourceInfoNodeName(bmh metal3v1alpha1.BareMetalHost, allocation *utils.ResourceAllocation) *string {
+	if allocation != nil {
+		return &allocation.NodeName
+	}
+	if claim := getBMHClaim(&bmh); claim != nil {
+		// The BMH is claimed for a Node that is still being allocated
+		return &claim.NodeName
+	}
+	if nodeName := bmh.Annotations[NodeNameAnnotation]; nodeName != "" {
+		return &nodeName
+	}
+	return nil
+}
+
+func getResourceInfoNodePoolName(allocation *utils.ResourceAllocation) *string {
+	if allocation == nil || allocation.NodePoolName == "" {
+		return nil
+	}
+	return &allocation.NodePoolName
+}
+
 func getResourceInfoOperationalState(bmh metal3v1alpha1.BareMetalHost) invserver.ResourceInfoOperationalState {
 	return invserver.ResourceInfoOperationalStateUNKNOWN
 }
@@ -134,6 +173,57 @@ func getResourceInfoProcessors(bmh metal3v1alpha1.BareMetalHost) []invserver.Pro
 	return processors
 }
 
+func getNetworkInterfaceInfoLabels(bmh metal3v1alpha1.BareMetalHost, nic metal3v1alpha1.NIC) *[]string {
+	// Interface labels with MACs use - instead of :
+	hyphenatedMac := strings.ReplaceAll(nic.MAC, ":", "-")
+
+	labels := []string{}
+	for fullLabel, value := range bmh.Labels {
+		match := REPatternInterfaceLabel.FindStringSubmatch(fullLabel)
+		if len(match) != 2 {
+			continue
+		}
+
+		if value == nic.Name || strings.EqualFold(hyphenatedMac, value) {
+			labels = append(labels, match[1])
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	sort.Strings(labels)
+	return &labels
+}
+
+func getNetworkInterfaceInfoSpeed(nic metal3v1alpha1.NIC) *int {
+	if nic.SpeedGbps == 0 {
+		return nil
+	}
+
+	speed := nic.SpeedGbps * 1000
+	return &speed
+}
+
+func getResourceInfoInterfaces(bmh metal3v1alpha1.BareMetalHost) *[]invserver.NetworkInterfaceInfo {
+	if bmh.Status.HardwareDetails == nil {
+		return nil
+	}
+
+	interfaces := []invserver.NetworkInterfaceInfo{}
+	for _, nic := range bmh.Status.HardwareDetails.NIC {
+		interfaces = append(interfaces, invserver.NetworkInterfaceInfo{
+			Labels:     getNetworkInterfaceInfoLabels(bmh, nic),
+			MacAddress: nic.MAC,
+			Model:      &nic.Model,
+			Name:       &nic.Name,
+			Speed:      getNetworkInterfaceInfoSpeed(nic),
+		})
+	}
+	return &interfaces
+}
+
 func getResourceInfoResourceId(bmh metal3v1alpha1.BareMetalHost) string {
 	return emptyString
 }
@@ -153,11 +243,19 @@ func getResourceInfoSerialNumber(bmh metal3v1alpha1.BareMetalHost) string {
 	return emptyString
 }
 
-func getResourceInfoTags(bmh metal3v1alpha1.BareMetalHost) *[]string {
-	return nil
+func getResourceInfoTags(bmh metal3v1alpha1.BareMetalHost) *[]string { // nolint: gocritic
+	tags := getBMHTags(&bmh)
+	if len(tags) == 0 {
+		return nil
+	}
+	return &tags
 }
 
 func getResourceInfoUsageState(bmh metal3v1alpha1.BareMetalHost) invserver.ResourceInfoUsageState {
+	if isBMHExcluded(&bmh) {
+		// An excluded BMH has no capacity for new allocations
+		return invserver.BUSY
+	}
 	return invserver.UNKNOWN
 }
 
@@ -168,17 +266,23 @@ func getResourceInfoVendor(bmh metal3v1alpha1.BareMetalHost) string {
 	return emptyString
 }
 
-func getResourceInfo(bmh metal3v1alpha1.BareMetalHost) invserver.ResourceInfo {
+// getResourceInfo returns the inventory data of a BMH, along with its allocation, if it backs a Node
+func getResourceInfo(bmh metal3v1alpha1.BareMetalHost, allocation *utils.ResourceAllocation) invserver.ResourceInfo {
 	return invserver.ResourceInfo{
 		AdminState:       getResourceInfoAdminState(bmh),
+		Allocated:        getResourceInfoAllocated(bmh),
+		CloudId:          getResourceInfoCloudId(allocation),
 		Description:      getResourceInfoDescription(bmh),
 		GlobalAssetId:    getResourceInfoGlobalAssetId(bmh),
 		Groups:           getResourceInfoGroups(bmh),
 		HwProfile:        getResourceInfoResourceProfileId(bmh),
+		Interfaces:       getResourceInfoInterfaces(bmh),
 		Labels:           getResourceInfoLabels(bmh),
 		Memory:           getResourceInfoMemory(bmh),
 		Model:            getResourceInfoModel(bmh),
 		Name:             getResourceInfoName(bmh),
+		NodeName:         getResourceInfoNodeName(bmh, allocation),
+		NodePoolName:     getResourceInfoNodePoolName(allocation),
 		OperationalState: getResourceInfoOperationalState(bmh),
 		PartNumber:       getResourceInfoPartNumber(bmh),
 		PowerState:       getResourceInfoPowerState(bmh),
@@ -0,0 +1,45 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// bmhMetadataMigrations convert the BMH labels and annotations set by earlier plugin versions, by schema version
+var bmhMetadataMigrations = []utils.MetadataMigration{
+	{
+		Version:     1,
+		Description: "normalize the allocated label value",
+		Mutations:   utils.NormalizeBoolLabel(BmhAllocatedLabel),
+	},
+}
+
+// hasPluginMetadata checks whether the plugin has set labels or annotations on a BMH, so that BMHs the plugin has
+// never handled are left untouched by the migration
+func hasPluginMetadata(object client.Object) bool {
+	for _, keys := range []map[string]string{object.GetLabels(), object.GetAnnotations()} {
+		for key := range keys {
+			if isBMHMetadataKeyReserved(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MigrateMetadata brings the labels and annotations of the BMHs handled by the plugin to the current metadata schema
+func (a *Adaptor) MigrateMetadata(ctx context.Context, c client.Client) (utils.MetadataMigrationResult, error) {
+	// nolint: wrapcheck
+	return utils.MigrateObjectsMetadata(ctx, c, a.Logger, "BareMetalHost", &metal3v1alpha1.BareMetalHostList{},
+		hasPluginMetadata, bmhMetadataMigrations)
+}
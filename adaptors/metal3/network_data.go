@@ -0,0 +1,218 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"text/template"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// NetworkDataTemplateExtension is the NodePool extension naming the ConfigMap, in the plugin namespace, that
+	// holds the preprovisioning network data template for the allocated BMHs
+	NetworkDataTemplateExtension = "preprovisioningNetworkDataTemplate"
+	NetworkDataTemplateKey       = "nmstate"
+	NetworkDataSecretKey         = "nmstate"
+	NetworkDataAnnotation        = "hwmgr-plugin.oran.openshift.io/network-data"
+	NetworkDataNodePoolLabel     = "hwmgr-plugin.oran.openshift.io/nodepool"
+)
+
+// networkDataTemplateBMH is the BMH data available to the network data template
+type networkDataTemplateBMH struct {
+	Name           string
+	Namespace      string
+	BootMACAddress string
+	Labels         map[string]string
+	Annotations    map[string]string
+}
+
+// networkDataTemplateInput is the data available to the network data template when rendering it for a node
+type networkDataTemplateInput struct {
+	NodeName   string
+	GroupName  string
+	NodePool   string
+	CloudID    string
+	Site       string
+	BMH        networkDataTemplateBMH
+	Interfaces []*hwmgmtv1alpha1.Interface
+}
+
+func networkDataSecretName(bmh *metal3v1alpha1.BareMetalHost) string {
+	return bmh.Name + "-network-data"
+}
+
+// getNetworkDataTemplate loads and parses the network data template requested by the NodePool, returning nil if
+// none is requested
+func (a *Adaptor) getNetworkDataTemplate(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (*template.Template, error) {
+	name := nodepool.Spec.Extensions[NetworkDataTemplateExtension]
+	if name == "" {
+		return nil, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: a.Namespace}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, typederrors.NewInputError("network data template configmap %s not found", name)
+		}
+		return nil, fmt.Errorf("failed to get network data template configmap %s: %w", name, err)
+	}
+
+	data, exists := cm.Data[NetworkDataTemplateKey]
+	if !exists {
+		return nil, typederrors.NewInputError("network data template configmap %s is missing the %s key", name, NetworkDataTemplateKey)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(data)
+	if err != nil {
+		return nil, typederrors.NewInputError("failed to parse network data template %s: %s", name, err.Error())
+	}
+
+	return tmpl, nil
+}
+
+// ValidateNetworkDataTemplate checks that the network data template requested by the NodePool, if any, can be parsed
+func (a *Adaptor) ValidateNetworkDataTemplate(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	_, err := a.getNetworkDataTemplate(ctx, nodepool)
+	return err
+}
+
+// generateNetworkData renders the NodePool network data template for an allocated BMH into a secret in the BMH
+// namespace, and records the secret on the BMH so it can be applied once the hardware profile has been processed
+func (a *Adaptor) generateNetworkData(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	bmh *metal3v1alpha1.BareMetalHost,
+	nodeName, groupName string) error {
+
+	tmpl, err := a.getNetworkDataTemplate(ctx, nodepool)
+	if err != nil || tmpl == nil {
+		return err
+	}
+
+	input := networkDataTemplateInput{
+		NodeName:  nodeName,
+		GroupName: groupName,
+		NodePool:  nodepool.Name,
+		CloudID:   nodepool.Spec.CloudID,
+		Site:      nodepool.Spec.Site,
+		BMH: networkDataTemplateBMH{
+			Name:           bmh.Name,
+			Namespace:      bmh.Namespace,
+			BootMACAddress: bmh.Spec.BootMACAddress,
+			Labels:         bmh.Labels,
+			Annotations:    bmh.Annotations,
+		},
+		Interfaces: a.buildInterfacesFromBMH(nodepool, *bmh),
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, input); err != nil {
+		return typederrors.NewInputError("failed to render network data template %s for BMH %s/%s: %s",
+			tmpl.Name(), bmh.Namespace, bmh.Name, err.Error())
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkDataSecretName(bmh),
+			Namespace: bmh.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, a.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = make(map[string]string)
+		}
+		secret.Labels[NetworkDataNodePoolLabel] = nodepool.Name
+		secret.Data = map[string][]byte{NetworkDataSecretKey: rendered.Bytes()}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to create network data secret for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(NetworkDataAnnotation, secret.Name)); err != nil {
+		return fmt.Errorf("failed to save network data annotation to BMH (%s): %w", bmh.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Generated network data for BMH",
+		slog.String("bmh", bmh.Name),
+		slog.String("secret", secret.Name))
+	return nil
+}
+
+// applyBMHNetworkData replaces the preprovisioning network data of the BMH with the data generated for the NodePool,
+// clearing it if none was generated
+func (a *Adaptor) applyBMHNetworkData(ctx context.Context, name types.NamespacedName) error {
+	// nolint:wrapcheck
+	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		updatedBmh := &metal3v1alpha1.BareMetalHost{}
+
+		if err := a.Get(ctx, name, updatedBmh); err != nil {
+			return fmt.Errorf("failed to fetch BMH %s/%s: %w", name.Namespace, name.Name, err)
+		}
+
+		networkData := updatedBmh.Annotations[NetworkDataAnnotation]
+		if updatedBmh.Spec.PreprovisioningNetworkDataName != networkData {
+			updatedBmh.Spec.PreprovisioningNetworkDataName = networkData
+			return a.Client.Update(ctx, updatedBmh)
+		}
+		return nil
+	})
+}
+
+// releaseBMHNetworkData removes the network data generated for the NodePool from a released BMH
+func (a *Adaptor) releaseBMHNetworkData(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) error {
+	secretName := bmh.Annotations[NetworkDataAnnotation]
+	if secretName == "" {
+		return nil
+	}
+
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		updatedBmh := &metal3v1alpha1.BareMetalHost{}
+		if err := a.Get(ctx, bmhName, updatedBmh); err != nil {
+			return fmt.Errorf("failed to fetch BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+		}
+
+		if updatedBmh.Spec.PreprovisioningNetworkDataName == secretName {
+			updatedBmh.Spec.PreprovisioningNetworkDataName = ""
+		}
+		delete(updatedBmh.Annotations, NetworkDataAnnotation)
+		// nolint:wrapcheck
+		return a.Client.Update(ctx, updatedBmh)
+	}); err != nil {
+		return fmt.Errorf("failed to clear network data from BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: bmh.Namespace,
+		},
+	}
+	if err := a.Client.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete network data secret %s/%s: %w", bmh.Namespace, secretName, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Released network data for BMH",
+		slog.String("bmh", bmh.Name),
+		slog.String("secret", secretName))
+	return nil
+}
@@ -31,7 +31,8 @@ func (a *Adaptor) GetNodeList(ctx context.Context) (*hwmgmtv1alpha1.NodeList, er
 }
 
 // CreateNode creates a Node CR with specified attributes
-func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, cloudID, nodename, nodeId, nodeNs, groupname, hwprofile string) error {
+func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, cloudID, nodename, nodeId, nodeNs, groupname, hwprofile,
+	vendor string) error {
 	a.Logger.InfoContext(ctx, "Ensuring node exists",
 		slog.String("nodegroup name", groupname),
 		slog.String("nodename", nodename),
@@ -45,6 +46,11 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 	existing := &hwmgmtv1alpha1.Node{}
 	err := a.Client.Get(ctx, nodeKey, existing)
 	if err == nil {
+		// The Node is left from an earlier attempt at the allocation, unless the name is taken by another BMH
+		if existing.Spec.HwMgrNodeId != nodeId || existing.Spec.HwMgrNodeNs != nodeNs {
+			return fmt.Errorf("node %s already exists for BMH %s/%s", nodename,
+				existing.Spec.HwMgrNodeNs, existing.Spec.HwMgrNodeId)
+		}
 		a.Logger.InfoContext(ctx, "Node already exists, skipping create", slog.String("nodename", nodename))
 		return nil
 	}
@@ -75,6 +81,13 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 			HwMgrNodeId: nodeId,
 		},
 	}
+	utils.SetNodeVendor(node, vendor)
+	utils.AddNodeEvent(node, utils.NodeEventAllocated,
+		fmt.Sprintf("BMH %s/%s allocated to NodePool %s", nodeNs, nodeId, nodepool.Name))
+
+	if err := utils.ApplyHardwareManagerNodeMetadata(ctx, a.Client, a.Namespace, node); err != nil {
+		return err // nolint: wrapcheck
+	}
 
 	if err := a.Client.Create(ctx, node); err != nil {
 		return fmt.Errorf("failed to create Node: %w", err)
@@ -87,8 +100,7 @@ func (a *Adaptor) CreateNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodeP
 // UpdateNodeStatus updates a Node CR status field with additional node information
 func (a *Adaptor) UpdateNodeStatus(ctx context.Context, info bmhNodeInfo, nodename, hwprofile string, updating bool) error {
 	a.Logger.InfoContext(ctx, "Updating node", slog.String("nodename", nodename))
-	// nolint:wrapcheck
-	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+	err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
 		node := &hwmgmtv1alpha1.Node{}
 
 		if err := a.Get(ctx, types.NamespacedName{Name: nodename, Namespace: a.Namespace}, node); err != nil {
@@ -102,7 +114,7 @@ func (a *Adaptor) UpdateNodeStatus(ctx context.Context, info bmhNodeInfo, nodena
 			slog.Any("info", info))
 
 		node.Status.BMC = &hwmgmtv1alpha1.BMC{
-			Address:         info.BMC.Address,
+			Address:         utils.NormalizeBMCAddress(info.BMC.Address),
 			CredentialsName: info.BMC.CredentialsName,
 		}
 		node.Status.Interfaces = info.Interfaces
@@ -115,7 +127,7 @@ func (a *Adaptor) UpdateNodeStatus(ctx context.Context, info bmhNodeInfo, nodena
 			message = "Hardware configuration in progess"
 			status = metav1.ConditionFalse
 		}
-		utils.SetStatusCondition(&node.Status.Conditions,
+		utils.SetStatusCondition(ctx, &node.Status.Conditions,
 			string(hwmgmtv1alpha1.Provisioned),
 			string(reason),
 			status,
@@ -126,27 +138,49 @@ func (a *Adaptor) UpdateNodeStatus(ctx context.Context, info bmhNodeInfo, nodena
 		return a.Client.Status().Update(ctx, node)
 
 	})
+	if err != nil {
+		return fmt.Errorf("failed to update status of node %s: %w", nodename, err)
+	}
+	if updating {
+		return nil
+	}
+
+	if err := utils.RecordNodeEvent(ctx, a.Client, nodename, a.Namespace, utils.NodeEventProvisioned,
+		"Hardware profile "+hwprofile); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", nodename), slog.String("error", err.Error()))
+	}
+
+	// The hardware already matches the profile, so it is the revision to restore should a later update fail
+	if err := a.recordAppliedHwProfile(ctx, nodename, a.Namespace, hwprofile); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record applied hardware profile", slog.String("node", nodename), slog.String("error", err.Error()))
+	}
+	return nil
 }
 
 func (a *Adaptor) ApplyPostConfigUpdates(ctx context.Context, bmhName types.NamespacedName, node *hwmgmtv1alpha1.Node) error {
 
-	if err := a.clearBMHNetworkData(ctx, bmhName); err != nil {
-		return fmt.Errorf("failed to clearBMHNetworkData bmh (%+v): %w", bmhName, err)
+	if err := a.applyBMHNetworkData(ctx, bmhName); err != nil {
+		return fmt.Errorf("failed to applyBMHNetworkData bmh (%+v): %w", bmhName, err)
 	}
-	// nolint:wrapcheck
-	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+	if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
 		updatedNode := &hwmgmtv1alpha1.Node{}
 
 		if err := a.Get(ctx, types.NamespacedName{Name: node.Name, Namespace: node.Namespace}, updatedNode); err != nil {
 			return fmt.Errorf("failed to fetch Node: %w", err)
 		}
 
+		if reason := utils.GetConfigAnnotation(updatedNode); reason != "" {
+			_, completed := nodeUpdateEvents(reason)
+			utils.AddNodeEvent(updatedNode, completed, "Hardware profile "+updatedNode.Spec.HwProfile)
+		}
+		utils.AddNodeEvent(updatedNode, utils.NodeEventProvisioned, "Hardware profile "+updatedNode.Spec.HwProfile)
 		utils.RemoveConfigAnnotation(updatedNode)
+		removeUpdateProgressAnnotation(updatedNode)
 		if err := a.Client.Update(ctx, updatedNode); err != nil {
 			return fmt.Errorf("failed to remove annotation for node %s/%s: %w", updatedNode.Name, updatedNode.Namespace, err)
 		}
 
-		utils.SetStatusCondition(&updatedNode.Status.Conditions,
+		utils.SetStatusCondition(ctx, &updatedNode.Status.Conditions,
 			string(hwmgmtv1alpha1.Provisioned),
 			string(hwmgmtv1alpha1.Completed),
 			metav1.ConditionTrue,
@@ -156,7 +190,14 @@ func (a *Adaptor) ApplyPostConfigUpdates(ctx context.Context, bmhName types.Name
 		}
 
 		return nil
-	})
+	}); err != nil {
+		return fmt.Errorf("failed to finalize node %s: %w", node.Name, err)
+	}
+
+	if err := a.recordAppliedHwProfile(ctx, node.Name, node.Namespace, node.Spec.HwProfile); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record applied hardware profile", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+	return nil
 }
 
 func (a *Adaptor) SetNodeFailedStatus(
@@ -166,7 +207,7 @@ func (a *Adaptor) SetNodeFailedStatus(
 	message string,
 ) error {
 
-	utils.SetStatusCondition(&node.Status.Conditions, conditionType, string(hwmgmtv1alpha1.Failed), metav1.ConditionFalse, message)
+	utils.SetStatusCondition(ctx, &node.Status.Conditions, conditionType, string(hwmgmtv1alpha1.Failed), metav1.ConditionFalse, message)
 
 	if err := a.Client.Status().Update(ctx, node); err != nil {
 		a.Logger.ErrorContext(ctx, "Failed to update node status with failure",
@@ -176,6 +217,11 @@ func (a *Adaptor) SetNodeFailedStatus(
 		return fmt.Errorf("failed to set node failed status: %w", err)
 	}
 
+	if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError,
+		fmt.Sprintf("%s: %s", conditionType, message)); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+
 	a.Logger.InfoContext(ctx, "Node status set to failed",
 		slog.String("node", node.Name),
 		slog.String("conditionType", conditionType),
@@ -8,11 +8,13 @@ package metal3
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 
 	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
@@ -28,29 +30,42 @@ func contains(slice []string, value string) bool {
 	return false
 }
 
-// AllocateBMH assigns a BareMetalHost to a NodePool.
-func (a *Adaptor) allocateBMHToNodePool(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) error {
+// allocateBMHToNodePool assigns a BareMetalHost to a NodePool. The BMH is claimed before anything else is done, and
+// every following step can be repeated safely, so an allocation that fails part way through is completed by calling
+// this again for the same BMH.
+func (a *Adaptor) allocateBMHToNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, bmh *metal3v1alpha1.BareMetalHost,
+	nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) error {
 
 	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
-	nodeName := bmh.Annotations[NodeNameAnnotation]
-	if nodeName == "" {
-		nodeName = utils.GenerateNodeName()
-		if err := a.updateBMHMetaWithRetry(ctx, bmhName, "annotation", NodeNameAnnotation, nodeName, OpAdd); err != nil {
-			return fmt.Errorf("failed to save node name annotation to BMH (%s): %w", bmh.Name, err)
-		}
+	claim, err := a.claimBMH(ctx, bmh, nodepool, group.NodePoolData.Name)
+	if err != nil {
+		return fmt.Errorf("failed to claim BMH (%s): %w", bmh.Name, err)
 	}
+	nodeName := claim.NodeName
 
 	nodeId := bmh.Name
 	nodeNs := bmh.Namespace
 	cloudID := nodepool.Spec.CloudID // cluster name
 
-	// Ensure node is created
-	if err := a.CreateNode(ctx, nodepool, cloudID, nodeName, nodeId, nodeNs, group.NodePoolData.Name, group.NodePoolData.HwProfile); err != nil {
+	// Ensure node is created. A Node left by an earlier attempt is reused.
+	if err := a.CreateNode(ctx, nodepool, cloudID, nodeName, nodeId, nodeNs, group.NodePoolData.Name, group.NodePoolData.HwProfile,
+		getResourceInfoVendor(*bmh)); err != nil {
 		return fmt.Errorf("failed to create allocated node (%s): %w", nodeName, err)
 	}
 
+	// Add the labels and annotations requested by the NodePool
+	if err := a.applyBMHMetadata(ctx, nodepool, bmh, nodeName, group.NodePoolData.Name); err != nil {
+		return fmt.Errorf("failed to add metadata to BMH for node (%s): %w", nodeName, err)
+	}
+
+	// Generate the network data requested by the NodePool. It is applied once the hw profile has been processed, as the
+	// BMH may need its current network data to boot the ramdisk for any update.
+	if err := a.generateNetworkData(ctx, nodepool, bmh, nodeName, group.NodePoolData.Name); err != nil {
+		return fmt.Errorf("failed to generate network data for node (%s): %w", nodeName, err)
+	}
+
 	// Process HW profile
-	updating, err := a.processHwProfileWithHandledError(ctx, bmh, nodeName, a.Namespace, group.NodePoolData.HwProfile, false)
+	updating, err := a.updater.updateBMHProfile(ctx, hwmgr, bmh, nodeName, a.Namespace, group.NodePoolData.HwProfile, false)
 	if err != nil {
 		return fmt.Errorf("failed to process hw profile for node (%s): %w", nodeName, err)
 	}
@@ -76,8 +91,8 @@ func (a *Adaptor) allocateBMHToNodePool(ctx context.Context, bmh *metal3v1alpha1
 	}
 
 	if !updating {
-		if err := a.clearBMHNetworkData(ctx, types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}); err != nil {
-			return fmt.Errorf("failed to clear network data for BMH (%s/%s): %w", bmh.Name, bmh.Namespace, err)
+		if err := a.applyBMHNetworkData(ctx, types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}); err != nil {
+			return fmt.Errorf("failed to apply network data for BMH (%s/%s): %w", bmh.Name, bmh.Namespace, err)
 		}
 	}
 
@@ -86,8 +101,8 @@ func (a *Adaptor) allocateBMHToNodePool(ctx context.Context, bmh *metal3v1alpha1
 		nodepool.Status.Properties.NodeNames = append(nodepool.Status.Properties.NodeNames, nodeName)
 	}
 
-	// Clean up annotation
-	if err := a.updateBMHMetaWithRetry(ctx, bmhName, "annotation", NodeNameAnnotation, "", OpRemove); err != nil {
+	// Clean up the node name annotation of an allocation started before BMH claims were introduced
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(NodeNameAnnotation)); err != nil {
 		a.Logger.ErrorContext(ctx, "failed to clear node name annotation from BMH", slog.Any("bmh", bmhName), slog.String("error", err.Error()))
 	}
 
@@ -95,46 +110,90 @@ func (a *Adaptor) allocateBMHToNodePool(ctx context.Context, bmh *metal3v1alpha1
 }
 
 // ProcessNodePoolAllocation allocates BareMetalHosts to a NodePool while ensuring all BMHs are in the same namespace.
-func (a *Adaptor) ProcessNodePoolAllocation(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+func (a *Adaptor) ProcessNodePoolAllocation(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) error {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allocationErr error
+	var awaitingInspection []string
+
+	// Adopted BMHs are already provisioned, so they are only recorded rather than allocated
+	allocator := a.allocator
+	if isNodePoolAdoption(nodepool) {
+		allocator = a.adopter
+	}
+
+	// Complete the allocations interrupted by an earlier reconcile before counting the pending nodes, so that their
+	// BMHs are neither leaked nor replaced by additional ones
+	if err := a.resumeInterruptedAllocations(ctx, hwmgr, nodepool, allocator); err != nil {
+		return err
+	}
 
 	// Get the BMH namespace from an already allocated node in this pool
-	bmhNamespace, err := a.getNodePoolBMHNamespace(ctx, nodepool)
+	bmhNamespace, err := a.getNodePoolBMHNamespace(ctx, hwmgr, nodepool)
 	if err != nil {
 		return fmt.Errorf("unable to determine BMH namespace for pool %s: %w", nodepool.Name, err)
 	}
 
 	// Process allocation for each NodeGroup
+	var groups []nodeGroupResources
 	for _, nodeGroup := range nodepool.Spec.NodeGroup {
 		if nodeGroup.Size == 0 {
 			continue // Skip groups with size 0
 		}
 
+		// Calculate pending nodes for the group, less any removed from it. A group that is already complete needs no
+		// free BMHs.
+		pendingNodes := utils.GetNodeGroupAllocationSize(nodepool, nodeGroup) - a.countNodesInGroup(ctx, nodepool.Status.Properties.NodeNames, nodeGroup.NodePoolData.Name)
+		if pendingNodes <= 0 {
+			continue
+		}
+
 		// Retrieve only unallocated BMHs for the current site, resourcePoolId, and namespace
-		unallocatedBMHs, err := a.FetchBMHList(ctx, nodepool.Spec.Site, nodeGroup.NodePoolData, UnallocatedBMHs, bmhNamespace)
+		unallocatedBMHs, err := a.fetchCandidateBMHs(ctx, hwmgr, nodepool, nodeGroup.NodePoolData, bmhNamespace)
 		if err != nil {
 			return fmt.Errorf("unable to fetch unallocated BMHs for site=%s, nodegroup=%s: %w",
 				nodepool.Spec.Site, nodeGroup.NodePoolData.Name, err)
 		}
+		unallocatedBMHs, err = a.filterClaimedBMHs(ctx, nodepool, unallocatedBMHs)
+		if err != nil {
+			return fmt.Errorf("unable to check the claims of BMHs for nodegroup=%s: %w",
+				nodeGroup.NodePoolData.Name, err)
+		}
 
+		groups = append(groups, nodeGroupResources{
+			Name:     nodeGroup.NodePoolData.Name,
+			Free:     len(unallocatedBMHs.Items),
+			Required: pendingNodes,
+		})
 		if len(unallocatedBMHs.Items) == 0 {
+			if err := a.updateNodePoolResourceCondition(ctx, nodepool, groups); err != nil {
+				return err
+			}
 			return fmt.Errorf("no available nodes for site=%s, nodegroup=%s",
 				nodepool.Spec.Site, nodeGroup.NodePoolData.Name)
 		}
 
-		// Calculate pending nodes for the group
-		pendingNodes := nodeGroup.Size - a.countNodesInGroup(ctx, nodepool.Status.Properties.NodeNames, nodeGroup.NodePoolData.Name)
-		if pendingNodes <= 0 {
+		// BMHs are only allocated once inspection has reported their hardware details. If there are not enough
+		// inspected BMHs for the group, the NodePool waits for the inspection of the others to complete.
+		inspectedBMHs, awaiting := filterInspectedBMHs(unallocatedBMHs)
+		if len(inspectedBMHs.Items) < pendingNodes {
+			awaitingInspection = append(awaitingInspection, awaiting...)
+		}
+		if len(inspectedBMHs.Items) == 0 {
 			continue
 		}
 
+		// Order the candidates to spread the group across failure domains, if requested
+		candidates, err := a.orderBMHsForGroup(ctx, nodepool, nodeGroup, inspectedBMHs.Items)
+		if err != nil {
+			return fmt.Errorf("unable to select BMHs for nodegroup=%s: %w", nodeGroup.NodePoolData.Name, err)
+		}
+
 		// Shared counter to track remaining nodes needed
 		nodeCounter := pendingNodes
 
 		// Allocate multiple nodes concurrently within the group
-		for _, bmh := range unallocatedBMHs.Items {
+		for _, bmh := range candidates {
 			mu.Lock()
 			if nodeCounter <= 0 {
 				mu.Unlock()
@@ -149,7 +208,12 @@ func (a *Adaptor) ProcessNodePoolAllocation(ctx context.Context, nodepool *hwmgm
 				defer wg.Done()
 
 				// Allocate BMH to NodePool
-				err := a.allocateBMHToNodePool(ctx, bmh, nodepool, nodeGroup)
+				err := allocator.allocateBMH(ctx, hwmgr, bmh, nodepool, nodeGroup)
+				if errors.Is(err, errBMHClaimed) {
+					// Another NodePool took the BMH first, so the node is allocated from another BMH on a later reconcile
+					a.Logger.InfoContext(ctx, "BMH was claimed by another NodePool", slog.String("bmh", bmh.Name))
+					return
+				}
 				if err != nil {
 					mu.Lock()
 					if typederrors.IsInputError(err) {
@@ -175,18 +239,26 @@ func (a *Adaptor) ProcessNodePoolAllocation(ctx context.Context, nodepool *hwmgm
 		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
 	}
 
+	if err := a.updateNodePoolInspectionCondition(ctx, nodepool, awaitingInspection); err != nil {
+		return err
+	}
+
+	if err := a.updateNodePoolResourceCondition(ctx, nodepool, groups); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // getNodePoolBMHNamespace retrieves the namespace of an already allocated BMH in the given NodePool.
-func (a *Adaptor) getNodePoolBMHNamespace(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
+func (a *Adaptor) getNodePoolBMHNamespace(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (string, error) {
 	for _, nodeGroup := range nodepool.Spec.NodeGroup {
 		if nodeGroup.Size == 0 {
 			continue // Skip groups with size 0
 		}
 
 		// Fetch only allocated BMHs that match site and resourcePoolId
-		bmhList, err := a.FetchBMHList(ctx, nodepool.Spec.Site, nodeGroup.NodePoolData, AllocatedBMHs, "")
+		bmhList, err := a.FetchBMHList(ctx, hwmgr, nodepool.Spec.Site, nodeGroup.NodePoolData, AllocatedBMHs, "")
 		if err != nil {
 			return "", fmt.Errorf("unable to fetch allocated BMHs for nodegroup=%s: %w", nodeGroup.NodePoolData.Name, err)
 		}
@@ -0,0 +1,104 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// checkNodeRemoval removes the nodes of a provisioned NodePool named in its remove-nodes annotation or whose Node CR
+// has been deleted, releasing their BMHs. It returns whether any removal was handled, in which case the result and
+// error are returned to the reconciler.
+func (a *Adaptor) checkNodeRemoval(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, bool, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), true, fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	removals, err := utils.GetNodesToRemove(ctx, a.NoncachedClient, nodepool, nodelist)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), true, err
+	}
+	if len(removals) == 0 {
+		if err := utils.ClearNodeRemovalRequest(ctx, a.Client, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), true, err
+		}
+		return ctrl.Result{}, false, nil
+	}
+
+	for _, removal := range removals {
+		if err := a.removeNode(ctx, hwmgr, nodepool, removal); err != nil {
+			return utils.RequeueWithShortInterval(), true, err
+		}
+	}
+
+	if err := utils.ClearNodeRemovalRequest(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), true, err
+	}
+	return utils.DoNotRequeue(), true, nil
+}
+
+// removeNode releases the BMH of a node being removed from its NodePool, then drops the node from the NodePool. The
+// BMH of a Node CR that was deleted is found by its claim, which also gives the node group the removal is recorded for.
+func (a *Adaptor) removeNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	removal utils.NodeRemoval) error {
+
+	var bmh *metal3v1alpha1.BareMetalHost
+	if removal.Node != nil {
+		var err error
+		// There is nothing to release if the BMH has since been deleted
+		if bmh, err = a.getBMHForNode(ctx, removal.Node); k8serrors.IsNotFound(err) {
+			bmh = nil
+		} else if err != nil {
+			return fmt.Errorf("failed to get BMH for node %s: %w", removal.NodeName, err)
+		}
+	} else {
+		claimed, err := a.listClaimedBMHs(ctx, hwmgr, nodepool)
+		if err != nil {
+			return err
+		}
+		for i := range claimed {
+			if claim := getBMHClaim(&claimed[i]); claim.NodeName == removal.NodeName {
+				bmh = &claimed[i]
+				removal.GroupName = claim.NodeGroup
+				break
+			}
+		}
+	}
+
+	if bmh != nil {
+		a.Logger.InfoContext(ctx, "Releasing BMH of removed node",
+			slog.String("node", removal.NodeName),
+			slog.String("bmh", bmh.Namespace+"/"+bmh.Name))
+		if err := a.finalizer.releaseBMH(ctx, bmh); err != nil {
+			return err
+		}
+	}
+
+	if err := utils.CompleteNodeRemoval(ctx, a.Client, nodepool, removal); err != nil {
+		return err
+	}
+
+	a.Logger.InfoContext(ctx, "Node removed from NodePool", slog.String("node", removal.NodeName))
+	return nil
+}
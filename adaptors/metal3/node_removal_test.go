@@ -0,0 +1,189 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// newRemovalTestBMH returns a BMH allocated to a node of the worker group of the test NodePool
+func newRemovalTestBMH(t *testing.T, name, nodeName string) *metal3v1alpha1.BareMetalHost {
+	claim, err := json.Marshal(bmhClaim{
+		NodeName:          nodeName,
+		NodePool:          "np1",
+		NodePoolNamespace: "oran-hwmgr-plugin",
+		NodePoolUID:       "np1-uid",
+		NodeGroup:         "worker",
+	})
+	if err != nil {
+		t.Fatalf("failed to encode BMH claim: %v", err)
+	}
+	return &metal3v1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   testBMHNamespace,
+			Labels:      map[string]string{LabelSiteID: "site1", BmhAllocatedLabel: ValueTrue},
+			Annotations: map[string]string{BmhClaimAnnotation: string(claim), NodeNameAnnotation: nodeName},
+		},
+	}
+}
+
+func newRemovalTestNode(name, bmhName string) *hwmgmtv1alpha1.Node {
+	return &hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "oran-hwmgr-plugin"},
+		Spec: hwmgmtv1alpha1.NodeSpec{
+			NodePool:    "np1",
+			GroupName:   "worker",
+			HwMgrId:     "hwmgr1",
+			HwMgrNodeId: bmhName,
+			HwMgrNodeNs: testBMHNamespace,
+		},
+	}
+}
+
+func newRemovalTestBmcSecret(nodeName string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.BmcSecretName(nodeName),
+			Namespace: "oran-hwmgr-plugin",
+			Labels:    map[string]string{utils.BmcSecretNodePoolLabel: "np1-uid"},
+		},
+	}
+}
+
+// newRemovalTestNodePool returns a provisioned NodePool whose worker group of two nodes is fully allocated
+func newRemovalTestNodePool(annotations map[string]string) *hwmgmtv1alpha1.NodePool {
+	nodepool := newLifecycleTestNodePool()
+	nodepool.Annotations = annotations
+	nodepool.Spec.NodeGroup[0].Size = 2
+	nodepool.Status.Properties.NodeNames = []string{"node1", "node2"}
+	nodepool.Status.Conditions = []metav1.Condition{{
+		Type:               string(hwmgmtv1alpha1.Provisioned),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(hwmgmtv1alpha1.Completed),
+		LastTransitionTime: metav1.Now(),
+	}}
+	return nodepool
+}
+
+func TestCheckNodeRemoval(t *testing.T) {
+	tests := []struct {
+		description string
+		annotations map[string]string
+		// nodeDeleted deletes the Node CR of node1 instead of annotating the NodePool
+		nodeDeleted bool
+	}{
+		{
+			description: "node removed by annotation",
+			annotations: map[string]string{utils.NodePoolRemoveNodesAnnotation: "node1"},
+		},
+		{
+			description: "Node CR deleted",
+			nodeDeleted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			nodepool := newRemovalTestNodePool(test.annotations)
+			objects := []client.Object{
+				nodepool,
+				newRemovalTestBMH(t, "bmh1", "node1"),
+				newRemovalTestBMH(t, "bmh2", "node2"),
+				newRemovalTestNode("node2", "bmh2"),
+				newRemovalTestBmcSecret("node1"),
+				newRemovalTestBmcSecret("node2"),
+			}
+			if !test.nodeDeleted {
+				objects = append(objects, newRemovalTestNode("node1", "bmh1"))
+			}
+			a := newTestAdaptor(t, objects...)
+			hwmgr := &pluginv1alpha1.HardwareManager{ObjectMeta: metav1.ObjectMeta{Name: "hwmgr1", Namespace: "oran-hwmgr-plugin"}}
+
+			if _, handled, err := a.checkNodeRemoval(ctx, hwmgr, nodepool); err != nil || !handled {
+				t.Fatalf("expected the removal to be handled, got handled=%t, err=%v", handled, err)
+			}
+
+			released := &metal3v1alpha1.BareMetalHost{}
+			if err := a.Client.Get(ctx, client.ObjectKey{Name: "bmh1", Namespace: testBMHNamespace}, released); err != nil {
+				t.Fatalf("failed to get BMH: %v", err)
+			}
+			if _, exists := released.Labels[BmhAllocatedLabel]; exists {
+				t.Errorf("expected the BMH of the removed node to be released")
+			}
+			if getBMHClaim(released) != nil {
+				t.Errorf("expected the claim of the removed node to be cleared")
+			}
+			kept := &metal3v1alpha1.BareMetalHost{}
+			if err := a.Client.Get(ctx, client.ObjectKey{Name: "bmh2", Namespace: testBMHNamespace}, kept); err != nil {
+				t.Fatalf("failed to get BMH: %v", err)
+			}
+			if !a.isBMHAllocated(kept) {
+				t.Errorf("expected the BMH of the other node to stay allocated")
+			}
+
+			secret := &corev1.Secret{}
+			if err := a.Client.Get(ctx, client.ObjectKeyFromObject(newRemovalTestBmcSecret("node1")), secret); !errors.IsNotFound(err) {
+				t.Errorf("expected the bmc-secret of the removed node to be deleted, got %v", err)
+			}
+			if err := a.Client.Get(ctx, client.ObjectKeyFromObject(newRemovalTestBmcSecret("node2")), secret); err != nil {
+				t.Errorf("expected the bmc-secret of the other node to be kept, got %v", err)
+			}
+			node := &hwmgmtv1alpha1.Node{}
+			if err := a.Client.Get(ctx, client.ObjectKey{Name: "node1", Namespace: "oran-hwmgr-plugin"}, node); !errors.IsNotFound(err) {
+				t.Errorf("expected the Node CR of the removed node to be deleted, got %v", err)
+			}
+
+			updated := &hwmgmtv1alpha1.NodePool{}
+			if err := a.Client.Get(ctx, client.ObjectKeyFromObject(nodepool), updated); err != nil {
+				t.Fatalf("failed to get NodePool: %v", err)
+			}
+			if !slices.Equal(updated.Status.Properties.NodeNames, []string{"node2"}) {
+				t.Errorf("expected only node2 to remain in the NodePool, got %v", updated.Status.Properties.NodeNames)
+			}
+			if _, exists := updated.Annotations[utils.NodePoolRemoveNodesAnnotation]; exists {
+				t.Errorf("expected the remove-nodes annotation to be cleared")
+			}
+
+			// The removed node is not allocated again while the group keeps its size, such as when the NodePool is
+			// moved back to processing to replace another node
+			full, err := a.IsNodePoolFullyAllocated(ctx, hwmgr, updated)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !full {
+				t.Errorf("expected the NodePool to remain fully allocated after the removal")
+			}
+
+			// Once the requester changes the size of the group, it is allocated to the new size
+			for _, size := range []struct {
+				size int
+				full bool
+			}{{size: 1, full: true}, {size: 3, full: false}} {
+				updated.Spec.NodeGroup[0].Size = size.size
+				if full, err := a.IsNodePoolFullyAllocated(ctx, hwmgr, updated); err != nil || full != size.full {
+					t.Errorf("expected the NodePool resized to %d to be fully allocated: %t, got %t, err=%v",
+						size.size, size.full, full, err)
+				}
+			}
+		})
+	}
+}
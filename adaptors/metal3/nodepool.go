@@ -20,6 +20,7 @@ import (
 	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -34,11 +35,11 @@ func (a *Adaptor) CheckNodePoolProgress(
 		return false, err
 	}
 	if !full {
-		return false, a.ProcessNodePoolAllocation(ctx, nodepool)
+		return false, a.ProcessNodePoolAllocation(ctx, hwmgr, nodepool)
 	}
 	// Node is fully allocated
 	// check if there are any pending work such as bios configuring
-	if updating, err := a.checkForPendingUpdate(ctx, nodepool); err != nil {
+	if updating, err := a.checkForPendingUpdate(ctx, hwmgr, nodepool); err != nil {
 		return false, err
 	} else if updating {
 		return false, nil
@@ -51,6 +52,13 @@ func (a *Adaptor) HandleNodePoolCreate(
 	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
 
+	if utils.IsNodePoolDryRun(nodepool) {
+		return a.HandleNodePoolDryRun(ctx, hwmgr, nodepool)
+	}
+	if utils.IsNodePoolReservation(nodepool) {
+		return a.HandleNodePoolReservation(ctx, hwmgr, nodepool)
+	}
+
 	conditionType := hwmgmtv1alpha1.Provisioned
 	var conditionReason hwmgmtv1alpha1.ConditionReason
 	var conditionStatus metav1.ConditionStatus
@@ -80,6 +88,28 @@ func (a *Adaptor) HandleNodePoolCreate(
 	return utils.DoNotRequeue(), nil
 }
 
+// HandleNodePoolDryRun runs the checks done for a new NodePool and reports the outcome, without allocating any BMHs
+func (a *Adaptor) HandleNodePoolDryRun(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Handling dry run of NodePool request")
+
+	checkErr := utils.ValidateNodePoolHwProfiles(ctx, a.Client, a.Namespace, nodepool)
+	if checkErr == nil {
+		// ProcessNewNodePool only counts the free BMHs matching each nodegroup, so it is safe to run here
+		checkErr = a.ProcessNewNodePool(ctx, hwmgr, nodepool)
+	}
+
+	if err := utils.UpdateNodePoolDryRunCondition(ctx, a.Client, nodepool, checkErr, ""); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
 func (a *Adaptor) HandleNodePoolProcessing(
 	ctx context.Context,
 	hwmgr *pluginv1alpha1.HardwareManager,
@@ -102,6 +132,11 @@ func (a *Adaptor) HandleNodePoolProcessing(
 
 	if full {
 		a.Logger.InfoContext(ctx, "NodePool request is fully allocated")
+		clearNodePoolResourceMetrics(nodepool)
+
+		if provisioned, result, err := utils.CheckPostAllocationHook(ctx, a.Client, a.NoncachedClient, a.Namespace, nodepool); !provisioned {
+			return result, err
+		}
 
 		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
 			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.Completed, metav1.ConditionTrue, "Created"); err != nil {
@@ -130,22 +165,53 @@ func (a *Adaptor) ProcessNewNodePool(ctx context.Context,
 
 	a.Logger.InfoContext(ctx, "Processing ProcessNewNodePool request")
 
+	if err := a.ValidateNetworkDataTemplate(ctx, nodepool); err != nil {
+		return fmt.Errorf("invalid network data template: %w", err)
+	}
+
+	if err := a.ValidateBMHMetadataTemplate(nodepool); err != nil {
+		return fmt.Errorf("invalid BMH metadata template: %w", err)
+	}
+
 	// Check if enough resources are available for each NodeGroup
+	var groups []nodeGroupResources
 	for _, nodeGroup := range nodepool.Spec.NodeGroup {
 		if nodeGroup.Size == 0 {
 			continue // Skip groups with size 0
 		}
 
+		if _, err := getSpreadPolicy(nodepool, nodeGroup.NodePoolData.Name); err != nil {
+			return err
+		}
+
 		// Fetch unallocated BMHs for the specific site and poolID
-		bmhListForGroup, err := a.FetchBMHList(ctx, nodepool.Spec.Site, nodeGroup.NodePoolData, UnallocatedBMHs, "")
+		bmhListForGroup, err := a.fetchCandidateBMHs(ctx, hwmgr, nodepool, nodeGroup.NodePoolData, "")
 		if err != nil {
 			return fmt.Errorf("unable to fetch BMHs for nodegroup=%s: %w", nodeGroup.NodePoolData.Name, err)
 		}
 
-		// Ensure enough resources exist in the requested pool
-		if len(bmhListForGroup.Items) < nodeGroup.Size {
+		// BMHs reserved by another NodePool are not free, while those reserved by this one are held for it
+		bmhListForGroup, err = a.filterBMHsClaimedByOthers(ctx, nodepool, bmhListForGroup)
+		if err != nil {
+			return fmt.Errorf("unable to check the claims of BMHs for nodegroup=%s: %w", nodeGroup.NodePoolData.Name, err)
+		}
+
+		groups = append(groups, nodeGroupResources{
+			Name:     nodeGroup.NodePoolData.Name,
+			Free:     len(bmhListForGroup.Items),
+			Required: nodeGroup.Size,
+		})
+	}
+
+	if err := a.updateNodePoolResourceCondition(ctx, nodepool, groups); err != nil {
+		return err
+	}
+
+	// Ensure enough resources exist in the requested pool
+	for _, group := range groups {
+		if group.isShort() {
 			return fmt.Errorf("not enough free resources matching nodegroup=%s criteria: freenodes=%d, required=%d",
-				nodeGroup.NodePoolData.Name, len(bmhListForGroup.Items), nodeGroup.Size)
+				group.Name, group.Free, group.Required)
 		}
 	}
 
@@ -158,24 +224,45 @@ func (a *Adaptor) IsNodePoolFullyAllocated(ctx context.Context,
 	nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
 
 	for _, nodeGroup := range nodepool.Spec.NodeGroup {
+		// Nodes removed from the group are not allocated again until its size is changed
 		allocatedNodes := a.countNodesInGroup(ctx, nodepool.Status.Properties.NodeNames, nodeGroup.NodePoolData.Name)
-		if allocatedNodes < nodeGroup.Size {
+		if allocatedNodes < utils.GetNodeGroupAllocationSize(nodepool, nodeGroup) {
 			return false, nil // At least one group is not fully allocated
 		}
 	}
 	return true, nil
 }
 
-// handleInProgressUpdate checks for any node marked as having a configuration update in progress.
-// If a node is found and its associated BMH status indicates that the update has completed,
-// it updates the node status, clears the annotation, applies the post-change annotation, and
-// requeues immediately.
-func (a *Adaptor) handleInProgressUpdate(ctx context.Context, nodelist *hwmgmtv1alpha1.NodeList) (ctrl.Result, bool, error) {
-	node := utils.FindNodeConfigInProgress(nodelist)
-	if node == nil {
+// handleInProgressUpdate checks the nodes marked as having a configuration update in progress.
+// If the associated BMH status of a node indicates that the update has completed, it updates the
+// node status, clears the annotation, applies the post-change annotation, and requeues immediately.
+func (a *Adaptor) handleInProgressUpdate(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodelist *hwmgmtv1alpha1.NodeList) (ctrl.Result, bool, error) {
+	handled := false
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if utils.GetConfigAnnotation(node) == "" {
+			continue
+		}
+
+		res, completed, err := a.handleNodeInProgressUpdate(ctx, hwmgr, node)
+		if err != nil || completed {
+			return res, completed, err
+		}
+		handled = true
+	}
+
+	if !handled {
 		a.Logger.InfoContext(ctx, "No node found that is in progress")
 		return ctrl.Result{}, false, nil
 	}
+	return utils.RequeueWithMediumInterval(), true, nil
+}
+
+// handleNodeInProgressUpdate checks the progress of the configuration update of a single node, returning true if the
+// update has completed
+func (a *Adaptor) handleNodeInProgressUpdate(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node) (ctrl.Result, bool, error) {
 	a.Logger.InfoContext(ctx, "Node found that is in progress", slog.String("node", node.Name))
 	bmh, err := a.getBMHForNode(ctx, node)
 	if err != nil {
@@ -186,9 +273,19 @@ func (a *Adaptor) handleInProgressUpdate(ctx context.Context, nodelist *hwmgmtv1
 	if bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusOK {
 		a.Logger.InfoContext(ctx, "BMH update complete", slog.String("BMH", bmh.Name))
 
+		if isHwProfileRollbackInProgress(node) {
+			if err := a.completeNodeRollback(ctx, node); err != nil {
+				return ctrl.Result{}, true, err
+			}
+			if err := a.removePreChangeAnnotation(ctx, bmh); err != nil {
+				return ctrl.Result{}, true, fmt.Errorf("failed to apply post-change annotation for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+			}
+			return utils.RequeueImmediately(), true, nil
+		}
+
 		// Update the node's status to reflect the new hardware profile.
 		node.Status.HwProfile = node.Spec.HwProfile
-		utils.SetStatusCondition(&node.Status.Conditions,
+		utils.SetStatusCondition(ctx, &node.Status.Conditions,
 			string(hwmgmtv1alpha1.Configured),
 			string(hwmgmtv1alpha1.ConfigApplied),
 			metav1.ConditionTrue,
@@ -196,10 +293,17 @@ func (a *Adaptor) handleInProgressUpdate(ctx context.Context, nodelist *hwmgmtv1
 		if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
 			return ctrl.Result{}, true, fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
 		}
+		_, completed := nodeUpdateEvents(utils.GetConfigAnnotation(node))
+		utils.AddNodeEvent(node, completed, "Hardware profile "+node.Spec.HwProfile)
 		utils.RemoveConfigAnnotation(node)
+		removeUpdateProgressAnnotation(node)
+		delete(node.Annotations, utils.NodeUpdateErrorAnnotation)
 		if err := utils.CreateOrUpdateK8sCR(ctx, a.Client, node, nil, utils.PATCH); err != nil {
 			return ctrl.Result{}, true, fmt.Errorf("failed to clear annotation from node %s: %w", node.Name, err)
 		}
+		if err := a.recordAppliedHwProfile(ctx, node.Name, node.Namespace, node.Spec.HwProfile); err != nil {
+			a.Logger.ErrorContext(ctx, "failed to record applied hardware profile", slog.String("node", node.Name), slog.String("error", err.Error()))
+		}
 
 		// Apply the post-change annotation to indicate completion.
 		if err := a.removePreChangeAnnotation(ctx, bmh); err != nil {
@@ -211,6 +315,20 @@ func (a *Adaptor) handleInProgressUpdate(ctx context.Context, nodelist *hwmgmtv1
 
 	if bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError {
 		a.Logger.InfoContext(ctx, "BMH update failed", slog.String("BMH", bmh.Name))
+		if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError,
+			fmt.Sprintf("Hardware configuration of BMH %s/%s failed: %s", bmh.Namespace, bmh.Name, bmh.Status.ErrorMessage)); err != nil {
+			a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+		}
+
+		// Restore the last applied revision of the hardware profile, rather than leaving the node half-configured
+		rollingBack, err := a.rollbackNodeUpdate(ctx, node, bmh)
+		if err != nil {
+			a.Logger.ErrorContext(ctx, "failed to roll back node update", slog.String("node", node.Name), slog.String("error", err.Error()))
+		}
+		if rollingBack {
+			return ctrl.Result{}, false, nil
+		}
+
 		if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
 			string(hwmgmtv1alpha1.Configured), metav1.ConditionFalse,
 			string(hwmgmtv1alpha1.Failed), BmhServicingErr); err != nil {
@@ -220,11 +338,27 @@ func (a *Adaptor) handleInProgressUpdate(ctx context.Context, nodelist *hwmgmtv1
 	}
 
 	a.Logger.InfoContext(ctx, "BMH config in progress", slog.String("bmh", bmh.Name))
-	return utils.RequeueWithMediumInterval(), true, nil
+	timedOut, err := a.updateNodeProgress(ctx, hwmgr, node, bmh, string(hwmgmtv1alpha1.Configured))
+	if err != nil {
+		a.Logger.ErrorContext(ctx, "failed to update node progress", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+	if timedOut {
+		return ctrl.Result{}, false, fmt.Errorf("configuration of node %s timed out", node.Name)
+	}
+	return ctrl.Result{}, false, nil
+}
+
+// isNodeUpdating returns true if a configuration update has been started on the node and has not yet completed
+func isNodeUpdating(node *hwmgmtv1alpha1.Node) bool {
+	if utils.GetConfigAnnotation(node) != "" {
+		return true
+	}
+	cond := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+	return cond != nil && cond.Status == metav1.ConditionFalse && cond.Reason == string(hwmgmtv1alpha1.ConfigUpdate)
 }
 
 // initiateNodeUpdate starts the update process for the given node by processing the new hardware profile,
-func (a *Adaptor) initiateNodeUpdate(ctx context.Context, node *hwmgmtv1alpha1.Node,
+func (a *Adaptor) initiateNodeUpdate(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node,
 	newHwProfile string) (ctrl.Result, error) {
 
 	bmh, err := a.getBMHForNode(ctx, node)
@@ -241,7 +375,7 @@ func (a *Adaptor) initiateNodeUpdate(ctx context.Context, node *hwmgmtv1alpha1.N
 		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to apply pre-change annotation for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
 	}
 
-	updateRequired, err := a.processHwProfileWithHandledError(ctx, bmh, node.Name, node.Namespace, newHwProfile, true)
+	updateRequired, err := a.updater.updateBMHProfile(ctx, hwmgr, bmh, node.Name, node.Namespace, newHwProfile, true)
 	if err != nil {
 		return utils.DoNotRequeue(), err
 	}
@@ -250,8 +384,10 @@ func (a *Adaptor) initiateNodeUpdate(ctx context.Context, node *hwmgmtv1alpha1.N
 	// Copy the current node object for patching
 	patch := client.MergeFrom(node.DeepCopy())
 
-	// Set the new profile in the spec
+	// Set the new profile in the spec, clearing any rollback of a previous update
 	node.Spec.HwProfile = newHwProfile
+	delete(node.Annotations, HwProfileRollbackAnnotation)
+	delete(node.Annotations, utils.NodeUpdateErrorAnnotation)
 
 	if err = a.Client.Patch(ctx, node, patch); err != nil {
 		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to patch Node %s in namespace %s: %w", node.Name, node.Namespace, err)
@@ -276,6 +412,9 @@ func (a *Adaptor) initiateNodeUpdate(ctx context.Context, node *hwmgmtv1alpha1.N
 			string(hwmgmtv1alpha1.ConfigApplied), string(hwmgmtv1alpha1.ConfigSuccess)); err != nil {
 			a.Logger.ErrorContext(ctx, "failed to update node status", slog.String("node", node.Name), slog.String("error", err.Error()))
 		}
+		if err := a.recordAppliedHwProfile(ctx, node.Name, node.Namespace, newHwProfile); err != nil {
+			a.Logger.ErrorContext(ctx, "failed to record applied hardware profile", slog.String("node", node.Name), slog.String("error", err.Error()))
+		}
 		// No update required, so we can remove the pre-change annotation
 		if err := a.removePreChangeAnnotation(ctx, bmh); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to remove pre-change annotation for BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
@@ -286,6 +425,7 @@ func (a *Adaptor) initiateNodeUpdate(ctx context.Context, node *hwmgmtv1alpha1.N
 
 func (a *Adaptor) handleNodePoolConfiguring(
 	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
 	nodepool *hwmgmtv1alpha1.NodePool,
 ) (ctrl.Result, *hwmgmtv1alpha1.NodeList, error) {
 
@@ -296,32 +436,87 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		return ctrl.Result{}, nil, fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
 	}
 
-	// STEP 1: Look for the next node that requires an update.
+	strategy, err := utils.GetUpdateStrategy(nodepool)
+	if err != nil {
+		if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool, hwmgmtv1alpha1.Configured,
+			hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse, err.Error()); err != nil {
+			return utils.RequeueWithMediumInterval(), nil,
+				fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return utils.DoNotRequeue(), nil, fmt.Errorf("invalid update strategy for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	// STEP 1: Start updating the nodes that require an update, as allowed by the update strategy.
+	var pending []*hwmgmtv1alpha1.Node
+	newHwProfiles := make(map[string]string)
 	for _, nodegroup := range nodepool.Spec.NodeGroup {
-		newHwProfile := nodegroup.NodePoolData.HwProfile
-		node := utils.FindNextNodeToUpdate(nodelist, nodegroup.NodePoolData.Name, newHwProfile)
-		if node == nil {
-			// No node pending update in this nodegroup; continue to the next one.
-			continue
+		for _, node := range utils.FindNodesToUpdate(nodelist, nodegroup.NodePoolData.Name, nodegroup.NodePoolData.HwProfile) {
+			if utils.IsNodeCordonRequested(node) {
+				a.Logger.InfoContext(ctx, "Skipping update of cordoned node", slog.String("node", node.Name))
+				continue
+			}
+			pending = append(pending, node)
+			newHwProfiles[node.Name] = utils.GetNodeTargetHwProfile(node, nodegroup.NodePoolData.HwProfile)
 		}
+	}
+	// No further updates are started once a node has aborted the rollout, while those already started run to completion
+	aborting := findRolloutAbortingNode(nodepool, nodelist)
+	if aborting != nil {
+		a.Logger.InfoContext(ctx, "NodePool rollout aborted by node update error", slog.String("node", aborting.Name),
+			slog.Int("pending", len(pending)))
+		pending = nil
+	}
+	updating := 0
+	for i := range nodelist.Items {
+		if isNodeUpdating(&nodelist.Items[i]) {
+			updating++
+		}
+	}
 
-		// Initiate the update process for the selected node.
-		res, err := a.initiateNodeUpdate(ctx, node, newHwProfile)
-		return res, nodelist, err
+	var start []*hwmgmtv1alpha1.Node
+	held, wait, err := utils.HoldNodesForMaintenanceWindow(ctx, a.Client, hwmgr, pending)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), nodelist, err
+	}
+	if !held {
+		start, wait, err = utils.PlanNodeUpdates(ctx, a.Client, nodepool, strategy, pending, updating)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), nodelist, err
+		}
+		start, err = a.limitManagerUpdates(ctx, hwmgr, nodepool, start)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), nodelist, err
+		}
+	}
+	if len(start) > 0 {
+		result := utils.RequeueImmediately()
+		for _, node := range start {
+			if err := utils.ClearNodeAwaitingWindow(ctx, a.Client, node); err != nil {
+				return utils.RequeueWithShortInterval(), nodelist, err
+			}
+			res, err := a.initiateNodeUpdate(ctx, hwmgr, node, newHwProfiles[node.Name])
+			if err != nil {
+				return res, nodelist, err
+			}
+			if res.RequeueAfter > 0 {
+				result = res
+			}
+		}
+		return result, nodelist, nil
 	}
 
 	// STEP 2: Handle nodes in transition (from update-needed to update in-progress).
-	updating, err := a.handleTransitionNodes(ctx, nodelist, true)
+	transitioning, err := a.handleTransitionNodes(ctx, hwmgr, nodelist, true)
 	if err != nil {
 		return ctrl.Result{}, nodelist, fmt.Errorf("error handling transitioning nodes: %w", err)
 	}
-	if updating {
+	if transitioning {
 		// Return a short interval requeue to allow time for the transition
 		return utils.RequeueWithShortInterval(), nodelist, nil
 	}
 
-	// STEP 3: Process any node that is already in the update-in-progress state.
-	res, handled, err := a.handleInProgressUpdate(ctx, nodelist)
+	// STEP 3: Process the nodes that are already in the update-in-progress state.
+	res, handled, err := a.handleInProgressUpdate(ctx, hwmgr, nodelist)
 	if err != nil {
 		if !handled {
 			a.Logger.InfoContext(ctx, "Not handled", slog.String("error", err.Error()))
@@ -333,8 +528,27 @@ func (a *Adaptor) handleNodePoolConfiguring(
 		return res, nodelist, err
 	}
 
-	// STEP 4: If no nodes are pending updates, mark the NodePool as fully configured.
+	// STEP 4: Wait for the maintenance window or the next batch of nodes, if any remain to be updated.
+	if aborting != nil {
+		if updating > 0 {
+			return utils.RequeueWithShortInterval(), nodelist, nil
+		}
+		return utils.DoNotRequeue(), nodelist, nil
+	}
+	if wait > 0 {
+		a.Logger.InfoContext(ctx, "Pausing before the next node updates", slog.Duration("wait", wait),
+			slog.Bool("awaitingWindow", held))
+		return utils.RequeueWithCustomInterval(wait), nodelist, nil
+	}
+	if len(pending) > 0 || updating > 0 {
+		return utils.RequeueWithShortInterval(), nodelist, nil
+	}
+
+	// STEP 5: If no nodes are pending updates, mark the NodePool as fully configured.
 	a.Logger.InfoContext(ctx, "All nodes have been updated to new profile")
+	if err := utils.ClearUpdateBatch(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), nodelist, err
+	}
 
 	return ctrl.Result{}, nodelist, nil
 }
@@ -354,7 +568,7 @@ func (a *Adaptor) HandleNodePoolSpecChanged(
 		}
 	}
 
-	result, nodelist, err := a.handleNodePoolConfiguring(ctx, nodepool)
+	result, nodelist, err := a.handleNodePoolConfiguring(ctx, hwmgr, nodepool)
 	if nodelist != nil {
 		status, reason, message := utils.DeriveNodePoolStatusFromNodes(ctx, a.NoncachedClient, a.Logger, nodelist)
 
@@ -418,18 +632,18 @@ func (a *Adaptor) ReleaseNodePool(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("failed to get child nodes for Node Pool %s: %w", nodepool.Name, err)
 	}
+	released := make(map[types.NamespacedName]bool)
 	for _, node := range nodelist.Items {
 		bmh, err := a.getBMHForNode(ctx, &node)
 		if err != nil {
 			return fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
 		}
-		if err = a.unmarkBMHAllocated(ctx, bmh); err != nil {
-			return fmt.Errorf("failed to unmarkBMHAllocated: %w", err)
-		}
-		if err = a.removeMetal3Finalizer(ctx, bmh.Name, bmh.Namespace); err != nil {
-			return fmt.Errorf("failed to remove finalizer: %w", err)
+		released[types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}] = true
+		if err = a.finalizer.releaseBMH(ctx, bmh); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	// BMHs claimed by an allocation that never got as far as creating the Node have no child Node to find them by
+	return a.releaseClaimedBMHs(ctx, hwmgr, nodepool, released)
 }
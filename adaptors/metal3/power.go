@@ -0,0 +1,113 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// BmhPowerActionAnnotation records a reboot requested by the plugin on a BMH, distinguishing a reboot that has
+// completed from one that has yet to be requested
+const BmhPowerActionAnnotation = "hwmgr-plugin.oran.openshift.io/power-action-in-progress"
+
+// HandleNodePowerAction applies a power action to the BareMetalHost backing a node, returning true once the BMH
+// reports the resulting power state
+func (a *Adaptor) HandleNodePowerAction(
+	ctx context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	action string) (bool, error) {
+
+	bmh, err := a.getBMHForNode(ctx, node)
+	if err != nil {
+		return false, fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+	}
+
+	switch action {
+	case utils.PowerActionOn:
+		return a.setBMHOnline(ctx, bmh, true)
+	case utils.PowerActionOff:
+		return a.setBMHOnline(ctx, bmh, false)
+	case utils.PowerActionReboot:
+		return a.rebootBMH(ctx, bmh)
+	default:
+		return false, typederrors.NewInputError("unsupported power action %s", action)
+	}
+}
+
+// setBMHOnline sets the requested power state on the BMH, returning true once the BMH reports that state
+func (a *Adaptor) setBMHOnline(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, online bool) (bool, error) {
+	if bmh.Spec.Online != online {
+		bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+		if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+			updatedBmh := &metal3v1alpha1.BareMetalHost{}
+			if err := a.Get(ctx, bmhName, updatedBmh); err != nil {
+				return fmt.Errorf("failed to fetch BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+			}
+			updatedBmh.Spec.Online = online
+			// nolint:wrapcheck
+			return a.Client.Update(ctx, updatedBmh)
+		}); err != nil {
+			return false, fmt.Errorf("failed to set online=%t on BMH %s/%s: %w", online, bmh.Namespace, bmh.Name, err)
+		}
+
+		a.Logger.InfoContext(ctx, "Set BMH power state", slog.String("bmh", bmh.Name), slog.Bool("online", online))
+		return false, nil
+	}
+
+	if bmh.Status.PoweredOn != online {
+		a.Logger.InfoContext(ctx, "Waiting for BMH power state", slog.String("bmh", bmh.Name), slog.Bool("online", online))
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// rebootBMH requests a reboot of the BMH through the baremetal-operator reboot annotation, returning true once the
+// baremetal-operator has cleared the annotation and the BMH is powered on again
+func (a *Adaptor) rebootBMH(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost) (bool, error) {
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+
+	if bmh.Annotations[BmhPowerActionAnnotation] != utils.PowerActionReboot {
+		if !bmh.Spec.Online {
+			return false, typederrors.NewInputError("BMH %s/%s is powered off, use %s to power it on",
+				bmh.Namespace, bmh.Name, utils.PowerActionOn)
+		}
+
+		if err := a.updateBMHMetaWithRetry(ctx, bmhName,
+			utils.AddAnnotation(BmhRebootAnnotation, ""),
+			utils.AddAnnotation(BmhPowerActionAnnotation, utils.PowerActionReboot)); err != nil {
+			return false, fmt.Errorf("failed to request reboot of BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+		}
+
+		a.Logger.InfoContext(ctx, "Requested BMH reboot", slog.String("bmh", bmh.Name))
+		return false, nil
+	}
+
+	if _, rebooting := bmh.Annotations[BmhRebootAnnotation]; rebooting || !bmh.Status.PoweredOn {
+		a.Logger.InfoContext(ctx, "Waiting for BMH reboot", slog.String("bmh", bmh.Name))
+		return false, nil
+	}
+
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.RemoveAnnotation(BmhPowerActionAnnotation)); err != nil {
+		return false, fmt.Errorf("failed to clear power action from BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "BMH reboot completed", slog.String("bmh", bmh.Name))
+	return true, nil
+}
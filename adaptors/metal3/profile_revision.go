@@ -0,0 +1,213 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// As with the update progress, the revision of the hardware profile applied to a node is recorded in an annotation on
+// the Node, along with the settings of that revision, so that they can be re-applied if a later update fails.
+const (
+	AppliedHwProfileAnnotation  = "hwmgr-plugin.oran.openshift.io/applied-hw-profile"
+	HwProfileRollbackAnnotation = "hwmgr-plugin.oran.openshift.io/hw-profile-rollback"
+)
+
+// appliedHwProfile records a revision of a hardware profile that was successfully applied to a node
+type appliedHwProfile struct {
+	Name         string                  `json:"name"`
+	Revision     string                  `json:"revision"`
+	Generation   int64                   `json:"generation"`
+	AppliedAt    metav1.Time             `json:"appliedAt"`
+	Bios         pluginv1alpha1.Bios     `json:"bios,omitempty"`
+	BiosFirmware pluginv1alpha1.Firmware `json:"biosFirmware,omitempty"`
+	BmcFirmware  pluginv1alpha1.Firmware `json:"bmcFirmware,omitempty"`
+}
+
+// hwProfileRevision returns a hash of the settings a resolved hardware profile applies to the hardware, which
+// changes whenever the profile, or one of its base profiles, changes those settings
+func hwProfileRevision(spec pluginv1alpha1.HardwareProfileSpec) (string, error) {
+	data, err := json.Marshal(struct {
+		Bios         pluginv1alpha1.Bios     `json:"bios"`
+		BiosFirmware pluginv1alpha1.Firmware `json:"biosFirmware"`
+		BmcFirmware  pluginv1alpha1.Firmware `json:"bmcFirmware"`
+	}{spec.Bios, spec.BiosFirmware, spec.BmcFirmware})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal hardware profile settings: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// spec returns the recorded settings as a hardware profile spec, to be re-applied
+func (p *appliedHwProfile) spec() pluginv1alpha1.HardwareProfileSpec {
+	return pluginv1alpha1.HardwareProfileSpec{
+		Bios:         p.Bios,
+		BiosFirmware: p.BiosFirmware,
+		BmcFirmware:  p.BmcFirmware,
+	}
+}
+
+// getAppliedHwProfile returns the last hardware profile revision successfully applied to a node, or nil if none has
+// been recorded
+func getAppliedHwProfile(node *hwmgmtv1alpha1.Node) (*appliedHwProfile, error) {
+	data, exists := node.Annotations[AppliedHwProfileAnnotation]
+	if !exists {
+		return nil, nil
+	}
+
+	applied := &appliedHwProfile{}
+	if err := json.Unmarshal([]byte(data), applied); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation of node %s: %w", AppliedHwProfileAnnotation, node.Name, err)
+	}
+	return applied, nil
+}
+
+// isHwProfileRollbackInProgress returns true if the settings of the last applied hardware profile revision are being
+// re-applied to the node after a failed update
+func isHwProfileRollbackInProgress(node *hwmgmtv1alpha1.Node) bool {
+	_, exists := node.Annotations[HwProfileRollbackAnnotation]
+	return exists
+}
+
+// updateNodeMetaWithRetry changes the annotations of the latest version of a node, retrying on conflict
+func (a *Adaptor) updateNodeMetaWithRetry(ctx context.Context, name, namespace string, mutations ...utils.MetaMutation) error {
+	node := &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return utils.UpdateObjectMetaWithRetry(ctx, a.Client, node, mutations)
+}
+
+// recordAppliedHwProfile records the current revision of a hardware profile as successfully applied to a node, making
+// it the revision restored should a later update of the node fail
+func (a *Adaptor) recordAppliedHwProfile(ctx context.Context, nodeName, nodeNamespace, profileName string) error {
+	hwProfile, err := utils.GetHardwareProfile(ctx, a.Client, a.Namespace, profileName)
+	if err != nil {
+		return err
+	}
+
+	revision, err := hwProfileRevision(hwProfile.Spec)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(appliedHwProfile{
+		Name:         hwProfile.Name,
+		Revision:     revision,
+		Generation:   hwProfile.Generation,
+		AppliedAt:    metav1.Now(),
+		Bios:         hwProfile.Spec.Bios,
+		BiosFirmware: hwProfile.Spec.BiosFirmware,
+		BmcFirmware:  hwProfile.Spec.BmcFirmware,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal applied hardware profile for node %s: %w", nodeName, err)
+	}
+
+	if err := a.updateNodeMetaWithRetry(ctx, nodeName, nodeNamespace,
+		utils.AddAnnotation(AppliedHwProfileAnnotation, string(data)),
+		utils.RemoveAnnotation(HwProfileRollbackAnnotation)); err != nil {
+		return fmt.Errorf("failed to record applied hardware profile on node %s: %w", nodeName, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Recorded applied hardware profile", slog.String("node", nodeName),
+		slog.String("hwProfile", profileName), slog.String("revision", revision))
+	return nil
+}
+
+// rollbackNodeUpdate starts re-applying the last hardware profile revision recorded for a node after its update has
+// failed, rather than leaving the node with part of the new profile applied. It returns false if there is no revision
+// to restore, or a rollback has already been attempted for the failed update.
+func (a *Adaptor) rollbackNodeUpdate(ctx context.Context, node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost) (bool, error) {
+
+	if isHwProfileRollbackInProgress(node) {
+		return false, nil
+	}
+
+	applied, err := getAppliedHwProfile(node)
+	if err != nil || applied == nil {
+		return false, err
+	}
+
+	a.Logger.InfoContext(ctx, "Rolling back node to last applied hardware profile", slog.String("node", node.Name),
+		slog.String("hwProfile", applied.Name), slog.String("revision", applied.Revision))
+
+	// The firmware images of the revision have already been applied, so are not checked again
+	updateRequired, err := a.applyHwProfileSpec(ctx, nil, bmh, applied.spec(), true)
+	if err != nil {
+		return false, fmt.Errorf("failed to re-apply revision %s of HardwareProfile %s to BMH %s/%s: %w",
+			applied.Revision, applied.Name, bmh.Namespace, bmh.Name, err)
+	}
+	if !updateRequired {
+		// The hardware still has the settings of the last applied revision, so there is nothing to roll back
+		return false, nil
+	}
+
+	if err := a.updateNodeMetaWithRetry(ctx, node.Name, node.Namespace,
+		utils.AddAnnotation(HwProfileRollbackAnnotation, applied.Revision)); err != nil {
+		return false, fmt.Errorf("failed to annotate rollback on node %s: %w", node.Name, err)
+	}
+
+	message := fmt.Sprintf("Update to HardwareProfile %s failed, rolling back to revision %s of HardwareProfile %s",
+		node.Spec.HwProfile, applied.Revision, applied.Name)
+	if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+		string(hwmgmtv1alpha1.Configured), metav1.ConditionFalse,
+		string(hwmgmtv1alpha1.Failed), message); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to update node status", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+
+	return true, nil
+}
+
+// completeNodeRollback finalizes a node whose last applied hardware profile revision has been restored. The node
+// reports the restored profile, while remaining failed for the profile requested by the update.
+func (a *Adaptor) completeNodeRollback(ctx context.Context, node *hwmgmtv1alpha1.Node) error {
+	applied, err := getAppliedHwProfile(node)
+	if err != nil {
+		return err
+	}
+	if applied == nil {
+		return fmt.Errorf("node %s has no applied hardware profile to roll back to", node.Name)
+	}
+
+	message := fmt.Sprintf("Update to HardwareProfile %s failed, rolled back to revision %s of HardwareProfile %s",
+		node.Spec.HwProfile, applied.Revision, applied.Name)
+	node.Status.HwProfile = applied.Name
+	utils.SetStatusCondition(ctx, &node.Status.Conditions,
+		string(hwmgmtv1alpha1.Configured),
+		string(hwmgmtv1alpha1.Failed),
+		metav1.ConditionFalse,
+		message)
+	if err := utils.UpdateK8sCRStatus(ctx, a.Client, node); err != nil {
+		return fmt.Errorf("failed to update status for node %s: %w", node.Name, err)
+	}
+
+	if err := a.updateNodeMetaWithRetry(ctx, node.Name, node.Namespace,
+		utils.RemoveAnnotation(utils.ConfigAnnotation),
+		utils.RemoveAnnotation(UpdateProgressAnnotation),
+		utils.RemoveAnnotation(HwProfileRollbackAnnotation)); err != nil {
+		return fmt.Errorf("failed to clear annotations from node %s: %w", node.Name, err)
+	}
+	if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventRolledBack, message); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+
+	a.Logger.InfoContext(ctx, "Node rolled back to last applied hardware profile", slog.String("node", node.Name),
+		slog.String("hwProfile", applied.Name), slog.String("revision", applied.Revision))
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// NodeReinspectionStartedAnnotation records on a Node when the plugin requested the inspection of its BMH, so that
+	// hardware details reported by an earlier inspection are not taken as the result
+	NodeReinspectionStartedAnnotation = "hwmgr-plugin.oran.openshift.io/reinspection-started"
+
+	// NodeEventReasonReinspectionFailed is the reason of the Kubernetes event emitted for a failed inspection
+	NodeEventReasonReinspectionFailed = "ReinspectionFailed"
+)
+
+// checkNodeReinspection handles the inspections requested on the nodes of a provisioned NodePool, or on the NodePool
+// itself, returning the result to requeue with while any of them is in progress. BMH status changes do not trigger a
+// reconcile, so the inspections are polled.
+func (a *Adaptor) checkNodeReinspection(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	if utils.IsReinspectionRequested(nodepool) {
+		// Hand the request down to each node, so that it completes per node
+		for i := range nodelist.Items {
+			node := &nodelist.Items[i]
+			if !utils.IsReinspectionRequested(node) {
+				if err := utils.SetNodeAnnotation(ctx, a.Client, node, utils.NodeReinspectAnnotation, "true"); err != nil {
+					return utils.RequeueWithShortInterval(), fmt.Errorf("failed to request inspection of node %s: %w", node.Name, err)
+				}
+			}
+		}
+
+		updatedNodePool := &hwmgmtv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: nodepool.Name, Namespace: nodepool.Namespace}}
+		if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, updatedNodePool,
+			[]utils.MetaMutation{utils.RemoveAnnotation(utils.NodeReinspectAnnotation)}); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to clear inspection request from NodePool %s: %w", nodepool.Name, err)
+		}
+		nodepool.SetAnnotations(updatedNodePool.GetAnnotations())
+	}
+
+	result := ctrl.Result{}
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if !utils.IsReinspectionRequested(node) {
+			if _, exists := node.Annotations[NodeReinspectionStartedAnnotation]; exists {
+				// The request was withdrawn before the inspection completed
+				if err := utils.SetNodeAnnotation(ctx, a.Client, node, NodeReinspectionStartedAnnotation, ""); err != nil {
+					return utils.RequeueWithShortInterval(), fmt.Errorf("failed to clear inspection of node %s: %w", node.Name, err)
+				}
+			}
+			continue
+		}
+
+		completed, err := a.reinspectNode(ctx, nodepool, node)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		if !completed {
+			result = utils.RequeueWithMediumInterval()
+		}
+	}
+
+	return result, nil
+}
+
+// reinspectNode requests the inspection of the BMH backing a node, returning true once the inspection has completed and
+// the node interfaces have been refreshed from the new hardware details, or the inspection has failed
+func (a *Adaptor) reinspectNode(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, node *hwmgmtv1alpha1.Node) (bool, error) {
+	bmh, err := a.getBMHForNode(ctx, node)
+	if err != nil {
+		return false, fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+	}
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+
+	started, exists := node.Annotations[NodeReinspectionStartedAnnotation]
+	if !exists {
+		if bmh.Annotations[metal3v1alpha1.InspectAnnotationPrefix] == metal3v1alpha1.InspectAnnotationValueDisabled {
+			return true, a.failNodeReinspection(ctx, node,
+				fmt.Sprintf("Inspection is disabled on BMH %s/%s", bmh.Namespace, bmh.Name))
+		}
+
+		if err := a.updateBMHMetaWithRetry(ctx, bmhName,
+			utils.AddAnnotation(metal3v1alpha1.InspectAnnotationPrefix, "")); err != nil {
+			return false, fmt.Errorf("failed to request inspection of BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+		}
+
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, NodeReinspectionStartedAnnotation,
+			time.Now().UTC().Format(time.RFC3339)); err != nil {
+			return false, fmt.Errorf("failed to record inspection of node %s: %w", node.Name, err)
+		}
+
+		if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventReinspectionStarted,
+			fmt.Sprintf("Requested inspection of BMH %s/%s", bmh.Namespace, bmh.Name)); err != nil {
+			return false, err // nolint: wrapcheck
+		}
+
+		a.Logger.InfoContext(ctx, "Requested BMH inspection", slog.String("node", node.Name), slog.String("bmh", bmh.Name))
+		return false, nil
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, started)
+	if err != nil {
+		// Request the inspection again rather than trusting hardware details of unknown age
+		a.Logger.WarnContext(ctx, "Invalid inspection start time, restarting inspection",
+			slog.String("node", node.Name), slog.String("started", started))
+		if err := utils.SetNodeAnnotation(ctx, a.Client, node, NodeReinspectionStartedAnnotation, ""); err != nil {
+			return false, fmt.Errorf("failed to clear inspection of node %s: %w", node.Name, err)
+		}
+		return false, nil
+	}
+
+	if bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError &&
+		bmh.Status.ErrorType == metal3v1alpha1.InspectionError {
+		return true, a.failNodeReinspection(ctx, node,
+			fmt.Sprintf("Inspection of BMH %s/%s failed: %s", bmh.Namespace, bmh.Name, bmh.Status.ErrorMessage))
+	}
+
+	_, pending := bmh.Annotations[metal3v1alpha1.InspectAnnotationPrefix]
+	inspected := bmh.Status.OperationHistory.Inspect.End
+	if pending || bmh.Status.HardwareDetails == nil || inspected.IsZero() || inspected.Time.Before(startedAt) {
+		a.Logger.InfoContext(ctx, "Waiting for BMH inspection",
+			slog.String("node", node.Name),
+			slog.String("bmh", bmh.Name),
+			slog.String("state", string(bmh.Status.Provisioning.State)))
+		return false, nil
+	}
+
+	interfaces := a.buildInterfacesFromBMH(nodepool, *bmh)
+	if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		updatedNode := &hwmgmtv1alpha1.Node{}
+		if err := a.Get(ctx, types.NamespacedName{Name: node.Name, Namespace: node.Namespace}, updatedNode); err != nil {
+			return fmt.Errorf("failed to fetch Node: %w", err)
+		}
+		updatedNode.Status.Interfaces = interfaces
+		// nolint:wrapcheck
+		return a.Client.Status().Update(ctx, updatedNode)
+	}); err != nil {
+		return false, fmt.Errorf("failed to update interfaces of node %s: %w", node.Name, err)
+	}
+
+	if err := a.clearNodeReinspection(ctx, node); err != nil {
+		return false, err
+	}
+
+	if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventReinspectionCompleted,
+		fmt.Sprintf("Hardware details refreshed by inspection of BMH %s/%s", bmh.Namespace, bmh.Name)); err != nil {
+		return false, err // nolint: wrapcheck
+	}
+
+	a.Logger.InfoContext(ctx, "BMH inspection completed", slog.String("node", node.Name), slog.String("bmh", bmh.Name))
+	return true, nil
+}
+
+// failNodeReinspection ends an inspection request that cannot complete, recording the failure on the node
+func (a *Adaptor) failNodeReinspection(ctx context.Context, node *hwmgmtv1alpha1.Node, message string) error {
+	a.Logger.WarnContext(ctx, "BMH inspection failed", slog.String("node", node.Name), slog.String("message", message))
+
+	if err := a.clearNodeReinspection(ctx, node); err != nil {
+		return err
+	}
+
+	if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError, message); err != nil {
+		return err // nolint: wrapcheck
+	}
+
+	if a.recorder != nil {
+		a.recorder.Event(node, corev1.EventTypeWarning, NodeEventReasonReinspectionFailed, message)
+	}
+	return nil
+}
+
+// clearNodeReinspection removes the inspection request and its start time from a node
+func (a *Adaptor) clearNodeReinspection(ctx context.Context, node *hwmgmtv1alpha1.Node) error {
+	updatedNode := &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: node.Namespace}}
+	if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, updatedNode, []utils.MetaMutation{
+		utils.RemoveAnnotation(utils.NodeReinspectAnnotation),
+		utils.RemoveAnnotation(NodeReinspectionStartedAnnotation),
+	}); err != nil {
+		return fmt.Errorf("failed to clear inspection request from node %s: %w", node.Name, err)
+	}
+
+	node.SetAnnotations(updatedNode.GetAnnotations())
+	return nil
+}
@@ -0,0 +1,234 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// isBMHFailed checks whether a BMH is in an error state that metal3 will not recover from on its own. Power
+// management errors are excluded, as they are usually caused by the BMC being temporarily unreachable.
+func isBMHFailed(bmh *metal3v1alpha1.BareMetalHost) bool {
+	return bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError &&
+		bmh.Status.ErrorType != metal3v1alpha1.PowerManagementError
+}
+
+// findNodeToReplace returns the first node of the NodePool that is marked for replacement or, when automatic
+// replacement is enabled, whose BMH has failed
+func (a *Adaptor) findNodeToReplace(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList) (*hwmgmtv1alpha1.Node, *metal3v1alpha1.BareMetalHost, string, error) {
+
+	autoReplace := utils.IsAutoReplaceFailedNodesEnabled(nodepool)
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		requested := utils.IsNodeReplacementRequested(node)
+		if !requested && !autoReplace {
+			continue
+		}
+
+		bmh, err := a.getBMHForNode(ctx, node)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+		}
+
+		switch {
+		case requested:
+			return node, bmh, "replacement requested", nil
+		case isBMHFailed(bmh):
+			return node, bmh, fmt.Sprintf("BMH failed with %s: %s", bmh.Status.ErrorType, bmh.Status.ErrorMessage), nil
+		}
+	}
+	return nil, nil, "", nil
+}
+
+// checkNodeReplacement replaces the hardware of a node of a provisioned NodePool, if requested or if it has failed.
+// It returns whether a replacement was handled, in which case the result and error are returned to the reconciler.
+func (a *Adaptor) checkNodeReplacement(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, bool, error) {
+
+	nodelist, err := utils.GetChildNodes(ctx, a.Logger, a.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), true, fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	node, bmh, reason, err := a.findNodeToReplace(ctx, nodepool, nodelist)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), true, err
+	}
+	if node == nil {
+		return ctrl.Result{}, false, nil
+	}
+
+	result, err := a.replaceNode(ctx, hwmgr, nodepool, node, bmh, reason)
+	return result, true, err
+}
+
+// replaceNode allocates a new BMH from the same pool as a node, with the same node group and hardware profile, then
+// removes the node and releases its BMH. The NodePool is moved back to processing until the new node is provisioned.
+func (a *Adaptor) replaceNode(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost,
+	reason string) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Replacing node",
+		slog.String("node", node.Name),
+		slog.String("bmh", bmh.Namespace+"/"+bmh.Name),
+		slog.String("reason", reason))
+
+	groupIndex := slices.IndexFunc(nodepool.Spec.NodeGroup, func(group hwmgmtv1alpha1.NodeGroup) bool {
+		return group.NodePoolData.Name == node.Spec.GroupName
+	})
+	if groupIndex < 0 {
+		return utils.DoNotRequeue(), fmt.Errorf("node %s is in group %s, which is not in NodePool %s",
+			node.Name, node.Spec.GroupName, nodepool.Name)
+	}
+	group := nodepool.Spec.NodeGroup[groupIndex]
+
+	// The node is dropped from the NodePool status once its replacement is allocated, so only its release is left
+	if !contains(nodepool.Status.Properties.NodeNames, node.Name) {
+		return a.releaseReplacedNode(ctx, nodepool, node, bmh, reason)
+	}
+
+	// A replacement claimed by an earlier attempt that failed part way through is resumed rather than replaced
+	claimed, err := a.findInterruptedReplacement(ctx, hwmgr, nodepool, group)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+	if claimed != nil {
+		return a.completeNodeReplacement(ctx, hwmgr, nodepool, node, bmh, reason, group, *claimed)
+	}
+
+	// The replacement must come from the same namespace, as all BMHs of a NodePool are in a single namespace
+	candidates, err := a.FetchBMHList(ctx, hwmgr, nodepool.Spec.Site, group.NodePoolData, UnallocatedBMHs, bmh.Namespace)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("unable to fetch BMHs to replace node %s: %w", node.Name, err)
+	}
+	candidates, err = a.filterClaimedBMHs(ctx, nodepool, candidates)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("unable to check the claims of BMHs to replace node %s: %w", node.Name, err)
+	}
+	candidates, _ = filterInspectedBMHs(candidates)
+	if len(candidates.Items) == 0 {
+		a.Logger.WarnContext(ctx, "No available BMH to replace node", slog.String("node", node.Name))
+		if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+			string(pluginv1alpha1.ConditionTypes.Replacement), metav1.ConditionFalse,
+			string(pluginv1alpha1.ConditionReasons.Failed),
+			fmt.Sprintf("Replacing node (%s), but no BMH is available in resource pool %s",
+				reason, group.NodePoolData.ResourcePoolId)); err != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to set replacement condition on node %s: %w", node.Name, err)
+		}
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	ordered, err := a.orderBMHsForGroup(ctx, nodepool, group, candidates.Items)
+	if err != nil {
+		return utils.DoNotRequeue(), fmt.Errorf("unable to select BMH to replace node %s: %w", node.Name, err)
+	}
+	return a.completeNodeReplacement(ctx, hwmgr, nodepool, node, bmh, reason, group, ordered[0])
+}
+
+// findInterruptedReplacement returns a BMH claimed by the NodePool for the given group whose Node was never recorded
+// in the NodePool status, or nil if there is none
+func (a *Adaptor) findInterruptedReplacement(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	group hwmgmtv1alpha1.NodeGroup) (*metal3v1alpha1.BareMetalHost, error) {
+
+	claimed, err := a.listClaimedBMHs(ctx, hwmgr, nodepool)
+	if err != nil {
+		return nil, err
+	}
+	for i := range claimed {
+		claim := getBMHClaim(&claimed[i])
+		if claim.NodeGroup == group.NodePoolData.Name && !contains(nodepool.Status.Properties.NodeNames, claim.NodeName) {
+			return &claimed[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// completeNodeReplacement allocates the replacement BMH of a node, then removes the node and releases its BMH
+func (a *Adaptor) completeNodeReplacement(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost,
+	reason string,
+	group hwmgmtv1alpha1.NodeGroup,
+	replacement metal3v1alpha1.BareMetalHost) (ctrl.Result, error) {
+
+	if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+		string(pluginv1alpha1.ConditionTypes.Replacement), metav1.ConditionFalse,
+		string(pluginv1alpha1.ConditionReasons.InProgress),
+		fmt.Sprintf("Replacing node (%s) with BMH %s/%s", reason, replacement.Namespace, replacement.Name)); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to set replacement condition on node %s: %w", node.Name, err)
+	}
+
+	// Allocate the replacement first, so that a failure leaves the NodePool with the node being replaced
+	if err := a.allocator.allocateBMH(ctx, hwmgr, &replacement, nodepool, group); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to allocate BMH %s to replace node %s: %w",
+			replacement.Name, node.Name, err)
+	}
+
+	nodepool.Status.Properties.NodeNames = slices.DeleteFunc(nodepool.Status.Properties.NodeNames, func(name string) bool {
+		return name == node.Name
+	})
+	if err := utils.UpdateNodePoolProperties(ctx, a.Client, nodepool); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Replacement allocated",
+		slog.String("node", node.Name),
+		slog.String("replacementBmh", replacement.Namespace+"/"+replacement.Name))
+	return a.releaseReplacedNode(ctx, nodepool, node, bmh, reason)
+}
+
+// releaseReplacedNode releases the BMH of a node whose replacement has been allocated, then removes the node
+func (a *Adaptor) releaseReplacedNode(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost,
+	reason string) (ctrl.Result, error) {
+
+	if err := a.finalizer.releaseBMH(ctx, bmh); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	if err := a.Client.Delete(ctx, node); err != nil && !errors.IsNotFound(err) {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete replaced node %s: %w", node.Name, err)
+	}
+
+	// The new node may need its hardware profile applied, which is driven to completion by the processing state
+	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+		hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.InProgress, metav1.ConditionFalse,
+		fmt.Sprintf("Replaced node %s (%s)", node.Name, reason)); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Node replaced", slog.String("node", node.Name))
+	return utils.RequeueImmediately(), nil
+}
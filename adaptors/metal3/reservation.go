@@ -0,0 +1,160 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// HandleNodePoolReservation claims the BMHs for a NodePool annotated for reservation, without creating its nodes. The
+// claims keep the BMHs from being allocated to other NodePools, and once the NodePool is activated, the claimed BMHs
+// are allocated to it by resumeInterruptedAllocations.
+func (a *Adaptor) HandleNodePoolReservation(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	a.Logger.InfoContext(ctx, "Handling reservation of NodePool hardware")
+
+	reserveErr := utils.ValidateNodePoolHwProfiles(ctx, a.Client, a.Namespace, nodepool)
+	if reserveErr == nil {
+		reserveErr = a.ProcessNewNodePool(ctx, hwmgr, nodepool)
+	}
+
+	reserved, total := 0, 0
+	if reserveErr == nil {
+		var err error
+		if reserved, total, err = a.reserveBMHs(ctx, hwmgr, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	complete := reserveErr == nil && reserved == total
+	if err := utils.UpdateNodePoolReservedCondition(ctx, a.Client, nodepool, reserveErr, complete,
+		fmt.Sprintf("%d of %d nodes", reserved, total)); err != nil {
+		return utils.RequeueWithMediumInterval(),
+			fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	switch {
+	case complete:
+		return utils.DoNotRequeue(), nil
+	case reserveErr != nil:
+		// Free hardware may be added to the site later
+		return utils.RequeueWithLongInterval(), nil
+	default:
+		// BMH inspection does not trigger a reconcile, so the reservation is polled until it completes
+		return utils.RequeueWithMediumInterval(), nil
+	}
+}
+
+// reserveBMHs claims enough inspected BMHs for each group of the NodePool, releasing the claims that are no longer
+// needed after a group was removed or shrunk. It returns the number of BMHs reserved and the number requested.
+func (a *Adaptor) reserveBMHs(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (int, int, error) {
+
+	claimed, err := a.listClaimedBMHs(ctx, hwmgr, nodepool)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// All the BMHs of a NodePool are allocated from the same namespace
+	bmhNamespace := ""
+	groupCounts := make(map[string]int)
+	for i := range claimed {
+		bmh := &claimed[i]
+		claim := getBMHClaim(bmh)
+		if group, found := findNodeGroup(nodepool, claim.NodeGroup); found && groupCounts[claim.NodeGroup] < group.Size {
+			groupCounts[claim.NodeGroup]++
+			bmhNamespace = bmh.Namespace
+			continue
+		}
+
+		a.Logger.InfoContext(ctx, "Releasing reserved BMH that is no longer needed",
+			slog.String("bmh", bmh.Namespace+"/"+bmh.Name),
+			slog.String("nodegroup", claim.NodeGroup))
+		if err := a.finalizer.releaseBMH(ctx, bmh); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	reserved, total := 0, 0
+	for _, nodeGroup := range nodepool.Spec.NodeGroup {
+		groupName := nodeGroup.NodePoolData.Name
+		total += nodeGroup.Size
+		reserved += groupCounts[groupName]
+
+		pending := nodeGroup.Size - groupCounts[groupName]
+		if pending <= 0 {
+			continue
+		}
+
+		candidates, err := a.fetchCandidateBMHs(ctx, hwmgr, nodepool, nodeGroup.NodePoolData, bmhNamespace)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to fetch BMHs for nodegroup=%s: %w", groupName, err)
+		}
+		candidates, err = a.filterClaimedBMHs(ctx, nodepool, candidates)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to check the claims of BMHs for nodegroup=%s: %w", groupName, err)
+		}
+
+		// As with an allocation, only inspected BMHs are reserved
+		inspected, _ := filterInspectedBMHs(candidates)
+		ordered, err := a.orderBMHsForGroup(ctx, nodepool, nodeGroup, inspected.Items)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unable to select BMHs for nodegroup=%s: %w", groupName, err)
+		}
+
+		for i := range ordered {
+			if pending == 0 {
+				break
+			}
+			bmh := &ordered[i]
+			if bmhNamespace != "" && bmh.Namespace != bmhNamespace {
+				continue
+			}
+
+			if err := a.reserveBMH(ctx, bmh, nodepool, groupName); errors.Is(err, errBMHClaimed) {
+				a.Logger.InfoContext(ctx, "BMH was claimed by another NodePool", slog.String("bmh", bmh.Name))
+				continue
+			} else if err != nil {
+				return 0, 0, err
+			}
+
+			bmhNamespace = bmh.Namespace
+			pending--
+			reserved++
+		}
+	}
+
+	return reserved, total, nil
+}
+
+// reserveBMH claims a BMH for a node of the given group of the NodePool
+func (a *Adaptor) reserveBMH(ctx context.Context, bmh *metal3v1alpha1.BareMetalHost, nodepool *hwmgmtv1alpha1.NodePool,
+	groupName string) error {
+
+	claim, err := a.claimBMH(ctx, bmh, nodepool, groupName)
+	if err != nil {
+		return err
+	}
+
+	a.Logger.InfoContext(ctx, "Reserved BMH",
+		slog.String("bmh", bmh.Namespace+"/"+bmh.Name),
+		slog.String("nodename", claim.NodeName),
+		slog.String("nodegroup", groupName))
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	nodeGroupFreeBMHs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hwmgr_plugin_metal3_nodegroup_free_bmhs",
+		Help: "Number of free BareMetalHosts matching the criteria of a NodePool nodegroup waiting for allocation",
+	}, []string{"nodepool", "nodegroup"})
+
+	nodeGroupRequiredBMHs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hwmgr_plugin_metal3_nodegroup_required_bmhs",
+		Help: "Number of BareMetalHosts a NodePool nodegroup waiting for allocation still requires",
+	}, []string{"nodepool", "nodegroup"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(nodeGroupFreeBMHs, nodeGroupRequiredBMHs)
+}
+
+// nodeGroupResources is the number of free BMHs matching a nodegroup, and the number it requires
+type nodeGroupResources struct {
+	Name     string
+	Free     int
+	Required int
+}
+
+// isShort checks whether the nodegroup requires more BMHs than are free
+func (r nodeGroupResources) isShort() bool {
+	return r.Free < r.Required
+}
+
+func (r nodeGroupResources) String() string {
+	return fmt.Sprintf("nodegroup=%s free=%d required=%d", r.Name, r.Free, r.Required)
+}
+
+// updateNodePoolResourceCondition reports the nodegroups of the NodePool without enough free BMHs in the
+// InsufficientResources condition, and records the counts of each nodegroup in the metrics. As allocation may wait a
+// long time for BMHs to be added, the condition is only written when it changes, and only cleared if it was set.
+func (a *Adaptor) updateNodePoolResourceCondition(
+	ctx context.Context,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	groups []nodeGroupResources) error {
+
+	var short []string
+	for _, group := range groups {
+		nodeGroupFreeBMHs.WithLabelValues(nodepool.Name, group.Name).Set(float64(group.Free))
+		nodeGroupRequiredBMHs.WithLabelValues(nodepool.Name, group.Name).Set(float64(group.Required))
+		if group.isShort() {
+			short = append(short, group.String())
+		}
+	}
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.InsufficientResources)
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, conditionType)
+
+	status := metav1.ConditionTrue
+	reason := pluginv1alpha1.ConditionReasons.ResourcesUnavailable
+	message := "Not enough free BareMetalHosts: " + strings.Join(short, ", ")
+	if len(short) == 0 {
+		if condition == nil || condition.Status == metav1.ConditionFalse {
+			return nil
+		}
+		status = metav1.ConditionFalse
+		reason = pluginv1alpha1.ConditionReasons.CapacityAvailable
+		message = "Enough free BareMetalHosts are available"
+	}
+
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) &&
+		utils.ConditionMessage(condition) == message {
+		return nil
+	}
+
+	if status == metav1.ConditionTrue {
+		a.Logger.WarnContext(ctx, "Not enough free BareMetalHosts for NodePool",
+			slog.String("nodepool", nodepool.Name),
+			slog.Any("nodegroups", short))
+	} else {
+		a.Logger.InfoContext(ctx, "Enough free BareMetalHosts are available for NodePool",
+			slog.String("nodepool", nodepool.Name))
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, a.Client, nodepool,
+		hwmgmtv1alpha1.ConditionType(conditionType), hwmgmtv1alpha1.ConditionReason(reason), status, message); err != nil {
+		return fmt.Errorf("failed to set resource condition on NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// clearNodePoolResourceMetrics removes the metrics of the nodegroups of a NodePool that no longer waits for allocation
+func clearNodePoolResourceMetrics(nodepool *hwmgmtv1alpha1.NodePool) {
+	nodeGroupFreeBMHs.DeletePartialMatch(prometheus.Labels{"nodepool": nodepool.Name})
+	nodeGroupRequiredBMHs.DeletePartialMatch(prometheus.Labels{"nodepool": nodepool.Name})
+}
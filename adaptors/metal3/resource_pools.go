@@ -0,0 +1,109 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+)
+
+// resourcePoolKey identifies a resource pool, as a pool ID may be used at more than one site
+type resourcePoolKey struct {
+	site string
+	pool string
+}
+
+// GetResourcePools returns the resource pools of every site, as set by the labels of the BMHs
+func (a *Adaptor) GetResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) ([]invserver.ResourcePoolInfo, int, error) {
+	return a.GetSiteResourcePools(ctx, hwmgr, "")
+}
+
+// GetSiteResourcePools returns the resource pools of a site, or of every site if none is given. Each pool is reported
+// with the number of its hosts in each state, including hosts not yet reported in the inventory, such as those still
+// being inspected.
+func (a *Adaptor) GetSiteResourcePools(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	site string) ([]invserver.ResourcePoolInfo, int, error) {
+
+	bmhList, err := a.listResourcePoolBMHs(ctx, site)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	hostCounts := make(map[resourcePoolKey]map[string]int)
+	for i := range bmhList.Items {
+		bmh := &bmhList.Items[i]
+		key := resourcePoolKey{site: bmh.Labels[LabelSiteID], pool: bmh.Labels[LabelResourcePoolID]}
+		if key.site == "" || key.pool == "" || !isBMHNamespaceAllowed(hwmgr, bmh.Namespace) {
+			continue
+		}
+		if hostCounts[key] == nil {
+			hostCounts[key] = make(map[string]int)
+		}
+		state := getBMHLifecycleState(bmh)
+		if state == "" {
+			state = bmhLifecycleNone
+		}
+		hostCounts[key][state]++
+	}
+
+	keys := make([]resourcePoolKey, 0, len(hostCounts))
+	for key := range hostCounts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].site != keys[j].site {
+			return keys[i].site < keys[j].site
+		}
+		return keys[i].pool < keys[j].pool
+	})
+
+	resp := make([]invserver.ResourcePoolInfo, 0, len(keys))
+	for _, key := range keys {
+		siteID, counts := key.site, hostCounts[key]
+		resp = append(resp, invserver.ResourcePoolInfo{
+			ResourcePoolId: key.pool,
+			Description:    key.pool,
+			Name:           key.pool,
+			SiteId:         &siteID,
+			HostCounts:     &counts,
+		})
+	}
+
+	return resp, http.StatusOK, nil
+}
+
+// listResourcePoolBMHs lists the BMHs with a resource pool, of a site if one is given. The BMHs are not copied from the
+// cache, so they must not be modified.
+func (a *Adaptor) listResourcePoolBMHs(ctx context.Context, site string) (metal3v1alpha1.BareMetalHostList, error) {
+	if a.bmhReader != nil && site != "" {
+		return a.listIndexedBMHs(ctx, client.MatchingFields{bmhSiteIDIndexKey: site}, client.UnsafeDisableDeepCopy)
+	}
+
+	var bmhList metal3v1alpha1.BareMetalHostList
+	opts := []client.ListOption{client.HasLabels{LabelSiteID, LabelResourcePoolID}}
+	if site != "" {
+		opts = append(opts, client.MatchingLabels{LabelSiteID: site})
+	}
+
+	reader := client.Reader(a.Client)
+	if a.bmhReader != nil {
+		reader = a.bmhReader
+		opts = append(opts, client.UnsafeDisableDeepCopy)
+	}
+	if err := reader.List(ctx, &bmhList, opts...); err != nil {
+		return bmhList, fmt.Errorf("failed to get BMH list: %w", err)
+	}
+	return bmhList, nil
+}
@@ -0,0 +1,70 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// LabelPrefixTags is the prefix of the BMH labels holding the tags of the hardware, one label per tag
+const LabelPrefixTags = "tags.oran.openshift.io/"
+
+// HandleNodeTags adds and removes the tag labels on the BMH backing a node
+func (a *Adaptor) HandleNodeTags(
+	ctx context.Context,
+	_ *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node,
+	add, remove []string) (bool, error) {
+
+	bmh, err := a.getBMHForNode(ctx, node)
+	if err != nil {
+		return false, fmt.Errorf("failed to get BMH for node %s: %w", node.Name, err)
+	}
+
+	var mutations []utils.MetaMutation
+	for _, tag := range add {
+		mutations = append(mutations, utils.AddLabel(LabelPrefixTags+tag, ""))
+	}
+	for _, tag := range remove {
+		mutations = append(mutations, utils.RemoveLabel(LabelPrefixTags+tag))
+	}
+	if len(mutations) == 0 {
+		return true, nil
+	}
+
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName, mutations...); err != nil {
+		return false, fmt.Errorf("failed to update tags of BMH %s/%s: %w", bmh.Namespace, bmh.Name, err)
+	}
+
+	a.Logger.InfoContext(ctx, "Updated BMH tags", slog.String("bmh", bmh.Name),
+		slog.Any("add", add), slog.Any("remove", remove))
+	return true, nil
+}
+
+// getBMHTags returns the sorted tags of a BMH, whether set through a Node or directly on the BMH
+func getBMHTags(bmh *metal3v1alpha1.BareMetalHost) []string {
+	var tags []string
+	for label := range bmh.Labels {
+		if tag, found := strings.CutPrefix(label, LabelPrefixTags); found && tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,77 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// countManagerUpdatingNodes counts the nodes allocated through the hardware manager, across all of its NodePools, that
+// have an update in progress. The nodes are read from the API server, as an update just started for another NodePool
+// may not have reached the cache yet.
+func (a *Adaptor) countManagerUpdatingNodes(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (int, error) {
+	var nodelist hwmgmtv1alpha1.NodeList
+	if err := a.NoncachedClient.List(ctx, &nodelist, client.InNamespace(a.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list nodes of hardware manager %s: %w", hwmgr.Name, err)
+	}
+
+	updating := 0
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if node.Spec.HwMgrId == hwmgr.Name && isNodeUpdating(node) {
+			updating++
+		}
+	}
+	return updating, nil
+}
+
+// managerUpdateSlots returns the number of further updates that may be started on the nodes of the hardware manager,
+// and whether its maxConcurrentUpdates limits them at all
+func (a *Adaptor) managerUpdateSlots(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager) (int, bool, error) {
+	if hwmgr.Spec.Metal3Data == nil || hwmgr.Spec.Metal3Data.MaxConcurrentUpdates == nil {
+		return 0, false, nil
+	}
+
+	updating, err := a.countManagerUpdatingNodes(ctx, hwmgr)
+	if err != nil {
+		return 0, true, err
+	}
+	return max(*hwmgr.Spec.Metal3Data.MaxConcurrentUpdates-updating, 0), true, nil
+}
+
+// limitManagerUpdates caps the nodes about to start updating by the maxConcurrentUpdates of the hardware manager. The
+// nodes left out stay pending, and are started by a later reconcile once updates on other nodes finish.
+func (a *Adaptor) limitManagerUpdates(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	start []*hwmgmtv1alpha1.Node) ([]*hwmgmtv1alpha1.Node, error) {
+
+	if len(start) == 0 {
+		return start, nil
+	}
+
+	slots, limited, err := a.managerUpdateSlots(ctx, hwmgr)
+	if err != nil {
+		return nil, err
+	}
+	if !limited || slots >= len(start) {
+		return start, nil
+	}
+
+	a.Logger.InfoContext(ctx, "Holding node updates at the hardware manager concurrency limit",
+		slog.String("nodepool", nodepool.Name),
+		slog.Int("maxConcurrentUpdates", *hwmgr.Spec.Metal3Data.MaxConcurrentUpdates),
+		slog.Int("held", len(start)-slots))
+	return start[:slots], nil
+}
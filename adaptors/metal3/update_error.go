@@ -0,0 +1,180 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	defaultUpdateErrorRetryInterval = 5 * time.Minute
+
+	// NodeEventReasonUpdateError is the reason of the Kubernetes event emitted when the update of a node is skipped, or
+	// aborts the rollout of its NodePool, as its BMH is in an error state
+	NodeEventReasonUpdateError = "UpdateError"
+)
+
+// getUpdateErrorRemediation returns the update error remediation policy of the HardwareManager, with the defaults
+// applied
+func getUpdateErrorRemediation(hwmgr *pluginv1alpha1.HardwareManager) pluginv1alpha1.UpdateErrorRemediation {
+	policy := pluginv1alpha1.UpdateErrorRemediation{
+		RetryInterval: &metav1.Duration{Duration: defaultUpdateErrorRetryInterval},
+		Action:        pluginv1alpha1.UpdateErrorActions.Abort,
+	}
+	if hwmgr == nil || hwmgr.Spec.Metal3Data == nil || hwmgr.Spec.Metal3Data.UpdateErrorRemediation == nil {
+		return policy
+	}
+
+	configured := hwmgr.Spec.Metal3Data.UpdateErrorRemediation
+	policy.Retries = configured.Retries
+	if configured.RetryInterval != nil && configured.RetryInterval.Duration > 0 {
+		policy.RetryInterval = configured.RetryInterval
+	}
+	if configured.Action != "" {
+		policy.Action = configured.Action
+	}
+	return policy
+}
+
+// remediateBMHUpdateError handles a BMH found in an error state as the day-2 update of its node is started, according
+// to the remediation policy of the HardwareManager. The update is requested again, at the retry interval, until the
+// retries are exhausted. The node is then failed, and either skipped, letting the rollout of its NodePool continue
+// with the other nodes, or left to abort the rollout. In both cases the update requests are cleared from the BMH, so
+// that the node is no longer picked up as transitioning.
+func (a *Adaptor) remediateBMHUpdateError(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	node *hwmgmtv1alpha1.Node, bmh *metal3v1alpha1.BareMetalHost) error {
+
+	policy := getUpdateErrorRemediation(hwmgr)
+	updateError, err := utils.GetNodeUpdateError(node)
+	if err != nil {
+		a.Logger.WarnContext(ctx, "Discarding invalid update error", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+	if updateError == nil || updateError.HwProfile != node.Spec.HwProfile || updateError.Outcome != utils.NodeUpdateErrorRetrying {
+		updateError = &utils.NodeUpdateError{HwProfile: node.Spec.HwProfile, Outcome: utils.NodeUpdateErrorRetrying}
+	}
+
+	now := metav1.Now()
+	if updateError.LastRetry != nil && now.Sub(updateError.LastRetry.Time) < policy.RetryInterval.Duration {
+		// Give the last retry time to take effect
+		return nil
+	}
+
+	bmhName := types.NamespacedName{Name: bmh.Name, Namespace: bmh.Namespace}
+	if updateError.Retries < policy.Retries {
+		updateError.Retries++
+		updateError.LastRetry = &now
+		updateError.Message = fmt.Sprintf("BMH %s/%s in error state, retry %d of %d: %s",
+			bmh.Namespace, bmh.Name, updateError.Retries, policy.Retries, bmh.Status.ErrorMessage)
+		a.Logger.WarnContext(ctx, "Retrying update of node with BMH in error state", slog.String("node", node.Name),
+			slog.String("BMH", bmh.Name), slog.Int("retry", updateError.Retries))
+
+		// Rebooting the BMH into servicing again retries the update
+		if err := a.updateBMHMetaWithRetry(ctx, bmhName, utils.AddAnnotation(BmhRebootAnnotation, "")); err != nil {
+			return fmt.Errorf("failed to add %s to BMH %+v: %w", BmhRebootAnnotation, bmhName, err)
+		}
+		if err := a.setNodeUpdateError(ctx, node, updateError); err != nil {
+			return err
+		}
+		if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+			string(hwmgmtv1alpha1.Configured), metav1.ConditionFalse, string(hwmgmtv1alpha1.ConfigUpdate),
+			updateError.Message); err != nil {
+			return fmt.Errorf("failed to set retry condition on node %s: %w", node.Name, err)
+		}
+		if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError, updateError.Message); err != nil {
+			a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+		}
+		return nil
+	}
+
+	if policy.Action == pluginv1alpha1.UpdateErrorActions.SkipNode {
+		updateError.Outcome = utils.NodeUpdateErrorSkipped
+		updateError.Message = fmt.Sprintf("Update skipped, BMH %s/%s in error state", bmh.Namespace, bmh.Name)
+	} else {
+		updateError.Outcome = utils.NodeUpdateErrorAborted
+		updateError.Message = fmt.Sprintf("Update aborted, BMH %s/%s in error state", bmh.Namespace, bmh.Name)
+	}
+	if bmh.Status.ErrorMessage != "" {
+		updateError.Message += ": " + bmh.Status.ErrorMessage
+	}
+	if updateError.Retries > 0 {
+		updateError.Message += fmt.Sprintf(" (after %d retries)", updateError.Retries)
+	}
+	a.Logger.WarnContext(ctx, "Giving up update of node with BMH in error state", slog.String("node", node.Name),
+		slog.String("BMH", bmh.Name), slog.String("outcome", updateError.Outcome))
+
+	if err := a.updateBMHMetaWithRetry(ctx, bmhName,
+		utils.RemoveAnnotation(BiosUpdateNeededAnnotation),
+		utils.RemoveAnnotation(FirmwareUpdateNeededAnnotation),
+		utils.RemoveAnnotation(BmhRebootAnnotation),
+		utils.RemoveAnnotation(BmhDay2ConfigAnnotation)); err != nil {
+		return fmt.Errorf("failed to clear update annotations from BMH %+v: %w", bmhName, err)
+	}
+	if err := a.setNodeUpdateError(ctx, node, updateError,
+		utils.RemoveAnnotation(utils.ConfigAnnotation),
+		utils.RemoveAnnotation(UpdateProgressAnnotation)); err != nil {
+		return err
+	}
+	if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+		string(hwmgmtv1alpha1.Configured), metav1.ConditionFalse, string(hwmgmtv1alpha1.Failed),
+		updateError.Message); err != nil {
+		return fmt.Errorf("failed to set failed condition on node %s: %w", node.Name, err)
+	}
+	if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError, updateError.Message); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+	if a.recorder != nil {
+		a.recorder.Event(node, corev1.EventTypeWarning, NodeEventReasonUpdateError, updateError.Message)
+	}
+
+	return nil
+}
+
+// setNodeUpdateError records the remediation of an update error on a node, along with any other changes to its
+// annotations
+func (a *Adaptor) setNodeUpdateError(ctx context.Context, node *hwmgmtv1alpha1.Node, updateError *utils.NodeUpdateError,
+	mutations ...utils.MetaMutation) error {
+
+	data, err := json.Marshal(updateError)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update error for node %s: %w", node.Name, err)
+	}
+
+	mutations = append(mutations, utils.AddAnnotation(utils.NodeUpdateErrorAnnotation, string(data)))
+	if err := a.updateNodeMetaWithRetry(ctx, node.Name, node.Namespace, mutations...); err != nil {
+		return fmt.Errorf("failed to record update error on node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// findRolloutAbortingNode returns a node whose update error has aborted the rollout of the NodePool, if any. The
+// rollout resumes once the node group of the node is moved to another hardware profile.
+func findRolloutAbortingNode(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList) *hwmgmtv1alpha1.Node {
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		for i := range nodelist.Items {
+			node := &nodelist.Items[i]
+			if node.Spec.GroupName != nodegroup.NodePoolData.Name || !utils.IsNodeUpdateAborted(node) {
+				continue
+			}
+			if utils.GetNodeTargetHwProfile(node, nodegroup.NodePoolData.HwProfile) == node.Spec.HwProfile {
+				return node
+			}
+		}
+	}
+	return nil
+}
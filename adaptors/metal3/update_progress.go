@@ -0,0 +1,284 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The Node status is defined by the O-Cloud Manager API, so the detailed progress of an update is published as a
+// JSON annotation on the Node, with a summary in the message of the in-progress condition.
+const (
+	UpdateProgressAnnotation = "hwmgr-plugin.oran.openshift.io/update-progress"
+
+	UpdatePhasePending  = "Pending"
+	UpdatePhaseUpdating = "Updating"
+	UpdatePhaseFailed   = "Failed"
+
+	defaultPendingTimeout  = 30 * time.Minute
+	defaultUpdatingTimeout = 4 * time.Hour
+
+	// NodeEventReasonUpdateTimedOut is the reason of the Kubernetes event emitted for an update that timed out
+	NodeEventReasonUpdateTimedOut = "UpdateTimedOut"
+)
+
+// nodeUpdateProgress describes the progress of a BIOS settings or firmware update on a node
+type nodeUpdateProgress struct {
+	Reason            string `json:"reason"`
+	Component         string `json:"component,omitempty"`
+	Phase             string `json:"phase"`
+	OperationalStatus string `json:"operationalStatus,omitempty"`
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	// ComponentsUpdated and ComponentsTotal count the firmware components of a firmware update that have reached the
+	// version in the profile. The BMH does not report finer progress, so no percentage is estimated.
+	ComponentsUpdated int `json:"componentsUpdated,omitempty"`
+	ComponentsTotal   int `json:"componentsTotal,omitempty"`
+	Retries           int `json:"retries"`
+	// PhaseStarted is when the node entered the current phase, for the phase timeouts
+	PhaseStarted metav1.Time `json:"phaseStarted"`
+}
+
+// String returns a summary of the progress, suitable for a condition message
+func (p nodeUpdateProgress) String() string {
+	target := p.Reason
+	if target == "" {
+		target = "update"
+	}
+	if p.Component != "" {
+		target = fmt.Sprintf("%s (%s)", target, p.Component)
+	}
+	msg := fmt.Sprintf("Hardware configuration %s: %s", strings.ToLower(p.Phase), target)
+	if p.ComponentsTotal > 0 {
+		msg += fmt.Sprintf(", %d/%d components updated", p.ComponentsUpdated, p.ComponentsTotal)
+	}
+	msg += fmt.Sprintf(", BMH %s/%s", p.ProvisioningState, p.OperationalStatus)
+	if p.Retries > 0 {
+		msg += fmt.Sprintf(", %d retries", p.Retries)
+	}
+	return msg
+}
+
+// firmwareComponentsUpdated counts the requested firmware components that have reached the version in the profile
+func firmwareComponentsUpdated(hfc *metal3v1alpha1.HostFirmwareComponents, spec pluginv1alpha1.HardwareProfileSpec) (updated, total int) {
+	desired := map[string]string{
+		"bios": spec.BiosFirmware.Version,
+		"bmc":  spec.BmcFirmware.Version,
+	}
+
+	for _, update := range hfc.Spec.Updates {
+		total++
+		for _, component := range hfc.Status.Components {
+			if component.Component == update.Component && component.CurrentVersion == desired[update.Component] {
+				updated++
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// getNodeUpdateProgress builds the update progress for a node from the state of its BMH and firmware resources
+func (a *Adaptor) getNodeUpdateProgress(ctx context.Context, node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost) nodeUpdateProgress {
+
+	progress := nodeUpdateProgress{
+		Reason:            utils.GetConfigAnnotation(node),
+		Phase:             UpdatePhasePending,
+		OperationalStatus: string(bmh.Status.OperationalStatus),
+		ProvisioningState: string(bmh.Status.Provisioning.State),
+		Retries:           bmh.Status.ErrorCount,
+	}
+
+	switch {
+	case bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusError:
+		progress.Phase = UpdatePhaseFailed
+	case bmh.Status.OperationalStatus == metal3v1alpha1.OperationalStatusServicing,
+		bmh.Status.Provisioning.State == metal3v1alpha1.StatePreparing:
+		progress.Phase = UpdatePhaseUpdating
+	}
+
+	if progress.Reason != UpdateReasonFirmware {
+		return progress
+	}
+
+	hfc, err := a.getHostFirmwareComponents(ctx, bmh.Name, bmh.Namespace)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "Unable to get HostFirmwareComponents for progress", slog.String("error", err.Error()))
+		return progress
+	}
+
+	components := make([]string, 0, len(hfc.Spec.Updates))
+	for _, update := range hfc.Spec.Updates {
+		components = append(components, update.Component)
+	}
+	progress.Component = strings.Join(components, ",")
+
+	hwProfile, err := utils.GetHardwareProfile(ctx, a.Client, a.Namespace, node.Spec.HwProfile)
+	if err != nil {
+		a.Logger.InfoContext(ctx, "Unable to get HardwareProfile for progress", slog.String("error", err.Error()))
+		return progress
+	}
+
+	// Firmware updates progress per component
+	progress.ComponentsUpdated, progress.ComponentsTotal = firmwareComponentsUpdated(hfc, hwProfile.Spec)
+
+	return progress
+}
+
+// getUpdatePhaseTimeout returns the timeout configured for a phase of an update, or zero if the phase is not timed
+func getUpdatePhaseTimeout(hwmgr *pluginv1alpha1.HardwareManager, phase string) time.Duration {
+	var timeouts *pluginv1alpha1.UpdateTimeouts
+	if hwmgr != nil && hwmgr.Spec.Metal3Data != nil {
+		timeouts = hwmgr.Spec.Metal3Data.UpdateTimeouts
+	}
+
+	switch phase {
+	case UpdatePhasePending:
+		if timeouts != nil && timeouts.Pending != nil && timeouts.Pending.Duration > 0 {
+			return timeouts.Pending.Duration
+		}
+		return defaultPendingTimeout
+	case UpdatePhaseUpdating:
+		if timeouts != nil && timeouts.Updating != nil && timeouts.Updating.Duration > 0 {
+			return timeouts.Updating.Duration
+		}
+		return defaultUpdatingTimeout
+	}
+	return 0
+}
+
+// getPhaseStarted returns when a node entered the given update phase, from its published progress. A node that has
+// changed phase, or has no progress published, enters the phase now.
+func getPhaseStarted(node *hwmgmtv1alpha1.Node, phase string) metav1.Time {
+	var published nodeUpdateProgress
+	if data, exists := node.Annotations[UpdateProgressAnnotation]; exists {
+		if err := json.Unmarshal([]byte(data), &published); err == nil &&
+			published.Phase == phase && !published.PhaseStarted.IsZero() {
+			return published.PhaseStarted
+		}
+	}
+	return metav1.NewTime(time.Now().Truncate(time.Second))
+}
+
+// updateNodeProgress publishes the update progress for a node, setting the progress annotation and the message of
+// the in-progress condition. It returns true if the node has exceeded the timeout of its update phase, in which case
+// the update is failed instead.
+func (a *Adaptor) updateNodeProgress(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager, node *hwmgmtv1alpha1.Node,
+	bmh *metal3v1alpha1.BareMetalHost, conditionType string) (bool, error) {
+
+	progress := a.getNodeUpdateProgress(ctx, node, bmh)
+	progress.PhaseStarted = getPhaseStarted(node, progress.Phase)
+
+	if timeout := getUpdatePhaseTimeout(hwmgr, progress.Phase); timeout > 0 && time.Since(progress.PhaseStarted.Time) > timeout {
+		return true, a.failTimedOutUpdate(ctx, node, conditionType, progress, timeout)
+	}
+
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal update progress for node %s: %w", node.Name, err)
+	}
+
+	if node.Annotations[UpdateProgressAnnotation] != string(data) {
+		// nolint: wrapcheck
+		if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+			updatedNode := &hwmgmtv1alpha1.Node{}
+			if err := a.Client.Get(ctx, types.NamespacedName{Name: node.Name, Namespace: node.Namespace}, updatedNode); err != nil {
+				return fmt.Errorf("failed to fetch Node: %w", err)
+			}
+			patch := client.MergeFrom(updatedNode.DeepCopy())
+			if updatedNode.Annotations == nil {
+				updatedNode.Annotations = make(map[string]string)
+			}
+			updatedNode.Annotations[UpdateProgressAnnotation] = string(data)
+			return a.Client.Patch(ctx, updatedNode, patch)
+		}); err != nil {
+			return false, fmt.Errorf("failed to set update progress on node %s: %w", node.Name, err)
+		}
+	}
+
+	condition := meta.FindStatusCondition(node.Status.Conditions, conditionType)
+	if condition == nil || condition.Status != metav1.ConditionFalse || utils.ConditionMessage(condition) == progress.String() {
+		return false, nil
+	}
+
+	if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+		conditionType, condition.Status, condition.Reason, progress.String()); err != nil {
+		return false, fmt.Errorf("failed to set update progress in node %s status: %w", node.Name, err)
+	}
+
+	return false, nil
+}
+
+// failTimedOutUpdate fails an update that has exceeded the timeout of its phase, setting a TimedOut condition and
+// clearing the in-progress annotations so the node is no longer polled. The timeout is recorded in the node history and
+// emitted as an event to alert operators.
+func (a *Adaptor) failTimedOutUpdate(ctx context.Context, node *hwmgmtv1alpha1.Node, conditionType string,
+	progress nodeUpdateProgress, timeout time.Duration) error {
+
+	message := fmt.Sprintf("Hardware configuration timed out after %s in phase %s, BMH %s/%s",
+		timeout, progress.Phase, progress.ProvisioningState, progress.OperationalStatus)
+	a.Logger.WarnContext(ctx, "Node update timed out",
+		slog.String("node", node.Name),
+		slog.String("phase", progress.Phase),
+		slog.Duration("timeout", timeout))
+
+	if err := utils.SetNodeConditionStatus(ctx, a.Client, node.Name, node.Namespace,
+		conditionType, metav1.ConditionFalse, string(hwmgmtv1alpha1.TimedOut), message); err != nil {
+		return fmt.Errorf("failed to set timed out condition on node %s: %w", node.Name, err)
+	}
+
+	if err := utils.UpdateObjectMetaWithRetry(ctx, a.Client, node, []utils.MetaMutation{
+		utils.RemoveAnnotation(utils.ConfigAnnotation),
+		utils.RemoveAnnotation(UpdateProgressAnnotation),
+	}); err != nil {
+		return fmt.Errorf("failed to clear update annotations from node %s: %w", node.Name, err)
+	}
+
+	if err := utils.RecordNodeEvent(ctx, a.Client, node.Name, node.Namespace, utils.NodeEventError, message); err != nil {
+		a.Logger.ErrorContext(ctx, "failed to record node event", slog.String("node", node.Name), slog.String("error", err.Error()))
+	}
+	if a.recorder != nil {
+		a.recorder.Event(node, corev1.EventTypeWarning, NodeEventReasonUpdateTimedOut, message)
+	}
+
+	return nil
+}
+
+// nodeUpdateEvents returns the events recorded in the history of a node when an update for the reason starts and
+// completes
+func nodeUpdateEvents(reason string) (started, completed string) {
+	switch reason {
+	case UpdateReasonBIOSSettings:
+		return utils.NodeEventBiosUpdateStarted, utils.NodeEventBiosUpdateCompleted
+	case UpdateReasonFirmware:
+		return utils.NodeEventFirmwareUpdateStarted, utils.NodeEventFirmwareUpdateCompleted
+	}
+	return utils.NodeEventProfileUpdateStarted, utils.NodeEventProfileUpdateCompleted
+}
+
+// removeUpdateProgressAnnotation clears the update progress annotation from a node
+func removeUpdateProgressAnnotation(node *hwmgmtv1alpha1.Node) {
+	delete(node.Annotations, UpdateProgressAnnotation)
+}
@@ -0,0 +1,68 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metal3
+
+import (
+	"testing"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+func TestFirmwareComponentsUpdated(t *testing.T) {
+	spec := pluginv1alpha1.HardwareProfileSpec{
+		BiosFirmware: pluginv1alpha1.Firmware{Version: "2.0"},
+		BmcFirmware:  pluginv1alpha1.Firmware{Version: "7.0"},
+	}
+	hfc := &metal3v1alpha1.HostFirmwareComponents{
+		Spec: metal3v1alpha1.HostFirmwareComponentsSpec{
+			Updates: []metal3v1alpha1.FirmwareUpdate{{Component: "bios"}, {Component: "bmc"}},
+		},
+		Status: metal3v1alpha1.HostFirmwareComponentsStatus{
+			Components: []metal3v1alpha1.FirmwareComponentStatus{
+				{Component: "bios", CurrentVersion: "2.0"},
+				{Component: "bmc", CurrentVersion: "6.0"},
+			},
+		},
+	}
+
+	updated, total := firmwareComponentsUpdated(hfc, spec)
+	if updated != 1 || total != 2 {
+		t.Errorf("expected 1 of 2 components updated, got %d of %d", updated, total)
+	}
+}
+
+func TestNodeUpdateProgressString(t *testing.T) {
+	tests := []struct {
+		description string
+		progress    nodeUpdateProgress
+		expected    string
+	}{
+		{
+			description: "BIOS settings update reports only its phase",
+			progress: nodeUpdateProgress{Reason: UpdateReasonBIOSSettings, Phase: UpdatePhaseUpdating,
+				ProvisioningState: "preparing", OperationalStatus: "OK"},
+			expected: "Hardware configuration updating: " + UpdateReasonBIOSSettings + ", BMH preparing/OK",
+		},
+		{
+			description: "firmware update reports its updated components",
+			progress: nodeUpdateProgress{Reason: UpdateReasonFirmware, Component: "bios,bmc", Phase: UpdatePhaseUpdating,
+				ProvisioningState: "available", OperationalStatus: "servicing", ComponentsUpdated: 1, ComponentsTotal: 2,
+				Retries: 1},
+			expected: "Hardware configuration updating: " + UpdateReasonFirmware +
+				" (bios,bmc), 1/2 components updated, BMH available/servicing, 1 retries",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if msg := test.progress.String(); msg != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// Node event reasons
+const (
+	HwProfileOverrideInvalid = "HwProfileOverrideInvalid"
+)
+
+// checkNodeHwProfileOverrides starts a configuration update of a provisioned NodePool when the hardware profile
+// override of any of its nodes has been set, changed or removed. An override naming a HardwareProfile that does not
+// exist is reported in an event on the Node and not applied.
+func (c *HwMgrAdaptorController) checkNodeHwProfileOverrides(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if !meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) ||
+		nodepool.Generation != nodepool.Status.HwMgrPlugin.ObservedGeneration ||
+		utils.IsNodePoolConfigUpdateRequested(nodepool) {
+		// A pending spec change or configuration update applies the overrides as it updates the nodes
+		return nil
+	}
+
+	nodelist, err := utils.GetChildNodes(ctx, c.Logger, c.Client, nodepool)
+	if err != nil {
+		return fmt.Errorf("failed to get child nodes for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	var names []string
+	for _, node := range utils.FindNodesWithStaleHwProfile(nodepool, nodelist) {
+		if override := utils.GetNodeHwProfileOverride(node); override != "" {
+			if _, err := utils.GetHardwareProfile(ctx, c.Client, c.Namespace, override); err != nil {
+				c.Logger.WarnContext(ctx, "Ignoring invalid hardware profile override", slog.String("node", node.Name),
+					slog.String("hwProfile", override), slog.String("error", err.Error()))
+				c.recorder.Event(node, corev1.EventTypeWarning, HwProfileOverrideInvalid,
+					fmt.Sprintf("Hardware profile override %s is not applied: %s", override, err.Error()))
+				continue
+			}
+		}
+		names = append(names, node.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	c.Logger.InfoContext(ctx, "Applying node hardware profile overrides", slog.Any("nodes", names))
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, hwmgmtv1alpha1.Configured,
+		hwmgmtv1alpha1.ConfigUpdate, metav1.ConditionFalse,
+		"Applying hardware profile overrides to nodes: "+strings.Join(names, ", ")); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+)
+
+// unknownNodeVendor is reported for nodes whose adaptor does not record the hardware vendor
+const unknownNodeVendor = "unknown"
+
+var nodePhaseDurations = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "hwmgr_plugin_node_phase_duration_seconds",
+	Help: "Time taken by the nodes to complete provisioning, BIOS, firmware and hardware profile updates",
+	// From one minute to about eight and a half hours
+	Buckets: prometheus.ExponentialBuckets(60, 2, 10),
+}, []string{"phase", "vendor", "hw_profile"})
+
+func init() {
+	metrics.Registry.MustRegister(nodePhaseDurations)
+}
+
+// recordNodePhaseDurations observes the duration of each phase completed by a Node update
+func recordNodePhaseDurations(oldNode, newNode *hwmgmtv1alpha1.Node) {
+	if newNode.GetAnnotations()[utils.NodeTimingsAnnotation] == oldNode.GetAnnotations()[utils.NodeTimingsAnnotation] {
+		return
+	}
+
+	// A corrupt annotation on either version leaves the update uncounted
+	before, err := utils.GetNodeTimings(oldNode)
+	if err != nil {
+		return
+	}
+	after, err := utils.GetNodeTimings(newNode)
+	if err != nil {
+		return
+	}
+
+	vendor := newNode.GetAnnotations()[utils.NodeVendorAnnotation]
+	if vendor == "" {
+		vendor = unknownNodeVendor
+	}
+	for phase, duration := range utils.CompletedNodeTimings(before, after) {
+		nodePhaseDurations.WithLabelValues(phase, vendor, newNode.Spec.HwProfile).Observe(duration.Seconds())
+	}
+}
+
+// setupNodeTimingMetrics observes the phase durations of the Nodes from the Node updates seen by the manager cache, so
+// that each completed phase is counted once, when it is saved
+func (c *HwMgrAdaptorController) setupNodeTimingMetrics(mgr ctrl.Manager) error {
+	informer, err := mgr.GetCache().GetInformer(context.Background(), &hwmgmtv1alpha1.Node{})
+	if err != nil {
+		return fmt.Errorf("failed to get Node informer: %w", err)
+	}
+
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, oldOk := oldObj.(*hwmgmtv1alpha1.Node)
+			newNode, newOk := newObj.(*hwmgmtv1alpha1.Node)
+			if oldOk && newOk && newNode.Namespace == c.Namespace {
+				recordNodePhaseDurations(oldNode, newNode)
+			}
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to add Node metrics event handler: %w", err)
+	}
+
+	return nil
+}
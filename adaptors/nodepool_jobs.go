@@ -0,0 +1,86 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/samber/lo"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListNodePoolJobs returns the hardware manager jobs run for the NodePool of the given name, as published in its
+// allocation records, in the order they were first seen. The records outlive the NodePool, so the jobs of a deleted
+// NodePool are returned until its records expire.
+func (c *HwMgrAdaptorController) ListNodePoolJobs(ctx context.Context, hwMgrId, nodePoolName string) ([]invserver.NodePoolJob, error) {
+	ctx = logging.WithHwMgr(ctx, hwMgrId)
+
+	if _, statusCode, err := c.getHwMgr(ctx, hwMgrId); err != nil {
+		return nil, &InventoryError{
+			Status: statusCode,
+			Detail: fmt.Sprintf("Hardware Manager %s unavailable: %s", hwMgrId, err.Error()),
+			Err:    fmt.Errorf("unable to get hardware manager %s: %w", hwMgrId, err),
+		}
+	}
+
+	records := &pluginv1alpha1.AllocationRecordList{}
+	if err := c.Client.List(ctx, records, client.InNamespace(c.Namespace)); err != nil {
+		return nil, &InventoryError{
+			Status: http.StatusInternalServerError,
+			Detail: fmt.Sprintf("Allocation record query failed for NodePool %s", nodePoolName),
+			Err:    fmt.Errorf("failed to list allocation records: %w", err),
+		}
+	}
+
+	found := false
+	jobs := []invserver.NodePoolJob{}
+	for _, record := range records.Items {
+		if record.Spec.NodePool != nodePoolName || record.Spec.HwMgrId != hwMgrId {
+			continue
+		}
+		found = true
+
+		for _, job := range record.Status.Jobs {
+			entry := invserver.NodePoolJob{
+				AllocationRecord: record.Name,
+				JobId:            job.JobId,
+				Operation:        invserver.NodePoolJobOperation(job.Operation),
+				NodeName:         lo.EmptyableToPtr(job.NodeName),
+				FailReason:       lo.EmptyableToPtr(job.FailReason),
+				FailCode:         lo.EmptyableToPtr(job.FailCode),
+				ObservedAt:       job.ObservedAt.Time,
+			}
+			if job.Phase != "" {
+				entry.Phase = lo.ToPtr(invserver.NodePoolJobPhase(job.Phase))
+			}
+			if job.LastTransitionTime != nil {
+				entry.LastTransitionTime = &job.LastTransitionTime.Time
+			}
+			if job.CompletedAt != nil {
+				entry.CompletedAt = &job.CompletedAt.Time
+			}
+			jobs = append(jobs, entry)
+		}
+	}
+
+	if !found {
+		return nil, &InventoryError{
+			Status: http.StatusNotFound,
+			Detail: fmt.Sprintf("No allocation records found for NodePool %s of Hardware Manager %s", nodePoolName, hwMgrId),
+			Err:    fmt.Errorf("no allocation records found for NodePool %s of hardware manager %s", nodePoolName, hwMgrId),
+		}
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].ObservedAt.Before(jobs[j].ObservedAt) })
+	return jobs, nil
+}
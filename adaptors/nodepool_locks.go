@@ -0,0 +1,46 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"sync"
+)
+
+// nodePoolLocks serializes the handlers that mutate a NodePool, or its Nodes, so that only one of them runs for a
+// given NodePool at a time. Locks are keyed by NodePool name, as that is all a Node records of its NodePool.
+type nodePoolLocks struct {
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+func newNodePoolLocks() *nodePoolLocks {
+	return &nodePoolLocks{
+		active: make(map[string]bool),
+	}
+}
+
+// tryLock claims the NodePool, returning false if another handler holds it. Callers that fail to claim the NodePool
+// requeue rather than wait, so that the controller workqueue collapses any further requests for the same object.
+func (l *nodePoolLocks) tryLock(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[name] {
+		return false
+	}
+
+	l.active[name] = true
+	return true
+}
+
+// unlock releases a NodePool claimed by tryLock
+func (l *nodePoolLocks) unlock(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.active, name)
+}
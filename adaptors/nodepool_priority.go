@@ -0,0 +1,132 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//
+// Allocation is otherwise first-come-first-served, so when NodePools compete for scarce hardware, the priority
+// extension decides which of them gets it first. A new NodePool is held back from the adaptor, with a
+// WaitingForCapacity condition, while any NodePool of higher priority competing for the same resource pools has yet
+// to be fully allocated. NodePools of equal priority are not held for each other, and NodePools that have already been
+// handed off to the adaptor are never held, so capacity is never taken back from them.
+//
+
+// nodePoolResourcePools returns the resource pools requested by the node groups of a NodePool. A node group without a
+// resource pool may be allocated from any pool, which is reported as an empty pool ID.
+func nodePoolResourcePools(nodepool *hwmgmtv1alpha1.NodePool) []string {
+	var pools []string
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if nodegroup.Size == 0 {
+			continue
+		}
+		if !slices.Contains(pools, nodegroup.NodePoolData.ResourcePoolId) {
+			pools = append(pools, nodegroup.NodePoolData.ResourcePoolId)
+		}
+	}
+	return pools
+}
+
+// nodePoolsCompete checks whether two NodePools may be allocated hardware from the same resource pool
+func nodePoolsCompete(a, b *hwmgmtv1alpha1.NodePool) bool {
+	if a.Spec.HwMgrId != b.Spec.HwMgrId {
+		return false
+	}
+
+	poolsA := nodePoolResourcePools(a)
+	poolsB := nodePoolResourcePools(b)
+	if (slices.Contains(poolsA, "") && len(poolsB) > 0) || (slices.Contains(poolsB, "") && len(poolsA) > 0) {
+		return true
+	}
+	for _, pool := range poolsA {
+		if slices.Contains(poolsB, pool) {
+			return true
+		}
+	}
+	return false
+}
+
+// higherPriorityNodePools returns the names of the NodePools of higher priority than the given NodePool that are
+// competing with it for hardware and have yet to be fully allocated
+func higherPriorityNodePools(nodepool *hwmgmtv1alpha1.NodePool, priority int32, nodepools []hwmgmtv1alpha1.NodePool) []string {
+	var names []string
+	for i := range nodepools {
+		other := &nodepools[i]
+		if other.UID == nodepool.UID || !utils.IsNodePoolAllocationPending(other) || !nodePoolsCompete(nodepool, other) {
+			continue
+		}
+
+		// An invalid priority is reported on the NodePool it is set on, and otherwise treated as the default
+		otherPriority, _ := utils.GetNodePoolPriority(other)
+		if otherPriority > priority {
+			names = append(names, other.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// checkNodePoolPriority holds a new NodePool back from the adaptor while NodePools of higher priority are waiting for
+// the same hardware. It returns whether the NodePool can be handed off.
+func (c *HwMgrAdaptorController) checkNodePoolPriority(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+	if utils.GetNodePoolProvisionedCondition(nodepool) != nil || utils.IsNodePoolDryRun(nodepool) ||
+		utils.IsNodePoolReserved(nodepool) {
+		return true, nil
+	}
+
+	priority, err := utils.GetNodePoolPriority(nodepool)
+	if err != nil {
+		c.Logger.InfoContext(ctx, "NodePool priority validation failed", slog.String("error", err.Error()))
+		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+			hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse,
+			"NodePool configuration invalid: "+err.Error()); err != nil {
+			return false, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return false, nil
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.Client.List(ctx, nodepools, client.InNamespace(nodepool.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	conditionType := hwmgmtv1alpha1.ConditionType(pluginv1alpha1.ConditionTypes.WaitingForCapacity)
+	blocking := higherPriorityNodePools(nodepool, priority, nodepools.Items)
+	if len(blocking) == 0 {
+		if meta.FindStatusCondition(nodepool.Status.Conditions, string(conditionType)) != nil {
+			c.Logger.InfoContext(ctx, "NodePool no longer waiting for higher priority NodePools")
+			if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, conditionType,
+				hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.CapacityAvailable), metav1.ConditionFalse,
+				"No higher priority NodePool is waiting for hardware"); err != nil {
+				return false, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+		}
+		return true, nil
+	}
+
+	c.Logger.InfoContext(ctx, "NodePool waiting for higher priority NodePools",
+		slog.Int("priority", int(priority)), slog.Any("nodepools", blocking))
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, conditionType,
+		hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.HigherPriorityPending), metav1.ConditionTrue,
+		"Waiting for higher priority NodePools to be allocated: "+strings.Join(blocking, ", ")); err != nil {
+		return false, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return false, nil
+}
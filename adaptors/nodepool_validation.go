@@ -0,0 +1,144 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getSiteResourcePools returns the resource pools of each site of a HardwareManager, from its status where the adaptor
+// reports them there, and otherwise from the inventory of the adaptor
+func (c *HwMgrAdaptorController) getSiteResourcePools(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	adaptor adaptorinterface.HwMgrAdaptorIntf) (pluginv1alpha1.PerSiteResourcePoolList, error) {
+
+	if len(hwmgr.Status.ResourcePools) > 0 {
+		return hwmgr.Status.ResourcePools, nil
+	}
+
+	pools, _, err := adaptor.GetResourcePools(ctx, hwmgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource pools of HardwareManager %s: %w", hwmgr.Name, err)
+	}
+
+	sitePools := make(pluginv1alpha1.PerSiteResourcePoolList)
+	for _, pool := range pools {
+		site := ""
+		if pool.SiteId != nil {
+			site = *pool.SiteId
+		}
+		sitePools[site] = append(sitePools[site], pool.ResourcePoolId)
+	}
+	return sitePools, nil
+}
+
+// validateNodePoolResourcePools checks that the resource pool requested by each node group of a NodePool exists in the
+// site of the NodePool. Node groups without a resource pool are left to the adaptor to select one.
+func validateNodePoolResourcePools(nodepool *hwmgmtv1alpha1.NodePool, sitePools pluginv1alpha1.PerSiteResourcePoolList) error {
+	site := nodepool.Spec.Site
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		poolId := nodegroup.NodePoolData.ResourcePoolId
+		if poolId == "" {
+			continue
+		}
+
+		if site != "" {
+			if !slices.Contains(sitePools[site], poolId) {
+				return fmt.Errorf("resource pool %s of nodegroup %s does not exist in site %s",
+					poolId, nodegroup.NodePoolData.Name, site)
+			}
+			continue
+		}
+
+		found := false
+		for _, pools := range sitePools {
+			if slices.Contains(pools, poolId) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("resource pool %s of nodegroup %s does not exist on hardware manager",
+				poolId, nodegroup.NodePoolData.Name)
+		}
+	}
+	return nil
+}
+
+// checkNodePoolResourcePools validates the resource pools requested by a new NodePool against the inventory of the
+// HardwareManager before the NodePool is handed off to the adaptor, so that a missing pool is reported immediately
+// rather than as a failure deep in allocation. It returns whether the NodePool can be handed off.
+func (c *HwMgrAdaptorController) checkNodePoolResourcePools(
+	ctx context.Context,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	adaptor adaptorinterface.HwMgrAdaptorIntf,
+	nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+
+	if utils.GetNodePoolProvisionedCondition(nodepool) != nil {
+		return true, nil
+	}
+
+	sitePools, err := c.getSiteResourcePools(ctx, hwmgr, adaptor)
+	if err != nil {
+		// The adaptor checks the pools again during allocation, so an unavailable inventory does not hold up the request
+		c.Logger.WarnContext(ctx, "Unable to validate NodePool resource pools", slog.String("error", err.Error()))
+		return true, nil
+	}
+
+	validationErr := validateNodePoolResourcePools(nodepool, sitePools)
+	if validationErr == nil {
+		return true, nil
+	}
+
+	c.Logger.InfoContext(ctx, "NodePool resource pool validation failed", slog.String("error", validationErr.Error()))
+	return false, c.updateNodePoolInvalidInput(ctx, nodepool, validationErr)
+}
+
+// checkNodePoolResourceSelectors validates the resourceSelector of each node group of a new NodePool before the
+// NodePool is handed off to the adaptor. A malformed or ambiguous selector would otherwise match no hardware at all,
+// and be reported only as a lack of capacity. It returns whether the NodePool can be handed off.
+func (c *HwMgrAdaptorController) checkNodePoolResourceSelectors(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+	if utils.GetNodePoolProvisionedCondition(nodepool) != nil {
+		return true, nil
+	}
+
+	validationErr := utils.ValidateNodePoolResourceSelectors(nodepool)
+	if validationErr == nil {
+		return true, nil
+	}
+
+	c.Logger.InfoContext(ctx, "NodePool resourceSelector validation failed", slog.String("error", validationErr.Error()))
+	return false, c.updateNodePoolInvalidInput(ctx, nodepool, validationErr)
+}
+
+// updateNodePoolInvalidInput reports a NodePool rejected before hand-off to the adaptor, in the DryRun condition if
+// only a dry run was requested
+func (c *HwMgrAdaptorController) updateNodePoolInvalidInput(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, validationErr error) error {
+	if utils.IsNodePoolDryRun(nodepool) {
+		if err := utils.UpdateNodePoolDryRunCondition(ctx, c.Client, nodepool, validationErr, ""); err != nil {
+			return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return nil
+	}
+
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool,
+		hwmgmtv1alpha1.Provisioned, hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse,
+		"NodePool configuration invalid: "+validationErr.Error()); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+// NodePool event reasons
+const (
+	ProvisioningTimedOut = "ProvisioningTimedOut"
+	ProvisioningReleased = "ProvisioningReleased"
+)
+
+// checkProvisioningTimeout fails the provisioning of a NodePool that has exceeded its deadline, which is measured
+// from when the adaptor started provisioning it, as recorded by the transition of its Provisioned condition. It
+// returns true with the result to return if the NodePool is not to be handed to the adaptor, and otherwise the time
+// remaining until the deadline, if any.
+func (c *HwMgrAdaptorController) checkProvisioningTimeout(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	adaptor adaptorinterface.HwMgrAdaptorIntf, nodepool *hwmgmtv1alpha1.NodePool) (bool, ctrl.Result, time.Duration, error) {
+
+	condition := utils.GetNodePoolProvisionedCondition(nodepool)
+	if condition == nil || condition.Status == metav1.ConditionTrue || utils.IsNodePoolDryRun(nodepool) {
+		return false, ctrl.Result{}, 0, nil
+	}
+
+	timeout, err := utils.GetProvisioningTimeout(hwmgr, nodepool)
+	if err != nil {
+		if !typederrors.IsInputError(err) {
+			return true, utils.RequeueWithShortInterval(), 0, err
+		}
+		if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, hwmgmtv1alpha1.Provisioned,
+			hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse, err.Error()); err != nil {
+			return true, utils.RequeueWithMediumInterval(),
+				0, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+		}
+		return true, utils.DoNotRequeue(), 0, nil
+	}
+
+	if utils.IsNodePoolProvisioningTimedOut(nodepool) {
+		if timeout.ReleaseOnTimeout && nodepool.GetAnnotations()[utils.ProvisioningReleasedAnnotation] == "" {
+			result, err := c.releaseTimedOutNodePool(ctx, hwmgr, adaptor, nodepool)
+			return true, result, 0, err
+		}
+		return true, utils.DoNotRequeue(), 0, nil
+	}
+
+	// Failed and invalid NodePools are left to the adaptor, which does not progress them
+	if timeout.Timeout == 0 || utils.IsConditionFailed(condition) ||
+		condition.Reason == string(hwmgmtv1alpha1.InvalidInput) {
+		return false, ctrl.Result{}, 0, nil
+	}
+
+	remaining := time.Until(condition.LastTransitionTime.Add(timeout.Timeout))
+	if remaining > 0 {
+		return false, ctrl.Result{}, remaining, nil
+	}
+
+	message := fmt.Sprintf("Provisioning did not complete within %s", timeout.Timeout)
+	c.Logger.WarnContext(ctx, "NodePool provisioning timed out", slog.String("timeout", timeout.Timeout.String()))
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, hwmgmtv1alpha1.Provisioned,
+		hwmgmtv1alpha1.TimedOut, metav1.ConditionFalse, message); err != nil {
+		return true, utils.RequeueWithMediumInterval(),
+			0, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	c.recorder.Event(nodepool, corev1.EventTypeWarning, ProvisioningTimedOut, message)
+
+	if timeout.ReleaseOnTimeout {
+		result, err := c.releaseTimedOutNodePool(ctx, hwmgr, adaptor, nodepool)
+		return true, result, 0, err
+	}
+	return true, utils.DoNotRequeue(), 0, nil
+}
+
+// releaseTimedOutNodePool releases the hardware allocated to a NodePool that timed out through the adaptor, as is
+// done when the NodePool is deleted, and deletes its Nodes. The NodePool is annotated once released, so that it is
+// only released once.
+func (c *HwMgrAdaptorController) releaseTimedOutNodePool(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	adaptor adaptorinterface.HwMgrAdaptorIntf, nodepool *hwmgmtv1alpha1.NodePool) (ctrl.Result, error) {
+
+	c.Logger.InfoContext(ctx, "Releasing hardware of timed out NodePool")
+	released, err := adaptor.HandleNodePoolDeletion(ctx, hwmgr, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to release timed out NodePool %s: %w", nodepool.Name, err)
+	}
+	if !released {
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	nodes, err := utils.GetChildNodes(ctx, c.Logger, c.Client, nodepool)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get nodes of NodePool %s: %w", nodepool.Name, err)
+	}
+	for i := range nodes.Items {
+		if err := c.Client.Delete(ctx, &nodes.Items[i]); client.IgnoreNotFound(err) != nil {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete node %s: %w", nodes.Items[i].Name, err)
+		}
+	}
+
+	if err := utils.UpdateObjectMetaWithRetry(ctx, c.Client, nodepool.DeepCopy(),
+		[]utils.MetaMutation{utils.AddAnnotation(utils.ProvisioningReleasedAnnotation, "true")}); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to annotate NodePool %s: %w", nodepool.Name, err)
+	}
+	c.recorder.Event(nodepool, corev1.EventTypeNormal, ProvisioningReleased,
+		"Released the hardware allocated to the timed out NodePool")
+
+	return utils.DoNotRequeue(), nil
+}
+
+// requeueByDeadline shortens the requeue of a result so that the NodePool is reconciled by its provisioning deadline
+func requeueByDeadline(result ctrl.Result, remaining time.Duration) ctrl.Result {
+	if remaining <= 0 || (result.Requeue && result.RequeueAfter == 0) {
+		return result
+	}
+	if result.RequeueAfter == 0 || remaining < result.RequeueAfter {
+		result.RequeueAfter = remaining
+	}
+	return result
+}
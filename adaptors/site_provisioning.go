@@ -0,0 +1,200 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package adaptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//
+// When a wave of NodePools is created for a site, each would otherwise be handed to the adaptor at once, creating
+// their resource groups or allocating their BMHs together. The site provisioning budget of the HardwareManager limits
+// how many NodePools of a site are provisioned at once: a new NodePool is held back from the adaptor, with a SiteQueued
+// condition, while the budget of its site is taken by NodePools still being provisioned. Queued NodePools are admitted
+// in order of priority, then of creation.
+//
+
+// siteAdmissions records the NodePools admitted for provisioning by the site budget. The status of an admitted
+// NodePool is only written once the adaptor has handled it, so the admissions cover NodePools not yet seen as
+// provisioning in the cache.
+type siteAdmissions struct {
+	mu       sync.Mutex
+	admitted map[string]map[types.UID]bool
+}
+
+func newSiteAdmissions() *siteAdmissions {
+	return &siteAdmissions{
+		admitted: make(map[string]map[types.UID]bool),
+	}
+}
+
+// siteKey identifies the NodePools sharing a site budget, which is set per HardwareManager
+func siteKey(nodepool *hwmgmtv1alpha1.NodePool) string {
+	return nodepool.Spec.HwMgrId + "/" + nodepool.Spec.Site
+}
+
+// getSiteProvisioningLimit returns the number of NodePools of a site that may be provisioned at once, or 0 if it is
+// not limited
+func getSiteProvisioningLimit(hwmgr *pluginv1alpha1.HardwareManager, site string) int {
+	budget := hwmgr.Spec.SiteProvisioning
+	if budget == nil {
+		return 0
+	}
+	if limit, exists := budget.SiteLimits[site]; exists && limit > 0 {
+		return limit
+	}
+	return max(budget.MaxConcurrentNodePools, 1)
+}
+
+// isNodePoolQueuedForSite checks whether a NodePool is waiting to be handed off to the adaptor, and so competes for the
+// budget of its site
+func isNodePoolQueuedForSite(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return utils.GetNodePoolProvisionedCondition(nodepool) == nil && utils.IsNodePoolAllocationPending(nodepool) &&
+		!utils.IsNodePoolPaused(nodepool)
+}
+
+// compareQueuedNodePools orders the NodePools queued for a site by priority, then by creation
+func compareQueuedNodePools(a, b *hwmgmtv1alpha1.NodePool) int {
+	// An invalid priority is reported on the NodePool it is set on, and otherwise treated as the default
+	priorityA, _ := utils.GetNodePoolPriority(a)
+	priorityB, _ := utils.GetNodePoolPriority(b)
+	if priorityA != priorityB {
+		return int(priorityB) - int(priorityA)
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		if a.CreationTimestamp.Before(&b.CreationTimestamp) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// admit decides whether a NodePool can be handed off within the budget of its site, given the other NodePools of the
+// site. It returns the names of the NodePools taking up the budget if it cannot.
+func (s *siteAdmissions) admit(nodepool *hwmgmtv1alpha1.NodePool, limit int, nodepools []hwmgmtv1alpha1.NodePool) (bool, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := siteKey(nodepool)
+	admitted := s.admitted[key]
+	if admitted == nil {
+		admitted = make(map[types.UID]bool)
+		s.admitted[key] = admitted
+	}
+
+	var active []string
+	var queued []*hwmgmtv1alpha1.NodePool
+	pending := make(map[types.UID]bool)
+	for i := range nodepools {
+		other := &nodepools[i]
+		if other.UID == nodepool.UID || siteKey(other) != key || !utils.IsNodePoolAllocationPending(other) {
+			continue
+		}
+		pending[other.UID] = true
+		switch {
+		case admitted[other.UID] || utils.GetNodePoolProvisionedCondition(other) != nil:
+			active = append(active, other.Name)
+		case isNodePoolQueuedForSite(other):
+			queued = append(queued, other)
+		}
+	}
+
+	// Admissions are kept until the NodePool is seen to be provisioned, failed, or deleted
+	for uid := range admitted {
+		if uid != nodepool.UID && !pending[uid] {
+			delete(admitted, uid)
+		}
+	}
+
+	if admitted[nodepool.UID] {
+		return true, nil
+	}
+
+	slices.Sort(active)
+	slots := limit - len(active)
+	if slots <= 0 {
+		return false, active
+	}
+
+	// Admit the NodePool if it is among the first queued NodePools of the site that fit in the remaining budget
+	ahead := 0
+	for _, other := range queued {
+		if compareQueuedNodePools(other, nodepool) < 0 {
+			ahead++
+		}
+	}
+	if ahead >= slots {
+		return false, active
+	}
+
+	admitted[nodepool.UID] = true
+	return true, nil
+}
+
+// release forgets the admission of a NodePool that was deleted before it was provisioned
+func (s *siteAdmissions) release(nodepool *hwmgmtv1alpha1.NodePool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.admitted[siteKey(nodepool)], nodepool.UID)
+}
+
+// checkSiteProvisioning holds a new NodePool back from the adaptor while the provisioning budget of its site is taken.
+// It returns whether the NodePool can be handed off.
+func (c *HwMgrAdaptorController) checkSiteProvisioning(ctx context.Context, hwmgr *pluginv1alpha1.HardwareManager,
+	nodepool *hwmgmtv1alpha1.NodePool) (bool, error) {
+
+	conditionType := hwmgmtv1alpha1.ConditionType(pluginv1alpha1.ConditionTypes.SiteQueued)
+	limit := getSiteProvisioningLimit(hwmgr, nodepool.Spec.Site)
+	if limit == 0 || !isNodePoolQueuedForSite(nodepool) {
+		return true, nil
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.Client.List(ctx, nodepools, client.InNamespace(nodepool.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	admitted, active := c.siteAdmissions.admit(nodepool, limit, nodepools.Items)
+	if admitted {
+		if meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(conditionType)) {
+			c.Logger.InfoContext(ctx, "NodePool admitted for provisioning at its site", slog.String("site", nodepool.Spec.Site))
+			if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, conditionType,
+				hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.SiteAvailable), metav1.ConditionFalse,
+				fmt.Sprintf("Admitted for provisioning at site %s", nodepool.Spec.Site)); err != nil {
+				return false, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+		}
+		return true, nil
+	}
+
+	c.Logger.InfoContext(ctx, "NodePool queued for provisioning at its site", slog.String("site", nodepool.Spec.Site),
+		slog.Int("limit", limit), slog.Any("nodepools", active))
+	message := fmt.Sprintf("Waiting for the provisioning budget of %d NodePools of site %s", limit, nodepool.Spec.Site)
+	if len(active) > 0 {
+		message += ", taken by: " + strings.Join(active, ", ")
+	}
+	if err := utils.UpdateNodePoolStatusCondition(ctx, c.Client, nodepool, conditionType,
+		hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.SiteBusy), metav1.ConditionTrue, message); err != nil {
+		return false, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,180 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AllocationOperation is a string representing the type of hardware manager job recorded for a NodePool
+type AllocationOperation string
+
+// AllocationOperations define the types of hardware manager jobs recorded for a NodePool
+var AllocationOperations = struct {
+	Allocation    AllocationOperation
+	ProfileUpdate AllocationOperation
+	Release       AllocationOperation
+}{
+	Allocation:    "Allocation",
+	ProfileUpdate: "ProfileUpdate",
+	Release:       "Release",
+}
+
+// JobPhase is a string representing the state of a hardware manager job
+type JobPhase string
+
+// JobPhases define the states of a hardware manager job
+var JobPhases = struct {
+	InProgress JobPhase
+	Completed  JobPhase
+	Failed     JobPhase
+	Missing    JobPhase
+}{
+	InProgress: "InProgress",
+	Completed:  "Completed",
+	Failed:     "Failed",
+	Missing:    "Missing",
+}
+
+// AllocationRecordSpec identifies the NodePool tracked by an AllocationRecord
+type AllocationRecordSpec struct {
+	// NodePool is the name of the NodePool CR
+	// +kubebuilder:validation:Required
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Pool",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	NodePool string `json:"nodePool"`
+
+	// NodePoolUID is the UID of the NodePool CR, distinguishing NodePools that reuse the same name
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodePoolUID types.UID `json:"nodePoolUID,omitempty"`
+
+	// CloudID is the identifier of the O-Cloud that requested the NodePool
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	CloudID string `json:"cloudID,omitempty"`
+
+	// Site is the site requested by the NodePool
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Site string `json:"site,omitempty"`
+
+	// HwMgrId is the name of the HardwareManager that handled the NodePool
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	HwMgrId string `json:"hwMgrId,omitempty"`
+
+	// AdaptorID is the adaptor of the HardwareManager that handled the NodePool
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	AdaptorID HardwareManagerAdaptorID `json:"adaptorId,omitempty"`
+}
+
+// AllocatedNodeRecord records the hardware backing a Node allocated to the NodePool
+type AllocatedNodeRecord struct {
+	// NodeName is the name of the Node CR
+	NodeName string `json:"nodeName"`
+
+	// GroupName is the node group the Node was allocated for
+	GroupName string `json:"groupName,omitempty"`
+
+	// HwMgrNodeId identifies the hardware: the BareMetalHost name for metal3, or the resource ID for dell-hwmgr
+	HwMgrNodeId string `json:"hwMgrNodeId,omitempty"`
+
+	// HwMgrNodeNs is the namespace of the BareMetalHost, for metal3
+	HwMgrNodeNs string `json:"hwMgrNodeNs,omitempty"`
+
+	// HwProfiles lists the hardware profiles requested for the Node, in the order they were applied
+	HwProfiles []string `json:"hwProfiles,omitempty"`
+
+	// AllocatedAt is the time the Node was created
+	AllocatedAt metav1.Time `json:"allocatedAt"`
+
+	// ReleasedAt is the time the Node was found to be deleted
+	ReleasedAt *metav1.Time `json:"releasedAt,omitempty"`
+}
+
+// JobRecord records a hardware manager job run for the NodePool
+type JobRecord struct {
+	// JobId is the identifier of the job on the hardware manager
+	JobId string `json:"jobId"`
+
+	// Operation is the purpose of the job
+	// +kubebuilder:validation:Enum=Allocation;ProfileUpdate;Release
+	Operation AllocationOperation `json:"operation"`
+
+	// NodeName is the Node the job applies to, for per-node jobs
+	NodeName string `json:"nodeName,omitempty"`
+
+	// ObservedAt is the time the job was first seen
+	ObservedAt metav1.Time `json:"observedAt"`
+
+	// Phase is the state of the job, as last reported by the hardware manager. A job purged by the hardware manager
+	// before its outcome was seen is Missing.
+	// +kubebuilder:validation:Enum=InProgress;Completed;Failed;Missing
+	Phase JobPhase `json:"phase,omitempty"`
+
+	// FailReason is the reason given by the hardware manager for a Failed job
+	FailReason string `json:"failReason,omitempty"`
+
+	// FailCode classifies the FailReason of a Failed job, such as InsufficientResources, or is Unknown if it matches
+	// no classification rule
+	FailCode string `json:"failCode,omitempty"`
+
+	// LastTransitionTime is the time the phase of the job last changed
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// CompletedAt is the time the job was seen to have finished, successfully or not
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// AllocationRecordStatus records the hardware allocated to the NodePool over its lifetime
+type AllocationRecordStatus struct {
+	// Nodes lists the Nodes allocated to the NodePool, including those since released
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Nodes []AllocatedNodeRecord `json:"nodes,omitempty"`
+
+	// Jobs lists the hardware manager jobs run for the NodePool
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Jobs []JobRecord `json:"jobs,omitempty"`
+
+	// ReleasedAt is the time the NodePool was found to be deleted
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ReleasedAt *metav1.Time `json:"releasedAt,omitempty"`
+
+	// ExpiresAt is the time the record will be deleted, once the retention period after release has passed
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// +operator-sdk:csv:customresourcedefinitions:resources={{Service,v1,policy-engine-service}}
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=allocationrecords,scope=Namespaced
+// +kubebuilder:resource:shortName=allocrec;allocrecs
+// +kubebuilder:printcolumn:name="NodePool",type="string",JSONPath=".spec.nodePool"
+// +kubebuilder:printcolumn:name="HwMgr",type="string",JSONPath=".spec.hwMgrId"
+// +kubebuilder:printcolumn:name="Released",type="date",JSONPath=".status.releasedAt"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="The age of the AllocationRecord resource."
+
+// AllocationRecord is the Schema for the allocationrecords API, providing an audit trail of the hardware allocated
+// to a NodePool that is kept after the NodePool is deleted
+type AllocationRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AllocationRecordSpec   `json:"spec,omitempty"`
+	Status AllocationRecordStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// AllocationRecordList contains a list of AllocationRecord
+type AllocationRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AllocationRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AllocationRecord{}, &AllocationRecordList{})
+}
@@ -29,9 +29,51 @@ type ConditionType string
 
 // ConditionTypes define the different types of conditions that will be set
 var ConditionTypes = struct {
-	Validation ConditionType
+	Validation             ConditionType
+	BMCCredentialsRotation ConditionType
+	DryRun                 ConditionType
+	PowerAction            ConditionType
+	Deletion               ConditionType
+	Drifted                ConditionType
+	AwaitingWindow         ConditionType
+	Replacement            ConditionType
+	Degraded               ConditionType
+	AwaitingInspection     ConditionType
+	OrphanedResourceGroups ConditionType
+	WaitingForCapacity     ConditionType
+	Healthy                ConditionType
+	AllocationHook         ConditionType
+	Reserved               ConditionType
+	InsufficientResources  ConditionType
+	Paused                 ConditionType
+	ArtifactsCached        ConditionType
+	TagsSynced             ConditionType
+	SiteQueued             ConditionType
+	Validated              ConditionType
+	InUse                  ConditionType
 }{
-	Validation: "Validation",
+	Validation:             "Validation",
+	BMCCredentialsRotation: "BMCCredentialsRotation",
+	DryRun:                 "DryRun",
+	PowerAction:            "PowerAction",
+	Deletion:               "Deletion",
+	Drifted:                "Drifted",
+	AwaitingWindow:         "AwaitingWindow",
+	Replacement:            "Replacement",
+	Degraded:               "Degraded",
+	AwaitingInspection:     "AwaitingInspection",
+	OrphanedResourceGroups: "OrphanedResourceGroups",
+	WaitingForCapacity:     "WaitingForCapacity",
+	Healthy:                "Healthy",
+	AllocationHook:         "AllocationHook",
+	Reserved:               "Reserved",
+	InsufficientResources:  "InsufficientResources",
+	Paused:                 "Paused",
+	ArtifactsCached:        "ArtifactsCached",
+	TagsSynced:             "TagsSynced",
+	SiteQueued:             "SiteQueued",
+	Validated:              "Validated",
+	InUse:                  "InUse",
 }
 
 // ConditionReason is a string representing the condition's reason
@@ -39,13 +81,51 @@ type ConditionReason string
 
 // ConditionReasons define the different reasons that conditions will be set for
 var ConditionReasons = struct {
-	Completed  ConditionReason
-	Failed     ConditionReason
-	InProgress ConditionReason
+	Completed             ConditionReason
+	Failed                ConditionReason
+	InProgress            ConditionReason
+	DriftDetected         ConditionReason
+	WindowClosed          ConditionReason
+	WindowOpen            ConditionReason
+	Diverged              ConditionReason
+	CircuitOpen           ConditionReason
+	HigherPriorityPending ConditionReason
+	CapacityAvailable     ConditionReason
+	HardwareHealthy       ConditionReason
+	HardwareFault         ConditionReason
+	HealthUnknown         ConditionReason
+	ResourcesUnavailable  ConditionReason
+	PauseRequested        ConditionReason
+	Resumed               ConditionReason
+	SiteBusy              ConditionReason
+	SiteAvailable         ConditionReason
+	SchemaIncompatible    ConditionReason
+	FirmwareUnreachable   ConditionReason
+	Referenced            ConditionReason
+	Unreferenced          ConditionReason
 }{
-	Completed:  "Completed",
-	Failed:     "Failed",
-	InProgress: "InProgress",
+	Completed:             "Completed",
+	Failed:                "Failed",
+	InProgress:            "InProgress",
+	DriftDetected:         "DriftDetected",
+	WindowClosed:          "WindowClosed",
+	WindowOpen:            "WindowOpen",
+	Diverged:              "Diverged",
+	CircuitOpen:           "CircuitOpen",
+	HigherPriorityPending: "HigherPriorityPending",
+	CapacityAvailable:     "CapacityAvailable",
+	HardwareHealthy:       "HardwareHealthy",
+	HardwareFault:         "HardwareFault",
+	HealthUnknown:         "HealthUnknown",
+	ResourcesUnavailable:  "ResourcesUnavailable",
+	PauseRequested:        "PauseRequested",
+	Resumed:               "Resumed",
+	SiteBusy:              "SiteBusy",
+	SiteAvailable:         "SiteAvailable",
+	SchemaIncompatible:    "SchemaIncompatible",
+	FirmwareUnreachable:   "FirmwareUnreachable",
+	Referenced:            "Referenced",
+	Unreferenced:          "Unreferenced",
 }
 
 // OAuthGrantType is a string representing the OAuth2 grant type
@@ -65,6 +145,73 @@ type LoopbackData struct {
 	// A test string
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	AddtionalInfo string `json:"additionalInfo,omitempty"`
+
+	// Scale generates a synthetic inventory of resource pools and nodes, in addition to the resources defined in the
+	// loopback-adaptor-nodelist configmap, for performance testing of the inventory server and its consumers.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Scale *LoopbackScale `json:"scale,omitempty"`
+}
+
+// LoopbackScale defines a synthetic inventory generated by the loopback adaptor
+type LoopbackScale struct {
+	// NumPools is the number of resource pools to generate, named <poolPrefix>-<index>
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NumPools int `json:"numPools"`
+
+	// NodesPerPool is the number of nodes to generate in each resource pool, named <pool>-node-<index>
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	NodesPerPool int `json:"nodesPerPool"`
+
+	// PoolPrefix is the prefix of the generated resource pool names
+	// +kubebuilder:default=scale-pool
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	PoolPrefix string `json:"poolPrefix,omitempty"`
+
+	// Template defines the hardware properties of each generated node
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Template LoopbackHardwareTemplate `json:"template,omitempty"`
+}
+
+// LoopbackHardwareTemplate defines the hardware properties of generated loopback nodes
+type LoopbackHardwareTemplate struct {
+	// +optional
+	Vendor string `json:"vendor,omitempty"`
+
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// Memory is the memory size, in MiB
+	// +optional
+	Memory int `json:"memory,omitempty"`
+
+	// +optional
+	Processors []LoopbackProcessor `json:"processors,omitempty"`
+
+	// Labels are added to each generated node
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// LoopbackProcessor defines a processor of a generated loopback node
+type LoopbackProcessor struct {
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// +optional
+	Cores int `json:"cores,omitempty"`
+
+	// +optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+
+	// +optional
+	Model string `json:"model,omitempty"`
 }
 
 // DellData defines configuration data for dell-hwmgr adaptor instance
@@ -74,25 +221,462 @@ type DellData struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	AuthSecret string `json:"authSecret"`
 
+	// ApiUrl is the URL of the hardware manager API. An IPv6 address is enclosed in brackets, as in
+	// https://[fd00::10]:8443/; an address given without brackets is taken as having no port.
 	// +kubebuilder:validation:Required
 	// +required
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	ApiUrl string `json:"apiUrl"`
 
+	// GrantType selects the OAuth grant used to acquire a token from the hardware manager. The password grant uses the
+	// client-id, username, and password fields of the authSecret, while the client_credentials grant uses the client-id
+	// and client-secret fields.
+	// +kubebuilder:validation:Enum=password;client_credentials
+	// +kubebuilder:default=password
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	GrantType OAuthGrantType `json:"grantType,omitempty"`
+
+	// TokenUrl overrides the URL used to acquire a token with the client_credentials grant. If not provided, the token
+	// endpoint of the hardware manager at apiUrl is used.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	TokenUrl *string `json:"tokenUrl,omitempty"`
+
 	// CaBundleName references a config map that contains a set of custom CA certificates to be used when communicating
 	// with a hardware manager that has its TLS certificate signed by a non-public CA certificate.
 	// +optional
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom CA Certificates",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	CaBundleName *string `json:"caBundleName,omitempty"`
 
+	// ClientCertSecret references a kubernetes.io/tls secret that contains the client certificate and key to be presented
+	// to a hardware manager that requires mutual TLS authentication.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Client Certificate Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	ClientCertSecret *string `json:"clientCertSecret,omitempty"`
+
+	// CallbackSecret references a secret that contains the bearer token, in the token field, that the hardware manager
+	// presents when posting job status callbacks to the Plugin. Callbacks are rejected if not provided.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Callback Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	CallbackSecret *string `json:"callbackSecret,omitempty"`
+
+	// FailReasonRulesName references a config map whose rules field holds additional rules for classifying the
+	// reasons given by the hardware manager for failed jobs. The rules are matched before the built-in rules.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Fail Reason Rules"
+	FailReasonRulesName *string `json:"failReasonRulesName,omitempty"`
+
 	// Tenant allows the specification of the hardware manager tenant to use for this instance.
 	// +optional
 	Tenant *string `json:"tenant,omitempty"`
 
+	// TenantMapping derives the tenant used for the resource groups of each NodePool, allowing one HardwareManager to
+	// serve multiple O-Cloud tenants with isolated resource groups. NodePools that are not mapped to a tenant use the
+	// Tenant of the HardwareManager.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tenant Mapping"
+	TenantMapping *TenantMapping `json:"tenantMapping,omitempty"`
+
 	// insecureSkipTLSVerify indicates that the plugin should not confirm the validity of the TLS certificate of the hardware manager.
 	// This is insecure and is not recommended.
 	// +optional
 	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// ResourceGroupValidationInterval sets how often the resource group of a provisioned NodePool is re-validated
+	// against the NodePool, reporting any divergence in a Degraded condition. If not provided, it defaults to 15m.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Group Validation Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ResourceGroupValidationInterval *metav1.Duration `json:"resourceGroupValidationInterval,omitempty"`
+
+	// HealthCheckInterval sets how often the health of the servers allocated to a provisioned NodePool is polled from the
+	// hardware manager, reporting faults in the Healthy condition of each Node and the Degraded condition of the
+	// NodePool. If not provided, it defaults to 5m.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Health Check Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	HealthCheckInterval *metav1.Duration `json:"healthCheckInterval,omitempty"`
+
+	// CircuitBreaker configures the circuit breaker on calls to the hardware manager API. If not provided, the circuit
+	// opens after 5 consecutive failures, for a cooldown of 2m.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Circuit Breaker"
+	CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty"`
+
+	// ResourceGroupGC enables periodic checks for resource groups left on the hardware manager by the Plugin for
+	// NodePools that no longer exist, such as when a NodePool is deleted while the Plugin is down. If not provided,
+	// orphaned resource groups are not checked for.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Group Garbage Collection"
+	ResourceGroupGC *ResourceGroupGCConfig `json:"resourceGroupGC,omitempty"`
+
+	// CredentialVault resolves the BMC credentials of each resource from a secret on the hardware manager named by a
+	// vault prefix and the resource ID, instead of the secret referenced by the password field of the resource. If not
+	// provided, the secret referenced by the resource is used.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Credential Vault"
+	CredentialVault *CredentialVaultConfig `json:"credentialVault,omitempty"`
+}
+
+// CredentialVaultConfig defines how the BMC credentials of resources are resolved from a credential vault on the
+// hardware manager. The credentials of a resource are only fetched when its bmc-secret is created, and are cached so
+// that a large allocation does not fetch the same secret repeatedly.
+type CredentialVaultConfig struct {
+	// Prefix is prepended to the ID of a resource to form the key of the secret that holds its BMC credentials
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Prefix",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Prefix string `json:"prefix"`
+
+	// CacheTTL sets how long fetched credentials are cached. If not provided, it defaults to 5m.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cache TTL",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	CacheTTL *metav1.Duration `json:"cacheTTL,omitempty"`
+}
+
+// ResourceGroupGCConfig defines the checks for orphaned resource groups on a hardware manager
+type ResourceGroupGCConfig struct {
+	// Interval sets how often the resource groups are checked. If not provided, it defaults to 1h.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Delete enables the deletion of orphaned resource groups that are found by two consecutive checks. If false,
+	// orphaned resource groups are only reported in the OrphanedResourceGroups condition of the HardwareManager.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Delete",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Delete bool `json:"delete,omitempty"`
+}
+
+// TenantMapping defines how the hardware manager tenant of a NodePool is derived. The tenant is resolved when the
+// NodePool is first handled, and recorded on the NodePool so that it does not change while its resource group exists.
+type TenantMapping struct {
+	// ExtensionKey is the key of the NodePool extension that names the tenant of the NodePool
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Extension Key",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	ExtensionKey string `json:"extensionKey,omitempty"`
+
+	// NamespaceLabel is the key of the label that names the tenant on the namespace of the cluster of the NodePool,
+	// which is the namespace named by the cloud ID of the NodePool. It is used when the tenant is not set by a NodePool
+	// extension.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Label",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	NamespaceLabel string `json:"namespaceLabel,omitempty"`
+
+	// AllowedTenants restricts the tenants that NodePools can be mapped to. A NodePool mapped to any other tenant is
+	// rejected as invalid input. If not provided, NodePools can be mapped to any tenant.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Allowed Tenants"
+	AllowedTenants []string `json:"allowedTenants,omitempty"`
+}
+
+// BmcSecretBackendType is a string representing where the BMC credentials of a node are kept
+type BmcSecretBackendType string
+
+// BmcSecretBackendTypes define the supported BMC secret backends
+var BmcSecretBackendTypes = struct {
+	Kubernetes     BmcSecretBackendType
+	ExternalSecret BmcSecretBackendType
+	Reference      BmcSecretBackendType
+}{
+	Kubernetes:     "Kubernetes",
+	ExternalSecret: "ExternalSecret",
+	Reference:      "Reference",
+}
+
+// BmcSecretBackend defines how the BMC credentials of a node are provided in its bmc-secret
+type BmcSecretBackend struct {
+	// Type selects the backend. Kubernetes writes the credentials retrieved from the hardware manager to the
+	// bmc-secret. ExternalSecret creates an External Secrets Operator ExternalSecret that populates the bmc-secret from
+	// an external secret store, so the credentials are never handled by the Plugin. Reference writes only a reference to
+	// the credentials held by the hardware manager to the bmc-secret, for consumers that retrieve them from there.
+	// +kubebuilder:validation:Enum=Kubernetes;ExternalSecret;Reference
+	// +kubebuilder:default=Kubernetes
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Type"
+	Type BmcSecretBackendType `json:"type,omitempty"`
+
+	// ExternalSecret configures the ExternalSecret backend, and is required when it is selected
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="External Secret"
+	ExternalSecret *ExternalSecretBackend `json:"externalSecret,omitempty"`
+}
+
+// ExternalSecretBackend defines the ExternalSecrets created for the bmc-secrets of nodes
+type ExternalSecretBackend struct {
+	// SecretStoreName is the name of the SecretStore or ClusterSecretStore holding the BMC credentials
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Store Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	SecretStoreName string `json:"secretStoreName"`
+
+	// SecretStoreKind is the kind of the secret store
+	// +kubebuilder:validation:Enum=SecretStore;ClusterSecretStore
+	// +kubebuilder:default=SecretStore
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret Store Kind",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	SecretStoreKind string `json:"secretStoreKind,omitempty"`
+
+	// KeyTemplate is a Go template for the key of the BMC credentials of a node in the secret store, with the
+	// .NodeName, .NodePool, .CloudID, and .Reference fields available, where .Reference identifies the credentials on
+	// the hardware manager. The username and password properties of the key are used. If not provided, the key is
+	// the reference.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Key Template",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	KeyTemplate string `json:"keyTemplate,omitempty"`
+
+	// RefreshInterval is how often the External Secrets Operator refreshes the bmc-secret from the secret store. If not
+	// provided, it defaults to 1h.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Refresh Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// CircuitBreakerConfig defines when calls to a hardware manager are failed fast rather than attempted
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls after which the circuit opens
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Failure Threshold",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
+
+	// Cooldown is how long the circuit stays open before a trial call is allowed through
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cooldown",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+}
+
+// Metal3Data defines configuration data for metal3 adaptor instance
+type Metal3Data struct {
+	// AllowedNamespaces restricts the namespaces of the BareMetalHosts that can be allocated through this hardware
+	// manager, allowing tenants that share a hub cluster to be given separate hardware. If not provided, BareMetalHosts
+	// in any namespace can be allocated.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Allowed Namespaces"
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// DriftDetectionInterval enables periodic checks of the BIOS settings and firmware versions of allocated
+	// BareMetalHosts against the hardware profiles of their nodes, at the given interval. Nodes that no longer match
+	// their profile are reported with a Drifted condition. If not provided, drift is not checked.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Drift Detection Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	DriftDetectionInterval *metav1.Duration `json:"driftDetectionInterval,omitempty"`
+
+	// FirmwarePreflight enables checks that the firmware images of a hardware profile can be downloaded before the
+	// firmware updates are set on a BareMetalHost, and configures the download of images that are verified against
+	// their checksum or signature. If not provided, only the syntax of the firmware URLs is checked for images that are
+	// not verified.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Firmware Preflight"
+	FirmwarePreflight *FirmwarePreflight `json:"firmwarePreflight,omitempty"`
+
+	// UpdateTimeouts limits how long a node may stay in each phase of a hardware configuration update. A node that
+	// exceeds the timeout of its phase has the update failed with a TimedOut condition. If not provided, the default
+	// timeouts are used.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Update Timeouts"
+	UpdateTimeouts *UpdateTimeouts `json:"updateTimeouts,omitempty"`
+
+	// UpdateErrorRemediation sets how a day-2 hardware configuration update handles a BareMetalHost found in an error
+	// state when its update is started. If not provided, the node is failed without retries and the rollout of its
+	// NodePool is aborted.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Update Error Remediation"
+	UpdateErrorRemediation *UpdateErrorRemediation `json:"updateErrorRemediation,omitempty"`
+
+	// MaxConcurrentUpdates limits the number of BareMetalHosts allocated through this hardware manager that may be
+	// updated at once, across all of its NodePools, so that firmware and BIOS settings updates do not overwhelm shared
+	// infrastructure such as image servers. Nodes beyond the limit wait for a running update to finish. If not
+	// provided, only the update strategy of each NodePool limits its updates.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Concurrent Updates",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	MaxConcurrentUpdates *int `json:"maxConcurrentUpdates,omitempty"`
+
+	// ArtifactCache sets the firmware updates of BareMetalHosts allocated through this hardware manager to download
+	// the firmware images, and verify their signatures, from the in-cluster artifact cache rather than their original
+	// URLs, for sites where the BMCs cannot reach the firmware servers. Updates wait until the images of their hardware
+	// profile are cached. If not provided, the original URLs are used.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Artifact Cache"
+	ArtifactCache *ArtifactCache `json:"artifactCache,omitempty"`
+}
+
+// ArtifactCache defines how BareMetalHosts reach the in-cluster artifact cache
+type ArtifactCache struct {
+	// URL is the base URL of the artifact cache server as reached from the BMCs, such as the address of a route or
+	// node port service exposing it
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="URL",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	URL string `json:"url"`
+}
+
+// UpdateTimeouts defines the timeouts of the phases of a hardware configuration update
+type UpdateTimeouts struct {
+	// Pending is the timeout of the Pending phase, from when the update is requested until the BareMetalHost starts
+	// applying it. Defaults to 30 minutes.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pending",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Pending *metav1.Duration `json:"pending,omitempty"`
+
+	// Updating is the timeout of the Updating phase, while the BareMetalHost is preparing or servicing. Defaults to
+	// 4 hours, allowing for firmware updates.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Updating",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Updating *metav1.Duration `json:"updating,omitempty"`
+}
+
+// UpdateErrorAction is a string representing what is done with a node whose BareMetalHost is still in an error state
+// once the retries of its update are exhausted
+type UpdateErrorAction string
+
+// UpdateErrorActions define the supported update error actions
+var UpdateErrorActions = struct {
+	Abort    UpdateErrorAction
+	SkipNode UpdateErrorAction
+}{
+	Abort:    "Abort",
+	SkipNode: "SkipNode",
+}
+
+// UpdateErrorRemediation defines how a day-2 update handles a BareMetalHost in an error state
+type UpdateErrorRemediation struct {
+	// Retries is the number of times the update of a node is requested again while its BareMetalHost is in an error
+	// state, before the Action is taken. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Retries",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Retries int `json:"retries,omitempty"`
+
+	// RetryInterval is the time between retries. Defaults to 5 minutes.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Retry Interval",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+
+	// Action is taken once the retries are exhausted. Abort fails the node and stops the rollout of its NodePool, letting
+	// the updates already started complete. SkipNode fails the node and continues the rollout with the other nodes.
+	// +kubebuilder:validation:Enum=Abort;SkipNode
+	// +kubebuilder:default=Abort
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Action"
+	Action UpdateErrorAction `json:"action,omitempty"`
+}
+
+// FirmwarePreflight defines the checks of firmware images before they are used for an update. The images are
+// requested through the proxy configuration of the HardwareManager.
+type FirmwarePreflight struct {
+	// CaBundleName is the name of a configmap in the plugin namespace that provides, in the ca-bundle.pem field, the
+	// CA certificates used to verify the firmware servers, in addition to the default CA bundle
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="CA Bundle Name",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	CaBundleName *string `json:"caBundleName,omitempty"`
+
+	// InsecureSkipTLSVerify disables the verification of the certificates of the firmware servers
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Insecure Skip TLS Verify",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+
+	// Timeout of the check of each firmware image, including its download when a checksum is verified. Defaults to
+	// 5 minutes.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Timeout",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Weekday is a day of the week, by its three letter abbreviation
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type Weekday string
+
+// MaintenanceWindow defines when day-2 hardware updates may be started. A recurring window opens at StartTime on each
+// of the given Days and stays open for Duration. Start and End bound the window to a fixed period, and can be used
+// without a recurring window for a one-off window.
+type MaintenanceWindow struct {
+	// Days of the week on which the recurring window opens. If not provided, the window opens every day.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Days []Weekday `json:"days,omitempty"`
+
+	// StartTime is the time of day the recurring window opens, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Start Time",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	StartTime string `json:"startTime,omitempty"`
+
+	// Duration is how long the recurring window stays open. Required with StartTime.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// TimeZone of StartTime, as an IANA time zone name. Defaults to UTC.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Time Zone",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Start is the time before which updates are not started
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Start *metav1.Time `json:"start,omitempty"`
+
+	// End is the time after which updates are not started
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	End *metav1.Time `json:"end,omitempty"`
+}
+
+// ProxyConfig defines the proxies used for outbound connections to the hardware manager. The values follow the
+// conventions of the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+type ProxyConfig struct {
+	// HTTPProxy is the URL of the proxy for http requests
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="HTTP Proxy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for https requests
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="HTTPS Proxy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hostnames, domains, IP addresses, or CIDRs that are connected to directly
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="No Proxy",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// LogLevel is the minimum severity of the logs written
+// +kubebuilder:validation:Enum=debug;info;warn;error
+type LogLevel string
+
+// ComponentLogLevels sets the log levels of individual components of the Plugin
+type ComponentLogLevels struct {
+	// Adaptor is the log level of the adaptor handling the HardwareManager
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:debug","urn:alm:descriptor:com.tectonic.ui:select:info","urn:alm:descriptor:com.tectonic.ui:select:warn","urn:alm:descriptor:com.tectonic.ui:select:error"}
+	Adaptor LogLevel `json:"adaptor,omitempty"`
+
+	// HwMgrClient is the log level of the client of the hardware manager API. At debug, the requests and responses
+	// exchanged with the hardware manager are traced, with credentials redacted.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Hardware Manager Client",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:debug","urn:alm:descriptor:com.tectonic.ui:select:info","urn:alm:descriptor:com.tectonic.ui:select:warn","urn:alm:descriptor:com.tectonic.ui:select:error"}
+	HwMgrClient LogLevel `json:"hwmgrClient,omitempty"`
+
+	// Server is the log level of the inventory and job callback servers, for requests concerning the HardwareManager
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:debug","urn:alm:descriptor:com.tectonic.ui:select:info","urn:alm:descriptor:com.tectonic.ui:select:warn","urn:alm:descriptor:com.tectonic.ui:select:error"}
+	Server LogLevel `json:"server,omitempty"`
+}
+
+// LoggingConfig defines the log levels of the Plugin for the work done on behalf of a HardwareManager. Changes take
+// effect without restarting the Plugin, and do not affect the logs of other HardwareManagers.
+type LoggingConfig struct {
+	// Level is the log level of the components without a level of their own. If not provided, the default level of
+	// the Plugin is used.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:select:debug","urn:alm:descriptor:com.tectonic.ui:select:info","urn:alm:descriptor:com.tectonic.ui:select:warn","urn:alm:descriptor:com.tectonic.ui:select:error"}
+	Level LogLevel `json:"level,omitempty"`
+
+	// Components sets the log levels of individual components, overriding Level
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Components *ComponentLogLevels `json:"components,omitempty"`
 }
 
 // HardwareManagerSpec defines the desired state of HardwareManager
@@ -112,6 +696,106 @@ type HardwareManagerSpec struct {
 	// Config data for an instance of the dell-hwmgr adaptor
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	DellData *DellData `json:"dellData,omitempty"`
+
+	// Config data for an instance of the metal3 adaptor
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Metal3Data *Metal3Data `json:"metal3Data,omitempty"`
+
+	// BmcCredentialsSecret references a secret in the plugin namespace, with username and password fields, that holds
+	// the BMC credentials for nodes allocated from this hardware manager, unless overridden by the HardwareProfile.
+	// When set, or when the contents of the secret change, the credentials are rotated on each allocated node.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="BMC Credentials Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	BmcCredentialsSecret *string `json:"bmcCredentialsSecret,omitempty"`
+
+	// BmcSecretBackend selects how the BMC credentials of the nodes allocated from this hardware manager are provided in
+	// their bmc-secrets. If not provided, the credentials are written to a Kubernetes Secret for each node.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="BMC Secret Backend"
+	BmcSecretBackend *BmcSecretBackend `json:"bmcSecretBackend,omitempty"`
+
+	// MaintenanceWindow restricts when BIOS settings, firmware, and hardware profile updates may be started on the
+	// nodes allocated from this hardware manager. Updates already in progress when the window closes run to completion.
+	// If not provided, updates may be started at any time.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Maintenance Window"
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// Proxy configures the proxies used for outbound connections to the hardware manager. If not provided, the
+	// HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables of the plugin are used.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Proxy"
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// Logging adjusts the log levels of the Plugin for this hardware manager, such as to trace the messages exchanged
+	// with it. If not provided, the default log levels are used.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Logging"
+	Logging *LoggingConfig `json:"logging,omitempty"`
+
+	// NodeMetadata sets labels and annotations to be added to each Node CR created for this hardware manager, such as
+	// to identify the site, owner, or environment of the node. The Node CRs of existing allocations are not updated.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node Metadata"
+	NodeMetadata *NodeMetadata `json:"nodeMetadata,omitempty"`
+
+	// ProvisioningTimeout limits how long the NodePools served by this hardware manager may take to be provisioned.
+	// A NodePool that is not provisioned in time has its Provisioned condition set to False with a TimedOut reason.
+	// It can be overridden for a NodePool by its provisioningTimeout extension. If not provided, provisioning is not
+	// limited.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Provisioning Timeout"
+	ProvisioningTimeout *ProvisioningTimeout `json:"provisioningTimeout,omitempty"`
+
+	// SiteProvisioning limits how many of the NodePools of a site served by this hardware manager are provisioned at
+	// once, so that a wave of NodePools created together does not overload the hardware manager. NodePools beyond the
+	// limit wait, with a SiteQueued condition, until a NodePool of their site is provisioned. If not provided, each
+	// NodePool is provisioned as soon as it is created.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Site Provisioning"
+	SiteProvisioning *SiteProvisioning `json:"siteProvisioning,omitempty"`
+}
+
+// SiteProvisioning defines the concurrency budget for provisioning the NodePools of each site
+type SiteProvisioning struct {
+	// MaxConcurrentNodePools is the number of NodePools of a site that may be provisioned at once. Setting it to 1
+	// provisions the NodePools of a site one at a time.
+	// +kubebuilder:validation:Minimum=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max Concurrent NodePools",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	MaxConcurrentNodePools int `json:"maxConcurrentNodePools"`
+
+	// SiteLimits overrides MaxConcurrentNodePools for individual sites, by site name
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Site Limits"
+	SiteLimits map[string]int `json:"siteLimits,omitempty"`
+}
+
+// ProvisioningTimeout defines the deadline for provisioning a NodePool, and what happens when it is exceeded
+type ProvisioningTimeout struct {
+	// Timeout is the time allowed from when the adaptor starts provisioning a NodePool until it is provisioned
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Timeout",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	Timeout metav1.Duration `json:"timeout"`
+
+	// ReleaseOnTimeout releases the hardware already allocated to a NodePool that timed out, so that it can be used
+	// by other NodePools. Otherwise, the partial allocation is kept until the NodePool is deleted.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Release On Timeout",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	ReleaseOnTimeout bool `json:"releaseOnTimeout,omitempty"`
+}
+
+// NodeMetadata defines the labels and annotations added to new Node CRs
+type NodeMetadata struct {
+	// Labels to add to each new Node CR
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to add to each new Node CR. Annotations in the hwmgr-plugin.oran.openshift.io domain are reserved
+	// for the plugin, and are not added.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type ResourcePoolList []string
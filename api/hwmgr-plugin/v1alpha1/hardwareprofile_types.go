@@ -11,11 +11,26 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// BiosSetting describes how a change to a BIOS attribute takes effect
+type BiosSetting struct {
+	// RebootRequired indicates whether the host must be rebooted for a change to the attribute to take effect.
+	// Defaults to true.
+	// +optional
+	RebootRequired *bool `json:"rebootRequired,omitempty"`
+}
+
 // Bios defines attributes as key value pairs
 type Bios struct {
 
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	Attributes map[string]intstr.IntOrString `json:"attributes,omitempty"`
+
+	// Settings describes individual attributes, by attribute name. When every attribute changed by a day-2 update is
+	// flagged as not requiring a reboot, the metal3 adaptor does not reboot the host, and the settings are applied at
+	// its next reboot.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	Settings map[string]BiosSetting `json:"settings,omitempty"`
 }
 
 type Firmware struct {
@@ -23,15 +38,45 @@ type Firmware struct {
 	Version string `json:"version,omitempty"`
 	// URL points to the firmware file
 	URL string `json:"url,omitempty"`
+	// Checksum is the SHA-256 digest of the firmware file, as a hex string. When set, the file is downloaded and
+	// verified before the update is started.
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// Signature is a detached signature of the firmware file. When set, the file is downloaded and the signature
+	// verified before the update is started.
+	// +optional
+	Signature *FirmwareSignature `json:"signature,omitempty"`
+}
+
+// FirmwareSignature identifies the detached signature of a firmware file and the key that it is verified with. The
+// signature is of the SHA-256 digest of the file, made with an RSA (PKCS #1 v1.5) or ECDSA key, such as produced by
+// "openssl dgst -sha256 -sign".
+type FirmwareSignature struct {
+	// URL points to the signature file
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// PublicKeyName is the name of a configmap in the plugin namespace that provides the PEM encoded public key in
+	// the public-key.pem field
+	// +kubebuilder:validation:MinLength=1
+	PublicKeyName string `json:"publicKeyName"`
 }
 
 // HardwareProfileSpec defines the desired state of HardwareProfile
 type HardwareProfileSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Bios defines a set of bios attributes
+	// BaseProfile is the name of a HardwareProfile in the same namespace that this profile extends. The BIOS
+	// attributes of this profile are merged over those of the base profile, and the firmware and BMC credentials
+	// secret set in this profile replace those of the base profile. A base profile may itself extend another profile.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Base Profile",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
+	BaseProfile string `json:"baseProfile,omitempty"`
+
+	// Bios defines a set of bios attributes. It may be omitted from a profile that extends a base profile.
+	// +optional
 	//+operator-sdk:csv:customresourcedefinitions:type=spec
-	Bios Bios `json:"bios"`
+	Bios Bios `json:"bios,omitempty"`
 
 	// BIOS firmware information
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="BIOS Firmware",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
@@ -40,6 +85,19 @@ type HardwareProfileSpec struct {
 	// BMC firmware information
 	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="BMC Firmware",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:text"}
 	BmcFirmware Firmware `json:"bmcFirmware,omitempty"`
+
+	// BmcCredentialsSecret references a secret in the plugin namespace, with username and password fields, that holds
+	// the BMC credentials for nodes allocated with this profile. When set, or when the contents of the secret change,
+	// the credentials are rotated on each allocated node. This takes precedence over the HardwareManager setting.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="BMC Credentials Secret",xDescriptors={"urn:alm:descriptor:io.kubernetes:Secret"}
+	BmcCredentialsSecret string `json:"bmcCredentialsSecret,omitempty"`
+
+	// AutoRemediate re-applies this profile to allocated nodes that are found to have drifted from it, when drift
+	// detection is enabled for their hardware manager. Otherwise, drift is only reported.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Auto Remediate",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	AutoRemediate bool `json:"autoRemediate,omitempty"`
 }
 
 // HardwareProfileStatus defines the observed state of HardwareProfile
@@ -55,6 +113,32 @@ type HardwareProfileStatus struct {
 	// +kubebuilder:validation:Optional
 	//+operator-sdk:csv:customresourcedefinitions:type=status
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// CachedArtifacts lists the firmware images and signatures of the resolved profile that are mirrored to the
+	// in-cluster artifact cache, when the artifact cache is enabled
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	CachedArtifacts []CachedArtifact `json:"cachedArtifacts,omitempty"`
+
+	// Nodes lists the Nodes using the profile, either directly, through a profile built on it, or through a hardware
+	// profile override. A profile with Nodes listed is applied to allocated hardware when edited.
+	// +optional
+	//+operator-sdk:csv:customresourcedefinitions:type=status
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// CachedArtifact is a file mirrored to the artifact cache
+type CachedArtifact struct {
+	// URL is the original URL of the file
+	URL string `json:"url"`
+	// Path is the path of the file on the artifact cache server
+	Path string `json:"path"`
+	// Size is the size of the file in bytes
+	Size int64 `json:"size"`
+	// Checksum is the SHA-256 digest of the file, as a hex string
+	Checksum string `json:"checksum"`
+	// CachedAt is the time the file was downloaded to the cache
+	CachedAt metav1.Time `json:"cachedAt"`
 }
 
 // +operator-sdk:csv:customresourcedefinitions:resources={{Service,v1,policy-engine-service}}
@@ -92,3 +176,10 @@ func init() {
 func (fm Firmware) IsEmpty() bool {
 	return fm.Version == "" && fm.URL == ""
 }
+
+// IsRebootRequired checks whether a change to the named attribute requires a reboot, which is assumed unless the
+// attribute is flagged otherwise
+func (b Bios) IsRebootRequired(name string) bool {
+	setting, exists := b.Settings[name]
+	return !exists || setting.RebootRequired == nil || *setting.RebootRequired
+}
@@ -16,6 +16,157 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocatedNodeRecord) DeepCopyInto(out *AllocatedNodeRecord) {
+	*out = *in
+	if in.HwProfiles != nil {
+		in, out := &in.HwProfiles, &out.HwProfiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.AllocatedAt.DeepCopyInto(&out.AllocatedAt)
+	if in.ReleasedAt != nil {
+		in, out := &in.ReleasedAt, &out.ReleasedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocatedNodeRecord.
+func (in *AllocatedNodeRecord) DeepCopy() *AllocatedNodeRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocatedNodeRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationRecord) DeepCopyInto(out *AllocationRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationRecord.
+func (in *AllocationRecord) DeepCopy() *AllocationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AllocationRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationRecordList) DeepCopyInto(out *AllocationRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AllocationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationRecordList.
+func (in *AllocationRecordList) DeepCopy() *AllocationRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AllocationRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationRecordSpec) DeepCopyInto(out *AllocationRecordSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationRecordSpec.
+func (in *AllocationRecordSpec) DeepCopy() *AllocationRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationRecordStatus) DeepCopyInto(out *AllocationRecordStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]AllocatedNodeRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]JobRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReleasedAt != nil {
+		in, out := &in.ReleasedAt, &out.ReleasedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationRecordStatus.
+func (in *AllocationRecordStatus) DeepCopy() *AllocationRecordStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationRecordStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactCache) DeepCopyInto(out *ArtifactCache) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactCache.
+func (in *ArtifactCache) DeepCopy() *ArtifactCache {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Bios) DeepCopyInto(out *Bios) {
 	*out = *in
@@ -26,6 +177,13 @@ func (in *Bios) DeepCopyInto(out *Bios) {
 			(*out)[key] = val
 		}
 	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]BiosSetting, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Bios.
@@ -38,19 +196,185 @@ func (in *Bios) DeepCopy() *Bios {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BiosSetting) DeepCopyInto(out *BiosSetting) {
+	*out = *in
+	if in.RebootRequired != nil {
+		in, out := &in.RebootRequired, &out.RebootRequired
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BiosSetting.
+func (in *BiosSetting) DeepCopy() *BiosSetting {
+	if in == nil {
+		return nil
+	}
+	out := new(BiosSetting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BmcSecretBackend) DeepCopyInto(out *BmcSecretBackend) {
+	*out = *in
+	if in.ExternalSecret != nil {
+		in, out := &in.ExternalSecret, &out.ExternalSecret
+		*out = new(ExternalSecretBackend)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BmcSecretBackend.
+func (in *BmcSecretBackend) DeepCopy() *BmcSecretBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(BmcSecretBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CachedArtifact) DeepCopyInto(out *CachedArtifact) {
+	*out = *in
+	in.CachedAt.DeepCopyInto(&out.CachedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CachedArtifact.
+func (in *CachedArtifact) DeepCopy() *CachedArtifact {
+	if in == nil {
+		return nil
+	}
+	out := new(CachedArtifact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreakerConfig) DeepCopyInto(out *CircuitBreakerConfig) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int)
+		**out = **in
+	}
+	if in.Cooldown != nil {
+		in, out := &in.Cooldown, &out.Cooldown
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerConfig.
+func (in *CircuitBreakerConfig) DeepCopy() *CircuitBreakerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreakerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentLogLevels) DeepCopyInto(out *ComponentLogLevels) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentLogLevels.
+func (in *ComponentLogLevels) DeepCopy() *ComponentLogLevels {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentLogLevels)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialVaultConfig) DeepCopyInto(out *CredentialVaultConfig) {
+	*out = *in
+	if in.CacheTTL != nil {
+		in, out := &in.CacheTTL, &out.CacheTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialVaultConfig.
+func (in *CredentialVaultConfig) DeepCopy() *CredentialVaultConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialVaultConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DellData) DeepCopyInto(out *DellData) {
 	*out = *in
+	if in.TokenUrl != nil {
+		in, out := &in.TokenUrl, &out.TokenUrl
+		*out = new(string)
+		**out = **in
+	}
 	if in.CaBundleName != nil {
 		in, out := &in.CaBundleName, &out.CaBundleName
 		*out = new(string)
 		**out = **in
 	}
+	if in.ClientCertSecret != nil {
+		in, out := &in.ClientCertSecret, &out.ClientCertSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.CallbackSecret != nil {
+		in, out := &in.CallbackSecret, &out.CallbackSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.FailReasonRulesName != nil {
+		in, out := &in.FailReasonRulesName, &out.FailReasonRulesName
+		*out = new(string)
+		**out = **in
+	}
 	if in.Tenant != nil {
 		in, out := &in.Tenant, &out.Tenant
 		*out = new(string)
 		**out = **in
 	}
+	if in.TenantMapping != nil {
+		in, out := &in.TenantMapping, &out.TenantMapping
+		*out = new(TenantMapping)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceGroupValidationInterval != nil {
+		in, out := &in.ResourceGroupValidationInterval, &out.ResourceGroupValidationInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HealthCheckInterval != nil {
+		in, out := &in.HealthCheckInterval, &out.HealthCheckInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreakerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceGroupGC != nil {
+		in, out := &in.ResourceGroupGC, &out.ResourceGroupGC
+		*out = new(ResourceGroupGCConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialVault != nil {
+		in, out := &in.CredentialVault, &out.CredentialVault
+		*out = new(CredentialVaultConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DellData.
@@ -63,9 +387,34 @@ func (in *DellData) DeepCopy() *DellData {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretBackend) DeepCopyInto(out *ExternalSecretBackend) {
+	*out = *in
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretBackend.
+func (in *ExternalSecretBackend) DeepCopy() *ExternalSecretBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Firmware) DeepCopyInto(out *Firmware) {
 	*out = *in
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(FirmwareSignature)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Firmware.
@@ -78,6 +427,46 @@ func (in *Firmware) DeepCopy() *Firmware {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwarePreflight) DeepCopyInto(out *FirmwarePreflight) {
+	*out = *in
+	if in.CaBundleName != nil {
+		in, out := &in.CaBundleName, &out.CaBundleName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwarePreflight.
+func (in *FirmwarePreflight) DeepCopy() *FirmwarePreflight {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwarePreflight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirmwareSignature) DeepCopyInto(out *FirmwareSignature) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirmwareSignature.
+func (in *FirmwareSignature) DeepCopy() *FirmwareSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(FirmwareSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HardwareManager) DeepCopyInto(out *HardwareManager) {
 	*out = *in
@@ -143,13 +532,58 @@ func (in *HardwareManagerSpec) DeepCopyInto(out *HardwareManagerSpec) {
 	if in.LoopbackData != nil {
 		in, out := &in.LoopbackData, &out.LoopbackData
 		*out = new(LoopbackData)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.DellData != nil {
 		in, out := &in.DellData, &out.DellData
 		*out = new(DellData)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Metal3Data != nil {
+		in, out := &in.Metal3Data, &out.Metal3Data
+		*out = new(Metal3Data)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BmcCredentialsSecret != nil {
+		in, out := &in.BmcCredentialsSecret, &out.BmcCredentialsSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.BmcSecretBackend != nil {
+		in, out := &in.BmcSecretBackend, &out.BmcSecretBackend
+		*out = new(BmcSecretBackend)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfig)
+		**out = **in
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(LoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeMetadata != nil {
+		in, out := &in.NodeMetadata, &out.NodeMetadata
+		*out = new(NodeMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvisioningTimeout != nil {
+		in, out := &in.ProvisioningTimeout, &out.ProvisioningTimeout
+		*out = new(ProvisioningTimeout)
+		**out = **in
+	}
+	if in.SiteProvisioning != nil {
+		in, out := &in.SiteProvisioning, &out.SiteProvisioning
+		*out = new(SiteProvisioning)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManagerSpec.
@@ -263,8 +697,8 @@ func (in *HardwareProfileList) DeepCopyObject() runtime.Object {
 func (in *HardwareProfileSpec) DeepCopyInto(out *HardwareProfileSpec) {
 	*out = *in
 	in.Bios.DeepCopyInto(&out.Bios)
-	out.BiosFirmware = in.BiosFirmware
-	out.BmcFirmware = in.BmcFirmware
+	in.BiosFirmware.DeepCopyInto(&out.BiosFirmware)
+	in.BmcFirmware.DeepCopyInto(&out.BmcFirmware)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileSpec.
@@ -287,6 +721,18 @@ func (in *HardwareProfileStatus) DeepCopyInto(out *HardwareProfileStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CachedArtifacts != nil {
+		in, out := &in.CachedArtifacts, &out.CachedArtifacts
+		*out = make([]CachedArtifact, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareProfileStatus.
@@ -299,9 +745,58 @@ func (in *HardwareProfileStatus) DeepCopy() *HardwareProfileStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobRecord) DeepCopyInto(out *JobRecord) {
+	*out = *in
+	in.ObservedAt.DeepCopyInto(&out.ObservedAt)
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobRecord.
+func (in *JobRecord) DeepCopy() *JobRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(JobRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingConfig) DeepCopyInto(out *LoggingConfig) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = new(ComponentLogLevels)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingConfig.
+func (in *LoggingConfig) DeepCopy() *LoggingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoopbackData) DeepCopyInto(out *LoopbackData) {
 	*out = *in
+	if in.Scale != nil {
+		in, out := &in.Scale, &out.Scale
+		*out = new(LoopbackScale)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopbackData.
@@ -314,6 +809,176 @@ func (in *LoopbackData) DeepCopy() *LoopbackData {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoopbackHardwareTemplate) DeepCopyInto(out *LoopbackHardwareTemplate) {
+	*out = *in
+	if in.Processors != nil {
+		in, out := &in.Processors, &out.Processors
+		*out = make([]LoopbackProcessor, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopbackHardwareTemplate.
+func (in *LoopbackHardwareTemplate) DeepCopy() *LoopbackHardwareTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(LoopbackHardwareTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoopbackProcessor) DeepCopyInto(out *LoopbackProcessor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopbackProcessor.
+func (in *LoopbackProcessor) DeepCopy() *LoopbackProcessor {
+	if in == nil {
+		return nil
+	}
+	out := new(LoopbackProcessor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoopbackScale) DeepCopyInto(out *LoopbackScale) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopbackScale.
+func (in *LoopbackScale) DeepCopy() *LoopbackScale {
+	if in == nil {
+		return nil
+	}
+	out := new(LoopbackScale)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = (*in).DeepCopy()
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3Data) DeepCopyInto(out *Metal3Data) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DriftDetectionInterval != nil {
+		in, out := &in.DriftDetectionInterval, &out.DriftDetectionInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FirmwarePreflight != nil {
+		in, out := &in.FirmwarePreflight, &out.FirmwarePreflight
+		*out = new(FirmwarePreflight)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateTimeouts != nil {
+		in, out := &in.UpdateTimeouts, &out.UpdateTimeouts
+		*out = new(UpdateTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateErrorRemediation != nil {
+		in, out := &in.UpdateErrorRemediation, &out.UpdateErrorRemediation
+		*out = new(UpdateErrorRemediation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxConcurrentUpdates != nil {
+		in, out := &in.MaxConcurrentUpdates, &out.MaxConcurrentUpdates
+		*out = new(int)
+		**out = **in
+	}
+	if in.ArtifactCache != nil {
+		in, out := &in.ArtifactCache, &out.ArtifactCache
+		*out = new(ArtifactCache)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3Data.
+func (in *Metal3Data) DeepCopy() *Metal3Data {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3Data)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMetadata) DeepCopyInto(out *NodeMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetadata.
+func (in *NodeMetadata) DeepCopy() *NodeMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in PerSiteResourcePoolList) DeepCopyInto(out *PerSiteResourcePoolList) {
 	{
@@ -344,6 +1009,57 @@ func (in PerSiteResourcePoolList) DeepCopy() PerSiteResourcePoolList {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningTimeout) DeepCopyInto(out *ProvisioningTimeout) {
+	*out = *in
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningTimeout.
+func (in *ProvisioningTimeout) DeepCopy() *ProvisioningTimeout {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningTimeout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfig.
+func (in *ProxyConfig) DeepCopy() *ProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceGroupGCConfig) DeepCopyInto(out *ResourceGroupGCConfig) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceGroupGCConfig.
+func (in *ResourceGroupGCConfig) DeepCopy() *ResourceGroupGCConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceGroupGCConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ResourcePoolList) DeepCopyInto(out *ResourcePoolList) {
 	{
@@ -362,3 +1078,90 @@ func (in ResourcePoolList) DeepCopy() ResourcePoolList {
 	in.DeepCopyInto(out)
 	return *out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SiteProvisioning) DeepCopyInto(out *SiteProvisioning) {
+	*out = *in
+	if in.SiteLimits != nil {
+		in, out := &in.SiteLimits, &out.SiteLimits
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SiteProvisioning.
+func (in *SiteProvisioning) DeepCopy() *SiteProvisioning {
+	if in == nil {
+		return nil
+	}
+	out := new(SiteProvisioning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantMapping) DeepCopyInto(out *TenantMapping) {
+	*out = *in
+	if in.AllowedTenants != nil {
+		in, out := &in.AllowedTenants, &out.AllowedTenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantMapping.
+func (in *TenantMapping) DeepCopy() *TenantMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateErrorRemediation) DeepCopyInto(out *UpdateErrorRemediation) {
+	*out = *in
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateErrorRemediation.
+func (in *UpdateErrorRemediation) DeepCopy() *UpdateErrorRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateErrorRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateTimeouts) DeepCopyInto(out *UpdateTimeouts) {
+	*out = *in
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Updating != nil {
+		in, out := &in.Updating, &out.Updating
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateTimeouts.
+func (in *UpdateTimeouts) DeepCopy() *UpdateTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
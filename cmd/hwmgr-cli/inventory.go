@@ -0,0 +1,213 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+const inventoryBasePath = "hardware-manager/inventory/v1/manager"
+
+// inventoryOptions holds the flags of the inventory commands
+type inventoryOptions struct {
+	*globalOptions
+	server             string
+	token              string
+	caFile             string
+	insecureSkipVerify bool
+	timeout            time.Duration
+}
+
+func newInventoryCommand(global *globalOptions) *cobra.Command {
+	opts := &inventoryOptions{globalOptions: global}
+
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Query the inventory API of the plugin",
+		Long: "Query the inventory API of the plugin. The API is usually reached through a route or port-forward " +
+			"to the plugin service. The bearer token of the kubeconfig is used unless --token is set.",
+	}
+
+	cmd.PersistentFlags().StringVar(&opts.server, "server", "", "The base URL of the plugin API server.")
+	cmd.PersistentFlags().StringVar(&opts.token, "token", "", "The bearer token for the API server.")
+	cmd.PersistentFlags().StringVar(&opts.caFile, "cacert", "", "Path to a CA bundle for the API server certificate.")
+	cmd.PersistentFlags().BoolVar(&opts.insecureSkipVerify, "insecure-skip-tls-verify", false,
+		"Skip verification of the API server certificate.")
+	cmd.PersistentFlags().DurationVar(&opts.timeout, "timeout", 30*time.Second, "The timeout for API requests.")
+	_ = cmd.MarkPersistentFlagRequired("server")
+
+	var site string
+	resourcePoolsCmd := &cobra.Command{
+		Use:   "resource-pools <hwMgrId> [resourcePoolId]",
+		Short: "List the resource pools of a hardware manager, or get a single resource pool",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := fmt.Sprintf("%s/%s/resourcePools", inventoryBasePath, url.PathEscape(args[0]))
+			if len(args) == 2 {
+				path += "/" + url.PathEscape(args[1])
+			} else if site != "" {
+				path += "?siteId=" + url.QueryEscape(site)
+			}
+			return opts.get(cmd.Context(), path)
+		},
+	}
+	resourcePoolsCmd.Flags().StringVar(&site, "site", "", "Only list the resource pools of the given site.")
+
+	cmd.AddCommand(
+		resourcePoolsCmd,
+		&cobra.Command{
+			Use:   "resources <hwMgrId> [resourceId]",
+			Short: "List the resources of a hardware manager, or get a single resource",
+			Args:  cobra.RangeArgs(1, 2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				path := fmt.Sprintf("%s/%s/resources", inventoryBasePath, url.PathEscape(args[0]))
+				if len(args) == 2 {
+					path += "/" + url.PathEscape(args[1])
+				}
+				return opts.get(cmd.Context(), path)
+			},
+		},
+		&cobra.Command{
+			Use:   "capacity <hwMgrId>",
+			Short: "Show the total, allocated, and free resources of each resource pool of a hardware manager",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return opts.get(cmd.Context(), fmt.Sprintf("%s/%s/capacity", inventoryBasePath, url.PathEscape(args[0])))
+			},
+		},
+		&cobra.Command{
+			Use:   "nodepool-jobs <hwMgrId> <nodePoolName>",
+			Short: "List the hardware manager jobs run for a NodePool, with their phase and failure reason",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return opts.get(cmd.Context(), fmt.Sprintf("%s/%s/nodePools/%s/jobs",
+					inventoryBasePath, url.PathEscape(args[0]), url.PathEscape(args[1])))
+			},
+		},
+		&cobra.Command{
+			Use:   "pool-resources <hwMgrId> <resourcePoolId>",
+			Short: "List the resources of a resource pool",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return opts.get(cmd.Context(), fmt.Sprintf("%s/%s/resourcePools/%s/resources",
+					inventoryBasePath, url.PathEscape(args[0]), url.PathEscape(args[1])))
+			},
+		},
+	)
+
+	return cmd
+}
+
+func (o *inventoryOptions) httpClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.insecureSkipVerify, // nolint: gosec
+	}
+
+	if o.caFile != "" {
+		caBundle, err := os.ReadFile(o.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %w", o.caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &http.Client{
+		Timeout:   o.timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+func (o *inventoryOptions) bearerToken() (string, error) {
+	if o.token != "" {
+		return o.token, nil
+	}
+
+	config, err := o.restConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.BearerToken == "" {
+		return "", fmt.Errorf("the kubeconfig has no bearer token, set --token")
+	}
+	return config.BearerToken, nil
+}
+
+// get queries an inventory API path, printing the response. The correlation ID of the request is printed to stderr,
+// so that the request can be found in the plugin logs.
+func (o *inventoryOptions) get(ctx context.Context, path string) error {
+	serverURL, err := url.Parse(o.server)
+	if err != nil {
+		return fmt.Errorf("invalid server url %s: %w", o.server, err)
+	}
+	queryURL, err := serverURL.Parse("./" + path)
+	if err != nil {
+		return fmt.Errorf("failed to build url: %w", err)
+	}
+
+	token, err := o.bearerToken()
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := o.httpClient()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, queryURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	correlationID := logging.NewCorrelationID()
+	req.Header.Set(logging.CorrelationIDHeader, correlationID)
+	fmt.Fprintf(os.Stderr, "%s: %s\n", logging.CorrelationIDHeader, correlationID)
+
+	response, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", queryURL, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var out bytes.Buffer
+	if json.Indent(&out, body, "", "  ") != nil {
+		out.Reset()
+		out.Write(body)
+	}
+	fmt.Println(out.String())
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status %s", queryURL, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// hwmgr-cli is a diagnostics tool for the hardware manager plugin. It runs outside the cluster, using a kubeconfig to
+// read the plugin CRs and the bearer token of the kubeconfig, or an explicit token, to query the inventory API.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	bmhv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const defaultNamespace = "oran-hwmgr-plugin"
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(hwmgmtv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(pluginv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(bmhv1alpha1.AddToScheme(scheme))
+}
+
+// globalOptions holds the flags shared by all commands
+type globalOptions struct {
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+func (o *globalOptions) restConfig() (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if o.kubeconfig != "" {
+		rules.ExplicitPath = o.kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.context}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+func (o *globalOptions) client() (client.Client, error) {
+	config, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return c, nil
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &globalOptions{}
+
+	cmd := &cobra.Command{
+		Use:           "hwmgr-cli",
+		Short:         "Diagnostics for the O-Cloud hardware manager plugin",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringVar(&opts.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file. Defaults to $KUBECONFIG or ~/.kube/config.")
+	cmd.PersistentFlags().StringVar(&opts.context, "context", "", "The kubeconfig context to use.")
+	cmd.PersistentFlags().StringVarP(&opts.namespace, "namespace", "n", defaultNamespace,
+		"The namespace the plugin is deployed in.")
+
+	cmd.AddCommand(
+		newInventoryCommand(opts),
+		newNodePoolsCommand(opts),
+		newNodeCommand(opts),
+		newReconcileCommand(opts),
+	)
+
+	return cmd
+}
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
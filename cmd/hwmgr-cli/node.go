@@ -0,0 +1,243 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/metal3"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+func newNodeCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "node <name>",
+		Short: "Show the state of a Node, including the BIOS and firmware status of its hardware",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			return describeNode(cmd.Context(), c, opts.namespace, args[0], os.Stdout)
+		},
+	}
+}
+
+func describeNode(ctx context.Context, c client.Client, namespace, name string, w io.Writer) error {
+	node := &hwmgmtv1alpha1.Node{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, node); err != nil {
+		return fmt.Errorf("failed to get Node %s: %w", name, err)
+	}
+
+	fmt.Fprintf(w, "Name:                %s\n", node.Name)
+	fmt.Fprintf(w, "NodePool:            %s\n", node.Spec.NodePool)
+	fmt.Fprintf(w, "Group:               %s\n", node.Spec.GroupName)
+	fmt.Fprintf(w, "Hardware manager:    %s\n", node.Spec.HwMgrId)
+	fmt.Fprintf(w, "Hardware node:       %s\n", hwMgrNodeName(node))
+	fmt.Fprintf(w, "Hardware profile:    %s (applied %s)\n", node.Spec.HwProfile, valueOrDash(node.Status.HwProfile))
+	fmt.Fprintf(w, "Hostname:            %s\n", valueOrDash(node.Status.Hostname))
+	if node.Status.BMC != nil {
+		fmt.Fprintf(w, "BMC address:         %s\n", valueOrDash(node.Status.BMC.Address))
+	}
+
+	printPluginAnnotations(w, node.Annotations)
+	printConditions(w, node.Status.Conditions)
+	printNodeHistory(w, node)
+	printNodeTimings(w, node)
+	fmt.Fprintln(w)
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := c.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+
+	switch hwmgr.Spec.AdaptorID {
+	case pluginv1alpha1.SupportedAdaptors.Metal3:
+		return describeBareMetalHost(ctx, c, node, w)
+	default:
+		fmt.Fprintf(w, "The BIOS and firmware status of %s nodes is held by the hardware manager, "+
+			"see the Configured condition for the last update.\n", hwmgr.Spec.AdaptorID)
+		return nil
+	}
+}
+
+// printNodeHistory prints the events recorded on a node as a table, oldest first
+func printNodeHistory(w io.Writer, node *hwmgmtv1alpha1.Node) {
+	history, err := utils.GetNodeHistory(node)
+	if err != nil {
+		fmt.Fprintf(w, "History: %s\n", err)
+		return
+	}
+	if len(history) == 0 {
+		fmt.Fprintln(w, "History: none")
+		return
+	}
+
+	fmt.Fprintln(w, "History:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TIME\tEVENT\tMESSAGE")
+	for _, event := range history {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", event.Time.UTC().Format("2006-01-02T15:04:05Z"), event.Event, event.Message)
+	}
+	_ = tw.Flush()
+}
+
+// printNodeTimings prints the start, completion and duration of the phases recorded on a node
+func printNodeTimings(w io.Writer, node *hwmgmtv1alpha1.Node) {
+	timings, err := utils.GetNodeTimings(node)
+	if err != nil {
+		fmt.Fprintf(w, "Timings: %s\n", err)
+		return
+	}
+	if len(timings) == 0 {
+		fmt.Fprintln(w, "Timings: none")
+		return
+	}
+
+	phases := make([]string, 0, len(timings))
+	for phase := range timings {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+
+	formatTime := func(t *metav1.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02T15:04:05Z")
+	}
+
+	fmt.Fprintln(w, "Timings:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  PHASE\tSTARTED\tCOMPLETED\tDURATION")
+	for _, phase := range phases {
+		timing := timings[phase]
+		duration := "-"
+		if d, done := timing.Duration(); done {
+			duration = d.Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", phase, formatTime(timing.StartedAt), formatTime(timing.CompletedAt), duration)
+	}
+	_ = tw.Flush()
+}
+
+func hwMgrNodeName(node *hwmgmtv1alpha1.Node) string {
+	if node.Spec.HwMgrNodeNs != "" {
+		return node.Spec.HwMgrNodeNs + "/" + node.Spec.HwMgrNodeId
+	}
+	return valueOrDash(node.Spec.HwMgrNodeId)
+}
+
+// describeBareMetalHost prints the state of the BareMetalHost of a metal3 node, along with the firmware settings and
+// components reported for it
+func describeBareMetalHost(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node, w io.Writer) error {
+	key := types.NamespacedName{Name: node.Spec.HwMgrNodeId, Namespace: node.Spec.HwMgrNodeNs}
+
+	bmh := &metal3v1alpha1.BareMetalHost{}
+	if err := c.Get(ctx, key, bmh); err != nil {
+		return fmt.Errorf("failed to get BareMetalHost %s: %w", key, err)
+	}
+
+	fmt.Fprintf(w, "BareMetalHost:       %s\n", key)
+	fmt.Fprintf(w, "  State:             %s\n", bmh.Status.Provisioning.State)
+	fmt.Fprintf(w, "  Operational:       %s\n", bmh.Status.OperationalStatus)
+	fmt.Fprintf(w, "  Powered on:        %t\n", bmh.Status.PoweredOn)
+	if bmh.Status.ErrorMessage != "" {
+		fmt.Fprintf(w, "  Error:             %s: %s\n", bmh.Status.ErrorType, bmh.Status.ErrorMessage)
+	}
+	fmt.Fprintf(w, "  BIOS update:       %s\n", pendingUpdate(bmh.Annotations, metal3.BiosUpdateNeededAnnotation))
+	fmt.Fprintf(w, "  Firmware update:   %s\n", pendingUpdate(bmh.Annotations, metal3.FirmwareUpdateNeededAnnotation))
+	fmt.Fprintln(w)
+
+	hfs := &metal3v1alpha1.HostFirmwareSettings{}
+	switch err := c.Get(ctx, key, hfs); {
+	case errors.IsNotFound(err):
+		fmt.Fprintln(w, "HostFirmwareSettings: not found")
+	case err != nil:
+		return fmt.Errorf("failed to get HostFirmwareSettings %s: %w", key, err)
+	default:
+		printHostFirmwareSettings(w, hfs)
+	}
+	fmt.Fprintln(w)
+
+	hfc := &metal3v1alpha1.HostFirmwareComponents{}
+	switch err := c.Get(ctx, key, hfc); {
+	case errors.IsNotFound(err):
+		fmt.Fprintln(w, "HostFirmwareComponents: not found")
+	case err != nil:
+		return fmt.Errorf("failed to get HostFirmwareComponents %s: %w", key, err)
+	default:
+		printHostFirmwareComponents(w, hfc)
+	}
+
+	return nil
+}
+
+func pendingUpdate(annotations map[string]string, annotation string) string {
+	if _, exists := annotations[annotation]; exists {
+		return "pending"
+	}
+	return "none"
+}
+
+// printHostFirmwareSettings prints the requested BIOS settings along with their current values
+func printHostFirmwareSettings(w io.Writer, hfs *metal3v1alpha1.HostFirmwareSettings) {
+	printConditions(w, hfs.Status.Conditions)
+	if len(hfs.Spec.Settings) == 0 {
+		fmt.Fprintln(w, "Requested BIOS settings: none")
+		return
+	}
+
+	names := make([]string, 0, len(hfs.Spec.Settings))
+	for name := range hfs.Spec.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Requested BIOS settings:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tREQUESTED\tCURRENT")
+	for _, name := range names {
+		requested := hfs.Spec.Settings[name]
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", name, requested.String(), valueOrDash(hfs.Status.Settings[name]))
+	}
+	_ = tw.Flush()
+}
+
+// printHostFirmwareComponents prints the firmware versions reported for each component
+func printHostFirmwareComponents(w io.Writer, hfc *metal3v1alpha1.HostFirmwareComponents) {
+	printConditions(w, hfc.Status.Conditions)
+	if len(hfc.Status.Components) == 0 {
+		fmt.Fprintln(w, "Firmware components: none reported")
+		return
+	}
+
+	fmt.Fprintln(w, "Firmware components:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  COMPONENT\tCURRENT\tINITIAL\tLAST FLASHED")
+	for _, component := range hfc.Status.Components {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", component.Component, valueOrDash(component.CurrentVersion),
+			valueOrDash(component.InitialVersion), valueOrDash(component.LastVersionFlashed))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,165 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+func newNodePoolsCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "nodepools [name]",
+		Aliases: []string{"nodepool"},
+		Short:   "List NodePools and their conditions, or show the details of a NodePool",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return listNodePools(cmd.Context(), c, opts.namespace, os.Stdout)
+			}
+			return describeNodePool(cmd.Context(), c, opts.namespace, args[0], os.Stdout)
+		},
+	}
+}
+
+// conditionSummary returns the status and reason of a condition for display
+func conditionSummary(conditions []metav1.Condition, conditionType hwmgmtv1alpha1.ConditionType) string {
+	condition := meta.FindStatusCondition(conditions, string(conditionType))
+	if condition == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s/%s", condition.Status, condition.Reason)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func listNodePools(ctx context.Context, c client.Client, namespace string, w io.Writer) error {
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.List(ctx, nodepools, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	sort.Slice(nodepools.Items, func(i, j int) bool { return nodepools.Items[i].Name < nodepools.Items[j].Name })
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tHWMGR\tPROVISIONED\tCONFIGURED\tNODES\tJOB")
+	for _, nodepool := range nodepools.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			nodepool.Name,
+			nodepool.Spec.HwMgrId,
+			conditionSummary(nodepool.Status.Conditions, hwmgmtv1alpha1.Provisioned),
+			conditionSummary(nodepool.Status.Conditions, hwmgmtv1alpha1.Configured),
+			len(nodepool.Status.Properties.NodeNames),
+			valueOrDash(utils.GetJobId(&nodepool)))
+	}
+	return tw.Flush() // nolint: wrapcheck
+}
+
+// printConditions prints a list of conditions as a table
+func printConditions(w io.Writer, conditions []metav1.Condition) {
+	if len(conditions) == 0 {
+		fmt.Fprintln(w, "Conditions: none")
+		return
+	}
+
+	fmt.Fprintln(w, "Conditions:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE")
+	for _, condition := range conditions {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+			condition.Type, condition.Status, condition.Reason,
+			condition.LastTransitionTime.UTC().Format("2006-01-02T15:04:05Z"), condition.Message)
+	}
+	_ = tw.Flush()
+}
+
+// printPluginAnnotations prints the annotations set by the plugin, which track jobs and operations in progress
+func printPluginAnnotations(w io.Writer, annotations map[string]string) {
+	var keys []string
+	for key := range annotations {
+		// The node history and timings are printed as tables of their own
+		if strings.HasPrefix(key, "hwmgr-plugin.oran.openshift.io/") && key != utils.NodeHistoryAnnotation &&
+			key != utils.NodeTimingsAnnotation {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		fmt.Fprintln(w, "Plugin annotations: none")
+		return
+	}
+
+	sort.Strings(keys)
+	fmt.Fprintln(w, "Plugin annotations:")
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", key, annotations[key])
+	}
+}
+
+func describeNodePool(ctx context.Context, c client.Client, namespace, name string, w io.Writer) error {
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, nodepool); err != nil {
+		return fmt.Errorf("failed to get NodePool %s: %w", name, err)
+	}
+
+	fmt.Fprintf(w, "Name:                %s\n", nodepool.Name)
+	fmt.Fprintf(w, "Cloud ID:            %s\n", nodepool.Spec.CloudID)
+	fmt.Fprintf(w, "Hardware manager:    %s\n", nodepool.Spec.HwMgrId)
+	fmt.Fprintf(w, "Generation:          %d (observed %d)\n",
+		nodepool.Generation, nodepool.Status.HwMgrPlugin.ObservedGeneration)
+
+	fmt.Fprintln(w, "Node groups:")
+	for _, group := range nodepool.Spec.NodeGroup {
+		fmt.Fprintf(w, "  %s: size=%d hwProfile=%s\n", group.NodePoolData.Name, group.Size, group.NodePoolData.HwProfile)
+	}
+
+	printPluginAnnotations(w, nodepool.Annotations)
+	printConditions(w, nodepool.Status.Conditions)
+
+	if len(nodepool.Status.Properties.NodeNames) == 0 {
+		fmt.Fprintln(w, "Nodes: none")
+		return nil
+	}
+
+	fmt.Fprintln(w, "Nodes:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tGROUP\tHWMGR NODE\tHWPROFILE\tPROVISIONED\tCONFIGURED")
+	for _, nodeName := range nodepool.Status.Properties.NodeNames {
+		node := &hwmgmtv1alpha1.Node{}
+		if err := c.Get(ctx, types.NamespacedName{Name: nodeName, Namespace: namespace}, node); err != nil {
+			fmt.Fprintf(tw, "  %s\t-\t-\t-\t%s\t-\n", nodeName, "unreadable: "+err.Error())
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n",
+			node.Name, node.Spec.GroupName, node.Spec.HwMgrNodeId, valueOrDash(node.Status.HwProfile),
+			conditionSummary(node.Status.Conditions, hwmgmtv1alpha1.Provisioned),
+			conditionSummary(node.Status.Conditions, hwmgmtv1alpha1.Configured))
+	}
+	return tw.Flush() // nolint: wrapcheck
+}
@@ -0,0 +1,56 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+func newReconcileCommand(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reconcile <nodepool>",
+		Short: "Trigger a reconcile of a NodePool",
+		Long: "Trigger a reconcile of a NodePool by setting the " + utils.ReconcileRequestAnnotation +
+			" annotation to the current time. This does not change the NodePool spec, so it only re-runs the " +
+			"processing of the current state, such as checking the status of an in-progress job.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			nodepool := &hwmgmtv1alpha1.NodePool{}
+			if err := c.Get(cmd.Context(), types.NamespacedName{Name: args[0], Namespace: opts.namespace}, nodepool); err != nil {
+				return fmt.Errorf("failed to get NodePool %s: %w", args[0], err)
+			}
+
+			patch := client.MergeFrom(nodepool.DeepCopy())
+			annotations := nodepool.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[utils.ReconcileRequestAnnotation] = time.Now().UTC().Format(time.RFC3339Nano)
+			nodepool.SetAnnotations(annotations)
+
+			if err := c.Patch(cmd.Context(), nodepool, patch); err != nil {
+				return fmt.Errorf("failed to annotate NodePool %s: %w", nodepool.Name, err)
+			}
+
+			fmt.Printf("Requested reconcile of NodePool %s\n", nodepool.Name)
+			return nil
+		},
+	}
+}
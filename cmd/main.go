@@ -9,10 +9,15 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 
@@ -22,11 +27,15 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -35,6 +44,7 @@ import (
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 
@@ -46,6 +56,18 @@ import (
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 )
 
+// Process modes, allowing the inventory API to be served by a separate deployment from the controllers so that
+// inventory load does not delay reconciles
+const (
+	modeAll         = "all"
+	modeControllers = "controllers"
+	modeInventory   = "inventory"
+)
+
+// metadataMigrationTimeout bounds the metadata migration run on startup, so that an unresponsive API server does not
+// hold up the plugin indefinitely
+const metadataMigrationTimeout = time.Minute
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -62,19 +84,57 @@ func init() {
 func _main() int {
 	var metricsAddr string
 	var tlsCertDir string
+	var tlsServerNames string
 	var enableLeaderElection bool
 	var probeAddr string
 	var enableHTTP2 bool
+	var enableWebhooks bool
 	var apiServerAddr string
+	var grpcServerAddr string
+	var enabledAdaptors string
+	var disabledAdaptors string
+	var allocationRecordRetention time.Duration
+	var artifactCacheDir string
+	var artifactCacheAddr string
+	var tracingConfig tracing.Config
+	var mode string
+	flag.StringVar(&mode, "mode", modeAll,
+		"The components run by the process: all, controllers (the controllers and the job callback API), or inventory "+
+			"(the inventory API and gRPC servers, reading from the API server without a cache).")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&tlsCertDir, "tls-cert-dir", "", "The path to the directory containing the TLS certificate and private key.")
+	flag.StringVar(&tlsServerNames, "tls-server-names", "",
+		"Comma-separated list of the hostnames the API and gRPC servers are reached by, which the serving certificate "+
+			"must cover.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&apiServerAddr, "api-bind-address", ":8082", "The address the API server binds to.")
+	flag.StringVar(&grpcServerAddr, "grpc-bind-address", "",
+		"The address the inventory gRPC server binds to. The gRPC server is not started if not set.")
+	flag.StringVar(&enabledAdaptors, "enabled-adaptors", "",
+		"Comma-separated list of the adaptors to enable. All registered adaptors are enabled if not set.")
+	flag.StringVar(&disabledAdaptors, "disabled-adaptors", "",
+		"Comma-separated list of the adaptors to disable. Disabled adaptors set up no controllers, watches, or clients.")
+	flag.DurationVar(&allocationRecordRetention, "allocation-record-retention",
+		o2imshardwaremanagementcontroller.DefaultAllocationRecordRetention,
+		"The time an AllocationRecord is kept after its NodePool is deleted.")
+	flag.StringVar(&artifactCacheDir, "artifact-cache-dir", "",
+		"The directory, normally on a persistent volume, that firmware images are mirrored to for the artifact cache. "+
+			"The artifact cache is not enabled if not set.")
+	flag.StringVar(&artifactCacheAddr, "artifact-cache-bind-address", ":8090",
+		"The address the artifact cache HTTP server binds to.")
+	flag.StringVar(&tracingConfig.Endpoint, "otlp-endpoint", "",
+		"The host:port of the OTLP gRPC collector that traces are exported to. Traces are not exported if not set.")
+	flag.BoolVar(&tracingConfig.Insecure, "otlp-insecure", false,
+		"If set, the connection to the OTLP collector does not use TLS.")
+	flag.Float64Var(&tracingConfig.SampleRatio, "trace-sample-ratio", 1,
+		"The fraction of traces started by the plugin that are exported, between 0 and 1.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the admission webhooks are served. This requires the webhook serving certificates.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -83,6 +143,28 @@ func _main() int {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if !slices.Contains([]string{modeAll, modeControllers, modeInventory}, mode) {
+		setupLog.Error(fmt.Errorf("invalid mode %q", mode), "unable to start", "modes", []string{modeAll, modeControllers, modeInventory})
+		return 1
+	}
+	runControllers := mode != modeInventory
+	serveInventory := mode != modeControllers
+	setupLog.Info("starting", "mode", mode)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		return 1
+	}
+	defer func() {
+		// Flush the spans of the last reconciles before exiting
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			setupLog.Error(err, "failed to shut down tracing")
+		}
+	}()
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -121,6 +203,9 @@ func _main() int {
 		return 1
 	}
 
+	// Inventory processes hold no state, so every replica serves requests
+	leaderElection := enableLeaderElection && runControllers
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
@@ -132,7 +217,7 @@ func _main() int {
 		},
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
+		LeaderElection:         leaderElection,
 		LeaderElectionID:       "d5b3dd42.oran.openshift.io",
 
 		// we need to watching all namespaces
@@ -157,29 +242,58 @@ func _main() int {
 		return 1
 	}
 
+	// Without controllers, nothing else needs the cache, so the inventory process reads from the API server instead of
+	// caching every object the inventory is built from. Adaptors that serve the inventory from the cache, such as
+	// metal3, read through the manager cache directly.
+	hwmgrClient := mgr.GetClient()
+	if !runControllers {
+		hwmgrClient, err = client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			return 1
+		}
+	}
+
 	hwmgrAdaptor := &adaptors.HwMgrAdaptorController{
-		Client:          mgr.GetClient(),
-		NoncachedClient: mgr.GetAPIReader(),
-		Scheme:          mgr.GetScheme(),
-		Logger:          slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "adaptors")),
-		Namespace:       myNamespace,
+		Client:           hwmgrClient,
+		NoncachedClient:  mgr.GetAPIReader(),
+		Scheme:           mgr.GetScheme(),
+		Logger:           slog.New(logging.NewComponentLoggingContextHandler(logging.Components.Adaptor, slog.LevelInfo)).With(slog.String("controller", "adaptors")),
+		Namespace:        myNamespace,
+		EnabledAdaptors:  splitList(enabledAdaptors),
+		DisabledAdaptors: splitList(disabledAdaptors),
+		InventoryOnly:    !runControllers,
 	}
 	if err = hwmgrAdaptor.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to setup adaptor controller")
 		return 1
 	}
 
-	if err = (&o2imshardwaremanagementcontroller.NodePoolReconciler{
-		Manager:         mgr,
-		Client:          mgr.GetClient(),
-		NoncachedClient: mgr.GetAPIReader(),
-		Scheme:          mgr.GetScheme(),
-		Logger:          slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "NodePool")),
-		Namespace:       myNamespace,
-		HwMgrAdaptor:    hwmgrAdaptor,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "NodePool")
-		return 1
+	// Job status callbacks received by the API server trigger NodePool reconciles through this channel
+	var jobEvents chan event.GenericEvent
+	if runControllers {
+		jobEvents = make(chan event.GenericEvent, 100)
+		if err := setupControllers(mgr, hwmgrAdaptor, controllerConfig{
+			namespace:                 myNamespace,
+			jobEvents:                 jobEvents,
+			allocationRecordRetention: allocationRecordRetention,
+			enableWebhooks:            enableWebhooks,
+			artifactCacheDir:          artifactCacheDir,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up controllers")
+			return 1
+		}
+
+		// Bring the metadata left by earlier plugin versions to the current schema before the controllers start. The
+		// manager cache is not yet running, so the migration reads from the API server.
+		migrationClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			return 1
+		}
+		migrationCtx, cancelMigration := context.WithTimeout(context.Background(), metadataMigrationTimeout)
+		hwmgrAdaptor.MigrateMetadata(migrationCtx, migrationClient)
+		cancelMigration()
 	}
 	//+kubebuilder:scaffold:builder
 
@@ -191,6 +305,19 @@ func _main() int {
 		setupLog.Error(err, "unable to set up ready check")
 		return 1
 	}
+	for name, check := range hwmgrAdaptor.ReadyzChecks() {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up ready check", "check", name)
+			return 1
+		}
+	}
+
+	// Certificate rotation and expiry events are recorded against the pod serving the certificate
+	tlsOptions := utils.ServerTLSOptions{Hostnames: splitList(tlsServerNames)}
+	if myPodName := os.Getenv("MY_POD_NAME"); myPodName != "" {
+		tlsOptions.Recorder = mgr.GetEventRecorderFor("server-tls")
+		tlsOptions.EventObject = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: myPodName, Namespace: myNamespace}}
+	}
 
 	serverErrors := make(chan error, 1)
 
@@ -198,13 +325,33 @@ func _main() int {
 	defer cancel()
 	go func() {
 		setupLog.Info("starting API server")
-		err = server.RunServer(ctx, apiServerAddr, tlsCertDir, hwmgrAdaptor)
+		err = server.RunServer(ctx, apiServerAddr, tlsCertDir, tlsOptions, hwmgrAdaptor, jobEvents, serveInventory)
 		if err != nil {
 			setupLog.Error(err, "unable to start API server")
 			serverErrors <- err
 		}
 	}()
 
+	if grpcServerAddr != "" && serveInventory {
+		go func() {
+			setupLog.Info("starting gRPC server")
+			if err := server.RunGRPCServer(ctx, grpcServerAddr, tlsCertDir, tlsOptions, hwmgrAdaptor); err != nil {
+				setupLog.Error(err, "unable to start gRPC server")
+				serverErrors <- err
+			}
+		}()
+	}
+
+	if artifactCacheDir != "" && runControllers {
+		go func() {
+			setupLog.Info("starting artifact cache server")
+			if err := server.RunArtifactServer(ctx, artifactCacheAddr, artifactCacheDir); err != nil {
+				setupLog.Error(err, "unable to start artifact cache server")
+				serverErrors <- err
+			}
+		}()
+	}
+
 	go func() {
 		setupLog.Info("starting manager")
 		if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -226,6 +373,169 @@ func _main() int {
 	}
 }
 
+// controllerConfig holds the settings of the controllers, which are not set up in a process serving only the inventory
+// API
+type controllerConfig struct {
+	namespace                 string
+	jobEvents                 chan event.GenericEvent
+	allocationRecordRetention time.Duration
+	enableWebhooks            bool
+	artifactCacheDir          string
+}
+
+// setupControllers sets up the controllers and webhooks of the plugin
+func setupControllers(mgr ctrl.Manager, hwmgrAdaptor *adaptors.HwMgrAdaptorController, config controllerConfig) error {
+	if err := (&o2imshardwaremanagementcontroller.NodePoolReconciler{
+		Manager:         mgr,
+		Client:          mgr.GetClient(),
+		NoncachedClient: mgr.GetAPIReader(),
+		Scheme:          mgr.GetScheme(),
+		Logger:          slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "NodePool")),
+		Namespace:       config.namespace,
+		HwMgrAdaptor:    hwmgrAdaptor,
+		JobEvents:       config.jobEvents,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller NodePool: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.NodeCleanupReconciler{
+		Client:       mgr.GetClient(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "NodeCleanup")),
+		Namespace:    config.namespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+		Recorder:     mgr.GetEventRecorderFor("node-cleanup"),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller NodeCleanup: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.BMCCredentialsReconciler{
+		Client:       mgr.GetClient(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "BMCCredentials")),
+		Namespace:    config.namespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+		Recorder:     mgr.GetEventRecorderFor("bmc-credentials"),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller BMCCredentials: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.NodePowerReconciler{
+		Client:       mgr.GetClient(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "NodePower")),
+		Namespace:    config.namespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+		Recorder:     mgr.GetEventRecorderFor("node-power"),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller NodePower: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.NodeTagsReconciler{
+		Client:       mgr.GetClient(),
+		Logger:       slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "NodeTags")),
+		Namespace:    config.namespace,
+		HwMgrAdaptor: hwmgrAdaptor,
+		Recorder:     mgr.GetEventRecorderFor("node-tags"),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller NodeTags: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.HardwareManagerDeletionReconciler{
+		Client:    mgr.GetClient(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "HardwareManagerDeletion")),
+		Namespace: config.namespace,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller HardwareManagerDeletion: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.HardwareManagerLoggingReconciler{
+		Client:    mgr.GetClient(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "HardwareManagerLogging")),
+		Namespace: config.namespace,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller HardwareManagerLogging: %w", err)
+	}
+
+	if err := (&o2imshardwaremanagementcontroller.AllocationRecordReconciler{
+		Client:    mgr.GetClient(),
+		Logger:    slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "AllocationRecord")),
+		Namespace: config.namespace,
+		Retention: config.allocationRecordRetention,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller AllocationRecord: %w", err)
+	}
+
+	profileTransport, err := utils.GetDefaultBackendTransport(false)
+	if err != nil {
+		return fmt.Errorf("unable to create hardware profile transport: %w", err)
+	}
+	if err := (&o2imshardwaremanagementcontroller.HardwareProfileReconciler{
+		Client:     mgr.GetClient(),
+		Logger:     slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "HardwareProfile")),
+		Namespace:  config.namespace,
+		HTTPClient: &http.Client{Transport: profileTransport},
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller HardwareProfile: %w", err)
+	}
+
+	if config.artifactCacheDir != "" {
+		transport, err := utils.GetDefaultBackendTransport(false)
+		if err != nil {
+			return fmt.Errorf("unable to create artifact cache transport: %w", err)
+		}
+		if err := (&o2imshardwaremanagementcontroller.ArtifactCacheReconciler{
+			Client:     mgr.GetClient(),
+			Logger:     slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("controller", "ArtifactCache")),
+			Namespace:  config.namespace,
+			Dir:        config.artifactCacheDir,
+			HTTPClient: &http.Client{Transport: transport},
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create controller ArtifactCache: %w", err)
+		}
+	}
+
+	if config.enableWebhooks {
+		myServiceAccount := os.Getenv("MY_POD_SERVICE_ACCOUNT")
+		if myServiceAccount == "" {
+			return errors.New("unable to determine service account: env variable MY_POD_SERVICE_ACCOUNT not set")
+		}
+
+		if err := (&o2imshardwaremanagementcontroller.NodeValidator{
+			Logger:         slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("webhook", "Node")),
+			Namespace:      config.namespace,
+			ServiceAccount: o2imshardwaremanagementcontroller.ServiceAccountUsername(config.namespace, myServiceAccount),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create webhook Node: %w", err)
+		}
+
+		if err := (&o2imshardwaremanagementcontroller.HardwareProfileValidator{
+			Client:    mgr.GetClient(),
+			Logger:    slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("webhook", "HardwareProfile")),
+			Namespace: config.namespace,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create webhook HardwareProfile: %w", err)
+		}
+
+		if err := (&o2imshardwaremanagementcontroller.NodePoolValidator{
+			Logger:    slog.New(logging.NewLoggingContextHandler(slog.LevelInfo)).With(slog.String("webhook", "NodePool")),
+			Namespace: config.namespace,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to create webhook NodePool: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func main() {
 	os.Exit(_main())
 }
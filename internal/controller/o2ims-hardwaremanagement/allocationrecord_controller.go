@@ -0,0 +1,347 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// DefaultAllocationRecordRetention is the default time an AllocationRecord is kept after its NodePool is deleted
+const DefaultAllocationRecordRetention = 30 * 24 * time.Hour
+
+// AllocationRecordReconciler maintains an AllocationRecord for each NodePool, recording the hardware, profiles and
+// hardware manager jobs used to serve it. Records are kept for the retention period after the NodePool is deleted.
+type AllocationRecordReconciler struct {
+	client.Client
+	Logger    *slog.Logger
+	Namespace string
+	Retention time.Duration
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=allocationrecords,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=allocationrecords/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+
+// Reconcile updates the AllocationRecords for the NodePool name in the request, releasing and expiring the records
+// of NodePools that no longer exist
+func (r *AllocationRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("nodepool", req.Name))
+
+	nodepool := &hwmgmtv1alpha1.NodePool{}
+	if err := r.Client.Get(ctx, req.NamespacedName, nodepool); err != nil {
+		if !errors.IsNotFound(err) {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get nodepool %s: %w", req.Name, err)
+		}
+		nodepool = nil
+	}
+
+	records := &pluginv1alpha1.AllocationRecordList{}
+	if err := r.Client.List(ctx, records, client.InNamespace(r.Namespace)); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to list allocation records: %w", err)
+	}
+
+	var current *pluginv1alpha1.AllocationRecord
+	var nextExpiry *time.Time
+	for i := range records.Items {
+		record := &records.Items[i]
+		if record.Spec.NodePool != req.Name {
+			continue
+		}
+
+		if nodepool != nil && record.Spec.NodePoolUID == nodepool.UID {
+			current = record
+			continue
+		}
+
+		// The NodePool for this record no longer exists
+		expired, err := r.releaseRecord(ctx, record)
+		if err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		if !expired && (nextExpiry == nil || record.Status.ExpiresAt.Time.Before(*nextExpiry)) {
+			nextExpiry = &record.Status.ExpiresAt.Time
+		}
+	}
+
+	// Nothing is allocated for a dry run, so there is nothing to record
+	if nodepool != nil && (current != nil || !utils.IsNodePoolDryRun(nodepool)) {
+		if current == nil {
+			var err error
+			if current, err = r.createRecord(ctx, nodepool); err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+		}
+
+		if err := r.syncRecord(ctx, current, nodepool); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	if nextExpiry != nil {
+		return utils.RequeueWithCustomInterval(time.Until(*nextExpiry)), nil
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// createRecord creates the AllocationRecord for a NodePool. The record is not owned by the NodePool, so that it is
+// kept after the NodePool is deleted.
+func (r *AllocationRecordReconciler) createRecord(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) (*pluginv1alpha1.AllocationRecord, error) {
+	record := &pluginv1alpha1.AllocationRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", nodepool.Name, string(nodepool.UID)[:8]),
+			Namespace: r.Namespace,
+		},
+		Spec: pluginv1alpha1.AllocationRecordSpec{
+			NodePool:    nodepool.Name,
+			NodePoolUID: nodepool.UID,
+			CloudID:     nodepool.Spec.CloudID,
+			Site:        nodepool.Spec.Site,
+			HwMgrId:     nodepool.Spec.HwMgrId,
+		},
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: nodepool.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err == nil {
+		record.Spec.AdaptorID = hwmgr.Spec.AdaptorID
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get HardwareManager %s: %w", nodepool.Spec.HwMgrId, err)
+	}
+
+	r.Logger.InfoContext(ctx, "Creating allocation record", slog.String("record", record.Name))
+	if err := r.Client.Create(ctx, record); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("failed to create allocation record %s: %w", record.Name, err)
+	}
+
+	return record, nil
+}
+
+// syncRecord adds the current Nodes and hardware manager jobs of a NodePool to its AllocationRecord
+func (r *AllocationRecordReconciler) syncRecord(ctx context.Context,
+	record *pluginv1alpha1.AllocationRecord, nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	if err := r.Client.List(ctx, nodes, client.InNamespace(r.Namespace)); err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	// nolint: wrapcheck
+	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		updated := &pluginv1alpha1.AllocationRecord{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(record), updated); err != nil {
+			return fmt.Errorf("failed to get allocation record %s: %w", record.Name, err)
+		}
+
+		now := metav1.Now()
+		changed := false
+		current := map[string]bool{}
+
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if !isOwnedBy(node, nodepool.UID) {
+				continue
+			}
+			current[node.Name] = true
+			if recordNode(&updated.Status, node) {
+				changed = true
+			}
+			if jobId := utils.GetJobId(node); jobId != "" &&
+				recordJob(&updated.Status, jobId, pluginv1alpha1.AllocationOperations.ProfileUpdate, node.Name, now) {
+				changed = true
+			}
+		}
+
+		for i := range updated.Status.Nodes {
+			entry := &updated.Status.Nodes[i]
+			if entry.ReleasedAt == nil && !current[entry.NodeName] {
+				entry.ReleasedAt = &now
+				changed = true
+			}
+		}
+
+		if jobId := utils.GetJobId(nodepool); jobId != "" &&
+			recordJob(&updated.Status, jobId, pluginv1alpha1.AllocationOperations.Allocation, "", now) {
+			changed = true
+		}
+		if jobId := utils.GetDeletionJobId(nodepool); jobId != "" &&
+			recordJob(&updated.Status, jobId, pluginv1alpha1.AllocationOperations.Release, "", now) {
+			changed = true
+		}
+
+		if !changed {
+			return nil
+		}
+		return r.Client.Status().Update(ctx, updated)
+	})
+}
+
+// releaseRecord marks the AllocationRecord of a deleted NodePool as released, deleting it once the retention period
+// has passed. It returns true if the record has expired.
+func (r *AllocationRecordReconciler) releaseRecord(ctx context.Context, record *pluginv1alpha1.AllocationRecord) (bool, error) {
+	if record.Status.ExpiresAt != nil {
+		if time.Now().Before(record.Status.ExpiresAt.Time) {
+			return false, nil
+		}
+
+		r.Logger.InfoContext(ctx, "Deleting expired allocation record", slog.String("record", record.Name))
+		if err := r.Client.Delete(ctx, record); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("failed to delete allocation record %s: %w", record.Name, err)
+		}
+		return true, nil
+	}
+
+	now := metav1.Now()
+	expiresAt := metav1.NewTime(now.Add(r.Retention))
+	record.Status.ReleasedAt = &now
+	record.Status.ExpiresAt = &expiresAt
+	for i := range record.Status.Nodes {
+		if record.Status.Nodes[i].ReleasedAt == nil {
+			record.Status.Nodes[i].ReleasedAt = &now
+		}
+	}
+
+	r.Logger.InfoContext(ctx, "Releasing allocation record", slog.String("record", record.Name),
+		slog.String("expiresAt", expiresAt.String()))
+	if err := r.Client.Status().Update(ctx, record); err != nil {
+		return false, fmt.Errorf("failed to release allocation record %s: %w", record.Name, err)
+	}
+
+	return false, nil
+}
+
+// recordNode adds a Node to the record, or updates its entry with a new hardware profile. It returns true if the
+// record was changed.
+func recordNode(status *pluginv1alpha1.AllocationRecordStatus, node *hwmgmtv1alpha1.Node) bool {
+	for i := range status.Nodes {
+		entry := &status.Nodes[i]
+		if entry.NodeName != node.Name {
+			continue
+		}
+
+		changed := false
+		if entry.HwMgrNodeId == "" && node.Spec.HwMgrNodeId != "" {
+			entry.HwMgrNodeId = node.Spec.HwMgrNodeId
+			entry.HwMgrNodeNs = node.Spec.HwMgrNodeNs
+			changed = true
+		}
+		if node.Spec.HwProfile != "" &&
+			(len(entry.HwProfiles) == 0 || entry.HwProfiles[len(entry.HwProfiles)-1] != node.Spec.HwProfile) {
+			entry.HwProfiles = append(entry.HwProfiles, node.Spec.HwProfile)
+			changed = true
+		}
+		return changed
+	}
+
+	entry := pluginv1alpha1.AllocatedNodeRecord{
+		NodeName:    node.Name,
+		GroupName:   node.Spec.GroupName,
+		HwMgrNodeId: node.Spec.HwMgrNodeId,
+		HwMgrNodeNs: node.Spec.HwMgrNodeNs,
+		AllocatedAt: node.CreationTimestamp,
+	}
+	if node.Spec.HwProfile != "" {
+		entry.HwProfiles = []string{node.Spec.HwProfile}
+	}
+	status.Nodes = append(status.Nodes, entry)
+	return true
+}
+
+// recordJob adds a hardware manager job to the record, if not already present. It returns true if the record was
+// changed.
+func recordJob(status *pluginv1alpha1.AllocationRecordStatus, jobId string,
+	operation pluginv1alpha1.AllocationOperation, nodename string, now metav1.Time) bool {
+	for _, job := range status.Jobs {
+		if job.JobId == jobId && job.Operation == operation {
+			return false
+		}
+	}
+
+	status.Jobs = append(status.Jobs, pluginv1alpha1.JobRecord{
+		JobId:      jobId,
+		Operation:  operation,
+		NodeName:   nodename,
+		ObservedAt: now,
+	})
+	return true
+}
+
+// isOwnedBy checks whether an object has an owner reference with the given UID
+func isOwnedBy(object client.Object, uid types.UID) bool {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AllocationRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	// Map a Node to the NodePool that owns it
+	nodeToNodePool := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+		var requests []reconcile.Request
+		for _, ref := range object.GetOwnerReferences() {
+			if ref.Kind == "NodePool" {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Name:      ref.Name,
+					Namespace: object.GetNamespace(),
+				}})
+			}
+		}
+		return requests
+	})
+
+	// Map an AllocationRecord to its NodePool, so that records are released and expired after a restart
+	recordToNodePool := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+		record, ok := object.(*pluginv1alpha1.AllocationRecord)
+		if !ok {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{
+			Name:      record.Spec.NodePool,
+			Namespace: record.Namespace,
+		}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("allocation-record").
+		For(&hwmgmtv1alpha1.NodePool{}, builder.WithPredicates(inNamespace)).
+		Watches(&hwmgmtv1alpha1.Node{}, nodeToNodePool, builder.WithPredicates(inNamespace)).
+		Watches(&pluginv1alpha1.AllocationRecord{}, recordToNodePool,
+			builder.WithPredicates(inNamespace, predicate.GenerationChangedPredicate{})).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create allocation record controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// artifactDownloadTimeout limits the download of a single file to the artifact cache
+const artifactDownloadTimeout = 30 * time.Minute
+
+// ArtifactCacheReconciler mirrors the firmware images and signatures of HardwareProfiles to the artifact cache
+// directory, which is served to the BMCs by the artifact cache server. The mirrored files of each profile are listed in
+// its status, and files no longer referenced by any profile are removed.
+type ArtifactCacheReconciler struct {
+	client.Client
+	Logger    *slog.Logger
+	Namespace string
+	// Dir is the artifact cache directory, normally on a persistent volume
+	Dir        string
+	HTTPClient *http.Client
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwareprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwareprofiles/status,verbs=get;update;patch
+
+// artifact is a file of a hardware profile to be cached, with the checksum it is verified against, if any
+type artifact struct {
+	url      string
+	checksum string
+}
+
+// profileArtifacts returns the firmware images and signatures of a resolved hardware profile that can be cached.
+// Only files served over http or https are cached.
+func profileArtifacts(spec pluginv1alpha1.HardwareProfileSpec) []artifact {
+	var artifacts []artifact
+	for _, fw := range []pluginv1alpha1.Firmware{spec.BiosFirmware, spec.BmcFirmware} {
+		if utils.IsCacheableArtifactURL(fw.URL) {
+			artifacts = append(artifacts, artifact{url: fw.URL, checksum: strings.ToLower(fw.Checksum)})
+		}
+		if fw.Signature != nil && utils.IsCacheableArtifactURL(fw.Signature.URL) {
+			artifacts = append(artifacts, artifact{url: fw.Signature.URL})
+		}
+	}
+	return artifacts
+}
+
+// artifactPath returns the path of a file in the artifact cache. Each URL is given its own directory, keeping the
+// original file name, as some BMCs expect the file extension of the image.
+func artifactPath(rawURL string) string {
+	digest := sha256.Sum256([]byte(rawURL))
+	name := "artifact"
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if base := path.Base(parsed.Path); base != "/" && base != "." {
+			name = base
+		}
+	}
+	return hex.EncodeToString(digest[:16]) + "/" + name
+}
+
+// Reconcile caches the files of the HardwareProfile in the request, and removes the cached files that are no longer
+// referenced by any profile
+func (r *ArtifactCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("hwProfile", req.Name))
+
+	result := utils.DoNotRequeue()
+	profile, err := utils.GetHardwareProfile(ctx, r.Client, r.Namespace, req.Name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return utils.RequeueWithShortInterval(), err
+		}
+	} else if err := r.cacheProfileArtifacts(ctx, profile); err != nil {
+		r.Logger.ErrorContext(ctx, "Failed to cache hardware profile artifacts", slog.String("error", err.Error()))
+		result = utils.RequeueWithMediumInterval()
+	}
+
+	if err := r.removeUnreferencedArtifacts(ctx); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	return result, nil
+}
+
+// cacheProfileArtifacts ensures that each file of the resolved profile is in the cache, reporting the cached files and
+// the outcome in the status of the profile
+func (r *ArtifactCacheReconciler) cacheProfileArtifacts(ctx context.Context, profile *pluginv1alpha1.HardwareProfile) error {
+	previous := make(map[string]pluginv1alpha1.CachedArtifact, len(profile.Status.CachedArtifacts))
+	for _, cached := range profile.Status.CachedArtifacts {
+		previous[cached.Path] = cached
+	}
+
+	var cachedArtifacts []pluginv1alpha1.CachedArtifact
+	var cacheErr error
+	for _, item := range profileArtifacts(profile.Spec) {
+		cached, err := r.cacheArtifact(ctx, item, previous)
+		if err != nil {
+			cacheErr = fmt.Errorf("failed to cache %s: %w", item.url, err)
+			break
+		}
+		cachedArtifacts = append(cachedArtifacts, cached)
+	}
+
+	condition := metav1.Condition{
+		Type:    string(pluginv1alpha1.ConditionTypes.ArtifactsCached),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(pluginv1alpha1.ConditionReasons.Completed),
+		Message: fmt.Sprintf("Cached %d artifacts", len(cachedArtifacts)),
+	}
+	if cacheErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(pluginv1alpha1.ConditionReasons.Failed)
+		condition.Message = cacheErr.Error()
+	}
+
+	if err := r.updateProfileStatus(ctx, profile.Name, cachedArtifacts, condition); err != nil {
+		return err
+	}
+	return cacheErr
+}
+
+// cacheArtifact returns the cached file of an artifact, downloading it if it is not yet cached or no longer matches
+// its checksum
+func (r *ArtifactCacheReconciler) cacheArtifact(ctx context.Context, item artifact,
+	previous map[string]pluginv1alpha1.CachedArtifact) (pluginv1alpha1.CachedArtifact, error) {
+
+	relPath := artifactPath(item.url)
+	filePath := filepath.Join(r.Dir, filepath.FromSlash(relPath))
+
+	if info, err := os.Stat(filePath); err == nil {
+		cached, known := previous[relPath]
+		if !known || cached.Size != info.Size() {
+			checksum, err := fileChecksum(filePath)
+			if err != nil {
+				return pluginv1alpha1.CachedArtifact{}, err
+			}
+			cached = pluginv1alpha1.CachedArtifact{
+				URL: item.url, Path: relPath, Size: info.Size(), Checksum: checksum,
+				CachedAt: metav1.NewTime(info.ModTime()),
+			}
+		}
+		if item.checksum == "" || cached.Checksum == item.checksum {
+			return cached, nil
+		}
+		r.Logger.InfoContext(ctx, "Cached artifact does not match its checksum, downloading it again",
+			slog.String("url", item.url))
+	}
+
+	r.Logger.InfoContext(ctx, "Downloading artifact", slog.String("url", item.url), slog.String("path", relPath))
+	size, checksum, err := r.download(ctx, item, filePath)
+	if err != nil {
+		return pluginv1alpha1.CachedArtifact{}, err
+	}
+	r.Logger.InfoContext(ctx, "Cached artifact", slog.String("url", item.url), slog.Int64("size", size))
+
+	return pluginv1alpha1.CachedArtifact{
+		URL: item.url, Path: relPath, Size: size, Checksum: checksum, CachedAt: metav1.Now(),
+	}, nil
+}
+
+// download fetches a file into the cache, verifying it against its checksum. The file is written under a temporary
+// name and renamed once complete, so that the server never serves a partial file.
+func (r *ArtifactCacheReconciler) download(ctx context.Context, item artifact, filePath string) (int64, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, artifactDownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, item.url, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, "", fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return 0, "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".download-*")
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to download: %w", err)
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if item.checksum != "" && checksum != item.checksum {
+		return 0, "", fmt.Errorf("checksum mismatch: expected %s, got %s", item.checksum, checksum)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return 0, "", fmt.Errorf("failed to set cache file permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return 0, "", fmt.Errorf("failed to store cache file: %w", err)
+	}
+	return size, checksum, nil
+}
+
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open cache file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read cache file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (r *ArtifactCacheReconciler) updateProfileStatus(ctx context.Context, name string,
+	cachedArtifacts []pluginv1alpha1.CachedArtifact, condition metav1.Condition) error {
+
+	// nolint: wrapcheck
+	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		profile := &pluginv1alpha1.HardwareProfile{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: r.Namespace}, profile); err != nil {
+			return fmt.Errorf("failed to get HardwareProfile %s: %w", name, err)
+		}
+		profile.Status.CachedArtifacts = cachedArtifacts
+		meta.SetStatusCondition(&profile.Status.Conditions, condition)
+		return r.Client.Status().Update(ctx, profile)
+	})
+}
+
+// removeUnreferencedArtifacts removes the cached files that are not referenced by the resolved spec of any profile
+func (r *ArtifactCacheReconciler) removeUnreferencedArtifacts(ctx context.Context) error {
+	profiles := &pluginv1alpha1.HardwareProfileList{}
+	if err := r.Client.List(ctx, profiles, client.InNamespace(r.Namespace)); err != nil {
+		return fmt.Errorf("failed to list HardwareProfiles: %w", err)
+	}
+
+	referenced := make(map[string]bool)
+	for _, item := range profiles.Items {
+		profile, err := utils.GetHardwareProfile(ctx, r.Client, r.Namespace, item.Name)
+		if err != nil {
+			// Keep the files of a profile that cannot be resolved, which may only be a transient problem
+			for _, cached := range item.Status.CachedArtifacts {
+				referenced[path.Dir(cached.Path)] = true
+			}
+			continue
+		}
+		for _, file := range profileArtifacts(profile.Spec) {
+			referenced[path.Dir(artifactPath(file.url))] = true
+		}
+	}
+
+	entries, err := os.ReadDir(r.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read artifact cache directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || referenced[entry.Name()] {
+			continue
+		}
+		r.Logger.InfoContext(ctx, "Removing unreferenced artifact", slog.String("path", entry.Name()))
+		if err := os.RemoveAll(filepath.Join(r.Dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove artifact %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArtifactCacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create artifact cache directory: %w", err)
+	}
+
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	// A change to a base profile changes the files of each profile built on it
+	profileToDerived := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		profiles, err := utils.GetDerivedHardwareProfiles(ctx, r.Client, r.Namespace, object.GetName())
+		if err != nil {
+			r.Logger.ErrorContext(ctx, "Failed to find profiles derived from HardwareProfile",
+				slog.String("hwProfile", object.GetName()), slog.String("error", err.Error()))
+			profiles = map[string]bool{object.GetName(): true}
+		}
+		var requests []reconcile.Request
+		for name := range profiles {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: name, Namespace: r.Namespace}})
+		}
+		return requests
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("artifact-cache").
+		Watches(&pluginv1alpha1.HardwareProfile{}, profileToDerived,
+			builder.WithPredicates(inNamespace, predicate.GenerationChangedPredicate{})).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create artifact cache controller: %w", err)
+	}
+
+	return nil
+}
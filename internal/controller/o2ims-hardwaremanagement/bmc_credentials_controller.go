@@ -0,0 +1,286 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Event reasons
+const (
+	BMCCredentialsRotated        = "BMCCredentialsRotated"
+	BMCCredentialsRotationFailed = "BMCCredentialsRotationFailed"
+)
+
+// BMCCredentialsReconciler rotates the BMC credentials of allocated nodes to match the credentials secret configured
+// on their HardwareProfile or HardwareManager. The applied revision of the secret is recorded in an annotation on each
+// Node, with the state of the rotation reported in a Node condition.
+type BMCCredentialsReconciler struct {
+	client.Client
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+	Recorder     record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwareprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
+
+// Reconcile checks whether the BMC credentials of the node in the request are up to date, starting or continuing a
+// rotation through the adaptor if not
+func (r *BMCCredentialsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("nodename", req.Name))
+
+	node := &hwmgmtv1alpha1.Node{}
+	if err := r.Client.Get(ctx, req.NamespacedName, node); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get node %s: %w", req.Name, err)
+	}
+
+	// Credentials are only rotated once the node has been allocated. A change in the node status retriggers this.
+	if node.DeletionTimestamp != nil ||
+		!meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	if utils.GetConfigAnnotation(node) != "" {
+		// Wait for a hardware configuration update on the node to finish
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	// Rotation submits changes to the hardware, so it waits while the NodePool of the node is paused
+	if paused, err := utils.IsNodeNodePoolPaused(ctx, r.Client, node); err != nil {
+		return utils.RequeueWithShortInterval(), err // nolint: wrapcheck
+	} else if paused {
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+
+	hwProfile, err := utils.GetHardwareProfile(ctx, r.Client, r.Namespace, node.Spec.HwProfile)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get HardwareProfile %s: %w", node.Spec.HwProfile, err)
+		}
+		hwProfile = nil
+	}
+
+	secretName := utils.GetBmcCredentialsSecretName(hwmgr, hwProfile)
+	if secretName == "" {
+		return utils.DoNotRequeue(), nil
+	}
+
+	credentials := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: r.Namespace}, credentials); err != nil {
+		if errors.IsNotFound(err) {
+			// The secret watch retriggers the rotation once the secret is created
+			return utils.DoNotRequeue(), r.setRotationCondition(ctx, node, metav1.ConditionFalse,
+				pluginv1alpha1.ConditionReasons.Failed, fmt.Sprintf("BMC credentials secret %s not found", secretName))
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get secret %s: %w", secretName, err)
+	}
+
+	revision := utils.GetBmcCredentialsRevision(credentials)
+	if node.Annotations[utils.BmcCredentialsRevisionAnnotation] == revision {
+		return utils.DoNotRequeue(), nil
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("revision", revision))
+
+	// A failed rotation is retried without resetting the condition, so the failure remains visible until resolved
+	condition := meta.FindStatusCondition(node.Status.Conditions, string(pluginv1alpha1.ConditionTypes.BMCCredentialsRotation))
+	if condition == nil || condition.Reason != string(pluginv1alpha1.ConditionReasons.Failed) {
+		if err := r.setRotationCondition(ctx, node, metav1.ConditionFalse, pluginv1alpha1.ConditionReasons.InProgress,
+			fmt.Sprintf("Rotating BMC credentials to %s", revision)); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	completed, err := r.HwMgrAdaptor.RotateBMCCredentials(ctx, hwmgr, node, credentials)
+	if err != nil {
+		r.Logger.ErrorContext(ctx, "BMC credentials rotation failed", slog.String("error", err.Error()))
+		r.Recorder.Eventf(node, corev1.EventTypeWarning, BMCCredentialsRotationFailed,
+			"Failed to rotate BMC credentials to %s: %s", revision, err.Error())
+		if err := r.setRotationCondition(ctx, node, metav1.ConditionFalse,
+			pluginv1alpha1.ConditionReasons.Failed, err.Error()); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	if !completed {
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	if err := utils.SetNodeAnnotation(ctx, r.Client, node, utils.BmcCredentialsRevisionAnnotation, revision); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to record BMC credentials revision on node %s: %w", node.Name, err)
+	}
+
+	if err := r.setRotationCondition(ctx, node, metav1.ConditionTrue, pluginv1alpha1.ConditionReasons.Completed,
+		fmt.Sprintf("BMC credentials rotated to %s", revision)); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	r.Logger.InfoContext(ctx, "BMC credentials rotated")
+	r.Recorder.Eventf(node, corev1.EventTypeNormal, BMCCredentialsRotated, "Rotated BMC credentials to %s", revision)
+
+	return utils.DoNotRequeue(), nil
+}
+
+// setRotationCondition sets the BMC credentials rotation condition on a node, if it has changed
+func (r *BMCCredentialsReconciler) setRotationCondition(ctx context.Context, node *hwmgmtv1alpha1.Node,
+	status metav1.ConditionStatus, reason pluginv1alpha1.ConditionReason, message string) error {
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.BMCCredentialsRotation)
+	condition := meta.FindStatusCondition(node.Status.Conditions, conditionType)
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) && utils.ConditionMessage(condition) == message {
+		return nil
+	}
+
+	if err := utils.SetNodeConditionStatus(ctx, r.Client, node.Name, node.Namespace,
+		conditionType, status, string(reason), message); err != nil {
+		return fmt.Errorf("failed to set BMC credentials rotation condition on node %s: %w", node.Name, err)
+	}
+
+	utils.SetStatusCondition(ctx, &node.Status.Conditions, conditionType, string(reason), status, message)
+	return nil
+}
+
+// nodesMatching returns a request for each node in the namespace accepted by the filter
+func (r *BMCCredentialsReconciler) nodesMatching(ctx context.Context, filter func(*hwmgmtv1alpha1.Node) bool) []reconcile.Request {
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	if err := r.Client.List(ctx, nodes, client.InNamespace(r.Namespace)); err != nil {
+		r.Logger.ErrorContext(ctx, "Failed to list nodes", slog.String("error", err.Error()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range nodes.Items {
+		if filter(&nodes.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&nodes.Items[i])})
+		}
+	}
+	return requests
+}
+
+// secretToNodes maps a credentials secret to the nodes using a HardwareProfile or HardwareManager that references it
+func (r *BMCCredentialsReconciler) secretToNodes(ctx context.Context, object client.Object) []reconcile.Request {
+	profiles := map[string]bool{}
+	hwProfiles := &pluginv1alpha1.HardwareProfileList{}
+	if err := r.Client.List(ctx, hwProfiles, client.InNamespace(r.Namespace)); err != nil {
+		r.Logger.ErrorContext(ctx, "Failed to list HardwareProfiles", slog.String("error", err.Error()))
+		return nil
+	}
+	for _, hwProfile := range hwProfiles.Items {
+		// The secret may be inherited from a base profile
+		resolved, err := utils.GetHardwareProfile(ctx, r.Client, r.Namespace, hwProfile.Name)
+		if err != nil {
+			r.Logger.InfoContext(ctx, "Unable to resolve HardwareProfile", slog.String("hwProfile", hwProfile.Name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if resolved.Spec.BmcCredentialsSecret == object.GetName() {
+			profiles[hwProfile.Name] = true
+		}
+	}
+
+	hwmgrs := map[string]bool{}
+	hwmgrList := &pluginv1alpha1.HardwareManagerList{}
+	if err := r.Client.List(ctx, hwmgrList, client.InNamespace(r.Namespace)); err != nil {
+		r.Logger.ErrorContext(ctx, "Failed to list HardwareManagers", slog.String("error", err.Error()))
+		return nil
+	}
+	for _, hwmgr := range hwmgrList.Items {
+		if hwmgr.Spec.BmcCredentialsSecret != nil && *hwmgr.Spec.BmcCredentialsSecret == object.GetName() {
+			hwmgrs[hwmgr.Name] = true
+		}
+	}
+
+	if len(profiles) == 0 && len(hwmgrs) == 0 {
+		return nil
+	}
+
+	return r.nodesMatching(ctx, func(node *hwmgmtv1alpha1.Node) bool {
+		return profiles[node.Spec.HwProfile] || hwmgrs[node.Spec.HwMgrId]
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BMCCredentialsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	profileToNodes := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		// A change to a base profile applies to the nodes of each profile built on it
+		profiles, err := utils.GetDerivedHardwareProfiles(ctx, r.Client, r.Namespace, object.GetName())
+		if err != nil {
+			r.Logger.ErrorContext(ctx, "Failed to find profiles derived from HardwareProfile",
+				slog.String("hwProfile", object.GetName()), slog.String("error", err.Error()))
+			profiles = map[string]bool{object.GetName(): true}
+		}
+		return r.nodesMatching(ctx, func(node *hwmgmtv1alpha1.Node) bool {
+			return profiles[node.Spec.HwProfile]
+		})
+	})
+
+	hwmgrToNodes := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		return r.nodesMatching(ctx, func(node *hwmgmtv1alpha1.Node) bool {
+			return node.Spec.HwMgrId == object.GetName()
+		})
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("bmc-credentials").
+		For(&hwmgmtv1alpha1.Node{}, builder.WithPredicates(inNamespace)).
+		Watches(&pluginv1alpha1.HardwareProfile{}, profileToNodes,
+			builder.WithPredicates(inNamespace, predicate.GenerationChangedPredicate{})).
+		Watches(&pluginv1alpha1.HardwareManager{}, hwmgrToNodes,
+			builder.WithPredicates(inNamespace, predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.secretToNodes),
+			builder.WithPredicates(inNamespace, predicate.ResourceVersionChangedPredicate{})).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create BMC credentials controller: %w", err)
+	}
+
+	return nil
+}
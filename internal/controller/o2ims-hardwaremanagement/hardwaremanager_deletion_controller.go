@@ -0,0 +1,151 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// HardwareManagerDeletionReconciler holds a finalizer on each HardwareManager, blocking its deletion while any NodePool
+// still references it, as those NodePools can no longer be reconciled or released without it
+type HardwareManagerDeletionReconciler struct {
+	client.Client
+	Logger    *slog.Logger
+	Namespace string
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+
+// Reconcile adds the finalizer to the HardwareManager in the request, or removes it once the HardwareManager is being
+// deleted and no NodePool references it
+func (r *HardwareManagerDeletionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.WithHwMgr(ctx, req.Name)
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get HardwareManager %s: %w", req.Name, err)
+	}
+
+	if hwmgr.DeletionTimestamp == nil {
+		if !controllerutil.ContainsFinalizer(hwmgr, utils.HardwareManagerFinalizer) {
+			if err := utils.HardwareManagerAddFinalizer(ctx, r.Client, hwmgr); err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+		}
+		return utils.DoNotRequeue(), nil
+	}
+
+	if !controllerutil.ContainsFinalizer(hwmgr, utils.HardwareManagerFinalizer) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	nodepools, err := r.referencingNodePools(ctx, hwmgr.Name)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	if len(nodepools) > 0 {
+		// The NodePool watch retriggers this as the NodePools are deleted
+		message := fmt.Sprintf("Deletion blocked by NodePools referencing this HardwareManager: %s", strings.Join(nodepools, ", "))
+		condition := meta.FindStatusCondition(hwmgr.Status.Conditions, string(pluginv1alpha1.ConditionTypes.Deletion))
+		if condition == nil || utils.ConditionMessage(condition) != message {
+			r.Logger.InfoContext(ctx, "HardwareManager deletion blocked", slog.Any("nodepools", nodepools))
+			if err := utils.UpdateHardwareManagerStatusCondition(ctx, r.Client, hwmgr,
+				pluginv1alpha1.ConditionTypes.Deletion,
+				pluginv1alpha1.ConditionReasons.InProgress,
+				metav1.ConditionFalse,
+				message); err != nil {
+				return utils.RequeueWithShortInterval(), err
+			}
+		}
+		return utils.DoNotRequeue(), nil
+	}
+
+	if err := utils.HardwareManagerRemoveFinalizer(ctx, r.Client, hwmgr); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	r.Logger.InfoContext(ctx, "HardwareManager is no longer referenced, deletion can proceed")
+	return utils.DoNotRequeue(), nil
+}
+
+// referencingNodePools returns the names of the NodePools, across all namespaces, that reference the HardwareManager
+func (r *HardwareManagerDeletionReconciler) referencingNodePools(ctx context.Context, hwMgrId string) ([]string, error) {
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, nodepools); err != nil {
+		return nil, fmt.Errorf("failed to list nodepools: %w", err)
+	}
+
+	var names []string
+	for _, nodepool := range nodepools.Items {
+		if nodepool.Spec.HwMgrId == hwMgrId {
+			names = append(names, nodepool.Namespace+"/"+nodepool.Name)
+		}
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HardwareManagerDeletionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	nodepoolToHwMgr := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{
+			Name:      object.(*hwmgmtv1alpha1.NodePool).Spec.HwMgrId,
+			Namespace: r.Namespace,
+		}}}
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("hardwaremanager-deletion").
+		For(&pluginv1alpha1.HardwareManager{}, builder.WithPredicates(inNamespace)).
+		Watches(&hwmgmtv1alpha1.NodePool{}, nodepoolToHwMgr,
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(_ event.CreateEvent) bool { return false },
+				UpdateFunc:  func(_ event.UpdateEvent) bool { return false },
+				DeleteFunc:  func(_ event.DeleteEvent) bool { return true },
+				GenericFunc: func(_ event.GenericEvent) bool { return false },
+			})).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create HardwareManager deletion controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+// HardwareManagerLoggingReconciler applies the logging configuration of each HardwareManager, registering its log
+// levels as they change and removing them once it is deleted
+type HardwareManagerLoggingReconciler struct {
+	client.Client
+	Logger    *slog.Logger
+	Namespace string
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+
+// Reconcile registers the log levels of the HardwareManager in the request
+func (r *HardwareManagerLoggingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String(logging.HwMgrAttr, req.Name))
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, req.NamespacedName, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			logging.ClearHwMgrLevels(req.Name)
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get HardwareManager %s: %w", req.Name, err)
+	}
+
+	levels, err := utils.GetHardwareManagerLogLevels(hwmgr)
+	if err != nil {
+		// The levels are validated by the CRD, so this is not expected to be fixed by a retry
+		r.Logger.WarnContext(ctx, "Invalid logging configuration", slog.String("error", err.Error()))
+		logging.ClearHwMgrLevels(req.Name)
+		return utils.DoNotRequeue(), nil
+	}
+
+	logging.SetHwMgrLevels(req.Name, levels)
+	return utils.DoNotRequeue(), nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HardwareManagerLoggingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	// The logging configuration is in the spec, apart from the message tracing annotation
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("hardwaremanager-logging").
+		For(&pluginv1alpha1.HardwareManager{}, builder.WithPredicates(inNamespace,
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create HardwareManager logging controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,440 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	metal3v1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+const (
+	// firmwareReachabilityTimeout limits the check of a single firmware URL of a HardwareProfile
+	firmwareReachabilityTimeout = 30 * time.Second
+
+	// hardwareProfileValidationInterval is how often a HardwareProfile is validated again
+	hardwareProfileValidationInterval = time.Hour
+)
+
+// HardwareProfileReconciler validates HardwareProfiles and reports where they are used. The Validated condition
+// reports whether the BIOS attributes of the resolved profile are accepted by the firmware schema of any known hardware
+// model, and whether its firmware images can be reached. The InUse condition, with the list of Nodes in the status,
+// reports whether the profile is used by any Node or NodePool, and so whether it is safe to edit or delete.
+type HardwareProfileReconciler struct {
+	client.Client
+	Logger     *slog.Logger
+	Namespace  string
+	HTTPClient *http.Client
+}
+
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwareprofiles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwareprofiles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=metal3.io,resources=firmwareschemas,verbs=get;list;watch
+
+// Reconcile validates the HardwareProfile in the request and updates its usage. Validation is repeated periodically,
+// as the firmware schemas of newly inspected hardware and the reachability of the firmware images change over time.
+func (r *HardwareProfileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("hwProfile", req.Name))
+
+	profile := &pluginv1alpha1.HardwareProfile{}
+	if err := r.Client.Get(ctx, req.NamespacedName, profile); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get HardwareProfile %s: %w", req.Name, err)
+	}
+
+	validated := r.validateProfile(ctx, profile.Name)
+
+	nodes, inUse, err := r.getProfileUsage(ctx, profile.Name)
+	if err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	if err := r.updateProfileStatus(ctx, profile.Name, profile.Generation, nodes, validated, inUse); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to update status of HardwareProfile %s: %w", profile.Name, err)
+	}
+
+	return utils.RequeueWithCustomInterval(hardwareProfileValidationInterval), nil
+}
+
+// validateProfile returns the Validated condition of a profile, checking its resolved BIOS attributes against the
+// known firmware schemas and the reachability of its firmware images
+func (r *HardwareProfileReconciler) validateProfile(ctx context.Context, name string) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   string(pluginv1alpha1.ConditionTypes.Validated),
+		Status: metav1.ConditionFalse,
+	}
+
+	profile, err := utils.GetHardwareProfile(ctx, r.Client, r.Namespace, name)
+	if err != nil {
+		condition.Reason = string(pluginv1alpha1.ConditionReasons.Failed)
+		condition.Message = err.Error()
+		return condition
+	}
+
+	compatible, err := r.checkBiosSchemas(ctx, profile.Spec.Bios.Attributes)
+	if err != nil {
+		condition.Reason = string(pluginv1alpha1.ConditionReasons.SchemaIncompatible)
+		condition.Message = err.Error()
+		return condition
+	}
+
+	if err := r.checkFirmwareURLs(ctx, profile.Spec); err != nil {
+		condition.Reason = string(pluginv1alpha1.ConditionReasons.FirmwareUnreachable)
+		condition.Message = err.Error()
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = string(pluginv1alpha1.ConditionReasons.Completed)
+	switch {
+	case len(profile.Spec.Bios.Attributes) == 0:
+		condition.Message = "Profile is valid"
+	case len(compatible) == 0:
+		condition.Message = "Profile is valid; BIOS attributes not checked, as no firmware schemas are known"
+	default:
+		condition.Message = "Profile is valid; BIOS attributes compatible with " + strings.Join(compatible, ", ")
+	}
+	return condition
+}
+
+// checkBiosSchemas checks the BIOS attributes of a profile against the firmware schemas reported by metal3 for the
+// inspected hardware. It returns the hardware models whose schema accepts every attribute, and an error if the
+// attributes are accepted by no known schema. The check is skipped if no schemas are known, such as when metal3 is not
+// installed.
+func (r *HardwareProfileReconciler) checkBiosSchemas(ctx context.Context, attributes map[string]intstr.IntOrString) ([]string, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	schemas := &metal3v1alpha1.FirmwareSchemaList{}
+	if err := r.Client.List(ctx, schemas); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		r.Logger.WarnContext(ctx, "Unable to list FirmwareSchemas, skipping BIOS attribute validation",
+			slog.String("error", err.Error()))
+		return nil, nil
+	}
+
+	// Identical schemas are reported for each host of the same model, so each model is checked once
+	models := make(map[string]*metal3v1alpha1.FirmwareSchema)
+	for i := range schemas.Items {
+		schema := &schemas.Items[i]
+		if schema.Spec.HardwareVendor == "" && schema.Spec.HardwareModel == "" {
+			continue
+		}
+		model := strings.TrimSpace(schema.Spec.HardwareVendor + " " + schema.Spec.HardwareModel)
+		if _, exists := models[model]; !exists {
+			models[model] = schema
+		}
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	var compatible, incompatible []string
+	for model, schema := range models {
+		var invalid []string
+		for attribute, value := range attributes {
+			if err := schema.ValidateSetting(attribute, value, schema.Spec.Schema); err != nil {
+				invalid = append(invalid, err.Error())
+			}
+		}
+		if len(invalid) == 0 {
+			compatible = append(compatible, model)
+			continue
+		}
+		sort.Strings(invalid)
+		incompatible = append(incompatible, fmt.Sprintf("%s (%s)", model, strings.Join(invalid, "; ")))
+	}
+
+	if len(compatible) == 0 {
+		sort.Strings(incompatible)
+		return nil, fmt.Errorf("BIOS attributes are not compatible with any known hardware model: %s",
+			strings.Join(incompatible, ", "))
+	}
+
+	sort.Strings(compatible)
+	return compatible, nil
+}
+
+// checkFirmwareURLs checks that the firmware images and signatures of a profile served over http or https can be
+// reached from the plugin
+func (r *HardwareProfileReconciler) checkFirmwareURLs(ctx context.Context, spec pluginv1alpha1.HardwareProfileSpec) error {
+	for _, fw := range []pluginv1alpha1.Firmware{spec.BiosFirmware, spec.BmcFirmware} {
+		urls := []string{fw.URL}
+		if fw.Signature != nil {
+			urls = append(urls, fw.Signature.URL)
+		}
+		for _, rawURL := range urls {
+			if err := r.checkURLReachable(ctx, rawURL); err != nil {
+				return fmt.Errorf("firmware %s is not reachable: %w", rawURL, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkURLReachable checks a firmware URL with a HEAD request, falling back to a GET request for servers that do not
+// support HEAD requests. URLs with a scheme other than http or https are not checked.
+func (r *HardwareProfileReconciler) checkURLReachable(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, firmwareReachabilityTimeout)
+	defer cancel()
+
+	status, err := r.requestStatus(ctx, http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = r.requestStatus(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("unexpected response: %d %s", status, http.StatusText(status))
+	}
+	return nil
+}
+
+func (r *HardwareProfileReconciler) requestStatus(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// getProfileUsage returns the Nodes using a profile and the InUse condition of the profile. A profile is used by a
+// Node or NodePool that references it, or a profile built on it, either directly or through a hardware profile
+// override of a Node.
+func (r *HardwareProfileReconciler) getProfileUsage(ctx context.Context, name string) ([]string, metav1.Condition, error) {
+	condition := metav1.Condition{Type: string(pluginv1alpha1.ConditionTypes.InUse)}
+
+	profiles, err := utils.GetDerivedHardwareProfiles(ctx, r.Client, r.Namespace, name)
+	if err != nil {
+		return nil, condition, err
+	}
+
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	if err := r.Client.List(ctx, nodelist, client.InNamespace(r.Namespace)); err != nil {
+		return nil, condition, fmt.Errorf("failed to list Nodes: %w", err)
+	}
+	var nodes []string
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if profiles[node.Spec.HwProfile] || profiles[utils.GetNodeHwProfileOverride(node)] {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	sort.Strings(nodes)
+
+	nodepoolList := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, nodepoolList, client.InNamespace(r.Namespace)); err != nil {
+		return nil, condition, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+	var nodepools []string
+	for _, nodepool := range nodepoolList.Items {
+		if slices.ContainsFunc(nodepool.Spec.NodeGroup, func(group hwmgmtv1alpha1.NodeGroup) bool {
+			return profiles[group.NodePoolData.HwProfile]
+		}) {
+			nodepools = append(nodepools, nodepool.Name)
+		}
+	}
+	sort.Strings(nodepools)
+
+	if len(nodes) == 0 && len(nodepools) == 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(pluginv1alpha1.ConditionReasons.Unreferenced)
+		condition.Message = "Not used by any Node or NodePool"
+		return nil, condition, nil
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = string(pluginv1alpha1.ConditionReasons.Referenced)
+	condition.Message = fmt.Sprintf("Used by %d Nodes", len(nodes))
+	if len(nodepools) > 0 {
+		condition.Message += " and NodePools: " + strings.Join(nodepools, ", ")
+	}
+	return nodes, condition, nil
+}
+
+func (r *HardwareProfileReconciler) updateProfileStatus(ctx context.Context, name string, generation int64,
+	nodes []string, conditions ...metav1.Condition) error {
+
+	// nolint: wrapcheck
+	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		profile := &pluginv1alpha1.HardwareProfile{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: r.Namespace}, profile); err != nil {
+			return fmt.Errorf("failed to get HardwareProfile %s: %w", name, err)
+		}
+		if profile.Generation != generation {
+			// The profile changed while it was validated, and is validated again for its new generation
+			return nil
+		}
+
+		changed := profile.Status.ObservedGeneration != generation || !slices.Equal(profile.Status.Nodes, nodes)
+		for _, condition := range conditions {
+			if meta.SetStatusCondition(&profile.Status.Conditions, condition) {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		profile.Status.ObservedGeneration = generation
+		profile.Status.Nodes = nodes
+		return r.Client.Status().Update(ctx, profile)
+	})
+}
+
+// profileWithBases returns the requests for a profile and the chain of base profiles it is built on, as each is used
+// wherever the profile is used
+func (r *HardwareProfileReconciler) profileWithBases(ctx context.Context, name string) []reconcile.Request {
+	var requests []reconcile.Request
+	for next := name; next != ""; {
+		if slices.ContainsFunc(requests, func(req reconcile.Request) bool { return req.Name == next }) {
+			// A cycle in the base profiles is reported in the Validated condition
+			break
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: next, Namespace: r.Namespace}})
+
+		profile := &pluginv1alpha1.HardwareProfile{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: next, Namespace: r.Namespace}, profile); err != nil {
+			break
+		}
+		next = profile.Spec.BaseProfile
+	}
+	return requests
+}
+
+// usageHandler enqueues the profiles referenced by a Node or NodePool, before and after an update, so that the
+// profile it stops using is updated as well as the profile it starts using
+func (r *HardwareProfileReconciler) usageHandler(referencedProfiles func(client.Object) []string) handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objects ...client.Object) {
+		for _, object := range objects {
+			if object == nil || object.GetNamespace() != r.Namespace {
+				continue
+			}
+			for _, name := range referencedProfiles(object) {
+				for _, req := range r.profileWithBases(ctx, name) {
+					q.Add(req)
+				}
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			if !slices.Equal(referencedProfiles(e.ObjectOld), referencedProfiles(e.ObjectNew)) {
+				enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+			}
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HardwareProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	// A change to a profile changes the resolved spec of each profile built on it, and the usage of its bases
+	profileToRelated := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, object client.Object) []reconcile.Request {
+		requests := r.profileWithBases(ctx, object.GetName())
+		profiles, err := utils.GetDerivedHardwareProfiles(ctx, r.Client, r.Namespace, object.GetName())
+		if err != nil {
+			r.Logger.ErrorContext(ctx, "Failed to find profiles derived from HardwareProfile",
+				slog.String("hwProfile", object.GetName()), slog.String("error", err.Error()))
+			return requests
+		}
+		for name := range profiles {
+			if name != object.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: name, Namespace: r.Namespace}})
+			}
+		}
+		return requests
+	})
+
+	nodeProfiles := func(object client.Object) []string {
+		node, ok := object.(*hwmgmtv1alpha1.Node)
+		if !ok {
+			return nil
+		}
+		return []string{node.Spec.HwProfile, utils.GetNodeHwProfileOverride(node)}
+	}
+
+	nodepoolProfiles := func(object client.Object) []string {
+		nodepool, ok := object.(*hwmgmtv1alpha1.NodePool)
+		if !ok {
+			return nil
+		}
+		var profiles []string
+		for _, group := range nodepool.Spec.NodeGroup {
+			profiles = append(profiles, group.NodePoolData.HwProfile)
+		}
+		return profiles
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("hardware-profile").
+		Watches(&pluginv1alpha1.HardwareProfile{}, profileToRelated,
+			builder.WithPredicates(inNamespace, predicate.GenerationChangedPredicate{})).
+		Watches(&hwmgmtv1alpha1.Node{}, r.usageHandler(nodeProfiles)).
+		Watches(&hwmgmtv1alpha1.NodePool{}, r.usageHandler(nodepoolProfiles)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create hardware profile controller: %w", err)
+	}
+
+	return nil
+}
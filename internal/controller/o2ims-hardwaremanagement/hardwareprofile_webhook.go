@@ -0,0 +1,107 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// maxReportedProfileNodes limits the number of Nodes named in the rejection of a HardwareProfile deletion
+const maxReportedProfileNodes = 10
+
+// HardwareProfileValidator rejects the deletion of a HardwareProfile while it is used by a Node, either directly or as
+// the base of the profile of a Node. The profile of a Node is resolved again whenever the Node is updated, which would
+// otherwise fail part way through the update.
+type HardwareProfileValidator struct {
+	Client    client.Reader
+	Logger    *slog.Logger
+	Namespace string
+}
+
+//+kubebuilder:webhook:path=/validate-hwmgr-plugin-oran-openshift-io-v1alpha1-hardwareprofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=hwmgr-plugin.oran.openshift.io,resources=hardwareprofiles,verbs=delete,versions=v1alpha1,name=vhardwareprofile.hwmgr-plugin.oran.openshift.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &HardwareProfileValidator{}
+
+// ValidateCreate allows all HardwareProfile creations
+func (v *HardwareProfileValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate allows all HardwareProfile updates
+func (v *HardwareProfileValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete rejects the deletion of a HardwareProfile used by any Node
+func (v *HardwareProfileValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	profile, ok := obj.(*pluginv1alpha1.HardwareProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a HardwareProfile but got %T", obj)
+	}
+
+	if profile.Namespace != v.Namespace {
+		return nil, nil
+	}
+
+	profiles, err := utils.GetDerivedHardwareProfiles(ctx, v.Client, v.Namespace, profile.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find profiles derived from HardwareProfile %s: %w", profile.Name, err)
+	}
+
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	if err := v.Client.List(ctx, nodelist, client.InNamespace(v.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Nodes: %w", err)
+	}
+
+	var nodes []string
+	for _, node := range nodelist.Items {
+		if profiles[node.Spec.HwProfile] {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(nodes)
+	v.Logger.InfoContext(ctx, "Rejected deletion of HardwareProfile in use",
+		slog.String("hwProfile", profile.Name), slog.Int("nodes", len(nodes)))
+
+	reported := nodes
+	if len(reported) > maxReportedProfileNodes {
+		reported = append(reported[:maxReportedProfileNodes:maxReportedProfileNodes],
+			fmt.Sprintf("and %d more", len(nodes)-maxReportedProfileNodes))
+	}
+	return nil, apierrors.NewForbidden(pluginv1alpha1.GroupVersion.WithResource("hardwareprofiles").GroupResource(),
+		profile.Name, fmt.Errorf("HardwareProfile is in use by Nodes: %s", strings.Join(reported, ", ")))
+}
+
+// SetupWebhookWithManager registers the validating webhook for HardwareProfiles with the Manager.
+func (v *HardwareProfileValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&pluginv1alpha1.HardwareProfile{}).
+		WithValidator(v).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to create HardwareProfile webhook: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,341 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	bmhv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// orphanGracePeriod is the minimum age of a Node or bmc-secret before it is considered for cleanup, to avoid
+	// racing with an allocation that is still in progress
+	orphanGracePeriod = 10 * time.Minute
+
+	// Event reasons
+	OrphanedNodeDeleted   = "OrphanedNodeDeleted"
+	OrphanedSecretDeleted = "OrphanedSecretDeleted"
+	MissingBareMetalHost  = "MissingBareMetalHost"
+)
+
+// NodeCleanupReconciler periodically checks Node CRs and bmc-secrets, deleting Nodes whose NodePool or BareMetalHost
+// no longer exists, and bmc-secrets no longer used by any node
+type NodeCleanupReconciler struct {
+	client.Client
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+	Recorder     record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
+
+// Reconcile checks the Node in the request, deleting it and its bmc-secret if orphaned. The request is requeued while
+// the Node exists, so that it is found to be orphaned even if no further events are received.
+func (r *NodeCleanupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("nodename", req.Name))
+
+	node := &hwmgmtv1alpha1.Node{}
+	if err := r.Client.Get(ctx, req.NamespacedName, node); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get node %s: %w", req.Name, err)
+	}
+
+	if node.DeletionTimestamp != nil || time.Since(node.CreationTimestamp.Time) < orphanGracePeriod {
+		return utils.RequeueWithLongInterval(), nil
+	}
+
+	orphaned, reason, err := r.isNodeOrphaned(ctx, node)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+	if !orphaned {
+		return utils.RequeueWithLongInterval(), nil
+	}
+
+	r.Logger.InfoContext(ctx, "Deleting orphaned node", slog.String("reason", reason))
+	if err := r.Client.Delete(ctx, node); client.IgnoreNotFound(err) != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to delete orphaned node %s: %w", node.Name, err)
+	}
+	r.Recorder.Eventf(node, corev1.EventTypeNormal, OrphanedNodeDeleted, "Deleted orphaned node: %s", reason)
+
+	// The bmc-secret of the node is deleted with it, rather than waiting for it to be found orphaned
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, utils.GetNodeBmcSecret(node), secret); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get bmc-secret of node %s: %w", node.Name, err)
+	}
+	if r.isBmcSecret(secret) && secret.DeletionTimestamp == nil {
+		if err := r.deleteBmcSecret(ctx, secret, fmt.Sprintf("Deleted bmc-secret of orphaned node %s", node.Name)); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// reconcileBmcSecret checks the bmc-secret in the request, deleting it if no node uses it. The request is requeued
+// while the secret exists, so that it is found to be orphaned once its node is deleted.
+func (r *NodeCleanupReconciler) reconcileBmcSecret(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("secret", req.String()))
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, req.NamespacedName, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get secret %s: %w", req, err)
+	}
+
+	if !r.isBmcSecret(secret) || secret.DeletionTimestamp != nil {
+		return utils.DoNotRequeue(), nil
+	}
+	if time.Since(secret.CreationTimestamp.Time) < orphanGracePeriod {
+		// The node may not have been created yet
+		return utils.RequeueWithLongInterval(), nil
+	}
+
+	inUse, err := r.isBmcSecretInUse(ctx, secret)
+	if err != nil {
+		return utils.RequeueWithMediumInterval(), err
+	}
+	if inUse {
+		return utils.RequeueWithLongInterval(), nil
+	}
+
+	r.Logger.InfoContext(ctx, "Deleting orphaned bmc-secret")
+	if err := r.deleteBmcSecret(ctx, secret, "Deleted bmc-secret not used by any node"); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	return utils.DoNotRequeue(), nil
+}
+
+// isBmcSecret checks whether a secret is a bmc-secret created by an adaptor. A bmc-secret is labeled with the NodePool
+// it was created for, in whichever namespace the NodePool placed it, but one created before the label was introduced is
+// only known by its name. As the BMC secrets of BareMetalHosts are commonly given the same suffix, an unlabeled secret
+// is only matched in the plugin namespace, and where it was created for a NodePool.
+func (r *NodeCleanupReconciler) isBmcSecret(secret client.Object) bool {
+	if _, exists := secret.GetLabels()[utils.BmcSecretNodePoolLabel]; exists {
+		return true
+	}
+	return secret.GetNamespace() == r.Namespace && strings.HasSuffix(secret.GetName(), utils.BmcSecretSuffix) &&
+		isNodePoolOwned(secret)
+}
+
+// isBmcSecretInUse checks whether a bmc-secret is the bmc-secret of an existing Node, or of a node still listed by its
+// NodePool, which is then removed through the NodePool. The location of the bmc-secret of a node depends on the NodePool
+// extensions and secret backend, so it is compared with the location recorded for each node rather than derived from
+// the secret name.
+func (r *NodeCleanupReconciler) isBmcSecretInUse(ctx context.Context, secret *corev1.Secret) (bool, error) {
+	location := client.ObjectKeyFromObject(secret)
+
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	if err := r.Client.List(ctx, nodes, client.InNamespace(r.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for i := range nodes.Items {
+		if utils.GetNodeBmcSecret(&nodes.Items[i]) == location {
+			return true, nil
+		}
+	}
+
+	nodepoolUID, labeled := secret.Labels[utils.BmcSecretNodePoolLabel]
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, nodepools, client.InNamespace(r.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list nodepools: %w", err)
+	}
+	for i := range nodepools.Items {
+		nodepool := &nodepools.Items[i]
+		if labeled && string(nodepool.UID) != nodepoolUID {
+			continue
+		}
+		for _, nodename := range nodepool.Status.Properties.NodeNames {
+			nodeLocation, err := utils.GetBmcSecretLocation(nodepool, nodename)
+			if err != nil {
+				// The secret can't be matched to a node of a NodePool with invalid extensions, so it is kept
+				return true, nil
+			}
+			if nodeLocation == location {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// deleteBmcSecret deletes a bmc-secret, along with any ExternalSecret populating it
+func (r *NodeCleanupReconciler) deleteBmcSecret(ctx context.Context, secret *corev1.Secret, message string) error {
+	location := client.ObjectKeyFromObject(secret)
+	if nodepoolUID, exists := secret.Labels[utils.BmcSecretNodePoolLabel]; exists {
+		if err := utils.DeleteBmcSecret(ctx, r.Client, types.UID(nodepoolUID), location); err != nil {
+			return fmt.Errorf("failed to delete orphaned secret %s: %w", location, err)
+		}
+	} else if err := r.Client.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete orphaned secret %s: %w", location, err)
+	}
+	r.Recorder.Event(secret, corev1.EventTypeNormal, OrphanedSecretDeleted, message)
+	return nil
+}
+
+// isNodeOrphaned checks whether the NodePool that owns a Node, or the BareMetalHost backing it, no longer exists. A Node
+// still listed by its NodePool is removed through the NodePool instead, so that the NodePool status is updated with it.
+func (r *NodeCleanupReconciler) isNodeOrphaned(ctx context.Context, node *hwmgmtv1alpha1.Node) (bool, string, error) {
+	nodepool, err := r.getNodePool(ctx, node)
+	if err != nil {
+		return false, "", err
+	}
+	if nodepool == nil {
+		return true, fmt.Sprintf("nodepool %s no longer exists", node.Spec.NodePool), nil
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			// Without the HardwareManager, the backing resource can't be checked
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+
+	// BareMetalHosts are not read if the metal3 adaptor is disabled, so that they are not cached
+	if hwmgr.Spec.AdaptorID == pluginv1alpha1.SupportedAdaptors.Metal3 && node.Spec.HwMgrNodeId != "" &&
+		r.HwMgrAdaptor.IsAdaptorEnabled(string(pluginv1alpha1.SupportedAdaptors.Metal3)) {
+		return r.isBareMetalHostMissing(ctx, node, nodepool)
+	}
+
+	return false, "", nil
+}
+
+// isBareMetalHostMissing checks whether the BareMetalHost backing a metal3 Node no longer exists, where the Node is not
+// part of its NodePool. A Node still listed by its NodePool is left for removal through the NodePool, with a warning.
+func (r *NodeCleanupReconciler) isBareMetalHostMissing(ctx context.Context, node *hwmgmtv1alpha1.Node,
+	nodepool *hwmgmtv1alpha1.NodePool) (bool, string, error) {
+
+	bmh := &bmhv1alpha1.BareMetalHost{}
+	bmhName := types.NamespacedName{Name: node.Spec.HwMgrNodeId, Namespace: node.Spec.HwMgrNodeNs}
+	if err := r.Client.Get(ctx, bmhName, bmh); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, "", fmt.Errorf("failed to get BareMetalHost %s: %w", bmhName, err)
+		}
+		if slices.Contains(nodepool.Status.Properties.NodeNames, node.Name) {
+			r.Recorder.Eventf(node, corev1.EventTypeWarning, MissingBareMetalHost,
+				"BareMetalHost %s no longer exists, remove the node with the %s annotation of nodepool %s",
+				bmhName, utils.NodePoolRemoveNodesAnnotation, nodepool.Name)
+			return false, "", nil
+		}
+		return true, fmt.Sprintf("BareMetalHost %s no longer exists", bmhName), nil
+	}
+
+	return false, "", nil
+}
+
+// getNodePool returns the NodePool owning a Node, or nil if it no longer exists, falling back to a lookup by
+// spec.nodePool if the Node has no owner reference. The adaptors differ in whether spec.nodePool holds the name or the
+// cloudID of the NodePool, so either is matched.
+func (r *NodeCleanupReconciler) getNodePool(ctx context.Context, node *hwmgmtv1alpha1.Node) (*hwmgmtv1alpha1.NodePool, error) {
+	owned := false
+	for _, ref := range node.OwnerReferences {
+		if ref.Kind != "NodePool" {
+			continue
+		}
+		owned = true
+
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: node.Namespace}, nodepool); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get nodepool %s: %w", ref.Name, err)
+		}
+		if nodepool.UID == ref.UID {
+			return nodepool, nil
+		}
+	}
+
+	if owned {
+		return nil, nil
+	}
+
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := r.Client.List(ctx, nodepools, client.InNamespace(node.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list nodepools: %w", err)
+	}
+	for i := range nodepools.Items {
+		if nodepools.Items[i].Name == node.Spec.NodePool || nodepools.Items[i].Spec.CloudID == node.Spec.NodePool {
+			return &nodepools.Items[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// isNodePoolOwned checks whether an object has a NodePool owner reference
+func isNodePoolOwned(object client.Object) bool {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.Kind == "NodePool" {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controllers with the Manager. Nodes and bmc-secrets are checked by separate
+// controllers, as the bmc-secret of a node may have any name and namespace, as configured by its NodePool.
+func (r *NodeCleanupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("node-cleanup").
+		For(&hwmgmtv1alpha1.Node{}, builder.WithPredicates(inNamespace)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create node cleanup controller: %w", err)
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("bmc-secret-cleanup").
+		For(&corev1.Secret{}, builder.WithPredicates(predicate.NewPredicateFuncs(r.isBmcSecret))).
+		Complete(reconcile.Func(r.reconcileBmcSecret)); err != nil {
+		return fmt.Errorf("failed to create bmc-secret cleanup controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	bmhv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "oran-hwmgr-plugin"
+
+func newTestNodeCleanupReconciler(t *testing.T, objects ...client.Object) *NodeCleanupReconciler {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := hwmgmtv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := bmhv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return &NodeCleanupReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Logger:    slog.Default(),
+		Namespace: testNamespace,
+		Recorder:  record.NewFakeRecorder(10),
+	}
+}
+
+// expired returns a creation timestamp outside the orphan grace period
+func expired() metav1.Time {
+	return metav1.NewTime(time.Now().Add(-2 * orphanGracePeriod))
+}
+
+func TestGetNodePoolWithoutOwnerReference(t *testing.T) {
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: testNamespace},
+		Spec:       hwmgmtv1alpha1.NodePoolSpec{CloudID: "cloud-1"},
+	}
+	r := newTestNodeCleanupReconciler(t, nodepool)
+
+	tests := []struct {
+		description string
+		nodePool    string
+		exists      bool
+	}{
+		// The dell-hwmgr adaptor records the NodePool name
+		{description: "name", nodePool: "np1", exists: true},
+		{description: "cloudID", nodePool: "cloud-1", exists: true},
+		{description: "deleted nodepool", nodePool: "np2", exists: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			node := &hwmgmtv1alpha1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: testNamespace},
+				Spec:       hwmgmtv1alpha1.NodeSpec{NodePool: test.nodePool},
+			}
+			found, err := r.getNodePool(context.Background(), node)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (found != nil) != test.exists {
+				t.Errorf("expected nodepool %s to exist: %t", test.nodePool, test.exists)
+			}
+		})
+	}
+}
+
+func TestIsBareMetalHostMissing(t *testing.T) {
+	tests := []struct {
+		description string
+		withBMH     bool
+		// listed is whether the NodePool lists the node
+		listed   bool
+		orphaned bool
+	}{
+		{description: "BMH exists", withBMH: true, listed: true},
+		{description: "BMH missing for a node of the nodepool", listed: true},
+		{description: "BMH missing for a node not in the nodepool", orphaned: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			nodepool := &hwmgmtv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: testNamespace}}
+			if test.listed {
+				nodepool.Status.Properties.NodeNames = []string{"node1"}
+			}
+			node := &hwmgmtv1alpha1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: testNamespace},
+				Spec:       hwmgmtv1alpha1.NodeSpec{NodePool: "np1", HwMgrNodeId: "bmh1", HwMgrNodeNs: "bmh-ns"},
+			}
+			objects := []client.Object{nodepool, node}
+			if test.withBMH {
+				objects = append(objects, &bmhv1alpha1.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "bmh1", Namespace: "bmh-ns"}})
+			}
+			r := newTestNodeCleanupReconciler(t, objects...)
+
+			orphaned, _, err := r.isBareMetalHostMissing(context.Background(), node, nodepool)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if orphaned != test.orphaned {
+				t.Errorf("expected the node to be orphaned: %t", test.orphaned)
+			}
+		})
+	}
+}
+
+func TestReconcileBmcSecret(t *testing.T) {
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: testNamespace, UID: "np1-uid"},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID: "cloud1",
+			Extensions: map[string]string{
+				utils.BmcSecretNameTemplateExtension: "{{ .CloudID }}-{{ .NodeName }}",
+				utils.BmcSecretNamespaceExtension:    "cloud1",
+			},
+		},
+		Status: hwmgmtv1alpha1.NodePoolStatus{
+			Properties: hwmgmtv1alpha1.Properties{NodeNames: []string{"node2"}},
+		},
+	}
+	node := &hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node1",
+			Namespace:   testNamespace,
+			Annotations: map[string]string{utils.BmcSecretNamespaceAnnotation: "cloud1"},
+		},
+		Status: hwmgmtv1alpha1.NodeStatus{BMC: &hwmgmtv1alpha1.BMC{CredentialsName: "cloud1-node1"}},
+	}
+	labels := map[string]string{utils.BmcSecretNodePoolLabel: "np1-uid"}
+
+	tests := []struct {
+		description string
+		secret      *corev1.Secret
+		deleted     bool
+	}{
+		{
+			description: "secret of an existing node at a templated location",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "cloud1-node1", Namespace: "cloud1", Labels: labels, CreationTimestamp: expired()}},
+		},
+		{
+			description: "secret of a deleted node still listed by its nodepool",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "cloud1-node2", Namespace: "cloud1", Labels: labels, CreationTimestamp: expired()}},
+		},
+		{
+			description: "secret without an owner reference whose node is gone",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "cloud1-node3", Namespace: "cloud1", Labels: labels, CreationTimestamp: expired()}},
+			deleted: true,
+		},
+		{
+			description: "secret of a deleted nodepool",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:              "node4-bmc-secret",
+				Namespace:         testNamespace,
+				Labels:            map[string]string{utils.BmcSecretNodePoolLabel: "np2-uid"},
+				CreationTimestamp: expired()}},
+			deleted: true,
+		},
+		{
+			description: "secret within the grace period",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "cloud1-node5", Namespace: "cloud1", Labels: labels, CreationTimestamp: metav1.Now()}},
+		},
+		{
+			description: "unlabeled BMC secret of a BareMetalHost",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "bmh1-bmc-secret", Namespace: testNamespace, CreationTimestamp: expired()}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			r := newTestNodeCleanupReconciler(t, nodepool, node, test.secret)
+
+			if _, err := r.reconcileBmcSecret(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(test.secret)}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err := r.Client.Get(ctx, client.ObjectKeyFromObject(test.secret), &corev1.Secret{})
+			if deleted := errors.IsNotFound(err); deleted != test.deleted {
+				t.Errorf("expected the secret to be deleted: %t, got %v", test.deleted, err)
+			}
+		})
+	}
+}
+
+func TestReconcileOrphanedNodeDeletesBmcSecret(t *testing.T) {
+	ctx := context.Background()
+	node := &hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "node1",
+			Namespace:         testNamespace,
+			CreationTimestamp: expired(),
+			Annotations:       map[string]string{utils.BmcSecretNamespaceAnnotation: "cloud1"},
+		},
+		Spec:   hwmgmtv1alpha1.NodeSpec{NodePool: "np1"},
+		Status: hwmgmtv1alpha1.NodeStatus{BMC: &hwmgmtv1alpha1.BMC{CredentialsName: "cloud1-node1"}},
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "cloud1-node1",
+		Namespace: "cloud1",
+		Labels:    map[string]string{utils.BmcSecretNodePoolLabel: "np1-uid"},
+	}}
+	r := newTestNodeCleanupReconciler(t, node, secret)
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(node)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(node), &hwmgmtv1alpha1.Node{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the orphaned node to be deleted, got %v", err)
+	}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the bmc-secret of the orphaned node to be deleted, got %v", err)
+	}
+}
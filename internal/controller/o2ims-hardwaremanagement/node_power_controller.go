@@ -0,0 +1,177 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// Event reasons
+const (
+	PowerActionCompleted = "PowerActionCompleted"
+	PowerActionFailed    = "PowerActionFailed"
+)
+
+// NodePowerReconciler applies the power action requested through an annotation on an allocated Node, through the
+// adaptor of its HardwareManager. The annotation is removed once the action completes, with the state of the action
+// reported in a Node condition.
+type NodePowerReconciler struct {
+	client.Client
+	Logger       *slog.Logger
+	Namespace    string
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+	Recorder     record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hwmgr-plugin.oran.openshift.io,resources=hardwaremanagers,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;update
+
+// Reconcile applies the power action requested on the node in the request, if any
+func (r *NodePowerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	ctx = logging.AppendCtx(ctx, slog.String("nodename", req.Name))
+
+	node := &hwmgmtv1alpha1.Node{}
+	if err := r.Client.Get(ctx, req.NamespacedName, node); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get node %s: %w", req.Name, err)
+	}
+
+	action := node.Annotations[utils.NodePowerActionAnnotation]
+	if action == "" || node.DeletionTimestamp != nil {
+		return utils.DoNotRequeue(), nil
+	}
+
+	// Power actions only apply once the node has been allocated. A change in the node status retriggers this.
+	if !meta.IsStatusConditionTrue(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned)) {
+		return utils.DoNotRequeue(), nil
+	}
+
+	ctx = logging.AppendCtx(ctx, slog.String("action", action))
+
+	if !utils.IsValidPowerAction(action) {
+		// A change to the annotation retriggers this
+		return utils.DoNotRequeue(), r.setPowerActionCondition(ctx, node, metav1.ConditionFalse,
+			pluginv1alpha1.ConditionReasons.Failed,
+			fmt.Sprintf("Unsupported power action %q, expected one of %v", action, utils.PowerActions))
+	}
+
+	if utils.GetConfigAnnotation(node) != "" {
+		// Wait for a hardware configuration update on the node to finish
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: r.Namespace}, hwmgr); err != nil {
+		if errors.IsNotFound(err) {
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+
+	// A failed action is retried without resetting the condition, so the failure remains visible until resolved
+	condition := meta.FindStatusCondition(node.Status.Conditions, string(pluginv1alpha1.ConditionTypes.PowerAction))
+	if condition == nil || condition.Reason != string(pluginv1alpha1.ConditionReasons.Failed) {
+		if err := r.setPowerActionCondition(ctx, node, metav1.ConditionFalse, pluginv1alpha1.ConditionReasons.InProgress,
+			fmt.Sprintf("Applying power action %s", action)); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+	}
+
+	completed, err := r.HwMgrAdaptor.HandleNodePowerAction(ctx, hwmgr, node, action)
+	if err != nil {
+		r.Logger.ErrorContext(ctx, "Power action failed", slog.String("error", err.Error()))
+		r.Recorder.Eventf(node, corev1.EventTypeWarning, PowerActionFailed, "Failed to apply power action %s: %s", action, err.Error())
+		if err := r.setPowerActionCondition(ctx, node, metav1.ConditionFalse,
+			pluginv1alpha1.ConditionReasons.Failed, err.Error()); err != nil {
+			return utils.RequeueWithShortInterval(), err
+		}
+		if typederrors.IsInputError(err) {
+			// Retrying will not help. A change to the annotation retriggers this.
+			return utils.DoNotRequeue(), nil
+		}
+		return utils.RequeueWithMediumInterval(), nil
+	}
+
+	if !completed {
+		return utils.RequeueWithShortInterval(), nil
+	}
+
+	if err := utils.SetNodeAnnotation(ctx, r.Client, node, utils.NodePowerActionAnnotation, ""); err != nil {
+		return utils.RequeueWithShortInterval(), fmt.Errorf("failed to clear power action on node %s: %w", node.Name, err)
+	}
+
+	if err := r.setPowerActionCondition(ctx, node, metav1.ConditionTrue, pluginv1alpha1.ConditionReasons.Completed,
+		fmt.Sprintf("Power action %s completed", action)); err != nil {
+		return utils.RequeueWithShortInterval(), err
+	}
+
+	r.Logger.InfoContext(ctx, "Power action completed")
+	r.Recorder.Eventf(node, corev1.EventTypeNormal, PowerActionCompleted, "Applied power action %s", action)
+
+	return utils.DoNotRequeue(), nil
+}
+
+// setPowerActionCondition sets the power action condition on a node, if it has changed
+func (r *NodePowerReconciler) setPowerActionCondition(ctx context.Context, node *hwmgmtv1alpha1.Node,
+	status metav1.ConditionStatus, reason pluginv1alpha1.ConditionReason, message string) error {
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.PowerAction)
+	condition := meta.FindStatusCondition(node.Status.Conditions, conditionType)
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) && utils.ConditionMessage(condition) == message {
+		return nil
+	}
+
+	if err := utils.SetNodeConditionStatus(ctx, r.Client, node.Name, node.Namespace,
+		conditionType, status, string(reason), message); err != nil {
+		return fmt.Errorf("failed to set power action condition on node %s: %w", node.Name, err)
+	}
+
+	utils.SetStatusCondition(ctx, &node.Status.Conditions, conditionType, string(reason), status, message)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodePowerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	inNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetNamespace() == r.Namespace
+	})
+
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("node-power").
+		For(&hwmgmtv1alpha1.Node{}, builder.WithPredicates(inNamespace)).
+		Complete(r); err != nil {
+		return fmt.Errorf("failed to create node power controller: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodeValidator rejects changes to the plugin-owned spec fields of the Nodes managed by the plugin, unless made by the
+// plugin itself. These fields drive the reconciliation of the Node, and editing them by hand leaves the Node out of
+// step with the hardware backing it.
+type NodeValidator struct {
+	Logger    *slog.Logger
+	Namespace string
+	// ServiceAccount is the username of the plugin service account, as system:serviceaccount:<namespace>:<name>
+	ServiceAccount string
+}
+
+//+kubebuilder:webhook:path=/validate-o2ims-hardwaremanagement-oran-openshift-io-v1alpha1-node,mutating=false,failurePolicy=fail,sideEffects=None,groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes,verbs=update,versions=v1alpha1,name=vnode.hwmgr-plugin.oran.openshift.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &NodeValidator{}
+
+// ServiceAccountUsername returns the username the API server authenticates a service account as
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// ValidateCreate allows all Node creations
+func (v *NodeValidator) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate rejects changes to the plugin-owned spec fields of a Node by anyone other than the plugin
+func (v *NodeValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNode, ok := oldObj.(*hwmgmtv1alpha1.Node)
+	if !ok {
+		return nil, fmt.Errorf("expected a Node but got %T", oldObj)
+	}
+	newNode, ok := newObj.(*hwmgmtv1alpha1.Node)
+	if !ok {
+		return nil, fmt.Errorf("expected a Node but got %T", newObj)
+	}
+
+	if newNode.Namespace != v.Namespace {
+		return nil, nil
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admission request: %w", err)
+	}
+	if req.UserInfo.Username == v.ServiceAccount {
+		return nil, nil
+	}
+
+	specPath := field.NewPath("spec")
+	var errs field.ErrorList
+	for _, f := range []struct {
+		name     string
+		old, new string
+	}{
+		{"nodePool", oldNode.Spec.NodePool, newNode.Spec.NodePool},
+		{"groupName", oldNode.Spec.GroupName, newNode.Spec.GroupName},
+		{"hwProfile", oldNode.Spec.HwProfile, newNode.Spec.HwProfile},
+		{"hwMgrId", oldNode.Spec.HwMgrId, newNode.Spec.HwMgrId},
+		{"hwMgrNodeId", oldNode.Spec.HwMgrNodeId, newNode.Spec.HwMgrNodeId},
+		{"hwMgrNodeNs", oldNode.Spec.HwMgrNodeNs, newNode.Spec.HwMgrNodeNs},
+	} {
+		if f.old != f.new {
+			errs = append(errs, field.Forbidden(specPath.Child(f.name), "field is managed by the hardware manager plugin"))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil, nil
+	}
+
+	v.Logger.InfoContext(ctx, "Rejected change to managed Node fields",
+		slog.String("nodename", newNode.Name), slog.String("user", req.UserInfo.Username))
+	return nil, apierrors.NewInvalid(hwmgmtv1alpha1.GroupVersion.WithKind("Node").GroupKind(), newNode.Name, errs)
+}
+
+// ValidateDelete allows all Node deletions
+func (v *NodeValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// SetupWebhookWithManager registers the validating webhook for Nodes with the Manager.
+func (v *NodeValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&hwmgmtv1alpha1.Node{}).
+		WithValidator(v).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to create Node webhook: %w", err)
+	}
+
+	return nil
+}
@@ -14,13 +14,21 @@ import (
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	adaptors "github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 )
 
@@ -33,6 +41,7 @@ type NodePoolReconciler struct {
 	Logger          *slog.Logger
 	Namespace       string
 	HwMgrAdaptor    *adaptors.HwMgrAdaptorController
+	JobEvents       <-chan event.GenericEvent
 	indexerEnabled  bool
 }
 
@@ -46,6 +55,15 @@ func (r *NodePoolReconciler) SetupIndexer(ctx context.Context) error {
 		return fmt.Errorf("failed to setup node indexer: %w", err)
 	}
 
+	// Setup the job ID indexers, allowing job status callbacks from the hardware manager to be mapped to the NodePool
+	if err := r.Manager.GetFieldIndexer().IndexField(ctx, &hwmgmtv1alpha1.NodePool{}, utils.JobIdIndexKey, utils.GetJobIds); err != nil {
+		return fmt.Errorf("failed to setup nodepool job indexer: %w", err)
+	}
+
+	if err := r.Manager.GetFieldIndexer().IndexField(ctx, &hwmgmtv1alpha1.Node{}, utils.JobIdIndexKey, utils.GetJobIds); err != nil {
+		return fmt.Errorf("failed to setup node job indexer: %w", err)
+	}
+
 	return nil
 }
 
@@ -59,18 +77,25 @@ func (r *NodePoolReconciler) SetupIndexer(ctx context.Context) error {
 //+kubebuilder:rbac:groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodes/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;patch;watch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;create;update;patch;watch;delete
+//+kubebuilder:rbac:groups=external-secrets.io,resources=externalsecrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.3/pkg/reconcile
-func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
 
 	// Add logging context with the nodepool name
 	ctx = logging.AppendCtx(ctx, slog.String("nodepool", req.Name))
 
+	ctx, span := tracing.StartSpan(ctx, "Reconcile NodePool",
+		tracing.AttrNodePool.String(req.Namespace+"/"+req.Name))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	if !r.indexerEnabled {
 		if err := r.SetupIndexer(ctx); err != nil {
 			return utils.DoNotRequeue(), fmt.Errorf("failed to setup indexer: %w", err)
@@ -124,7 +149,16 @@ func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	// Hand off the CR to the adaptor
-	result, err := r.HwMgrAdaptor.HandleNodePool(ctx, nodepool)
+	result, err = r.HwMgrAdaptor.HandleNodePool(ctx, nodepool)
+
+	// Publish the progress of each node, so consumers don't need to correlate the child Node CRs themselves
+	statusCtx, statusSpan := tracing.StartSpan(ctx, "Update NodePool node status")
+	statusErr := utils.UpdateNodePoolNodeStatus(statusCtx, r.Client, r.Logger, nodepool)
+	if statusErr != nil {
+		r.Logger.WarnContext(ctx, "Unable to update node status table", slog.String("error", statusErr.Error()))
+	}
+	tracing.EndSpan(statusSpan, statusErr)
+
 	if err != nil {
 		return result, fmt.Errorf("failed HandleNodePool: %w", err)
 	}
@@ -132,11 +166,85 @@ func (r *NodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	return result, nil
 }
 
+// isDeletedNodeRemovalRequested checks whether a deleted Node is still a member of a NodePool that owns it, such that
+// its deletion is a request to remove it from the NodePool
+func (r *NodePoolReconciler) isDeletedNodeRemovalRequested(object client.Object) bool {
+	for _, ref := range object.GetOwnerReferences() {
+		if ref.Kind != "NodePool" {
+			continue
+		}
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := r.Client.Get(context.Background(), types.NamespacedName{Name: ref.Name, Namespace: object.GetNamespace()},
+			nodepool); err != nil {
+			continue
+		}
+		if utils.IsNodeRemovalRequested(nodepool, object.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *NodePoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	if err := ctrl.NewControllerManagedBy(mgr).
+	// Map a Node marked for replacement, whose cordon or hardware profile override has changed, or that was deleted, to
+	// the NodePool that owns it
+	nodeToNodePool := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+		node, ok := object.(*hwmgmtv1alpha1.Node)
+		if !ok {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, ref := range node.GetOwnerReferences() {
+			if ref.Kind == "NodePool" {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Name:      ref.Name,
+					Namespace: node.Namespace,
+				}})
+			}
+		}
+		return requests
+	})
+
+	isReplacementRequested := func(object client.Object) bool {
+		node, ok := object.(*hwmgmtv1alpha1.Node)
+		return ok && utils.IsNodeReplacementRequested(node)
+	}
+	isCordonRequested := func(object client.Object) bool {
+		node, ok := object.(*hwmgmtv1alpha1.Node)
+		return ok && utils.IsNodeCordonRequested(node)
+	}
+	isHwProfileOverrideChanged := func(oldObject, newObject client.Object) bool {
+		return oldObject.GetAnnotations()[utils.NodeHwProfileOverrideAnnotation] !=
+			newObject.GetAnnotations()[utils.NodeHwProfileOverrideAnnotation]
+	}
+	nodeEvents := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isReplacementRequested(e.Object) || isCordonRequested(e.Object) || utils.IsReinspectionRequested(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isReplacementRequested(e.ObjectNew) || isCordonRequested(e.ObjectOld) != isCordonRequested(e.ObjectNew) ||
+				utils.IsReinspectionRequested(e.ObjectNew) || isHwProfileOverrideChanged(e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			// Deleting a Node removes it from its NodePool, unless it was deleted by the plugin or with the NodePool
+			return isReplacementRequested(e.Object) || r.isDeletedNodeRemovalRequested(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isReplacementRequested(e.Object)
+		},
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&hwmgmtv1alpha1.NodePool{}).
-		Complete(r); err != nil {
+		Watches(&hwmgmtv1alpha1.Node{}, nodeToNodePool, ctrlbuilder.WithPredicates(nodeEvents))
+
+	if r.JobEvents != nil {
+		// Reconcile immediately when the hardware manager reports progress on a NodePool job
+		builder = builder.WatchesRawSource(source.Channel(r.JobEvents, &handler.EnqueueRequestForObject{}))
+	}
+
+	if err := builder.Complete(r); err != nil {
 		return fmt.Errorf("failed to create controller: %w", err)
 	}
 
@@ -0,0 +1,111 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package o2imshardwaremanagement
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodePoolValidator rejects NodePools with a malformed or ambiguous resourceSelector, which would otherwise be accepted
+// and then select no hardware at all
+type NodePoolValidator struct {
+	Logger    *slog.Logger
+	Namespace string
+}
+
+//+kubebuilder:webhook:path=/validate-o2ims-hardwaremanagement-oran-openshift-io-v1alpha1-nodepool,mutating=false,failurePolicy=fail,sideEffects=None,groups=o2ims-hardwaremanagement.oran.openshift.io,resources=nodepools,verbs=create;update,versions=v1alpha1,name=vnodepool.hwmgr-plugin.oran.openshift.io,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &NodePoolValidator{}
+
+// ValidateCreate rejects a NodePool with an invalid resourceSelector in any node group
+func (v *NodePoolValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nodepool, ok := obj.(*hwmgmtv1alpha1.NodePool)
+	if !ok {
+		return nil, fmt.Errorf("expected a NodePool but got %T", obj)
+	}
+
+	return nil, v.validateResourceSelectors(ctx, nodepool, nil)
+}
+
+// ValidateUpdate rejects a change to the resourceSelector of a node group that leaves it invalid. Node groups whose
+// resourceSelector is unchanged are not checked, so that a NodePool accepted before the check existed can still be
+// updated, such as to remove its finalizer.
+func (v *NodePoolValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNodepool, ok := oldObj.(*hwmgmtv1alpha1.NodePool)
+	if !ok {
+		return nil, fmt.Errorf("expected a NodePool but got %T", oldObj)
+	}
+	newNodepool, ok := newObj.(*hwmgmtv1alpha1.NodePool)
+	if !ok {
+		return nil, fmt.Errorf("expected a NodePool but got %T", newObj)
+	}
+
+	return nil, v.validateResourceSelectors(ctx, newNodepool, oldNodepool)
+}
+
+// ValidateDelete allows all NodePool deletions
+func (v *NodePoolValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateResourceSelectors checks the resourceSelector of each node group of the NodePool, skipping those unchanged
+// from the old NodePool, if any
+func (v *NodePoolValidator) validateResourceSelectors(ctx context.Context, nodepool, oldNodepool *hwmgmtv1alpha1.NodePool) error {
+	if nodepool.Namespace != v.Namespace {
+		return nil
+	}
+
+	previous := make(map[string]string)
+	if oldNodepool != nil {
+		for _, nodegroup := range oldNodepool.Spec.NodeGroup {
+			previous[nodegroup.NodePoolData.Name] = nodegroup.NodePoolData.ResourceSelector
+		}
+	}
+
+	nodeGroupPath := field.NewPath("spec", "nodeGroup")
+	var errs field.ErrorList
+	for i, nodegroup := range nodepool.Spec.NodeGroup {
+		selector := nodegroup.NodePoolData.ResourceSelector
+		if old, exists := previous[nodegroup.NodePoolData.Name]; exists && old == selector {
+			continue
+		}
+		if _, err := utils.ParseResourceSelector(selector); err != nil {
+			errs = append(errs, field.Invalid(nodeGroupPath.Index(i).Child("nodePoolData", "resourceSelector"),
+				selector, err.Error()))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	v.Logger.InfoContext(ctx, "Rejected NodePool with invalid resourceSelector", slog.String("nodepool", nodepool.Name))
+	return apierrors.NewInvalid(hwmgmtv1alpha1.GroupVersion.WithKind("NodePool").GroupKind(), nodepool.Name, errs)
+}
+
+// SetupWebhookWithManager registers the validating webhook for NodePools with the Manager.
+func (v *NodePoolValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&hwmgmtv1alpha1.NodePool{}).
+		WithValidator(v).
+		Complete(); err != nil {
+		return fmt.Errorf("failed to create NodePool webhook: %w", err)
+	}
+
+	return nil
+}
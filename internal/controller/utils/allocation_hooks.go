@@ -0,0 +1,344 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"text/template"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+//
+// A NodePool can run hook Jobs before its hardware is allocated and once it has been allocated, for example to
+// register the hosts in an external CMDB or to run vendor diagnostics. Each hook is a Go template of a Job manifest,
+// held in a ConfigMap in the plugin namespace named by a NodePool extension. The Job is created when the NodePool
+// reaches the hook, and the NodePool does not proceed until the Job has completed. A failed Job fails the NodePool.
+//
+
+const (
+	// PreAllocationHookExtension and PostAllocationHookExtension are the NodePool extensions naming the ConfigMaps
+	// that hold the hook Job templates
+	PreAllocationHookExtension  = "preAllocationHook"
+	PostAllocationHookExtension = "postAllocationHook"
+
+	// AllocationHookJobKey is the ConfigMap key holding the hook Job template
+	AllocationHookJobKey = "job"
+
+	// AllocationHookNodePoolLabel and AllocationHookPhaseLabel identify the NodePool and phase a hook Job was run for
+	AllocationHookNodePoolLabel = "hwmgr-plugin.oran.openshift.io/hook-nodepool-uid"
+	AllocationHookPhaseLabel    = "hwmgr-plugin.oran.openshift.io/hook-phase"
+)
+
+// AllocationHookPhase is the point in the allocation of a NodePool at which a hook is run
+type AllocationHookPhase string
+
+const (
+	PreAllocationHook  AllocationHookPhase = "pre-allocation"
+	PostAllocationHook AllocationHookPhase = "post-allocation"
+)
+
+// AllocationHookState is the state of the hook for a phase of the allocation of a NodePool
+type AllocationHookState int
+
+const (
+	// AllocationHookSucceeded is reported when the hook Job has completed, or no hook is configured
+	AllocationHookSucceeded AllocationHookState = iota
+	// AllocationHookRunning is reported while the hook Job is running
+	AllocationHookRunning
+	// AllocationHookFailed is reported when the hook Job, or its template, has failed
+	AllocationHookFailed
+)
+
+// allocationHookNode is the data available to a post-allocation hook template for each node of the NodePool
+type allocationHookNode struct {
+	Name        string
+	HwMgrNodeId string
+	GroupName   string
+	BMCAddress  string
+}
+
+// allocationHookTemplateInput is the data available to a hook Job template
+type allocationHookTemplateInput struct {
+	NodePool  string
+	Namespace string
+	CloudID   string
+	Site      string
+	HwMgrId   string
+	Phase     string
+	Nodes     []allocationHookNode
+}
+
+func allocationHookExtension(phase AllocationHookPhase) string {
+	if phase == PreAllocationHook {
+		return PreAllocationHookExtension
+	}
+	return PostAllocationHookExtension
+}
+
+// allocationHookJobName returns the name of the hook Job for a phase of a NodePool, shortening the NodePool name with
+// a hash of its UID when needed to fit the limit of a Job name
+func allocationHookJobName(nodepool *hwmgmtv1alpha1.NodePool, phase AllocationHookPhase) string {
+	const maxJobNameLength = 63
+	suffix := "-" + string(phase) + "-hook"
+	name := nodepool.Name + suffix
+	if len(name) <= maxJobNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(nodepool.UID))
+	hash := hex.EncodeToString(sum[:])[:8]
+	return nodepool.Name[:maxJobNameLength-len(suffix)-len(hash)-1] + "-" + hash + suffix
+}
+
+// newAllocationHookJob renders the hook Job template for a phase of a NodePool
+func newAllocationHookJob(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	phase AllocationHookPhase,
+	configmap string) (*batchv1.Job, error) {
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: configmap, Namespace: namespace}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, typederrors.NewInputError("%s hook configmap %s not found", phase, configmap)
+		}
+		return nil, fmt.Errorf("failed to get %s hook configmap %s: %w", phase, configmap, err)
+	}
+
+	data, exists := cm.Data[AllocationHookJobKey]
+	if !exists {
+		return nil, typederrors.NewInputError("%s hook configmap %s is missing the %s key", phase, configmap, AllocationHookJobKey)
+	}
+
+	tmpl, err := template.New(configmap).Option("missingkey=error").Parse(data)
+	if err != nil {
+		return nil, typederrors.NewInputError("failed to parse %s hook template %s: %s", phase, configmap, err.Error())
+	}
+
+	input := allocationHookTemplateInput{
+		NodePool:  nodepool.Name,
+		Namespace: nodepool.Namespace,
+		CloudID:   nodepool.Spec.CloudID,
+		Site:      nodepool.Spec.Site,
+		HwMgrId:   nodepool.Spec.HwMgrId,
+		Phase:     string(phase),
+	}
+	if phase == PostAllocationHook {
+		nodelist := &hwmgmtv1alpha1.NodeList{}
+		if err := c.List(ctx, nodelist, client.MatchingFields{"spec.nodePool": nodepool.Name}); err != nil {
+			return nil, fmt.Errorf("failed to list nodes for %s hook: %w", phase, err)
+		}
+		for _, node := range nodelist.Items {
+			hookNode := allocationHookNode{
+				Name:        node.Name,
+				HwMgrNodeId: node.Spec.HwMgrNodeId,
+				GroupName:   node.Spec.GroupName,
+			}
+			if node.Status.BMC != nil {
+				hookNode.BMCAddress = node.Status.BMC.Address
+			}
+			input.Nodes = append(input.Nodes, hookNode)
+		}
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, input); err != nil {
+		return nil, typederrors.NewInputError("failed to render %s hook template %s: %s", phase, configmap, err.Error())
+	}
+
+	job := &batchv1.Job{}
+	if err := yaml.UnmarshalStrict(rendered.Bytes(), job); err != nil {
+		return nil, typederrors.NewInputError("%s hook template %s does not render a valid Job: %s", phase, configmap, err.Error())
+	}
+
+	// The Job is named and labeled for the NodePool, whatever the template sets
+	job.Name = allocationHookJobName(nodepool, phase)
+	job.Namespace = namespace
+	job.GenerateName = ""
+	if job.Labels == nil {
+		job.Labels = make(map[string]string)
+	}
+	job.Labels[AllocationHookNodePoolLabel] = string(nodepool.UID)
+	job.Labels[AllocationHookPhaseLabel] = string(phase)
+	job.OwnerReferences = nil
+	if nodepool.Namespace == namespace {
+		blockDeletion := true
+		job.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion:         nodepool.APIVersion,
+			Kind:               nodepool.Kind,
+			Name:               nodepool.Name,
+			UID:                nodepool.UID,
+			BlockOwnerDeletion: &blockDeletion,
+		}}
+	}
+
+	return job, nil
+}
+
+// getAllocationHookJobState returns the state of a hook Job and a description of it
+func getAllocationHookJobState(job *batchv1.Job) (AllocationHookState, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return AllocationHookSucceeded, fmt.Sprintf("Job %s completed", job.Name)
+		case batchv1.JobFailed:
+			return AllocationHookFailed, fmt.Sprintf("Job %s failed: %s", job.Name, condition.Message)
+		}
+	}
+	return AllocationHookRunning, fmt.Sprintf("Job %s is running", job.Name)
+}
+
+// RunAllocationHook runs the hook configured for a phase of the allocation of a NodePool, creating its Job from the
+// template on the first call, and reports the progress in the AllocationHook condition. The NodePool may proceed past
+// the phase once the hook has succeeded. A failed hook also fails the Provisioned condition of the NodePool.
+func RunAllocationHook(
+	ctx context.Context,
+	c client.Client,
+	reader client.Reader,
+	namespace string,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	phase AllocationHookPhase) (AllocationHookState, error) {
+
+	configmap := nodepool.Spec.Extensions[allocationHookExtension(phase)]
+	if configmap == "" {
+		return AllocationHookSucceeded, nil
+	}
+
+	conditionType := hwmgmtv1alpha1.ConditionType(pluginv1alpha1.ConditionTypes.AllocationHook)
+	jobName := types.NamespacedName{Name: allocationHookJobName(nodepool, phase), Namespace: namespace}
+
+	job := &batchv1.Job{}
+	err := reader.Get(ctx, jobName, job)
+	if err != nil && !errors.IsNotFound(err) {
+		return AllocationHookRunning, fmt.Errorf("failed to get %s hook job %s: %w", phase, jobName, err)
+	}
+
+	if err == nil && job.Labels[AllocationHookNodePoolLabel] != string(nodepool.UID) {
+		// Left behind by an earlier NodePool of the same name, so it is replaced
+		propagation := metav1.DeletePropagationBackground
+		if err := c.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); client.IgnoreNotFound(err) != nil {
+			return AllocationHookRunning, fmt.Errorf("failed to delete stale %s hook job %s: %w", phase, jobName, err)
+		}
+		return AllocationHookRunning, nil
+	}
+
+	if errors.IsNotFound(err) {
+		newJob, err := newAllocationHookJob(ctx, c, namespace, nodepool, phase, configmap)
+		if err != nil {
+			if !typederrors.IsInputError(err) {
+				return AllocationHookRunning, err
+			}
+			message := fmt.Sprintf("Invalid %s hook: %s", phase, err.Error())
+			if err := UpdateNodePoolStatusCondition(ctx, c, nodepool, hwmgmtv1alpha1.Provisioned,
+				hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse, message); err != nil {
+				return AllocationHookFailed, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+			}
+			return AllocationHookFailed, nil
+		}
+		if err := c.Create(ctx, newJob); err != nil && !errors.IsAlreadyExists(err) {
+			return AllocationHookRunning, fmt.Errorf("failed to create %s hook job %s: %w", phase, jobName, err)
+		}
+		job = newJob
+	}
+
+	state, message := getAllocationHookJobState(job)
+	message = fmt.Sprintf("%s hook: %s", phase, message)
+	switch state {
+	case AllocationHookSucceeded:
+		err = UpdateNodePoolStatusCondition(ctx, c, nodepool, conditionType,
+			hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.Completed), metav1.ConditionTrue, message)
+	case AllocationHookFailed:
+		err = UpdateNodePoolStatusCondition(ctx, c, nodepool, conditionType,
+			hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.Failed), metav1.ConditionFalse, message)
+		if err == nil {
+			err = UpdateNodePoolStatusCondition(ctx, c, nodepool, hwmgmtv1alpha1.Provisioned,
+				hwmgmtv1alpha1.Failed, metav1.ConditionFalse, "Allocation "+message)
+		}
+	default:
+		err = UpdateNodePoolStatusCondition(ctx, c, nodepool, conditionType,
+			hwmgmtv1alpha1.ConditionReason(pluginv1alpha1.ConditionReasons.InProgress), metav1.ConditionFalse, message)
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return state, nil
+}
+
+// CheckPostAllocationHook runs the post-allocation hook of a fully allocated NodePool. It returns whether the NodePool
+// can be reported as provisioned and, if not, the result to return until the hook has completed.
+func CheckPostAllocationHook(
+	ctx context.Context,
+	c client.Client,
+	reader client.Reader,
+	namespace string,
+	nodepool *hwmgmtv1alpha1.NodePool) (bool, ctrl.Result, error) {
+
+	state, err := RunAllocationHook(ctx, c, reader, namespace, nodepool, PostAllocationHook)
+	if err != nil {
+		return false, RequeueWithShortInterval(), err
+	}
+	switch state {
+	case AllocationHookRunning:
+		return false, RequeueWithShortInterval(), nil
+	case AllocationHookFailed:
+		return false, DoNotRequeue(), nil
+	}
+	return true, ctrl.Result{}, nil
+}
+
+// DeleteNodePoolHookJobs deletes the hook Jobs run for a NodePool, along with their pods
+func DeleteNodePoolHookJobs(
+	ctx context.Context,
+	c client.Client,
+	reader client.Reader,
+	namespace string,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	propagation := metav1.DeletePropagationBackground
+	for _, phase := range []AllocationHookPhase{PreAllocationHook, PostAllocationHook} {
+		if nodepool.Spec.Extensions[allocationHookExtension(phase)] == "" {
+			continue
+		}
+
+		job := &batchv1.Job{}
+		jobName := types.NamespacedName{Name: allocationHookJobName(nodepool, phase), Namespace: namespace}
+		if err := reader.Get(ctx, jobName, job); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s hook job %s: %w", phase, jobName, err)
+		}
+		if job.Labels[AllocationHookNodePoolLabel] != string(nodepool.UID) {
+			continue
+		}
+		if err := c.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete %s hook job %s: %w", phase, jobName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The NodePool and Node status is defined by the O-Cloud Manager API, so the state of the hardware manager jobs run for
+// a NodePool is published in the status of its AllocationRecord. The job ID annotations remain the source of truth for
+// the jobs in flight.
+
+// JobUpdate is the state of a hardware manager job, as last seen by an adaptor
+type JobUpdate struct {
+	JobId      string
+	Operation  pluginv1alpha1.AllocationOperation
+	NodeName   string
+	Phase      pluginv1alpha1.JobPhase
+	FailReason string
+	FailCode   string
+}
+
+// UpdateAllocationRecordJob records the state of a hardware manager job in the AllocationRecord of the NodePool. The
+// record is created by the AllocationRecord controller, so an update made before then is skipped, and is made again
+// as the job is next checked.
+func UpdateAllocationRecordJob(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, update JobUpdate) error {
+	// nolint: wrapcheck
+	err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		records := &pluginv1alpha1.AllocationRecordList{}
+		if err := c.List(ctx, records, client.InNamespace(nodepool.Namespace)); err != nil {
+			return err
+		}
+
+		for i := range records.Items {
+			record := &records.Items[i]
+			if record.Spec.NodePoolUID != nodepool.UID {
+				continue
+			}
+			if !SetJobRecord(&record.Status, update, metav1.Now()) {
+				return nil
+			}
+			return c.Status().Update(ctx, record)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record job %s in allocation record of NodePool %s: %w", update.JobId, nodepool.Name, err)
+	}
+
+	return nil
+}
+
+// GetAllocatedNodeRecord returns the entry for a node in the AllocationRecord of the NodePool, which identifies the
+// hardware of a Node CR that has since been deleted. It returns nil if there is no record of the node.
+func GetAllocatedNodeRecord(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodename string) (*pluginv1alpha1.AllocatedNodeRecord, error) {
+
+	records := &pluginv1alpha1.AllocationRecordList{}
+	if err := c.List(ctx, records, client.InNamespace(nodepool.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list allocation records: %w", err)
+	}
+
+	for i := range records.Items {
+		record := &records.Items[i]
+		if record.Spec.NodePoolUID != nodepool.UID {
+			continue
+		}
+		for j := range record.Status.Nodes {
+			if record.Status.Nodes[j].NodeName == nodename {
+				return &record.Status.Nodes[j], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// SetJobRecord adds or updates the entry for a job in the status of an AllocationRecord. It returns true if the status
+// was changed.
+func SetJobRecord(status *pluginv1alpha1.AllocationRecordStatus, update JobUpdate, now metav1.Time) bool {
+	var job *pluginv1alpha1.JobRecord
+	for i := range status.Jobs {
+		if status.Jobs[i].JobId == update.JobId && status.Jobs[i].Operation == update.Operation {
+			job = &status.Jobs[i]
+			break
+		}
+	}
+
+	if job == nil {
+		status.Jobs = append(status.Jobs, pluginv1alpha1.JobRecord{
+			JobId:      update.JobId,
+			Operation:  update.Operation,
+			NodeName:   update.NodeName,
+			ObservedAt: now,
+		})
+		job = &status.Jobs[len(status.Jobs)-1]
+	} else if job.Phase == update.Phase && job.FailReason == update.FailReason && job.FailCode == update.FailCode {
+		return false
+	}
+
+	job.Phase = update.Phase
+	job.FailReason = update.FailReason
+	job.FailCode = update.FailCode
+	job.LastTransitionTime = &now
+	if update.Phase != pluginv1alpha1.JobPhases.InProgress && job.CompletedAt == nil {
+		job.CompletedAt = &now
+	}
+
+	return true
+}
@@ -0,0 +1,24 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IsCacheableArtifactURL checks whether the file at a URL can be mirrored to the artifact cache, which only downloads
+// files served over http or https
+func IsCacheableArtifactURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https")
+}
+
+// GetArtifactCacheURL returns the URL of a cached file on the artifact cache server with the given base URL
+func GetArtifactCacheURL(baseURL, path string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + path
+}
@@ -19,13 +19,17 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sync"
 
+	"golang.org/x/net/http/httpproxy"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 	"k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apiserver/pkg/server/dynamiccertificates"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 )
 
 // OAuthClientConfig defines the parameters required to establish an HTTP Client capable of acquiring an OAuth Token
@@ -50,6 +54,14 @@ type OAuthClientConfig struct {
 	Username string
 	// Password, for Password grant type
 	Password string
+	// Provides the client certificate and key presented to the server when mutual TLS authentication is required.  If
+	// not provided then no client certificate is sent.
+	ClientCert dynamiccertificates.CertKeyContentProvider
+	// Defines the proxies used to connect to the server.  If not provided then the proxies are taken from the
+	// HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+	Proxy *pluginv1alpha1.ProxyConfig
+	// The name of the HardwareManager the client connects to, whose log levels apply to the traced messages
+	HwMgrName string
 }
 
 // Default values for backend URL and token:
@@ -113,29 +125,6 @@ func GetDefaultTLSConfig(config *tls.Config, insecureSkipTLSVerify bool) (*tls.C
 	return config, nil
 }
 
-// GetServerTLSConfig creates a tls.Config that uses a dynamic loader to handle updates to the certificate and/or key.
-func GetServerTLSConfig(ctx context.Context, certFile, keyFile string) (*tls.Config, error) {
-	loader, err := dynamiccertificates.NewDynamicServingContentFromFiles("tls-server", certFile, keyFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to setup certificate loader: %w", err)
-	}
-	go loader.Run(ctx, 1)
-
-	tlsConfig := &tls.Config{
-		MinVersion: tls.VersionTLS12,
-		GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			certBytes, keyBytes := loader.CurrentCertKeyContent()
-			cert, err := tls.X509KeyPair(certBytes, keyBytes)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create server certificate: %w", err)
-			}
-			return &cert, nil
-		},
-	}
-
-	return tlsConfig, nil
-}
-
 // GetDefaultBackendTransport returns an HTTP transport with the proper TLS defaults set.
 func GetDefaultBackendTransport(insecureSkipTLSVerify bool) (http.RoundTripper, error) {
 	tlsConfig, err := GetDefaultTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}, insecureSkipTLSVerify)
@@ -168,18 +157,91 @@ func GetTransportWithCaBundle(config OAuthClientConfig, insecureSkipTLSVerify, l
 		}
 	}
 
+	if config.ClientCert != nil {
+		// Resolve the certificate on each handshake so that rotated content from the provider is picked up
+		clientCert := &clientCertificate{provider: config.ClientCert}
+		tlsConfig.GetClientCertificate = func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return clientCert.get()
+		}
+	}
+
+	proxy, err := GetProxyFunc(config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tracing wraps the message logging so that the logged messages carry the propagated trace context
 	if logMessages {
-		return LoggingRoundTripper{TLSClientConfig: tlsConfig}, nil
+		return tracing.WrapTransport(LoggingRoundTripper{TLSClientConfig: tlsConfig, Proxy: proxy, HwMgrName: config.HwMgrName}), nil
 	}
 
-	return net.SetTransportDefaults(&http.Transport{TLSClientConfig: tlsConfig}), nil
+	return tracing.WrapTransport(net.SetTransportDefaults(&http.Transport{TLSClientConfig: tlsConfig, Proxy: proxy})), nil
+}
+
+// clientCertificate holds the client certificate parsed from the content of a provider, so that it is only parsed
+// again once the content has changed
+type clientCertificate struct {
+	provider dynamiccertificates.CertKeyContentProvider
+
+	mu      sync.Mutex
+	certPEM []byte
+	keyPEM  []byte
+	cert    *tls.Certificate
 }
 
-// TODO: Determine whether to remove the message tracing altogether.
-// Currently this writes debug logs, but the level is hardcoded. Seeing these debug logs requires
-// setting the loglevel of the utilsLog logger, so this needs some work here.
+// get returns the client certificate for the current content of the provider
+func (c *clientCertificate) get() (*tls.Certificate, error) {
+	certPEM, keyPEM := c.provider.CurrentCertKeyContent()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cert != nil && bytes.Equal(c.certPEM, certPEM) && bytes.Equal(c.keyPEM, keyPEM) {
+		return c.cert, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client certificate: %w", err)
+	}
+	c.certPEM, c.keyPEM, c.cert = certPEM, keyPEM, &cert
+	return c.cert, nil
+}
+
+// GetProxyFunc returns the function selecting the proxy for each request, as configured for a hardware manager. If
+// no proxy configuration is provided then the proxies are taken from the environment.
+func GetProxyFunc(proxy *pluginv1alpha1.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == nil {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	for _, proxyURL := range []string{proxy.HTTPProxy, proxy.HTTPSProxy} {
+		if proxyURL != "" && !IsValidURL(BracketURLHost(proxyURL)) {
+			return nil, fmt.Errorf("invalid proxy URL: %s", proxyURL)
+		}
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  BracketURLHost(proxy.HTTPProxy),
+		HTTPSProxy: BracketURLHost(proxy.HTTPSProxy),
+		NoProxy:    proxy.NoProxy,
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// messageLog writes the traces of the messages exchanged with hardware managers. They are written at debug level, which
+// is enabled by setting the log level of the hardware manager client in the logging configuration of a
+// HardwareManager.
+var messageLog = slog.New(logging.NewComponentLoggingContextHandler(logging.Components.HwMgrClient, slog.LevelInfo)).
+	With(slog.String("module", "utils"))
+
+// LoggingRoundTripper traces the requests and responses exchanged with a hardware manager, with credentials redacted
 type LoggingRoundTripper struct {
 	TLSClientConfig *tls.Config
+	Proxy           func(*http.Request) (*url.URL, error)
+	HwMgrName       string
 }
 
 func redactObject(object interface{}) interface{} {
@@ -241,7 +303,8 @@ func (t LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 
 	// Do work before the request is sent
 	rt := http.Transport{
-		TLSClientConfig: t.TLSClientConfig}
+		TLSClientConfig: t.TLSClientConfig,
+		Proxy:           t.Proxy}
 	resp, err := rt.RoundTrip(req)
 	if err != nil {
 		return resp, err // nolint: wrapcheck
@@ -276,7 +339,11 @@ func (t LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	}
 
 	// Do work after the response is received
-	utilsLog.Debug(fmt.Sprintf("REQUEST(%s) %s, Headers: %+v, Body: %s, RESPONSE(%d), Headers: %+v, Body: %s",
+	ctx := req.Context()
+	if logging.HwMgrFromContext(ctx) == "" && t.HwMgrName != "" {
+		ctx = logging.WithHwMgr(ctx, t.HwMgrName)
+	}
+	messageLog.DebugContext(ctx, fmt.Sprintf("REQUEST(%s) %s, Headers: %+v, Body: %s, RESPONSE(%d), Headers: %+v, Body: %s",
 		req.Method,
 		req.URL.Path,
 		req.Header,
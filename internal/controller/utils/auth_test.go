@@ -0,0 +1,59 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+)
+
+// testCertKeyContent is a content provider whose content can be replaced by the test
+type testCertKeyContent struct {
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c *testCertKeyContent) Name() string { return "test" }
+
+func (c *testCertKeyContent) CurrentCertKeyContent() ([]byte, []byte) { return c.certPEM, c.keyPEM }
+
+func (c *testCertKeyContent) AddListener(_ dynamiccertificates.Listener) {}
+
+func TestClientCertificateCache(t *testing.T) {
+	certPEM, keyPEM := generateServerCertificate(t)
+	provider := &testCertKeyContent{certPEM: certPEM, keyPEM: keyPEM}
+	clientCert := &clientCertificate{provider: provider}
+
+	first, err := clientCert.get()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := clientCert.get()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected the certificate to be reused while the content is unchanged")
+	}
+
+	// The content is rotated
+	provider.certPEM, provider.keyPEM = generateServerCertificate(t)
+	rotated, err := clientCert.get()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated == first {
+		t.Errorf("expected the rotated content to be parsed")
+	}
+
+	// The content is rotated to a key that does not match the certificate
+	_, provider.keyPEM = generateServerCertificate(t)
+	if _, err := clientCert.get(); err == nil {
+		t.Errorf("expected an error for an invalid certificate and key pair")
+	}
+}
@@ -0,0 +1,106 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	BmcCredentialsRevisionAnnotation = "hwmgr-plugin.oran.openshift.io/bmc-credentials-revision"
+	BmcCredentialsJobAnnotation      = "hwmgr-plugin.oran.openshift.io/bmc-credentials-job"
+
+	BmcUsernameKey = "username"
+	BmcPasswordKey = "password"
+)
+
+// GetBmcCredentialsSecretName returns the name of the BMC credentials secret that applies to nodes using the given
+// profile, with the HardwareProfile taking precedence over the HardwareManager
+func GetBmcCredentialsSecretName(hwmgr *pluginv1alpha1.HardwareManager, hwProfile *pluginv1alpha1.HardwareProfile) string {
+	if hwProfile != nil && hwProfile.Spec.BmcCredentialsSecret != "" {
+		return hwProfile.Spec.BmcCredentialsSecret
+	}
+	if hwmgr != nil && hwmgr.Spec.BmcCredentialsSecret != nil {
+		return *hwmgr.Spec.BmcCredentialsSecret
+	}
+	return ""
+}
+
+// GetBmcCredentialsRevision returns an identifier for the current contents of a BMC credentials secret
+func GetBmcCredentialsRevision(secret *corev1.Secret) string {
+	return fmt.Sprintf("%s/%s", secret.Name, secret.ResourceVersion)
+}
+
+// GetBmcCredentials returns the username and password from a BMC credentials secret
+func GetBmcCredentials(secret *corev1.Secret) (string, string, error) {
+	username, err := GetSecretField(secret, BmcUsernameKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	password, err := GetSecretField(secret, BmcPasswordKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return username, password, nil
+}
+
+// UpdateBmcSecretCredentials sets the username and password in an existing BMC secret. If the secret has changed,
+// it is returned with its new resource version.
+func UpdateBmcSecretCredentials(ctx context.Context, c client.Client, name, namespace, username, password string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+
+	// nolint: wrapcheck
+	err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+			return fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+		}
+
+		if string(secret.Data[BmcUsernameKey]) == username && string(secret.Data[BmcPasswordKey]) == password {
+			return nil
+		}
+
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[BmcUsernameKey] = []byte(username)
+		secret.Data[BmcPasswordKey] = []byte(password)
+		return c.Update(ctx, secret)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update BMC credentials in secret %s/%s: %w", namespace, name, err)
+	}
+
+	return secret, nil
+}
+
+// SetNodeAnnotation sets an annotation on a node, removing it if the value is empty
+func SetNodeAnnotation(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node, key, value string) error {
+	mutation := AddAnnotation(key, value)
+	if value == "" {
+		mutation = RemoveAnnotation(key)
+	}
+
+	updatedNode := &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.Name, Namespace: node.Namespace}}
+	if err := UpdateObjectMetaWithRetry(ctx, c, updatedNode, []MetaMutation{mutation}); err != nil {
+		return err
+	}
+
+	node.SetAnnotations(updatedNode.GetAnnotations())
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BmcSecretNameTemplateExtension is a NodePool extension providing a Go template for the names of the bmc-secrets
+	// created for its nodes, with the .NodeName, .NodePool and .CloudID fields available
+	BmcSecretNameTemplateExtension = "bmcSecretNameTemplate"
+
+	// BmcSecretNamespaceExtension is a NodePool extension naming the namespace in which the bmc-secrets for its nodes
+	// are created, such as the namespace of the cluster being installed
+	BmcSecretNamespaceExtension = "bmcSecretNamespace"
+
+	// BmcSecretNamespaceAnnotation records on a node the namespace of its bmc-secret, when it is not the namespace of
+	// the node, as the Node BMC status only provides the secret name
+	BmcSecretNamespaceAnnotation = "hwmgr-plugin.oran.openshift.io/bmc-secret-namespace"
+
+	// BmcSecretNodePoolLabel identifies the NodePool a bmc-secret was created for, by UID, as a secret in another
+	// namespace cannot have an owner reference to the NodePool
+	BmcSecretNodePoolLabel = "hwmgr-plugin.oran.openshift.io/nodepool-uid"
+)
+
+// bmcSecretTemplateData is the data available to the bmc-secret name template
+type bmcSecretTemplateData struct {
+	NodeName string
+	NodePool string
+	CloudID  string
+}
+
+// GetBmcSecretLocation returns the name and namespace of the bmc-secret for a node of a NodePool, as configured by the
+// NodePool extensions. By default, the secret is named for the node in the namespace of the NodePool.
+func GetBmcSecretLocation(nodepool *hwmgmtv1alpha1.NodePool, nodename string) (types.NamespacedName, error) {
+	location := types.NamespacedName{
+		Name:      BmcSecretName(nodename),
+		Namespace: nodepool.Namespace,
+	}
+
+	if nameTemplate := nodepool.Spec.Extensions[BmcSecretNameTemplateExtension]; nameTemplate != "" {
+		tmpl, err := template.New("bmcSecretName").Option("missingkey=error").Parse(nameTemplate)
+		if err != nil {
+			return location, fmt.Errorf("invalid %s extension: %w", BmcSecretNameTemplateExtension, err)
+		}
+		var name strings.Builder
+		if err := tmpl.Execute(&name, bmcSecretTemplateData{
+			NodeName: nodename,
+			NodePool: nodepool.Name,
+			CloudID:  nodepool.Spec.CloudID,
+		}); err != nil {
+			return location, fmt.Errorf("invalid %s extension: %w", BmcSecretNameTemplateExtension, err)
+		}
+		if errs := validation.IsDNS1123Subdomain(name.String()); len(errs) > 0 {
+			return location, fmt.Errorf("invalid %s extension: secret name %q: %s",
+				BmcSecretNameTemplateExtension, name.String(), strings.Join(errs, ", "))
+		}
+		location.Name = name.String()
+	}
+
+	if namespace := nodepool.Spec.Extensions[BmcSecretNamespaceExtension]; namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return location, fmt.Errorf("invalid %s extension: namespace %q: %s",
+				BmcSecretNamespaceExtension, namespace, strings.Join(errs, ", "))
+		}
+		location.Namespace = namespace
+	}
+
+	return location, nil
+}
+
+// NewBmcSecret builds the bmc-secret for a node of a NodePool. The secret is owned by the NodePool where they share a
+// namespace, and is otherwise deleted along with the NodePool by DeleteNodePoolBmcSecrets.
+func NewBmcSecret(nodepool *hwmgmtv1alpha1.NodePool, location types.NamespacedName, username, password []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: newBmcSecretObjectMeta(nodepool, location),
+		Data: map[string][]byte{
+			BmcUsernameKey: username,
+			BmcPasswordKey: password,
+		},
+	}
+}
+
+// newBmcSecretObjectMeta returns the metadata of an object created at the bmc-secret location for a node of a
+// NodePool, labeled with the NodePool and owned by it where they share a namespace
+func newBmcSecretObjectMeta(nodepool *hwmgmtv1alpha1.NodePool, location types.NamespacedName) metav1.ObjectMeta {
+	objectMeta := metav1.ObjectMeta{
+		Name:      location.Name,
+		Namespace: location.Namespace,
+		Labels: map[string]string{
+			BmcSecretNodePoolLabel: string(nodepool.UID),
+		},
+	}
+
+	if location.Namespace == nodepool.Namespace {
+		blockDeletion := true
+		objectMeta.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion:         nodepool.APIVersion,
+			Kind:               nodepool.Kind,
+			Name:               nodepool.Name,
+			UID:                nodepool.UID,
+			BlockOwnerDeletion: &blockDeletion,
+		}}
+	}
+
+	return objectMeta
+}
+
+// GetBmcSecretNodeAnnotations returns the annotations recording the bmc-secret location on a node, if needed
+func GetBmcSecretNodeAnnotations(location types.NamespacedName, nodeNamespace string) map[string]string {
+	if location.Namespace == nodeNamespace {
+		return nil
+	}
+	return map[string]string{BmcSecretNamespaceAnnotation: location.Namespace}
+}
+
+// GetNodeBmcSecret returns the name and namespace of the bmc-secret of a node
+func GetNodeBmcSecret(node *hwmgmtv1alpha1.Node) types.NamespacedName {
+	location := types.NamespacedName{
+		Name:      BmcSecretName(node.Name),
+		Namespace: node.Namespace,
+	}
+	if node.Status.BMC != nil && node.Status.BMC.CredentialsName != "" {
+		location.Name = node.Status.BMC.CredentialsName
+	}
+	if namespace := node.GetAnnotations()[BmcSecretNamespaceAnnotation]; namespace != "" {
+		location.Namespace = namespace
+	}
+	return location
+}
+
+// DeleteNodePoolBmcSecrets deletes the bmc-secrets created for a NodePool outside its namespace, which are not
+// garbage collected with the NodePool, along with any ExternalSecrets populating them
+func DeleteNodePoolBmcSecrets(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	namespace := nodepool.Spec.Extensions[BmcSecretNamespaceExtension]
+	if namespace == "" || namespace == nodepool.Namespace {
+		return nil
+	}
+
+	if err := deleteNodePoolExternalSecrets(ctx, c, nodepool, namespace); err != nil {
+		return err
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets,
+		client.InNamespace(namespace),
+		client.MatchingLabels{BmcSecretNodePoolLabel: string(nodepool.UID)}); err != nil {
+		return fmt.Errorf("failed to list bmc-secrets for NodePool %s in namespace %s: %w", nodepool.Name, namespace, err)
+	}
+
+	for i := range secrets.Items {
+		if err := c.Delete(ctx, &secrets.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete bmc-secret %s/%s: %w", namespace, secrets.Items[i].Name, err)
+		}
+	}
+
+	return nil
+}
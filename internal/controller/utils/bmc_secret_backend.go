@@ -0,0 +1,302 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// BmcHwMgrIdKey and BmcReferenceKey are the keys of a bmc-secret created by the Reference backend, identifying the
+	// hardware manager holding the BMC credentials and the credentials on it
+	BmcHwMgrIdKey   = "hwMgrId"
+	BmcReferenceKey = "reference"
+
+	defaultExternalSecretRefreshInterval = time.Hour
+)
+
+// ExternalSecretGVK is the External Secrets Operator resource created for bmc-secrets by the ExternalSecret backend
+var ExternalSecretGVK = schema.GroupVersionKind{
+	Group:   "external-secrets.io",
+	Version: "v1beta1",
+	Kind:    "ExternalSecret",
+}
+
+// BmcCredentialsSource identifies the BMC credentials of a node on its hardware manager. The credentials are only
+// fetched by backends that write them to the bmc-secret.
+type BmcCredentialsSource struct {
+	// Reference identifies the credentials on the hardware manager
+	Reference string
+
+	// Fetch retrieves the username and password from the hardware manager
+	Fetch func(ctx context.Context) (username, password []byte, err error)
+}
+
+// BmcSecretBackend provides the BMC credentials of nodes in their bmc-secrets
+type BmcSecretBackend interface {
+	// CreateBmcSecret creates or updates the bmc-secret for a node of a NodePool at the given location
+	CreateBmcSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, nodename string,
+		location types.NamespacedName, source BmcCredentialsSource) error
+
+	// UpdateBmcSecretCredentials sets rotated credentials in the bmc-secret at the given location, where the
+	// backend holds them
+	UpdateBmcSecretCredentials(ctx context.Context, location types.NamespacedName, username, password string) error
+}
+
+// NewBmcSecretBackend returns the BMC secret backend configured for a HardwareManager
+func NewBmcSecretBackend(c client.Client, hwmgr *pluginv1alpha1.HardwareManager) (BmcSecretBackend, error) {
+	config := hwmgr.Spec.BmcSecretBackend
+	if config == nil {
+		return &kubernetesBmcSecretBackend{client: c}, nil
+	}
+
+	switch config.Type {
+	case "", pluginv1alpha1.BmcSecretBackendTypes.Kubernetes:
+		return &kubernetesBmcSecretBackend{client: c}, nil
+	case pluginv1alpha1.BmcSecretBackendTypes.Reference:
+		return &referenceBmcSecretBackend{client: c}, nil
+	case pluginv1alpha1.BmcSecretBackendTypes.ExternalSecret:
+		if config.ExternalSecret == nil {
+			return nil, fmt.Errorf("bmcSecretBackend type %s requires the externalSecret configuration", config.Type)
+		}
+		if config.ExternalSecret.SecretStoreName == "" {
+			return nil, fmt.Errorf("bmcSecretBackend externalSecret requires a secretStoreName")
+		}
+		keyTemplate := "{{ .Reference }}"
+		if config.ExternalSecret.KeyTemplate != "" {
+			keyTemplate = config.ExternalSecret.KeyTemplate
+		}
+		tmpl, err := template.New("bmcSecretKey").Option("missingkey=error").Parse(keyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bmcSecretBackend externalSecret keyTemplate: %w", err)
+		}
+		return &externalSecretBmcSecretBackend{client: c, config: config.ExternalSecret, keyTemplate: tmpl}, nil
+	default:
+		return nil, fmt.Errorf("unsupported bmcSecretBackend type: %s", config.Type)
+	}
+}
+
+// kubernetesBmcSecretBackend writes the credentials retrieved from the hardware manager to the bmc-secret
+type kubernetesBmcSecretBackend struct {
+	client client.Client
+}
+
+func (b *kubernetesBmcSecretBackend) CreateBmcSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	nodename string, location types.NamespacedName, source BmcCredentialsSource) error {
+
+	username, password, err := source.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	bmcSecret := NewBmcSecret(nodepool, location, username, password)
+	if err := CreateOrUpdateK8sCR(ctx, b.client, bmcSecret, nil, UPDATE); err != nil {
+		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
+	}
+	return nil
+}
+
+func (b *kubernetesBmcSecretBackend) UpdateBmcSecretCredentials(ctx context.Context, location types.NamespacedName,
+	username, password string) error {
+
+	_, err := UpdateBmcSecretCredentials(ctx, b.client, location.Name, location.Namespace, username, password)
+	return err
+}
+
+// referenceBmcSecretBackend writes only a reference to the credentials held by the hardware manager to the bmc-secret
+type referenceBmcSecretBackend struct {
+	client client.Client
+}
+
+func (b *referenceBmcSecretBackend) CreateBmcSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	nodename string, location types.NamespacedName, source BmcCredentialsSource) error {
+
+	bmcSecret := &corev1.Secret{
+		ObjectMeta: newBmcSecretObjectMeta(nodepool, location),
+		Data: map[string][]byte{
+			BmcHwMgrIdKey:   []byte(nodepool.Spec.HwMgrId),
+			BmcReferenceKey: []byte(source.Reference),
+		},
+	}
+	if err := CreateOrUpdateK8sCR(ctx, b.client, bmcSecret, nil, UPDATE); err != nil {
+		return fmt.Errorf("failed to create bmc-secret for node %s: %w", nodename, err)
+	}
+	return nil
+}
+
+// UpdateBmcSecretCredentials has nothing to update, as the rotated credentials are held by the hardware manager
+func (b *referenceBmcSecretBackend) UpdateBmcSecretCredentials(context.Context, types.NamespacedName, string, string) error {
+	return nil
+}
+
+// externalSecretBmcSecretBackend creates an ExternalSecret that populates the bmc-secret from an external secret store
+type externalSecretBmcSecretBackend struct {
+	client      client.Client
+	config      *pluginv1alpha1.ExternalSecretBackend
+	keyTemplate *template.Template
+}
+
+// bmcSecretKeyTemplateData is the data available to the ExternalSecret key template
+type bmcSecretKeyTemplateData struct {
+	bmcSecretTemplateData
+	Reference string
+}
+
+func (b *externalSecretBmcSecretBackend) CreateBmcSecret(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool,
+	nodename string, location types.NamespacedName, source BmcCredentialsSource) error {
+
+	var key strings.Builder
+	if err := b.keyTemplate.Execute(&key, bmcSecretKeyTemplateData{
+		bmcSecretTemplateData: bmcSecretTemplateData{
+			NodeName: nodename,
+			NodePool: nodepool.Name,
+			CloudID:  nodepool.Spec.CloudID,
+		},
+		Reference: source.Reference,
+	}); err != nil {
+		return fmt.Errorf("invalid bmcSecretBackend externalSecret keyTemplate: %w", err)
+	}
+	if key.Len() == 0 {
+		return fmt.Errorf("empty secret store key for the bmc-secret of node %s", nodename)
+	}
+
+	storeKind := b.config.SecretStoreKind
+	if storeKind == "" {
+		storeKind = "SecretStore"
+	}
+	refreshInterval := defaultExternalSecretRefreshInterval
+	if b.config.RefreshInterval != nil && b.config.RefreshInterval.Duration > 0 {
+		refreshInterval = b.config.RefreshInterval.Duration
+	}
+
+	objectMeta := newBmcSecretObjectMeta(nodepool, location)
+	externalSecret := &unstructured.Unstructured{}
+	externalSecret.SetGroupVersionKind(ExternalSecretGVK)
+	externalSecret.SetName(objectMeta.Name)
+	externalSecret.SetNamespace(objectMeta.Namespace)
+	externalSecret.SetLabels(objectMeta.Labels)
+	externalSecret.SetOwnerReferences(objectMeta.OwnerReferences)
+
+	// The target secret is labeled like a bmc-secret, so it can be found for the NodePool
+	targetLabels := make(map[string]interface{}, len(objectMeta.Labels))
+	for k, v := range objectMeta.Labels {
+		targetLabels[k] = v
+	}
+	remoteRef := func(property string) map[string]interface{} {
+		return map[string]interface{}{"key": key.String(), "property": property}
+	}
+	externalSecret.Object["spec"] = map[string]interface{}{
+		"refreshInterval": refreshInterval.String(),
+		"secretStoreRef": map[string]interface{}{
+			"name": b.config.SecretStoreName,
+			"kind": storeKind,
+		},
+		"target": map[string]interface{}{
+			"name":           location.Name,
+			"creationPolicy": "Owner",
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": targetLabels},
+			},
+		},
+		"data": []interface{}{
+			map[string]interface{}{"secretKey": BmcUsernameKey, "remoteRef": remoteRef(BmcUsernameKey)},
+			map[string]interface{}{"secretKey": BmcPasswordKey, "remoteRef": remoteRef(BmcPasswordKey)},
+		},
+	}
+
+	if err := CreateOrUpdateK8sCR(ctx, b.client, externalSecret, nil, UPDATE); err != nil {
+		return fmt.Errorf("failed to create ExternalSecret for the bmc-secret of node %s: %w", nodename, err)
+	}
+	return nil
+}
+
+// UpdateBmcSecretCredentials has nothing to update, as the bmc-secret is refreshed from the secret store, where rotated
+// credentials must be stored by its owner
+func (b *externalSecretBmcSecretBackend) UpdateBmcSecretCredentials(context.Context, types.NamespacedName, string, string) error {
+	return nil
+}
+
+// deleteNodePoolExternalSecrets deletes the ExternalSecrets created for the bmc-secrets of a NodePool in a namespace.
+// Deleting an ExternalSecret also deletes the bmc-secret it owns.
+func deleteNodePoolExternalSecrets(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool,
+	namespace string) error {
+
+	externalSecrets := &unstructured.UnstructuredList{}
+	externalSecrets.SetGroupVersionKind(ExternalSecretGVK.GroupVersion().WithKind(ExternalSecretGVK.Kind + "List"))
+	if err := c.List(ctx, externalSecrets,
+		client.InNamespace(namespace),
+		client.MatchingLabels{BmcSecretNodePoolLabel: string(nodepool.UID)}); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			// The External Secrets Operator is not installed, so there are none to delete
+			return nil
+		}
+		return fmt.Errorf("failed to list ExternalSecrets for NodePool %s in namespace %s: %w", nodepool.Name, namespace, err)
+	}
+
+	for i := range externalSecrets.Items {
+		if err := c.Delete(ctx, &externalSecrets.Items[i]); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete ExternalSecret %s/%s: %w", namespace, externalSecrets.Items[i].GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteNodeBmcSecret deletes the bmc-secret created for a node of a NodePool at the given location, along with any
+// ExternalSecret populating it. Objects at the location that were not created for the NodePool, such as the BMC secret
+// of a BMH referenced by a metal3 node, are left alone.
+func DeleteNodeBmcSecret(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool,
+	location types.NamespacedName) error {
+	return DeleteBmcSecret(ctx, c, nodepool.UID, location)
+}
+
+// DeleteBmcSecret deletes the bmc-secret at the given location if it was created for the NodePool with the given UID,
+// along with any ExternalSecret populating it. The NodePool itself need not exist.
+func DeleteBmcSecret(ctx context.Context, c client.Client, nodepoolUID types.UID, location types.NamespacedName) error {
+	externalSecret := &unstructured.Unstructured{}
+	externalSecret.SetGroupVersionKind(ExternalSecretGVK)
+	err := c.Get(ctx, location, externalSecret)
+	switch {
+	case err == nil:
+		if externalSecret.GetLabels()[BmcSecretNodePoolLabel] == string(nodepoolUID) {
+			if err := c.Delete(ctx, externalSecret); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete ExternalSecret %s: %w", location, err)
+			}
+		}
+	case errors.IsNotFound(err), meta.IsNoMatchError(err), runtime.IsNotRegisteredError(err):
+		// There is no ExternalSecret, or the External Secrets Operator is not installed
+	default:
+		return fmt.Errorf("failed to get ExternalSecret %s: %w", location, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, location, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if secret.Labels[BmcSecretNodePoolLabel] != string(nodepoolUID) {
+		return nil
+	}
+	if err := c.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete bmc-secret %s: %w", location, err)
+	}
+	return nil
+}
@@ -7,14 +7,58 @@ SPDX-License-Identifier: Apache-2.0
 package utils
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 )
 
-// SetStatusCondition is a convenience wrapper for meta.SetStatusCondition that takes in the types defined here and converts them to strings
-func SetStatusCondition(existingConditions *[]metav1.Condition, conditionType, conditionReason string, conditionStatus metav1.ConditionStatus, message string) {
+// correlationIDTag prefixes the correlation ID appended to condition messages
+const correlationIDTag = " [correlationID: "
+
+// ConditionMessage returns the message of a condition without the correlation ID appended by SetStatusCondition
+func ConditionMessage(condition *metav1.Condition) string {
+	if condition == nil {
+		return ""
+	}
+	return stripCorrelationID(condition.Message)
+}
+
+// IsConditionFailed returns true if a condition reports a failure, including an operation that timed out
+func IsConditionFailed(condition *metav1.Condition) bool {
+	return condition != nil &&
+		(condition.Reason == string(hwmgmtv1alpha1.Failed) || condition.Reason == string(hwmgmtv1alpha1.TimedOut))
+}
+
+func stripCorrelationID(message string) string {
+	if i := strings.LastIndex(message, correlationIDTag); i >= 0 && strings.HasSuffix(message, "]") {
+		return message[:i]
+	}
+	return message
+}
+
+// SetStatusCondition is a convenience wrapper for meta.SetStatusCondition that takes in the types defined here and converts them to strings.
+// The correlation ID of the context is appended to the message when the condition changes. An unchanged condition
+// keeps the ID of the reconcile or request that last changed it, so that repeated reconciles do not update the status.
+func SetStatusCondition(ctx context.Context, existingConditions *[]metav1.Condition, conditionType, conditionReason string, conditionStatus metav1.ConditionStatus, message string) {
 	conditions := *existingConditions
 	condition := meta.FindStatusCondition(*existingConditions, conditionType)
+
+	message = stripCorrelationID(message)
+	if condition != nil &&
+		condition.Status == conditionStatus &&
+		condition.Reason == conditionReason &&
+		ConditionMessage(condition) == message {
+		message = condition.Message
+	} else if id := logging.CorrelationIDFromContext(ctx); id != "" {
+		message = fmt.Sprintf("%s%s%s]", message, correlationIDTag, id)
+	}
+
 	if condition != nil &&
 		condition.Status != conditionStatus &&
 		conditions[len(conditions)-1].Type != conditionType {
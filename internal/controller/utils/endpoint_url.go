@@ -0,0 +1,77 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// bracketIPv6Literal encloses an IPv6 literal address in brackets, as required for the host of a URL. A zone is
+// percent-encoded. Any other host, including an address already in brackets, is returned unchanged.
+func bracketIPv6Literal(host string) string {
+	address, zone, _ := strings.Cut(host, "%")
+	ip := net.ParseIP(address)
+	if ip == nil || !strings.Contains(address, ":") {
+		return host
+	}
+	if zone != "" {
+		return "[" + address + "%25" + zone + "]"
+	}
+	return "[" + address + "]"
+}
+
+// BracketURLHost encloses an IPv6 literal host of a URL in brackets. As a port cannot be told apart from the last
+// groups of an address without them, an unbracketed host that is a valid IPv6 address is taken as having no port.
+// The rest of the URL is left as it is.
+func BracketURLHost(rawURL string) string {
+	scheme, rest, found := strings.Cut(rawURL, "://")
+	if !found {
+		return rawURL
+	}
+
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority, path := rest[:end], rest[end:]
+
+	var userinfo string
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		userinfo, authority = authority[:at+1], authority[at+1:]
+	}
+
+	return scheme + "://" + userinfo + bracketIPv6Literal(authority) + path
+}
+
+// ParseEndpointURL parses the absolute URL of an endpoint, such as a hardware manager API or a BMC, accepting an
+// IPv6 literal host with or without brackets. No TLS server name is sent for an IP literal host, so the server
+// certificate must have the address in its IP subject alternative names.
+func ParseEndpointURL(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(BracketURLHost(strings.TrimSpace(rawURL)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid URL %s: scheme and host are required", rawURL)
+	}
+	if parsed.Hostname() == "" {
+		return nil, fmt.Errorf("invalid URL %s: empty host", rawURL)
+	}
+	return parsed, nil
+}
+
+// NormalizeBMCAddress returns a BMC address with an IPv6 literal host enclosed in brackets. The address may be a URL,
+// such as redfish+https://[fd00::1]/redfish/v1/Systems/1, or a bare host for the default IPMI driver.
+func NormalizeBMCAddress(address string) string {
+	if !strings.Contains(address, "://") {
+		return bracketIPv6Literal(address)
+	}
+	return BracketURLHost(address)
+}
@@ -0,0 +1,237 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseEndpointURL(t *testing.T) {
+	tests := []struct {
+		description      string
+		rawURL           string
+		expectError      bool
+		expectedURL      string
+		expectedHostname string
+		expectedPort     string
+	}{
+		{
+			description:      "hostname",
+			rawURL:           "https://hwmgr.example.com:8443/api",
+			expectedURL:      "https://hwmgr.example.com:8443/api",
+			expectedHostname: "hwmgr.example.com",
+			expectedPort:     "8443",
+		},
+		{
+			description:      "IPv4 address",
+			rawURL:           "https://192.0.2.10/api",
+			expectedURL:      "https://192.0.2.10/api",
+			expectedHostname: "192.0.2.10",
+		},
+		{
+			description:      "bracketed IPv6 address with port",
+			rawURL:           "https://[fd00:1::10]:8443/api",
+			expectedURL:      "https://[fd00:1::10]:8443/api",
+			expectedHostname: "fd00:1::10",
+			expectedPort:     "8443",
+		},
+		{
+			description:      "unbracketed IPv6 address is taken as having no port",
+			rawURL:           "https://fd00:1::10:8443/api",
+			expectedURL:      "https://[fd00:1::10:8443]/api",
+			expectedHostname: "fd00:1::10:8443",
+		},
+		{
+			description:      "unbracketed IPv6 address without path",
+			rawURL:           "https://fd00:1::10",
+			expectedURL:      "https://[fd00:1::10]",
+			expectedHostname: "fd00:1::10",
+		},
+		{
+			description:      "unbracketed IPv6 address with userinfo and query",
+			rawURL:           "http://user@fd00:1::10?x=1",
+			expectedURL:      "http://user@[fd00:1::10]?x=1",
+			expectedHostname: "fd00:1::10",
+		},
+		{
+			description:      "IPv4-mapped IPv6 address",
+			rawURL:           "https://[::ffff:192.0.2.10]/api",
+			expectedURL:      "https://[::ffff:192.0.2.10]/api",
+			expectedHostname: "::ffff:192.0.2.10",
+		},
+		{
+			description:      "unbracketed IPv4-mapped IPv6 address",
+			rawURL:           "https://::ffff:192.0.2.10/api",
+			expectedURL:      "https://[::ffff:192.0.2.10]/api",
+			expectedHostname: "::ffff:192.0.2.10",
+		},
+		{
+			description:      "link-local IPv6 address with zone",
+			rawURL:           "https://fe80::1%eth0/api",
+			expectedURL:      "https://[fe80::1%25eth0]/api",
+			expectedHostname: "fe80::1%eth0",
+		},
+		{
+			description:      "bracketed link-local IPv6 address with encoded zone",
+			rawURL:           "https://[fe80::1%25eth0]:443/api",
+			expectedURL:      "https://[fe80::1%25eth0]:443/api",
+			expectedHostname: "fe80::1%eth0",
+			expectedPort:     "443",
+		},
+		{
+			description: "missing scheme",
+			rawURL:      "fd00:1::10",
+			expectError: true,
+		},
+		{
+			description: "missing host",
+			rawURL:      "https:///api",
+			expectError: true,
+		},
+		{
+			description: "unterminated bracket",
+			rawURL:      "https://[fd00:1::10/api",
+			expectError: true,
+		},
+		{
+			description: "invalid port",
+			rawURL:      "https://[fd00:1::10]:port/api",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			parsed, err := ParseEndpointURL(test.rawURL)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got %s", parsed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed.String() != test.expectedURL {
+				t.Errorf("expected URL %s, got %s", test.expectedURL, parsed.String())
+			}
+			if parsed.Hostname() != test.expectedHostname {
+				t.Errorf("expected hostname %s, got %s", test.expectedHostname, parsed.Hostname())
+			}
+			if parsed.Port() != test.expectedPort {
+				t.Errorf("expected port %q, got %q", test.expectedPort, parsed.Port())
+			}
+		})
+	}
+}
+
+func TestNormalizeBMCAddress(t *testing.T) {
+	tests := []struct {
+		description string
+		address     string
+		expected    string
+	}{
+		{
+			description: "IPv4 redfish address",
+			address:     "redfish+https://192.0.2.20/redfish/v1/Systems/1",
+			expected:    "redfish+https://192.0.2.20/redfish/v1/Systems/1",
+		},
+		{
+			description: "unbracketed IPv6 virtual media address",
+			address:     "idrac-virtualmedia+https://fd00:2::20/redfish/v1/Systems/System.Embedded.1",
+			expected:    "idrac-virtualmedia+https://[fd00:2::20]/redfish/v1/Systems/System.Embedded.1",
+		},
+		{
+			description: "bracketed IPv6 address with port",
+			address:     "ipmi://[fd00:2::20]:623",
+			expected:    "ipmi://[fd00:2::20]:623",
+		},
+		{
+			description: "bare IPv4 address",
+			address:     "192.0.2.20",
+			expected:    "192.0.2.20",
+		},
+		{
+			description: "bare IPv6 address",
+			address:     "fd00:2::20",
+			expected:    "[fd00:2::20]",
+		},
+		{
+			description: "bare hostname",
+			address:     "bmc-1.example.com",
+			expected:    "bmc-1.example.com",
+		},
+		{
+			description: "empty address",
+			address:     "",
+			expected:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if actual := NormalizeBMCAddress(test.address); actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEndpointTransportDualStack(t *testing.T) {
+	for _, listenAddress := range []string{"127.0.0.1:0", "[::1]:0"} {
+		t.Run(listenAddress, func(t *testing.T) {
+			listener, err := net.Listen("tcp", listenAddress)
+			if err != nil {
+				t.Skipf("address family not available: %v", err)
+			}
+
+			serverNames := make(chan string, 1)
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.Listener = listener
+			server.TLS = &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+					serverNames <- hello.ServerName
+					return nil, nil
+				},
+			}
+			server.StartTLS()
+			defer server.Close()
+
+			// The host is given without brackets, as an IPv6 endpoint may be configured, and the port added once parsed
+			host, port, err := net.SplitHostPort(listener.Addr().String())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			endpoint, err := ParseEndpointURL("https://" + host + "/")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			endpoint.Host = net.JoinHostPort(endpoint.Hostname(), port)
+
+			tr, err := GetTransportWithCaBundle(OAuthClientConfig{}, true, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp, err := (&http.Client{Transport: tr}).Get(endpoint.String())
+			if err != nil {
+				t.Fatalf("request to %s failed: %v", endpoint, err)
+			}
+			resp.Body.Close()
+
+			if serverName := <-serverNames; serverName != "" {
+				t.Errorf("expected no TLS server name for an IP literal host, got %s", serverName)
+			}
+		})
+	}
+}
@@ -9,16 +9,22 @@ package utils
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 const (
 	LogMessagesAnnotation = "hwmgr-plugin.oran.openshift.io/logMessages"
 	LogMessagesEnabled    = "enabled"
+
+	HardwareManagerFinalizer = "oran-hwmgr-plugin/hardwaremanager-finalizer"
 )
 
 func GetHardwareManagerValidationCondition(hwmgr *pluginv1alpha1.HardwareManager) *metav1.Condition {
@@ -45,13 +51,56 @@ func IsHardwareManagerValidationFailed(hwmgr *pluginv1alpha1.HardwareManager) bo
 	return false
 }
 
+// IsHardwareManagerLogMessagesEnabled checks whether the messages exchanged with the hardware manager are traced, as
+// they are when the hardware manager client logs at debug level
 func IsHardwareManagerLogMessagesEnabled(hwmgr *pluginv1alpha1.HardwareManager) bool {
-	annotations := hwmgr.GetAnnotations()
-	if annotations == nil {
+	levels, err := GetHardwareManagerLogLevels(hwmgr)
+	if err != nil {
 		return false
 	}
 
-	return annotations[LogMessagesAnnotation] == LogMessagesEnabled
+	level, exists := levels.Get(logging.Components.HwMgrClient)
+	return exists && level <= slog.LevelDebug
+}
+
+// GetHardwareManagerLogLevels returns the log levels configured for a HardwareManager. The LogMessagesAnnotation sets
+// the hardware manager client to debug level, unless a level is configured for it.
+func GetHardwareManagerLogLevels(hwmgr *pluginv1alpha1.HardwareManager) (logging.HwMgrLevels, error) {
+	levels := logging.HwMgrLevels{Components: make(map[logging.Component]slog.Level)}
+
+	if config := hwmgr.Spec.Logging; config != nil {
+		if config.Level != "" {
+			level, err := logging.ParseLevel(string(config.Level))
+			if err != nil {
+				return levels, err // nolint: wrapcheck
+			}
+			levels.Level = &level
+		}
+
+		if config.Components != nil {
+			for component, name := range map[logging.Component]pluginv1alpha1.LogLevel{
+				logging.Components.Adaptor:     config.Components.Adaptor,
+				logging.Components.HwMgrClient: config.Components.HwMgrClient,
+				logging.Components.Server:      config.Components.Server,
+			} {
+				if name == "" {
+					continue
+				}
+				level, err := logging.ParseLevel(string(name))
+				if err != nil {
+					return levels, err // nolint: wrapcheck
+				}
+				levels.Components[component] = level
+			}
+		}
+	}
+
+	if _, exists := levels.Components[logging.Components.HwMgrClient]; !exists &&
+		hwmgr.GetAnnotations()[LogMessagesAnnotation] == LogMessagesEnabled {
+		levels.Components[logging.Components.HwMgrClient] = slog.LevelDebug
+	}
+
+	return levels, nil
 }
 
 func UpdateHardwareManagerStatusCondition(
@@ -63,7 +112,7 @@ func UpdateHardwareManagerStatusCondition(
 	conditionStatus metav1.ConditionStatus,
 	message string) error {
 
-	SetStatusCondition(&hwmgr.Status.Conditions,
+	SetStatusCondition(ctx, &hwmgr.Status.Conditions,
 		string(conditionType),
 		string(conditionReason),
 		conditionStatus,
@@ -75,3 +124,49 @@ func UpdateHardwareManagerStatusCondition(
 
 	return nil
 }
+
+func HardwareManagerAddFinalizer(
+	ctx context.Context,
+	c client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+) error {
+	// nolint: wrapcheck
+	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
+		newHwmgr := &pluginv1alpha1.HardwareManager{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(hwmgr), newHwmgr); err != nil {
+			return err
+		}
+		controllerutil.AddFinalizer(newHwmgr, HardwareManagerFinalizer)
+		if err := c.Update(ctx, newHwmgr); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add finalizer to hwmgr: %w", err)
+	}
+	return nil
+}
+
+func HardwareManagerRemoveFinalizer(
+	ctx context.Context,
+	c client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+) error {
+	// nolint: wrapcheck
+	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
+		newHwmgr := &pluginv1alpha1.HardwareManager{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(hwmgr), newHwmgr); err != nil {
+			return err
+		}
+		controllerutil.RemoveFinalizer(newHwmgr, HardwareManagerFinalizer)
+		if err := c.Update(ctx, newHwmgr); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove finalizer from hwmgr: %w", err)
+	}
+	return nil
+}
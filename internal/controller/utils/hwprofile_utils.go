@@ -0,0 +1,114 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxHwProfileDepth limits the length of a chain of base profiles
+const maxHwProfileDepth = 8
+
+// GetHardwareProfile gets a HardwareProfile with its base profiles resolved. The spec of the returned profile is the
+// merge of the chain of base profiles, starting from the root, with each profile overriding the BIOS attributes,
+// firmware, and BMC credentials secret it sets. AutoRemediate is taken from the requested profile alone.
+func GetHardwareProfile(ctx context.Context, c client.Reader, namespace, name string) (*pluginv1alpha1.HardwareProfile, error) {
+	var chain []*pluginv1alpha1.HardwareProfile
+	for next := name; next != ""; {
+		if slices.ContainsFunc(chain, func(profile *pluginv1alpha1.HardwareProfile) bool { return profile.Name == next }) {
+			return nil, fmt.Errorf("HardwareProfile %s has a cycle in its base profiles at %s", name, next)
+		}
+		if len(chain) == maxHwProfileDepth {
+			return nil, fmt.Errorf("HardwareProfile %s exceeds the maximum of %d levels of base profiles", name, maxHwProfileDepth)
+		}
+
+		profile := &pluginv1alpha1.HardwareProfile{}
+		if err := c.Get(ctx, types.NamespacedName{Name: next, Namespace: namespace}, profile); err != nil {
+			if next == name {
+				return nil, fmt.Errorf("unable to find HardwareProfile CR (%s): %w", name, err)
+			}
+			return nil, fmt.Errorf("unable to find base HardwareProfile CR (%s) of %s: %w", next, name, err)
+		}
+		chain = append(chain, profile)
+		next = profile.Spec.BaseProfile
+	}
+
+	resolved := chain[0].DeepCopy()
+	if len(chain) == 1 {
+		return resolved, nil
+	}
+
+	spec := pluginv1alpha1.HardwareProfileSpec{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		mergeHardwareProfileSpec(&spec, &chain[i].Spec)
+	}
+	spec.BaseProfile = resolved.Spec.BaseProfile
+	spec.AutoRemediate = resolved.Spec.AutoRemediate
+	resolved.Spec = spec
+
+	return resolved, nil
+}
+
+// mergeHardwareProfileSpec overlays the settings of a profile onto the merged spec of its base profiles
+func mergeHardwareProfileSpec(merged, overlay *pluginv1alpha1.HardwareProfileSpec) {
+	if len(overlay.Bios.Attributes) > 0 {
+		if merged.Bios.Attributes == nil {
+			merged.Bios.Attributes = maps.Clone(overlay.Bios.Attributes)
+		} else {
+			maps.Copy(merged.Bios.Attributes, overlay.Bios.Attributes)
+		}
+	}
+	if len(overlay.Bios.Settings) > 0 {
+		if merged.Bios.Settings == nil {
+			merged.Bios.Settings = maps.Clone(overlay.Bios.Settings)
+		} else {
+			maps.Copy(merged.Bios.Settings, overlay.Bios.Settings)
+		}
+	}
+	if !overlay.BiosFirmware.IsEmpty() {
+		merged.BiosFirmware = overlay.BiosFirmware
+	}
+	if !overlay.BmcFirmware.IsEmpty() {
+		merged.BmcFirmware = overlay.BmcFirmware
+	}
+	if overlay.BmcCredentialsSecret != "" {
+		merged.BmcCredentialsSecret = overlay.BmcCredentialsSecret
+	}
+}
+
+// GetDerivedHardwareProfiles returns the names of the HardwareProfiles that resolve through the given profile,
+// including the profile itself, so that a change to a base profile can be applied to the profiles built on it
+func GetDerivedHardwareProfiles(ctx context.Context, c client.Reader, namespace, name string) (map[string]bool, error) {
+	profiles := &pluginv1alpha1.HardwareProfileList{}
+	if err := c.List(ctx, profiles, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list HardwareProfiles: %w", err)
+	}
+
+	bases := make(map[string]string, len(profiles.Items))
+	for _, profile := range profiles.Items {
+		bases[profile.Name] = profile.Spec.BaseProfile
+	}
+
+	derived := map[string]bool{name: true}
+	for profile := range bases {
+		for next, depth := bases[profile], 0; next != "" && depth < maxHwProfileDepth; next, depth = bases[next], depth+1 {
+			if next == name {
+				derived[profile] = true
+				break
+			}
+		}
+	}
+
+	return derived, nil
+}
@@ -0,0 +1,191 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+var weekdays = map[pluginv1alpha1.Weekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// GetMaintenanceWindowStatus checks whether updates may be started at the given time, according to the maintenance
+// window of the HardwareManager. If the window is closed, the time it next opens is returned, or the zero time if it
+// never opens again.
+func GetMaintenanceWindowStatus(hwmgr *pluginv1alpha1.HardwareManager, now time.Time) (bool, time.Time, error) {
+	window := hwmgr.Spec.MaintenanceWindow
+	if window == nil {
+		return true, now, nil
+	}
+
+	from := now
+	if window.Start != nil && now.Before(window.Start.Time) {
+		from = window.Start.Time
+	}
+	if window.End != nil && !from.Before(window.End.Time) {
+		return false, time.Time{}, nil
+	}
+
+	if window.StartTime == "" {
+		// A one-off window, bounded by Start and End only
+		return from.Equal(now), from, nil
+	}
+
+	open, next, err := checkRecurringWindow(window, from)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if open {
+		return from.Equal(now), from, nil
+	}
+	if window.End != nil && !next.Before(window.End.Time) {
+		return false, time.Time{}, nil
+	}
+	return false, next, nil
+}
+
+// checkRecurringWindow checks whether the recurring window is open at the given time, returning the time it next
+// opens if not
+func checkRecurringWindow(window *pluginv1alpha1.MaintenanceWindow, t time.Time) (bool, time.Time, error) {
+	if window.Duration == nil || window.Duration.Duration <= 0 {
+		return false, time.Time{}, typederrors.NewInputError("maintenance window duration is required with startTime")
+	}
+
+	loc := time.UTC
+	if window.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(window.TimeZone); err != nil {
+			return false, time.Time{}, typederrors.NewInputError("invalid maintenance window timeZone %q: %s", window.TimeZone, err)
+		}
+	}
+
+	startTime, err := time.Parse("15:04", window.StartTime)
+	if err != nil {
+		return false, time.Time{}, typederrors.NewInputError("invalid maintenance window startTime %q: %s", window.StartTime, err)
+	}
+
+	var days []time.Weekday
+	for _, day := range window.Days {
+		weekday, exists := weekdays[day]
+		if !exists {
+			return false, time.Time{}, typederrors.NewInputError("invalid maintenance window day %q", day)
+		}
+		days = append(days, weekday)
+	}
+
+	// Look back far enough to catch a window opened on an earlier day that is still open, and ahead to the next
+	// opening within a week
+	local := t.In(loc)
+	lookback := int(window.Duration.Duration/(24*time.Hour)) + 1
+	for offset := -lookback; offset <= 7; offset++ {
+		// A start time skipped by a daylight saving transition is normalized to a time before it, so the window opens
+		// once the same time has passed after the transition instead
+		nominal := time.Date(local.Year(), local.Month(), local.Day()+offset,
+			startTime.Hour(), startTime.Minute(), 0, 0, time.UTC)
+		start := time.Date(nominal.Year(), nominal.Month(), nominal.Day(), nominal.Hour(), nominal.Minute(), 0, 0, loc)
+		wall := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+		if skipped := nominal.Sub(wall); skipped > 0 {
+			start = start.Add(skipped)
+		}
+		if len(days) > 0 && !slices.Contains(days, start.Weekday()) {
+			continue
+		}
+		if !t.Before(start) && t.Before(start.Add(window.Duration.Duration)) {
+			return true, t, nil
+		}
+		if start.After(t) {
+			return false, start, nil
+		}
+	}
+
+	return false, time.Time{}, nil
+}
+
+// setNodeWindowCondition sets the AwaitingWindow condition on a node, if it has changed
+func setNodeWindowCondition(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node,
+	status metav1.ConditionStatus, reason pluginv1alpha1.ConditionReason, message string) error {
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.AwaitingWindow)
+	condition := meta.FindStatusCondition(node.Status.Conditions, conditionType)
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) && ConditionMessage(condition) == message {
+		return nil
+	}
+
+	if err := SetNodeConditionStatus(ctx, c, node.Name, node.Namespace,
+		conditionType, status, string(reason), message); err != nil {
+		return fmt.Errorf("failed to set maintenance window condition on node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// ClearNodeAwaitingWindow marks a node that was held for the maintenance window as no longer waiting, when its update
+// is started
+func ClearNodeAwaitingWindow(ctx context.Context, c client.Client, node *hwmgmtv1alpha1.Node) error {
+	if meta.FindStatusCondition(node.Status.Conditions, string(pluginv1alpha1.ConditionTypes.AwaitingWindow)) == nil {
+		// The node was never held
+		return nil
+	}
+	return setNodeWindowCondition(ctx, c, node, metav1.ConditionFalse,
+		pluginv1alpha1.ConditionReasons.WindowOpen, "Maintenance window is open")
+}
+
+// HoldNodesForMaintenanceWindow checks the maintenance window of the HardwareManager before updates are started on the
+// given nodes. If the window is closed, the nodes are marked as awaiting the window, and the time to wait before
+// checking again is returned.
+func HoldNodesForMaintenanceWindow(
+	ctx context.Context,
+	c client.Client,
+	hwmgr *pluginv1alpha1.HardwareManager,
+	nodes []*hwmgmtv1alpha1.Node) (bool, time.Duration, error) {
+
+	if len(nodes) == 0 {
+		return false, 0, nil
+	}
+
+	open, next, err := GetMaintenanceWindowStatus(hwmgr, time.Now())
+	if err != nil {
+		return true, 0, fmt.Errorf("failed to check maintenance window of hardware manager %s: %w", hwmgr.Name, err)
+	}
+	if open {
+		return false, 0, nil
+	}
+
+	message := "Update held, as the maintenance window does not open again"
+	if !next.IsZero() {
+		message = fmt.Sprintf("Update held until the maintenance window opens at %s", next.UTC().Format(time.RFC3339))
+	}
+	for _, node := range nodes {
+		if err := setNodeWindowCondition(ctx, c, node, metav1.ConditionTrue,
+			pluginv1alpha1.ConditionReasons.WindowClosed, message); err != nil {
+			return true, 0, err
+		}
+	}
+
+	if next.IsZero() {
+		// Check again later, in case the window is changed
+		return true, RequeueWithLongInterval().RequeueAfter, nil
+	}
+	return true, time.Until(next), nil
+}
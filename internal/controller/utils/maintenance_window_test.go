@@ -0,0 +1,201 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+func TestGetMaintenanceWindowStatus(t *testing.T) {
+	utc := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("invalid time %s: %v", value, err)
+		}
+		return parsed
+	}
+	metaTime := func(value string) *metav1.Time {
+		parsed := metav1.NewTime(utc(value))
+		return &parsed
+	}
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+
+	// Opens every day at 22:00 UTC, closing at 02:00 the next day
+	overnight := &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: duration(4 * time.Hour)}
+	// Opens on Saturdays at 22:00 UTC, closing at 02:00 on Sunday
+	saturdayNight := &pluginv1alpha1.MaintenanceWindow{
+		Days:      []pluginv1alpha1.Weekday{"Sat"},
+		StartTime: "22:00",
+		Duration:  duration(4 * time.Hour),
+	}
+	// Opens at the start of Saturday, for the whole weekend
+	weekend := &pluginv1alpha1.MaintenanceWindow{
+		Days:      []pluginv1alpha1.Weekday{"Sat"},
+		StartTime: "00:00",
+		Duration:  duration(48 * time.Hour),
+	}
+	// Opens every day at 01:00 in New York, which moves to daylight saving time at 02:00 on 2026-03-08
+	newYork := &pluginv1alpha1.MaintenanceWindow{
+		StartTime: "01:00",
+		Duration:  duration(3 * time.Hour),
+		TimeZone:  "America/New_York",
+	}
+	// Opens every day at 02:30 in New York, which does not occur on 2026-03-08
+	newYorkGap := &pluginv1alpha1.MaintenanceWindow{
+		StartTime: "02:30",
+		Duration:  duration(time.Hour),
+		TimeZone:  "America/New_York",
+	}
+	oneOff := &pluginv1alpha1.MaintenanceWindow{
+		Start: metaTime("2026-10-17T10:00:00Z"),
+		End:   metaTime("2026-10-17T12:00:00Z"),
+	}
+
+	tests := []struct {
+		description string
+		window      *pluginv1alpha1.MaintenanceWindow
+		now         string
+		open        bool
+		// next is the time the window next opens, if closed, or empty if it never opens again
+		next string
+	}{
+		{description: "no window", now: "2026-10-17T09:00:00Z", open: true},
+
+		// 2026-10-16 is a Friday
+		{description: "overnight window before start", window: overnight, now: "2026-10-16T21:59:00Z",
+			next: "2026-10-16T22:00:00Z"},
+		{description: "overnight window at start", window: overnight, now: "2026-10-16T22:00:00Z", open: true},
+		{description: "overnight window before midnight", window: overnight, now: "2026-10-16T23:00:00Z", open: true},
+		{description: "overnight window after midnight", window: overnight, now: "2026-10-17T01:59:00Z", open: true},
+		{description: "overnight window at end", window: overnight, now: "2026-10-17T02:00:00Z",
+			next: "2026-10-17T22:00:00Z"},
+
+		{description: "Saturday window on Friday", window: saturdayNight, now: "2026-10-16T23:00:00Z",
+			next: "2026-10-17T22:00:00Z"},
+		{description: "Saturday window on Saturday", window: saturdayNight, now: "2026-10-17T23:00:00Z", open: true},
+		{description: "Saturday window continuing on Sunday", window: saturdayNight, now: "2026-10-18T01:00:00Z", open: true},
+		{description: "Saturday window at end on Sunday", window: saturdayNight, now: "2026-10-18T02:00:00Z",
+			next: "2026-10-24T22:00:00Z"},
+		{description: "weekend window on Sunday night", window: weekend, now: "2026-10-18T23:59:00Z", open: true},
+		{description: "weekend window on Monday", window: weekend, now: "2026-10-19T00:00:00Z",
+			next: "2026-10-24T00:00:00Z"},
+
+		{description: "time zone before the transition", window: newYork, now: "2026-03-07T17:00:00Z",
+			next: "2026-03-08T06:00:00Z"},
+		{description: "time zone across the transition", window: newYork, now: "2026-03-08T08:30:00Z", open: true},
+		{description: "time zone at end across the transition", window: newYork, now: "2026-03-08T09:00:00Z",
+			next: "2026-03-09T05:00:00Z"},
+		{description: "time zone start skipped by the transition", window: newYorkGap, now: "2026-03-08T06:45:00Z",
+			next: "2026-03-08T07:30:00Z"},
+		{description: "time zone window after the skipped start", window: newYorkGap, now: "2026-03-08T08:00:00Z", open: true},
+
+		{description: "one-off window before start", window: oneOff, now: "2026-10-17T09:00:00Z",
+			next: "2026-10-17T10:00:00Z"},
+		{description: "one-off window at start", window: oneOff, now: "2026-10-17T10:00:00Z", open: true},
+		{description: "one-off window before end", window: oneOff, now: "2026-10-17T11:59:00Z", open: true},
+		{description: "one-off window at end", window: oneOff, now: "2026-10-17T12:00:00Z"},
+
+		{
+			description: "recurring window before start",
+			window: &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: duration(4 * time.Hour),
+				Start: metaTime("2026-10-20T03:00:00Z")},
+			now:  "2026-10-17T23:00:00Z",
+			next: "2026-10-20T22:00:00Z",
+		},
+		{
+			description: "recurring window open at start",
+			window: &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: duration(4 * time.Hour),
+				Start: metaTime("2026-10-20T00:00:00Z")},
+			now:  "2026-10-17T23:00:00Z",
+			next: "2026-10-20T00:00:00Z",
+		},
+		{
+			description: "recurring window ending before the next opening",
+			window: &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: duration(4 * time.Hour),
+				End: metaTime("2026-10-16T21:30:00Z")},
+			now: "2026-10-16T21:00:00Z",
+		},
+		{
+			description: "recurring window after end",
+			window: &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: duration(4 * time.Hour),
+				End: metaTime("2026-10-17T00:00:00Z")},
+			now: "2026-10-17T01:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			hwmgr := &pluginv1alpha1.HardwareManager{
+				Spec: pluginv1alpha1.HardwareManagerSpec{MaintenanceWindow: test.window},
+			}
+			now := utc(test.now)
+
+			open, next, err := GetMaintenanceWindowStatus(hwmgr, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if open != test.open {
+				t.Errorf("expected the window to be open: %t", test.open)
+			}
+			switch {
+			case open:
+				if !next.Equal(now) {
+					t.Errorf("expected the current time for an open window, got %s", next)
+				}
+			case test.next == "":
+				if !next.IsZero() {
+					t.Errorf("expected the window never to open again, got %s", next)
+				}
+			case !next.Equal(utc(test.next)):
+				t.Errorf("expected the window to open next at %s, got %s", test.next, next.UTC().Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestCheckRecurringWindowInvalid(t *testing.T) {
+	tests := []struct {
+		description string
+		window      *pluginv1alpha1.MaintenanceWindow
+	}{
+		{
+			description: "missing duration",
+			window:      &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00"},
+		},
+		{
+			description: "invalid time zone",
+			window: &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", TimeZone: "Mars/Olympus_Mons",
+				Duration: &metav1.Duration{Duration: time.Hour}},
+		},
+		{
+			description: "invalid start time",
+			window:      &pluginv1alpha1.MaintenanceWindow{StartTime: "10pm", Duration: &metav1.Duration{Duration: time.Hour}},
+		},
+		{
+			description: "invalid day",
+			window: &pluginv1alpha1.MaintenanceWindow{StartTime: "22:00", Days: []pluginv1alpha1.Weekday{"Saturday"},
+				Duration: &metav1.Duration{Duration: time.Hour}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			if _, _, err := checkRecurringWindow(test.window, time.Now()); !typederrors.IsInputError(err) {
+				t.Errorf("expected an input error, got %v", err)
+			}
+		})
+	}
+}
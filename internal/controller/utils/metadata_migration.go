@@ -0,0 +1,194 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MetadataSchemaVersion is the version of the labels and annotations set by the plugin. It is incremented with
+	// each change to them that leaves existing objects to be migrated, with a migration to the new version.
+	MetadataSchemaVersion = 1
+
+	// MetadataSchemaAnnotation records the metadata schema version an object was last migrated to. An object without
+	// it predates the migrations.
+	MetadataSchemaAnnotation = "hwmgr-plugin.oran.openshift.io/metadata-schema"
+
+	// MetadataMigrationConfigMap records the outcome of the last metadata migration, in its status field
+	MetadataMigrationConfigMap = "oran-hwmgr-plugin-metadata-migration"
+
+	metadataMigrationStatusKey = "status"
+)
+
+// MetadataMigration converts the labels and annotations of an object set by an earlier plugin version to those of
+// the schema Version
+type MetadataMigration struct {
+	Version     int
+	Description string
+	Mutations   func(object client.Object) []MetaMutation
+}
+
+// RenameLabel migrates a label from its former key, keeping the value of the current key if both are set
+func RenameLabel(oldKey, newKey string) func(client.Object) []MetaMutation {
+	return func(object client.Object) []MetaMutation {
+		value, exists := object.GetLabels()[oldKey]
+		if !exists {
+			return nil
+		}
+		if _, current := object.GetLabels()[newKey]; current {
+			return []MetaMutation{RemoveLabel(oldKey)}
+		}
+		return []MetaMutation{AddLabel(newKey, value), RemoveLabel(oldKey)}
+	}
+}
+
+// RenameAnnotation migrates an annotation from its former key, keeping the value of the current key if both are set
+func RenameAnnotation(oldKey, newKey string) func(client.Object) []MetaMutation {
+	return func(object client.Object) []MetaMutation {
+		value, exists := object.GetAnnotations()[oldKey]
+		if !exists {
+			return nil
+		}
+		if _, current := object.GetAnnotations()[newKey]; current {
+			return []MetaMutation{RemoveAnnotation(oldKey)}
+		}
+		return []MetaMutation{AddAnnotation(newKey, value), RemoveAnnotation(oldKey)}
+	}
+}
+
+// NormalizeBoolLabel migrates a boolean label whose value was written in another form, such as True, to the value
+// "true", and removes it if false, as the label is only ever set to true
+func NormalizeBoolLabel(key string) func(client.Object) []MetaMutation {
+	return func(object client.Object) []MetaMutation {
+		value, exists := object.GetLabels()[key]
+		if !exists || value == "true" {
+			return nil
+		}
+		if set, err := strconv.ParseBool(value); err == nil && set {
+			return []MetaMutation{AddLabel(key, "true")}
+		}
+		return []MetaMutation{RemoveLabel(key)}
+	}
+}
+
+// GetMetadataSchemaVersion returns the metadata schema version of an object, which is 0 if it was never migrated
+func GetMetadataSchemaVersion(object client.Object) int {
+	version, err := strconv.Atoi(object.GetAnnotations()[MetadataSchemaAnnotation])
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// MetadataMigrationResult counts the objects of a kind checked by a metadata migration
+type MetadataMigrationResult struct {
+	Kind     string `json:"kind"`
+	Checked  int    `json:"checked"`
+	Migrated int    `json:"migrated"`
+	Failed   int    `json:"failed,omitempty"`
+}
+
+// MigrateObjectsMetadata applies the migrations newer than the metadata schema version of each listed object for
+// which selected returns true, then records the current version on the object. Objects already at the current
+// version are skipped. A failure to migrate an object is logged and counted, and it is migrated on the next start.
+func MigrateObjectsMetadata(ctx context.Context, c client.Client, logger *slog.Logger, kind string,
+	list client.ObjectList, selected func(client.Object) bool, migrations []MetadataMigration,
+	opts ...client.ListOption) (MetadataMigrationResult, error) {
+
+	result := MetadataMigrationResult{Kind: kind}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return result, fmt.Errorf("failed to list %s: %w", kind, err)
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return result, fmt.Errorf("failed to extract %s: %w", kind, err)
+	}
+
+	for _, item := range items {
+		object, ok := item.(client.Object)
+		if !ok || (selected != nil && !selected(object)) {
+			continue
+		}
+		result.Checked++
+
+		version := GetMetadataSchemaVersion(object)
+		if version >= MetadataSchemaVersion {
+			continue
+		}
+
+		var mutations []MetaMutation
+		var applied []string
+		for _, migration := range migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if changes := migration.Mutations(object); len(changes) > 0 {
+				mutations = append(mutations, changes...)
+				applied = append(applied, migration.Description)
+			}
+		}
+		mutations = append(mutations, AddAnnotation(MetadataSchemaAnnotation, strconv.Itoa(MetadataSchemaVersion)))
+
+		if err := UpdateObjectMetaWithRetry(ctx, c, object, mutations); err != nil {
+			logger.WarnContext(ctx, "Failed to migrate metadata", slog.String("kind", kind),
+				slog.String("name", object.GetName()), slog.String("namespace", object.GetNamespace()),
+				slog.String("error", err.Error()))
+			result.Failed++
+			continue
+		}
+
+		if len(applied) > 0 {
+			logger.InfoContext(ctx, "Migrated metadata", slog.String("kind", kind),
+				slog.String("name", object.GetName()), slog.String("namespace", object.GetNamespace()),
+				slog.Any("migrations", applied))
+		}
+		result.Migrated++
+	}
+
+	return result, nil
+}
+
+// MetadataMigrationStatus is the outcome of a metadata migration, as recorded in the MetadataMigrationConfigMap
+type MetadataMigrationStatus struct {
+	SchemaVersion int                       `json:"schemaVersion"`
+	CompletedAt   metav1.Time               `json:"completedAt"`
+	Results       []MetadataMigrationResult `json:"results"`
+	Errors        []string                  `json:"errors,omitempty"`
+}
+
+// RecordMetadataMigrationStatus records the outcome of a metadata migration in the MetadataMigrationConfigMap
+func RecordMetadataMigrationStatus(ctx context.Context, c client.Client, namespace string, status MetadataMigrationStatus) error {
+	data, err := yaml.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata migration status: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: MetadataMigrationConfigMap, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[metadataMigrationStatusKey] = string(data)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to record metadata migration status in configmap %s: %w", MetadataMigrationConfigMap, err)
+	}
+
+	return nil
+}
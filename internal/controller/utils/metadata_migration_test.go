@@ -0,0 +1,88 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestMetadataMigrations(t *testing.T) {
+	tests := []struct {
+		description         string
+		meta                metav1.ObjectMeta
+		migration           func(client.Object) []MetaMutation
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			description:    "rename label",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"old": "1"}},
+			migration:      RenameLabel("old", "new"),
+			expectedLabels: map[string]string{"new": "1"},
+		},
+		{
+			description:    "rename label keeps the current key",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"old": "1", "new": "2"}},
+			migration:      RenameLabel("old", "new"),
+			expectedLabels: map[string]string{"new": "2"},
+		},
+		{
+			description:         "rename annotation",
+			meta:                metav1.ObjectMeta{Annotations: map[string]string{"old": "1", "other": "2"}},
+			migration:           RenameAnnotation("old", "new"),
+			expectedAnnotations: map[string]string{"new": "1", "other": "2"},
+		},
+		{
+			description:         "rename annotation not set",
+			meta:                metav1.ObjectMeta{Annotations: map[string]string{"other": "2"}},
+			migration:           RenameAnnotation("old", "new"),
+			expectedAnnotations: map[string]string{"other": "2"},
+		},
+		{
+			description:    "normalize true label",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"flag": "True"}},
+			migration:      NormalizeBoolLabel("flag"),
+			expectedLabels: map[string]string{"flag": "true"},
+		},
+		{
+			description:    "normalize false label",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"flag": "False", "other": "1"}},
+			migration:      NormalizeBoolLabel("flag"),
+			expectedLabels: map[string]string{"other": "1"},
+		},
+		{
+			description:    "normalize label already current",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"flag": "true"}},
+			migration:      NormalizeBoolLabel("flag"),
+			expectedLabels: map[string]string{"flag": "true"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			obj := &corev1.ConfigMap{ObjectMeta: tc.meta}
+			if _, err := ApplyMetaMutations(obj, tc.migration(obj)...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.expectedLabels) != 0 || len(obj.Labels) != 0 {
+				if !reflect.DeepEqual(obj.Labels, tc.expectedLabels) {
+					t.Errorf("labels: expected %v, got %v", tc.expectedLabels, obj.Labels)
+				}
+			}
+			if len(tc.expectedAnnotations) != 0 || len(obj.Annotations) != 0 {
+				if !reflect.DeepEqual(obj.Annotations, tc.expectedAnnotations) {
+					t.Errorf("annotations: expected %v, got %v", tc.expectedAnnotations, obj.Annotations)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodeCordonAnnotation requests that the hardware backing a Node is cordoned: excluded from future allocations and
+// from hardware profile updates, while remaining allocated to the Node. Removing the annotation lifts the exclusion.
+const NodeCordonAnnotation = "hwmgr-plugin.oran.openshift.io/cordon"
+
+// IsNodeCordonRequested checks whether the hardware backing a Node has been cordoned
+func IsNodeCordonRequested(node *hwmgmtv1alpha1.Node) bool {
+	return node.GetAnnotations()[NodeCordonAnnotation] == "true"
+}
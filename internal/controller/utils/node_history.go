@@ -0,0 +1,115 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The Node status is defined by the O-Cloud Manager API, so the timeline of the transitions of a node is kept as a
+// JSON annotation on the Node, holding the most recent NodeHistoryLimit events.
+const (
+	NodeHistoryAnnotation = "hwmgr-plugin.oran.openshift.io/history"
+	NodeHistoryLimit      = 20
+
+	NodeEventAllocated               = "Allocated"
+	NodeEventProvisioned             = "Provisioned"
+	NodeEventBiosUpdateStarted       = "BiosUpdateStarted"
+	NodeEventBiosUpdateCompleted     = "BiosUpdateCompleted"
+	NodeEventBiosUpdateDeferred      = "BiosUpdateDeferred"
+	NodeEventFirmwareUpdateStarted   = "FirmwareUpdateStarted"
+	NodeEventFirmwareUpdateCompleted = "FirmwareUpdateCompleted"
+	NodeEventProfileUpdateStarted    = "ProfileUpdateStarted"
+	NodeEventProfileUpdateCompleted  = "ProfileUpdateCompleted"
+	NodeEventRolledBack              = "RolledBack"
+	NodeEventReinspectionStarted     = "ReinspectionStarted"
+	NodeEventReinspectionCompleted   = "ReinspectionCompleted"
+	NodeEventError                   = "Error"
+)
+
+// NodeEvent records a transition of a node
+type NodeEvent struct {
+	Time    metav1.Time `json:"time"`
+	Event   string      `json:"event"`
+	Message string      `json:"message,omitempty"`
+}
+
+// GetNodeHistory returns the events recorded on a node, oldest first
+func GetNodeHistory(node *hwmgmtv1alpha1.Node) ([]NodeEvent, error) {
+	data, exists := node.GetAnnotations()[NodeHistoryAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	var history []NodeEvent
+	if err := json.Unmarshal([]byte(data), &history); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation of node %s: %w", NodeHistoryAnnotation, node.Name, err)
+	}
+	return history, nil
+}
+
+// AddNodeEvent appends an event to the history of a node, dropping the oldest events beyond NodeHistoryLimit, and
+// records the phase it starts or completes. An event repeating the last one recorded is ignored, as the same transition
+// may be seen by more than one reconcile. The node is only modified in memory, to be saved along with the other changes
+// made by the caller.
+func AddNodeEvent(node *hwmgmtv1alpha1.Node, event, message string) {
+	// A corrupt annotation starts a new history, rather than blocking the transition
+	history, _ := GetNodeHistory(node)
+
+	if n := len(history); n > 0 && history[n-1].Event == event && history[n-1].Message == message {
+		return
+	}
+
+	now := metav1.Now()
+	updateNodeTimings(node, event, now)
+
+	history = append(history, NodeEvent{Time: now, Event: event, Message: message})
+	if len(history) > NodeHistoryLimit {
+		history = history[len(history)-NodeHistoryLimit:]
+	}
+
+	// The events only hold strings and times, so cannot fail to marshal
+	data, _ := json.Marshal(history)
+
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[NodeHistoryAnnotation] = string(data)
+	node.SetAnnotations(annotations)
+}
+
+// RecordNodeEvent appends an event to the history of the latest version of a node, retrying on conflict
+func RecordNodeEvent(ctx context.Context, c client.Client, nodename, namespace, event, message string) error {
+	// nolint: wrapcheck
+	if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		node := &hwmgmtv1alpha1.Node{}
+		if err := c.Get(ctx, types.NamespacedName{Name: nodename, Namespace: namespace}, node); err != nil {
+			return fmt.Errorf("failed to fetch Node: %w", err)
+		}
+
+		patch := client.MergeFromWithOptions(node.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		// The timings only change along with the history
+		before := node.GetAnnotations()[NodeHistoryAnnotation]
+		AddNodeEvent(node, event, message)
+		if node.GetAnnotations()[NodeHistoryAnnotation] == before {
+			return nil
+		}
+		return c.Patch(ctx, node, patch)
+	}); err != nil {
+		return fmt.Errorf("failed to record %s event on node %s: %w", event, nodename, err)
+	}
+	return nil
+}
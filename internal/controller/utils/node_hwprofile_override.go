@@ -0,0 +1,57 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+// NodeHwProfileOverrideAnnotation sets the HardwareProfile applied to a Node in place of the hwProfile of its node
+// group, such as to pilot new firmware on a single node. Removing the annotation returns the Node to the profile of
+// its node group.
+const NodeHwProfileOverrideAnnotation = "hwmgr-plugin.oran.openshift.io/hw-profile-override"
+
+// GetNodeHwProfileOverride returns the HardwareProfile override of a Node, or an empty string if it has none
+func GetNodeHwProfileOverride(node *hwmgmtv1alpha1.Node) string {
+	return strings.TrimSpace(node.GetAnnotations()[NodeHwProfileOverrideAnnotation])
+}
+
+// GetNodeTargetHwProfile returns the HardwareProfile to be applied to a Node, which is its override, if set, and
+// otherwise the hwProfile of its node group
+func GetNodeTargetHwProfile(node *hwmgmtv1alpha1.Node, groupHwProfile string) string {
+	if override := GetNodeHwProfileOverride(node); override != "" {
+		return override
+	}
+	return groupHwProfile
+}
+
+// FindNodesWithStaleHwProfile returns the nodes of the NodePool whose HardwareProfile differs from their target
+// profile, other than nodes already being updated. These are the nodes whose override has been set, changed or
+// removed since they were last configured, as a change to the hwProfile of a node group changes the generation of
+// the NodePool.
+func FindNodesWithStaleHwProfile(nodepool *hwmgmtv1alpha1.NodePool, nodelist *hwmgmtv1alpha1.NodeList) []*hwmgmtv1alpha1.Node {
+	groupHwProfiles := make(map[string]string)
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		groupHwProfiles[nodegroup.NodePoolData.Name] = nodegroup.NodePoolData.HwProfile
+	}
+
+	var nodes []*hwmgmtv1alpha1.Node
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		groupHwProfile, exists := groupHwProfiles[node.Spec.GroupName]
+		if !exists || GetJobId(node) != "" || GetConfigAnnotation(node) != "" || IsNodeCordonRequested(node) {
+			continue
+		}
+		if GetNodeTargetHwProfile(node, groupHwProfile) != node.Spec.HwProfile {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
@@ -0,0 +1,77 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name, group, hwProfile, override string) hwmgmtv1alpha1.Node {
+	node := hwmgmtv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       hwmgmtv1alpha1.NodeSpec{GroupName: group, HwProfile: hwProfile},
+		Status: hwmgmtv1alpha1.NodeStatus{Conditions: []metav1.Condition{{
+			Type:   string(hwmgmtv1alpha1.Configured),
+			Status: metav1.ConditionTrue,
+			Reason: string(hwmgmtv1alpha1.ConfigApplied),
+		}}},
+	}
+	if override != "" {
+		node.Annotations = map[string]string{NodeHwProfileOverrideAnnotation: override}
+	}
+	return node
+}
+
+func TestFindNodesToUpdateWithOverride(t *testing.T) {
+	nodelist := &hwmgmtv1alpha1.NodeList{Items: []hwmgmtv1alpha1.Node{
+		testNode("current", "worker", "profile-v2", ""),
+		testNode("stale", "worker", "profile-v1", ""),
+		testNode("pilot", "worker", "profile-v3", "profile-v3"),
+		testNode("pilot-pending", "worker", "profile-v1", "profile-v3"),
+		testNode("pilot-removed", "worker", "profile-v3", ""),
+		testNode("other-group", "master", "profile-v1", ""),
+	}}
+
+	var names []string
+	for _, node := range FindNodesToUpdate(nodelist, "worker", "profile-v2") {
+		names = append(names, node.Name)
+	}
+
+	expected := []string{"stale", "pilot-pending", "pilot-removed"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected nodes %v to be updated, got %v", expected, names)
+	}
+
+	if node := FindNextNodeToUpdate(nodelist, "worker", "profile-v3"); node == nil || node.Name != "current" {
+		t.Errorf("expected node current to be updated next, got %v", node)
+	}
+}
+
+func TestGetNodeTargetHwProfile(t *testing.T) {
+	tests := []struct {
+		description string
+		override    string
+		expected    string
+	}{
+		{description: "no override", expected: "group-profile"},
+		{description: "override", override: "pilot-profile", expected: "pilot-profile"},
+		{description: "blank override", override: " ", expected: "group-profile"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			node := testNode("node", "worker", "", test.override)
+			if profile := GetNodeTargetHwProfile(&node, "group-profile"); profile != test.expected {
+				t.Errorf("expected profile %s, got %s", test.expected, profile)
+			}
+		})
+	}
+}
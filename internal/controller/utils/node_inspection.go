@@ -0,0 +1,21 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeReinspectAnnotation requests a new inspection of the hardware backing a Node, to refresh the hardware details
+// reported for it after a hardware change. It is removed once the inspection has completed. On a NodePool, it requests
+// the inspection of all the nodes of the NodePool.
+const NodeReinspectAnnotation = "hwmgr-plugin.oran.openshift.io/reinspect"
+
+// IsReinspectionRequested checks whether a new inspection has been requested on a Node or NodePool
+func IsReinspectionRequested(object client.Object) bool {
+	return object.GetAnnotations()[NodeReinspectAnnotation] == "true"
+}
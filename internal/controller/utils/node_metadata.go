@@ -0,0 +1,63 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pluginAnnotationDomain is the domain of the annotations used by the plugin to drive the handling of a Node
+const pluginAnnotationDomain = "hwmgr-plugin.oran.openshift.io/"
+
+// SetNodeMetadata adds the labels and annotations of a NodeMetadata to a Node CR. Keys already set on the node are left
+// as they are, as are annotations in the plugin domain, so that the metadata cannot change how the node is handled.
+func SetNodeMetadata(node *hwmgmtv1alpha1.Node, metadata *pluginv1alpha1.NodeMetadata) {
+	if metadata == nil {
+		return
+	}
+
+	for key, value := range metadata.Labels {
+		if _, exists := node.Labels[key]; exists {
+			continue
+		}
+		if node.Labels == nil {
+			node.Labels = make(map[string]string)
+		}
+		node.Labels[key] = value
+	}
+
+	for key, value := range metadata.Annotations {
+		if strings.HasPrefix(key, pluginAnnotationDomain) {
+			continue
+		}
+		if _, exists := node.Annotations[key]; exists {
+			continue
+		}
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[key] = value
+	}
+}
+
+// ApplyHardwareManagerNodeMetadata adds the node metadata configured on the HardwareManager of a new Node CR to it
+func ApplyHardwareManagerNodeMetadata(ctx context.Context, c client.Reader, namespace string, node *hwmgmtv1alpha1.Node) error {
+	hwmgr := &pluginv1alpha1.HardwareManager{}
+	if err := c.Get(ctx, types.NamespacedName{Name: node.Spec.HwMgrId, Namespace: namespace}, hwmgr); err != nil {
+		return fmt.Errorf("failed to get HardwareManager %s: %w", node.Spec.HwMgrId, err)
+	}
+
+	SetNodeMetadata(node, hwmgr.Spec.NodeMetadata)
+	return nil
+}
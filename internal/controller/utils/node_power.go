@@ -0,0 +1,32 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"slices"
+)
+
+const (
+	// NodePowerActionAnnotation requests a power action on the hardware backing a Node. It is removed once the action
+	// has completed.
+	NodePowerActionAnnotation = "hwmgr-plugin.oran.openshift.io/power-action"
+
+	// PowerActionJobAnnotation tracks the hardware manager job applying a power action, as "<action>;<jobId>"
+	PowerActionJobAnnotation = "hwmgr-plugin.oran.openshift.io/power-action-job"
+
+	PowerActionOff    = "power-off"
+	PowerActionOn     = "power-on"
+	PowerActionReboot = "reboot"
+)
+
+// PowerActions are the supported values of the NodePowerActionAnnotation
+var PowerActions = []string{PowerActionOff, PowerActionOn, PowerActionReboot}
+
+// IsValidPowerAction checks whether the action is a supported NodePowerActionAnnotation value
+func IsValidPowerAction(action string) bool {
+	return slices.Contains(PowerActions, action)
+}
@@ -0,0 +1,234 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NodePoolRemoveNodesAnnotation requests that the nodes named in it, as a comma-separated list, be removed from a
+	// provisioned NodePool and their hardware released. The annotation is removed once the nodes have been removed.
+	NodePoolRemoveNodesAnnotation = "hwmgr-plugin.oran.openshift.io/remove-nodes"
+
+	// NodePoolRemovedNodesAnnotation records the nodes removed from each node group of a NodePool, so that they are not
+	// allocated again while the node group keeps the size it had when they were removed
+	NodePoolRemovedNodesAnnotation = "hwmgr-plugin.oran.openshift.io/removed-nodes"
+)
+
+// NodeRemoval is a node to be removed from its NodePool
+type NodeRemoval struct {
+	NodeName string
+
+	// Node is the Node CR, or nil if it has already been deleted
+	Node *hwmgmtv1alpha1.Node
+
+	// GroupName is the node group of a node whose Node CR has been deleted, if known to the adaptor. The node group of
+	// a node with a Node CR is taken from its spec.
+	GroupName string
+}
+
+// RemovedNodes are the nodes removed from a node group, and the size of the node group when they were removed
+type RemovedNodes struct {
+	Size  int      `json:"size"`
+	Nodes []string `json:"nodes"`
+}
+
+// getRemovedNodes returns the nodes removed from each node group of a NodePool, by group name. A corrupt annotation is
+// treated as empty, so that allocation falls back to the node group sizes rather than being blocked.
+func getRemovedNodes(nodepool *hwmgmtv1alpha1.NodePool) map[string]RemovedNodes {
+	removed := make(map[string]RemovedNodes)
+	if data, exists := nodepool.GetAnnotations()[NodePoolRemovedNodesAnnotation]; exists {
+		if err := json.Unmarshal([]byte(data), &removed); err != nil {
+			return make(map[string]RemovedNodes)
+		}
+	}
+	return removed
+}
+
+// GetNodeGroupAllocationSize returns the number of nodes to allocate to a node group: its size, less the nodes removed
+// from it since the size was last changed. The requester acknowledges the removals by reducing the size, after which
+// the recorded removals no longer apply.
+func GetNodeGroupAllocationSize(nodepool *hwmgmtv1alpha1.NodePool, group hwmgmtv1alpha1.NodeGroup) int {
+	removed, exists := getRemovedNodes(nodepool)[group.NodePoolData.Name]
+	if !exists || removed.Size != group.Size {
+		return group.Size
+	}
+	return max(group.Size-len(removed.Nodes), 0)
+}
+
+// recordNodeRemoval adds a node to the removed nodes of its node group. Removals recorded against an earlier size of
+// the node group are dropped. Recording a node more than once has no effect, so a removal can be completed again.
+func recordNodeRemoval(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, groupName, nodename string) error {
+	index := slices.IndexFunc(nodepool.Spec.NodeGroup, func(group hwmgmtv1alpha1.NodeGroup) bool {
+		return group.NodePoolData.Name == groupName
+	})
+	if index < 0 {
+		// The node group is no longer requested, so there is nothing to allocate it against
+		return nil
+	}
+	size := nodepool.Spec.NodeGroup[index].Size
+
+	removed := getRemovedNodes(nodepool)
+	group := removed[groupName]
+	if group.Size != size {
+		group = RemovedNodes{Size: size}
+	}
+	if slices.Contains(group.Nodes, nodename) {
+		return nil
+	}
+	group.Nodes = append(group.Nodes, nodename)
+	removed[groupName] = group
+
+	// The removed nodes only hold names and sizes, so cannot fail to marshal
+	data, _ := json.Marshal(removed)
+	if err := UpdateObjectMetaWithRetry(ctx, c, nodepool.DeepCopy(),
+		[]MetaMutation{AddAnnotation(NodePoolRemovedNodesAnnotation, string(data))}); err != nil {
+		return fmt.Errorf("failed to record removal of node %s on NodePool %s: %w", nodename, nodepool.Name, err)
+	}
+
+	annotations := nodepool.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[NodePoolRemovedNodesAnnotation] = string(data)
+	nodepool.SetAnnotations(annotations)
+	return nil
+}
+
+// getRequestedNodeRemovals returns the names of the nodes listed in the remove-nodes annotation of a NodePool
+func getRequestedNodeRemovals(nodepool *hwmgmtv1alpha1.NodePool) []string {
+	var names []string
+	for _, name := range strings.Split(nodepool.GetAnnotations()[NodePoolRemoveNodesAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsNodeRemovalRequested checks whether the deletion of a Node is a request to remove it from its NodePool, as the
+// provisioned NodePool still lists it. A Node deleted along with its NodePool, or by the plugin once removed from the
+// NodePool status, is not a removal request.
+func IsNodeRemovalRequested(nodepool *hwmgmtv1alpha1.NodePool, nodename string) bool {
+	return nodepool.DeletionTimestamp == nil && IsNodePoolProvisionedCompleted(nodepool) &&
+		slices.Contains(nodepool.Status.Properties.NodeNames, nodename)
+}
+
+// GetNodesToRemove returns the nodes of a provisioned NodePool that are to be removed, either named in the remove-nodes
+// annotation or whose Node CR has been deleted. As the cached node list may lag behind a newly created Node, a Node
+// missing from it is only taken as deleted once confirmed by the given reader.
+func GetNodesToRemove(
+	ctx context.Context,
+	c client.Reader,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	nodelist *hwmgmtv1alpha1.NodeList) ([]NodeRemoval, error) {
+
+	if !IsNodePoolProvisionedCompleted(nodepool) {
+		return nil, nil
+	}
+
+	requested := getRequestedNodeRemovals(nodepool)
+
+	var removals []NodeRemoval
+	for _, nodename := range nodepool.Status.Properties.NodeNames {
+		index := slices.IndexFunc(nodelist.Items, func(node hwmgmtv1alpha1.Node) bool { return node.Name == nodename })
+		if index >= 0 {
+			if slices.Contains(requested, nodename) {
+				removals = append(removals, NodeRemoval{NodeName: nodename, Node: &nodelist.Items[index]})
+			}
+			continue
+		}
+
+		node := &hwmgmtv1alpha1.Node{}
+		err := c.Get(ctx, types.NamespacedName{Name: nodename, Namespace: nodepool.Namespace}, node)
+		switch {
+		case errors.IsNotFound(err):
+			removals = append(removals, NodeRemoval{NodeName: nodename})
+		case err != nil:
+			return nil, fmt.Errorf("failed to get node %s: %w", nodename, err)
+		case slices.Contains(requested, nodename):
+			removals = append(removals, NodeRemoval{NodeName: nodename, Node: node})
+		}
+	}
+
+	return removals, nil
+}
+
+// CompleteNodeRemoval drops a node whose hardware has been released from the NodePool status, deleting its bmc-secret
+// and its Node CR if it still exists. The removal is recorded against the size of the node group of the node, if known,
+// so that the node is not allocated again until the requester changes the size.
+func CompleteNodeRemoval(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	removal NodeRemoval) error {
+
+	var location types.NamespacedName
+	if removal.Node != nil {
+		location = GetNodeBmcSecret(removal.Node)
+	} else {
+		var err error
+		if location, err = GetBmcSecretLocation(nodepool, removal.NodeName); err != nil {
+			return fmt.Errorf("failed to get bmc-secret name for node %s: %w", removal.NodeName, err)
+		}
+	}
+	if err := DeleteNodeBmcSecret(ctx, c, nodepool, location); err != nil {
+		return err
+	}
+
+	if removal.Node != nil {
+		if err := c.Delete(ctx, removal.Node); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete node %s: %w", removal.NodeName, err)
+		}
+	}
+
+	// Recorded before the node is dropped from the status, so that it is not allocated again if the update fails
+	groupName := removal.GroupName
+	if removal.Node != nil {
+		groupName = removal.Node.Spec.GroupName
+	}
+	if groupName != "" {
+		if err := recordNodeRemoval(ctx, c, nodepool, groupName, removal.NodeName); err != nil {
+			return err
+		}
+	}
+
+	nodepool.Status.Properties.NodeNames = slices.DeleteFunc(nodepool.Status.Properties.NodeNames, func(name string) bool {
+		return name == removal.NodeName
+	})
+	if err := UpdateNodePoolProperties(ctx, c, nodepool); err != nil {
+		return fmt.Errorf("failed to update status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
+
+// ClearNodeRemovalRequest removes the remove-nodes annotation from a provisioned NodePool once the nodes named in it
+// have been removed. Names that are not nodes of the NodePool are ignored. The annotation is kept on a NodePool that is
+// not provisioned, until it can be acted on.
+func ClearNodeRemovalRequest(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if _, exists := nodepool.GetAnnotations()[NodePoolRemoveNodesAnnotation]; !exists || !IsNodePoolProvisionedCompleted(nodepool) {
+		return nil
+	}
+
+	// The NodePool status is updated separately, so the latest version fetched by the patch is not kept
+	if err := UpdateObjectMetaWithRetry(ctx, c, nodepool.DeepCopy(),
+		[]MetaMutation{RemoveAnnotation(NodePoolRemoveNodesAnnotation)}); err != nil {
+		return fmt.Errorf("failed to remove %s annotation from NodePool %s: %w", NodePoolRemoveNodesAnnotation, nodepool.Name, err)
+	}
+	return nil
+}
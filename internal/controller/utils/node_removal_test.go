@@ -0,0 +1,144 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newNodeRemovalTestNodePool(size int, removedNodes string) *hwmgmtv1alpha1.NodePool {
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "oran-hwmgr-plugin", UID: "np1-uid"},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{NodePoolData: hwmgmtv1alpha1.NodePoolData{Name: "worker"}, Size: size},
+			},
+		},
+	}
+	if removedNodes != "" {
+		nodepool.Annotations = map[string]string{NodePoolRemovedNodesAnnotation: removedNodes}
+	}
+	return nodepool
+}
+
+func TestGetNodeGroupAllocationSize(t *testing.T) {
+	tests := []struct {
+		description  string
+		size         int
+		removedNodes string
+		expected     int
+	}{
+		{description: "no removals", size: 3, expected: 3},
+		{description: "removals at the current size", size: 3, removedNodes: `{"worker":{"size":3,"nodes":["node1"]}}`, expected: 2},
+		{description: "removals at an earlier size", size: 2, removedNodes: `{"worker":{"size":3,"nodes":["node1"]}}`, expected: 2},
+		{description: "removals from another group", size: 3, removedNodes: `{"master":{"size":3,"nodes":["node1"]}}`, expected: 3},
+		{
+			description:  "all nodes removed",
+			size:         1,
+			removedNodes: `{"worker":{"size":1,"nodes":["node1","node2"]}}`,
+			expected:     0,
+		},
+		{description: "corrupt annotation", size: 3, removedNodes: `{"worker":`, expected: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			nodepool := newNodeRemovalTestNodePool(test.size, test.removedNodes)
+			if actual := GetNodeGroupAllocationSize(nodepool, nodepool.Spec.NodeGroup[0]); actual != test.expected {
+				t.Errorf("expected allocation size %d, got %d", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompleteNodeRemovalRecordsRemoval(t *testing.T) {
+	tests := []struct {
+		description  string
+		size         int
+		removedNodes string
+		removal      NodeRemoval
+		expected     int
+	}{
+		{
+			description: "node with a Node CR",
+			size:        3,
+			removal: NodeRemoval{NodeName: "node1", Node: &hwmgmtv1alpha1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node1", Namespace: "oran-hwmgr-plugin"},
+				Spec:       hwmgmtv1alpha1.NodeSpec{GroupName: "worker"},
+			}},
+			expected: 2,
+		},
+		{
+			description: "deleted Node CR of a known group",
+			size:        3,
+			removal:     NodeRemoval{NodeName: "node1", GroupName: "worker"},
+			expected:    2,
+		},
+		{
+			description: "deleted Node CR of an unknown group",
+			size:        3,
+			removal:     NodeRemoval{NodeName: "node1"},
+			expected:    3,
+		},
+		{
+			description: "node of a group no longer requested",
+			size:        3,
+			removal:     NodeRemoval{NodeName: "node1", GroupName: "master"},
+			expected:    3,
+		},
+		{
+			description:  "node already recorded",
+			size:         3,
+			removedNodes: `{"worker":{"size":3,"nodes":["node1"]}}`,
+			removal:      NodeRemoval{NodeName: "node1", GroupName: "worker"},
+			expected:     2,
+		},
+		{
+			description:  "another node at the same size",
+			size:         3,
+			removedNodes: `{"worker":{"size":3,"nodes":["node2"]}}`,
+			removal:      NodeRemoval{NodeName: "node1", GroupName: "worker"},
+			expected:     1,
+		},
+		{
+			description:  "node removed after a resize",
+			size:         2,
+			removedNodes: `{"worker":{"size":3,"nodes":["node2"]}}`,
+			removal:      NodeRemoval{NodeName: "node1", GroupName: "worker"},
+			expected:     1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			nodepool := newNodeRemovalTestNodePool(test.size, test.removedNodes)
+			nodepool.Status.Properties.NodeNames = []string{"node1", "node2", "node3"}
+			c := newNodePoolStatusTestClient(t, nodepool)
+
+			if err := CompleteNodeRemoval(ctx, c, nodepool, test.removal); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			updated := &hwmgmtv1alpha1.NodePool{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), updated); err != nil {
+				t.Fatalf("failed to get NodePool: %v", err)
+			}
+			if actual := GetNodeGroupAllocationSize(updated, updated.Spec.NodeGroup[0]); actual != test.expected {
+				t.Errorf("expected allocation size %d, got %d", test.expected, actual)
+			}
+			if len(updated.Status.Properties.NodeNames) != 2 {
+				t.Errorf("expected the node to be dropped from the NodePool, got %v", updated.Status.Properties.NodeNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+)
+
+const (
+	// NodeReplaceAnnotation requests that the hardware backing a Node is replaced with other hardware from the same
+	// pool. The Node is removed from its NodePool once the replacement node has been allocated.
+	NodeReplaceAnnotation = "hwmgr-plugin.oran.openshift.io/replace"
+
+	// AutoReplaceFailedNodesExtension is the NodePool extension enabling the automatic replacement of nodes whose
+	// hardware has failed
+	AutoReplaceFailedNodesExtension = "autoReplaceFailedNodes"
+)
+
+// IsNodeReplacementRequested checks whether the replacement of a Node has been requested
+func IsNodeReplacementRequested(node *hwmgmtv1alpha1.Node) bool {
+	return node.GetAnnotations()[NodeReplaceAnnotation] == "true"
+}
+
+// IsAutoReplaceFailedNodesEnabled checks whether a NodePool has automatic replacement of failed nodes enabled
+func IsAutoReplaceFailedNodesEnabled(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.Spec.Extensions[AutoReplaceFailedNodesExtension] == "true"
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"slices"
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+const (
+	// NodeTagsAnnotation sets the tags of the hardware backing a Node, as a comma-separated list
+	NodeTagsAnnotation = "hwmgr-plugin.oran.openshift.io/tags"
+
+	// NodeAppliedTagsAnnotation records the tags last applied to the hardware from the NodeTagsAnnotation, so that
+	// tags removed from the annotation are removed from the hardware, while tags set by other means are kept
+	NodeAppliedTagsAnnotation = "hwmgr-plugin.oran.openshift.io/applied-tags"
+
+	// NodeTagsJobAnnotation tracks the hardware manager job applying the tags of a Node, as "<tags>;<jobId>"
+	NodeTagsJobAnnotation = "hwmgr-plugin.oran.openshift.io/tags-job"
+)
+
+// ParseNodeTags parses a comma-separated list of tags, returning the sorted tags without duplicates. Each tag must be
+// a valid label value, so that it can be stored by any adaptor.
+func ParseNodeTags(value string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(tag); len(errs) > 0 {
+			return nil, typederrors.NewInputError("invalid tag %q: %s", tag, strings.Join(errs, "; "))
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+	return slices.Compact(tags), nil
+}
+
+// JoinNodeTags formats tags as a NodeTagsAnnotation value
+func JoinNodeTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// GetNodeTagChanges returns the tags to add to and remove from the hardware backing a Node to bring it in line with
+// its NodeTagsAnnotation, along with the tags requested
+func GetNodeTagChanges(node *hwmgmtv1alpha1.Node) (tags, add, remove []string, err error) {
+	tags, err = ParseNodeTags(node.Annotations[NodeTagsAnnotation])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// The applied tags were validated when they were requested
+	applied, _ := ParseNodeTags(node.Annotations[NodeAppliedTagsAnnotation])
+
+	for _, tag := range tags {
+		if !slices.Contains(applied, tag) {
+			add = append(add, tag)
+		}
+	}
+	for _, tag := range applied {
+		if !slices.Contains(tags, tag) {
+			remove = append(remove, tag)
+		}
+	}
+
+	return tags, add, remove, nil
+}
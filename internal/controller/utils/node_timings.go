@@ -0,0 +1,139 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Unlike the history, which only holds the most recent events, the start and completion of each phase of a node are
+// kept for the life of the node, in their own annotation. The hardware vendor of the node is kept alongside, as set by
+// the adaptor on allocation, to break down the phase durations by vendor.
+const (
+	NodeTimingsAnnotation = "hwmgr-plugin.oran.openshift.io/timings"
+	NodeVendorAnnotation  = "hwmgr-plugin.oran.openshift.io/vendor"
+
+	// NodeTimingProvisioning runs from the allocation of the node until it is first provisioned
+	NodeTimingProvisioning   = "provisioning"
+	NodeTimingBiosUpdate     = "biosUpdate"
+	NodeTimingFirmwareUpdate = "firmwareUpdate"
+	NodeTimingProfileUpdate  = "profileUpdate"
+)
+
+// NodeTiming records when a phase of a node started and completed. A phase that is repeated, such as a day-2 BIOS
+// update, records its latest run.
+type NodeTiming struct {
+	StartedAt   *metav1.Time `json:"startedAt,omitempty"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// NodeTimings holds the timed phases of a node, by phase name
+type NodeTimings map[string]NodeTiming
+
+// nodeTimingEvent is the phase a history event starts or completes
+type nodeTimingEvent struct {
+	phase string
+	start bool
+}
+
+var nodeTimingEvents = map[string]nodeTimingEvent{
+	NodeEventAllocated:               {phase: NodeTimingProvisioning, start: true},
+	NodeEventProvisioned:             {phase: NodeTimingProvisioning},
+	NodeEventBiosUpdateStarted:       {phase: NodeTimingBiosUpdate, start: true},
+	NodeEventBiosUpdateCompleted:     {phase: NodeTimingBiosUpdate},
+	NodeEventFirmwareUpdateStarted:   {phase: NodeTimingFirmwareUpdate, start: true},
+	NodeEventFirmwareUpdateCompleted: {phase: NodeTimingFirmwareUpdate},
+	NodeEventProfileUpdateStarted:    {phase: NodeTimingProfileUpdate, start: true},
+	NodeEventProfileUpdateCompleted:  {phase: NodeTimingProfileUpdate},
+}
+
+// Duration returns how long a completed phase took
+func (p NodeTiming) Duration() (time.Duration, bool) {
+	if p.StartedAt == nil || p.CompletedAt == nil {
+		return 0, false
+	}
+	return p.CompletedAt.Sub(p.StartedAt.Time), true
+}
+
+// GetNodeTimings returns the phases recorded on a node
+func GetNodeTimings(node *hwmgmtv1alpha1.Node) (NodeTimings, error) {
+	data, exists := node.GetAnnotations()[NodeTimingsAnnotation]
+	if !exists {
+		return NodeTimings{}, nil
+	}
+	phases := NodeTimings{}
+	if err := json.Unmarshal([]byte(data), &phases); err != nil {
+		return NodeTimings{}, fmt.Errorf("failed to parse %s annotation of node %s: %w", NodeTimingsAnnotation, node.Name, err)
+	}
+	return phases, nil
+}
+
+// updateNodeTimings records the start or completion of the phase of a history event. A phase is only completed once
+// per start, so that an event seen again by a later reconcile does not move the completion time.
+func updateNodeTimings(node *hwmgmtv1alpha1.Node, event string, now metav1.Time) {
+	phaseEvent, exists := nodeTimingEvents[event]
+	if !exists {
+		return
+	}
+
+	// A corrupt annotation starts new timings, rather than blocking the transition
+	phases, _ := GetNodeTimings(node)
+	phase := phases[phaseEvent.phase]
+	switch {
+	case phaseEvent.start:
+		phase = NodeTiming{StartedAt: &now}
+	case phase.StartedAt != nil && phase.CompletedAt == nil:
+		phase.CompletedAt = &now
+	default:
+		return
+	}
+	phases[phaseEvent.phase] = phase
+
+	// The phases only hold times, so cannot fail to marshal
+	data, _ := json.Marshal(phases)
+
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[NodeTimingsAnnotation] = string(data)
+	node.SetAnnotations(annotations)
+}
+
+// CompletedNodeTimings returns the duration of each phase completed between two versions of the phases of a node
+func CompletedNodeTimings(before, after NodeTimings) map[string]time.Duration {
+	completed := make(map[string]time.Duration)
+	for name, phase := range after {
+		duration, done := phase.Duration()
+		if !done {
+			continue
+		}
+		if previous, exists := before[name]; exists && previous.CompletedAt != nil && previous.CompletedAt.Equal(phase.CompletedAt) {
+			continue
+		}
+		completed[name] = duration
+	}
+	return completed
+}
+
+// SetNodeVendor records the hardware vendor of a node, if known. The node is only modified in memory.
+func SetNodeVendor(node *hwmgmtv1alpha1.Node, vendor string) {
+	if vendor == "" {
+		return
+	}
+	annotations := node.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[NodeVendorAnnotation] = vendor
+	node.SetAnnotations(annotations)
+}
@@ -0,0 +1,93 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddNodeEventTimings(t *testing.T) {
+	node := &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+
+	AddNodeEvent(node, NodeEventAllocated, "allocated")
+	AddNodeEvent(node, NodeEventBiosUpdateStarted, "profile-v1")
+	AddNodeEvent(node, NodeEventBiosUpdateCompleted, "profile-v1")
+	AddNodeEvent(node, NodeEventProvisioned, "profile-v1")
+	AddNodeEvent(node, NodeEventFirmwareUpdateStarted, "profile-v2")
+
+	timings, err := GetNodeTimings(node)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, phase := range []string{NodeTimingProvisioning, NodeTimingBiosUpdate} {
+		if _, done := timings[phase].Duration(); !done {
+			t.Errorf("expected phase %s to be completed, got %+v", phase, timings[phase])
+		}
+	}
+	if timing := timings[NodeTimingFirmwareUpdate]; timing.StartedAt == nil || timing.CompletedAt != nil {
+		t.Errorf("expected phase %s to be in progress, got %+v", NodeTimingFirmwareUpdate, timing)
+	}
+	if _, exists := timings[NodeTimingProfileUpdate]; exists {
+		t.Errorf("expected no %s phase", NodeTimingProfileUpdate)
+	}
+}
+
+func TestCompletedNodeTimings(t *testing.T) {
+	start := metav1.NewTime(time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(20 * time.Minute))
+	later := metav1.NewTime(start.Add(time.Hour))
+
+	tests := []struct {
+		description string
+		before      NodeTimings
+		after       NodeTimings
+		expected    map[string]time.Duration
+	}{
+		{
+			description: "phase completed",
+			before:      NodeTimings{NodeTimingBiosUpdate: {StartedAt: &start}},
+			after:       NodeTimings{NodeTimingBiosUpdate: {StartedAt: &start, CompletedAt: &end}},
+			expected:    map[string]time.Duration{NodeTimingBiosUpdate: 20 * time.Minute},
+		},
+		{
+			description: "phase already completed",
+			before: NodeTimings{
+				NodeTimingProvisioning: {StartedAt: &start, CompletedAt: &end},
+			},
+			after: NodeTimings{
+				NodeTimingProvisioning: {StartedAt: &start, CompletedAt: &end},
+				NodeTimingBiosUpdate:   {StartedAt: &later},
+			},
+			expected: map[string]time.Duration{},
+		},
+		{
+			description: "phase repeated",
+			before:      NodeTimings{NodeTimingBiosUpdate: {StartedAt: &start, CompletedAt: &end}},
+			after:       NodeTimings{NodeTimingBiosUpdate: {StartedAt: &end, CompletedAt: &later}},
+			expected:    map[string]time.Duration{NodeTimingBiosUpdate: 40 * time.Minute},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			completed := CompletedNodeTimings(test.before, test.after)
+			if len(completed) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, completed)
+			}
+			for phase, duration := range test.expected {
+				if completed[phase] != duration {
+					t.Errorf("expected %s to take %s, got %s", phase, duration, completed[phase])
+				}
+			}
+		})
+	}
+}
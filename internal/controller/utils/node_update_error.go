@@ -0,0 +1,69 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeUpdateErrorAnnotation records, as JSON, the remediation of a day-2 update of a Node whose hardware was found in
+// an error state. It is cleared when the next update of the Node is started.
+const NodeUpdateErrorAnnotation = "hwmgr-plugin.oran.openshift.io/update-error"
+
+// Outcomes of the remediation of a Node update error
+const (
+	NodeUpdateErrorRetrying = "Retrying"
+	NodeUpdateErrorSkipped  = "Skipped"
+	NodeUpdateErrorAborted  = "Aborted"
+)
+
+// NodeUpdateError describes the remediation of an update error of a Node
+type NodeUpdateError struct {
+	// HwProfile is the hardware profile the update was applying
+	HwProfile string       `json:"hwProfile"`
+	Outcome   string       `json:"outcome"`
+	Retries   int          `json:"retries"`
+	LastRetry *metav1.Time `json:"lastRetry,omitempty"`
+	Message   string       `json:"message,omitempty"`
+}
+
+// GetNodeUpdateError returns the remediation of the update error of a Node, or nil if there is none
+func GetNodeUpdateError(node *hwmgmtv1alpha1.Node) (*NodeUpdateError, error) {
+	data, exists := node.GetAnnotations()[NodeUpdateErrorAnnotation]
+	if !exists {
+		return nil, nil
+	}
+
+	updateError := &NodeUpdateError{}
+	if err := json.Unmarshal([]byte(data), updateError); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation of node %s: %w", NodeUpdateErrorAnnotation, node.Name, err)
+	}
+	return updateError, nil
+}
+
+// getNodeUpdateErrorOutcome returns the outcome of the update error of a Node for its current hardware profile
+func getNodeUpdateErrorOutcome(node *hwmgmtv1alpha1.Node) string {
+	updateError, err := GetNodeUpdateError(node)
+	if err != nil || updateError == nil || updateError.HwProfile != node.Spec.HwProfile {
+		return ""
+	}
+	return updateError.Outcome
+}
+
+// IsNodeUpdateSkipped checks whether the update of a Node to its hardware profile was skipped after an error
+func IsNodeUpdateSkipped(node *hwmgmtv1alpha1.Node) bool {
+	return getNodeUpdateErrorOutcome(node) == NodeUpdateErrorSkipped
+}
+
+// IsNodeUpdateAborted checks whether the update of a Node to its hardware profile aborted the rollout of its NodePool
+func IsNodeUpdateAborted(node *hwmgmtv1alpha1.Node) bool {
+	return getNodeUpdateErrorOutcome(node) == NodeUpdateErrorAborted
+}
@@ -24,8 +24,14 @@ import (
 const (
 	HwMgrNodeId         = "hwmgrNodeId"
 	NodeSpecNodePoolKey = "spec.nodePool"
+	BmcSecretSuffix     = "-bmc-secret"
 )
 
+// BmcSecretName returns the name of the bmc-secret created for a node
+func BmcSecretName(nodename string) string {
+	return nodename + BmcSecretSuffix
+}
+
 // GetNode get a node resource for a provided name
 func GetNode(
 	ctx context.Context,
@@ -93,14 +99,15 @@ func FindNodeUpdateInProgress(nodelist *hwmgmtv1alpha1.NodeList) *hwmgmtv1alpha1
 	return nil
 }
 
-// FindNextNodeToUpdate scans the nodelist to find the first node with stale HwProfile
+// FindNextNodeToUpdate scans the nodelist to find the first node with stale HwProfile. The target profile of each node
+// is the newHwProfile of its group, unless overridden on the node.
 func FindNextNodeToUpdate(nodelist *hwmgmtv1alpha1.NodeList, groupname, newHwProfile string) *hwmgmtv1alpha1.Node {
 	for _, node := range nodelist.Items {
 		if groupname != node.Spec.GroupName {
 			continue
 		}
 
-		if newHwProfile != node.Spec.HwProfile {
+		if GetNodeTargetHwProfile(&node, newHwProfile) != node.Spec.HwProfile {
 			return &node
 		}
 
@@ -115,6 +122,32 @@ func FindNextNodeToUpdate(nodelist *hwmgmtv1alpha1.NodeList, groupname, newHwPro
 	return nil
 }
 
+// FindNodesToUpdate scans the nodelist to find all nodes in the group with a stale HwProfile, as compared to the
+// target profile of each node
+func FindNodesToUpdate(nodelist *hwmgmtv1alpha1.NodeList, groupname, newHwProfile string) []*hwmgmtv1alpha1.Node {
+	var nodes []*hwmgmtv1alpha1.Node
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		if groupname != node.Spec.GroupName {
+			continue
+		}
+
+		if GetNodeTargetHwProfile(node, newHwProfile) != node.Spec.HwProfile {
+			nodes = append(nodes, node)
+			continue
+		}
+
+		// Profile is already set — but check if it failed due to invalid inputs
+		cond := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+		if cond == nil || cond.Reason == string(hwmgmtv1alpha1.InvalidInput) {
+			// retry this node
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
 // FindNodeInProgress scans the nodelist to find the first node in InProgress
 func FindNodeInProgress(nodelist *hwmgmtv1alpha1.NodeList) *hwmgmtv1alpha1.Node {
 	for _, node := range nodelist.Items {
@@ -157,6 +190,7 @@ func SetNodeConditionStatus(
 		}
 
 		SetStatusCondition(
+			ctx,
 			&node.Status.Conditions,
 			conditionType,
 			reason,
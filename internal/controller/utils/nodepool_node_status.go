@@ -0,0 +1,158 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The NodePool status is defined by the O-Cloud Manager API, so the per-node status table is published as a JSON
+// annotation on the NodePool.
+const (
+	NodeStatusAnnotation = "hwmgr-plugin.oran.openshift.io/node-status"
+
+	NodePhaseAllocating   = "Allocating"
+	NodePhaseProvisioning = "Provisioning"
+	NodePhaseProvisioned  = "Provisioned"
+	NodePhaseConfiguring  = "Configuring"
+	NodePhaseFailed       = "Failed"
+	NodePhaseConfigFailed = "ConfigurationFailed"
+	NodePhaseReplacing    = "Replacing"
+)
+
+// NodePoolNodeStatus describes the provisioning progress of a single node of a NodePool
+type NodePoolNodeStatus struct {
+	Name        string `json:"name"`
+	HwMgrNodeId string `json:"hwMgrNodeId"`
+	GroupName   string `json:"groupName,omitempty"`
+	Phase       string `json:"phase"`
+	LastError   string `json:"lastError,omitempty"`
+}
+
+// failedConditionMessage returns the message of the most recent condition of a node reporting a failure
+func failedConditionMessage(node *hwmgmtv1alpha1.Node) string {
+	var failed *metav1.Condition
+	for i := range node.Status.Conditions {
+		cond := &node.Status.Conditions[i]
+		if !IsConditionFailed(cond) {
+			continue
+		}
+		if failed == nil || cond.LastTransitionTime.After(failed.LastTransitionTime.Time) {
+			failed = cond
+		}
+	}
+	if failed == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", failed.Type, ConditionMessage(failed))
+}
+
+// GetNodePhase derives the phase of a node from its conditions and annotations
+func GetNodePhase(node *hwmgmtv1alpha1.Node) string {
+	provisioned := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+	configured := meta.FindStatusCondition(node.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+
+	switch {
+	case IsNodeReplacementRequested(node):
+		return NodePhaseReplacing
+	case provisioned == nil:
+		return NodePhaseAllocating
+	case IsConditionFailed(provisioned):
+		return NodePhaseFailed
+	case IsConditionFailed(configured):
+		return NodePhaseConfigFailed
+	case GetConfigAnnotation(node) != "",
+		configured != nil && configured.Reason == string(hwmgmtv1alpha1.ConfigUpdate):
+		return NodePhaseConfiguring
+	case provisioned.Status != metav1.ConditionTrue:
+		return NodePhaseProvisioning
+	}
+	return NodePhaseProvisioned
+}
+
+// BuildNodePoolNodeStatus builds the per-node status table of a NodePool from its child nodes, sorted by node name
+func BuildNodePoolNodeStatus(nodelist *hwmgmtv1alpha1.NodeList) []NodePoolNodeStatus {
+	table := make([]NodePoolNodeStatus, 0, len(nodelist.Items))
+	for i := range nodelist.Items {
+		node := &nodelist.Items[i]
+		table = append(table, NodePoolNodeStatus{
+			Name:        node.Name,
+			HwMgrNodeId: node.Spec.HwMgrNodeId,
+			GroupName:   node.Spec.GroupName,
+			Phase:       GetNodePhase(node),
+			LastError:   failedConditionMessage(node),
+		})
+	}
+	slices.SortFunc(table, func(a, b NodePoolNodeStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return table
+}
+
+// GetNodePoolNodeStatus returns the per-node status table published on a NodePool, if any
+func GetNodePoolNodeStatus(nodepool *hwmgmtv1alpha1.NodePool) ([]NodePoolNodeStatus, error) {
+	data, exists := nodepool.GetAnnotations()[NodeStatusAnnotation]
+	if !exists {
+		return nil, nil
+	}
+	var table []NodePoolNodeStatus
+	if err := json.Unmarshal([]byte(data), &table); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", NodeStatusAnnotation, err)
+	}
+	return table, nil
+}
+
+// UpdateNodePoolNodeStatus publishes the per-node status table on the NodePool, if it has changed
+func UpdateNodePoolNodeStatus(
+	ctx context.Context,
+	c client.Client,
+	logger *slog.Logger,
+	nodepool *hwmgmtv1alpha1.NodePool) error {
+
+	nodelist, err := GetChildNodes(ctx, logger, c, nodepool)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(BuildNodePoolNodeStatus(nodelist))
+	if err != nil {
+		return fmt.Errorf("failed to marshal node status for NodePool %s: %w", nodepool.Name, err)
+	}
+
+	// nolint: wrapcheck
+	if err := retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		updatedNodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), updatedNodepool); err != nil {
+			return err
+		}
+		if updatedNodepool.GetAnnotations()[NodeStatusAnnotation] == string(data) {
+			return nil
+		}
+		patch := client.MergeFrom(updatedNodepool.DeepCopy())
+		if updatedNodepool.Annotations == nil {
+			updatedNodepool.Annotations = make(map[string]string)
+		}
+		updatedNodepool.Annotations[NodeStatusAnnotation] = string(data)
+		return c.Patch(ctx, updatedNodepool, patch)
+	}); err != nil {
+		return fmt.Errorf("failed to set node status on NodePool %s: %w", nodepool.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// NodePoolPauseAnnotation requests that the adaptors stop making changes for a NodePool, such as allocating hardware,
+// applying hardware profiles, or submitting jobs to the hardware manager, until the annotation is removed
+const NodePoolPauseAnnotation = "hwmgr-plugin.oran.openshift.io/pause"
+
+// IsNodePoolPaused checks whether the NodePool is annotated to pause its handling
+func IsNodePoolPaused(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.GetAnnotations()[NodePoolPauseAnnotation] == "true"
+}
+
+// IsNodeNodePoolPaused checks whether the NodePool that owns the Node is paused. A Node whose NodePool no longer
+// exists is not paused.
+func IsNodeNodePoolPaused(ctx context.Context, c client.Reader, node *hwmgmtv1alpha1.Node) (bool, error) {
+	for _, ref := range node.GetOwnerReferences() {
+		if ref.Kind != "NodePool" {
+			continue
+		}
+
+		nodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: node.Namespace}, nodepool); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to get NodePool %s of node %s: %w", ref.Name, node.Name, err)
+		}
+		return IsNodePoolPaused(nodepool), nil
+	}
+	return false, nil
+}
+
+// UpdateNodePoolPausedCondition reports whether the handling of the NodePool is paused in the Paused condition. The
+// condition is only written when it changes, and is only cleared if it was set.
+func UpdateNodePoolPausedCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool,
+	paused bool) error {
+
+	conditionType := string(pluginv1alpha1.ConditionTypes.Paused)
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, conditionType)
+
+	status := metav1.ConditionTrue
+	reason := pluginv1alpha1.ConditionReasons.PauseRequested
+	message := fmt.Sprintf("Paused by the %s annotation", NodePoolPauseAnnotation)
+	if !paused {
+		if condition == nil || condition.Status == metav1.ConditionFalse {
+			return nil
+		}
+		status = metav1.ConditionFalse
+		reason = pluginv1alpha1.ConditionReasons.Resumed
+		message = "Resumed"
+	}
+
+	if condition != nil && condition.Status == status && condition.Reason == string(reason) {
+		return nil
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		hwmgmtv1alpha1.ConditionType(conditionType), hwmgmtv1alpha1.ConditionReason(reason), status, message)
+}
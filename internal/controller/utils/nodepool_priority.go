@@ -0,0 +1,47 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodePoolPriorityExtension is the NodePool extension setting the priority of the NodePool when competing with other
+// NodePools for the hardware of a resource pool. NodePools without a priority have a priority of 0.
+const NodePoolPriorityExtension = "priority"
+
+// GetNodePoolPriority returns the priority of a NodePool
+func GetNodePoolPriority(nodepool *hwmgmtv1alpha1.NodePool) (int32, error) {
+	value, exists := nodepool.Spec.Extensions[NodePoolPriorityExtension]
+	if !exists || value == "" {
+		return 0, nil
+	}
+
+	priority, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s extension %q: must be an integer", NodePoolPriorityExtension, value)
+	}
+	return int32(priority), nil
+}
+
+// IsNodePoolAllocationPending checks whether a NodePool has yet to be allocated all of its hardware, either because it
+// has not been handed off to the adaptor, or because the adaptor is still allocating it. A NodePool holding a completed
+// reservation already has its hardware.
+func IsNodePoolAllocationPending(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	if !nodepool.DeletionTimestamp.IsZero() || IsNodePoolDryRun(nodepool) ||
+		(IsNodePoolReservation(nodepool) && IsNodePoolReserved(nodepool)) {
+		return false
+	}
+
+	provisioned := GetNodePoolProvisionedCondition(nodepool)
+	return provisioned == nil ||
+		(provisioned.Status == metav1.ConditionFalse && provisioned.Reason == string(hwmgmtv1alpha1.InProgress))
+}
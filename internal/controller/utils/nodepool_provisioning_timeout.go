@@ -0,0 +1,66 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"strconv"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+// NodePool extensions overriding the provisioning timeout of the hardware manager
+const (
+	ProvisioningTimeoutKey          = "provisioningTimeout"
+	ReleaseOnProvisioningTimeoutKey = "releaseOnProvisioningTimeout"
+
+	// ProvisioningReleasedAnnotation records that the hardware allocated to a NodePool that timed out was released
+	ProvisioningReleasedAnnotation = "hwmgr-plugin.oran.openshift.io/provisioning-released"
+)
+
+// ProvisioningTimeout is the deadline for provisioning a NodePool. A zero Timeout does not limit provisioning.
+type ProvisioningTimeout struct {
+	Timeout          time.Duration
+	ReleaseOnTimeout bool
+}
+
+// GetProvisioningTimeout returns the provisioning timeout of a NodePool, taking each setting from the NodePool
+// extensions if provided, or else from the hardware manager
+func GetProvisioningTimeout(hwmgr *pluginv1alpha1.HardwareManager, nodepool *hwmgmtv1alpha1.NodePool) (ProvisioningTimeout, error) {
+	var timeout ProvisioningTimeout
+	if hwmgr != nil && hwmgr.Spec.ProvisioningTimeout != nil {
+		timeout.Timeout = hwmgr.Spec.ProvisioningTimeout.Timeout.Duration
+		timeout.ReleaseOnTimeout = hwmgr.Spec.ProvisioningTimeout.ReleaseOnTimeout
+	}
+
+	extensions := nodepool.Spec.Extensions
+	if value, exists := extensions[ProvisioningTimeoutKey]; exists {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return timeout, typederrors.NewInputError("invalid %s extension %q: must be a non-negative duration", ProvisioningTimeoutKey, value)
+		}
+		timeout.Timeout = d
+	}
+	if value, exists := extensions[ReleaseOnProvisioningTimeoutKey]; exists {
+		release, err := strconv.ParseBool(value)
+		if err != nil {
+			return timeout, typederrors.NewInputError("invalid %s extension %q: must be a boolean", ReleaseOnProvisioningTimeoutKey, value)
+		}
+		timeout.ReleaseOnTimeout = release
+	}
+
+	return timeout, nil
+}
+
+// IsNodePoolProvisioningTimedOut checks whether provisioning of the NodePool was failed for exceeding its deadline
+func IsNodePoolProvisioningTimedOut(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	condition := GetNodePoolProvisionedCondition(nodepool)
+	return condition != nil && condition.Reason == string(hwmgmtv1alpha1.TimedOut)
+}
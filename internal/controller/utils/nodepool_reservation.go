@@ -0,0 +1,57 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+)
+
+// NodePoolReserveAnnotation requests that the hardware for a NodePool be claimed without creating its nodes, holding
+// the capacity until the annotation is removed to activate the NodePool
+const NodePoolReserveAnnotation = "hwmgr-plugin.oran.openshift.io/reserve"
+
+// IsNodePoolReservation checks whether the NodePool is annotated to only reserve its hardware. As with a dry run, the
+// annotation applies until the NodePool has been handed off for provisioning.
+func IsNodePoolReservation(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.GetAnnotations()[NodePoolReserveAnnotation] == "true" && GetNodePoolProvisionedCondition(nodepool) == nil
+}
+
+// IsNodePoolReserved checks whether all the hardware for the NodePool has been reserved
+func IsNodePoolReserved(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(pluginv1alpha1.ConditionTypes.Reserved))
+}
+
+// UpdateNodePoolReservedCondition reports the progress of the NodePool reservation in the Reserved condition
+func UpdateNodePoolReservedCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool,
+	reserveErr error, reserved bool, detail string) error {
+	conditionReason := hwmgmtv1alpha1.InProgress
+	conditionStatus := metav1.ConditionFalse
+	message := "Reserving hardware"
+
+	switch {
+	case reserveErr != nil:
+		conditionReason = hwmgmtv1alpha1.Failed
+		message = "Reservation failed: " + reserveErr.Error()
+	case reserved:
+		conditionReason = hwmgmtv1alpha1.Completed
+		conditionStatus = metav1.ConditionTrue
+		message = "Hardware reserved"
+	}
+	if reserveErr == nil && detail != "" {
+		message += ": " + detail
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		hwmgmtv1alpha1.ConditionType(pluginv1alpha1.ConditionTypes.Reserved), conditionReason, conditionStatus, message)
+}
@@ -0,0 +1,141 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodePoolStatusMutation applies a status change to a NodePool
+type nodePoolStatusMutation func(nodepool *hwmgmtv1alpha1.NodePool)
+
+type nodePoolStatusBatchKey struct{}
+
+// nodePoolStatusBatch collects the NodePool status changes made during a reconcile, so that they are written in a
+// single patch per NodePool by FlushNodePoolStatus rather than each in its own update
+type nodePoolStatusBatch struct {
+	mu      sync.Mutex
+	order   []types.NamespacedName
+	pending map[types.NamespacedName][]nodePoolStatusMutation
+
+	// generations are the generations of the NodePools as read by the reconcile, which are the ones observed, even if
+	// the spec has since changed
+	generations map[types.NamespacedName]int64
+}
+
+// WithNodePoolStatusBatch returns a context in which the NodePool status update functions defer their writes until
+// FlushNodePoolStatus is called with it. The generation of the NodePool read by the reconcile is recorded, to be
+// reported as observed.
+func WithNodePoolStatusBatch(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) context.Context {
+	return context.WithValue(ctx, nodePoolStatusBatchKey{}, &nodePoolStatusBatch{
+		pending:     make(map[types.NamespacedName][]nodePoolStatusMutation),
+		generations: map[types.NamespacedName]int64{client.ObjectKeyFromObject(nodepool): nodepool.Generation},
+	})
+}
+
+func getNodePoolStatusBatch(ctx context.Context) *nodePoolStatusBatch {
+	batch, _ := ctx.Value(nodePoolStatusBatchKey{}).(*nodePoolStatusBatch)
+	return batch
+}
+
+func (b *nodePoolStatusBatch) add(key types.NamespacedName, mutation nodePoolStatusMutation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.pending[key]; !exists {
+		b.order = append(b.order, key)
+	}
+	b.pending[key] = append(b.pending[key], mutation)
+}
+
+// getNodePoolObservedGeneration returns the generation of the NodePool read by the reconcile, if the context has a
+// batch, or else the generation of the given NodePool
+func getNodePoolObservedGeneration(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) int64 {
+	if batch := getNodePoolStatusBatch(ctx); batch != nil {
+		if generation, exists := batch.generations[client.ObjectKeyFromObject(nodepool)]; exists {
+			return generation
+		}
+	}
+	return nodepool.Generation
+}
+
+// take removes and returns the pending changes of the batch
+func (b *nodePoolStatusBatch) take() ([]types.NamespacedName, map[types.NamespacedName][]nodePoolStatusMutation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	order, pending := b.order, b.pending
+	b.order = nil
+	b.pending = make(map[types.NamespacedName][]nodePoolStatusMutation)
+	return order, pending
+}
+
+// FlushNodePoolStatus writes the NodePool status changes collected in the batch of the context, in one patch per
+// NodePool. It does nothing if the context has no batch.
+func FlushNodePoolStatus(ctx context.Context, c client.Client) error {
+	batch := getNodePoolStatusBatch(ctx)
+	if batch == nil {
+		return nil
+	}
+
+	order, pending := batch.take()
+	for _, key := range order {
+		if err := writeNodePoolStatus(ctx, c, key, pending[key]...); err != nil {
+			return fmt.Errorf("failed to update nodepool status: %s, %w", key.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// updateNodePoolStatus applies a status change to the latest version of the NodePool, deferring the write to
+// FlushNodePoolStatus if the context has a batch. A deferred write can only fail once flushed, so its error is returned
+// by FlushNodePoolStatus to the caller that created the batch.
+func updateNodePoolStatus(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool,
+	mutation nodePoolStatusMutation) error {
+
+	key := client.ObjectKeyFromObject(nodepool)
+	if batch := getNodePoolStatusBatch(ctx); batch != nil {
+		batch.add(key, mutation)
+		return nil
+	}
+
+	return writeNodePoolStatus(ctx, c, key, mutation)
+}
+
+// writeNodePoolStatus applies the status changes to the latest version of the NodePool and patches its status, unless
+// the changes leave it semantically unchanged
+func writeNodePoolStatus(ctx context.Context, c client.Client, key types.NamespacedName,
+	mutations ...nodePoolStatusMutation) error {
+
+	// nolint: wrapcheck
+	return RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
+		current := &hwmgmtv1alpha1.NodePool{}
+		if err := c.Get(ctx, key, current); err != nil {
+			return err
+		}
+
+		updated := current.DeepCopy()
+		for _, mutation := range mutations {
+			mutation(updated)
+		}
+
+		if equality.Semantic.DeepEqual(current.Status, updated.Status) {
+			return nil
+		}
+
+		return c.Status().Patch(ctx, updated, client.MergeFromWithOptions(current, client.MergeFromWithOptimisticLock{}))
+	})
+}
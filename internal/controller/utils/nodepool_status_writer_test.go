@@ -0,0 +1,89 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNodePoolStatusTestClient(t *testing.T, nodepool *hwmgmtv1alpha1.NodePool) client.Client {
+	scheme := runtime.NewScheme()
+	if err := hwmgmtv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(nodepool).
+		WithStatusSubresource(&hwmgmtv1alpha1.NodePool{}).
+		Build()
+}
+
+func TestFlushNodePoolStatusObservedGeneration(t *testing.T) {
+	ctx := context.Background()
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "oran-hwmgr-plugin", Generation: 1},
+	}
+	c := newNodePoolStatusTestClient(t, nodepool)
+
+	batchCtx := WithNodePoolStatusBatch(ctx, nodepool)
+	if err := UpdateNodePoolPluginStatus(batchCtx, c, nodepool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The spec changes while the reconcile is in progress
+	changed := &hwmgmtv1alpha1.NodePool{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), changed); err != nil {
+		t.Fatalf("failed to get nodepool: %v", err)
+	}
+	changed.Generation = 2
+	if err := c.Update(ctx, changed); err != nil {
+		t.Fatalf("failed to update nodepool: %v", err)
+	}
+
+	if err := FlushNodePoolStatus(batchCtx, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated := &hwmgmtv1alpha1.NodePool{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), updated); err != nil {
+		t.Fatalf("failed to get nodepool: %v", err)
+	}
+	if updated.Status.HwMgrPlugin.ObservedGeneration != 1 {
+		t.Errorf("expected observed generation 1, got %d", updated.Status.HwMgrPlugin.ObservedGeneration)
+	}
+}
+
+func TestFlushNodePoolStatusError(t *testing.T) {
+	ctx := context.Background()
+	nodepool := &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "oran-hwmgr-plugin", Generation: 1},
+	}
+	c := newNodePoolStatusTestClient(t, nodepool)
+
+	batchCtx := WithNodePoolStatusBatch(ctx, nodepool)
+	if err := UpdateNodePoolPluginStatus(batchCtx, c, nodepool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Delete(ctx, nodepool); err != nil {
+		t.Fatalf("failed to delete nodepool: %v", err)
+	}
+
+	if err := FlushNodePoolStatus(batchCtx, c); err == nil {
+		t.Errorf("expected the failed status write to be returned")
+	}
+}
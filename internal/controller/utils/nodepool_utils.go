@@ -10,7 +10,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"strings"
 
+	pluginv1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
 	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,6 +27,12 @@ import (
 const (
 	NodepoolFinalizer = "oran-hwmgr-plugin/nodepool-finalizer"
 	ResourceTypeIdKey = "resourceTypeId"
+
+	NodePoolDryRunAnnotation = "hwmgr-plugin.oran.openshift.io/dry-run"
+
+	// ReconcileRequestAnnotation is set by hwmgr-cli to the time a reconcile of the NodePool was requested. Any
+	// change to the NodePool queues it for reconcile, so the value itself is informational.
+	ReconcileRequestAnnotation = "hwmgr-plugin.oran.openshift.io/reconcile-requested"
 )
 
 var nodepoolGVK schema.GroupVersionKind
@@ -75,15 +84,65 @@ func IsNodePoolProvisionedCompleted(nodepool *hwmgmtv1alpha1.NodePool) bool {
 	return false
 }
 
+// IsNodePoolDryRun checks whether the NodePool is annotated to request validation only. The dry run applies until the
+// NodePool has been handed off for provisioning.
+func IsNodePoolDryRun(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return nodepool.GetAnnotations()[NodePoolDryRunAnnotation] == "true" && GetNodePoolProvisionedCondition(nodepool) == nil
+}
+
+// UpdateNodePoolDryRunCondition reports the outcome of the NodePool dry run checks in the DryRun condition
+func UpdateNodePoolDryRunCondition(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, checkErr error, detail string) error {
+	conditionReason := hwmgmtv1alpha1.Completed
+	conditionStatus := metav1.ConditionTrue
+	message := "Dry run passed: NodePool can be provisioned"
+	if detail != "" {
+		message += ": " + detail
+	}
+
+	if checkErr != nil {
+		conditionReason = hwmgmtv1alpha1.Failed
+		conditionStatus = metav1.ConditionFalse
+		message = "Dry run failed: " + checkErr.Error()
+	}
+
+	return UpdateNodePoolStatusCondition(ctx, c, nodepool,
+		hwmgmtv1alpha1.ConditionType(pluginv1alpha1.ConditionTypes.DryRun), conditionReason, conditionStatus, message)
+}
+
+// ValidateNodePoolHwProfiles checks that the HardwareProfile CRs requested by each nodegroup exist, along with any
+// base profiles they extend
+func ValidateNodePoolHwProfiles(ctx context.Context, c client.Reader, namespace string, nodepool *hwmgmtv1alpha1.NodePool) error {
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if nodegroup.NodePoolData.HwProfile == "" {
+			continue
+		}
+
+		if _, err := GetHardwareProfile(ctx, c, namespace, nodegroup.NodePoolData.HwProfile); err != nil {
+			return fmt.Errorf("invalid HardwareProfile for nodegroup %s: %w", nodegroup.NodePoolData.Name, err)
+		}
+	}
+
+	return nil
+}
+
 func IsNodePoolProvisionedFailed(nodepool *hwmgmtv1alpha1.NodePool) bool {
 	provisionedCondition := GetNodePoolProvisionedCondition(nodepool)
-	if provisionedCondition != nil && provisionedCondition.Reason == string(hwmgmtv1alpha1.Failed) {
+	if IsConditionFailed(provisionedCondition) {
 		return true
 	}
 
 	return false
 }
 
+// IsNodePoolConfigUpdateRequested checks whether a configuration update of a provisioned NodePool has been started
+// without a spec change, such as to apply a node hardware profile override or remediate drift, and not yet completed
+func IsNodePoolConfigUpdateRequested(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	condition := meta.FindStatusCondition(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+	return condition != nil && condition.Status == metav1.ConditionFalse && !IsConditionFailed(condition) &&
+		condition.Reason != string(hwmgmtv1alpha1.InvalidInput)
+}
+
+// UpdateNodePoolStatusCondition sets a status condition on the NodePool
 func UpdateNodePoolStatusCondition(
 	ctx context.Context,
 	c client.Client,
@@ -93,105 +152,68 @@ func UpdateNodePoolStatusCondition(
 	conditionStatus metav1.ConditionStatus,
 	message string) error {
 
-	SetStatusCondition(&nodepool.Status.Conditions,
+	SetStatusCondition(ctx, &nodepool.Status.Conditions,
 		string(conditionType),
 		string(conditionReason),
 		conditionStatus,
 		message)
 
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		SetStatusCondition(&newNodepool.Status.Conditions,
+	if err := updateNodePoolStatus(ctx, c, nodepool, func(newNodepool *hwmgmtv1alpha1.NodePool) {
+		SetStatusCondition(ctx, &newNodepool.Status.Conditions,
 			string(conditionType),
 			string(conditionReason),
 			conditionStatus,
 			message)
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
+	}); err != nil {
 		return fmt.Errorf("failed to update nodepool condition: %s, %w", nodepool.Name, err)
 	}
 
 	return nil
 }
 
+// UpdateNodePoolProperties sets the properties of the NodePool status from the given NodePool
 func UpdateNodePoolProperties(
 	ctx context.Context,
 	c client.Client,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
 
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		newNodepool.Status.Properties = nodepool.Status.Properties
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
+	properties := *nodepool.Status.Properties.DeepCopy()
+	if err := updateNodePoolStatus(ctx, c, nodepool, func(newNodepool *hwmgmtv1alpha1.NodePool) {
+		newNodepool.Status.Properties = properties
+	}); err != nil {
 		return fmt.Errorf("failed to update nodepool properties: %w", err)
 	}
 
 	return nil
 }
 
+// UpdateNodePoolSelectedPools sets the selected pools of the NodePool status from the given NodePool
 func UpdateNodePoolSelectedPools(
 	ctx context.Context,
 	c client.Client,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
 
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		newNodepool.Status.SelectedPools = nodepool.Status.SelectedPools
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
+	selectedPools := maps.Clone(nodepool.Status.SelectedPools)
+	if err := updateNodePoolStatus(ctx, c, nodepool, func(newNodepool *hwmgmtv1alpha1.NodePool) {
+		newNodepool.Status.SelectedPools = selectedPools
+	}); err != nil {
 		return fmt.Errorf("failed to update nodepool selectedPools: %w", err)
 	}
 
 	return nil
 }
 
+// UpdateNodePoolPluginStatus records the generation of the NodePool observed by the plugin
 func UpdateNodePoolPluginStatus(
 	ctx context.Context,
 	c client.Client,
 	nodepool *hwmgmtv1alpha1.NodePool) error {
 
-	// nolint: wrapcheck
-	err := RetryOnConflictOrRetriable(retry.DefaultRetry, func() error {
-		newNodepool := &hwmgmtv1alpha1.NodePool{}
-		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
-			return err
-		}
-		newNodepool.Status.HwMgrPlugin.ObservedGeneration = newNodepool.ObjectMeta.Generation
-		if err := c.Status().Update(ctx, newNodepool); err != nil {
-			return err
-		}
-		return nil
-	})
-
-	if err != nil {
+	// The generation handled is the one read by the reconcile, as the spec may have changed since
+	generation := getNodePoolObservedGeneration(ctx, nodepool)
+	if err := updateNodePoolStatus(ctx, c, nodepool, func(newNodepool *hwmgmtv1alpha1.NodePool) {
+		newNodepool.Status.HwMgrPlugin.ObservedGeneration = generation
+	}); err != nil {
 		return fmt.Errorf("failed to update nodepool condition: %w", err)
 	}
 
@@ -207,6 +229,11 @@ func DeriveNodePoolStatusFromNodes(
 	nodelist *hwmgmtv1alpha1.NodeList,
 ) (metav1.ConditionStatus, string, string) {
 
+	// A node that aborted the rollout is reported first, and nodes skipped after an update error only once no other
+	// node remains to be configured
+	var pending *metav1.Condition
+	var pendingNode string
+	var skipped []string
 	for _, node := range nodelist.Items {
 		// Fetch the latest version of the node from the API server
 		updatedNode, err := GetNode(ctx, logger, reader, node.Namespace, node.Name)
@@ -223,10 +250,26 @@ func DeriveNodePoolStatusFromNodes(
 				fmt.Sprintf("Node %s missing Configured condition", node.Name)
 		}
 
-		// If not successfully applied, return this node’s current condition
-		if cond.Reason != string(hwmgmtv1alpha1.ConfigApplied) {
-			return cond.Status, cond.Reason, fmt.Sprintf("Node %s: %s", node.Name, cond.Message)
+		if cond.Reason == string(hwmgmtv1alpha1.ConfigApplied) {
+			continue
 		}
+		switch {
+		case IsNodeUpdateAborted(updatedNode):
+			return cond.Status, cond.Reason, fmt.Sprintf("Rollout aborted by node %s: %s", node.Name, ConditionMessage(cond))
+		case IsNodeUpdateSkipped(updatedNode):
+			skipped = append(skipped, node.Name)
+		case pending == nil:
+			pending, pendingNode = cond, node.Name
+		}
+	}
+
+	// If not successfully applied, return the first pending node’s current condition
+	if pending != nil {
+		return pending.Status, pending.Reason, fmt.Sprintf("Node %s: %s", pendingNode, ConditionMessage(pending))
+	}
+	if len(skipped) > 0 {
+		return metav1.ConditionFalse, string(hwmgmtv1alpha1.Failed),
+			fmt.Sprintf("Hardware configuration skipped after BMH errors for nodes: %s", strings.Join(skipped, ", "))
 	}
 
 	// All nodes are successfully configured
@@ -0,0 +1,262 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// MetaType is the metadata of an object changed by a MetaMutation
+type MetaType string
+
+const (
+	MetaTypeLabel      MetaType = "label"
+	MetaTypeAnnotation MetaType = "annotation"
+	MetaTypeFinalizer  MetaType = "finalizer"
+)
+
+// MetaOperation is the change made by a MetaMutation
+type MetaOperation string
+
+const (
+	MetaOpAdd    MetaOperation = "add"
+	MetaOpRemove MetaOperation = "remove"
+)
+
+// MetaMutation is a change to a label, annotation or finalizer of an object
+type MetaMutation struct {
+	Type      MetaType
+	Operation MetaOperation
+	Key       string
+	// Value is the value of an added label or annotation
+	Value string
+}
+
+func (m MetaMutation) String() string {
+	return fmt.Sprintf("%s %s %s", m.Operation, m.Type, m.Key)
+}
+
+// AddLabel sets a label on an object
+func AddLabel(key, value string) MetaMutation {
+	return MetaMutation{Type: MetaTypeLabel, Operation: MetaOpAdd, Key: key, Value: value}
+}
+
+// RemoveLabel removes a label from an object
+func RemoveLabel(key string) MetaMutation {
+	return MetaMutation{Type: MetaTypeLabel, Operation: MetaOpRemove, Key: key}
+}
+
+// AddAnnotation sets an annotation on an object
+func AddAnnotation(key, value string) MetaMutation {
+	return MetaMutation{Type: MetaTypeAnnotation, Operation: MetaOpAdd, Key: key, Value: value}
+}
+
+// RemoveAnnotation removes an annotation from an object
+func RemoveAnnotation(key string) MetaMutation {
+	return MetaMutation{Type: MetaTypeAnnotation, Operation: MetaOpRemove, Key: key}
+}
+
+// AddFinalizer adds a finalizer to an object
+func AddFinalizer(finalizer string) MetaMutation {
+	return MetaMutation{Type: MetaTypeFinalizer, Operation: MetaOpAdd, Key: finalizer}
+}
+
+// RemoveFinalizer removes a finalizer from an object
+func RemoveFinalizer(finalizer string) MetaMutation {
+	return MetaMutation{Type: MetaTypeFinalizer, Operation: MetaOpRemove, Key: finalizer}
+}
+
+// ApplyMetaMutations makes the changes to the metadata of an object in memory, returning true if any of them changed
+// the object. Adding a label or annotation that is already set to the same value, or removing one that is not present,
+// is not a change.
+func ApplyMetaMutations(obj client.Object, mutations ...MetaMutation) (bool, error) {
+	changed := false
+	for _, mutation := range mutations {
+		var mutated bool
+		switch mutation.Type {
+		case MetaTypeLabel:
+			labels, updated, err := mutateMetaMap(obj.GetLabels(), mutation)
+			if err != nil {
+				return false, err
+			}
+			if updated {
+				obj.SetLabels(labels)
+			}
+			mutated = updated
+		case MetaTypeAnnotation:
+			annotations, updated, err := mutateMetaMap(obj.GetAnnotations(), mutation)
+			if err != nil {
+				return false, err
+			}
+			if updated {
+				obj.SetAnnotations(annotations)
+			}
+			mutated = updated
+		case MetaTypeFinalizer:
+			switch mutation.Operation {
+			case MetaOpAdd:
+				mutated = controllerutil.AddFinalizer(obj, mutation.Key)
+			case MetaOpRemove:
+				mutated = controllerutil.RemoveFinalizer(obj, mutation.Key)
+			default:
+				return false, fmt.Errorf("unsupported operation: %s", mutation.Operation)
+			}
+		default:
+			return false, fmt.Errorf("unsupported meta type: %s", mutation.Type)
+		}
+		changed = changed || mutated
+	}
+	return changed, nil
+}
+
+func mutateMetaMap(values map[string]string, mutation MetaMutation) (map[string]string, bool, error) {
+	switch mutation.Operation {
+	case MetaOpAdd:
+		if current, exists := values[mutation.Key]; exists && current == mutation.Value {
+			return values, false, nil
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[mutation.Key] = mutation.Value
+		return values, true, nil
+	case MetaOpRemove:
+		if _, exists := values[mutation.Key]; !exists {
+			return values, false, nil
+		}
+		delete(values, mutation.Key)
+		return values, true, nil
+	default:
+		return nil, false, fmt.Errorf("unsupported operation: %s", mutation.Operation)
+	}
+}
+
+type metaUpdateOptions struct {
+	fieldOwner string
+}
+
+// MetaUpdateOption configures UpdateObjectMetaWithRetry
+type MetaUpdateOption func(*metaUpdateOptions)
+
+// WithServerSideApply adds labels, annotations and finalizers with a server-side apply by the given field owner,
+// rather than a merge patch, so that the field owner is recorded as managing them. As with any server-side apply,
+// metadata previously added by the field owner and not included in a later apply is removed by the API server, so
+// each field owner should manage a distinct set of keys. Removals are always made with a merge patch, so that metadata
+// managed by another field owner can be removed.
+func WithServerSideApply(fieldOwner string) MetaUpdateOption {
+	return func(opts *metaUpdateOptions) {
+		opts.fieldOwner = fieldOwner
+	}
+}
+
+// UpdateObjectMetaWithRetry changes the labels, annotations and finalizers of the latest version of an object,
+// retrying on conflict. The object is identified by the name and namespace of obj, which is updated with the latest
+// version of the object. No patch is made if the changes are already in place.
+//
+// Finalizers are a list, replaced as a whole by a merge patch, so the patch is made with an optimistic lock when
+// finalizers are changed, to avoid dropping a finalizer concurrently added by another controller.
+func UpdateObjectMetaWithRetry(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	mutations []MetaMutation,
+	opts ...MetaUpdateOption) error {
+
+	options := metaUpdateOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.fieldOwner == "" {
+		return patchObjectMetaWithRetry(ctx, c, obj, mutations)
+	}
+
+	var adds, removes []MetaMutation
+	for _, mutation := range mutations {
+		if mutation.Operation == MetaOpRemove {
+			removes = append(removes, mutation)
+		} else {
+			adds = append(adds, mutation)
+		}
+	}
+
+	if len(removes) > 0 {
+		if err := patchObjectMetaWithRetry(ctx, c, obj, removes); err != nil {
+			return err
+		}
+	}
+	if len(adds) > 0 {
+		return applyObjectMeta(ctx, c, obj, adds, options.fieldOwner)
+	}
+	return nil
+}
+
+func patchObjectMetaWithRetry(ctx context.Context, c client.Client, obj client.Object, mutations []MetaMutation) error {
+	key := client.ObjectKeyFromObject(obj)
+
+	var patchOpts []client.MergeFromOption
+	for _, mutation := range mutations {
+		if mutation.Type == MetaTypeFinalizer {
+			patchOpts = append(patchOpts, client.MergeFromWithOptimisticLock{})
+			break
+		}
+	}
+
+	// nolint: wrapcheck
+	return retry.OnError(retry.DefaultRetry, errors.IsConflict, func() error {
+		if err := c.Get(ctx, key, obj); err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", key, err)
+		}
+
+		base, ok := obj.DeepCopyObject().(client.Object)
+		if !ok {
+			return fmt.Errorf("failed to copy %s", key)
+		}
+
+		changed, err := ApplyMetaMutations(obj, mutations...)
+		if err != nil || !changed {
+			return err
+		}
+
+		if err := c.Patch(ctx, obj, client.MergeFromWithOptions(base, patchOpts...)); err != nil {
+			return fmt.Errorf("failed to patch metadata of %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+func applyObjectMeta(ctx context.Context, c client.Client, obj client.Object, mutations []MetaMutation, fieldOwner string) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return fmt.Errorf("failed to get kind of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+
+	applied := &unstructured.Unstructured{}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetName(obj.GetName())
+	applied.SetNamespace(obj.GetNamespace())
+	if _, err := ApplyMetaMutations(applied, mutations...); err != nil {
+		return err
+	}
+
+	if err := c.Patch(ctx, applied, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership); err != nil {
+		return fmt.Errorf("failed to apply metadata of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyMetaMutations(t *testing.T) {
+	tests := []struct {
+		description         string
+		meta                metav1.ObjectMeta
+		mutations           []MetaMutation
+		expectChanged       bool
+		expectError         bool
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		expectedFinalizers  []string
+	}{
+		{
+			description:    "add label to object without labels",
+			mutations:      []MetaMutation{AddLabel("a", "1")},
+			expectChanged:  true,
+			expectedLabels: map[string]string{"a": "1"},
+		},
+		{
+			description:    "add label already set to the same value",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"a": "1"}},
+			mutations:      []MetaMutation{AddLabel("a", "1")},
+			expectedLabels: map[string]string{"a": "1"},
+		},
+		{
+			description:    "add label already set to a different value",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"a": "1"}},
+			mutations:      []MetaMutation{AddLabel("a", "2")},
+			expectChanged:  true,
+			expectedLabels: map[string]string{"a": "2"},
+		},
+		{
+			description:    "remove label",
+			meta:           metav1.ObjectMeta{Labels: map[string]string{"a": "1", "b": "2"}},
+			mutations:      []MetaMutation{RemoveLabel("a")},
+			expectChanged:  true,
+			expectedLabels: map[string]string{"b": "2"},
+		},
+		{
+			description: "remove label from object without labels",
+			mutations:   []MetaMutation{RemoveLabel("a")},
+		},
+		{
+			description:         "add annotation with empty value",
+			meta:                metav1.ObjectMeta{Annotations: map[string]string{"a": "1"}},
+			mutations:           []MetaMutation{AddAnnotation("b", "")},
+			expectChanged:       true,
+			expectedAnnotations: map[string]string{"a": "1", "b": ""},
+		},
+		{
+			description:         "remove and add annotations",
+			meta:                metav1.ObjectMeta{Annotations: map[string]string{"a": "1"}},
+			mutations:           []MetaMutation{RemoveAnnotation("a"), AddAnnotation("b", "2")},
+			expectChanged:       true,
+			expectedAnnotations: map[string]string{"b": "2"},
+		},
+		{
+			description:         "remove missing annotation",
+			meta:                metav1.ObjectMeta{Annotations: map[string]string{"a": "1"}},
+			mutations:           []MetaMutation{RemoveAnnotation("b")},
+			expectedAnnotations: map[string]string{"a": "1"},
+		},
+		{
+			description:        "add finalizer",
+			meta:               metav1.ObjectMeta{Finalizers: []string{"x"}},
+			mutations:          []MetaMutation{AddFinalizer("y")},
+			expectChanged:      true,
+			expectedFinalizers: []string{"x", "y"},
+		},
+		{
+			description:        "add existing finalizer",
+			meta:               metav1.ObjectMeta{Finalizers: []string{"x"}},
+			mutations:          []MetaMutation{AddFinalizer("x")},
+			expectedFinalizers: []string{"x"},
+		},
+		{
+			description:        "remove finalizer",
+			meta:               metav1.ObjectMeta{Finalizers: []string{"x", "y"}},
+			mutations:          []MetaMutation{RemoveFinalizer("x")},
+			expectChanged:      true,
+			expectedFinalizers: []string{"y"},
+		},
+		{
+			description:         "labels, annotations and finalizers together",
+			mutations:           []MetaMutation{AddLabel("a", "1"), AddAnnotation("b", "2"), AddFinalizer("c")},
+			expectChanged:       true,
+			expectedLabels:      map[string]string{"a": "1"},
+			expectedAnnotations: map[string]string{"b": "2"},
+			expectedFinalizers:  []string{"c"},
+		},
+		{
+			description: "unsupported meta type",
+			mutations:   []MetaMutation{{Type: "owner", Operation: MetaOpAdd, Key: "a"}},
+			expectError: true,
+		},
+		{
+			description: "unsupported operation",
+			mutations:   []MetaMutation{{Type: MetaTypeAnnotation, Operation: "replace", Key: "a"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			obj := &corev1.ConfigMap{ObjectMeta: tc.meta}
+			changed, err := ApplyMetaMutations(obj, tc.mutations...)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if changed != tc.expectChanged {
+				t.Errorf("changed: expected %t, got %t", tc.expectChanged, changed)
+			}
+			if len(tc.expectedLabels) != 0 || len(obj.Labels) != 0 {
+				if !reflect.DeepEqual(obj.Labels, tc.expectedLabels) {
+					t.Errorf("labels: expected %v, got %v", tc.expectedLabels, obj.Labels)
+				}
+			}
+			if len(tc.expectedAnnotations) != 0 || len(obj.Annotations) != 0 {
+				if !reflect.DeepEqual(obj.Annotations, tc.expectedAnnotations) {
+					t.Errorf("annotations: expected %v, got %v", tc.expectedAnnotations, obj.Annotations)
+				}
+			}
+			if len(tc.expectedFinalizers) != 0 || len(obj.Finalizers) != 0 {
+				if !reflect.DeepEqual(obj.Finalizers, tc.expectedFinalizers) {
+					t.Errorf("finalizers: expected %v, got %v", tc.expectedFinalizers, obj.Finalizers)
+				}
+			}
+		})
+	}
+}
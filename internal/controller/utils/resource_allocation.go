@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceAllocation identifies the NodePool, and the Node, that a hardware manager resource is allocated to
+type ResourceAllocation struct {
+	NodePoolName string
+	CloudID      string
+	NodeName     string
+}
+
+// GetNodePoolAllocations returns the NodePools of a hardware manager, by name
+func GetNodePoolAllocations(ctx context.Context, c client.Reader, namespace, hwMgrId string) (map[string]ResourceAllocation, error) {
+	nodepools := &hwmgmtv1alpha1.NodePoolList{}
+	if err := c.List(ctx, nodepools, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+
+	allocations := make(map[string]ResourceAllocation)
+	for _, nodepool := range nodepools.Items {
+		if nodepool.Spec.HwMgrId != hwMgrId {
+			continue
+		}
+		allocations[nodepool.Name] = ResourceAllocation{
+			NodePoolName: nodepool.Name,
+			CloudID:      nodepool.Spec.CloudID,
+		}
+	}
+	return allocations, nil
+}
+
+// GetNodeAllocations returns the allocation of each resource of a hardware manager that backs a Node, keyed by the
+// hardware manager node namespace and ID of the resource
+func GetNodeAllocations(ctx context.Context, c client.Reader, namespace, hwMgrId string) (map[types.NamespacedName]ResourceAllocation, error) {
+	nodepools, err := GetNodePoolAllocations(ctx, c, namespace, hwMgrId)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := &hwmgmtv1alpha1.NodeList{}
+	if err := c.List(ctx, nodes, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Nodes: %w", err)
+	}
+
+	allocations := make(map[types.NamespacedName]ResourceAllocation)
+	for _, node := range nodes.Items {
+		if node.Spec.HwMgrId != hwMgrId || node.Spec.HwMgrNodeId == "" {
+			continue
+		}
+
+		// The Node is owned by its NodePool, while the adaptors differ in whether spec.nodePool holds the name or
+		// cloud ID of the NodePool
+		allocation := ResourceAllocation{NodePoolName: node.Spec.NodePool, NodeName: node.Name}
+		for _, ref := range node.GetOwnerReferences() {
+			if ref.Kind == "NodePool" {
+				allocation.NodePoolName = ref.Name
+				break
+			}
+		}
+		allocation.CloudID = nodepools[allocation.NodePoolName].CloudID
+
+		allocations[types.NamespacedName{Namespace: node.Spec.HwMgrNodeNs, Name: node.Spec.HwMgrNodeId}] = allocation
+	}
+	return allocations, nil
+}
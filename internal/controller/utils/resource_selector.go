@@ -0,0 +1,248 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceSelectorOperator is the comparison made by a resourceSelector requirement against the value of a label
+type ResourceSelectorOperator string
+
+const (
+	ResourceSelectorEquals         ResourceSelectorOperator = "="
+	ResourceSelectorGreaterThan    ResourceSelectorOperator = ">"
+	ResourceSelectorGreaterOrEqual ResourceSelectorOperator = ">="
+	ResourceSelectorLessThan       ResourceSelectorOperator = "<"
+	ResourceSelectorLessOrEqual    ResourceSelectorOperator = "<="
+)
+
+// resourceSelectorComparisons are the operators a resourceSelector value may start with, longest first so that ">="
+// is not taken for ">"
+var resourceSelectorComparisons = []ResourceSelectorOperator{
+	ResourceSelectorGreaterOrEqual,
+	ResourceSelectorLessOrEqual,
+	ResourceSelectorGreaterThan,
+	ResourceSelectorLessThan,
+}
+
+// resourceSelectorUnits maps the units accepted in numeric resourceSelector values to Kubernetes quantity suffixes.
+// Both the IEC and the Kubernetes spellings of binary units are accepted, as are byte-suffixed decimal units.
+var resourceSelectorUnits = map[string]string{
+	"":    "",
+	"k":   "k",
+	"kB":  "k",
+	"KB":  "k",
+	"M":   "M",
+	"MB":  "M",
+	"G":   "G",
+	"GB":  "G",
+	"T":   "T",
+	"TB":  "T",
+	"P":   "P",
+	"PB":  "P",
+	"E":   "E",
+	"EB":  "E",
+	"Ki":  "Ki",
+	"KiB": "Ki",
+	"Mi":  "Mi",
+	"MiB": "Mi",
+	"Gi":  "Gi",
+	"GiB": "Gi",
+	"Ti":  "Ti",
+	"TiB": "Ti",
+	"Pi":  "Pi",
+	"PiB": "Pi",
+	"Ei":  "Ei",
+	"EiB": "Ei",
+}
+
+var reResourceSelectorQuantity = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?) ?([A-Za-z]*)$`)
+
+// ResourceSelectorRequirement is a single requirement of a resourceSelector, on the value of the label with its key
+type ResourceSelectorRequirement struct {
+	Key      string
+	Operator ResourceSelectorOperator
+	Value    string
+	// Quantity is the numeric value of Value, for a comparison
+	Quantity *resource.Quantity
+}
+
+// IsExactMatch returns whether the requirement is met only by a label with exactly its value, such that it can be
+// expressed as a label selector. Plain values are always matched exactly, even if they look like a quantity, such as
+// "25g".
+func (r ResourceSelectorRequirement) IsExactMatch() bool {
+	return r.Operator == ResourceSelectorEquals
+}
+
+// Matches returns whether a label value meets the requirement. A label value that is not a quantity never meets a
+// numeric comparison.
+func (r ResourceSelectorRequirement) Matches(value string) bool {
+	if r.IsExactMatch() {
+		return value == r.Value
+	}
+	if r.Quantity == nil {
+		return false
+	}
+
+	quantity, err := parseResourceSelectorQuantity(value)
+	if err != nil || quantity == nil {
+		return false
+	}
+
+	cmp := quantity.Cmp(*r.Quantity)
+	switch r.Operator {
+	case ResourceSelectorGreaterThan:
+		return cmp > 0
+	case ResourceSelectorGreaterOrEqual:
+		return cmp >= 0
+	case ResourceSelectorLessThan:
+		return cmp < 0
+	case ResourceSelectorLessOrEqual:
+		return cmp <= 0
+	}
+	return false
+}
+
+// parseResourceSelectorQuantity parses a value as a number with an optional unit. A value that is not a number is not
+// a quantity, and a unit that differs only in case from a supported one is rejected as ambiguous, as is "m", which
+// Kubernetes reads as milli.
+func parseResourceSelectorQuantity(value string) (*resource.Quantity, error) {
+	match := reResourceSelectorQuantity.FindStringSubmatch(value)
+	if match == nil {
+		return nil, nil
+	}
+
+	number, unit := match[1], match[2]
+	suffix, known := resourceSelectorUnits[unit]
+	if !known {
+		if unit == "m" {
+			return nil, fmt.Errorf("unit of %q is ambiguous, use M or Mi", value)
+		}
+		for supported := range resourceSelectorUnits {
+			if strings.EqualFold(unit, supported) {
+				return nil, fmt.Errorf("unit of %q is ambiguous, units are case-sensitive", value)
+			}
+		}
+		return nil, nil
+	}
+
+	quantity, err := resource.ParseQuantity(number + suffix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quantity %q: %w", value, err)
+	}
+	return &quantity, nil
+}
+
+// ParseResourceSelector parses the resourceSelector of a node group, a JSON object mapping label keys to string
+// values, into its requirements, sorted by key. A value may start with a comparison operator, such as ">=512GiB",
+// in which case it must be a number with an optional supported unit. Values without an operator are matched exactly.
+// Selectors that cannot be read only one way, such as ones with repeated keys or non-string values, are rejected.
+func ParseResourceSelector(selector string) ([]ResourceSelectorRequirement, error) {
+	if selector == "" {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(selector))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("resourceSelector must be a JSON object")
+	}
+
+	var requirements []ResourceSelectorRequirement
+	seen := make(map[string]bool)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse resourceSelector: %w", err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unable to parse resourceSelector: unexpected %v", token)
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("unable to parse resourceSelector: %w", err)
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("value of %q must be a string, got %s", key, raw)
+		}
+
+		if strings.TrimSpace(key) == "" {
+			return nil, errors.New("resourceSelector has an empty key")
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("resourceSelector key %q is repeated", key)
+		}
+		seen[key] = true
+
+		requirement, err := parseResourceSelectorRequirement(key, value)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("unable to parse resourceSelector: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("resourceSelector has trailing data after the JSON object")
+	}
+
+	sort.Slice(requirements, func(i, j int) bool { return requirements[i].Key < requirements[j].Key })
+	return requirements, nil
+}
+
+// parseResourceSelectorRequirement parses the value of a single resourceSelector key
+func parseResourceSelectorRequirement(key, value string) (ResourceSelectorRequirement, error) {
+	requirement := ResourceSelectorRequirement{Key: key, Operator: ResourceSelectorEquals, Value: value}
+	for _, operator := range resourceSelectorComparisons {
+		if operand, found := strings.CutPrefix(value, string(operator)); found {
+			requirement.Operator = operator
+			requirement.Value = strings.TrimSpace(operand)
+			break
+		}
+	}
+
+	if requirement.IsExactMatch() {
+		return requirement, nil
+	}
+
+	quantity, err := parseResourceSelectorQuantity(requirement.Value)
+	if err != nil {
+		return requirement, fmt.Errorf("value of %q: %w", key, err)
+	}
+	if quantity == nil {
+		return requirement, fmt.Errorf("value of %q compares with %s, which requires a number with an optional unit, got %q",
+			key, requirement.Operator, requirement.Value)
+	}
+	requirement.Quantity = quantity
+
+	return requirement, nil
+}
+
+// ValidateNodePoolResourceSelectors checks that the resourceSelector of each node group of a NodePool is well-formed
+func ValidateNodePoolResourceSelectors(nodepool *hwmgmtv1alpha1.NodePool) error {
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		if _, err := ParseResourceSelector(nodegroup.NodePoolData.ResourceSelector); err != nil {
+			return fmt.Errorf("invalid resourceSelector for nodegroup %s: %w", nodegroup.NodePoolData.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseResourceSelectorErrors(t *testing.T) {
+	tests := []struct {
+		description string
+		selector    string
+		valid       bool
+	}{
+		{description: "empty", selector: "", valid: true},
+		{description: "labels", selector: `{"server-type": "R740", "rack": "r12"}`, valid: true},
+		{description: "comparisons", selector: `{"memory": ">=512GiB", "cores": "<64"}`, valid: true},
+		{description: "not an object", selector: `["memory"]`},
+		{description: "malformed", selector: `{"memory": }`},
+		{description: "trailing data", selector: `{"memory": "512Gi"} {}`},
+		{description: "numeric value", selector: `{"memory": 512}`},
+		{description: "repeated key", selector: `{"memory": "512Gi", "memory": "1Ti"}`},
+		{description: "empty key", selector: `{"": "512Gi"}`},
+		{description: "comparison with string", selector: `{"memory": ">=large"}`},
+		{description: "wrong unit case", selector: `{"memory": ">=512gib"}`},
+		{description: "milli unit", selector: `{"memory": ">512m"}`},
+		{description: "plain values like units", selector: `{"nic-speed": "25g", "memory": "512m"}`, valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			_, err := ParseResourceSelector(test.selector)
+			if test.valid && err != nil {
+				t.Errorf("expected selector %s to be valid, got %v", test.selector, err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected selector %s to be rejected", test.selector)
+			}
+		})
+	}
+}
+
+func TestResourceSelectorRequirementMatches(t *testing.T) {
+	tests := []struct {
+		value      string
+		label      string
+		matches    bool
+		exactMatch bool
+	}{
+		{value: "R740", label: "R740", matches: true, exactMatch: true},
+		{value: "R740", label: "R750", matches: false, exactMatch: true},
+		{value: "512GiB", label: "512GiB", matches: true, exactMatch: true},
+		{value: "512GiB", label: "512Gi", matches: false, exactMatch: true},
+		{value: "25g", label: "25g", matches: true, exactMatch: true},
+		{value: ">=512GiB", label: "1Ti", matches: true},
+		{value: ">=512GiB", label: "256GiB", matches: false},
+		{value: ">= 512GiB", label: "512Gi", matches: true},
+		{value: ">512GiB", label: "512Gi", matches: false},
+		{value: "<64", label: "32", matches: true},
+		{value: "<=64", label: "many", matches: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value+" "+test.label, func(t *testing.T) {
+			requirements, err := ParseResourceSelector(`{"key": "` + test.value + `"}`)
+			if err != nil || len(requirements) != 1 {
+				t.Fatalf("failed to parse %s: %v", test.value, err)
+			}
+			requirement := requirements[0]
+			if matches := requirement.Matches(test.label); matches != test.matches {
+				t.Errorf("expected %s matching %s to be %t", test.value, test.label, test.matches)
+			}
+			if exactMatch := requirement.IsExactMatch(); exactMatch != test.exactMatch {
+				t.Errorf("expected %s exact match to be %t", test.value, test.exactMatch)
+			}
+		})
+	}
+}
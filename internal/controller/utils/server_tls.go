@@ -0,0 +1,178 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ServerCertificateExpiryWarning is the time before the expiry of a server certificate from which it is reported
+	// as expiring
+	ServerCertificateExpiryWarning = 30 * 24 * time.Hour
+
+	serverCertificateCheckInterval = time.Hour
+)
+
+// Server certificate event reasons
+const (
+	ServerCertificateRotated  = "ServerCertificateRotated"
+	ServerCertificateRejected = "ServerCertificateRejected"
+	ServerCertificateExpiring = "ServerCertificateExpiring"
+)
+
+var serverCertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hwmgr_plugin_server_certificate_expiry_timestamp_seconds",
+	Help: "Time at which the certificate served by a server expires, in seconds since the epoch",
+}, []string{"server"})
+
+func init() {
+	metrics.Registry.MustRegister(serverCertificateExpiry)
+}
+
+// ServerTLSOptions defines how the certificate served by a TLS server is validated and reported
+type ServerTLSOptions struct {
+	// Name identifies the server in logs, events, and metrics
+	Name string
+	// Hostnames are the names the server is reached by, each of which must be covered by the certificate
+	Hostnames []string
+	// Recorder, if set, records the rotation and expiry events of the certificate against EventObject
+	Recorder    record.EventRecorder
+	EventObject runtime.Object
+}
+
+// ValidateServerCertificate checks that the key matches the certificate and that the certificate covers each of the
+// hostnames, returning the certificate with its parsed leaf
+func ValidateServerCertificate(certPEM, keyPEM []byte, hostnames []string) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate and key pair: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	for _, hostname := range hostnames {
+		if err := leaf.VerifyHostname(hostname); err != nil {
+			return nil, fmt.Errorf("certificate does not cover %s: %w", hostname, err)
+		}
+	}
+
+	return &cert, nil
+}
+
+// serverCertificate holds the certificate served by a TLS server, replacing it when the files are rotated to content
+// that passes validation
+type serverCertificate struct {
+	ServerTLSOptions
+	loader  *dynamiccertificates.DynamicCertKeyPairContent
+	current atomic.Pointer[tls.Certificate]
+}
+
+// Enqueue is called by the loader once rotated content has been loaded
+func (s *serverCertificate) Enqueue() {
+	certPEM, keyPEM := s.loader.CurrentCertKeyContent()
+	cert, err := ValidateServerCertificate(certPEM, keyPEM, s.Hostnames)
+	if err != nil {
+		slog.Error("Rejected rotated server certificate, serving the previous certificate",
+			slog.String("server", s.Name), slog.String("error", err.Error()))
+		s.event(corev1.EventTypeWarning, ServerCertificateRejected,
+			"Rejected rotated certificate of the %s server: %s", s.Name, err.Error())
+		return
+	}
+
+	s.store(cert)
+	slog.Info("Rotated server certificate", slog.String("server", s.Name),
+		slog.String("serial", cert.Leaf.SerialNumber.String()), slog.Time("notAfter", cert.Leaf.NotAfter))
+	s.event(corev1.EventTypeNormal, ServerCertificateRotated,
+		"Rotated certificate of the %s server to serial %s, expiring at %s",
+		s.Name, cert.Leaf.SerialNumber.String(), cert.Leaf.NotAfter.Format(time.RFC3339))
+}
+
+func (s *serverCertificate) store(cert *tls.Certificate) {
+	s.current.Store(cert)
+	serverCertificateExpiry.WithLabelValues(s.Name).Set(float64(cert.Leaf.NotAfter.Unix()))
+}
+
+func (s *serverCertificate) event(eventType, reason, messageFmt string, args ...interface{}) {
+	if s.Recorder != nil && s.EventObject != nil {
+		s.Recorder.Eventf(s.EventObject, eventType, reason, messageFmt, args...)
+	}
+}
+
+// watchExpiry reports the served certificate once it is within the expiry warning period, and again for each rotated
+// certificate that is
+func (s *serverCertificate) watchExpiry(ctx context.Context) {
+	ticker := time.NewTicker(serverCertificateCheckInterval)
+	defer ticker.Stop()
+
+	reported := ""
+	for {
+		leaf := s.current.Load().Leaf
+		remaining := time.Until(leaf.NotAfter)
+		if serial := leaf.SerialNumber.String(); remaining < ServerCertificateExpiryWarning && serial != reported {
+			reported = serial
+			slog.Warn("Server certificate is expiring", slog.String("server", s.Name),
+				slog.String("serial", serial), slog.Time("notAfter", leaf.NotAfter))
+			s.event(corev1.EventTypeWarning, ServerCertificateExpiring,
+				"Certificate of the %s server expires at %s", s.Name, leaf.NotAfter.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetServerTLSConfig creates a tls.Config that uses a dynamic loader to handle updates to the certificate and/or key.
+// The certificate is validated against the options when loaded, failing the setup if it is invalid, and rotated
+// content that fails validation is rejected in favour of the certificate already served.
+func GetServerTLSConfig(ctx context.Context, certFile, keyFile string, opts ServerTLSOptions) (*tls.Config, error) {
+	loader, err := dynamiccertificates.NewDynamicServingContentFromFiles(opts.Name, certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup certificate loader: %w", err)
+	}
+
+	certPEM, keyPEM := loader.CurrentCertKeyContent()
+	cert, err := ValidateServerCertificate(certPEM, keyPEM, opts.Hostnames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server certificate: %w", err)
+	}
+
+	served := &serverCertificate{ServerTLSOptions: opts, loader: loader}
+	served.store(cert)
+	loader.AddListener(served)
+	go loader.Run(ctx, 1)
+	go served.watchExpiry(ctx)
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return served.current.Load(), nil
+		},
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,100 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func generateServerCertificate(t *testing.T, dnsNames ...string) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestValidateServerCertificate(t *testing.T) {
+	const service = "oran-hwmgr-plugin-controller-manager.oran-hwmgr-plugin.svc"
+	certPEM, keyPEM := generateServerCertificate(t, service, service+".cluster.local")
+	_, otherKeyPEM := generateServerCertificate(t, service)
+
+	tests := []struct {
+		description string
+		keyPEM      []byte
+		hostnames   []string
+		expectError bool
+	}{
+		{
+			description: "no hostnames",
+			keyPEM:      keyPEM,
+		},
+		{
+			description: "hostnames covered",
+			keyPEM:      keyPEM,
+			hostnames:   []string{service, service + ".cluster.local"},
+		},
+		{
+			description: "hostname not covered",
+			keyPEM:      keyPEM,
+			hostnames:   []string{service, "hwmgr.example.com"},
+			expectError: true,
+		},
+		{
+			description: "key does not match",
+			keyPEM:      otherKeyPEM,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cert, err := ValidateServerCertificate(certPEM, test.keyPEM, test.hostnames)
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cert.Leaf == nil {
+				t.Error("expected the certificate leaf to be parsed")
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+// NodePool extensions defining the strategy for rolling out hardware profile changes to the nodes of a NodePool
+const (
+	UpdateMaxConcurrentKey = "updateMaxConcurrent"
+	UpdateBatchSizeKey     = "updateBatchSize"
+	UpdateBatchPauseKey    = "updateBatchPause"
+	UpdateCanaryKey        = "updateCanary"
+
+	// UpdateBatchAnnotation tracks the batch of nodes being updated on a NodePool, as JSON
+	UpdateBatchAnnotation = "hwmgr-plugin.oran.openshift.io/update-batch"
+)
+
+// UpdateStrategy controls how many nodes of a NodePool are updated at once when its hardware profiles change. By
+// default, nodes are updated one at a time.
+type UpdateStrategy struct {
+	// MaxConcurrent is the maximum number of nodes updating at once
+	MaxConcurrent int
+	// BatchSize is the number of nodes in each batch. A batch completes before the next one starts.
+	BatchSize int
+	// BatchPause is the time to wait after a batch completes before starting the next one
+	BatchPause time.Duration
+	// Canary updates a single node in the first batch, so a bad profile is caught before it reaches more nodes
+	Canary bool
+
+	// batched is set if any batch setting is provided, requiring the progress of batches to be tracked
+	batched bool
+}
+
+// UpdateBatch records the progress of the current batch of a NodePool update
+type UpdateBatch struct {
+	Generation  int64        `json:"generation"`
+	Number      int          `json:"number"`
+	Nodes       []string     `json:"nodes"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// GetUpdateStrategy parses the update strategy from the NodePool extensions
+func GetUpdateStrategy(nodepool *hwmgmtv1alpha1.NodePool) (UpdateStrategy, error) {
+	strategy := UpdateStrategy{MaxConcurrent: 1}
+	extensions := nodepool.Spec.Extensions
+
+	if value, exists := extensions[UpdateMaxConcurrentKey]; exists {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return strategy, typederrors.NewInputError("invalid %s extension %q: must be a positive integer", UpdateMaxConcurrentKey, value)
+		}
+		strategy.MaxConcurrent = n
+	}
+
+	strategy.BatchSize = strategy.MaxConcurrent
+	if value, exists := extensions[UpdateBatchSizeKey]; exists {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return strategy, typederrors.NewInputError("invalid %s extension %q: must be a positive integer", UpdateBatchSizeKey, value)
+		}
+		strategy.BatchSize = n
+		strategy.batched = true
+	}
+
+	if value, exists := extensions[UpdateBatchPauseKey]; exists {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return strategy, typederrors.NewInputError("invalid %s extension %q: must be a duration", UpdateBatchPauseKey, value)
+		}
+		strategy.BatchPause = d
+		strategy.batched = true
+	}
+
+	if value, exists := extensions[UpdateCanaryKey]; exists {
+		canary, err := strconv.ParseBool(value)
+		if err != nil {
+			return strategy, typederrors.NewInputError("invalid %s extension %q: must be a boolean", UpdateCanaryKey, value)
+		}
+		strategy.Canary = canary
+		strategy.batched = strategy.batched || canary
+	}
+
+	return strategy, nil
+}
+
+// GetUpdateBatch returns the batch recorded on the NodePool for its current generation, or nil if there is none
+func GetUpdateBatch(nodepool *hwmgmtv1alpha1.NodePool) *UpdateBatch {
+	value := nodepool.GetAnnotations()[UpdateBatchAnnotation]
+	if value == "" {
+		return nil
+	}
+
+	batch := &UpdateBatch{}
+	if err := json.Unmarshal([]byte(value), batch); err != nil || batch.Generation != nodepool.Generation {
+		return nil
+	}
+	return batch
+}
+
+// setUpdateBatch records the batch on the NodePool, or removes it if nil
+func setUpdateBatch(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool, batch *UpdateBatch) error {
+	var value string
+	if batch != nil {
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return fmt.Errorf("failed to marshal update batch: %w", err)
+		}
+		value = string(data)
+	}
+
+	// nolint: wrapcheck
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		newNodepool := &hwmgmtv1alpha1.NodePool{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), newNodepool); err != nil {
+			return err
+		}
+
+		annotations := newNodepool.GetAnnotations()
+		if batch == nil {
+			if _, exists := annotations[UpdateBatchAnnotation]; !exists {
+				return nil
+			}
+			delete(annotations, UpdateBatchAnnotation)
+		} else {
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[UpdateBatchAnnotation] = value
+		}
+		newNodepool.SetAnnotations(annotations)
+
+		if err := c.Update(ctx, newNodepool); err != nil {
+			return err
+		}
+		nodepool.SetAnnotations(newNodepool.GetAnnotations())
+		return nil
+	})
+}
+
+// ClearUpdateBatch removes the batch tracking from the NodePool once its update has completed
+func ClearUpdateBatch(ctx context.Context, c client.Client, nodepool *hwmgmtv1alpha1.NodePool) error {
+	if _, exists := nodepool.GetAnnotations()[UpdateBatchAnnotation]; !exists {
+		return nil
+	}
+	if err := setUpdateBatch(ctx, c, nodepool, nil); err != nil {
+		return fmt.Errorf("failed to clear update batch on nodepool %s: %w", nodepool.Name, err)
+	}
+	return nil
+}
+
+// PlanNodeUpdates applies the update strategy of the NodePool, given the nodes pending an update and the number of
+// nodes currently updating. It returns the nodes to start updating now, in order, or the time to wait before the next
+// batch can be started if none can be started yet.
+func PlanNodeUpdates(
+	ctx context.Context,
+	c client.Client,
+	nodepool *hwmgmtv1alpha1.NodePool,
+	strategy UpdateStrategy,
+	pending []*hwmgmtv1alpha1.Node,
+	updating int) ([]*hwmgmtv1alpha1.Node, time.Duration, error) {
+
+	slots := strategy.MaxConcurrent - updating
+	if !strategy.batched {
+		// Rolling update, with no batches to track
+		if slots <= 0 || len(pending) == 0 {
+			return nil, 0, nil
+		}
+		return pending[:min(slots, len(pending))], 0, nil
+	}
+
+	pendingByName := make(map[string]*hwmgmtv1alpha1.Node, len(pending))
+	for _, node := range pending {
+		pendingByName[node.Name] = node
+	}
+
+	batch := GetUpdateBatch(nodepool)
+	if batch != nil && batch.CompletedAt == nil {
+		var remaining []*hwmgmtv1alpha1.Node
+		for _, name := range batch.Nodes {
+			if node, exists := pendingByName[name]; exists {
+				remaining = append(remaining, node)
+			}
+		}
+
+		if len(remaining) > 0 {
+			if slots <= 0 {
+				return nil, 0, nil
+			}
+			return remaining[:min(slots, len(remaining))], 0, nil
+		}
+
+		if updating > 0 {
+			// Wait for the rest of the batch to finish
+			return nil, 0, nil
+		}
+
+		now := metav1.Now()
+		batch.CompletedAt = &now
+		if err := setUpdateBatch(ctx, c, nodepool, batch); err != nil {
+			return nil, 0, fmt.Errorf("failed to record completed update batch on nodepool %s: %w", nodepool.Name, err)
+		}
+	}
+
+	if len(pending) == 0 || updating > 0 {
+		return nil, 0, nil
+	}
+
+	number := 0
+	if batch != nil {
+		if elapsed := time.Since(batch.CompletedAt.Time); elapsed < strategy.BatchPause {
+			return nil, strategy.BatchPause - elapsed, nil
+		}
+		number = batch.Number + 1
+	}
+
+	size := strategy.BatchSize
+	if strategy.Canary && number == 0 {
+		size = 1
+	}
+	nodes := pending[:min(size, len(pending))]
+
+	next := &UpdateBatch{Generation: nodepool.Generation, Number: number}
+	for _, node := range nodes {
+		next.Nodes = append(next.Nodes, node.Name)
+	}
+	if err := setUpdateBatch(ctx, c, nodepool, next); err != nil {
+		return nil, 0, fmt.Errorf("failed to record update batch on nodepool %s: %w", nodepool.Name, err)
+	}
+
+	return nodes[:min(strategy.MaxConcurrent, len(nodes))], 0, nil
+}
@@ -0,0 +1,334 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	typederrors "github.com/openshift-kni/oran-hwmgr-plugin/internal/typed-errors"
+)
+
+func TestGetUpdateStrategy(t *testing.T) {
+	tests := []struct {
+		description string
+		extensions  map[string]string
+		expected    UpdateStrategy
+		inputError  bool
+	}{
+		{
+			description: "default rolling update",
+			expected:    UpdateStrategy{MaxConcurrent: 1, BatchSize: 1},
+		},
+		{
+			description: "max concurrent sets the batch size without batching",
+			extensions:  map[string]string{UpdateMaxConcurrentKey: "3"},
+			expected:    UpdateStrategy{MaxConcurrent: 3, BatchSize: 3},
+		},
+		{
+			description: "batch size",
+			extensions:  map[string]string{UpdateMaxConcurrentKey: "2", UpdateBatchSizeKey: "4"},
+			expected:    UpdateStrategy{MaxConcurrent: 2, BatchSize: 4, batched: true},
+		},
+		{
+			description: "batch pause",
+			extensions:  map[string]string{UpdateBatchPauseKey: "30m"},
+			expected:    UpdateStrategy{MaxConcurrent: 1, BatchSize: 1, BatchPause: 30 * time.Minute, batched: true},
+		},
+		{
+			description: "canary",
+			extensions:  map[string]string{UpdateBatchSizeKey: "5", UpdateCanaryKey: "true"},
+			expected:    UpdateStrategy{MaxConcurrent: 1, BatchSize: 5, Canary: true, batched: true},
+		},
+		{
+			description: "disabled canary does not batch",
+			extensions:  map[string]string{UpdateCanaryKey: "false"},
+			expected:    UpdateStrategy{MaxConcurrent: 1, BatchSize: 1},
+		},
+		{
+			description: "zero max concurrent",
+			extensions:  map[string]string{UpdateMaxConcurrentKey: "0"},
+			inputError:  true,
+		},
+		{
+			description: "invalid batch size",
+			extensions:  map[string]string{UpdateBatchSizeKey: "all"},
+			inputError:  true,
+		},
+		{
+			description: "negative batch pause",
+			extensions:  map[string]string{UpdateBatchPauseKey: "-1m"},
+			inputError:  true,
+		},
+		{
+			description: "invalid canary",
+			extensions:  map[string]string{UpdateCanaryKey: "maybe"},
+			inputError:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			nodepool := &hwmgmtv1alpha1.NodePool{Spec: hwmgmtv1alpha1.NodePoolSpec{Extensions: test.extensions}}
+			strategy, err := GetUpdateStrategy(nodepool)
+			if test.inputError {
+				if !typederrors.IsInputError(err) {
+					t.Errorf("expected an input error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strategy != test.expected {
+				t.Errorf("expected strategy %+v, got %+v", test.expected, strategy)
+			}
+		})
+	}
+}
+
+func TestFindNodesToUpdate(t *testing.T) {
+	configured := func(reason hwmgmtv1alpha1.ConditionReason, status metav1.ConditionStatus) []metav1.Condition {
+		return []metav1.Condition{{Type: string(hwmgmtv1alpha1.Configured), Reason: string(reason), Status: status}}
+	}
+	node := func(name, group, profile string, conditions []metav1.Condition) hwmgmtv1alpha1.Node {
+		return hwmgmtv1alpha1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       hwmgmtv1alpha1.NodeSpec{GroupName: group, HwProfile: profile},
+			Status:     hwmgmtv1alpha1.NodeStatus{Conditions: conditions},
+		}
+	}
+
+	overridden := node("overridden", "worker", "profile-v2", configured(hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue))
+	overridden.Annotations = map[string]string{NodeHwProfileOverrideAnnotation: "profile-pilot"}
+
+	nodelist := &hwmgmtv1alpha1.NodeList{Items: []hwmgmtv1alpha1.Node{
+		node("other-group", "master", "profile-v1", nil),
+		node("stale", "worker", "profile-v1", configured(hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue)),
+		node("configured", "worker", "profile-v2", configured(hwmgmtv1alpha1.ConfigApplied, metav1.ConditionTrue)),
+		node("unconfigured", "worker", "profile-v2", nil),
+		node("invalid-input", "worker", "profile-v2", configured(hwmgmtv1alpha1.InvalidInput, metav1.ConditionFalse)),
+		node("failed", "worker", "profile-v2", configured(hwmgmtv1alpha1.Failed, metav1.ConditionFalse)),
+		overridden,
+	}}
+
+	var names []string
+	for _, node := range FindNodesToUpdate(nodelist, "worker", "profile-v2") {
+		names = append(names, node.Name)
+	}
+
+	// A node whose update failed for reasons other than its inputs is not retried
+	expected := []string{"stale", "unconfigured", "invalid-input", "overridden"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected nodes %v to be updated, got %v", expected, names)
+	}
+}
+
+func TestPlanNodeUpdates(t *testing.T) {
+	completedAgo := func(d time.Duration) *metav1.Time {
+		completed := metav1.NewTime(time.Now().Add(-d))
+		return &completed
+	}
+
+	tests := []struct {
+		description string
+		extensions  map[string]string
+		// batch is the batch recorded on the NodePool before planning
+		batch    *UpdateBatch
+		pending  []string
+		updating int
+
+		expectedStart []string
+		expectedWait  bool
+		// expectedBatch is the batch recorded on the NodePool after planning, compared without its completion time
+		expectedBatch *UpdateBatch
+		// expectCompleted is whether the recorded batch is marked complete
+		expectCompleted bool
+	}{
+		{
+			description:   "rolling update limited by max concurrent",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "2"},
+			pending:       []string{"n1", "n2", "n3"},
+			expectedStart: []string{"n1", "n2"},
+		},
+		{
+			description:   "rolling update counts the nodes in progress",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "2"},
+			pending:       []string{"n1", "n2", "n3"},
+			updating:      1,
+			expectedStart: []string{"n1"},
+		},
+		{
+			description: "rolling update with no free slots",
+			extensions:  map[string]string{UpdateMaxConcurrentKey: "2"},
+			pending:     []string{"n1", "n2", "n3"},
+			updating:    2,
+		},
+		{
+			description:   "batch size larger than the pending nodes",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "5", UpdateBatchSizeKey: "5"},
+			pending:       []string{"n1", "n2", "n3"},
+			expectedStart: []string{"n1", "n2", "n3"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2", "n3"}},
+		},
+		{
+			description:   "batch size equal to the pending nodes",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "3", UpdateBatchSizeKey: "3"},
+			pending:       []string{"n1", "n2", "n3"},
+			expectedStart: []string{"n1", "n2", "n3"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2", "n3"}},
+		},
+		{
+			description:   "batch size smaller than the pending nodes",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "3", UpdateBatchSizeKey: "2"},
+			pending:       []string{"n1", "n2", "n3"},
+			expectedStart: []string{"n1", "n2"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2"}},
+		},
+		{
+			description:   "batch larger than max concurrent",
+			extensions:    map[string]string{UpdateBatchSizeKey: "3"},
+			pending:       []string{"n1", "n2", "n3"},
+			expectedStart: []string{"n1"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2", "n3"}},
+		},
+		{
+			description:   "canary batch",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "3", UpdateBatchSizeKey: "3", UpdateCanaryKey: "true"},
+			pending:       []string{"n1", "n2", "n3"},
+			expectedStart: []string{"n1"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1"}},
+		},
+		{
+			description: "pause after the canary",
+			extensions: map[string]string{UpdateMaxConcurrentKey: "3", UpdateBatchSizeKey: "3", UpdateCanaryKey: "true",
+				UpdateBatchPauseKey: "1h"},
+			batch:           &UpdateBatch{Generation: 1, Nodes: []string{"n1"}, CompletedAt: completedAgo(time.Minute)},
+			pending:         []string{"n2", "n3", "n4"},
+			expectedWait:    true,
+			expectedBatch:   &UpdateBatch{Generation: 1, Nodes: []string{"n1"}},
+			expectCompleted: true,
+		},
+		{
+			description: "batch after the canary pause",
+			extensions: map[string]string{UpdateMaxConcurrentKey: "3", UpdateBatchSizeKey: "3", UpdateCanaryKey: "true",
+				UpdateBatchPauseKey: "1h"},
+			batch:         &UpdateBatch{Generation: 1, Nodes: []string{"n1"}, CompletedAt: completedAgo(2 * time.Hour)},
+			pending:       []string{"n2", "n3", "n4"},
+			expectedStart: []string{"n2", "n3", "n4"},
+			expectedBatch: &UpdateBatch{Generation: 1, Number: 1, Nodes: []string{"n2", "n3", "n4"}},
+		},
+		{
+			description:   "nodes in progress are counted against the batch",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "2", UpdateBatchSizeKey: "3"},
+			batch:         &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2", "n3"}},
+			pending:       []string{"n2", "n3", "n4"},
+			updating:      1,
+			expectedStart: []string{"n2"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2", "n3"}},
+		},
+		{
+			description:   "batch waits for the nodes in progress",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "2", UpdateBatchSizeKey: "2"},
+			batch:         &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2"}},
+			pending:       []string{"n3"},
+			updating:      1,
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2"}},
+		},
+		{
+			// A node that failed is neither pending nor updating, so it does not hold up the rollout
+			description:     "batch with a failed node completes",
+			extensions:      map[string]string{UpdateMaxConcurrentKey: "2", UpdateBatchSizeKey: "2", UpdateBatchPauseKey: "10m"},
+			batch:           &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2"}},
+			pending:         []string{"n3"},
+			expectedWait:    true,
+			expectedBatch:   &UpdateBatch{Generation: 1, Nodes: []string{"n1", "n2"}},
+			expectCompleted: true,
+		},
+		{
+			description:   "batch of an earlier generation is ignored",
+			extensions:    map[string]string{UpdateMaxConcurrentKey: "2", UpdateBatchSizeKey: "2"},
+			batch:         &UpdateBatch{Generation: 0, Number: 3, Nodes: []string{"n1", "n2"}},
+			pending:       []string{"n3"},
+			expectedStart: []string{"n3"},
+			expectedBatch: &UpdateBatch{Generation: 1, Nodes: []string{"n3"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			nodepool := &hwmgmtv1alpha1.NodePool{
+				ObjectMeta: metav1.ObjectMeta{Name: "np1", Namespace: "oran-hwmgr-plugin", Generation: 1},
+				Spec:       hwmgmtv1alpha1.NodePoolSpec{Extensions: test.extensions},
+			}
+			if test.batch != nil {
+				data, err := json.Marshal(test.batch)
+				if err != nil {
+					t.Fatalf("failed to marshal batch: %v", err)
+				}
+				nodepool.Annotations = map[string]string{UpdateBatchAnnotation: string(data)}
+			}
+			c := newNodePoolStatusTestClient(t, nodepool)
+
+			strategy, err := GetUpdateStrategy(nodepool)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var pending []*hwmgmtv1alpha1.Node
+			for _, name := range test.pending {
+				pending = append(pending, &hwmgmtv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			}
+
+			start, wait, err := PlanNodeUpdates(ctx, c, nodepool, strategy, pending, test.updating)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, node := range start {
+				names = append(names, node.Name)
+			}
+			if !slices.Equal(names, test.expectedStart) {
+				t.Errorf("expected nodes %v to be started, got %v", test.expectedStart, names)
+			}
+			if (wait > 0) != test.expectedWait {
+				t.Errorf("expected to wait for the next batch: %t, got %s", test.expectedWait, wait)
+			}
+
+			updated := &hwmgmtv1alpha1.NodePool{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(nodepool), updated); err != nil {
+				t.Fatalf("failed to get nodepool: %v", err)
+			}
+			updated.Generation = nodepool.Generation
+			batch := GetUpdateBatch(updated)
+			if test.expectedBatch == nil {
+				if batch != nil {
+					t.Errorf("expected no batch to be recorded, got %+v", batch)
+				}
+				return
+			}
+			if batch == nil {
+				t.Fatalf("expected batch %+v to be recorded", test.expectedBatch)
+			}
+			if batch.Generation != test.expectedBatch.Generation || batch.Number != test.expectedBatch.Number ||
+				!slices.Equal(batch.Nodes, test.expectedBatch.Nodes) {
+				t.Errorf("expected batch %+v, got %+v", test.expectedBatch, batch)
+			}
+			if (batch.CompletedAt != nil) != test.expectCompleted {
+				t.Errorf("expected the batch to be completed: %t", test.expectCompleted)
+			}
+		})
+	}
+}
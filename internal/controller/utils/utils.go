@@ -43,6 +43,12 @@ const (
 	JobIdAnnotation         = "hwmgr-plugin.oran.openshift.io/jobId"
 	DeletionJobIdAnnotation = "hwmgr-plugin.oran.openshift.io/deletionJobId"
 	ConfigAnnotation        = "hwmgr-plugin.oran.openshift.io/config-in-progress"
+
+	// NodeRemovalJobIdAnnotation is the job releasing the hardware of nodes removed from a provisioned NodePool
+	NodeRemovalJobIdAnnotation = "hwmgr-plugin.oran.openshift.io/nodeRemovalJobId"
+
+	// JobIdIndexKey is the field index of NodePool and Node CRs by their in-progress hardware manager job IDs
+	JobIdIndexKey = "metadata.annotations.jobId"
 )
 
 func UpdateK8sCRStatus(ctx context.Context, c client.Client, object client.Object) error {
@@ -266,6 +272,18 @@ func ClearDeletionJobId(object client.Object) {
 	}
 }
 
+// GetJobIds returns the in-progress hardware manager job IDs recorded on the object, for use as JobIdIndexKey values
+func GetJobIds(object client.Object) []string {
+	var jobIds []string
+	nodeRemovalJobId := object.GetAnnotations()[NodeRemovalJobIdAnnotation]
+	for _, jobId := range []string{GetJobId(object), GetDeletionJobId(object), nodeRemovalJobId} {
+		if jobId != "" {
+			jobIds = append(jobIds, jobId)
+		}
+	}
+	return jobIds
+}
+
 func GetConfigAnnotation(object client.Object) string {
 	annotations := object.GetAnnotations()
 	if annotations == nil {
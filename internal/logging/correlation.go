@@ -0,0 +1,84 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+//
+// Correlation IDs tie together the logs, outgoing hardware manager requests, and status conditions resulting from a
+// single reconcile or API request. An ID is generated at each entry point, or taken from the incoming request, and
+// carried through the execution flow in the context.
+//
+
+const (
+	correlationIDKey loggingContextKey = "correlation_id"
+
+	// CorrelationIDAttr is the log attribute carrying the correlation ID
+	CorrelationIDAttr = "correlationID"
+
+	// CorrelationIDHeader is the HTTP header carrying the correlation ID on incoming API requests and outgoing
+	// hardware manager requests. The same name, in lower case, is used for gRPC metadata.
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+// correlationIDPattern restricts the correlation IDs accepted from clients, as they end up in logs and conditions
+var correlationIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// NewCorrelationID generates a new correlation ID
+func NewCorrelationID() string {
+	return uuid.NewString()
+}
+
+// IsValidCorrelationID checks whether a correlation ID provided by a client can be used
+func IsValidCorrelationID(id string) bool {
+	return correlationIDPattern.MatchString(id)
+}
+
+// WithCorrelationID adds a correlation ID to the provided context, both as a value and as a log attribute. An empty
+// ID is replaced with a newly generated one.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = NewCorrelationID()
+	}
+	return context.WithValue(AppendCtx(ctx, slog.String(CorrelationIDAttr, id)), correlationIDKey, id)
+}
+
+// EnsureCorrelationID returns a context carrying a correlation ID, generating a new one if the provided context does
+// not already have one
+func EnsureCorrelationID(ctx context.Context) context.Context {
+	if CorrelationIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithCorrelationID(ctx, "")
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by the context, or an empty string if none
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(correlationIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// AddCorrelationIDHeader sets the correlation ID header of an outgoing request from its context. Its signature
+// matches the request editor functions of the generated API clients.
+func AddCorrelationIDHeader(ctx context.Context, req *http.Request) error {
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+//
+// The log levels of the Plugin can be overridden for the work done on behalf of a single HardwareManager, so that
+// verbose logs, such as the tracing of hardware manager messages, can be enabled for it at runtime. The overrides of
+// each HardwareManager are registered as its configuration changes, and applied to the logs of each component whose
+// context carries the name of the HardwareManager.
+//
+
+// Component identifies a part of the Plugin whose log level can be set separately
+type Component string
+
+// Components of the Plugin with their own log level
+var Components = struct {
+	Adaptor     Component
+	HwMgrClient Component
+	Server      Component
+}{
+	Adaptor:     "adaptor",
+	HwMgrClient: "hwmgrclient",
+	Server:      "server",
+}
+
+const (
+	hwmgrKey loggingContextKey = "hwmgr"
+
+	// HwMgrAttr is the log attribute carrying the name of the HardwareManager
+	HwMgrAttr = "hwmgr"
+)
+
+// HwMgrLevels holds the log levels configured for a HardwareManager
+type HwMgrLevels struct {
+	// Level applies to the components without a level of their own. If nil, the default level is used.
+	Level *slog.Level
+
+	// Components holds the level of individual components
+	Components map[Component]slog.Level
+}
+
+// Get returns the level configured for a component, if any
+func (l HwMgrLevels) Get(component Component) (slog.Level, bool) {
+	if level, exists := l.Components[component]; exists && component != "" {
+		return level, true
+	}
+	if l.Level != nil {
+		return *l.Level, true
+	}
+	return 0, false
+}
+
+var hwmgrLevels sync.Map
+
+// SetHwMgrLevels registers the log levels of a HardwareManager, replacing any previously registered
+func SetHwMgrLevels(hwmgr string, levels HwMgrLevels) {
+	if levels.Level == nil && len(levels.Components) == 0 {
+		hwmgrLevels.Delete(hwmgr)
+		return
+	}
+	hwmgrLevels.Store(hwmgr, levels)
+}
+
+// ClearHwMgrLevels removes the log levels of a HardwareManager, so that the default levels apply to it again
+func ClearHwMgrLevels(hwmgr string) {
+	hwmgrLevels.Delete(hwmgr)
+}
+
+// GetHwMgrLevel returns the log level registered for a component on behalf of a HardwareManager, if any
+func GetHwMgrLevel(hwmgr string, component Component) (slog.Level, bool) {
+	value, exists := hwmgrLevels.Load(hwmgr)
+	if !exists {
+		return 0, false
+	}
+	return value.(HwMgrLevels).Get(component)
+}
+
+// ParseLevel converts a level name, as used in the HardwareManager configuration, to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level: %s", name)
+}
+
+// WithHwMgr adds the name of the HardwareManager being worked on to the provided context, both as a value, so that
+// its log levels are applied, and as a log attribute
+func WithHwMgr(ctx context.Context, hwmgr string) context.Context {
+	return context.WithValue(AppendCtx(ctx, slog.String(HwMgrAttr, hwmgr)), hwmgrKey, hwmgr)
+}
+
+// HwMgrFromContext returns the name of the HardwareManager carried by the context, or an empty string if none
+func HwMgrFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if hwmgr, ok := ctx.Value(hwmgrKey).(string); ok {
+		return hwmgr
+	}
+	return ""
+}
@@ -9,6 +9,8 @@ package logging
 import (
 	"context"
 	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 //
@@ -24,8 +26,9 @@ const (
 )
 
 type LoggingContextHandler struct {
-	handler slog.Handler
-	level   slog.Level
+	handler   slog.Handler
+	level     slog.Level
+	component Component
 }
 
 // Handle adds attributes from the context to the log record
@@ -36,10 +39,22 @@ func (h LoggingContextHandler) Handle(ctx context.Context, record slog.Record) e
 		}
 	}
 
+	// Tie the log to the trace of the request, if it is being traced
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("traceID", spanContext.TraceID().String()))
+	}
+
 	return h.handler.Handle(ctx, record) // nolint: wrapcheck
 }
 
+// Enabled applies the log level registered for the component on behalf of the HardwareManager carried by the
+// context, if any, or else the level of the handler
 func (h LoggingContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if hwmgr := HwMgrFromContext(ctx); hwmgr != "" {
+		if hwmgrLevel, exists := GetHwMgrLevel(hwmgr, h.component); exists {
+			return level >= hwmgrLevel
+		}
+	}
 	return level >= h.level
 }
 
@@ -47,14 +62,14 @@ func (h LoggingContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	if len(attrs) == 0 {
 		return h
 	}
-	return LoggingContextHandler{handler: h.handler.WithAttrs(attrs), level: h.level}
+	return LoggingContextHandler{handler: h.handler.WithAttrs(attrs), level: h.level, component: h.component}
 }
 
 func (h LoggingContextHandler) WithGroup(name string) slog.Handler {
 	if name == "" {
 		return h
 	}
-	return LoggingContextHandler{handler: h.handler.WithGroup(name), level: h.level}
+	return LoggingContextHandler{handler: h.handler.WithGroup(name), level: h.level, component: h.component}
 }
 
 func NewLoggingContextHandler(level slog.Level) *LoggingContextHandler {
@@ -64,6 +79,31 @@ func NewLoggingContextHandler(level slog.Level) *LoggingContextHandler {
 	}
 }
 
+// NewComponentLoggingContextHandler creates a handler for the logs of a component, whose level can be overridden for
+// each HardwareManager
+func NewComponentLoggingContextHandler(component Component, level slog.Level) *LoggingContextHandler {
+	return &LoggingContextHandler{
+		handler:   slog.Default().Handler(),
+		level:     level,
+		component: component,
+	}
+}
+
+// ComponentLogger returns a logger that writes the logs of a component through the handler of the provided logger.
+// Loggers that do not use a LoggingContextHandler are returned unchanged.
+func ComponentLogger(logger *slog.Logger, component Component) *slog.Logger {
+	switch h := logger.Handler().(type) {
+	case LoggingContextHandler:
+		h.component = component
+		return slog.New(h)
+	case *LoggingContextHandler:
+		handler := *h
+		handler.component = component
+		return slog.New(handler)
+	}
+	return logger
+}
+
 // AppendCtx adds an slog attribute to the provided context so that it will be
 // included in any Record created with such context
 func AppendCtx(ctx context.Context, attr slog.Attr) context.Context {
@@ -0,0 +1,93 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+)
+
+// JobCallbackPattern is the route on which hardware managers post job status updates
+const JobCallbackPattern = "POST /hardware-manager/callbacks/v1/managers/{hwMgrId}/jobs"
+
+// maxJobCallbackSize limits the size of a job callback request body
+const maxJobCallbackSize = 64 * 1024
+
+// JobCallbackServer receives job status callbacks from the hardware managers. The callback itself only triggers a
+// reconcile of the affected NodePools, which then query the job status from the hardware manager as they would when
+// polling, so a callback can never change a NodePool on its own.
+type JobCallbackServer struct {
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+	JobEvents    chan<- event.GenericEvent
+}
+
+// HandleJobCallback handles a job status callback from a hardware manager
+func (s *JobCallbackServer) HandleJobCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	hwMgrId := r.PathValue("hwMgrId")
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		ProblemDetails(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	if status, err := s.HwMgrAdaptor.AuthenticateJobCallback(ctx, hwMgrId, token); err != nil {
+		serverLog.InfoContext(ctx, "Rejected job callback", slog.String("error", err.Error()))
+		ProblemDetails(w, http.StatusText(status), status)
+		return
+	}
+
+	callback := adaptors.JobCallback{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobCallbackSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&callback); err != nil {
+		ProblemDetails(w, "invalid job callback: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := callback.Validate(); err != nil {
+		ProblemDetails(w, "invalid job callback: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nodepools, err := s.HwMgrAdaptor.FindJobNodePools(ctx, hwMgrId, callback.JobId)
+	if err != nil {
+		serverLog.ErrorContext(ctx, "Failed to find NodePools for job callback",
+			slog.String("jobId", callback.JobId), slog.String("error", err.Error()))
+		ProblemDetails(w, "unable to process job callback", http.StatusServiceUnavailable)
+		return
+	}
+
+	for _, nodepool := range nodepools {
+		serverLog.InfoContext(ctx, "Job callback received, triggering NodePool reconcile",
+			slog.String("jobId", callback.JobId),
+			slog.String("status", callback.Status),
+			slog.String("nodepool", nodepool.Name))
+
+		select {
+		case s.JobEvents <- event.GenericEvent{Object: nodepool}:
+		case <-ctx.Done():
+			ProblemDetails(w, "unable to process job callback", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	if len(nodepools) == 0 {
+		// The job may already have been handled through polling
+		serverLog.InfoContext(ctx, "No NodePool waiting on job callback",
+			slog.String("jobId", callback.JobId))
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
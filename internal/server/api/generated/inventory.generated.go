@@ -12,10 +12,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/oapi-codegen/runtime"
@@ -23,6 +25,21 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for NodePoolJobOperation.
+const (
+	Allocation    NodePoolJobOperation = "Allocation"
+	ProfileUpdate NodePoolJobOperation = "ProfileUpdate"
+	Release       NodePoolJobOperation = "Release"
+)
+
+// Defines values for NodePoolJobPhase.
+const (
+	Completed  NodePoolJobPhase = "Completed"
+	Failed     NodePoolJobPhase = "Failed"
+	InProgress NodePoolJobPhase = "InProgress"
+	Missing    NodePoolJobPhase = "Missing"
+)
+
 // Defines values for ResourceInfoAdminState.
 const (
 	ResourceInfoAdminStateLOCKED       ResourceInfoAdminState = "LOCKED"
@@ -52,6 +69,12 @@ const (
 	UNKNOWN ResourceInfoUsageState = "UNKNOWN"
 )
 
+// Defines values for GetResourcesParamsFormat.
+const (
+	Json   GetResourcesParamsFormat = "json"
+	Ndjson GetResourcesParamsFormat = "ndjson"
+)
+
 // APIVersion Information about a version of the API.
 type APIVersion struct {
 	Version *string `json:"version,omitempty"`
@@ -63,6 +86,66 @@ type APIVersions struct {
 	UriPrefix   *string       `json:"uriPrefix,omitempty"`
 }
 
+// NetworkInterfaceInfo Information about a network interface
+type NetworkInterfaceInfo struct {
+	// Labels Labels identifying the role of the network interface, such as the data or boot interface
+	Labels *[]string `json:"labels,omitempty"`
+
+	// MacAddress The MAC address of the network interface
+	MacAddress string `json:"macAddress"`
+
+	// Model The model of the network interface card
+	Model *string `json:"model,omitempty"`
+
+	// Name The name of the network interface
+	Name *string `json:"name,omitempty"`
+
+	// Speed The link speed of the network interface in Mbps
+	Speed *int `json:"speed,omitempty"`
+}
+
+// NodePoolJob A hardware manager job run for a NodePool.
+type NodePoolJob struct {
+	// AllocationRecord Name of the allocation record the job is recorded in
+	AllocationRecord string `json:"allocationRecord"`
+
+	// CompletedAt The time the job was seen to have finished, successfully or not
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// FailCode Classification of the reason of a Failed job, such as InsufficientResources, or Unknown if it matches no
+	// classification rule
+	FailCode *string `json:"failCode,omitempty"`
+
+	// FailReason The reason given by the hardware manager for a Failed job
+	FailReason *string `json:"failReason,omitempty"`
+
+	// JobId Identifier of the job on the hardware manager
+	JobId string `json:"jobId"`
+
+	// LastTransitionTime The time the phase of the job last changed
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+
+	// NodeName The Node the job applies to, for per-node jobs
+	NodeName *string `json:"nodeName,omitempty"`
+
+	// ObservedAt The time the job was first seen
+	ObservedAt time.Time `json:"observedAt"`
+
+	// Operation The purpose of the job
+	Operation NodePoolJobOperation `json:"operation"`
+
+	// Phase The state of the job, as last reported by the hardware manager. A job purged by the hardware manager
+	// before its outcome was seen is Missing.
+	Phase *NodePoolJobPhase `json:"phase,omitempty"`
+}
+
+// NodePoolJobOperation The purpose of the job
+type NodePoolJobOperation string
+
+// NodePoolJobPhase The state of the job, as last reported by the hardware manager. A job purged by the hardware manager
+// before its outcome was seen is Missing.
+type NodePoolJobPhase string
+
 // ProblemDetails defines model for ProblemDetails.
 type ProblemDetails struct {
 	// AdditionalAttributes Any number of additional attributes, as defined in a specification or by an implementation.
@@ -108,6 +191,12 @@ type ResourceInfo struct {
 	// AdminState The administrative state of the resource
 	AdminState ResourceInfoAdminState `json:"adminState"`
 
+	// Allocated Whether the resource is allocated to a NodePool
+	Allocated *bool `json:"allocated,omitempty"`
+
+	// CloudId The cloud ID of the NodePool the resource is allocated to
+	CloudId *string `json:"cloudId,omitempty"`
+
 	// Description Human readable description of the resource.
 	Description string `json:"description"`
 
@@ -118,6 +207,9 @@ type ResourceInfo struct {
 	Groups    *[]string `json:"groups,omitempty"`
 	HwProfile string    `json:"hwProfile"`
 
+	// Interfaces The network interfaces of the resource
+	Interfaces *[]NetworkInterfaceInfo `json:"interfaces,omitempty"`
+
 	// Labels Optional labels applied to this resource
 	Labels *map[string]string `json:"labels,omitempty"`
 
@@ -130,6 +222,12 @@ type ResourceInfo struct {
 	// Name Short name for the resource.
 	Name string `json:"name"`
 
+	// NodeName The name of the Node for the resource, once allocated
+	NodeName *string `json:"nodeName,omitempty"`
+
+	// NodePoolName The name of the NodePool the resource is allocated to
+	NodePoolName *string `json:"nodePoolName,omitempty"`
+
 	// OperationalState The operational state of the resource
 	OperationalState ResourceInfoOperationalState `json:"operationalState"`
 
@@ -147,6 +245,9 @@ type ResourceInfo struct {
 	// SerialNumber The vendor serial number of the resource
 	SerialNumber string `json:"serialNumber"`
 
+	// Storage The storage devices of the resource
+	Storage *[]StorageInfo `json:"storage,omitempty"`
+
 	// Tags Keywords describing or classifying the resource instance
 	Tags       *[]string              `json:"tags,omitempty"`
 	UsageState ResourceInfoUsageState `json:"usageState"`
@@ -167,11 +268,32 @@ type ResourceInfoPowerState string
 // ResourceInfoUsageState defines model for ResourceInfo.UsageState.
 type ResourceInfoUsageState string
 
+// ResourcePoolCapacity Capacity of a resource pool.
+type ResourcePoolCapacity struct {
+	// Allocated The number of resources in the resource pool that are allocated
+	Allocated int `json:"allocated"`
+
+	// Free The number of resources in the resource pool that are not allocated
+	Free int `json:"free"`
+
+	// ResourcePoolId Identifier for the Resource Pool in the hardware manager instance.
+	ResourcePoolId string `json:"resourcePoolId"`
+
+	// SiteId Identifier for the location of the resource pool.
+	SiteId *string `json:"siteId,omitempty"`
+
+	// Total The number of resources in the resource pool
+	Total int `json:"total"`
+}
+
 // ResourcePoolInfo Information about a resource pool.
 type ResourcePoolInfo struct {
 	// Description Human readable description of the resource pool.
 	Description string `json:"description"`
 
+	// HostCounts The number of hosts in the resource pool by state, for hardware managers that report it.
+	HostCounts *map[string]int `json:"hostCounts,omitempty"`
+
 	// Name Human readable name of the resource pool.
 	Name string `json:"name"`
 
@@ -182,6 +304,30 @@ type ResourcePoolInfo struct {
 	SiteId *string `json:"siteId,omitempty"`
 }
 
+// StorageInfo Information about a storage device
+type StorageInfo struct {
+	// Capacity The capacity of the storage device in bytes
+	Capacity *int64 `json:"capacity,omitempty"`
+
+	// Model The vendor model name of the storage device
+	Model *string `json:"model,omitempty"`
+
+	// Name The name of the storage device
+	Name *string `json:"name,omitempty"`
+
+	// Protocol The protocol of the storage device, such as SATA, SAS, or NVMe
+	Protocol *string `json:"protocol,omitempty"`
+
+	// SerialNumber The vendor serial number of the storage device
+	SerialNumber *string `json:"serialNumber,omitempty"`
+
+	// Type The media type of the storage device, such as HDD or SSD
+	Type *string `json:"type,omitempty"`
+
+	// Vendor Vendor or manufacturer name of the storage device
+	Vendor *string `json:"vendor,omitempty"`
+}
+
 // Subscription Information about an inventory subscription.
 type Subscription struct {
 	// Callback The fully qualified URI to a consumer procedure which can process a Post of the
@@ -202,9 +348,28 @@ type Subscription struct {
 // HwMgrId defines model for hwMgrId.
 type HwMgrId = string
 
+// NodePoolName defines model for nodePoolName.
+type NodePoolName = string
+
 // SubscriptionId defines model for subscriptionId.
 type SubscriptionId = openapi_types.UUID
 
+// GetResourcePoolsParams defines parameters for GetResourcePools.
+type GetResourcePoolsParams struct {
+	// SiteId Only return the resource pools of the given site.
+	SiteId *string `form:"siteId,omitempty" json:"siteId,omitempty"`
+}
+
+// GetResourcesParams defines parameters for GetResources.
+type GetResourcesParams struct {
+	// Format Format of the response. With ndjson, the resources are streamed as they are read from the hardware manager,
+	// one JSON object per line, rather than returned as a single array.
+	Format *GetResourcesParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetResourcesParamsFormat defines parameters for GetResources.
+type GetResourcesParamsFormat string
+
 // CreateSubscriptionJSONRequestBody defines body for CreateSubscription for application/json ContentType.
 type CreateSubscriptionJSONRequestBody = Subscription
 
@@ -216,9 +381,15 @@ type ServerInterface interface {
 	// Get minor API versions
 	// (GET /hardware-manager/inventory/v1/api_versions)
 	GetMinorVersions(w http.ResponseWriter, r *http.Request)
+	// Retrieve the capacity of each resource pool
+	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/capacity)
+	GetResourcePoolCapacity(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId)
+	// Retrieve the hardware manager jobs run for a NodePool
+	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/nodePools/{nodePoolName}/jobs)
+	GetNodePoolJobs(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, nodePoolName NodePoolName)
 	// Retrieve the list of resource pools
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools)
-	GetResourcePools(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId)
+	GetResourcePools(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, params GetResourcePoolsParams)
 	// Retrieve exactly one resource pool
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId})
 	GetResourcePool(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, resourcePoolId string)
@@ -227,7 +398,7 @@ type ServerInterface interface {
 	GetResourcePoolResources(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, resourcePoolId string)
 	// Retrieve the list of resources
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resources)
-	GetResources(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId)
+	GetResources(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, params GetResourcesParams)
 	// Retrieve exactly one resource
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resources/{resourceId})
 	GetResource(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, resourceId string)
@@ -282,6 +453,65 @@ func (siw *ServerInterfaceWrapper) GetMinorVersions(w http.ResponseWriter, r *ht
 	handler.ServeHTTP(w, r)
 }
 
+// GetResourcePoolCapacity operation middleware
+func (siw *ServerInterfaceWrapper) GetResourcePoolCapacity(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "hwMgrId" -------------
+	var hwMgrId HwMgrId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "hwMgrId", r.PathValue("hwMgrId"), &hwMgrId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "hwMgrId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetResourcePoolCapacity(w, r, hwMgrId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetNodePoolJobs operation middleware
+func (siw *ServerInterfaceWrapper) GetNodePoolJobs(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "hwMgrId" -------------
+	var hwMgrId HwMgrId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "hwMgrId", r.PathValue("hwMgrId"), &hwMgrId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "hwMgrId", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "nodePoolName" -------------
+	var nodePoolName NodePoolName
+
+	err = runtime.BindStyledParameterWithOptions("simple", "nodePoolName", r.PathValue("nodePoolName"), &nodePoolName, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "nodePoolName", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetNodePoolJobs(w, r, hwMgrId, nodePoolName)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetResourcePools operation middleware
 func (siw *ServerInterfaceWrapper) GetResourcePools(w http.ResponseWriter, r *http.Request) {
 
@@ -296,8 +526,19 @@ func (siw *ServerInterfaceWrapper) GetResourcePools(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetResourcePoolsParams
+
+	// ------------- Optional query parameter "siteId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "siteId", r.URL.Query(), &params.SiteId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "siteId", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetResourcePools(w, r, hwMgrId)
+		siw.Handler.GetResourcePools(w, r, hwMgrId, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -389,8 +630,19 @@ func (siw *ServerInterfaceWrapper) GetResources(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetResourcesParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetResources(w, r, hwMgrId)
+		siw.Handler.GetResources(w, r, hwMgrId, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -674,6 +926,8 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/api_versions", wrapper.GetAllVersions)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/api_versions", wrapper.GetMinorVersions)
+	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/capacity", wrapper.GetResourcePoolCapacity)
+	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/nodePools/{nodePoolName}/jobs", wrapper.GetNodePoolJobs)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools", wrapper.GetResourcePools)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}", wrapper.GetResourcePool)
 	m.HandleFunc("GET "+options.BaseURL+"/hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools/{resourcePoolId}/resources", wrapper.GetResourcePoolResources)
@@ -755,8 +1009,107 @@ func (response GetMinorVersions500ApplicationProblemPlusJSONResponse) VisitGetMi
 	return json.NewEncoder(w).Encode(response)
 }
 
+type GetResourcePoolCapacityRequestObject struct {
+	HwMgrId HwMgrId `json:"hwMgrId"`
+}
+
+type GetResourcePoolCapacityResponseObject interface {
+	VisitGetResourcePoolCapacityResponse(w http.ResponseWriter) error
+}
+
+type GetResourcePoolCapacity200JSONResponse []ResourcePoolCapacity
+
+func (response GetResourcePoolCapacity200JSONResponse) VisitGetResourcePoolCapacityResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolCapacity400ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolCapacity400ApplicationProblemPlusJSONResponse) VisitGetResourcePoolCapacityResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolCapacity404ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolCapacity404ApplicationProblemPlusJSONResponse) VisitGetResourcePoolCapacityResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolCapacity500ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolCapacity500ApplicationProblemPlusJSONResponse) VisitGetResourcePoolCapacityResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetResourcePoolCapacity503ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetResourcePoolCapacity503ApplicationProblemPlusJSONResponse) VisitGetResourcePoolCapacityResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetNodePoolJobsRequestObject struct {
+	HwMgrId      HwMgrId      `json:"hwMgrId"`
+	NodePoolName NodePoolName `json:"nodePoolName"`
+}
+
+type GetNodePoolJobsResponseObject interface {
+	VisitGetNodePoolJobsResponse(w http.ResponseWriter) error
+}
+
+type GetNodePoolJobs200JSONResponse []NodePoolJob
+
+func (response GetNodePoolJobs200JSONResponse) VisitGetNodePoolJobsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetNodePoolJobs400ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetNodePoolJobs400ApplicationProblemPlusJSONResponse) VisitGetNodePoolJobsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetNodePoolJobs404ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetNodePoolJobs404ApplicationProblemPlusJSONResponse) VisitGetNodePoolJobsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetNodePoolJobs500ApplicationProblemPlusJSONResponse ProblemDetails
+
+func (response GetNodePoolJobs500ApplicationProblemPlusJSONResponse) VisitGetNodePoolJobsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type GetResourcePoolsRequestObject struct {
 	HwMgrId HwMgrId `json:"hwMgrId"`
+	Params  GetResourcePoolsParams
 }
 
 type GetResourcePoolsResponseObject interface {
@@ -900,6 +1253,7 @@ func (response GetResourcePoolResources500ApplicationProblemPlusJSONResponse) Vi
 
 type GetResourcesRequestObject struct {
 	HwMgrId HwMgrId `json:"hwMgrId"`
+	Params  GetResourcesParams
 }
 
 type GetResourcesResponseObject interface {
@@ -915,6 +1269,25 @@ func (response GetResources200JSONResponse) VisitGetResourcesResponse(w http.Res
 	return json.NewEncoder(w).Encode(response)
 }
 
+type GetResources200ApplicationXNdjsonResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetResources200ApplicationXNdjsonResponse) VisitGetResourcesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
 type GetResources400ApplicationProblemPlusJSONResponse ProblemDetails
 
 func (response GetResources400ApplicationProblemPlusJSONResponse) VisitGetResourcesResponse(w http.ResponseWriter) error {
@@ -1227,6 +1600,12 @@ type StrictServerInterface interface {
 	// Get minor API versions
 	// (GET /hardware-manager/inventory/v1/api_versions)
 	GetMinorVersions(ctx context.Context, request GetMinorVersionsRequestObject) (GetMinorVersionsResponseObject, error)
+	// Retrieve the capacity of each resource pool
+	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/capacity)
+	GetResourcePoolCapacity(ctx context.Context, request GetResourcePoolCapacityRequestObject) (GetResourcePoolCapacityResponseObject, error)
+	// Retrieve the hardware manager jobs run for a NodePool
+	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/nodePools/{nodePoolName}/jobs)
+	GetNodePoolJobs(ctx context.Context, request GetNodePoolJobsRequestObject) (GetNodePoolJobsResponseObject, error)
 	// Retrieve the list of resource pools
 	// (GET /hardware-manager/inventory/v1/manager/{hwMgrId}/resourcePools)
 	GetResourcePools(ctx context.Context, request GetResourcePoolsRequestObject) (GetResourcePoolsResponseObject, error)
@@ -1333,11 +1712,65 @@ func (sh *strictHandler) GetMinorVersions(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// GetResourcePoolCapacity operation middleware
+func (sh *strictHandler) GetResourcePoolCapacity(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId) {
+	var request GetResourcePoolCapacityRequestObject
+
+	request.HwMgrId = hwMgrId
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetResourcePoolCapacity(ctx, request.(GetResourcePoolCapacityRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetResourcePoolCapacity")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetResourcePoolCapacityResponseObject); ok {
+		if err := validResponse.VisitGetResourcePoolCapacityResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetNodePoolJobs operation middleware
+func (sh *strictHandler) GetNodePoolJobs(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, nodePoolName NodePoolName) {
+	var request GetNodePoolJobsRequestObject
+
+	request.HwMgrId = hwMgrId
+	request.NodePoolName = nodePoolName
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetNodePoolJobs(ctx, request.(GetNodePoolJobsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetNodePoolJobs")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetNodePoolJobsResponseObject); ok {
+		if err := validResponse.VisitGetNodePoolJobsResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // GetResourcePools operation middleware
-func (sh *strictHandler) GetResourcePools(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId) {
+func (sh *strictHandler) GetResourcePools(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, params GetResourcePoolsParams) {
 	var request GetResourcePoolsRequestObject
 
 	request.HwMgrId = hwMgrId
+	request.Params = params
 
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
 		return sh.ssi.GetResourcePools(ctx, request.(GetResourcePoolsRequestObject))
@@ -1414,10 +1847,11 @@ func (sh *strictHandler) GetResourcePoolResources(w http.ResponseWriter, r *http
 }
 
 // GetResources operation middleware
-func (sh *strictHandler) GetResources(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId) {
+func (sh *strictHandler) GetResources(w http.ResponseWriter, r *http.Request, hwMgrId HwMgrId, params GetResourcesParams) {
 	var request GetResourcesRequestObject
 
 	request.HwMgrId = hwMgrId
+	request.Params = params
 
 	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
 		return sh.ssi.GetResources(ctx, request.(GetResourcesRequestObject))
@@ -1582,53 +2016,79 @@ func (sh *strictHandler) GetSubscription(w http.ResponseWriter, r *http.Request,
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xc63LbNhZ+FQx3Z7adpSQ7cj1e/3PsXDRNHI0vbXciTwciDkW0IMAAoGzVo3ffAUBS",
-	"4MWSnEujZP0rMgUC5/Z95+AAyn0QiTQTHLhWwfF9kGGJU9Ag7V/J7duZHBHzkYCKJM00FTw4Dq45/ZAD",
-	"ogS4pjEFiUSMMEqwJLdYAkoxxzOQ/QkPwgDucJoxCI4DJVLozYETIXtMRNjOFgbUTJlhnQRhwHFqRpYr",
-	"h4GEDzmVQIJjLXMIAxUlkGIjkl5kdlItKZ8Fy2UYqHxaSfkIsf3XmiJjfDQke1Pcwz8B9A7i/bg3haOD",
-	"XjwcHkyf7e8fHkZxtwoNYdZpEguZYh0cB3lOzcimZstysPXKyXj0C0hlVWpqOOJuLio4wlORa4TR3A02",
-	"uuoE0Ml45JTMpMhAagp21vlqypX2+/29/l6HQNUTMf0DIh0sQ08qtZ1YjCptZCoWVhvkwxn1569kfO+J",
-	"Xsi7vAkDqiG1A/8pIQ6Og38MVoE+KIw58Cy5UglLiRfm71zSsYSY3tVtMiijvFdE+YDyOXAt5GIw39/O",
-	"WGMppgzSM9CYMge8urKEUGMszE60lnSa6+bzcW18Y8mwYf4TvkA8T6dFwFeTIFzNHiKsEIGYciCIcoOK",
-	"DCIaU4dSJCSaLhDmiBozpMC1fd4POrQjVq12FJygJE8x70nABE8ZILjLGOZugXI5pAXSCVVIRFEuJfAI",
-	"ysjInNX6NYCeCs4hslNogQjWeIoVIE1TIEjkuu0Qg1alMY+gS8TrixGSEINbWSdYr/hCWTEqSR+WcMJH",
-	"GqV4gRYUGEFxLnUCElEPBjRGBKqFiAv5FRFI2iW40ljnHfi6SgC9vroaIzcARYIAioXcwpLVkpR7tqJc",
-	"wwykhQXVrNNSKhFSh02fqjxNsVw0VkJm3j4aafNWzgjiQqMowXwGKJYi9WXU4mGJwwmHuwgybbXLcpkJ",
-	"BZY6TD5h9C8XlWgU2xURVWhG58AR5gQJ6wSdYI4mgaWh4ynD/M9JEDpDVXBAKsGMIcyUQFO7+JyS0kkt",
-	"r7gHm0IJR5GQhPKZUXD04uolunh5iob/OTpE74c3nZHWMh5VCHgkcolnQNwrZpxZqJBRTXjDIUREeYXX",
-	"IihWU/8A/Vkf5Yry2eurt29+RLcJ8Hpkol/NI2ugFCyJUGX9l0lQwHU44VQrNMcstwbHSuUGfNrarmHp",
-	"Zn5NtM7U8WBQRqRnw34k0o2YWPp59X0JkIqDbrrJNwKlhDRZabtclZWvtNOSjBKqIdK5hG5cVu+i2ljf",
-	"CHdHh73Dg67QioSEB/CuhcbMo/UsWSgaYYbcO978w2dduE4xz2NshZHdK/gjPBxWllgpMOIaWJf8qSDA",
-	"Ns/+L+WZyb6DbBXVWuOHix/RbyC4+feVYAQdHgyH59sl3QtQIpcRbO92WbzRb7udpJRfaqwfcLr9niot",
-	"saZzsLRcUVk5q9GO56kJ2+vzN+9Of35xFoTB5evrq6vR+avfz979ahSrvrg+//ncPLoJN6T7pjyvDR+g",
-	"FR+svmxKVM+slyKtj3ZmsUTg6dASZsbEFLMTpUB3FeEjr/qWSIGktTD25QlNlsRzTJmRvC7dnTw63NN3",
-	"EY/J7NmzTjmkyLMO8PwMi1shiSl3uNCGkN1Iz+FoCkzwmUJamFWrYvIB6l/VjMntWIqYuoS5ElYmvcw9",
-	"72lQujfFikZdMjM8BfYppd67rCjt3EwIZxmjjoybjluJdz9xC/fwJDhGk8BSufkjnJhU6b6b+t9NJ8HS",
-	"T4YrlKWQCrlYR1kVUbmhptp8S5931h5r6MNtIz2y6IJXpeFY3IJ8QWaAfrswcdNle7dva651aaoct0CZ",
-	"O7vhsjkgjRuxc88a6vBGbeSNF+cnz99YdjgbXZYf1xFFhqU+t1hba1Uz7AFMdimWGeuuUcl+v1GZd4bu",
-	"3r182S14mR4sCLba3dXzfAdYSxk2sFTp9ouPdHu5zFgI5paqE4MQrLfmdceQWzhtLZV21q14tp4ezeOp",
-	"IUghUcSwUjRe2ALWmxhVm6nH8GSu8AyqiCkjYHT25kUQBienV6NfzIfn15f/3RDQTve2Fr84mxiG8KuY",
-	"VlVxBoyhEY/6G0tLL1paPvWJv87IBa1Ugpac1vBrDZkVidbCPvSLjg4yqRn1Zk39Y2V+dA2ETJy2C6HP",
-	"VHlUs396+dFN4w1RuhJGhwxbwLON7q2JBJl3TPozD5u90wpXj5ZIUb0tpZVN2G1MQfLh1hipYFEEvy9I",
-	"V2heer3SrcKSo6rp1tG+rYdohBmb4ujPbvKMc8YW6EOOmTENsXtpLRBGkeBmHyvdzoTkEtBtQqMERZiX",
-	"uxWE0Vi4NqYx34SXrj21rY1zoasO2gO9g3KVyw2t6w7nVQKKGIExhkJmO45IDq7cA+TPioyjQOla06e7",
-	"4RwGMWW6K92cSqoNb1khikWdVYiwPQEO1c5fQiakBmJI+JYyZp65eYGg6cL10nwBJ5x7BjP5bE4j6KOr",
-	"BCTEQhb7gWKSVRfCNWfMfBxhxkq5DJhKGR6wvnq81X2TGtGo8s8TqDISGFStdHxdIvttcSrS4QBDTO84",
-	"W5RnA+thVkV0G0tL29505B4JrnGkzcfiTOICCHqNTfWbS+Z1X25vb/sSSIK1bbq0G8jjkTWAdQmftVTy",
-	"0FhSgElYReswaA0fVcNPxiObHBsdfJvfOM5ocBwM+3v9oc2QOrGAXteBxxn9fe6dE8xAt916ATqXXBUo",
-	"MgSnoTqPMLpWZxJVt9sL2SIsbURVWdhET/AK9Alj1TGFTQ6Z4Mrx0LO9vdIrwLU708hYEe2DP5SjvtWp",
-	"0HYnF8r5vLFpySNDT47bxFRj29bvVLdU1eizDIODtUIWXbp/P07YxmlHh7zPMSnpyQjx01cRYsQ1SLvr",
-	"AjkHiUBKIfvFwaJtajsX1yIkKMvo90EKGhOscXBjXll/TPT4OC39lVIu5MNBWjX9U/yHkA+e/bXi9q2Z",
-	"dnci9ykYtw3Gdjx8bEiWD++Lw/flwC/n/ChtRc9FbWBYu0bwvtsUqyGD8rB/efOJcbdVW6C1DWptT9fx",
-	"KSoF3Jn4PNgbfgUhXgo5pYQA7zsZDr6CDFerw1gg7Q3ULXYFYixyTvq7B2Ujz3A3zZZzr+te55wL0JLC",
-	"HGpJqbZv9AmoIpjPwUCD+/r+crktJX08I4Xrm3Ud135aW+DtLzDdfMG022a9b43lvj7D1KJ85+mlG7Vw",
-	"hyNtNgW80e3520A7WO0Qt4Tvhbel/H/A8aPKmO+hhNkh4Dwm2ym728LFDaMvjaat4PKtFN/fR+H9VPQ+",
-	"FlzfYc37JcpdL2tuWeZ+ptTYOs1ekxl3sLp9qmy3FeK85IhvJP921a0e8PyDHPWR4KvPsQZzl7WBu51w",
-	"ayer33zC3f8KQlxznOtESPoXkB3ot32D9XL3Ub1aA98wyITSXcfPgDXUbmq2T//reHWv1GDwaYi14fhc",
-	"kMVny151jNYPe01WXbaIYv8Lrr3mJDGytiStk/tdOjt8IondI4lmPe0wWQuhL5nLB/f1ex5LRywMuu6r",
-	"ntnnqvNnoXVmcSM/D7OEG4c2rqo8VD2sQa/TeA16n4DDd2VfD1xTvfi2eswOD9uiOtx85cH9hks99CPt",
-	"tXX5DkDx78/PtZs+nvWe8vUT7Xy3tPMK9NaVhPs5xbykhMZPp3qnTOSkfbnxZDxCl/a12sXJ48HA/ug4",
-	"EUofH+0duf94oFj7vuMGZXkbx/8d+KqtVt3VMQzUtEO5gfL7/MV7q57j8mb5vwAAAP//Tte2o9BDAAA=",
+	"H4sIAAAAAAAC/+xce3PbOJL/KijeVd1uHSVLtuN49Z9jJxPtJI7Lj5m9ilNbINEUkVAABwBta1P+7lcA",
+	"+ABJUJLzmHFm/FdiEkQ3Gt2/fqChz0HMlzlnwJQMZp+DHAu8BAXC/JXevl2IOdH/JSBjQXNFOQtmwRWj",
+	"vxWAKAGmaEJBIJ4gjFIsyC0WgJaY4QWI8TULwgDu8DLPIJgFki9hdAOMcDHKeIzNbGFA9ZQ5VmkQBgwv",
+	"9ciKchgI+K2gAkgwU6KAMJBxCkusWVKr3EyqBGWL4P4+DBgncMZ5dmom6TKtn1o+T8txXf5YPvWz05r4",
+	"YTzJIqqZeIAo3c+6bGJ8uEcmER7hZwCj/WSajCI43B8le3v70e50enAQJ/51dJhZt5KEiyVWwSwoCqpH",
+	"dld2Xw02mnJ0Nv8FhDRL6q5wzuxclDOEI14ohNGNHazXqlJAR2dzu8hc8ByEomBmvWmmbFY/HU/GEw9D",
+	"9RMefYRYBfehw5Xcjq2MSqV5KgnLDfzhnLrz1zy+d1gv+b3/EAZUwdIM/G8BSTAL/munMb6dUpg7jiSb",
+	"JWEh8Er/XQh6JiChd22Z7FSWNyotb4eyG2CKi9XOzXQ7YZ2CuuXi05wpEAmOQctnO6kx+yWi1ac9QWU4",
+	"gsyzB2/M80r5V5QtjLgFz6ASfW/yEMkiThGW5jXBCiMuUMS5ajFQC7uz9L5Mlzg+IkSA9DB4mQJ6e3SM",
+	"sB0wyFTLPuODWXQwm+7N8GQ22Z1NPBsQBktOIPMTNK8GSaEYC9KiN7k7nBweoMnd9NnzXR8t5sVDTYqV",
+	"mLh5UcC4dx0yByD+yTPKPiHzfngxlKG3US5dUrvPJpNJTUkPXYAwOtug1Xt31z74tLlE7H/yqM/cUc9T",
+	"oY88QqJgKOHCdRB9k88qx3UOMRdk2M3o9TajkTDDzVNNi8ryCRBEe15odJjsTsl+/NwncQ0aGSggR8ov",
+	"d0WXUBO6xRJJAIYURym+AZRQRmUKxNhRDFImRZattA0xroKwAX6CFYz0XD4mEkyzY048anWcYSlpQst1",
+	"l5IQgKX9C6NXmGZANHeNLc+ZLJKExhSYOgfJCxGDDDVXV+wT47cM0QRRhZZYxSlIxPg1i9uURJFBx1N6",
+	"Zx1azrlh0S/Skv0FvQGGopVZUk+HrO40q/MR+sgjXxQwb7n/au848xJqLfEQ78EzOITRNNmLRvvJwfPR",
+	"P5JJPNol02QS70fPYddrtxmW6lJgJqlm4ZIOIUStTHmKJbjc6RlQnGK2ALK13jBO4HQQjrTd1fPjPM8o",
+	"SKR4aESbgxjpz/XLFmAEBLJsNDUvR3s+qjySIG4eYDEJFVIZu9l6ZTwHYSNaL4m8EDlvCVAvgRVLDWZH",
+	"mRMOnwme0Ayuck0uCINzyABLCD54iJpN8ROUCiuXXKjNzOyZgJwLBWRIkcfoyAgiL8RieNQ1iyDhAhBV",
+	"EvFCxXwJDdpQid5SKSlblNFrudI5OxN8YWA7DI4rJAvCwJpNEAblZ57ldjxAD4or63L3orX3PkdxJniU",
+	"wfIEFKY2ROkAPiHGQnB2pJSgUaG6z89a43tb1HE9bIVYsYzKOL+eBOF6drNRBBLKjG/QyUAOsYOoQm8J",
+	"Zohq6S2BKfN8HHhWR8yyvB6wWGI2EoAJjjJAcJdnmFkCFTntMlRKJeJxXAgBLK71KbdSG7fM8JgzBrGZ",
+	"QnETmUUaMrTBEK0hPquhTCrMYvCxeHU+RwISsJRVilWTJtnor+Z0mMNrNtdeY4VWFDKCkkKoFASiThxL",
+	"E0SgJkSswjb5j6A+xrV5FQMR4+vLyzNkB6BYI5aGr82SrElSpoJ+ABQGiqrMKymZcqHC7p7KYrnEYtWh",
+	"hPS8YzRX+qsiI9rtlzCOEsGXLo+KD3McXjO4iyFXFpwtvJkIWZtlRv9jtRLNE0NRI4J1n5gRxM0mqBQz",
+	"dB2YPGIWZZh9ug5CK6jaHJBMcZYhnEmOIkP8hpJqkwZi+02qhGONFybb4Gj+8vIVOn91jPb+cXiA3u99",
+	"8GpaT3hUImAxLwTWGGk+0eM0oZJHec06G0J4XNT2WipFM/XfYLwYo0KD3+vLt2/+jm5TYG3NRL/qR0ZA",
+	"SzAgQqXZv1yABKbCa6bR+AZnhRE4lrLQxqeM7DqS7pYVUqVyOdvZqTTSkeE45suNNtGB59JAagwaAN8Y",
+	"pORi+2Qzrz7ph+YiTqmCWBViwCXW36LWWFcId4cHo4N9f9gtYMDeFVc4c2A9T1eSxjhD9htn/r1dn10v",
+	"MSsSbJgRAymhM8Kxw1oSbsirIHtowunM/j/SEZP5xiSJfRp/O/87+hdwpv/9iWcEHezv7Z1uV2uoYvHt",
+	"t12UX3gyMrKk7EJhNbDp5j2VSgcEN52wqJrVCVCuTt+8O/755UkQBhevry4v56c//fvk3a96YfWLq9Of",
+	"T/UjX0RWRiW+nPjXFErYaygbK60+0XbaJJ+NKCPOM8CmIBRnvCDzgYTbvETzk2p51UxrKbZLF3qGkTdh",
+	"aJHrUn+tgQ41QNe87Iq6HTJc8GV7tN1vg3DO5vSYWWQ8wtmRlKA2pVMCSRC0ZZ8uP6F2//gG00xz3ubu",
+	"ThweTNRdzBKy2PVWVhaCF7kHFX6G1S0XRMdxjCvtaexIR5NRBBlnC4kUHz+oXpXelhlCuwYo0lFun48U",
+	"SDWKsKSxP+Qqiy8DaNYr0kiPuWxVy/SWFD0rasqDXxhVv8vtR8jOVKaOpA5gXTuvBfb52hIe4etghq4D",
+	"4zX1H+E1Q9W7yH0XXQf3btzRANoSllys1nmH2ifYoabwRV94w7w1SG0PTxxc9iFZvcIzfgviJVkA+te5",
+	"1uTta4MXOqC0BKowxW/Am01kfcbvruGUkz65EHETstWw2qp8RvAsmsTT0fNkf2+0H+/tjqL9ZH90GJEJ",
+	"3scHyTM8yNPwQZGPr4fBqD1IGi4R4GyNw3JGbfRWL0+PXrwxPulkflH9d517yrFQpwYI1yqYHjYAmL6F",
+	"5VrR1izJvN+4mHfayb579crPeBWUGDzYCn7a0aUHdyoeNriQSiXPv9ACKjJahyypNmpzno3WfG7d1xab",
+	"ttbPeWdWXKcvQ/Uj8xIRuKFf4QMu7DRDW6DwYr371I8j7UC5QGXBtzknqm2xqiI8xI8WEi+gVtq6QHXy",
+	"5mUQBkfHl/Nf9H9eXF383wabsuLvr+IXuy1ctALsfjh9AlmG5iweb8ypHIXtqZUbGLT9YwnyNaOVh+mo",
+	"VgscapfWsrzQjbY9eNYS6oc1gb/m+RjnOKbK4zWrN/a4oN7lfN2JzNAhVGMN1TxS+96W+uQW3LFCWAy4",
+	"mkOfo04EwLeiyrjyU973Ue5DytbohfQ3FTO9M4zKktoYtw1KUbUtjtZnYjzpS6RDmBTegr6Jqb5O9C6d",
+	"6e7G88aetVkW3ISvVIhNev/wpHdA879RRuYR+5emZSmX6pgXTG0TzDvqvG4X9ZwDxhOtbExhz4e6uiyt",
+	"cdnTDkRVp971OaizPmNkpmonKWd6vw99VQt/qNwRtC8o9yn2Zov6a9v4JvMrXZrLiM/w3PBjK5trhz09",
+	"m4sHnZYpwDiOS/ViKL0h0Uq1K4LTg8lkb3e6N90/nB60zwDcOuRXpoe9VXWij5dModNf3gI6e3YwmaC3",
+	"V+hqvIum44PLF1/eULKG6AXPKEEmTkAnVH5Ck9l0Ngn8cb/iMc8GK7rmrZ9k015wcXR5FKKLowvTUqBX",
+	"2mKnfPCNQ+/e+rc8rtDzLoFQbA9ONizt9cmJXtPFxUlbwubvbxGrbrGdA0VnH4heOC2IW1kkQ3Uvm6cr",
+	"smudWRbh+JNfprbR5bcCZxqriDmrMRXXmDN9TiJs5ZsUAtBtSuMUxZihMvxFGJ1xqSphXLMKa4/N0dkp",
+	"V/UJ7cDZVEXlYkNHqAdNawZ5gkALQyIJTCFSgK1xAXJnRRo5QarWoaK/jzMMEpopn3YfC6q0VhsmSqJW",
+	"KoSbaJVBfbJUdxRwgW5plulndt6mf8DdO3TNmCMwbT5ap8boMgVhugpsWbacpDnlsod/ej6mo+WKLywa",
+	"HgakLx8udVekmjUq3TbdVv2nXOPrytW+LTspPBugI4V3LFtVLbfr/V6t0X3ndm9qudavxZwpHJvuFovN",
+	"wTkQ9BornZKJzDndu729HQsgKVbmUK/foHA2NwIwW8IWvSU51ijcjip7NB30hs/r4Udnc5ODdhpjTRrJ",
+	"cE6DWbA3noz3TCKqUmPQ6xpbcU7/feO03y7A091zDqoQTJZWZNtN6jZfvdZqhqabwlHZUi2NRtXJrtae",
+	"4CdQR1lWd/+aaC3nTFoc2p1Mql0BZrgyFWmr7Tsfyzazptl6u4Zgafe8U6ltNfFFCpu2Ee9yq6Xq9dyH",
+	"wf5aJstT4P99GLOdbhoPvy8wqeBJM/HsD2HCnEiY+iqIGxAIhOBiXPbrm6YJu8UtDQmqatX7YAkK6/6W",
+	"4IP+ZH339cP1tNqvJWVcDCtp3VSyxB+5GGyp7+ntWz3t49HcJ2XcVhn7+vClKlk9/Fzes7nfcfOajQo6",
+	"UGIBHKedDL1Uxm4KGpomoJTf6gdVtrTUbvya1U41RLdUpToG1AbRrfkaTpYSshuQA4ruLTaGrTtO7/2b",
+	"1wzZKSVkLnB8laVsVS33styrXa/zAqhi8tFY1f5k/w9g4rJpDwTSr4HcYhtSJrxgZPzIjN+ys/c4pVYw",
+	"p1uiDVLnoASFG9tK7dZC+sjgIFeNTF8IXdVhrtz57J7r3u+YPvFt8CylUk8/hFam4VznL0uqGsfbnAtb",
+	"oNKPqCjb5DW86SH6XkGhUa28QLCp9TqsinZcENsptEK3IMBpSDdJUs0ylUjhT8Bs66ZKGwBtLp3I7kF2",
+	"iCRHVCHK4qwgZYejWaTZK5FREPXg+mupawGmIGDqqvoyiUnj0AqU7h/WKcsAEju3ceSXI3C4cai7/78T",
+	"YjtLewLq7wA5vNHExw7aw1joR5T+fbNviIpuwdxFwbVR0tcZZ6cni2W6RKBRtn8CUIOKbQmXVJVZtrm0",
+	"/FsB5uS5urVsjx3W3bP+3YMzfz/Dj2bvf0SI8YqLiBICbPwUHH5hZvgniA6rGkcbFb4X/u18bp8f3m8L",
+	"iF+Fh+vOVj0/ztA74tz+ZyY+fMcqTh/1nqKah5pKuyjy2OHFb7Vwh2Ola8wMvlsqt95o69dbxzPune+/",
+	"gh0/KIz5M4QwP0j83/V2soz7bfT7va1pK3P5lqH/K3Pk6bT+GKUZo191mYQRvR1hp5iLBSCpBGB9XdH+",
+	"tsvKPBSASV3c6NdLrhlngP558e4U2XNRlINAGWUQIoGbW6Y2EbFzY6TvWGaAjMoP5x3lya1rsAQSXGQq",
+	"mAVGp5re9fJPuzjf3fXHYNphi8bdiJE+nfq4OqIMG2l4fvnpyfs/FZ9/qPTie2QWToCyZUbxjaKQ3o2T",
+	"NUHII0wknpKIbZk4rTDiBwl1fCmCY3huC5b8QuNrz7HG5i5aAx/3qa/L649fVJz+AUxcMVyolAv6HyCP",
+	"oLT5A6Ym/iZbucZ8wyDnUvkaRwEraF117/fttu3VftIyg6+zWKOOLzhZfTPv1bbR+/uuV73vAcX0O9Je",
+	"0wMYG1mSXs/tY+r6ewKJxwcS3Xja2mRLhb6nL9/53O7QvrfAkoHvTvmJeS4R3ogsduS3QZbN/QftJQxG",
+	"D2us1654jfU+GQ57LHk9MKX7m36ocr61h22tOtzcO2V/3UsO/Wr52rj8EZji7++fWz36jvSe/PUT7Pxp",
+	"YUe3r28bSdybe5c3FSR0uopGx+ZHznr3jHRb/IX5rHXlabazY36OMuVSzQ4nh/aX+Evanz13n6o+evcX",
+	"QpuyWvXWc+jRXHRyj1TK75qa4/2H+/8fADoK0Ex1YwAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -14,15 +14,22 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3filter"
 	oapimiddleware "github.com/oapi-codegen/nethttp-middleware"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type Middleware = func(http.Handler) http.Handler
 
+// serverLog writes the logs of the API server, whose level can be overridden by the logging configuration of the
+// HardwareManager a request concerns
+var serverLog = slog.New(logging.NewComponentLoggingContextHandler(logging.Components.Server, slog.LevelInfo))
+
 type durationLogger struct {
 	http.ResponseWriter
 	statusCode int
@@ -42,11 +49,57 @@ func GetLogDurationFunc() Middleware {
 				ResponseWriter: w,
 			}
 			next.ServeHTTP(&d, r)
-			slog.Debug("Request completed", "method", r.Method, "url", r.RequestURI, "status", d.statusCode, "duration", time.Since(startTime).String())
+			serverLog.DebugContext(r.Context(), "Request completed", "method", r.Method, "url", r.RequestURI, "status", d.statusCode, "duration", time.Since(startTime).String())
 		})
 	}
 }
 
+// GetCorrelationIDFunc tags each request with a correlation ID, taken from the request header if provided by the
+// client, and returns it in the response header
+func GetCorrelationIDFunc() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(logging.CorrelationIDHeader)
+			if !logging.IsValidCorrelationID(id) {
+				id = logging.NewCorrelationID()
+			}
+			w.Header().Set(logging.CorrelationIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(logging.WithCorrelationID(r.Context(), id)))
+		})
+	}
+}
+
+// GetHwMgrFunc tags each request for a hardware manager with its name, so that the log levels configured for the
+// HardwareManager apply to the request
+func GetHwMgrFunc() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hwMgrId := r.PathValue("hwMgrId"); hwMgrId != "" {
+				r = r.WithContext(logging.WithHwMgr(r.Context(), hwMgrId))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetTracingFunc records a span for each request, continuing the trace of the client if it sent a traceparent header,
+// tagged with the correlation ID and hardware manager of the request
+func GetTracingFunc() Middleware {
+	return func(next http.Handler) http.Handler {
+		return tracing.WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var attrs []attribute.KeyValue
+			if id := logging.CorrelationIDFromContext(r.Context()); id != "" {
+				attrs = append(attrs, tracing.AttrCorrelationID.String(id))
+			}
+			if hwMgrId := logging.HwMgrFromContext(r.Context()); hwMgrId != "" {
+				attrs = append(attrs, tracing.AttrHwMgr.String(hwMgrId))
+			}
+			tracing.SetAttributes(r.Context(), attrs...)
+			next.ServeHTTP(w, r)
+		}), "api")
+	}
+}
+
 // GetOpenAPIValidationFunc to validate all incoming requests as specified in the spec
 func GetOpenAPIValidationFunc(swagger *openapi3.T) Middleware {
 	// Clear out the servers array in the swagger spec, that skips validating
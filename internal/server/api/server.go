@@ -8,6 +8,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
@@ -22,15 +23,13 @@ var _ generated.StrictServerInterface = (*InventoryServer)(nil)
 
 // baseURL is the prefix for all of our supported API endpoints
 var baseURL = "/hardware-manager/inventory/v1"
-var currentVersion = "1.0.0"
 
 // GetAllVersions handles an API request to fetch all versions
 func (i *InventoryServer) GetAllVersions(_ context.Context, _ generated.GetAllVersionsRequestObject) (generated.GetAllVersionsResponseObject, error) {
-	// We currently only support a single version
-	versions := []generated.APIVersion{
-		{
-			Version: &currentVersion,
-		},
+	// Versions served under other prefixes are listed by the api-versions route
+	versions, err := minorVersions()
+	if err != nil {
+		return nil, err
 	}
 	return generated.GetAllVersions200JSONResponse(generated.APIVersions{
 		ApiVersions: &versions,
@@ -40,11 +39,9 @@ func (i *InventoryServer) GetAllVersions(_ context.Context, _ generated.GetAllVe
 
 // GetMinorVersions handles an API request to fetch minor versions
 func (i *InventoryServer) GetMinorVersions(_ context.Context, _ generated.GetMinorVersionsRequestObject) (generated.GetMinorVersionsResponseObject, error) {
-	// We currently only support a single version
-	versions := []generated.APIVersion{
-		{
-			Version: &currentVersion,
-		},
+	versions, err := minorVersions()
+	if err != nil {
+		return nil, err
 	}
 	return generated.GetMinorVersions200JSONResponse(generated.APIVersions{
 		ApiVersions: &versions,
@@ -52,6 +49,20 @@ func (i *InventoryServer) GetMinorVersions(_ context.Context, _ generated.GetMin
 	}), nil
 }
 
+// minorVersions returns the version of the v1 API, as given in its OpenAPI spec
+func minorVersions() ([]generated.APIVersion, error) {
+	swagger, err := generated.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swagger: %w", err)
+	}
+	// We currently only support a single minor version
+	return []generated.APIVersion{
+		{
+			Version: &swagger.Info.Version,
+		},
+	}, nil
+}
+
 func (i *InventoryServer) GetResourcePools(ctx context.Context, request generated.GetResourcePoolsRequestObject) (generated.GetResourcePoolsResponseObject, error) {
 	return i.HwMgrAdaptor.GetResourcePools(ctx, request) // nolint: wrapcheck
 }
@@ -66,6 +77,14 @@ func (i *InventoryServer) GetResourcePoolResources(ctx context.Context, request
 	return generated.GetResourcePoolResources200JSONResponse([]generated.ResourceInfo{}), nil
 }
 
+func (i *InventoryServer) GetResourcePoolCapacity(ctx context.Context, request generated.GetResourcePoolCapacityRequestObject) (generated.GetResourcePoolCapacityResponseObject, error) {
+	return i.HwMgrAdaptor.GetResourcePoolCapacity(ctx, request) // nolint: wrapcheck
+}
+
+func (i *InventoryServer) GetNodePoolJobs(ctx context.Context, request generated.GetNodePoolJobsRequestObject) (generated.GetNodePoolJobsResponseObject, error) {
+	return i.HwMgrAdaptor.GetNodePoolJobs(ctx, request) // nolint: wrapcheck
+}
+
 func (i *InventoryServer) GetResources(ctx context.Context, request generated.GetResourcesRequestObject) (generated.GetResourcesResponseObject, error) {
 	return i.HwMgrAdaptor.GetResources(ctx, request) // nolint: wrapcheck
 }
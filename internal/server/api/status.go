@@ -0,0 +1,47 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+)
+
+// HealthStatusPattern is the route serving the detailed health of the plugin and its hardware manager backends
+const HealthStatusPattern = "GET /hardware-manager/status/v1"
+
+// HealthStatusServer serves the aggregated health status behind the readiness checks, for orchestration layers that
+// need to know which backend is down rather than only that the plugin is not ready
+type HealthStatusServer struct {
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+// HandleHealthStatus returns the health status as JSON, with a 503 status code if the plugin is not ready
+func (s *HealthStatusServer) HandleHealthStatus(w http.ResponseWriter, r *http.Request) {
+	status := s.HwMgrAdaptor.GetHealthStatus(r.Context())
+
+	body, err := json.Marshal(status)
+	if err != nil {
+		serverLog.ErrorContext(r.Context(), "Failed to encode health status", slog.String("error", err.Error()))
+		ProblemDetails(w, "unable to encode health status", http.StatusInternalServerError)
+		return
+	}
+
+	code := http.StatusOK
+	if !status.Ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(body); err != nil {
+		serverLog.ErrorContext(r.Context(), "Failed to write health status", slog.String("error", err.Error()))
+	}
+}
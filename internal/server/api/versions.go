@@ -0,0 +1,201 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+)
+
+// APIVersionsPattern is the route listing every version of the inventory API served by the plugin
+const APIVersionsPattern = "GET /hardware-manager/inventory/api-versions"
+
+// apiSpecPath is the path, relative to the URI prefix of a version, serving the OpenAPI spec of the version
+const apiSpecPath = "/openapi.json"
+
+// APIVersionStatus tells consumers whether a version of the inventory API is the one to use or a preview of the next
+type APIVersionStatus string
+
+const (
+	APIVersionCurrent APIVersionStatus = "current"
+	APIVersionNext    APIVersionStatus = "next"
+)
+
+// InventoryAPIVersion is a major version of the inventory API, served under its own URI prefix from its own OpenAPI
+// spec and generated code. Serving the next version alongside the current one lets consumers move to it one endpoint
+// at a time when the schema changes.
+type InventoryAPIVersion struct {
+	// URIPrefix is the prefix of all the paths of the version, ending with its major version, e.g. "v1"
+	URIPrefix string
+	Status    APIVersionStatus
+	// GetSwagger returns the OpenAPI spec of the version, whose info.version is the version reported to consumers
+	GetSwagger func() (*openapi3.T, error)
+	// Register adds the routes of the version to the router, behind the authentication and authorization middlewares
+	Register func(router *http.ServeMux, server *InventoryServer, authn, authz Middleware) error
+}
+
+// InventoryAPIVersions lists the versions of the inventory API served by the plugin. A next version is added here,
+// with the code generated from its spec in its own package, until it replaces the current version.
+var InventoryAPIVersions = []InventoryAPIVersion{
+	{
+		URIPrefix:  baseURL,
+		Status:     APIVersionCurrent,
+		GetSwagger: generated.GetSwagger,
+		Register:   registerInventoryV1,
+	},
+}
+
+// APIVersionInfo describes a served version of the inventory API in the api-versions response
+type APIVersionInfo struct {
+	Version   string           `json:"version"`
+	URIPrefix string           `json:"uriPrefix"`
+	Status    APIVersionStatus `json:"status"`
+	SpecPath  string           `json:"specPath"`
+}
+
+// APIVersionsResponse is the body of the api-versions response
+type APIVersionsResponse struct {
+	APIVersions []APIVersionInfo `json:"apiVersions"`
+}
+
+// specVersion returns the version given in the OpenAPI spec of an API version, after checking that it matches the
+// major version of its URI prefix
+func (v *InventoryAPIVersion) specVersion() (string, error) {
+	swagger, err := v.GetSwagger()
+	if err != nil {
+		return "", fmt.Errorf("failed to get OpenAPI spec for %s: %w", v.URIPrefix, err)
+	}
+
+	if swagger.Info == nil || swagger.Info.Version == "" {
+		return "", fmt.Errorf("OpenAPI spec for %s has no version", v.URIPrefix)
+	}
+
+	major, _, _ := strings.Cut(swagger.Info.Version, ".")
+	if !strings.HasPrefix(path.Base(v.URIPrefix), "v"+major) {
+		return "", fmt.Errorf("OpenAPI spec version %s does not match the URI prefix %s", swagger.Info.Version, v.URIPrefix)
+	}
+	return swagger.Info.Version, nil
+}
+
+// GetAPIVersionInfo returns the description of each served version of the inventory API
+func GetAPIVersionInfo() ([]APIVersionInfo, error) {
+	var infos []APIVersionInfo
+	prefixes := make(map[string]bool)
+	statuses := make(map[APIVersionStatus]bool)
+	for i := range InventoryAPIVersions {
+		v := &InventoryAPIVersions[i]
+		if prefixes[v.URIPrefix] {
+			return nil, fmt.Errorf("inventory API URI prefix %s is registered more than once", v.URIPrefix)
+		}
+		if statuses[v.Status] {
+			return nil, fmt.Errorf("more than one %s inventory API version is registered", v.Status)
+		}
+		prefixes[v.URIPrefix] = true
+		statuses[v.Status] = true
+
+		version, err := v.specVersion()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, APIVersionInfo{
+			Version:   version,
+			URIPrefix: v.URIPrefix,
+			Status:    v.Status,
+			SpecPath:  v.URIPrefix + apiSpecPath,
+		})
+	}
+
+	if !statuses[APIVersionCurrent] {
+		return nil, fmt.Errorf("no %s inventory API version is registered", APIVersionCurrent)
+	}
+	return infos, nil
+}
+
+// RegisterInventoryAPIVersions adds the routes of every served version of the inventory API to the router, along with
+// the OpenAPI spec of each version and the api-versions discovery route
+func RegisterInventoryAPIVersions(router *http.ServeMux, server *InventoryServer, authn, authz Middleware) error {
+	infos, err := GetAPIVersionInfo()
+	if err != nil {
+		return err
+	}
+
+	for i := range InventoryAPIVersions {
+		v := &InventoryAPIVersions[i]
+		if err := v.Register(router, server, authn, authz); err != nil {
+			return fmt.Errorf("failed to register inventory API %s: %w", v.URIPrefix, err)
+		}
+
+		spec, err := v.GetSwagger()
+		if err != nil {
+			return fmt.Errorf("failed to get OpenAPI spec for %s: %w", v.URIPrefix, err)
+		}
+		router.Handle("GET "+v.URIPrefix+apiSpecPath,
+			GetCorrelationIDFunc()(GetLogDurationFunc()(authn(authz(jsonHandler("OpenAPI spec", spec))))))
+	}
+
+	router.Handle(APIVersionsPattern,
+		GetCorrelationIDFunc()(GetLogDurationFunc()(authn(authz(jsonHandler("API versions", APIVersionsResponse{APIVersions: infos}))))))
+	return nil
+}
+
+// registerInventoryV1 adds the routes generated from the v1 spec of the inventory API
+func registerInventoryV1(router *http.ServeMux, server *InventoryServer, authn, authz Middleware) error {
+	// This also validates the spec file
+	swagger, err := generated.GetSwagger()
+	if err != nil {
+		return fmt.Errorf("failed to get swagger: %w", err)
+	}
+
+	serverStrictHandler := generated.NewStrictHandlerWithOptions(server, nil,
+		generated.StrictHTTPServerOptions{
+			RequestErrorHandlerFunc:  GetRequestErrorFunc(),
+			ResponseErrorHandlerFunc: GetResponseErrorFunc(),
+		},
+	)
+
+	opt := generated.StdHTTPServerOptions{
+		BaseRouter: router,
+		Middlewares: []generated.MiddlewareFunc{ // Add middlewares here
+			GetOpenAPIValidationFunc(swagger),
+			authz,
+			authn,
+			GetLogDurationFunc(),
+			GetTracingFunc(),
+			GetHwMgrFunc(),
+			GetCorrelationIDFunc(),
+		},
+		ErrorHandlerFunc: GetRequestErrorFunc(),
+	}
+
+	generated.HandlerWithOptions(serverStrictHandler, opt)
+	return nil
+}
+
+// jsonHandler serves a fixed object as JSON, encoded once when the handler is created
+func jsonHandler(what string, obj any) http.Handler {
+	body, err := json.Marshal(obj)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			serverLog.ErrorContext(r.Context(), "Failed to encode "+what, slog.String("error", err.Error()))
+			ProblemDetails(w, "unable to encode "+what, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(body); err != nil {
+			serverLog.ErrorContext(r.Context(), "Failed to write "+what, slog.String("error", err.Error()))
+		}
+	})
+}
@@ -0,0 +1,78 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api"
+)
+
+// cachedFilesOnly rejects requests for directories and for the temporary files of downloads in progress, so that only
+// complete cached files can be fetched, by their path
+func cachedFilesOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") || strings.HasPrefix(path.Base(r.URL.Path), ".") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RunArtifactServer serves the files of the artifact cache directory over plain HTTP, which BMCs download firmware
+// images from, and blocks until it terminates or context is canceled. The files are only readable, and are served
+// without authentication, as BMCs do not support it.
+func RunArtifactServer(ctx context.Context, address, dir string) error {
+	slog.InfoContext(ctx, "Starting artifact cache server", slog.String("dir", dir))
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create artifact cache directory: %w", err)
+	}
+
+	// There is no write timeout, as firmware images can take a long time to download
+	srv := &http.Server{
+		Handler:     cachedFilesOnly(http.FileServer(http.Dir(dir))),
+		Addr:        address,
+		ReadTimeout: readTimeout,
+		IdleTimeout: idleTimeout,
+		ErrorLog: slog.NewLogLogger(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			AddSource: true,
+		}), slog.LevelError),
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		slog.Info(fmt.Sprintf("Artifact cache server Listening on %s", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+
+	defer func() {
+		slog.InfoContext(ctx, "Shutting down artifact cache server")
+		if err := api.GracefulShutdown(srv); err != nil {
+			slog.Error("error shutting down artifact cache server", "error", err)
+		}
+	}()
+
+	select {
+	case err := <-serverErrors:
+		return fmt.Errorf("error starting artifact cache server: %w", err)
+	case <-ctx.Done():
+		slog.InfoContext(ctx, "Artifact cache server shutting down")
+	}
+
+	return nil
+}
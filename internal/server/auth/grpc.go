@@ -0,0 +1,102 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/rest"
+)
+
+// GRPCPathFunc returns the inventory API path equivalent to a gRPC call, which is used to authorize the call
+type GRPCPathFunc func(fullMethod string, req any) string
+
+// GRPCAuthInterceptor defines a gRPC interceptor that authenticates the bearer token in the authorization metadata of
+// an incoming call, and authorizes the call as a get on the equivalent inventory API path. This applies the same
+// checks as the Authenticator and Authorizer middleware, so access to both APIs is granted by the same RBAC rules.
+func GRPCAuthInterceptor(k8sAuthenticator authenticator.Request, k8sAuthorizer authorizer.Authorizer,
+	pathFor GRPCPathFunc) grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		path := pathFor(info.FullMethod, req)
+
+		// The Kubernetes authenticator works on HTTP requests, so carry the token over in one
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to build authentication request: %v", err)
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				httpReq.Header.Set("Authorization", values[0])
+			}
+		}
+
+		response, ok, err := k8sAuthenticator.AuthenticateRequest(httpReq)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "failed to authenticate request: %v", err)
+		}
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "unable to authenticate request")
+		}
+
+		attributes := authorizer.AttributesRecord{
+			User: response.User,
+			Verb: "get",
+			Path: path,
+		}
+
+		decision, reason, err := k8sAuthorizer.Authorize(ctx, attributes)
+		if err != nil {
+			msg := fmt.Sprintf("Authorization for user '%s' failed", attributes.User.GetName())
+			slog.Error(msg, "user", response.User, "verb", attributes.Verb, "path", attributes.Path, "error", err)
+			return nil, status.Error(codes.Internal, msg)
+		}
+
+		if decision != authorizer.DecisionAllow {
+			msg := fmt.Sprintf("Authorization not allowed for user '%s'", attributes.User.GetName())
+			slog.Debug(msg, "user", response.User, "verb", attributes.Verb, "path", attributes.Path, "decision", decision, "reason", reason)
+			return nil, status.Error(codes.PermissionDenied, msg)
+		}
+
+		return handler(request.WithUser(ctx, response.User), req)
+	}
+}
+
+// GetGRPCAuthInterceptor builds a gRPC interceptor to authenticate and authorize incoming calls
+func GetGRPCAuthInterceptor(pathFor GRPCPathFunc) (grpc.UnaryServerInterceptor, error) {
+	// Setup kubernetes config
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rest config: %w", err)
+	}
+
+	authenticatorConfig := KubernetesAuthenticatorConfig{
+		RESTConfig: restConfig,
+	}
+	k8sAuthenticator, err := authenticatorConfig.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s authenticator: %w", err)
+	}
+
+	authorizerConfig := KubernetesAuthorizerConfig{RESTConfig: restConfig}
+	k8sAuthorizer, err := authorizerConfig.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create k8s authorizer: %w", err)
+	}
+
+	return GRPCAuthInterceptor(k8sAuthenticator, k8sAuthorizer, pathFor), nil
+}
@@ -0,0 +1,154 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/auth"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/grpcapi"
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/tracing"
+)
+
+// gracefulStopTimeout is the time allowed for in-flight calls to complete when the gRPC server is stopped
+const gracefulStopTimeout = 10 * time.Second
+
+// RunGRPCServer starts the inventory gRPC server and blocks until it terminates or context is canceled.
+func RunGRPCServer(ctx context.Context, address, tlsCertDir string, tlsOptions utils.ServerTLSOptions,
+	hwMgrAdaptor *adaptors.HwMgrAdaptorController) error {
+	slog.InfoContext(ctx, "Starting inventory gRPC server")
+
+	// Calls are authenticated and authorized against the same RBAC rules as the REST API
+	authInterceptor, err := auth.GetGRPCAuthInterceptor(grpcapi.InventoryPath)
+	if err != nil {
+		return fmt.Errorf("error setting up gRPC auth interceptor: %w", err)
+	}
+
+	certFile := filepath.Join(tlsCertDir, "tls.crt")
+	keyFile := filepath.Join(tlsCertDir, "tls.key")
+	tlsOptions.Name = "grpc"
+	serverTLSConfig, err := utils.GetServerTLSConfig(ctx, certFile, keyFile, tlsOptions)
+	if err != nil {
+		return fmt.Errorf("failed to get server TLS config: %w", err)
+	}
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(serverTLSConfig)),
+		grpc.ChainUnaryInterceptor(correlationIDInterceptor, tracingInterceptor, authInterceptor),
+	)
+	srv.RegisterService(&grpcapi.ServiceDesc, &grpcapi.InventoryServer{HwMgrAdaptor: hwMgrAdaptor})
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	// Start server
+	serverErrors := make(chan error, 1)
+	go func() {
+		slog.Info(fmt.Sprintf("Inventory gRPC server Listening on %s", listener.Addr()))
+		if err := srv.Serve(listener); err != nil {
+			serverErrors <- err
+		}
+	}()
+
+	defer func() {
+		slog.InfoContext(ctx, "Shutting down inventory gRPC server")
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			slog.Info("gRPC server gracefully stopped")
+		case <-time.After(gracefulStopTimeout):
+			srv.Stop()
+		}
+	}()
+
+	// Blocking select
+	select {
+	case err := <-serverErrors:
+		return fmt.Errorf("error starting inventory gRPC server: %w", err)
+	case <-ctx.Done():
+		slog.InfoContext(ctx, "Inventory gRPC server shutting down")
+	}
+
+	return nil
+}
+
+// correlationIDInterceptor tags each call with a correlation ID, taken from the call metadata if provided by the
+// client, and returns it in the response header metadata
+func correlationIDInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	key := strings.ToLower(logging.CorrelationIDHeader)
+
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !logging.IsValidCorrelationID(id) {
+		id = logging.NewCorrelationID()
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(key, id)); err != nil {
+		slog.WarnContext(ctx, "Failed to set correlation ID header", slog.String("error", err.Error()))
+	}
+	return handler(logging.WithCorrelationID(ctx, id), req)
+}
+
+// tracingInterceptor records a span for each call, continuing the trace of the client if it sent a traceparent in the
+// call metadata
+func tracingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+
+	ctx, span := tracing.StartSpan(ctx, info.FullMethod, semconv.RPCSystemGRPC, semconv.RPCMethod(info.FullMethod))
+	resp, err := handler(ctx, req)
+	tracing.EndSpan(span, err)
+	return resp, err
+}
+
+// metadataCarrier adapts the gRPC call metadata, whose keys are lower case, for the extraction of the trace context
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
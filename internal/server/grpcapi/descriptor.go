@@ -0,0 +1,234 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package grpcapi
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The descriptor of inventory.proto is built here rather than generated by protoc, and registered globally like
+// generated code. Messages are handled as dynamic messages of this descriptor. descriptor_test.go checks it against
+// inventory.proto.
+
+const (
+	protoFile    = "hwmgrplugin/inventory/v1/inventory.proto"
+	protoPackage = "hwmgrplugin.inventory.v1"
+
+	// ServiceName is the full name of the inventory service
+	ServiceName = protoPackage + ".InventoryService"
+)
+
+var fileDescriptor = registerFileDescriptor()
+
+func registerFileDescriptor() protoreflect.FileDescriptor {
+	fd, err := protodesc.NewFile(buildFileDescriptorProto(), protoregistry.GlobalFiles)
+	if err != nil {
+		panic(fmt.Sprintf("invalid descriptor for %s: %v", protoFile, err))
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic(fmt.Sprintf("failed to register %s: %v", protoFile, err))
+	}
+	return fd
+}
+
+// messageDescriptor returns the descriptor of a message defined in inventory.proto
+func messageDescriptor(name protoreflect.Name) protoreflect.MessageDescriptor {
+	return fileDescriptor.Messages().ByName(name)
+}
+
+type fieldDef struct {
+	name     string
+	number   int32
+	kind     descriptorpb.FieldDescriptorProto_Type
+	typeName string
+	repeated bool
+	optional bool
+}
+
+func stringField(name string, number int32) fieldDef {
+	return fieldDef{name: name, number: number, kind: descriptorpb.FieldDescriptorProto_TYPE_STRING}
+}
+
+func int32Field(name string, number int32) fieldDef {
+	return fieldDef{name: name, number: number, kind: descriptorpb.FieldDescriptorProto_TYPE_INT32}
+}
+
+func boolField(name string, number int32) fieldDef {
+	return fieldDef{name: name, number: number, kind: descriptorpb.FieldDescriptorProto_TYPE_BOOL}
+}
+
+func messageField(name string, number int32, typeName string) fieldDef {
+	return fieldDef{name: name, number: number, kind: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, typeName: typeName}
+}
+
+func (f fieldDef) asRepeated() fieldDef {
+	f.repeated = true
+	return f
+}
+
+func (f fieldDef) asOptional() fieldDef {
+	f.optional = true
+	return f
+}
+
+// messageProto builds the descriptor of a message, with a synthetic oneof for each proto3 optional field
+func messageProto(name string, fields ...fieldDef) *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String(name)}
+	for _, f := range fields {
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(f.name),
+			Number: proto.Int32(f.number),
+			Type:   f.kind.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if f.typeName != "" {
+			field.TypeName = proto.String(f.typeName)
+		}
+		if f.repeated {
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		}
+		if f.optional {
+			field.Proto3Optional = proto.Bool(true)
+			field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+			msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + f.name)})
+		}
+		msg.Field = append(msg.Field, field)
+	}
+	return msg
+}
+
+// mapEntryProto builds the descriptor of the entry message of a map field with string keys
+func mapEntryProto(name string, value fieldDef) *descriptorpb.DescriptorProto {
+	entry := messageProto(name, stringField("key", 1), value)
+	entry.Options = &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)}
+	return entry
+}
+
+func methodProto(name, input, output string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(typeRef(input)),
+		OutputType: proto.String(typeRef(output)),
+	}
+}
+
+func typeRef(name string) string {
+	return "." + protoPackage + "." + name
+}
+
+// buildFileDescriptorProto builds the descriptor of inventory.proto
+func buildFileDescriptorProto() *descriptorpb.FileDescriptorProto {
+	resourceInfo := messageProto("ResourceInfo",
+		stringField("resource_id", 1),
+		stringField("resource_pool_id", 2),
+		stringField("name", 3),
+		stringField("description", 4),
+		stringField("admin_state", 5),
+		stringField("operational_state", 6),
+		stringField("usage_state", 7),
+		stringField("power_state", 8).asOptional(),
+		stringField("hw_profile", 9),
+		stringField("global_asset_id", 10).asOptional(),
+		stringField("groups", 11).asRepeated(),
+		stringField("tags", 12).asRepeated(),
+		messageField("labels", 13, typeRef("ResourceInfo.LabelsEntry")).asRepeated(),
+		messageField("interfaces", 14, typeRef("NetworkInterfaceInfo")).asRepeated(),
+		int32Field("memory", 15),
+		stringField("model", 16),
+		stringField("part_number", 17),
+		stringField("serial_number", 18),
+		stringField("vendor", 19),
+		messageField("processors", 20, typeRef("ProcessorInfo")).asRepeated(),
+		boolField("allocated", 21).asOptional(),
+		stringField("node_pool_name", 22).asOptional(),
+		stringField("cloud_id", 23).asOptional(),
+		stringField("node_name", 24).asOptional(),
+	)
+	resourceInfo.NestedType = append(resourceInfo.NestedType, mapEntryProto("LabelsEntry", stringField("value", 2)))
+
+	resourcePoolInfo := messageProto("ResourcePoolInfo",
+		stringField("resource_pool_id", 1),
+		stringField("name", 2),
+		stringField("description", 3),
+		stringField("site_id", 4).asOptional(),
+		messageField("host_counts", 5, typeRef("ResourcePoolInfo.HostCountsEntry")).asRepeated(),
+	)
+	resourcePoolInfo.NestedType = append(resourcePoolInfo.NestedType, mapEntryProto("HostCountsEntry", int32Field("value", 2)))
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(protoFile),
+		Package: proto.String(protoPackage),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("github.com/openshift-kni/oran-hwmgr-plugin/internal/server/grpcapi"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			messageProto("GetResourcePoolsRequest",
+				stringField("hw_mgr_id", 1),
+				stringField("site_id", 2).asOptional(),
+			),
+			messageProto("GetResourcePoolsResponse",
+				messageField("resource_pools", 1, typeRef("ResourcePoolInfo")).asRepeated(),
+			),
+			messageProto("GetResourcesRequest",
+				stringField("hw_mgr_id", 1),
+			),
+			messageProto("GetResourcesResponse",
+				messageField("resources", 1, typeRef("ResourceInfo")).asRepeated(),
+			),
+			messageProto("GetResourceRequest",
+				stringField("hw_mgr_id", 1),
+				stringField("resource_id", 2),
+			),
+			messageProto("GetResourcePoolCapacityRequest",
+				stringField("hw_mgr_id", 1),
+			),
+			messageProto("GetResourcePoolCapacityResponse",
+				messageField("resource_pools", 1, typeRef("ResourcePoolCapacity")).asRepeated(),
+			),
+			resourcePoolInfo,
+			messageProto("ResourcePoolCapacity",
+				stringField("resource_pool_id", 1),
+				stringField("site_id", 2).asOptional(),
+				int32Field("total", 3),
+				int32Field("allocated", 4),
+				int32Field("free", 5),
+			),
+			resourceInfo,
+			messageProto("NetworkInterfaceInfo",
+				stringField("labels", 1).asRepeated(),
+				stringField("mac_address", 2),
+				stringField("model", 3).asOptional(),
+				stringField("name", 4).asOptional(),
+				int32Field("speed", 5).asOptional(),
+			),
+			messageProto("ProcessorInfo",
+				stringField("architecture", 1).asOptional(),
+				int32Field("cores", 2).asOptional(),
+				stringField("manufacturer", 3).asOptional(),
+				stringField("model", 4).asOptional(),
+			),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("InventoryService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					methodProto("GetResourcePools", "GetResourcePoolsRequest", "GetResourcePoolsResponse"),
+					methodProto("GetResources", "GetResourcesRequest", "GetResourcesResponse"),
+					methodProto("GetResource", "GetResourceRequest", "ResourceInfo"),
+					methodProto("GetResourcePoolCapacity", "GetResourcePoolCapacityRequest", "GetResourcePoolCapacityResponse"),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,162 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package grpcapi
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	protoPackagePattern   = regexp.MustCompile(`^package\s+([\w.]+);$`)
+	protoGoPackagePattern = regexp.MustCompile(`^option\s+go_package\s*=\s*"([^"]+)";$`)
+	protoServicePattern   = regexp.MustCompile(`^service\s+(\w+)\s*\{$`)
+	protoMessagePattern   = regexp.MustCompile(`^message\s+(\w+)\s*\{$`)
+	protoRPCPattern       = regexp.MustCompile(`^rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(\w+)\s*\);$`)
+	protoMapFieldPattern  = regexp.MustCompile(`^map<\s*(\w+)\s*,\s*(\w+)\s*>\s+(\w+)\s*=\s*(\d+);$`)
+	protoFieldPattern     = regexp.MustCompile(`^(?:(optional|repeated)\s+)?(\w+)\s+(\w+)\s*=\s*(\d+);$`)
+)
+
+// parseProtoFile reads the subset of the proto3 syntax used by inventory.proto, describing each declaration as a line
+// that can be compared with describeFileDescriptor
+func parseProtoFile(t *testing.T, path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var declarations []string
+	var scope string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		var match []string
+		switch {
+		case line == `syntax = "proto3";`:
+			declarations = append(declarations, "syntax proto3")
+		case line == "}":
+			if scope == "" {
+				t.Fatalf("%s:%d: unexpected }", path, lineNumber)
+			}
+			scope = ""
+		case scope == "":
+			if match = protoPackagePattern.FindStringSubmatch(line); match != nil {
+				declarations = append(declarations, "package "+match[1])
+			} else if match = protoGoPackagePattern.FindStringSubmatch(line); match != nil {
+				declarations = append(declarations, "go_package "+match[1])
+			} else if match = protoServicePattern.FindStringSubmatch(line); match != nil {
+				scope = match[1]
+				declarations = append(declarations, "service "+scope)
+			} else if match = protoMessagePattern.FindStringSubmatch(line); match != nil {
+				scope = match[1]
+				declarations = append(declarations, "message "+scope)
+			} else {
+				t.Fatalf("%s:%d: unsupported declaration: %s", path, lineNumber, line)
+			}
+		default:
+			if match = protoRPCPattern.FindStringSubmatch(line); match != nil {
+				declarations = append(declarations, fmt.Sprintf("rpc %s.%s(%s) %s", scope, match[1], match[2], match[3]))
+			} else if match = protoMapFieldPattern.FindStringSubmatch(line); match != nil {
+				declarations = append(declarations,
+					fmt.Sprintf("field %s.%s = %s map<%s, %s>", scope, match[3], match[4], match[1], match[2]))
+			} else if match = protoFieldPattern.FindStringSubmatch(line); match != nil {
+				label := match[1]
+				if label == "" {
+					label = "singular"
+				}
+				declarations = append(declarations,
+					fmt.Sprintf("field %s.%s = %s %s %s", scope, match[3], match[4], label, match[2]))
+			} else {
+				t.Fatalf("%s:%d: unsupported declaration in %s: %s", path, lineNumber, scope, line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	slices.Sort(declarations)
+	return declarations
+}
+
+// fieldTypeName returns the name of the type of a field, as written in a proto file
+func fieldTypeName(fd protoreflect.FieldDescriptor) string {
+	if fd.Kind() == protoreflect.MessageKind {
+		return string(fd.Message().Name())
+	}
+	return fd.Kind().String()
+}
+
+// describeFileDescriptor describes each declaration of a file descriptor in the form used by parseProtoFile
+func describeFileDescriptor(fd protoreflect.FileDescriptor) []string {
+	declarations := []string{
+		"syntax " + fd.Syntax().String(),
+		"package " + string(fd.Package()),
+		"go_package " + fd.Options().(interface{ GetGoPackage() string }).GetGoPackage(),
+	}
+
+	for i := 0; i < fd.Services().Len(); i++ {
+		service := fd.Services().Get(i)
+		declarations = append(declarations, "service "+string(service.Name()))
+		for j := 0; j < service.Methods().Len(); j++ {
+			method := service.Methods().Get(j)
+			declarations = append(declarations, fmt.Sprintf("rpc %s.%s(%s) %s", service.Name(), method.Name(),
+				method.Input().Name(), method.Output().Name()))
+		}
+	}
+
+	for i := 0; i < fd.Messages().Len(); i++ {
+		message := fd.Messages().Get(i)
+		declarations = append(declarations, "message "+string(message.Name()))
+		for j := 0; j < message.Fields().Len(); j++ {
+			field := message.Fields().Get(j)
+			prefix := fmt.Sprintf("field %s.%s = %d", message.Name(), field.Name(), field.Number())
+			switch {
+			case field.IsMap():
+				declarations = append(declarations, fmt.Sprintf("%s map<%s, %s>", prefix,
+					fieldTypeName(field.MapKey()), fieldTypeName(field.MapValue())))
+			case field.IsList():
+				declarations = append(declarations, fmt.Sprintf("%s repeated %s", prefix, fieldTypeName(field)))
+			case field.HasOptionalKeyword():
+				declarations = append(declarations, fmt.Sprintf("%s optional %s", prefix, fieldTypeName(field)))
+			default:
+				declarations = append(declarations, fmt.Sprintf("%s singular %s", prefix, fieldTypeName(field)))
+			}
+		}
+	}
+
+	slices.Sort(declarations)
+	return declarations
+}
+
+func TestDescriptorMatchesProtoFile(t *testing.T) {
+	expected := parseProtoFile(t, "inventory.proto")
+	actual := describeFileDescriptor(fileDescriptor)
+
+	for _, declaration := range expected {
+		if !slices.Contains(actual, declaration) {
+			t.Errorf("declared in inventory.proto but not in descriptor.go: %s", declaration)
+		}
+	}
+	for _, declaration := range actual {
+		if !slices.Contains(expected, declaration) {
+			t.Errorf("declared in descriptor.go but not in inventory.proto: %s", declaration)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+)
+
+// inventoryBaseURL is the prefix of the inventory REST API paths
+const inventoryBaseURL = "/hardware-manager/inventory/v1"
+
+// InventoryServer serves the inventory gRPC API, with the same data as the inventory REST API
+type InventoryServer struct {
+	HwMgrAdaptor *adaptors.HwMgrAdaptorController
+}
+
+type inventoryCall func(s *InventoryServer, ctx context.Context, req *dynamicpb.Message) (proto.Message, error)
+
+// ServiceDesc describes the inventory service for registration with a gRPC server
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetResourcePools",
+			Handler:    unaryHandler("GetResourcePools", "GetResourcePoolsRequest", (*InventoryServer).GetResourcePools),
+		},
+		{
+			MethodName: "GetResources",
+			Handler:    unaryHandler("GetResources", "GetResourcesRequest", (*InventoryServer).GetResources),
+		},
+		{
+			MethodName: "GetResource",
+			Handler:    unaryHandler("GetResource", "GetResourceRequest", (*InventoryServer).GetResource),
+		},
+		{
+			MethodName: "GetResourcePoolCapacity",
+			Handler:    unaryHandler("GetResourcePoolCapacity", "GetResourcePoolCapacityRequest", (*InventoryServer).GetResourcePoolCapacity),
+		},
+	},
+	Metadata: protoFile,
+}
+
+// unaryHandler decodes the request of a call into a dynamic message of the given type and dispatches it through any
+// interceptor, as protoc-generated handlers do for generated types
+func unaryHandler(method string, input protoreflect.Name, call inventoryCall) func(
+	srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	inputDesc := messageDescriptor(input)
+	fullMethod := "/" + ServiceName + "/" + method
+
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		in := dynamicpb.NewMessage(inputDesc)
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		handler := func(ctx context.Context, req any) (any, error) {
+			return call(srv.(*InventoryServer), ctx, req.(*dynamicpb.Message))
+		}
+		if interceptor == nil {
+			return handler(ctx, in)
+		}
+		return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+	}
+}
+
+// GetResourcePools handles a call to list the resource pools of a hardware manager
+func (s *InventoryServer) GetResourcePools(ctx context.Context, req *dynamicpb.Message) (proto.Message, error) {
+	hwMgrId, err := getRequiredString(req, "hw_mgr_id")
+	if err != nil {
+		return nil, err
+	}
+
+	pools, err := s.HwMgrAdaptor.ListResourcePools(ctx, hwMgrId, getOptionalString(req, "site_id"))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toMessage("GetResourcePoolsResponse", struct {
+		ResourcePools []invserver.ResourcePoolInfo `json:"resourcePools"`
+	}{pools})
+}
+
+// GetResources handles a call to list the resources of a hardware manager
+func (s *InventoryServer) GetResources(ctx context.Context, req *dynamicpb.Message) (proto.Message, error) {
+	hwMgrId, err := getRequiredString(req, "hw_mgr_id")
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := s.HwMgrAdaptor.ListResources(ctx, hwMgrId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toMessage("GetResourcesResponse", struct {
+		Resources []invserver.ResourceInfo `json:"resources"`
+	}{resources})
+}
+
+// GetResource handles a call to get a single resource of a hardware manager
+func (s *InventoryServer) GetResource(ctx context.Context, req *dynamicpb.Message) (proto.Message, error) {
+	hwMgrId, err := getRequiredString(req, "hw_mgr_id")
+	if err != nil {
+		return nil, err
+	}
+	resourceId, err := getRequiredString(req, "resource_id")
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.HwMgrAdaptor.GetResourceInfo(ctx, hwMgrId, resourceId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toMessage("ResourceInfo", resource)
+}
+
+// GetResourcePoolCapacity handles a call to count the resources of each resource pool of a hardware manager
+func (s *InventoryServer) GetResourcePoolCapacity(ctx context.Context, req *dynamicpb.Message) (proto.Message, error) {
+	hwMgrId, err := getRequiredString(req, "hw_mgr_id")
+	if err != nil {
+		return nil, err
+	}
+
+	capacity, err := s.HwMgrAdaptor.ListResourcePoolCapacity(ctx, hwMgrId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toMessage("GetResourcePoolCapacityResponse", struct {
+		ResourcePools []invserver.ResourcePoolCapacity `json:"resourcePools"`
+	}{capacity})
+}
+
+// getRequiredString returns the value of a string field of a request, which must be set
+func getRequiredString(req *dynamicpb.Message, name protoreflect.Name) (string, error) {
+	value := req.Get(req.Descriptor().Fields().ByName(name)).String()
+	if value == "" {
+		return "", status.Errorf(codes.InvalidArgument, "%s is required", name)
+	}
+	return value, nil
+}
+
+// getOptionalString returns the value of an optional string field of a request, or "" if it is not set
+func getOptionalString(req *dynamicpb.Message, name protoreflect.Name) string {
+	return req.Get(req.Descriptor().Fields().ByName(name)).String()
+}
+
+// toMessage converts an inventory API object to a message of the given type. The messages of inventory.proto use the
+// same JSON names as the inventory API objects, so the conversion is done through their JSON encoding.
+func toMessage(name protoreflect.Name, v any) (proto.Message, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode %s: %v", name, err)
+	}
+
+	msg := dynamicpb.NewMessage(messageDescriptor(name))
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert %s: %v", name, err)
+	}
+	return msg, nil
+}
+
+// toStatusError converts an inventory query failure to a gRPC status error
+func toStatusError(err error) error {
+	var invErr *adaptors.InventoryError
+	if !errors.As(err, &invErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code := codes.Internal
+	switch invErr.Status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, invErr.Detail)
+}
+
+// InventoryPath returns the inventory REST API path equivalent to a call, so calls can be authorized against the
+// same RBAC rules as the REST API
+func InventoryPath(fullMethod string, req any) string {
+	msg, ok := req.(*dynamicpb.Message)
+	if !ok {
+		return inventoryBaseURL + fullMethod
+	}
+
+	field := func(name protoreflect.Name) string {
+		fd := msg.Descriptor().Fields().ByName(name)
+		if fd == nil {
+			return ""
+		}
+		return url.PathEscape(msg.Get(fd).String())
+	}
+
+	switch fullMethod {
+	case "/" + ServiceName + "/GetResourcePools":
+		return fmt.Sprintf("%s/manager/%s/resourcePools", inventoryBaseURL, field("hw_mgr_id"))
+	case "/" + ServiceName + "/GetResources":
+		return fmt.Sprintf("%s/manager/%s/resources", inventoryBaseURL, field("hw_mgr_id"))
+	case "/" + ServiceName + "/GetResource":
+		return fmt.Sprintf("%s/manager/%s/resources/%s", inventoryBaseURL, field("hw_mgr_id"), field("resource_id"))
+	case "/" + ServiceName + "/GetResourcePoolCapacity":
+		return fmt.Sprintf("%s/manager/%s/capacity", inventoryBaseURL, field("hw_mgr_id"))
+	}
+	return inventoryBaseURL + fullMethod
+}
@@ -0,0 +1,231 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/auth"
+)
+
+const testToken = "Bearer test-token"
+
+// newRequest returns a request message of the given type with the given string fields set
+func newRequest(t *testing.T, name protoreflect.Name, fields map[string]string) *dynamicpb.Message {
+	desc := messageDescriptor(name)
+	if desc == nil {
+		t.Fatalf("message %s is not defined", name)
+	}
+	msg := dynamicpb.NewMessage(desc)
+	for field, value := range fields {
+		fd := desc.Fields().ByName(protoreflect.Name(field))
+		if fd == nil {
+			t.Fatalf("message %s has no field %s", name, field)
+		}
+		msg.Set(fd, protoreflect.ValueOfString(value))
+	}
+	return msg
+}
+
+// recordingAuthorizer denies every call, recording the path each was authorized against
+type recordingAuthorizer struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (a *recordingAuthorizer) Authorize(ctx context.Context, attributes authorizer.Attributes) (authorizer.Decision, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paths = append(a.paths, attributes.GetPath())
+	return authorizer.DecisionDeny, "denied by test", nil
+}
+
+func (a *recordingAuthorizer) lastPath() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.paths) == 0 {
+		return ""
+	}
+	return a.paths[len(a.paths)-1]
+}
+
+// newTestConn starts the inventory service on an in-memory listener, behind the auth interceptor, and returns a client
+// connection to it. Only testToken is authenticated, and every authenticated call is denied by the authorizer, so
+// calls never reach the hardware manager adaptor.
+func newTestConn(t *testing.T) (*grpc.ClientConn, *recordingAuthorizer) {
+	authn := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		if req.Header.Get("Authorization") != testToken {
+			return nil, false, nil
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{Name: "test-user"}}, true, nil
+	})
+	authz := &recordingAuthorizer{}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(auth.GRPCAuthInterceptor(authn, authz, InventoryPath)))
+	server.RegisterService(&ServiceDesc, &InventoryServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, authz
+}
+
+var inventoryPathTests = []struct {
+	description string
+	method      string
+	input       protoreflect.Name
+	output      protoreflect.Name
+	fields      map[string]string
+	expected    string
+}{
+	{
+		description: "resource pools",
+		method:      "GetResourcePools",
+		input:       "GetResourcePoolsRequest",
+		output:      "GetResourcePoolsResponse",
+		fields:      map[string]string{"hw_mgr_id": "dell-1", "site_id": "site-1"},
+		expected:    "/hardware-manager/inventory/v1/manager/dell-1/resourcePools",
+	},
+	{
+		description: "resources",
+		method:      "GetResources",
+		input:       "GetResourcesRequest",
+		output:      "GetResourcesResponse",
+		fields:      map[string]string{"hw_mgr_id": "dell-1"},
+		expected:    "/hardware-manager/inventory/v1/manager/dell-1/resources",
+	},
+	{
+		description: "resource",
+		method:      "GetResource",
+		input:       "GetResourceRequest",
+		output:      "ResourceInfo",
+		fields:      map[string]string{"hw_mgr_id": "dell-1", "resource_id": "node-1"},
+		expected:    "/hardware-manager/inventory/v1/manager/dell-1/resources/node-1",
+	},
+	{
+		description: "resource pool capacity",
+		method:      "GetResourcePoolCapacity",
+		input:       "GetResourcePoolCapacityRequest",
+		output:      "GetResourcePoolCapacityResponse",
+		fields:      map[string]string{"hw_mgr_id": "dell-1"},
+		expected:    "/hardware-manager/inventory/v1/manager/dell-1/capacity",
+	},
+	{
+		description: "escaped identifiers",
+		method:      "GetResource",
+		input:       "GetResourceRequest",
+		output:      "ResourceInfo",
+		fields:      map[string]string{"hw_mgr_id": "dell/1", "resource_id": "../node 1"},
+		expected:    "/hardware-manager/inventory/v1/manager/dell%2F1/resources/..%2Fnode%201",
+	},
+}
+
+func TestInventoryPath(t *testing.T) {
+	for _, test := range inventoryPathTests {
+		t.Run(test.description, func(t *testing.T) {
+			path := InventoryPath("/"+ServiceName+"/"+test.method, newRequest(t, test.input, test.fields))
+			if path != test.expected {
+				t.Errorf("expected path %s, got %s", test.expected, path)
+			}
+		})
+	}
+
+	t.Run("unknown method", func(t *testing.T) {
+		fullMethod := "/" + ServiceName + "/DeleteResource"
+		path := InventoryPath(fullMethod, newRequest(t, "GetResourceRequest", nil))
+		if expected := "/hardware-manager/inventory/v1" + fullMethod; path != expected {
+			t.Errorf("expected path %s, got %s", expected, path)
+		}
+	})
+
+	t.Run("unknown request type", func(t *testing.T) {
+		fullMethod := "/" + ServiceName + "/GetResources"
+		path := InventoryPath(fullMethod, struct{}{})
+		if expected := "/hardware-manager/inventory/v1" + fullMethod; path != expected {
+			t.Errorf("expected path %s, got %s", expected, path)
+		}
+	})
+}
+
+func TestInventoryServiceAuthorizesInventoryPath(t *testing.T) {
+	conn, authz := newTestConn(t)
+
+	for _, test := range inventoryPathTests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", testToken)
+			req := newRequest(t, test.input, test.fields)
+			resp := dynamicpb.NewMessage(messageDescriptor(test.output))
+
+			err := conn.Invoke(ctx, "/"+ServiceName+"/"+test.method, req, resp)
+			if status.Code(err) != codes.PermissionDenied {
+				t.Fatalf("expected the call to be denied, got %v", err)
+			}
+			if path := authz.lastPath(); path != test.expected {
+				t.Errorf("expected the call to be authorized against %s, got %s", test.expected, path)
+			}
+		})
+	}
+}
+
+func TestInventoryServiceUnauthenticated(t *testing.T) {
+	conn, authz := newTestConn(t)
+
+	tests := []struct {
+		description string
+		token       string
+	}{
+		{description: "no token"},
+		{description: "invalid token", token: "Bearer invalid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ctx := context.Background()
+			if test.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", test.token)
+			}
+			req := newRequest(t, "GetResourcesRequest", map[string]string{"hw_mgr_id": "dell-1"})
+			resp := dynamicpb.NewMessage(messageDescriptor("GetResourcesResponse"))
+
+			err := conn.Invoke(ctx, "/"+ServiceName+"/GetResources", req, resp)
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("expected an unauthenticated error, got %v", err)
+			}
+		})
+	}
+
+	if path := authz.lastPath(); path != "" {
+		t.Errorf("expected unauthenticated calls not to be authorized, got %s", path)
+	}
+}
@@ -18,10 +18,11 @@ import (
 	"syscall"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
 	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api"
-	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
 	"github.com/openshift-kni/oran-hwmgr-plugin/internal/server/auth"
 )
 
@@ -32,9 +33,13 @@ const (
 	idleTimeout  = 120 * time.Second
 )
 
-// RunServer starts the API server and blocks until it terminates or context is canceled.
-func RunServer(ctx context.Context, address, tlsCertDir string, hwMgrAdaptor *adaptors.HwMgrAdaptorController) error {
-	slog.InfoContext(ctx, "Starting inventory API server")
+// RunServer starts the API server and blocks until it terminates or context is canceled. The inventory API is only
+// served if serveInventory is set, and the job callbacks are only accepted if jobEvents is set, so that the inventory
+// API and the controllers can be run in separate processes.
+func RunServer(ctx context.Context, address, tlsCertDir string, tlsOptions utils.ServerTLSOptions,
+	hwMgrAdaptor *adaptors.HwMgrAdaptorController, jobEvents chan<- event.GenericEvent, serveInventory bool) error {
+	slog.InfoContext(ctx, "Starting API server",
+		slog.Bool("inventory", serveInventory), slog.Bool("jobCallbacks", jobEvents != nil))
 	// Channel for shutdown signals
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -54,23 +59,10 @@ func RunServer(ctx context.Context, address, tlsCertDir string, hwMgrAdaptor *ad
 		HwMgrAdaptor: hwMgrAdaptor,
 	}
 
-	serverStrictHandler := generated.NewStrictHandlerWithOptions(&server, nil,
-		generated.StrictHTTPServerOptions{
-			RequestErrorHandlerFunc:  api.GetRequestErrorFunc(),
-			ResponseErrorHandlerFunc: api.GetResponseErrorFunc(),
-		},
-	)
-
 	router := http.NewServeMux()
 	// Register a default handler that replies with 404 so that we can override the response format
 	router.HandleFunc("/", api.GetNotFoundFunc())
 
-	// This also validates the spec file
-	swagger, err := generated.GetSwagger()
-	if err != nil {
-		return fmt.Errorf("failed to get swagger: %w", err)
-	}
-
 	// Create authn/authz middleware
 	authn, err := auth.GetAuthenticator()
 	if err != nil {
@@ -82,23 +74,36 @@ func RunServer(ctx context.Context, address, tlsCertDir string, hwMgrAdaptor *ad
 		return fmt.Errorf("error setting up authorizer middleware: %w", err)
 	}
 
-	opt := generated.StdHTTPServerOptions{
-		BaseRouter: router,
-		Middlewares: []generated.MiddlewareFunc{ // Add middlewares here
-			api.GetOpenAPIValidationFunc(swagger),
-			authz,
-			authn,
-			api.GetLogDurationFunc(),
-		},
-		ErrorHandlerFunc: api.GetRequestErrorFunc(),
+	// Register the handlers of each served version of the inventory API, routed by their URI prefix
+	if serveInventory {
+		if err := api.RegisterInventoryAPIVersions(router, &server, authn, authz); err != nil {
+			return fmt.Errorf("failed to register inventory API: %w", err)
+		}
 	}
 
-	// Register the handler
-	generated.HandlerWithOptions(serverStrictHandler, opt)
+	// Register the job callback handler. Hardware managers are not cluster clients, so this route is authenticated with
+	// the callback token of the HardwareManager rather than by the authn/authz middleware.
+	if jobEvents != nil {
+		callbackServer := api.JobCallbackServer{
+			HwMgrAdaptor: hwMgrAdaptor,
+			JobEvents:    jobEvents,
+		}
+		router.Handle(api.JobCallbackPattern,
+			api.GetCorrelationIDFunc()(api.GetHwMgrFunc()(api.GetTracingFunc()(api.GetLogDurationFunc()(http.HandlerFunc(callbackServer.HandleJobCallback))))))
+	}
+
+	// Register the health status handler, which is not part of the inventory API but is subject to the same
+	// authn/authz as it exposes the state of the hardware managers
+	statusServer := api.HealthStatusServer{
+		HwMgrAdaptor: hwMgrAdaptor,
+	}
+	router.Handle(api.HealthStatusPattern,
+		api.GetCorrelationIDFunc()(api.GetLogDurationFunc()(authn(authz(http.HandlerFunc(statusServer.HandleHealthStatus))))))
 
 	certFile := filepath.Join(tlsCertDir, "tls.crt")
 	keyFile := filepath.Join(tlsCertDir, "tls.key")
-	serverTLSConfig, err := utils.GetServerTLSConfig(ctx, certFile, keyFile)
+	tlsOptions.Name = "api"
+	serverTLSConfig, err := utils.GetServerTLSConfig(ctx, certFile, keyFile, tlsOptions)
 	if err != nil {
 		return fmt.Errorf("failed to get server TLS config: %w", err)
 	}
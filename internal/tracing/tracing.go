@@ -0,0 +1,139 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/internal/logging"
+)
+
+//
+// Spans are recorded around the phases of each reconcile and API request, and around each call to a hardware manager,
+// so that the time spent provisioning a NodePool can be broken down between the plugin and the hardware manager. The
+// trace context is propagated to hardware managers, and accepted from API clients, in the W3C traceparent header.
+// Without an exporter configured, spans are not recorded, but the trace context is still propagated.
+//
+
+const (
+	// TracerName is the instrumentation scope of the spans of the plugin
+	TracerName = "github.com/openshift-kni/oran-hwmgr-plugin"
+
+	serviceName = "oran-hwmgr-plugin"
+)
+
+// Span attributes identifying the objects a span is working on
+const (
+	AttrHwMgr         = attribute.Key("hwmgr")
+	AttrAdaptor       = attribute.Key("hwmgr.adaptor")
+	AttrNodePool      = attribute.Key("nodepool")
+	AttrNodePoolPhase = attribute.Key("nodepool.phase")
+	AttrCorrelationID = attribute.Key("correlationID")
+)
+
+// Config configures the export of spans
+type Config struct {
+	// Endpoint is the host:port of the OTLP gRPC collector. Spans are not exported if empty.
+	Endpoint string
+
+	// Insecure disables TLS for the connection to the collector
+	Insecure bool
+
+	// SampleRatio is the fraction of traces started by the plugin that are sampled. Traces started by an API client
+	// follow the sampling decision of the client.
+	SampleRatio float64
+}
+
+// Setup installs the global propagator and, if an endpoint is configured, a tracer provider exporting to it. The
+// returned function flushes and stops the export.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid trace sample ratio %v: must be between 0 and 1", config.SampleRatio)
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span as a child of any span in the context, tagged with the correlation ID and HardwareManager
+// carried by the context
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if id := logging.CorrelationIDFromContext(ctx); id != "" {
+		attrs = append(attrs, AttrCorrelationID.String(id))
+	}
+	if hwmgr := logging.HwMgrFromContext(ctx); hwmgr != "" {
+		attrs = append(attrs, AttrHwMgr.String(hwmgr))
+	}
+
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...)) // nolint: spancheck
+}
+
+// EndSpan ends a span, recording the error it ended with, if any
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// SetAttributes adds attributes to the current span of the context
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+}
+
+// WrapTransport records a client span for each request sent through the transport, and propagates the trace context
+// of the request in its headers
+func WrapTransport(transport http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(transport,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}))
+}
+
+// WrapHandler records a server span for each request received by the handler, continuing the trace of the client if
+// it provides a trace context
+func WrapHandler(handler http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(handler, operation,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}))
+}
@@ -9,6 +9,7 @@ package typederrors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // GenericError is an error structure containing common fields to be
@@ -106,6 +107,34 @@ func IsNonRetriableError(target error) bool {
 	return errors.As(target, &e)
 }
 
+// CircuitOpenError type, returned without calling a backend whose circuit breaker is open
+type CircuitOpenError struct {
+	GenericError
+	// RetryAfter is the time remaining until a call to the backend is next allowed
+	RetryAfter time.Duration
+}
+
+func NewCircuitOpenError(retryAfter time.Duration, format string, args ...interface{}) error {
+	return CircuitOpenError{
+		GenericError: GenericError{fmt.Sprintf(format, args...), nil},
+		RetryAfter:   retryAfter,
+	}
+}
+
+func IsCircuitOpenError(target error) bool {
+	var e CircuitOpenError
+	return errors.As(target, &e)
+}
+
+// GetCircuitOpenRetryAfter returns the time until a call is next allowed, if the error is a CircuitOpenError
+func GetCircuitOpenRetryAfter(target error) (time.Duration, bool) {
+	var e CircuitOpenError
+	if !errors.As(target, &e) {
+		return 0, false
+	}
+	return e.RetryAfter, true
+}
+
 // InputError wraps a standard error and provides a custom error type for input-related errors
 type InputError struct {
 	err error
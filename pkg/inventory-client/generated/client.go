@@ -68,6 +68,24 @@ type APIVersions struct {
 	UriPrefix   *string       `json:"uriPrefix,omitempty"`
 }
 
+// NetworkInterfaceInfo Information about a network interface
+type NetworkInterfaceInfo struct {
+	// Labels Labels identifying the role of the network interface, such as the data or boot interface
+	Labels *[]string `json:"labels,omitempty"`
+
+	// MacAddress The MAC address of the network interface
+	MacAddress string `json:"macAddress"`
+
+	// Model The model of the network interface card
+	Model *string `json:"model,omitempty"`
+
+	// Name The name of the network interface
+	Name *string `json:"name,omitempty"`
+
+	// Speed The link speed of the network interface in Mbps
+	Speed *int `json:"speed,omitempty"`
+}
+
 // ProblemDetails defines model for ProblemDetails.
 type ProblemDetails struct {
 	// AdditionalAttributes Any number of additional attributes, as defined in a specification or by an implementation.
@@ -144,6 +162,9 @@ type ResourceInfo struct {
 	Groups    *[]string `json:"groups,omitempty"`
 	HwProfile string    `json:"hwProfile"`
 
+	// Interfaces The network interfaces of the resource
+	Interfaces *[]NetworkInterfaceInfo `json:"interfaces,omitempty"`
+
 	// Labels Optional labels applied to this resource
 	Labels *map[string]string `json:"labels,omitempty"`
 
@@ -1908,56 +1929,59 @@ func ParseGetSubscriptionResponse(rsp *http.Response) (*GetSubscriptionResponse,
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xce3PbtrL/KhjeO3PbuZTkVz0++s+xnUTTxPb40fZM5OlA5FJECwIsAMpWPfruZwDw",
-	"TUii82icHP8VhQKB3cX+fruLhfzoBTxJOQOmpDd+9FIscAIKhPlffP9+Liah/hiCDARJFeHMG3u3jPyV",
-	"ASIhMEUiAgLxCGEUYxHeYwEowQzPQQynzPM9eMBJSsEbe5InMFgAC7kYUB5gM5vvET1lilXs+R7DiR5Z",
-	"rOx7Av7KiIDQGyuRge/JIIYEa5HUMjWTKkHY3FutfE9ms1LKJ4hdf60tMsZH++HODA/wTwCDg2g3Gszg",
-	"6GAQ7e8fzPZ2dw8Pg8itQkuYTZpEXCRYeWMvy4ge2dZsVQw2u3J8OfkFhDQqtTWcMDsX4QzhGc8Uwmhh",
-	"B2tdVQzo+HJilUwFT0EoAmbWRTVlpf3ucGe44xCofMJnf0CgvJVfk0r2E4sSqbRM+cJyi3w4JfX5Sxk/",
-	"1ETP5V3d+R5RkJiB/ysg8sbe/4wqRx/lxhzVLFmphIXAS/3/TJBLARF5aNpkVHj5IPfyEWELYIqL5Wix",
-	"289Yl4LPKCSnoDChFnhNZcOQaGNheqyUILNMtZ9fNsa3lvRb5j9mS8SyZJY7fDkJwuXsPsIShRARBiEi",
-	"TKMihYBExKIUcYFmS4QZItoMCTBlng89h3ahUavrBccozhLMBgJwiGcUEDykFDO7QLEcUhypmEjEgyAT",
-	"AlgAhWek1mrDBkBPOGMQmCkURyFWeIYlIEUSCBHPVHdDNFqlwiwAl4i3VxMkIAK7soqxqvhCGjFKSddL",
-	"OGUThRK8REsCNERRJlQMApEaDEiEQigXCq3LV0QgiEtwqbDKHPi6iQG9vbm5RHYACngIKOKihyXLJQmr",
-	"2YowBXMQBhZEUaelZMyF8tt7KrMkwWLZWgnpeYdoovRbGQ0R4woFMWZzQJHgSV1GxddL7E8ZPASQKqNd",
-	"momUSzDUoeMJJX9br0STyKyIiERzsgCGMAsRN5ugYszQ1DM0NJ5RzP6cer41VAkHJGNMKcJUcjQziy9I",
-	"WGxSZ1fsg22uhIOAi5CwuVZwcnbzGl29PkH7/zo6RB/275ye1jEekQhYwDOB5xDaV/Q4vVAuo5yy1oaE",
-	"PMhKvOZOUU39AwznQ5RJwuZvb96/+xHdx8Canol+1Y+MgRIwJEKk2b9UgASm/CkjSqIFppkxOJYy0+BT",
-	"xnYtS7fja6xUKsejUeGRNRsOA55sxcSqHlc/FAApOejOTb4BSMmFjkr9YlVavNINSyKIiYJAZQLcuCzf",
-	"RY2xdSM8HB0ODg9crhVwAWvwrrjCtEbrabyUJMAU2Xdq8+/vuXCdYJZF2Agj3CvUR9RwWFqiUmDCFFCX",
-	"/AkPgW6f/f9kzUzmHWSyqM4aP1z9iH4DzvS/bzgN0eHB/v55v6B7BZJnIoATQzvnXJXRrZ8TiPz9grdY",
-	"fYa2XwScaRSI6y0pqbaERU5BMjrOaksXM+hobMJObaZWsHBmjb5XF/BMpyg3TqK6YCXLRpxSfq85ysgk",
-	"x2gHDVAgACvw0S4a6M0h0dJHe2iAQqCgwAKaZYk3/rDj7/p7dy5vq8vissMxyjrJueJIQE4yln/qsyDQ",
-	"KvWzRO4ETuvb3Qyr7bWDG1xfOZH9dKVzStdkt1fvbAJTToNutOA5YxZUpaO/HuPcIT14D/1wevbu7Obs",
-	"R2fMabFey7jrdv5uAyj6c2Fhp2GXC8OEsGuF1RomNN8TqQRWZAEmVyk9r5i18iXv9vzdxcnPZ6ee712/",
-	"vb25mZy/+f304leN9vKL2/Ofz/WjO39LDtyW560OkqgKktWXbYma6eY1T5qjrVmMd9Z06Agzp3yG6bGU",
-	"oFzuP6mVpAJJEKTB7XV5fO08eIEJ1ZI3pXsQR4c76iFgUTjf23PKIXiWOiLKz7C85yLUNYB2HjZHdmSd",
-	"92ZAOZtLpLhetayw1uRDVSEV318KHhGbRVbCiniQ2ucDBVINZliSwCUzxTOgn1L/XKR5vWNnQjhNKbEZ",
-	"SnvjKvEep3bhAZ56YzT1DCPq//hTnT/a72b172ZTb+VmjQQSLpab4ngZve1QTfrvyStnQr4hptqzlVoE",
-	"dcGr1PCS34M4C+eAfrvSfuOMIeYwo73WtU797QJFQumGy3aH1NuI7fZsoI7aqK28cXZ+/OqdYYfTyXXx",
-	"cRNRpFioc4O1jVbVw9Zg0qVYqq27QSXz/VZlLjTdXbx+7Ra8yJkMCHodeTSTXwdYCxm2sFSx7Vcfue3F",
-	"MpecU7tUkxg4p4MNr1uG7LFpG6nUWczh+WZ61I9nmiC5QAHFUpJoaaq62sSoPGF4Ck9mEs+h9JjCAyan",
-	"78483zs+uZn8oj+8ur3+9xaHtrp3tfjF2kQzRD2176Tap0ApmrBguDXzqHlLZ0/rxN9k5JxWSkELTmvt",
-	"awOZJYk23N6vJx0OMmkYdVP+Y2R+cg6EtJ92E6HPlHmUs396+uGm8ZYoroDhkKEHPLvo7k0kSL9T1Dzt",
-	"hkKJqydLJInqS2lFZ6KPKcJsvzdGSljkzl8XxOWa9dKxl1syVJ5EO3oarfoUUzrDwZ9u8owySpforwxT",
-	"bZrQHDApjnBVlBoMhpkAdB+TIEYBZkUJjzC65PZsX5tvytYX3msO1PoWz47NKwXkkS0QJTLlY5hBUZjV",
-	"ZzUVGUg17FNFRoQqV7g5EURp3jJC5Itaq4TclH0MyuMwASkXCkJNwveEUv3MzltV/vW9Q1PWKHoliAUJ",
-	"QJeVICDiIq8H8kmqo7n8MEHFwBCmtJBLg6mQYY315dOtXjdpUfFWo4jUEmhUVTq+LZD9Pm8VOjZAE9MF",
-	"o8uiYbYZZqVHd7G0Mmf+ltwDzhS2ZwF5o+4KQvQW6+w3E7R2JHl/fz8UEMZYmZPIblflcmIMYLaEzTsq",
-	"1dBYUIAOWPl5utcZPimHH19OTHBstbVMfGM4Jd7Y2x/uDPdNhFSxAfSmthROye+LWvNsDo6zkCtQmWAy",
-	"R5EmOAVlk07rWjbqyhZQzWVztzQeVUZh7T3eG1DHlJa9OxMcUs6k5aG9nZ1iV4Ap2+hLae7toz+kpb6q",
-	"VdqvnSftnreKlizQ9GS5jc8UNr0up7qFqlqfle8dbBQyP7r+/6cJ22oBOuR9hcOCnrQQP30VISZMgTBV",
-	"F4gFCARCcDHMu+2m02O3uOEhXpFGf/ASUDjECnt3+pXNvdOn+2mxXwlhXKx30rITluA/uFjbEO/47Xs9",
-	"7fPx3Bdn7OuMXX/4WJcsHj7mN1JWo3o6V/fSjvdcNQb6jbs1H9ymqIaMihswq7tP9LtexwKdMqhTnm7i",
-	"U1QI+Gz882Bn/ysI8ZqLGQlDYEMrw8FXkOGmuqEAYbeAusc2QYx4xsLh84Oylmf/eZotY7VT9ybnXIES",
-	"BBbQCEqNurFOQCXBfA4GGj0268tVX0r6eEbyNx/WOe7CdUrg/rf67r5g2O2y3rfGcl+fYRpe/uzpxY1a",
-	"eMCB0kUBa532/GOgHVUVYk/4XtVKyv8GHD8pjfkeUphnBJynRDtpqi2cX7v70mjqBZdvJfn+PhLvl6T3",
-	"qeD6DnPeL5Hu1qJmzzT3M4XGTjd7Q2R8htntS2bbV4jzgiO+kfjryltrwKs3cuRHgq85xwbMXTcGPu+A",
-	"2+isfvMBd/crCHHLcKZiLsjfED6D87ZvMF92t+rlBvj6XsqlcrWfASto3NTsdv+beLWvNGDwaYg17viK",
-	"h8vPFr2aGG02e3VUXXWIYvcLrr2hk2hvx4edzv1z6h2+kMTzI4l2Pm0x2XChLxnLR4/Nex4rSywUXPdV",
-	"T81z6fytdJNZ7MjPwyz+1qGtqyrrsocN6LUab0DvC3DYc6nrgSmilt/WGbPFQ19U+9uvPNgfNsp1f7lg",
-	"Y17+DKD4z8fnxk2fmvVe4vUL7Xy3tPMGVO9Mwv6cYlFQQuunU4MTyrOwe7nx+HKCrs1rjYuT49HI/BI/",
-	"5lKNj3aO7F/jyNd+dNygLG7j1P84QnWsVt7V0QzUtkNRQNXP+fP3qjPH1d3qPwEAAP//tY0if+VGAAA=",
+	"H4sIAAAAAAAC/+xcaXPjNtL+Kyi+b9UmtdRhy+P16pvH9mRU8dguH0m2Rq4URDZFZEiAAUDJikv/fQsA",
+	"b0ISPUfGM+tPlkgc3Y2nn+4GYD06HosTRoFK4YwfnQRzHIMErr+Fy3dzPvHVRx+Ex0kiCaPO2Lmj5M8U",
+	"EPGBShIQ4IgFCKMQc3+JOaAYUzwH3p9Sx3XgAcdJBM7YESyG3gKoz3gvYh7Wo7kOUUMmWIaO61Acq5b5",
+	"zK7D4c+UcPCdseQpuI7wQoixEkmuEj2o5ITOnfXadUQ6K6R8gtjVbk2RMT4a+cMZ7uFXAL2DYC/ozeDo",
+	"oBeMRgez/b29w0MvsKvQEGabJgHjMZbO2ElTolo2NVvnjfWqHF9NfgEutEpNDSfUjEUYRXjGUokwWpjG",
+	"SlcZAjq+mhglE84S4JKAHnVRDllqv9cf9ocWgYonbPYHeNJZuxWpRDexIiKkkimbWOyQDyekOn4h4/uK",
+	"6Jm863vXIRJi3fD/OQTO2Pm/QQn0QWbMQcWSpUqYc7xS31NOrjgE5KFuk0GO8l6G8gGhC6CS8dVgsdfN",
+	"WBcgl4x/mFAJPMAeKPt0sxo1PRHJu7YMFeEZRJY1ONfPc/CvCJ1rc3MWQW761uAuEqkXIiz0ax9LjBhH",
+	"M8ZkTYDC2A3V2zaNsXfs+xyERcDbENC74xOETYONQtX80zsczw7He6MxHo6H++OhZQFcJ2Y+RPYJ9auN",
+	"UyEPc7823/DhaHh0iIYPe6/+tW+by3i/bSr1pptSQJlVD5EA+PbBI0I/IP1+szKEonezRFSn2n81HA6L",
+	"mVTTOXCN2ZKt3ldX7d6C5ivOZhHEpyAxMchruK7vEyUqjo6l5GSWyubzq1r7lt51dY/pCtE0nmX0XQyC",
+	"cDG6qyDrQ0Ao+EptrEzjkYCYmKNBvEKYIqLMEAOV+nnfsWjna7XaVj9GYRpj2uOAfTyLAMFDEmFqJsin",
+	"Q5IhGRKBmOelnAP1Cggkxmr92sqfMErB00NIph1uhgUgSWK1sKm0oYJQITH1wCbi3fUEcQjAzCxDLMvo",
+	"Z5y6kHSzhFM6kSjGK7QiEPkoSLkMgSNSoScSIB+KiXxD4GVY48QKZ4lluoEI3t7eXiHTAHnMBxQw3sGS",
+	"xZSESqeNa9eRREZWS4mQcek211SkcYz5qjETUuP20USqXmnkI8ok8kJM54ACzuKqjJJtltidUnjwIJFa",
+	"uyTlCROgiU9lRxH5y6ASTQI9IyICzckCKMLUR0wvggwxRVNHh4fxLML0w9RxjaEKd0AixFGEcCQYmunJ",
+	"F8TPF2kDZe+CEvY8xn0dRBianN2+QddvTtDo30eH6P3o3oq0lvGIQEA9lnI8B990Ue3URJmMYkobC+Iz",
+	"Ly38NQNFOfQP0J/3USoInb+9fXf+I1qGQOvIRL+qR9pAMWgSIUKvX8JBAJXulBIp0AJHqTY4FiJVzie1",
+	"7RqWbmaLoZSJGA8GOSIrNux7LN7pEw3ezRyk4KAN5OuBEIx3zyGSvEs7yeJeSCR4MuUbgljRF9XaVo3w",
+	"cHTYOzywQctjHDb4u2QSRxVaT8KVIB6OkOlTGX+0b/PrGNM0wFoYviHSV1pU/LCwRKmASsuip+YRldH/",
+	"ISpm0n107G/P8cP1j+g3YFT9/YlFPjo8GI0uuqWQ1yBYyj040bRzwWQR3bqBgGf9c96i1RGauPAYVV7A",
+	"b3YUWMoSxnNyklFxVlk6H0FFY/W9Wh01goW1BnKdqoBnKuG+tRLVJS1YNmBRxJaKo7RMYoyGqIc8DliC",
+	"i/ZQTy0OCVYu2kc95EMEEoxD0zR2xu+H7p67f29DW1UWmx2OUdoqNSVDHDKSMfxTHQWBUqmbJTIQWK1v",
+	"VtMvl9c0rnF9CSLz6RoC+2B31+cmgSmGQbdK8Iwxc6pS0V+1sa6QaryPfjg9Oz+7PfvRGnMarNcw7qaV",
+	"v9/iFN25MLdTv82FfkzojcRyAxPq90RIjiVZgM5VCuTlo5ZYcu4uzi9Pfj47dVzn5u3d7e3k4qffTy9/",
+	"Vd5evLi7+PlCPbp3d+TATXneqiCJyiBZvmxKVE83b1hcb23MotFZ0aElzDxiMxwdCwHSBv9JiXrGkQBO",
+	"atxelcdV4MELTCIleV26B350OJQPHg38+b612JpzliaWiPIzrJaM+6oGUOChc2RaVnlvBhGjc4Ek6z+p",
+	"hA2XV5wFxGSRpbA87CXmeU+CkL0ZFsSzp+tZPbYhErbqNmFBVaftDesug0WjcsfgIyuyy8R0QmYkhJMk",
+	"IiZnakKpNNjj1Ezcw1NnjKaO5mj1xZ1SlL+bVd/Nps7azmMxxIyvtmUWRT5hmupamLy2lghborzZu6zE",
+	"dJvDFxpesSXwM38O6LdrheTu2wU3qhgxE+Qprt2Bd7uIWkZslmcLmVVa7WSys4vj1+ear04nN/nHbdSV",
+	"YC4vtPdvtapqtoElbIolyrpbVNLvdypzqQj48s0bu+B5FqedoJPP1dNxi7PlMuzgzXzZrz9y2fNprhiL",
+	"zFR1qmIs6m3pbji7w6JtJXfbyBLPtxO2ejxTlM048iIsRHWzMifvYs/jKcydCjyHAjE5Aian52eO6xyf",
+	"3E5+UR9e3938Zwegje5tLX4xNmG8Vg60k/9TiCI0oV5/Zy5UQUtrTauhqM7IGa0Uguac1ljXmmcWJFqD",
+	"vVtNgyxkUjPqtoxMy/zkrAwpnLZTs8+UCxWjf3pCZKfxhii2gGGRoYN7tr27M5Eg1SevwpoHdoVfPVki",
+	"QWRXSstP/rqYwk9HnX2kcIsM/FVBbNCsFrOdYElRcdJjOTNsVMw4imbY+2AnzyCNohX6M8WRMo2vt7wk",
+	"Q7gsk7UP+ikHtAyJFyIPU5T5JcLoigmZm29KN28FbNji61rOWxavEJAFpmQVSBe0fgp5qVgdVdeIIGS/",
+	"S10bkEjaws0JJ1LxlhYim9RYxWe6EKVQbNBxSBiXarOcoyWJIvXMjFvuRVTXDk1prQwXwBfEA1XoAoeA",
+	"8axCyQYpNwuz7Q2pdhPV7momF+alDBusL55u9apJ8xq8bEWEkkB5Vanj29yz32VH8ZYFUMR0SaNVfiC9",
+	"3c0KRLd9aa3LGkPuHqMSm92J7CD8Gnz0FksVK3hU2SRdLpd9Dn6Ipd4bbZ/zXE20AfSS0HlLpYo35hQg",
+	"nGKH32k1nxTNj68mOjg2jo11fKM4Ic7YGfWH/ZGOkDLUDr3t2Bcn5PdF5XB6DpbdmWuQKaci8yJFcBKK",
+	"Q3Claz5CeShVgWwGS42oIgor9Dg/gTyOouJsXAeHhFFheGh/OMxXBag0B+lJlKF98Icw1FdeReh2XC7M",
+	"mjeKltRT9GS4jc0k1qdvVnVzVZU+a9c52Cpktpn+z6cJ2ziUtMj7Gvs5PSkhXn0VIXRxrqsu4AvgCDhn",
+	"vJ/dZtFnT2aJawhx8jT6vRODxOqY0LlXXbbfTXg6TvP1igllfDNIi7O5GP/B+MYLJy3cvlPDPh/kvoCx",
+	"KxjbePhYSOYPH7MbX+tBNZ2rorSFnutaQ7d2d+293RRlk0E2n74s9Em467Qt0CqDWuXpNj5FuYDPBp8H",
+	"w9FXEOIN4zPi+0D7RoaDryDDbXlnAvx2AbXEJkEMWEr9/vNzZSXP6HmaLaWVc4A651yD5AQWUAtKtbqx",
+	"SkAFwXwOBho81uvLdVdK+nhGcrdv1lnumrZK4O63Zu+/YNhts963xnJfn2FqKH/29GL3WnjAnlRFAW3s",
+	"9vxtTlu87pxRXFdKyv8FP35SGvM9pDDPyHGeEu2ErrZwdhHwS3tTJ3f5VpLv7yPxfkl6n+pc32HO+yXS",
+	"3UrU7JjmfqbQ2DrN3hIZn2F2+5LZdhXiIueIbyT+2vLWiuNVD3LERzpffYwtPndTa/i8A25V1m8/4O59",
+	"BSHuKE5lyDj5C/xnsN/2DebL9qN6scV9XSdhQtqOnwFLqN0dbZ/+1/3VdKm5wad5rIbja+avPlv0qvvo",
+	"et2MqusWUex9wbm3nCSa+/p+6+T+OZ0dvpDE8yOJZj5tfLIGoS8ZyweP9Xsea0MsEdjuq57q5wLhncxi",
+	"Wn4eZnF3Nq2rsDF72OK9RuMt3vviOPS51PVAJZGrb2uP2fhDV692d195MP9qKTb9MsjWvPwZuOLfH59r",
+	"N30q1nuJ1y+0893SjroE0zWTWOtr94ucEhr/OtU7iVjqty83qss1N7pb7eLkeDDQvw0QMiHHR8Mj82s3",
+	"2dyPlhuU+W2c6s81lNtq+VvNQE075AVUdZ8/61fuOa7v1/8dABsDxXFFSgAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
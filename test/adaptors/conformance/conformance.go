@@ -0,0 +1,422 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+//nolint:all
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	adaptorinterface "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/adaptor-interface"
+	hwmgrpluginoranopenshiftiov1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	controllerutils "github.com/openshift-kni/oran-hwmgr-plugin/internal/controller/utils"
+	invserver "github.com/openshift-kni/oran-hwmgr-plugin/internal/server/api/generated"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const defaultMaxReconciles = 10
+
+// Fixture describes an adaptor under test, along with the fake inventory its NodePools are provisioned from.
+//
+// The adaptor is created from the adaptor registry, by the adaptorId of the HardwareManager, so the package of the
+// adaptor must be imported by the test suite. The adaptor is driven directly through the HwMgrAdaptorIntf, against a
+// fake client, without running the controllers set up by SetupAdaptor.
+type Fixture struct {
+	// HardwareManager is the HardwareManager of the adaptor under test
+	HardwareManager *hwmgrpluginoranopenshiftiov1alpha1.HardwareManager
+
+	// Objects seed the fake client, such as the inventory and hardware profiles used by the adaptor
+	Objects []client.Object
+
+	// NodePool is a NodePool that the inventory can satisfy. The inventory should hold no more free nodes in the
+	// resource pool of each node group than the NodePool requests, so that provisioning the NodePool again verifies
+	// that its nodes were released.
+	NodePool *hwmgmtv1alpha1.NodePool
+
+	// UpdatedHwProfile is the hardware profile the node groups of the NodePool are changed to by the spec change
+	UpdatedHwProfile string
+
+	// AddToScheme adds the types used by the adaptor beyond the core, plugin and hardware management types
+	AddToScheme func(s *runtime.Scheme) error
+
+	// Progress stands in for the controllers the adaptor depends on, such as the Metal3 operator, and is called
+	// before each reconcile of a NodePool
+	Progress func(ctx context.Context, c client.Client) error
+
+	// MaxReconciles bounds the reconciles allowed for a NodePool to reach each expected state. If not set, it
+	// defaults to 10.
+	MaxReconciles int
+}
+
+// harness holds the fake client and adaptor shared by the ordered specs of a conformance run
+type harness struct {
+	fixture Fixture
+	client  client.Client
+	adaptor adaptorinterface.HwMgrAdaptorIntf
+	hwmgr   *hwmgrpluginoranopenshiftiov1alpha1.HardwareManager
+}
+
+// DescribeAdaptor registers the conformance specs for the adaptor of a fixture, exercising the full lifecycle of a
+// NodePool and the inventory queries. The specs are ordered, as each builds on the state left by the previous one.
+func DescribeAdaptor(name string, fixture Fixture) bool {
+	return Describe(fmt.Sprintf("the %s adaptor conformance", name), Ordered, func() {
+		var (
+			h        *harness
+			nodepool *hwmgmtv1alpha1.NodePool
+			nodes    []hwmgmtv1alpha1.Node
+		)
+
+		ctx := context.Background()
+
+		BeforeAll(func() {
+			Expect(fixture.HardwareManager).NotTo(BeNil())
+			Expect(fixture.NodePool).NotTo(BeNil())
+			h = newHarness(fixture)
+		})
+
+		It("reports its resource pools and resources", func() {
+			pools, status, err := h.adaptor.GetResourcePools(ctx, h.hwmgr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(pools).NotTo(BeEmpty())
+
+			poolIds := make([]string, 0, len(pools))
+			for _, pool := range pools {
+				Expect(pool.ResourcePoolId).NotTo(BeEmpty())
+				poolIds = append(poolIds, pool.ResourcePoolId)
+			}
+
+			resources, status, err := h.adaptor.GetResources(ctx, h.hwmgr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(resources).NotTo(BeEmpty())
+			for _, resource := range resources {
+				Expect(resource.ResourceId).NotTo(BeEmpty())
+				Expect(poolIds).To(ContainElement(resource.ResourcePoolId),
+					"resource %s is in an unreported resource pool", resource.ResourceId)
+			}
+
+			for _, nodegroup := range fixture.NodePool.Spec.NodeGroup {
+				Expect(poolIds).To(ContainElement(nodegroup.NodePoolData.ResourcePoolId))
+			}
+		})
+
+		It("streams the same resources as it reports", func() {
+			resources, _, err := h.adaptor.GetResources(ctx, h.hwmgr)
+			Expect(err).NotTo(HaveOccurred())
+
+			var streamed []invserver.ResourceInfo
+			status, err := h.adaptor.StreamResources(ctx, h.hwmgr, func(resource invserver.ResourceInfo) error {
+				streamed = append(streamed, resource)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(streamed).To(ConsistOf(resources))
+
+			stop := errors.New("stop")
+			count := 0
+			_, err = h.adaptor.StreamResources(ctx, h.hwmgr, func(_ invserver.ResourceInfo) error {
+				count++
+				return stop
+			})
+			Expect(err).To(MatchError(stop))
+			Expect(count).To(Equal(1))
+		})
+
+		It("fails a NodePool that the inventory cannot satisfy", func() {
+			resources, _, err := h.adaptor.GetResources(ctx, h.hwmgr)
+			Expect(err).NotTo(HaveOccurred())
+
+			oversized := h.newNodePool("oversized")
+			for i := range oversized.Spec.NodeGroup {
+				oversized.Spec.NodeGroup[i].Size = len(resources) + 1
+			}
+			Expect(h.client.Create(ctx, oversized)).To(Succeed())
+
+			// The failure may be returned as an error as well as reported in the Provisioned condition
+			h.reconcileUntil(ctx, oversized, true, func(np *hwmgmtv1alpha1.NodePool) bool {
+				provisioned := meta.FindStatusCondition(np.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+				return provisioned != nil && provisioned.Reason != string(hwmgmtv1alpha1.InProgress)
+			})
+			provisioned := meta.FindStatusCondition(oversized.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+			Expect(provisioned.Status).To(Equal(metav1.ConditionFalse))
+			Expect(h.ownedNodes(ctx, oversized)).To(BeEmpty())
+
+			h.deleteNodePool(ctx, oversized)
+		})
+
+		It("provisions a NodePool", func() {
+			nodepool = h.newNodePool("")
+			Expect(h.client.Create(ctx, nodepool)).To(Succeed())
+
+			h.reconcileUntil(ctx, nodepool, false, isProvisioned)
+
+			nodes = h.ownedNodes(ctx, nodepool)
+			h.expectNodes(nodepool, nodes, "")
+			Expect(nodepool.Status.HwMgrPlugin.ObservedGeneration).To(Equal(nodepool.Generation))
+		})
+
+		It("applies the node metadata of the HardwareManager to new Nodes", func() {
+			metadata := h.hwmgr.Spec.NodeMetadata
+			if metadata == nil {
+				Skip("the HardwareManager of the fixture has no node metadata")
+			}
+
+			for _, node := range nodes {
+				for key, value := range metadata.Labels {
+					Expect(node.Labels).To(HaveKeyWithValue(key, value), "node %s", node.Name)
+				}
+				for key, value := range metadata.Annotations {
+					Expect(node.Annotations).To(HaveKeyWithValue(key, value), "node %s", node.Name)
+				}
+			}
+		})
+
+		It("leaves a provisioned NodePool unchanged", func() {
+			_, err := h.adaptor.HandleNodePool(ctx, h.hwmgr, nodepool)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(h.client.Get(ctx, client.ObjectKeyFromObject(nodepool), nodepool)).To(Succeed())
+			Expect(isProvisioned(nodepool)).To(BeTrue())
+			Expect(nodeNames(h.ownedNodes(ctx, nodepool))).To(Equal(nodeNames(nodes)))
+		})
+
+		It("applies a change of the NodePool spec", func() {
+			if fixture.UpdatedHwProfile == "" {
+				Skip("no updated hardware profile in the fixture")
+			}
+
+			Expect(h.client.Get(ctx, client.ObjectKeyFromObject(nodepool), nodepool)).To(Succeed())
+			for i := range nodepool.Spec.NodeGroup {
+				nodepool.Spec.NodeGroup[i].NodePoolData.HwProfile = fixture.UpdatedHwProfile
+			}
+			// The fake client does not manage the generation, so the spec change is recorded as the API server would
+			nodepool.Generation++
+			Expect(h.client.Update(ctx, nodepool)).To(Succeed())
+
+			h.reconcileUntil(ctx, nodepool, false, func(np *hwmgmtv1alpha1.NodePool) bool {
+				configured := meta.FindStatusCondition(np.Status.Conditions, string(hwmgmtv1alpha1.Configured))
+				return configured != nil && configured.Status == metav1.ConditionTrue &&
+					np.Status.HwMgrPlugin.ObservedGeneration == np.Generation
+			})
+
+			updated := h.ownedNodes(ctx, nodepool)
+			Expect(nodeNames(updated)).To(Equal(nodeNames(nodes)))
+			h.expectNodes(nodepool, updated, fixture.UpdatedHwProfile)
+			Expect(isProvisioned(nodepool)).To(BeTrue())
+		})
+
+		It("releases the hardware of a deleted NodePool", func() {
+			h.deleteNodePool(ctx, nodepool)
+
+			// The released nodes must be available to a new NodePool requesting the same resources
+			reprovisioned := h.newNodePool("again")
+			Expect(h.client.Create(ctx, reprovisioned)).To(Succeed())
+			h.reconcileUntil(ctx, reprovisioned, false, isProvisioned)
+			h.expectNodes(reprovisioned, h.ownedNodes(ctx, reprovisioned), "")
+
+			h.deleteNodePool(ctx, reprovisioned)
+		})
+	})
+}
+
+func newHarness(fixture Fixture) *harness {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(hwmgrpluginoranopenshiftiov1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(hwmgmtv1alpha1.AddToScheme(scheme)).To(Succeed())
+	if fixture.AddToScheme != nil {
+		Expect(fixture.AddToScheme(scheme)).To(Succeed())
+	}
+	Expect(controllerutils.InitNodepoolUtils(scheme)).To(Succeed())
+
+	if fixture.MaxReconciles == 0 {
+		fixture.MaxReconciles = defaultMaxReconciles
+	}
+
+	hwmgr := fixture.HardwareManager.DeepCopy()
+	objects := []client.Object{hwmgr}
+	for _, obj := range fixture.Objects {
+		objects = append(objects, obj.DeepCopyObject().(client.Object))
+	}
+
+	// Register the field indexes set up by the NodePool controller, which the adaptors list Nodes and NodePools by
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(&hwmgmtv1alpha1.Node{}, controllerutils.NodeSpecNodePoolKey, func(obj client.Object) []string {
+			return []string{obj.(*hwmgmtv1alpha1.Node).Spec.NodePool}
+		}).
+		WithIndex(&hwmgmtv1alpha1.NodePool{}, controllerutils.JobIdIndexKey, controllerutils.GetJobIds).
+		WithIndex(&hwmgmtv1alpha1.Node{}, controllerutils.JobIdIndexKey, controllerutils.GetJobIds).
+		WithStatusSubresource(&hwmgrpluginoranopenshiftiov1alpha1.HardwareManager{}, &hwmgmtv1alpha1.NodePool{},
+			&hwmgmtv1alpha1.Node{}).
+		Build()
+
+	factory, exists := adaptorinterface.GetAdaptorFactory(string(hwmgr.Spec.AdaptorID))
+	Expect(exists).To(BeTrue(), "adaptor %s is not registered", hwmgr.Spec.AdaptorID)
+
+	logger := slog.New(slog.NewJSONHandler(GinkgoWriter, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	return &harness{
+		fixture: fixture,
+		client:  c,
+		adaptor: factory(c, c, scheme, logger, hwmgr.Namespace),
+		hwmgr:   hwmgr,
+	}
+}
+
+// newNodePool returns a copy of the NodePool of the fixture, with the name and cloud ID suffixed to tell it apart
+// from the other NodePools of the run
+func (h *harness) newNodePool(suffix string) *hwmgmtv1alpha1.NodePool {
+	nodepool := h.fixture.NodePool.DeepCopy()
+	if suffix != "" {
+		nodepool.Name += "-" + suffix
+		nodepool.Spec.CloudID += "-" + suffix
+	}
+	nodepool.Namespace = h.hwmgr.Namespace
+	nodepool.Spec.HwMgrId = h.hwmgr.Name
+	nodepool.Generation = 1
+
+	// The fake client does not assign UIDs, which the Nodes reference as their owner
+	nodepool.UID = types.UID(fmt.Sprintf("conformance-%s", nodepool.Name))
+	return nodepool
+}
+
+// reconcileUntil calls HandleNodePool until the NodePool reaches the expected state, failing if it does not within
+// the allowed reconciles. The latest NodePool is left in nodepool.
+func (h *harness) reconcileUntil(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool, allowErrors bool,
+	done func(*hwmgmtv1alpha1.NodePool) bool) {
+
+	GinkgoHelper()
+
+	key := client.ObjectKeyFromObject(nodepool)
+	for i := 0; i < h.fixture.MaxReconciles; i++ {
+		Expect(h.client.Get(ctx, key, nodepool)).To(Succeed())
+		if done(nodepool) {
+			return
+		}
+
+		if h.fixture.Progress != nil {
+			Expect(h.fixture.Progress(ctx, h.client)).To(Succeed())
+		}
+
+		_, err := h.adaptor.HandleNodePool(ctx, h.hwmgr, nodepool)
+		if !allowErrors {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+
+	Expect(h.client.Get(ctx, key, nodepool)).To(Succeed())
+	Expect(done(nodepool)).To(BeTrue(), "NodePool %s did not reach the expected state in %d reconciles: %v",
+		nodepool.Name, h.fixture.MaxReconciles, nodepool.Status.Conditions)
+}
+
+// deleteNodePool finalizes a NodePool through the adaptor and deletes it, along with the Nodes it owns, as the
+// garbage collector would
+func (h *harness) deleteNodePool(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) {
+	GinkgoHelper()
+
+	Expect(h.client.Get(ctx, client.ObjectKeyFromObject(nodepool), nodepool)).To(Succeed())
+
+	completed := false
+	for i := 0; i < h.fixture.MaxReconciles && !completed; i++ {
+		if h.fixture.Progress != nil {
+			Expect(h.fixture.Progress(ctx, h.client)).To(Succeed())
+		}
+
+		var err error
+		completed, err = h.adaptor.HandleNodePoolDeletion(ctx, h.hwmgr, nodepool)
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(completed).To(BeTrue(), "deletion of NodePool %s did not complete in %d reconciles",
+		nodepool.Name, h.fixture.MaxReconciles)
+
+	for _, node := range h.ownedNodes(ctx, nodepool) {
+		Expect(h.client.Delete(ctx, &node)).To(Succeed())
+	}
+	Expect(h.client.Delete(ctx, nodepool)).To(Succeed())
+}
+
+// ownedNodes returns the Nodes owned by a NodePool
+func (h *harness) ownedNodes(ctx context.Context, nodepool *hwmgmtv1alpha1.NodePool) []hwmgmtv1alpha1.Node {
+	GinkgoHelper()
+
+	nodelist := &hwmgmtv1alpha1.NodeList{}
+	Expect(h.client.List(ctx, nodelist)).To(Succeed())
+
+	var nodes []hwmgmtv1alpha1.Node
+	for _, node := range nodelist.Items {
+		for _, ref := range node.OwnerReferences {
+			if ref.UID == nodepool.UID {
+				nodes = append(nodes, node)
+				break
+			}
+		}
+	}
+	return nodes
+}
+
+// expectNodes checks that the Nodes of a NodePool match its node groups. If hwProfile is set, each Node must also
+// have completed its update to that hardware profile.
+func (h *harness) expectNodes(nodepool *hwmgmtv1alpha1.NodePool, nodes []hwmgmtv1alpha1.Node, hwProfile string) {
+	GinkgoHelper()
+
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		count := 0
+		for _, node := range nodes {
+			if node.Spec.GroupName != nodegroup.NodePoolData.Name {
+				continue
+			}
+			count++
+			Expect(node.Spec.HwMgrId).To(Equal(h.hwmgr.Name))
+			Expect(node.Spec.HwProfile).To(Equal(nodegroup.NodePoolData.HwProfile))
+			if hwProfile != "" {
+				Expect(node.Status.HwProfile).To(Equal(hwProfile))
+			}
+		}
+		Expect(count).To(Equal(nodegroup.Size), "unexpected number of nodes in node group %s",
+			nodegroup.NodePoolData.Name)
+	}
+	Expect(nodes).To(HaveLen(totalSize(nodepool)))
+	Expect(nodepool.Status.Properties.NodeNames).To(ConsistOf(nodeNames(nodes)))
+}
+
+func isProvisioned(nodepool *hwmgmtv1alpha1.NodePool) bool {
+	return meta.IsStatusConditionTrue(nodepool.Status.Conditions, string(hwmgmtv1alpha1.Provisioned))
+}
+
+func totalSize(nodepool *hwmgmtv1alpha1.NodePool) int {
+	size := 0
+	for _, nodegroup := range nodepool.Spec.NodeGroup {
+		size += nodegroup.Size
+	}
+	return size
+}
+
+func nodeNames(nodes []hwmgmtv1alpha1.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	slices.Sort(names)
+	return names
+}
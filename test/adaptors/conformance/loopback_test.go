@@ -0,0 +1,65 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+//nolint:all
+package conformance_test
+
+import (
+	_ "github.com/openshift-kni/oran-hwmgr-plugin/adaptors/loopback"
+	hwmgrpluginoranopenshiftiov1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/test/adaptors/conformance"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The loopback adaptor generates a single node from its scale settings, which the NodePool takes, so that no
+// nodelist configmap is needed
+var _ = conformance.DescribeAdaptor("loopback", conformance.Fixture{
+	HardwareManager: &hwmgrpluginoranopenshiftiov1alpha1.HardwareManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loopback-conformance",
+			Namespace: "default",
+		},
+		Spec: hwmgrpluginoranopenshiftiov1alpha1.HardwareManagerSpec{
+			AdaptorID: hwmgrpluginoranopenshiftiov1alpha1.SupportedAdaptors.Loopback,
+			LoopbackData: &hwmgrpluginoranopenshiftiov1alpha1.LoopbackData{
+				Scale: &hwmgrpluginoranopenshiftiov1alpha1.LoopbackScale{
+					NumPools:     1,
+					NodesPerPool: 1,
+					PoolPrefix:   "conformance-pool",
+				},
+			},
+			NodeMetadata: &hwmgrpluginoranopenshiftiov1alpha1.NodeMetadata{
+				Labels:      map[string]string{"environment": "conformance"},
+				Annotations: map[string]string{"example.com/owner": "conformance"},
+			},
+		},
+	},
+	NodePool: &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "np-conformance",
+		},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID: "np-conformance",
+			LocationSpec: hwmgmtv1alpha1.LocationSpec{
+				Site:     "building-1",
+				Location: "ottawa",
+			},
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{
+					NodePoolData: hwmgmtv1alpha1.NodePoolData{
+						Name:           "controller",
+						Role:           "master",
+						HwProfile:      "profile-spr-single-processor-64G",
+						ResourcePoolId: "conformance-pool-0",
+					},
+					Size: 1,
+				},
+			},
+		},
+	},
+	UpdatedHwProfile: "profile-spr-dual-processor-128G",
+})
@@ -0,0 +1,23 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+//nolint:all
+package conformance_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// These tests use Ginkgo: http://onsi.github.io/ginkgo/
+
+func TestAdaptorConformance(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "The adaptor conformance test suite")
+}
@@ -0,0 +1,734 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dellserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	apiserver "github.com/openshift-kni/oran-hwmgr-plugin/test/adaptors/dell-hwmgr/dell-server/generated"
+)
+
+const (
+	// FakeToken is the access token issued by a FakeServer, unless overridden
+	FakeToken = "fake-dell-token"
+
+	jobStatusStarted   = "started"
+	jobStatusCompleted = "completed"
+	jobStatusFailed    = "failed"
+)
+
+// Operations of the hardware manager API, as named by the server interface, for injecting failures and counting
+// requests
+const (
+	OpGetToken            = "GetToken"
+	OpVerifyRequestStatus = "VerifyRequestStatus"
+	OpGetResourceGroups   = "GetResourceGroups"
+	OpCreateResourceGroup = "CreateResourceGroup"
+	OpDeleteResourceGroup = "DeleteResourceGroup"
+	OpGetResourceGroup    = "GetResourceGroup"
+	OpGetResourcePools    = "GetResourcePools"
+	OpGetResourcePool     = "GetResourcePool"
+	OpGetResources        = "GetResources"
+	OpGetResource         = "GetResource"
+	OpUpdateResource      = "UpdateResource"
+)
+
+// Failure is an error response returned for the requests of an operation
+type Failure struct {
+	StatusCode int
+	Message    string
+
+	// Count is the number of requests that fail, after which the operation succeeds again. If zero, all requests
+	// fail until the failures are cleared.
+	Count int
+}
+
+// fakeResourceGroup is a resource group, along with the resources allocated to each of its resource selectors
+type fakeResourceGroup struct {
+	request   apiserver.RhprotoResourceGroupObjectRequest
+	resources map[string][]string
+}
+
+// fakeTenant holds the inventory and resource groups of a tenant
+type fakeTenant struct {
+	pools     map[string]apiserver.ApiprotoResourcePool
+	resources map[string]apiserver.ApiprotoResource
+	groups    map[string]*fakeResourceGroup
+}
+
+// fakeJob is an asynchronous request, which stays in progress until its due time. Its effect is applied when it
+// completes, unless it is set to fail.
+type fakeJob struct {
+	started    time.Time
+	due        time.Time
+	status     string
+	failReason string
+	apply      func() string
+}
+
+// FakeServer is an in-process fake of the Dell hardware manager API, serving the token endpoint, resource pools,
+// resources, resource groups and jobs of each tenant from memory. Resource groups are created and deleted, and
+// resources updated, by jobs that complete after JobDuration, so the job polling of the adaptor is exercised as it
+// would be against a real hardware manager. Endpoints that are not faked respond as the DellServer does.
+type FakeServer struct {
+	DellServer
+
+	// Token is the access token issued by the token endpoint, and required by the other endpoints
+	Token string
+
+	// Latency delays each response
+	Latency time.Duration
+
+	// JobDuration is how long each job stays in progress
+	JobDuration time.Duration
+
+	lock        sync.Mutex
+	server      *httptest.Server
+	tenants     map[string]*fakeTenant
+	jobs        map[string]*fakeJob
+	nextJobId   int
+	failures    map[string]*Failure
+	jobFailures map[string][]string
+	requests    map[string]int
+}
+
+// NewFakeServer creates a FakeServer with an empty inventory
+func NewFakeServer() *FakeServer {
+	return &FakeServer{
+		Token:       FakeToken,
+		tenants:     make(map[string]*fakeTenant),
+		jobs:        make(map[string]*fakeJob),
+		failures:    make(map[string]*Failure),
+		jobFailures: make(map[string][]string),
+		requests:    make(map[string]int),
+	}
+}
+
+// Start serves the fake API on a local port, returning the URL to use as the apiUrl of a HardwareManager
+func (s *FakeServer) Start() string {
+	s.server = httptest.NewServer(apiserver.Handler(s))
+	return s.server.URL
+}
+
+// Close stops serving the fake API
+func (s *FakeServer) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+func (s *FakeServer) tenant(name string) *fakeTenant {
+	t, exists := s.tenants[name]
+	if !exists {
+		t = &fakeTenant{
+			pools:     make(map[string]apiserver.ApiprotoResourcePool),
+			resources: make(map[string]apiserver.ApiprotoResource),
+			groups:    make(map[string]*fakeResourceGroup),
+		}
+		s.tenants[name] = t
+	}
+	return t
+}
+
+// AddResourcePool adds a resource pool to the inventory of a tenant
+func (s *FakeServer) AddResourcePool(tenant string, pool apiserver.ApiprotoResourcePool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tenant(tenant).pools[*pool.Id] = pool
+}
+
+// AddResource adds a resource to the inventory of a tenant. Resources are allocated to resource groups from the
+// resource pool given by their ResourcePoolId, and matched against the labels of the resource selectors.
+func (s *FakeServer) AddResource(tenant string, resource apiserver.ApiprotoResource) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tenant(tenant).resources[*resource.Id] = resource
+}
+
+// AddResourceGroup adds a resource group to a tenant directly, without allocating any resources, such as to stand in
+// for a resource group left behind by a NodePool
+func (s *FakeServer) AddResourceGroup(tenant string, rg apiserver.RhprotoResourceGroupObjectRequest) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tenant(tenant).groups[*rg.Id] = &fakeResourceGroup{request: rg, resources: make(map[string][]string)}
+}
+
+// ResourceGroup returns a resource group of a tenant, as served by the API
+func (s *FakeServer) ResourceGroup(tenant, rgId string) (apiserver.RhprotoResourceGroupObjectGetResponseBody, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.advanceJobs()
+	t := s.tenant(tenant)
+	rg, exists := t.groups[rgId]
+	if !exists {
+		return apiserver.RhprotoResourceGroupObjectGetResponseBody{}, false
+	}
+	return t.renderResourceGroup(rg), true
+}
+
+// Resource returns a resource of a tenant
+func (s *FakeServer) Resource(tenant, resourceId string) (apiserver.ApiprotoResource, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.advanceJobs()
+	resource, exists := s.tenant(tenant).resources[resourceId]
+	return resource, exists
+}
+
+// FailRequests makes the requests of an operation fail with the given response
+func (s *FakeServer) FailRequests(operation string, failure Failure) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failures[operation] = &failure
+}
+
+// FailNextJob makes the next job started by an operation fail with the given reason
+func (s *FakeServer) FailNextJob(operation, reason string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.jobFailures[operation] = append(s.jobFailures[operation], reason)
+}
+
+// ClearFailures removes all injected request and job failures
+func (s *FakeServer) ClearFailures() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failures = make(map[string]*Failure)
+	s.jobFailures = make(map[string][]string)
+}
+
+// ExpireJob forgets a job, as the hardware manager does some time after the job has finished
+func (s *FakeServer) ExpireJob(jobId string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.jobs, jobId)
+}
+
+// Requests returns the number of requests received for an operation, including failed requests
+func (s *FakeServer) Requests(operation string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.requests[operation]
+}
+
+// begin counts a request, applies the latency, and checks its authorization and any injected failure, returning
+// false if an error response has been written
+func (s *FakeServer) begin(w http.ResponseWriter, r *http.Request, operation string) bool {
+	s.lock.Lock()
+	s.requests[operation]++
+	latency := s.Latency
+	s.lock.Unlock()
+
+	time.Sleep(latency)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if operation != OpGetToken && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+		return false
+	}
+
+	if failure, exists := s.failures[operation]; exists {
+		if failure.Count > 0 {
+			failure.Count--
+			if failure.Count == 0 {
+				delete(s.failures, operation)
+			}
+		}
+		writeError(w, failure.StatusCode, failure.Message)
+		return false
+	}
+
+	s.advanceJobs()
+	return true
+}
+
+// startJob records a job for an operation, returning its ID
+func (s *FakeServer) startJob(operation string, apply func() string) string {
+	s.nextJobId++
+	jobId := fmt.Sprintf("fake-job-%d", s.nextJobId)
+
+	now := time.Now()
+	job := &fakeJob{started: now, due: now.Add(s.JobDuration), status: jobStatusStarted, apply: apply}
+	if reasons := s.jobFailures[operation]; len(reasons) > 0 {
+		job.failReason = reasons[0]
+		s.jobFailures[operation] = reasons[1:]
+	}
+	s.jobs[jobId] = job
+
+	// A job without a duration completes immediately, so its effect is visible to the next request
+	s.advanceJobs()
+	return jobId
+}
+
+// advanceJobs finishes the jobs that are due, applying their effects in the order they were started
+func (s *FakeServer) advanceJobs() {
+	var due []string
+	for jobId, job := range s.jobs {
+		if job.status == jobStatusStarted && !time.Now().Before(job.due) {
+			due = append(due, jobId)
+		}
+	}
+	slices.SortFunc(due, func(a, b string) int {
+		return s.jobs[a].started.Compare(s.jobs[b].started)
+	})
+
+	for _, jobId := range due {
+		job := s.jobs[jobId]
+		if job.failReason == "" {
+			job.failReason = job.apply()
+		}
+		if job.failReason != "" {
+			job.status = jobStatusFailed
+		} else {
+			job.status = jobStatusCompleted
+		}
+	}
+}
+
+// writeError writes an error response in the format returned by the hardware manager
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, hwmgrclient.RespDefault{
+		Code:    status,
+		Message: message,
+		Details: []hwmgrclient.RespDefaultDetails{
+			{
+				Type:   "type.googleapis.com/google.rpc.ErrorInfo",
+				Reason: message,
+				Domain: "fake-dell-hwmgr",
+				Metadata: hwmgrclient.RespDefaultDetailsMetadata{
+					HTTPErrorCode: strconv.Itoa(status),
+				},
+			},
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// convert copies between the API types that share a JSON representation
+func convert[T any](from any) T {
+	var to T
+	data, _ := json.Marshal(from)
+	_ = json.Unmarshal(data, &to)
+	return to
+}
+
+// toRhprotoResource converts an inventory resource to the representation used in resource groups, which differs only
+// in its deployment field
+func toRhprotoResource(resource apiserver.ApiprotoResource) apiserver.RhprotoResource {
+	resource.Deployment = nil
+	return convert[apiserver.RhprotoResource](resource)
+}
+
+// renderResourceGroup builds the response for a resource group, with the current state of its allocated resources
+func (t *fakeTenant) renderResourceGroup(rg *fakeResourceGroup) apiserver.RhprotoResourceGroupObjectGetResponseBody {
+	selectors := make(map[string]apiserver.RhprotoResourceSelectorGetResponse)
+	if rg.request.ResourceSelectors != nil {
+		for name, request := range *rg.request.ResourceSelectors {
+			selector := convert[apiserver.RhprotoResourceSelectorGetResponse](request)
+			resources := []apiserver.RhprotoResource{}
+			for _, resourceId := range rg.resources[name] {
+				if resource, exists := t.resources[resourceId]; exists {
+					resources = append(resources, toRhprotoResource(resource))
+				}
+			}
+			selector.Resources = &resources
+			selectors[name] = selector
+		}
+	}
+
+	return apiserver.RhprotoResourceGroupObjectGetResponseBody{
+		Description:       rg.request.Description,
+		Id:                rg.request.Id,
+		Name:              rg.request.Name,
+		ResourceTypeId:    rg.request.ResourceTypeId,
+		ResourceSelectors: &selectors,
+	}
+}
+
+// allocated returns the resources allocated to any resource group of the tenant
+func (t *fakeTenant) allocated() map[string]bool {
+	allocated := make(map[string]bool)
+	for _, rg := range t.groups {
+		for _, resourceIds := range rg.resources {
+			for _, resourceId := range resourceIds {
+				allocated[resourceId] = true
+			}
+		}
+	}
+	return allocated
+}
+
+// matchesSelector checks whether a resource is in the resource pool of a selector, and has all of its labels
+func matchesSelector(resource apiserver.ApiprotoResource, selector apiserver.RhprotoResourceSelectorRequest) bool {
+	if selector.RpId != nil && *selector.RpId != "" &&
+		(resource.ResourcePoolId == nil || *resource.ResourcePoolId != *selector.RpId) {
+		return false
+	}
+
+	if selector.Filters == nil || selector.Filters.Include == nil || selector.Filters.Include.Labels == nil {
+		return true
+	}
+	for _, include := range *selector.Filters.Include.Labels {
+		if include.Key == nil || include.Value == nil {
+			continue
+		}
+		found := false
+		if resource.Labels != nil {
+			for _, label := range *resource.Labels {
+				if label.Key != nil && label.Value != nil && *label.Key == *include.Key && *label.Value == *include.Value {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// allocateResourceGroup allocates free resources to each resource selector of a new resource group, setting their
+// resource profile, and adds the resource group to the tenant. A reason is returned if the request cannot be
+// satisfied, in which case nothing is allocated.
+func (t *fakeTenant) allocateResourceGroup(request apiserver.RhprotoResourceGroupObjectRequest) string {
+	if _, exists := t.groups[*request.Id]; exists {
+		return fmt.Sprintf("resource group %s already exists", *request.Id)
+	}
+
+	rg := &fakeResourceGroup{request: request, resources: make(map[string][]string)}
+	allocated := t.allocated()
+
+	// Resources are allocated in order of their IDs, so allocations are repeatable
+	resourceIds := make([]string, 0, len(t.resources))
+	for resourceId := range t.resources {
+		resourceIds = append(resourceIds, resourceId)
+	}
+	slices.Sort(resourceIds)
+
+	if request.ResourceSelectors != nil {
+		for name, selector := range *request.ResourceSelectors {
+			if selector.NumResources == nil || *selector.NumResources == 0 {
+				continue
+			}
+
+			for _, resourceId := range resourceIds {
+				if len(rg.resources[name]) == *selector.NumResources {
+					break
+				}
+				if !allocated[resourceId] && matchesSelector(t.resources[resourceId], selector) {
+					rg.resources[name] = append(rg.resources[name], resourceId)
+					allocated[resourceId] = true
+				}
+			}
+
+			if len(rg.resources[name]) < *selector.NumResources {
+				return fmt.Sprintf("insufficient free resources for resource selector %s: requested %d, found %d",
+					name, *selector.NumResources, len(rg.resources[name]))
+			}
+		}
+	}
+
+	for name, resourceIds := range rg.resources {
+		profile := (*request.ResourceSelectors)[name].ResourceProfileId
+		for _, resourceId := range resourceIds {
+			resource := t.resources[resourceId]
+			resource.ResourceProfileID = profile
+			t.resources[resourceId] = resource
+		}
+	}
+
+	t.groups[*request.Id] = rg
+	return ""
+}
+
+func (s *FakeServer) GetToken(w http.ResponseWriter, r *http.Request) {
+	if !s.begin(w, r, OpGetToken) {
+		return
+	}
+
+	tokenType := "Bearer"
+	expiresIn := int64(300)
+	writeJSON(w, http.StatusOK, apiserver.RhprotoGetTokenResponseBody{
+		AccessToken: &s.Token,
+		ExpiresIn:   &expiresIn,
+		TokenType:   &tokenType,
+	})
+}
+
+func (s *FakeServer) VerifyRequestStatus(w http.ResponseWriter, r *http.Request, tenant, jobid string) {
+	if !s.begin(w, r, OpVerifyRequestStatus) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	job, exists := s.jobs[jobid]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("job %s not found", jobid))
+		return
+	}
+
+	startTime := job.started.Format(time.RFC3339)
+	brief := apiserver.RhprotoJobStatusBrief{Id: &jobid, StartTime: &startTime, Status: &job.status}
+	if job.status == jobStatusFailed {
+		brief.FailReason = &job.failReason
+	}
+	writeJSON(w, http.StatusOK, apiserver.RhprotoJobStatus{Brief: &brief})
+}
+
+func (s *FakeServer) GetResourceGroups(w http.ResponseWriter, r *http.Request, tenant string, params apiserver.GetResourceGroupsParams) {
+	if !s.begin(w, r, OpGetResourceGroups) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	rgIds := make([]string, 0, len(t.groups))
+	for rgId := range t.groups {
+		rgIds = append(rgIds, rgId)
+	}
+	slices.Sort(rgIds)
+
+	groups := make([]apiserver.RhprotoResourceGroupObjectGetResponseBody, 0, len(rgIds))
+	for _, rgId := range rgIds {
+		groups = append(groups, t.renderResourceGroup(t.groups[rgId]))
+	}
+	writeJSON(w, http.StatusOK, apiserver.RhprotoResourceGroupsResp{ResourceGroups: &groups})
+}
+
+func (s *FakeServer) CreateResourceGroup(w http.ResponseWriter, r *http.Request, tenant string) {
+	if !s.begin(w, r, OpCreateResourceGroup) {
+		return
+	}
+
+	var body apiserver.CreateResourceGroupJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ResourceGroup == nil ||
+		body.ResourceGroup.Id == nil || *body.ResourceGroup.Id == "" {
+		writeError(w, http.StatusBadRequest, "invalid resource group request")
+		return
+	}
+	request := *body.ResourceGroup
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	if _, exists := t.groups[*request.Id]; exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("resource group %s already exists", *request.Id))
+		return
+	}
+
+	jobId := s.startJob(OpCreateResourceGroup, func() string {
+		return t.allocateResourceGroup(request)
+	})
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoResponse{Id: request.Id, Jobid: &jobId})
+}
+
+func (s *FakeServer) DeleteResourceGroup(w http.ResponseWriter, r *http.Request, tenant, resourceGroupId string) {
+	if !s.begin(w, r, OpDeleteResourceGroup) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	if _, exists := t.groups[resourceGroupId]; !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("resource group %s not found", resourceGroupId))
+		return
+	}
+
+	// The resource profiles of the released resources are left as they are, as on the hardware manager
+	jobId := s.startJob(OpDeleteResourceGroup, func() string {
+		delete(t.groups, resourceGroupId)
+		return ""
+	})
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoResponse{Id: &resourceGroupId, Jobid: &jobId})
+}
+
+func (s *FakeServer) GetResourceGroup(w http.ResponseWriter, r *http.Request, tenant, resourceGroupId string) {
+	if !s.begin(w, r, OpGetResourceGroup) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	rg, exists := t.groups[resourceGroupId]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("resource group %s not found", resourceGroupId))
+		return
+	}
+	writeJSON(w, http.StatusOK, t.renderResourceGroup(rg))
+}
+
+func (s *FakeServer) GetResourcePools(w http.ResponseWriter, r *http.Request, tenant string) {
+	if !s.begin(w, r, OpGetResourcePools) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	pools := make([]apiserver.ApiprotoResourcePool, 0, len(t.pools))
+	for _, pool := range t.pools {
+		pools = append(pools, pool)
+	}
+	slices.SortFunc(pools, func(a, b apiserver.ApiprotoResourcePool) int {
+		return compareIds(a.Id, b.Id)
+	})
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoResourcePoolsResp{ResourcePools: &pools})
+}
+
+func (s *FakeServer) GetResourcePool(w http.ResponseWriter, r *http.Request, tenant, id string) {
+	if !s.begin(w, r, OpGetResourcePool) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pool, exists := s.tenant(tenant).pools[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("resource pool %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoResourcePoolResp{ResourcePool: &pool})
+}
+
+func (s *FakeServer) GetResources(w http.ResponseWriter, r *http.Request, tenant string) {
+	if !s.begin(w, r, OpGetResources) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	resources := make([]apiserver.ApiprotoResource, 0, len(t.resources))
+	for _, resource := range t.resources {
+		resources = append(resources, resource)
+	}
+	slices.SortFunc(resources, func(a, b apiserver.ApiprotoResource) int {
+		return compareIds(a.Id, b.Id)
+	})
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoGetResourcesResp{Resources: &resources, Tenant: &tenant})
+}
+
+func (s *FakeServer) GetResource(w http.ResponseWriter, r *http.Request, tenant, id string) {
+	if !s.begin(w, r, OpGetResource) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	resource, exists := s.tenant(tenant).resources[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("resource %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoGetResourceResp{Resource: &resource, Tenant: &tenant})
+}
+
+func (s *FakeServer) UpdateResource(w http.ResponseWriter, r *http.Request, tenant string) {
+	if !s.begin(w, r, OpUpdateResource) {
+		return
+	}
+
+	var body apiserver.UpdateResourceJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ResourceName == nil {
+		writeError(w, http.StatusBadRequest, "invalid resource update request")
+		return
+	}
+	resourceId := *body.ResourceName
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t := s.tenant(tenant)
+	resource, exists := t.resources[resourceId]
+	if !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("resource %s not found", resourceId))
+		return
+	}
+
+	// Of the updates made by the adaptor, only a change of resource profile is reflected in the resource. Power state
+	// and BMC credential changes complete without effect.
+	var updates []apiserver.ApiprotoUpdateResource
+	if body.Resource != nil {
+		updates = *body.Resource
+	}
+	jobId := s.startJob(OpUpdateResource, func() string {
+		for _, update := range updates {
+			if update.Path == nil || *update.Path != "/Resource/ResourceProfileID" || update.Value == nil {
+				continue
+			}
+			for _, value := range *update.Value {
+				if profile, ok := value["resourceProfileID"].(string); ok {
+					resource := t.resources[resourceId]
+					resource.ResourceProfileID = &profile
+					t.resources[resourceId] = resource
+				}
+			}
+		}
+		return ""
+	})
+	writeJSON(w, http.StatusOK, apiserver.ApiprotoUpdateResourceResp{
+		Resource: &resource,
+		Response: &apiserver.ApiprotoResponse{Id: &resourceId, Jobid: &jobId},
+		Tenant:   &tenant,
+	})
+}
+
+func compareIds(a, b *string) int {
+	var x, y string
+	if a != nil {
+		x = *a
+	}
+	if b != nil {
+		y = *b
+	}
+	if x < y {
+		return -1
+	}
+	if x > y {
+		return 1
+	}
+	return 0
+}
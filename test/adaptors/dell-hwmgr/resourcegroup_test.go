@@ -0,0 +1,175 @@
+/*
+SPDX-FileCopyrightText: Red Hat
+
+SPDX-License-Identifier: Apache-2.0
+*/
+//nolint:all
+package dellhwmgr
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/openshift-kni/oran-hwmgr-plugin/adaptors/dell-hwmgr/hwmgrclient"
+	hwmgrpluginoranopenshiftiov1alpha1 "github.com/openshift-kni/oran-hwmgr-plugin/api/hwmgr-plugin/v1alpha1"
+	"github.com/openshift-kni/oran-hwmgr-plugin/test/adaptors/assets"
+	dellserver "github.com/openshift-kni/oran-hwmgr-plugin/test/adaptors/dell-hwmgr/dell-server"
+	apiserver "github.com/openshift-kni/oran-hwmgr-plugin/test/adaptors/dell-hwmgr/dell-server/generated"
+	hwmgmtv1alpha1 "github.com/openshift-kni/oran-o2ims/api/hardwaremanagement/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("manage resource groups", func() {
+	When("managing resource groups with the fake hardware manager", func() {
+
+		var (
+			fake   *dellserver.FakeServer
+			hwmgr  *hwmgrpluginoranopenshiftiov1alpha1.HardwareManager
+			secret *corev1.Secret
+			hmc    *hwmgrclient.HardwareManagerClient
+		)
+
+		ctx := context.Background()
+
+		BeforeEach(func() {
+
+			var err error
+
+			// start the fake hardware manager, with two master resources
+			fake = dellserver.NewFakeServer()
+			fake.JobDuration = 500 * time.Millisecond
+			url := fake.Start()
+
+			tenant := hwmgrclient.DefaultTenant
+			fake.AddResourcePool(tenant, apiserver.ApiprotoResourcePool{Id: ptr("pool-1"), Name: ptr("pool-1")})
+			for _, id := range []string{"server-1", "server-2"} {
+				fake.AddResource(tenant, apiserver.ApiprotoResource{
+					Id:             ptr(id),
+					Name:           ptr(id),
+					ResourcePoolId: ptr("pool-1"),
+					Labels:         &[]apiserver.ApiprotoLabel{{Key: ptr("role"), Value: ptr("master")}},
+				})
+			}
+
+			// create the HardwareManager cr instance
+			hwmgr, err = assets.GetHardwareManagerFromTmpl(url, "manifests/dell-hwmgr.tmpl")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, hwmgr)).To(Succeed())
+
+			// create the Dell secret
+			secret, err = assets.GetSecretFromFile("manifests/dell-secret.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			hmc, err = hwmgrclient.NewClientWithResponses(ctx, logger, k8sClient, hwmgr)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			fake.Close()
+
+			// delete the HardwareManager cr instance
+			Expect(k8sClient.Delete(ctx, hwmgr)).To(Succeed())
+
+			// delete the secret
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		})
+
+		It("must create and delete a resource group through its jobs", func() {
+			nodepool := newNodePool("np-1", "cloud-1", 2)
+
+			By("creating the resource group")
+			jobId, err := hmc.CreateResourceGroup(ctx, nodepool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jobStatus(ctx, hmc, jobId)).To(Equal(hwmgrclient.JobStatusInProgress))
+			Eventually(func() hwmgrclient.JobStatus { return jobStatus(ctx, hmc, jobId) }, 5*time.Second).
+				Should(Equal(hwmgrclient.JobStatusCompleted))
+
+			rg, err := hmc.GetResourceGroupFromNodePool(ctx, nodepool)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*(*rg.ResourceSelectors)["master"].Resources).To(HaveLen(2))
+
+			resource, exists := fake.Resource(hwmgrclient.DefaultTenant, "server-1")
+			Expect(exists).To(BeTrue())
+			Expect(*resource.ResourceProfileID).To(Equal("profile-1"))
+
+			By("deleting the resource group")
+			jobId, err = hmc.DeleteResourceGroup(ctx, nodepool)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() hwmgrclient.JobStatus { return jobStatus(ctx, hmc, jobId) }, 5*time.Second).
+				Should(Equal(hwmgrclient.JobStatusCompleted))
+			Expect(hmc.ResourceGroupExists(ctx, nodepool)).To(BeFalse())
+		})
+
+		It("must fail the job of a resource group that the inventory cannot satisfy", func() {
+			nodepool := newNodePool("np-2", "cloud-2", 3)
+
+			jobId, err := hmc.CreateResourceGroup(ctx, nodepool)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() hwmgrclient.JobStatus { return jobStatus(ctx, hmc, jobId) }, 5*time.Second).
+				Should(Equal(hwmgrclient.JobStatusFailed))
+			Expect(hmc.ResourceGroupExists(ctx, nodepool)).To(BeFalse())
+		})
+
+		It("must report a job that is no longer known to the hardware manager", func() {
+			nodepool := newNodePool("np-3", "cloud-3", 1)
+
+			fake.FailNextJob(dellserver.OpCreateResourceGroup, "server unavailable")
+			jobId, err := hmc.CreateResourceGroup(ctx, nodepool)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() hwmgrclient.JobStatus { return jobStatus(ctx, hmc, jobId) }, 5*time.Second).
+				Should(Equal(hwmgrclient.JobStatusFailed))
+
+			fake.ExpireJob(jobId)
+			Expect(jobStatus(ctx, hmc, jobId)).To(Equal(hwmgrclient.JobStatusNotExist))
+		})
+
+		It("must return an error while the hardware manager is failing requests", func() {
+			fake.FailRequests(dellserver.OpGetResourceGroups,
+				dellserver.Failure{StatusCode: http.StatusServiceUnavailable, Message: "unavailable", Count: 1})
+
+			_, err := hmc.GetResourceGroups(ctx)
+			Expect(err).To(HaveOccurred())
+
+			_, err = hmc.GetResourceGroups(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fake.Requests(dellserver.OpGetResourceGroups)).To(Equal(2))
+		})
+	})
+})
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// newNodePool returns a NodePool with a single master node group, allocated from the resources of the fake server
+func newNodePool(name, cloudID string, size int) *hwmgmtv1alpha1.NodePool {
+	return &hwmgmtv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: hwmgmtv1alpha1.NodePoolSpec{
+			CloudID: cloudID,
+			HwMgrId: "dell-1",
+			NodeGroup: []hwmgmtv1alpha1.NodeGroup{
+				{
+					NodePoolData: hwmgmtv1alpha1.NodePoolData{
+						Name:           "master",
+						Role:           "master",
+						ResourcePoolId: "pool-1",
+						HwProfile:      "profile-1",
+					},
+					Size: size,
+				},
+			},
+		},
+	}
+}
+
+func jobStatus(ctx context.Context, hmc *hwmgrclient.HardwareManagerClient, jobId string) hwmgrclient.JobStatus {
+	status, _, err := hmc.CheckJobStatus(ctx, jobId)
+	Expect(err).NotTo(HaveOccurred())
+	return status
+}